@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"runtime"
 	"sync"
 	"syscall"
 	"time"
 
+	"github.com/dbehnke/dmr-nexus/pkg/aprs"
 	"github.com/dbehnke/dmr-nexus/pkg/bridge"
 	"github.com/dbehnke/dmr-nexus/pkg/config"
 	"github.com/dbehnke/dmr-nexus/pkg/database"
@@ -18,6 +20,8 @@ import (
 	"github.com/dbehnke/dmr-nexus/pkg/mqtt"
 	"github.com/dbehnke/dmr-nexus/pkg/network"
 	"github.com/dbehnke/dmr-nexus/pkg/peer"
+	"github.com/dbehnke/dmr-nexus/pkg/privacy"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
 	"github.com/dbehnke/dmr-nexus/pkg/radioid"
 	"github.com/dbehnke/dmr-nexus/pkg/web"
 )
@@ -28,9 +32,12 @@ var (
 	buildTime = "unknown"
 )
 
+// logRingSize is how many recent log lines /api/logs/stream replays on connect
+const logRingSize = 500
+
 func main() {
 	// Parse command line flags
-	configFile := flag.String("config", "config.yaml", "Path to configuration file")
+	configFile := flag.String("config", "config.yaml", "Path or http(s) URL of configuration file")
 	showVersion := flag.Bool("version", false, "Show version information")
 	validate := flag.Bool("validate", false, "Validate configuration and exit")
 	flag.Parse()
@@ -71,12 +78,40 @@ func main() {
 		logger.String("config_file", *configFile))
 
 	// Reinitialize logger with config from file
+	// Keep recent lines for remote tailing if the dashboard streams logs
+	var logRing *logger.Ring
+	if cfg.Web.Enabled && cfg.Web.LogStream {
+		logRing = logger.NewRing(logRingSize)
+	}
+
+	// Subscriber identities are anonymized everywhere they leave memory
+	anon := privacy.New(cfg.Privacy.Anonymize, cfg.Privacy.Salt)
+	var redact func(logger.Field) logger.Field
+	if anon.Enabled() {
+		redact = anon.LogField
+	}
+
 	log = logger.New(logger.Config{
 		Level:  cfg.Logging.Level,
 		Format: cfg.Logging.Format,
+		Ring:   logRing,
+		Redact: redact,
 	})
 
 	log.Debug("Debug logging enabled")
+	if anon.Enabled() {
+		log.Info("Privacy mode enabled", logger.String("anonymize", cfg.Privacy.Anonymize))
+	}
+
+	// Apply runtime tuning before anything starts
+	if cfg.Runtime.GOMAXPROCS > 0 {
+		runtime.GOMAXPROCS(cfg.Runtime.GOMAXPROCS)
+	}
+	packetWorkers := network.PacketWorkerCount(cfg.Runtime.PacketWorkers)
+	log.Info("Runtime tuning",
+		logger.Int("gomaxprocs", runtime.GOMAXPROCS(0)),
+		logger.Int("num_cpu", runtime.NumCPU()),
+		logger.Int("packet_workers", packetWorkers))
 
 	// Set version info for web API
 	web.SetVersionInfo(version, gitCommit, buildTime)
@@ -94,6 +129,9 @@ func main() {
 
 	// Initialize metrics collector
 	metricsCollector := metrics.NewCollector()
+	if len(cfg.Metrics.PacketSizeBuckets) > 0 {
+		metricsCollector.SetPacketSizeBuckets(cfg.Metrics.PacketSizeBuckets)
+	}
 
 	// Initialize database
 	db, err := database.NewDB(database.Config{
@@ -111,6 +149,9 @@ func main() {
 
 	txRepo := database.NewTransmissionRepository(db.GetDB())
 	userRepo := database.NewDMRUserRepository(db.GetDB())
+	positionRepo := database.NewPositionRepository(db.GetDB())
+	messageRepo := database.NewMessageRepository(db.GetDB())
+	airtimeRepo := database.NewAirtimeRepository(db.GetDB())
 	log.Info("Database initialized")
 
 	// Start RadioID syncer
@@ -129,9 +170,10 @@ func main() {
 			defer wg.Done()
 			metricsServer := metrics.NewPrometheusServer(
 				metrics.PrometheusConfig{
-					Enabled: cfg.Metrics.Prometheus.Enabled,
-					Port:    cfg.Metrics.Prometheus.Port,
-					Path:    cfg.Metrics.Prometheus.Path,
+					Enabled:    cfg.Metrics.Prometheus.Enabled,
+					Port:       cfg.Metrics.Prometheus.Port,
+					Path:       cfg.Metrics.Prometheus.Path,
+					UnixSocket: cfg.Metrics.Prometheus.UnixSocket,
 				},
 				metricsCollector,
 				log.WithComponent("metrics"),
@@ -145,6 +187,29 @@ func main() {
 			logger.String("path", cfg.Metrics.Prometheus.Path))
 	}
 
+	// Start StatsD exporter if enabled
+	if cfg.Metrics.Enabled && cfg.Metrics.StatsD.Enabled {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			exporter := metrics.NewStatsDExporter(
+				metrics.StatsDConfig{
+					Address:       cfg.Metrics.StatsD.Address,
+					Prefix:        cfg.Metrics.StatsD.Prefix,
+					FlushInterval: time.Duration(cfg.Metrics.StatsD.FlushInterval) * time.Second,
+					DogStatsD:     cfg.Metrics.StatsD.DogStatsD,
+				},
+				metricsCollector,
+				log.WithComponent("metrics"),
+			)
+			if err := exporter.Start(ctx); err != nil && err != context.Canceled {
+				log.Error("StatsD exporter error", logger.Error(err))
+			}
+		}()
+		log.Info("StatsD exporter started",
+			logger.String("address", cfg.Metrics.StatsD.Address))
+	}
+
 	// Initialize MQTT publisher if enabled
 	var mqttPublisher *mqtt.Publisher
 	if cfg.MQTT.Enabled {
@@ -158,9 +223,27 @@ func main() {
 				Password:    cfg.MQTT.Password,
 				QoS:         cfg.MQTT.QoS,
 				Retained:    cfg.MQTT.Retained,
+
+				IncludePosition: cfg.MQTT.IncludePosition,
+				StatusInterval:  time.Duration(cfg.MQTT.StatusInterval) * time.Second,
 			},
 			log.WithComponent("mqtt"),
 		)
+		mqttPublisher.SetPositionLookup(func(radioID uint32) (float64, float64, bool) {
+			pos, err := userRepo.GetPosition(radioID)
+			if err != nil {
+				return 0, 0, false
+			}
+			return pos.Latitude, pos.Longitude, true
+		})
+		mqttPublisher.SetAnonymizer(anon)
+		mqttPublisher.SetStatusSource(func() mqtt.StatusEvent {
+			return mqtt.StatusEvent{
+				Version:       version,
+				Peers:         metricsCollector.GetActivePeers(),
+				ActiveStreams: metricsCollector.GetActiveStreams(),
+			}
+		})
 
 		wg.Add(1)
 		go func() {
@@ -172,14 +255,82 @@ func main() {
 		log.Info("MQTT publisher started",
 			logger.String("broker", cfg.MQTT.Broker),
 			logger.String("topic_prefix", cfg.MQTT.TopicPrefix))
+
+		if len(cfg.Talkgroups.Entries) > 0 {
+			directory := make([]mqtt.Talkgroup, 0, len(cfg.Talkgroups.Entries))
+			for _, tg := range cfg.Talkgroups.Entries {
+				directory = append(directory, mqtt.Talkgroup{ID: uint32(tg.ID), Name: tg.Name, Description: tg.Description})
+			}
+			if err := mqttPublisher.PublishTalkgroupDirectory(mqtt.TalkgroupDirectoryEvent{
+				Talkgroups: directory,
+				Timestamp:  time.Now(),
+			}); err != nil {
+				log.Warn("Failed to publish talkgroup directory", logger.Error(err))
+			}
+		}
+	}
+
+	// Initialize the APRS-IS uplink; stations are never sent in privacy mode
+	var aprsClient *aprs.Client
+	if cfg.APRS.Enabled && anon.Enabled() {
+		log.Warn("APRS-IS uplink disabled while privacy.anonymize is set")
+	} else if cfg.APRS.Enabled {
+		aprsClient = aprs.New(
+			aprs.Config{
+				Enabled:  cfg.APRS.Enabled,
+				Server:   cfg.APRS.Server,
+				Callsign: cfg.APRS.Callsign,
+				Passcode: cfg.APRS.Passcode,
+				Comment:  cfg.APRS.Comment,
+				Interval: time.Duration(cfg.APRS.Interval) * time.Second,
+				Version:  version,
+			},
+			log.WithComponent("aprs"),
+		)
+		aprsClient.SetStationLookup(func(radioID uint32) (aprs.Station, bool) {
+			user, err := userRepo.GetByRadioID(radioID)
+			if err != nil || user == nil {
+				return aprs.Station{}, false
+			}
+			station := aprs.Station{Callsign: user.Callsign}
+			if pos, err := userRepo.GetPosition(radioID); err == nil {
+				station.Latitude, station.Longitude, station.HasPosition = pos.Latitude, pos.Longitude, true
+			}
+			return station, true
+		})
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := aprsClient.Start(ctx); err != nil && err != context.Canceled {
+				log.Error("APRS-IS uplink error", logger.Error(err))
+			}
+		}()
+		log.Info("APRS-IS uplink started",
+			logger.String("server", cfg.APRS.Server),
+			logger.String("callsign", cfg.APRS.Callsign))
 	}
 
 	// Initialize DMR components
 	peerManager := peer.NewPeerManager()
 	router := bridge.NewRouter()
+	masters := network.NewRegistry()
+	dedupPolicy, err := bridge.ParseDedupPolicy(cfg.Global.StreamDedup)
+	if err != nil {
+		log.Error("Invalid stream dedup policy", logger.Error(err))
+		os.Exit(1)
+	}
+	router.SetDedupPolicy(dedupPolicy)
+	for _, sp := range cfg.Global.StreamPriorities {
+		router.SetStreamPriority(uint32(sp.SourceID), uint32(sp.TGID), sp.Priority)
+	}
+	for name, rules := range cfg.Bridges {
+		router.AddBridge(bridge.NewBridgeRuleSetFromConfig(name, rules))
+	}
 
 	// Set up transmission logger for router
 	txLogger := bridge.NewTransmissionLogger(txRepo, log.WithComponent("txlog"))
+	txLogger.SetAnonymizer(anon)
 	router.SetTransmissionLogger(txLogger)
 
 	// Start cleanup routine for stale streams
@@ -198,6 +349,58 @@ func main() {
 		}
 	}()
 
+	// Start database maintenance: prune old rows daily, or with each VACUUM
+	// that reclaims the freed space when vacuum_interval is set
+	if cfg.Database.RetentionDays > 0 || cfg.Database.VacuumInterval > 0 {
+		interval := 24 * time.Hour
+		if cfg.Database.VacuumInterval > 0 {
+			interval = time.Duration(cfg.Database.VacuumInterval) * time.Hour
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if cfg.Database.RetentionDays > 0 {
+						cutoff := time.Now().AddDate(0, 0, -cfg.Database.RetentionDays)
+						deleted, err := txRepo.DeleteOlderThan(cutoff)
+						if err != nil {
+							log.Error("Failed to prune transmissions", logger.Error(err))
+						} else {
+							log.Info("Pruned old transmissions", logger.Int64("deleted", deleted))
+						}
+						deleted, err = positionRepo.DeleteOlderThan(cutoff)
+						if err != nil {
+							log.Error("Failed to prune positions", logger.Error(err))
+						} else {
+							log.Info("Pruned old positions", logger.Int64("deleted", deleted))
+						}
+						deleted, err = messageRepo.DeleteOlderThan(cutoff)
+						if err != nil {
+							log.Error("Failed to prune messages", logger.Error(err))
+						} else {
+							log.Info("Pruned old messages", logger.Int64("deleted", deleted))
+						}
+					}
+					if cfg.Database.VacuumInterval == 0 {
+						continue
+					}
+					start := time.Now()
+					if err := txRepo.Vacuum(); err != nil {
+						log.Error("Failed to vacuum database", logger.Error(err))
+					} else {
+						log.Info("Database vacuumed", logger.String("duration", time.Since(start).String()))
+					}
+				}
+			}
+		}()
+	}
+
 	// Start web server if enabled (after creating peer manager and router)
 	var webServer *web.Server
 	if cfg.Web.Enabled {
@@ -207,7 +410,21 @@ func main() {
 
 		// Set transmission repository and user repository for API
 		webServer.GetAPI().SetTransmissionRepo(txRepo)
+		webServer.GetAPI().SetNetLogger(txLogger)
 		webServer.GetAPI().SetUserRepo(userRepo)
+		webServer.GetAPI().SetPositionRepo(positionRepo)
+		if cfg.Database.StoreMessages {
+			webServer.GetAPI().SetMessageRepo(messageRepo)
+		}
+		webServer.GetAPI().SetTalkgroups(cfg.Talkgroups.Entries)
+		radioIDSyncer.OnSync(webServer.GetAPI().InvalidateUserCache)
+		webServer.GetAPI().SetMetrics(metricsCollector)
+		webServer.GetAPI().SetConfig(cfg)
+		webServer.GetAPI().SetAnonymizer(anon)
+		webServer.GetAPI().SetSystems(masters)
+		if logRing != nil {
+			webServer.GetAPI().SetLogRing(logRing, cfg.Web.LogStreamLevel)
+		}
 
 		wg.Add(1)
 		go func() {
@@ -216,10 +433,52 @@ func main() {
 				log.Error("Web server error", logger.Error(err))
 			}
 		}()
-		log.Info("Web server started",
-			logger.String("host", cfg.Web.Host),
-			logger.Int("port", cfg.Web.Port))
+		if cfg.Web.UnixSocket != "" {
+			log.Info("Web server started",
+				logger.String("socket", cfg.Web.UnixSocket))
+		} else {
+			log.Info("Web server started",
+				logger.String("host", cfg.Web.Host),
+				logger.Int("port", cfg.Web.Port))
+		}
+	}
+
+	// Report static bridge rules linking and unlinking systems
+	var announceToWeb func(bridge.BridgeChange)
+	if webServer != nil {
+		announceToWeb = webServer.BridgeChangeHandler()
 	}
+	router.SetBridgeChangeHandler(func(change bridge.BridgeChange) {
+		state := "Bridge rule deactivated"
+		if change.Active {
+			state = "Bridge rule activated"
+		}
+		log.Info(state,
+			logger.String("bridge", change.Bridge),
+			logger.String("system", change.System),
+			logger.Int("tg", change.TGID),
+			logger.Int("ts", change.Timeslot),
+			logger.Int("trigger_tg", int(change.Trigger)))
+
+		if !cfg.Global.AnnounceBridgeChanges {
+			return
+		}
+		if mqttPublisher != nil {
+			if err := mqttPublisher.PublishBridgeChange(mqtt.BridgeEvent{
+				BridgeName: change.Bridge,
+				System:     change.System,
+				TGID:       uint32(change.TGID),
+				Timeslot:   uint8(change.Timeslot),
+				Active:     change.Active,
+				Timestamp:  time.Now(),
+			}); err != nil {
+				log.Warn("Failed to publish bridge change", logger.Error(err))
+			}
+		}
+		if announceToWeb != nil {
+			announceToWeb(change)
+		}
+	})
 
 	// Start DMR network servers for each configured system
 	for name, system := range cfg.Systems {
@@ -237,7 +496,32 @@ func main() {
 
 			server := network.NewServer(system, name, log.WithComponent("network."+name)).
 				WithPeerManager(peerManager).
-				WithRouter(router)
+				WithRouter(router).
+				WithMetrics(metricsCollector).
+				WithUserRepo(userRepo).
+				WithAnonymizer(anon).
+				WithAirtimeRepo(airtimeRepo).
+				WithPacketWorkers(packetWorkers)
+			masters.Register(server)
+
+			if system.AnnounceCaller || system.SubscriptionSummary {
+				clips, err := network.LoadAnnounceClips(system.AnnounceClipsDir)
+				if err != nil {
+					log.Error("Failed to load announce clips, announcements disabled",
+						logger.String("system", name),
+						logger.Error(err))
+				} else if system.AnnounceCaller {
+					server.SetCallerAnnouncement(clips, func(radioID uint32) string {
+						user, err := userRepo.GetByRadioID(radioID)
+						if err != nil || user == nil {
+							return ""
+						}
+						return user.Callsign
+					})
+				} else {
+					server.SetAnnounceClips(clips)
+				}
+			}
 
 			// Wire peer event handlers to WebSocket if web server is enabled
 			if webServer != nil {
@@ -247,6 +531,96 @@ func main() {
 				)
 			}
 
+			if system.FirstHeardGreeting && mqttPublisher != nil {
+				sysName := name
+				server.SetFirstHeardHandler(func(radioID, dst, peerID uint32) {
+					if err := mqttPublisher.PublishFirstHeard(mqtt.FirstHeardEvent{
+						RadioID:   radioID,
+						DestID:    dst,
+						PeerID:    peerID,
+						System:    sysName,
+						Timestamp: time.Now(),
+					}); err != nil {
+						log.Warn("Failed to publish first-heard event", logger.Error(err))
+					}
+				})
+			}
+
+			if mqttPublisher != nil {
+				server.SetTrafficHandler(func(streamID, src, dst uint32, timeslot int, active bool) {
+					if err := mqttPublisher.PublishTraffic(mqtt.TrafficEvent{
+						SourceID:  src,
+						DestID:    dst,
+						Timeslot:  uint8(timeslot),
+						StreamID:  streamID,
+						Active:    active,
+						Timestamp: time.Now(),
+					}); err != nil {
+						log.Warn("Failed to publish traffic event", logger.Error(err))
+					}
+				})
+			}
+
+			if system.DailyAirtimeMinutes > 0 && mqttPublisher != nil {
+				sysName := name
+				server.SetAirtimeExceededHandler(func(radioID uint32, used time.Duration) {
+					if err := mqttPublisher.PublishAirtimeExceeded(mqtt.AirtimeEvent{
+						RadioID:   radioID,
+						System:    sysName,
+						Seconds:   used.Seconds(),
+						Timestamp: time.Now(),
+					}); err != nil {
+						log.Warn("Failed to publish airtime event", logger.Error(err))
+					}
+				})
+			}
+
+			if aprsClient != nil {
+				server.SetHeardHandler(func(radioID, dst, peerID uint32) {
+					aprsClient.Heard(radioID)
+				})
+			}
+
+			// Positions are never stored in privacy mode
+			if !anon.Enabled() {
+				server.SetPositionHandler(func(radioID uint32, report *protocol.LRRPReport) {
+					if aprsClient != nil {
+						aprsClient.Position(radioID, report.Latitude, report.Longitude)
+					}
+					at := report.Time
+					if at.IsZero() {
+						at = time.Now()
+					}
+					if err := positionRepo.Create(&database.Position{
+						RadioID:   radioID,
+						Latitude:  report.Latitude,
+						Longitude: report.Longitude,
+						Timestamp: at,
+					}); err != nil {
+						log.Warn("Failed to save position", logger.Error(err))
+					}
+					// The last known position rides along on traffic events
+					if err := userRepo.SetPosition(radioID, report.Latitude, report.Longitude); err != nil {
+						log.Warn("Failed to update last known position", logger.Error(err))
+					}
+				})
+			}
+
+			// Nor are text messages
+			if cfg.Database.StoreMessages && !anon.Enabled() {
+				server.SetMessageHandler(func(src, dst uint32, group bool, text string) {
+					if err := messageRepo.Create(&database.Message{
+						SourceID:      src,
+						DestinationID: dst,
+						Group:         group,
+						Text:          text,
+						Timestamp:     time.Now(),
+					}); err != nil {
+						log.Warn("Failed to save message", logger.Error(err))
+					}
+				})
+			}
+
 			wg.Add(1)
 			go func(sysName string, srv *network.Server) {
 				defer wg.Done()
@@ -258,14 +632,41 @@ func main() {
 			}(name, server)
 
 		case "PEER":
-			log.Info("PEER mode not yet implemented",
-				logger.String("system", name))
-			// TODO: Implement PEER mode client
+			log.Info("Starting PEER mode client",
+				logger.String("system", name),
+				logger.String("master", fmt.Sprintf("%s:%d", system.MasterIP, system.MasterPort)))
+
+			client := network.NewClient(system, log.WithComponent("network."+name)).
+				WithRouter(router, name)
+
+			wg.Add(1)
+			go func(sysName string, c *network.Client) {
+				defer wg.Done()
+				if err := c.Run(ctx); err != nil && err != context.Canceled {
+					log.Error("DMR client error",
+						logger.String("system", sysName),
+						logger.Error(err))
+				}
+			}(name, client)
 
 		case "OPENBRIDGE":
-			log.Info("OPENBRIDGE mode not yet implemented",
-				logger.String("system", name))
-			// TODO: Implement OPENBRIDGE mode
+			log.Info("Starting OPENBRIDGE mode client",
+				logger.String("system", name),
+				logger.String("target", fmt.Sprintf("%s:%d", system.TargetIP, system.TargetPort)),
+				logger.Int("network_id", system.NetworkID))
+
+			obClient := network.NewOpenBridgeClient(system, log.WithComponent("network."+name)).
+				WithRouter(router, name)
+
+			wg.Add(1)
+			go func(sysName string, c *network.OpenBridgeClient) {
+				defer wg.Done()
+				if err := c.Start(ctx); err != nil && err != context.Canceled {
+					log.Error("OpenBridge client error",
+						logger.String("system", sysName),
+						logger.Error(err))
+				}
+			}(name, obClient)
 
 		default:
 			log.Warn("Unknown system mode",
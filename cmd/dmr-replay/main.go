@@ -0,0 +1,133 @@
+// Command dmr-replay replays a pcap of UDP DMR traffic at a server, or records
+// the datagrams a listening socket receives into a pcap for later replay.
+package main
+
+import (
+	"context"
+	"flag"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/internal/replay"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+)
+
+func main() {
+	mode := flag.String("mode", "replay", "Mode: replay or record")
+	file := flag.String("file", "capture.pcap", "pcap file to replay from or record to")
+	target := flag.String("target", "127.0.0.1:62031", "Server address to replay at")
+	listen := flag.String("listen", ":62031", "UDP address to record on")
+	serverPort := flag.Int("server-port", 0, "Replay only datagrams sent to this port (0 infers it from the first datagram)")
+	speed := flag.Float64("speed", 1.0, "Playback speed relative to the capture (0 sends as fast as possible)")
+	passphrase := flag.String("passphrase", "", "Re-sign captured RPTK challenges with this passphrase")
+	settle := flag.Duration("settle", replay.DefaultSettle, "How long to collect replies after the last datagram")
+	flag.Parse()
+
+	log := logger.New(logger.Config{
+		Level:  "info",
+		Format: "text",
+	})
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	var err error
+	switch *mode {
+	case "replay":
+		err = runReplay(ctx, log, *file, *target, replay.Options{
+			ServerPort: *serverPort,
+			Speed:      *speed,
+			Passphrase: *passphrase,
+			Settle:     *settle,
+		})
+	case "record":
+		err = runRecord(ctx, log, *file, *listen)
+	default:
+		log.Error("Unknown mode", logger.String("mode", *mode))
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Error("dmr-replay failed", logger.Error(err))
+		os.Exit(1)
+	}
+}
+
+// runReplay sends a capture's client datagrams at the target server
+func runReplay(ctx context.Context, log *logger.Logger, file, target string, opts replay.Options) error {
+	addr, err := net.ResolveUDPAddr("udp", target)
+	if err != nil {
+		return err
+	}
+	opts.Target = addr
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	r, err := replay.NewReader(f)
+	if err != nil {
+		return err
+	}
+	datagrams, err := r.ReadAll()
+	if err != nil {
+		return err
+	}
+
+	log.Info("Replaying capture",
+		logger.String("file", file),
+		logger.String("target", addr.String()),
+		logger.Int("datagrams", len(datagrams)))
+
+	start := time.Now()
+	result, err := replay.Replay(ctx, datagrams, opts)
+	if err != nil {
+		return err
+	}
+
+	for src, replies := range result.Responses {
+		log.Info("Replies received",
+			logger.String("source", src),
+			logger.Int("count", len(replies)))
+	}
+	log.Info("Replay complete",
+		logger.Int("sent", result.Sent),
+		logger.String("elapsed", time.Since(start).String()))
+	return nil
+}
+
+// runRecord captures datagrams received on listen until interrupted
+func runRecord(ctx context.Context, log *logger.Logger, file, listen string) error {
+	addr, err := net.ResolveUDPAddr("udp", listen)
+	if err != nil {
+		return err
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	w, err := replay.NewWriter(f)
+	if err != nil {
+		return err
+	}
+
+	log.Info("Recording datagrams",
+		logger.String("listen", conn.LocalAddr().String()),
+		logger.String("file", file))
+
+	count, err := replay.Record(ctx, conn, w)
+	log.Info("Recording stopped", logger.Int("datagrams", count))
+	return err
+}
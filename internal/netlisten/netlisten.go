@@ -0,0 +1,28 @@
+// Package netlisten opens the HTTP listeners shared by the web dashboard and
+// the metrics endpoint: TCP, or a Unix domain socket for reverse proxy
+// deployments.
+package netlisten
+
+import (
+	"fmt"
+	"net"
+	"os"
+)
+
+// Listen opens a listener: a Unix domain socket when socketPath is set,
+// otherwise TCP on addr. A socket left behind by an unclean exit is removed
+// first; anything else at the path is an error.
+func Listen(addr, socketPath string) (net.Listener, error) {
+	if socketPath == "" {
+		return net.Listen("tcp", addr)
+	}
+	if fi, err := os.Lstat(socketPath); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", socketPath)
+		}
+		if err := os.Remove(socketPath); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+	return net.Listen("unix", socketPath)
+}
@@ -0,0 +1,35 @@
+package netlisten
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListen_RefusesNonSocketPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "not-a-socket")
+	if err := os.WriteFile(path, []byte("data"), 0o644); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+	if _, err := Listen("", path); err == nil {
+		t.Fatal("Expected error listening over a regular file")
+	}
+}
+
+func TestListen_ReplacesStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stale.sock")
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("Listen error: %v", err)
+	}
+	// Leave the socket file behind, as a crash would
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = stale.Close()
+
+	ln, err := Listen("", path)
+	if err != nil {
+		t.Fatalf("Expected the stale socket replaced, got %v", err)
+	}
+	_ = ln.Close()
+}
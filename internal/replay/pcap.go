@@ -0,0 +1,295 @@
+package replay
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// Link types understood by the reader. The writer always emits LinkTypeRaw.
+const (
+	LinkTypeNull     = 0   // BSD loopback
+	LinkTypeEthernet = 1   // Ethernet II
+	LinkTypeRaw      = 101 // Raw IPv4/IPv6
+	LinkTypeLinuxSLL = 113 // Linux "any" cooked capture
+)
+
+const (
+	pcapMagicMicros = 0xa1b2c3d4
+	pcapMagicNanos  = 0xa1b23c4d
+	pcapSnapLen     = 65535
+
+	ipProtoUDP = 17
+)
+
+// Datagram is one UDP datagram taken from, or destined for, a capture
+type Datagram struct {
+	Timestamp time.Time
+	Src       *net.UDPAddr
+	Dst       *net.UDPAddr
+	Payload   []byte
+}
+
+// Reader reads UDP datagrams from a classic libpcap capture file
+type Reader struct {
+	r        io.Reader
+	order    binary.ByteOrder
+	nanos    bool
+	linkType uint32
+}
+
+// NewReader reads the capture's global header and returns a reader for its records
+func NewReader(r io.Reader) (*Reader, error) {
+	hdr := make([]byte, 24)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return nil, fmt.Errorf("failed to read pcap header: %w", err)
+	}
+
+	pr := &Reader{r: r}
+	switch {
+	case binary.LittleEndian.Uint32(hdr[0:4]) == pcapMagicMicros:
+		pr.order = binary.LittleEndian
+	case binary.BigEndian.Uint32(hdr[0:4]) == pcapMagicMicros:
+		pr.order = binary.BigEndian
+	case binary.LittleEndian.Uint32(hdr[0:4]) == pcapMagicNanos:
+		pr.order, pr.nanos = binary.LittleEndian, true
+	case binary.BigEndian.Uint32(hdr[0:4]) == pcapMagicNanos:
+		pr.order, pr.nanos = binary.BigEndian, true
+	default:
+		return nil, fmt.Errorf("not a pcap file (magic %x)", hdr[0:4])
+	}
+
+	pr.linkType = pr.order.Uint32(hdr[20:24])
+	switch pr.linkType {
+	case LinkTypeNull, LinkTypeEthernet, LinkTypeRaw, LinkTypeLinuxSLL:
+	default:
+		return nil, fmt.Errorf("unsupported pcap link type %d", pr.linkType)
+	}
+	return pr, nil
+}
+
+// Next returns the next UDP datagram in the capture, skipping any record
+// that isn't UDP over IPv4/IPv6. It returns io.EOF at the end of the file.
+func (pr *Reader) Next() (*Datagram, error) {
+	rec := make([]byte, 16)
+	for {
+		if _, err := io.ReadFull(pr.r, rec); err != nil {
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil, fmt.Errorf("truncated pcap record header: %w", err)
+			}
+			return nil, err
+		}
+
+		sec := pr.order.Uint32(rec[0:4])
+		frac := pr.order.Uint32(rec[4:8])
+		capLen := pr.order.Uint32(rec[8:12])
+		if capLen > pcapSnapLen*4 {
+			return nil, fmt.Errorf("pcap record too large: %d bytes", capLen)
+		}
+
+		frame := make([]byte, capLen)
+		if _, err := io.ReadFull(pr.r, frame); err != nil {
+			return nil, fmt.Errorf("truncated pcap record: %w", err)
+		}
+
+		nsec := int64(frac) * 1000
+		if pr.nanos {
+			nsec = int64(frac)
+		}
+
+		d, ok := pr.decode(frame)
+		if !ok {
+			continue
+		}
+		d.Timestamp = time.Unix(int64(sec), nsec)
+		return d, nil
+	}
+}
+
+// ReadAll reads every remaining UDP datagram in the capture
+func (pr *Reader) ReadAll() ([]*Datagram, error) {
+	var out []*Datagram
+	for {
+		d, err := pr.Next()
+		if errors.Is(err, io.EOF) {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, d)
+	}
+}
+
+// decode strips the link layer and returns the UDP datagram in frame, if any
+func (pr *Reader) decode(frame []byte) (*Datagram, bool) {
+	switch pr.linkType {
+	case LinkTypeEthernet:
+		if len(frame) < 14 {
+			return nil, false
+		}
+		etherType := binary.BigEndian.Uint16(frame[12:14])
+		frame = frame[14:]
+		// Skip a single 802.1Q tag
+		if etherType == 0x8100 && len(frame) >= 4 {
+			etherType = binary.BigEndian.Uint16(frame[2:4])
+			frame = frame[4:]
+		}
+		if etherType != 0x0800 && etherType != 0x86dd {
+			return nil, false
+		}
+	case LinkTypeLinuxSLL:
+		if len(frame) < 16 {
+			return nil, false
+		}
+		frame = frame[16:]
+	case LinkTypeNull:
+		if len(frame) < 4 {
+			return nil, false
+		}
+		frame = frame[4:]
+	}
+	return decodeIP(frame)
+}
+
+// decodeIP parses an IPv4 or IPv6 packet carrying UDP
+func decodeIP(pkt []byte) (*Datagram, bool) {
+	if len(pkt) < 1 {
+		return nil, false
+	}
+
+	var src, dst net.IP
+	var udp []byte
+	switch pkt[0] >> 4 {
+	case 4:
+		if len(pkt) < 20 {
+			return nil, false
+		}
+		ihl := int(pkt[0]&0x0f) * 4
+		// Fragments can't be reassembled here; only the first would have a UDP header
+		fragOffset := binary.BigEndian.Uint16(pkt[6:8]) & 0x1fff
+		moreFragments := pkt[6]&0x20 != 0
+		if ihl < 20 || len(pkt) < ihl || pkt[9] != ipProtoUDP || fragOffset != 0 || moreFragments {
+			return nil, false
+		}
+		total := int(binary.BigEndian.Uint16(pkt[2:4]))
+		if total >= ihl && total <= len(pkt) {
+			pkt = pkt[:total]
+		}
+		src, dst = net.IP(pkt[12:16]), net.IP(pkt[16:20])
+		udp = pkt[ihl:]
+	case 6:
+		if len(pkt) < 40 || pkt[6] != ipProtoUDP {
+			return nil, false
+		}
+		src, dst = net.IP(pkt[8:24]), net.IP(pkt[24:40])
+		udp = pkt[40:]
+	default:
+		return nil, false
+	}
+
+	if len(udp) < 8 {
+		return nil, false
+	}
+	length := int(binary.BigEndian.Uint16(udp[4:6]))
+	if length < 8 || length > len(udp) {
+		length = len(udp)
+	}
+
+	return &Datagram{
+		Src:     &net.UDPAddr{IP: append(net.IP(nil), src...), Port: int(binary.BigEndian.Uint16(udp[0:2]))},
+		Dst:     &net.UDPAddr{IP: append(net.IP(nil), dst...), Port: int(binary.BigEndian.Uint16(udp[2:4]))},
+		Payload: append([]byte(nil), udp[8:length]...),
+	}, true
+}
+
+// Writer writes UDP datagrams to a classic libpcap capture file using raw IP framing
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter writes the capture's global header and returns a writer for its records
+func NewWriter(w io.Writer) (*Writer, error) {
+	hdr := make([]byte, 24)
+	binary.LittleEndian.PutUint32(hdr[0:4], pcapMagicMicros)
+	binary.LittleEndian.PutUint16(hdr[4:6], 2) // Version 2.4
+	binary.LittleEndian.PutUint16(hdr[6:8], 4)
+	binary.LittleEndian.PutUint32(hdr[16:20], pcapSnapLen)
+	binary.LittleEndian.PutUint32(hdr[20:24], LinkTypeRaw)
+	if _, err := w.Write(hdr); err != nil {
+		return nil, fmt.Errorf("failed to write pcap header: %w", err)
+	}
+	return &Writer{w: w}, nil
+}
+
+// WriteDatagram appends one UDP datagram to the capture
+func (pw *Writer) WriteDatagram(d *Datagram) error {
+	if d.Src == nil || d.Dst == nil {
+		return fmt.Errorf("datagram needs both source and destination addresses")
+	}
+
+	udpLen := 8 + len(d.Payload)
+	if udpLen > 0xffff-40 {
+		return fmt.Errorf("datagram too large: %d bytes", len(d.Payload))
+	}
+
+	var ip []byte
+	src4, dst4 := d.Src.IP.To4(), d.Dst.IP.To4()
+	if src4 != nil && dst4 != nil {
+		ip = make([]byte, 20)
+		ip[0] = 0x45
+		binary.BigEndian.PutUint16(ip[2:4], uint16(20+udpLen))
+		ip[8] = 64
+		ip[9] = ipProtoUDP
+		copy(ip[12:16], src4)
+		copy(ip[16:20], dst4)
+		binary.BigEndian.PutUint16(ip[10:12], ipv4Checksum(ip))
+	} else {
+		ip = make([]byte, 40)
+		ip[0] = 0x60
+		binary.BigEndian.PutUint16(ip[4:6], uint16(udpLen))
+		ip[6] = ipProtoUDP
+		ip[7] = 64
+		copy(ip[8:24], d.Src.IP.To16())
+		copy(ip[24:40], d.Dst.IP.To16())
+	}
+
+	// UDP checksum is left as zero; readers don't verify it
+	udp := make([]byte, 8)
+	binary.BigEndian.PutUint16(udp[0:2], uint16(d.Src.Port))
+	binary.BigEndian.PutUint16(udp[2:4], uint16(d.Dst.Port))
+	binary.BigEndian.PutUint16(udp[4:6], uint16(udpLen))
+
+	frameLen := len(ip) + udpLen
+	ts := d.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	rec := make([]byte, 16)
+	binary.LittleEndian.PutUint32(rec[0:4], uint32(ts.Unix()))
+	binary.LittleEndian.PutUint32(rec[4:8], uint32(ts.Nanosecond()/1000))
+	binary.LittleEndian.PutUint32(rec[8:12], uint32(frameLen))
+	binary.LittleEndian.PutUint32(rec[12:16], uint32(frameLen))
+
+	for _, b := range [][]byte{rec, ip, udp, d.Payload} {
+		if _, err := pw.w.Write(b); err != nil {
+			return fmt.Errorf("failed to write pcap record: %w", err)
+		}
+	}
+	return nil
+}
+
+// ipv4Checksum computes the header checksum over a header whose checksum field is zero
+func ipv4Checksum(hdr []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(hdr); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(hdr[i : i+2]))
+	}
+	for sum > 0xffff {
+		sum = (sum >> 16) + (sum & 0xffff)
+	}
+	return ^uint16(sum)
+}
@@ -0,0 +1,235 @@
+// Package replay records UDP DMR traffic to pcap files and replays captured
+// traffic at a live server, so real-world client quirks can be exercised
+// against the full packet pipeline.
+package replay
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+// DefaultSettle is how long replies are collected after the last datagram is sent
+const DefaultSettle = 500 * time.Millisecond
+
+// Options controls how a capture is replayed
+type Options struct {
+	Target     *net.UDPAddr  // Server to replay at
+	ServerPort int           // Only datagrams sent to this port are replayed; 0 infers it from the first datagram
+	Speed      float64       // Playback speed relative to the capture; 0 sends as fast as possible
+	Passphrase string        // When set, RPTK challenges are re-signed with the live server's salt
+	Settle     time.Duration // How long to keep collecting replies; 0 uses DefaultSettle
+}
+
+// Result summarises a replay
+type Result struct {
+	Sent      int                 // Datagrams sent to the target
+	Responses map[string][][]byte // Replies from the target, keyed by captured source address
+}
+
+// session replays one captured source from its own socket so the server
+// sees each original client as a distinct peer address
+type session struct {
+	conn  *net.UDPConn
+	salts chan []byte
+
+	mu        sync.Mutex
+	responses [][]byte
+}
+
+// Replay sends the client-to-server datagrams in a capture to opts.Target,
+// preserving their relative timing and source separation, and collects what
+// the server sends back to each source
+func Replay(ctx context.Context, datagrams []*Datagram, opts Options) (*Result, error) {
+	if opts.Target == nil {
+		return nil, fmt.Errorf("replay target is required")
+	}
+	if opts.Speed < 0 {
+		return nil, fmt.Errorf("replay speed must not be negative")
+	}
+	settle := opts.Settle
+	if settle <= 0 {
+		settle = DefaultSettle
+	}
+
+	serverPort := opts.ServerPort
+	if serverPort == 0 && len(datagrams) > 0 {
+		serverPort = datagrams[0].Dst.Port
+	}
+
+	sessions := make(map[string]*session)
+	var wg sync.WaitGroup
+	defer func() {
+		for _, s := range sessions {
+			_ = s.conn.Close()
+		}
+		wg.Wait()
+	}()
+
+	result := &Result{Responses: make(map[string][][]byte)}
+	var first time.Time
+	start := time.Now()
+
+	for _, d := range datagrams {
+		if d.Dst.Port != serverPort {
+			continue
+		}
+
+		if opts.Speed > 0 {
+			if first.IsZero() {
+				first = d.Timestamp
+			}
+			offset := time.Duration(float64(d.Timestamp.Sub(first)) / opts.Speed)
+			if wait := time.Until(start.Add(offset)); wait > 0 {
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(wait):
+				}
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		key := d.Src.String()
+		s, ok := sessions[key]
+		if !ok {
+			conn, err := net.DialUDP("udp", nil, opts.Target)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open socket for %s: %w", key, err)
+			}
+			s = &session{conn: conn, salts: make(chan []byte, 1)}
+			sessions[key] = s
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.receive()
+			}()
+		}
+
+		payload := d.Payload
+		if opts.Passphrase != "" && len(payload) == protocol.RPTKPacketSize && string(payload[0:4]) == protocol.PacketTypeRPTK {
+			signed, err := s.resign(ctx, payload, opts.Passphrase, settle)
+			if err != nil {
+				return nil, fmt.Errorf("source %s: %w", key, err)
+			}
+			payload = signed
+		}
+
+		if _, err := s.conn.Write(payload); err != nil {
+			return nil, fmt.Errorf("failed to send datagram for %s: %w", key, err)
+		}
+		result.Sent++
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(settle):
+	}
+
+	for key, s := range sessions {
+		s.mu.Lock()
+		result.Responses[key] = s.responses
+		s.mu.Unlock()
+	}
+	return result, nil
+}
+
+// receive collects replies until the socket is closed, handing any salt
+// from an RPTACK to a pending re-sign
+func (s *session) receive() {
+	buf := make([]byte, 2048)
+	for {
+		n, err := s.conn.Read(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			// ICMP port-unreachable and the like surface as read errors; keep listening
+			continue
+		}
+		reply := append([]byte(nil), buf[:n]...)
+
+		s.mu.Lock()
+		s.responses = append(s.responses, reply)
+		s.mu.Unlock()
+
+		if n == protocol.RPTACKPacketSizeWithSalt && string(reply[0:6]) == protocol.PacketTypeRPTACK {
+			salt := reply[6 : 6+protocol.SaltLength]
+			select {
+			case <-s.salts:
+			default:
+			}
+			s.salts <- salt
+		}
+	}
+}
+
+// resign replaces a captured RPTK challenge with one computed from the salt
+// the live server just issued, since the captured salt won't match
+func (s *session) resign(ctx context.Context, rptk []byte, passphrase string, timeout time.Duration) ([]byte, error) {
+	var salt []byte
+	select {
+	case salt = <-s.salts:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("no salted RPTACK received before RPTK")
+	}
+
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(passphrase))
+
+	signed := append([]byte(nil), rptk...)
+	copy(signed[8:8+protocol.ChallengeLength], h.Sum(nil))
+	return signed, nil
+}
+
+// Record writes every datagram received on conn to w until ctx is done or
+// the connection is closed, and returns how many were recorded
+func Record(ctx context.Context, conn *net.UDPConn, w *Writer) (int, error) {
+	local, _ := conn.LocalAddr().(*net.UDPAddr)
+	buf := make([]byte, 2048)
+	count := 0
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return count, nil
+		}
+		if err := conn.SetReadDeadline(time.Now().Add(250 * time.Millisecond)); err != nil {
+			return count, fmt.Errorf("failed to set read deadline: %w", err)
+		}
+
+		n, addr, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return count, nil
+			}
+			return count, fmt.Errorf("failed to read datagram: %w", err)
+		}
+
+		d := &Datagram{
+			Timestamp: time.Now(),
+			Src:       addr,
+			Dst:       local,
+			Payload:   buf[:n],
+		}
+		if err := w.WriteDatagram(d); err != nil {
+			return count, err
+		}
+		count++
+	}
+}
@@ -0,0 +1,239 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/network"
+	"github.com/dbehnke/dmr-nexus/pkg/peer"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+// captureBuilder assembles a pcap in memory with 20ms between datagrams
+type captureBuilder struct {
+	t   *testing.T
+	w   *Writer
+	buf bytes.Buffer
+	ts  time.Time
+}
+
+func newCaptureBuilder(t *testing.T) *captureBuilder {
+	b := &captureBuilder{t: t, ts: time.Unix(1700000000, 0)}
+	w, err := NewWriter(&b.buf)
+	if err != nil {
+		t.Fatalf("NewWriter error: %v", err)
+	}
+	b.w = w
+	return b
+}
+
+func (b *captureBuilder) add(src, dst *net.UDPAddr, payload []byte) {
+	b.ts = b.ts.Add(20 * time.Millisecond)
+	if err := b.w.WriteDatagram(&Datagram{Timestamp: b.ts, Src: src, Dst: dst, Payload: payload}); err != nil {
+		b.t.Fatalf("WriteDatagram error: %v", err)
+	}
+}
+
+func encode(t *testing.T, p interface{ Encode() ([]byte, error) }) []byte {
+	data, err := p.Encode()
+	if err != nil {
+		t.Fatalf("Encode error: %v", err)
+	}
+	return data
+}
+
+// handshake adds a captured login, including the master's replies that the
+// replay must skip
+func (b *captureBuilder) handshake(client, master *net.UDPAddr, id uint32, callsign string) {
+	b.add(client, master, encode(b.t, &protocol.RPTLPacket{RepeaterID: id}))
+	b.add(master, client, encode(b.t, &protocol.RPTACKPacket{RepeaterID: id, Salt: []byte{1, 2, 3, 4}}))
+	b.add(client, master, encode(b.t, &protocol.RPTKPacket{RepeaterID: id, Challenge: bytes.Repeat([]byte{0xaa}, 32)}))
+	b.add(master, client, encode(b.t, &protocol.RPTACKPacket{RepeaterID: id}))
+	b.add(client, master, encode(b.t, &protocol.RPTCPacket{RepeaterID: id, Callsign: callsign, ColorCode: "1", Slots: "3"}))
+	b.add(master, client, encode(b.t, &protocol.RPTACKPacket{RepeaterID: id}))
+}
+
+func TestReplay_CapturedHandshakeAndForwarding(t *testing.T) {
+	master := &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 62031}
+	repeaterA := &net.UDPAddr{IP: net.ParseIP("198.51.100.10"), Port: 50001}
+	repeaterB := &net.UDPAddr{IP: net.ParseIP("198.51.100.20"), Port: 50002}
+
+	b := newCaptureBuilder(t)
+	b.handshake(repeaterA, master, 311001, "W1AAA")
+	b.handshake(repeaterB, master, 311002, "W1BBB")
+	frames := []byte{protocol.FrameTypeVoiceHeader, protocol.FrameTypeVoice, protocol.FrameTypeVoiceTerminator}
+	for i, ft := range frames {
+		b.add(repeaterA, master, encode(t, &protocol.DMRDPacket{
+			Sequence:      byte(i),
+			SourceID:      3120001,
+			DestinationID: 3100,
+			RepeaterID:    311001,
+			Timeslot:      1,
+			FrameType:     ft,
+			StreamID:      777,
+			Payload:       make([]byte, 33),
+		}))
+	}
+
+	r, err := NewReader(bytes.NewReader(b.buf.Bytes()))
+	if err != nil {
+		t.Fatalf("NewReader error: %v", err)
+	}
+	datagrams, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll error: %v", err)
+	}
+	if len(datagrams) != 15 {
+		t.Fatalf("Expected 15 datagrams in capture, got %d", len(datagrams))
+	}
+
+	cfg := config.SystemConfig{Mode: "MASTER", Port: 0, Passphrase: "s3cret", Repeat: true, MaxPeers: 10}
+	log := logger.New(logger.Config{Level: "error"})
+	pm := peer.NewPeerManager()
+	srv := network.NewServer(cfg, "test-system", log).WithPeerManager(pm)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = srv.Start(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	startCtx, startCancel := context.WithTimeout(ctx, 2*time.Second)
+	defer startCancel()
+	if err := srv.WaitStarted(startCtx); err != nil {
+		t.Fatalf("Server did not start: %v", err)
+	}
+	addr, err := srv.Addr()
+	if err != nil {
+		t.Fatalf("Addr error: %v", err)
+	}
+
+	result, err := Replay(ctx, datagrams, Options{
+		Target:     &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: addr.Port},
+		Speed:      1,
+		Passphrase: "s3cret",
+	})
+	if err != nil {
+		t.Fatalf("Replay error: %v", err)
+	}
+
+	// Only client-to-master datagrams are replayed
+	if result.Sent != 9 {
+		t.Errorf("Expected 9 datagrams sent, got %d", result.Sent)
+	}
+
+	// Both repeaters completed the handshake
+	for _, id := range []uint32{311001, 311002} {
+		p := pm.GetPeer(id)
+		if p == nil || p.GetState() != peer.StateConnected {
+			t.Errorf("Expected peer %d connected after replay", id)
+		}
+	}
+	for _, src := range []*net.UDPAddr{repeaterA, repeaterB} {
+		acks := 0
+		for _, reply := range result.Responses[src.String()] {
+			if bytes.HasPrefix(reply, []byte(protocol.PacketTypeRPTACK)) {
+				acks++
+			}
+		}
+		if acks != 3 {
+			t.Errorf("Expected 3 RPTACKs for %s, got %d", src, acks)
+		}
+	}
+
+	// Repeater A's transmission was repeated to repeater B
+	var forwarded []*protocol.DMRDPacket
+	for _, reply := range result.Responses[repeaterB.String()] {
+		if bytes.HasPrefix(reply, []byte(protocol.PacketTypeDMRD)) {
+			dmrd, err := protocol.ParseDMRD(reply)
+			if err != nil {
+				t.Fatalf("ParseDMRD error: %v", err)
+			}
+			forwarded = append(forwarded, dmrd)
+		}
+	}
+	if len(forwarded) != len(frames) {
+		t.Fatalf("Expected %d frames forwarded to repeater B, got %d", len(frames), len(forwarded))
+	}
+	if forwarded[0].SourceID != 3120001 || forwarded[0].DestinationID != 3100 {
+		t.Errorf("Unexpected forwarded frame: src=%d dst=%d", forwarded[0].SourceID, forwarded[0].DestinationID)
+	}
+	for _, reply := range result.Responses[repeaterA.String()] {
+		if bytes.HasPrefix(reply, []byte(protocol.PacketTypeDMRD)) {
+			t.Error("Transmission was echoed back to its source")
+		}
+	}
+}
+
+func TestRecord_RoundTrip(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	if err != nil {
+		t.Fatalf("NewWriter error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	recorded := make(chan int, 1)
+	go func() {
+		n, _ := Record(ctx, conn, w)
+		recorded <- n
+	}()
+
+	client, err := net.DialUDP("udp", nil, conn.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatalf("DialUDP error: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	rptl := encode(t, &protocol.RPTLPacket{RepeaterID: 311001})
+	ping := []byte("RPTPING\x00\x04\xbe\xd9")
+	for _, payload := range [][]byte{rptl, ping} {
+		if _, err := client.Write(payload); err != nil {
+			t.Fatalf("Write error: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	if n := <-recorded; n != 2 {
+		t.Fatalf("Expected 2 datagrams recorded, got %d", n)
+	}
+
+	r, err := NewReader(&buf)
+	if err != nil {
+		t.Fatalf("NewReader error: %v", err)
+	}
+	datagrams, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll error: %v", err)
+	}
+	if len(datagrams) != 2 {
+		t.Fatalf("Expected 2 datagrams read back, got %d", len(datagrams))
+	}
+	if !bytes.Equal(datagrams[0].Payload, rptl) || !bytes.Equal(datagrams[1].Payload, ping) {
+		t.Error("Recorded payloads do not match what was sent")
+	}
+	if datagrams[0].Src.Port != client.LocalAddr().(*net.UDPAddr).Port {
+		t.Errorf("Expected source port %d, got %d", client.LocalAddr().(*net.UDPAddr).Port, datagrams[0].Src.Port)
+	}
+	if datagrams[0].Dst.Port != conn.LocalAddr().(*net.UDPAddr).Port {
+		t.Errorf("Expected destination port %d, got %d", conn.LocalAddr().(*net.UDPAddr).Port, datagrams[0].Dst.Port)
+	}
+}
@@ -0,0 +1,276 @@
+// Package aprs uplinks heard stations to APRS-IS as APRS objects, so DMR
+// activity shows up on aprs.fi-style maps.
+package aprs
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+)
+
+const (
+	// toCall is the APRS destination for packets this software originates;
+	// APZ is the experimental block
+	toCall = "APZDMR"
+
+	// symbolTable and symbolCode draw objects as a person
+	symbolTable = '/'
+	symbolCode  = '['
+
+	// objectNameLen is the fixed width of an APRS object name
+	objectNameLen = 9
+
+	// queueSize bounds reports waiting to be sent; more are dropped
+	queueSize = 64
+
+	reconnectDelay = 30 * time.Second
+	dialTimeout    = 10 * time.Second
+	writeTimeout   = 10 * time.Second
+)
+
+// Config holds the APRS-IS uplink configuration
+type Config struct {
+	Enabled  bool
+	Server   string // APRS-IS host:port
+	Callsign string // Login callsign, also the sender of every object
+	Passcode string
+	Comment  string        // Appended to each object after the radio ID
+	Interval time.Duration // Minimum time between objects for one station
+	Version  string        // Software version sent at login
+}
+
+// Station is what is known about a radio ID: its callsign and, if on file,
+// its last known coordinates
+type Station struct {
+	Callsign    string
+	Latitude    float64
+	Longitude   float64
+	HasPosition bool
+}
+
+// StationLookup returns the station behind a radio ID
+type StationLookup func(radioID uint32) (Station, bool)
+
+// report is a heard station waiting to be sent
+type report struct {
+	radioID     uint32
+	latitude    float64
+	longitude   float64
+	hasPosition bool // Coordinates came with the report (decoded GPS)
+}
+
+// Client sends APRS objects for heard stations to APRS-IS. Reports are queued
+// and sent from the client's own goroutine, so callers never block on the
+// network.
+type Client struct {
+	config Config
+	log    *logger.Logger
+	lookup StationLookup
+	now    func() time.Time
+
+	queue chan report
+
+	mu       sync.Mutex
+	lastSent map[uint32]time.Time // radio ID -> last object sent
+}
+
+// New creates an APRS-IS client
+func New(config Config, log *logger.Logger) *Client {
+	if log == nil {
+		log = logger.New(logger.Config{Level: "info", Format: "text"})
+	}
+	return &Client{
+		config:   config,
+		log:      log.WithComponent("aprs"),
+		now:      time.Now,
+		queue:    make(chan report, queueSize),
+		lastSent: make(map[uint32]time.Time),
+	}
+}
+
+// SetStationLookup sets where callsigns and last known coordinates come from.
+// Call before Start.
+func (c *Client) SetStationLookup(lookup StationLookup) {
+	c.lookup = lookup
+}
+
+// Heard reports a transmission from radioID; an object is sent if the
+// station has a last known position on file
+func (c *Client) Heard(radioID uint32) {
+	c.enqueue(report{radioID: radioID})
+}
+
+// Position reports a position decoded from radioID's own GPS
+func (c *Client) Position(radioID uint32, lat, lon float64) {
+	c.enqueue(report{radioID: radioID, latitude: lat, longitude: lon, hasPosition: true})
+}
+
+func (c *Client) enqueue(r report) {
+	if !c.config.Enabled {
+		return
+	}
+	select {
+	case c.queue <- r:
+	default:
+		c.log.Debug("APRS queue full, report dropped", logger.Int("radio_id", int(r.radioID)))
+	}
+}
+
+// Start connects to APRS-IS and sends queued reports until ctx is done,
+// reconnecting after connection failures
+func (c *Client) Start(ctx context.Context) error {
+	if !c.config.Enabled {
+		c.log.Info("APRS-IS uplink disabled")
+		return nil
+	}
+
+	for {
+		err := c.session(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		c.log.Warn("APRS-IS connection lost, reconnecting",
+			logger.String("server", c.config.Server),
+			logger.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// session logs in to APRS-IS and sends reports until the connection fails
+// or ctx is done
+func (c *Client) session(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: dialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.config.Server)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	// The server's banner and login response are only logged; anything it
+	// sends later (there is no filter, so only comments) is discarded
+	go func() {
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			c.log.Debug("APRS-IS", logger.String("line", scanner.Text()))
+		}
+	}()
+
+	login := fmt.Sprintf("user %s pass %s vers dmr-nexus %s", c.config.Callsign, c.config.Passcode, c.config.Version)
+	if err := c.writeLine(conn, login); err != nil {
+		return err
+	}
+	c.log.Info("Connected to APRS-IS", logger.String("server", c.config.Server))
+
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case r := <-c.queue:
+			packet, ok := c.packetFor(r)
+			if !ok {
+				continue
+			}
+			if err := c.writeLine(conn, packet); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// packetFor builds the object for a report, or reports false if the station
+// is unknown, has no position, or was sent too recently
+func (c *Client) packetFor(r report) (string, bool) {
+	if c.lookup == nil {
+		return "", false
+	}
+	station, ok := c.lookup(r.radioID)
+	if !ok || station.Callsign == "" {
+		return "", false
+	}
+	if r.hasPosition {
+		station.Latitude, station.Longitude, station.HasPosition = r.latitude, r.longitude, true
+	}
+	if !station.HasPosition {
+		return "", false
+	}
+
+	now := c.now()
+	c.mu.Lock()
+	last, sent := c.lastSent[r.radioID]
+	if sent && now.Sub(last) < c.config.Interval {
+		c.mu.Unlock()
+		return "", false
+	}
+	c.lastSent[r.radioID] = now
+	c.mu.Unlock()
+
+	comment := fmt.Sprintf("DMR %d", r.radioID)
+	if c.config.Comment != "" {
+		comment += " " + c.config.Comment
+	}
+	return FormatObject(c.config.Callsign, station.Callsign, station.Latitude, station.Longitude, now, comment), true
+}
+
+func (c *Client) writeLine(conn net.Conn, line string) error {
+	_ = conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	_, err := conn.Write([]byte(line + "\r\n"))
+	return err
+}
+
+// FormatObject returns a live APRS object named name at the given position,
+// sent by from and timestamped at in UTC
+func FormatObject(from, name string, lat, lon float64, at time.Time, comment string) string {
+	name = strings.ToUpper(name)
+	if len(name) > objectNameLen {
+		name = name[:objectNameLen]
+	}
+	return fmt.Sprintf("%s>%s,TCPIP*:;%-*s*%s%s%c%s%c%s",
+		strings.ToUpper(from), toCall,
+		objectNameLen, name,
+		at.UTC().Format("021504")+"z",
+		formatLatitude(lat), symbolTable,
+		formatLongitude(lon), symbolCode,
+		comment)
+}
+
+// formatLatitude renders a latitude as DDMM.mmN
+func formatLatitude(lat float64) string {
+	hemisphere := 'N'
+	if lat < 0 {
+		hemisphere = 'S'
+	}
+	deg, minutes := degreesMinutes(math.Abs(lat))
+	return fmt.Sprintf("%02d%05.2f%c", deg, minutes, hemisphere)
+}
+
+// formatLongitude renders a longitude as DDDMM.mmE
+func formatLongitude(lon float64) string {
+	hemisphere := 'E'
+	if lon < 0 {
+		hemisphere = 'W'
+	}
+	deg, minutes := degreesMinutes(math.Abs(lon))
+	return fmt.Sprintf("%03d%05.2f%c", deg, minutes, hemisphere)
+}
+
+// degreesMinutes splits decimal degrees into whole degrees and minutes,
+// rounded to hundredths so minutes never print as 60.00
+func degreesMinutes(v float64) (int, float64) {
+	hundredths := int(math.Round(v * 60 * 100))
+	return hundredths / 6000, float64(hundredths%6000) / 100
+}
@@ -0,0 +1,106 @@
+package aprs
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestFormatObject(t *testing.T) {
+	at := time.Date(2026, 3, 9, 14, 5, 30, 0, time.UTC)
+
+	got := FormatObject("n0call-10", "w1aw", 41.7147, -72.7272, at, "DMR 3120001")
+	want := "N0CALL-10>APZDMR,TCPIP*:;W1AW     *091405z4142.88N/07243.63W[DMR 3120001"
+	if got != want {
+		t.Errorf("FormatObject() =\n%q\nwant\n%q", got, want)
+	}
+
+	got = FormatObject("N0CALL", "VK2ABCDEFGH", -33.8688, 151.2093, at, "")
+	want = "N0CALL>APZDMR,TCPIP*:;VK2ABCDEF*091405z3352.13S/15112.56E["
+	if got != want {
+		t.Errorf("FormatObject() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestClient_SendsObjectsToAPRSIS(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen error: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	lines := make(chan string, 10)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	client := New(Config{
+		Enabled:  true,
+		Server:   ln.Addr().String(),
+		Callsign: "N0CALL",
+		Passcode: "13023",
+		Comment:  "via dmr-nexus",
+		Interval: 10 * time.Minute,
+		Version:  "1.0",
+	}, nil)
+	client.now = func() time.Time { return time.Date(2026, 3, 9, 14, 5, 0, 0, time.UTC) }
+	client.SetStationLookup(func(radioID uint32) (Station, bool) {
+		switch radioID {
+		case 3120001:
+			return Station{Callsign: "W1AW", Latitude: 41.7147, Longitude: -72.7272, HasPosition: true}, true
+		case 3120002:
+			return Station{Callsign: "K1ABC"}, true
+		}
+		return Station{}, false
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = client.Start(ctx) }()
+
+	next := func() string {
+		t.Helper()
+		select {
+		case line := <-lines:
+			return line
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for an APRS-IS line")
+			return ""
+		}
+	}
+
+	if got, want := next(), "user N0CALL pass 13023 vers dmr-nexus 1.0"; got != want {
+		t.Fatalf("login = %q, want %q", got, want)
+	}
+
+	client.Heard(3120002) // No position on file
+	client.Heard(9999999) // Unknown station
+	client.Heard(3120001)
+	client.Heard(3120001) // Inside the interval
+	client.Position(3120002, 42.5, -71.25)
+
+	want := []string{
+		"N0CALL>APZDMR,TCPIP*:;W1AW     *091405z4142.88N/07243.63W[DMR 3120001 via dmr-nexus",
+		"N0CALL>APZDMR,TCPIP*:;K1ABC    *091405z4230.00N/07115.00W[DMR 3120002 via dmr-nexus",
+	}
+	for _, w := range want {
+		if got := next(); got != w {
+			t.Errorf("packet =\n%q\nwant\n%q", got, w)
+		}
+	}
+	select {
+	case line := <-lines:
+		t.Errorf("Unexpected extra packet %q", line)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
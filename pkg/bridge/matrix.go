@@ -0,0 +1,104 @@
+package bridge
+
+import "sort"
+
+// BridgeMatrix is an adjacency view of which systems traffic can flow
+// between: Links[i][j] lists the talkgroups on Systems[i] that reach
+// Systems[j]
+type BridgeMatrix struct {
+	Systems []string     `json:"systems"`
+	Links   [][][]uint32 `json:"links"`
+}
+
+// BridgeMatrix builds the matrix from the active static bridge rules and the
+// dynamic bridges with subscribers on more than one system
+func (r *Router) BridgeMatrix() BridgeMatrix {
+	r.mu.RLock()
+	rules := make([]BridgeRuleSetSnapshot, 0, len(r.bridges))
+	for _, bridge := range r.bridges {
+		rules = append(rules, bridge.Snapshot())
+	}
+	systems := make([]string, 0, len(r.systems))
+	for name := range r.systems {
+		systems = append(systems, name)
+	}
+	dynamic := make(map[uint32][]string, len(r.dynamicBridges))
+	for _, bridge := range r.dynamicBridges {
+		bridge.mu.RLock()
+		for peerID := range bridge.Subscribers {
+			if system, ok := r.peerIDToSystemName[peerID]; ok {
+				dynamic[bridge.TGID] = append(dynamic[bridge.TGID], system)
+			}
+		}
+		bridge.mu.RUnlock()
+	}
+	r.mu.RUnlock()
+
+	return buildBridgeMatrix(systems, rules, dynamic)
+}
+
+// buildBridgeMatrix links, within each rule set, every active rule's system
+// and talkgroup to the systems of the set's other active rules, and each
+// dynamic talkgroup between every pair of systems with subscribers on it.
+// systems lists systems to include even if nothing links them.
+func buildBridgeMatrix(systems []string, rules []BridgeRuleSetSnapshot, dynamic map[uint32][]string) BridgeMatrix {
+	links := make(map[string]map[string]map[uint32]bool)
+	names := make(map[string]bool)
+	for _, name := range systems {
+		names[name] = true
+	}
+	link := func(from, to string, tgid uint32) {
+		if from == to {
+			return
+		}
+		if links[from] == nil {
+			links[from] = make(map[string]map[uint32]bool)
+		}
+		if links[from][to] == nil {
+			links[from][to] = make(map[uint32]bool)
+		}
+		links[from][to][tgid] = true
+	}
+
+	for _, set := range rules {
+		for _, from := range set.Rules {
+			names[from.System] = true
+			if !from.Active {
+				continue
+			}
+			for _, to := range set.Rules {
+				if to.Active {
+					link(from.System, to.System, uint32(from.TGID))
+				}
+			}
+		}
+	}
+	for tgid, subscribed := range dynamic {
+		for _, from := range subscribed {
+			names[from] = true
+			for _, to := range subscribed {
+				link(from, to, tgid)
+			}
+		}
+	}
+
+	m := BridgeMatrix{Systems: make([]string, 0, len(names))}
+	for name := range names {
+		m.Systems = append(m.Systems, name)
+	}
+	sort.Strings(m.Systems)
+
+	m.Links = make([][][]uint32, len(m.Systems))
+	for i, from := range m.Systems {
+		m.Links[i] = make([][]uint32, len(m.Systems))
+		for j, to := range m.Systems {
+			tgids := make([]uint32, 0, len(links[from][to]))
+			for tgid := range links[from][to] {
+				tgids = append(tgids, tgid)
+			}
+			sort.Slice(tgids, func(a, b int) bool { return tgids[a] < tgids[b] })
+			m.Links[i][j] = tgids
+		}
+	}
+	return m
+}
@@ -0,0 +1,49 @@
+package bridge
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestRouter_BridgeMatrix(t *testing.T) {
+	router := NewRouter()
+	router.RegisterSystem("LOCAL", func(*protocol.DMRDPacket, []byte) {})
+
+	// NATIONAL links all three systems; REGIONAL's OBP side is switched off
+	national := NewBridgeRuleSet("NATIONAL")
+	national.AddRule(&BridgeRule{System: "MASTER-1", TGID: 3100, Timeslot: 1, Active: true})
+	national.AddRule(&BridgeRule{System: "MASTER-2", TGID: 3100, Timeslot: 2, Active: true})
+	national.AddRule(&BridgeRule{System: "OBP-1", TGID: 31000, Timeslot: 1, Active: true})
+	router.AddBridge(national)
+
+	regional := NewBridgeRuleSet("REGIONAL")
+	regional.AddRule(&BridgeRule{System: "MASTER-1", TGID: 3120, Timeslot: 2, Active: true})
+	regional.AddRule(&BridgeRule{System: "OBP-1", TGID: 3120, Timeslot: 1, Active: false})
+	router.AddBridge(regional)
+
+	// A dynamic talkgroup with subscribers on both masters
+	router.RegisterPeer(312001, "MASTER-1")
+	router.RegisterPeer(312002, "MASTER-2")
+	router.GetOrCreateDynamicBridge(91)
+	router.AddSubscriberToDynamicBridge(91, 312001)
+	router.AddSubscriberToDynamicBridge(91, 312002)
+
+	m := router.BridgeMatrix()
+
+	wantSystems := []string{"LOCAL", "MASTER-1", "MASTER-2", "OBP-1"}
+	if !reflect.DeepEqual(m.Systems, wantSystems) {
+		t.Fatalf("Systems = %v, want %v", m.Systems, wantSystems)
+	}
+	none := []uint32{}
+	want := [][][]uint32{
+		{none, none, none, none},
+		{none, none, {91, 3100}, {3100}},
+		{none, {91, 3100}, none, {3100}},
+		{none, {31000}, {31000}, none},
+	}
+	if !reflect.DeepEqual(m.Links, want) {
+		t.Errorf("Links = %v, want %v", m.Links, want)
+	}
+}
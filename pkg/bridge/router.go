@@ -11,6 +11,24 @@ import (
 // PeerSubscriptionChecker is a function that checks if a peer has a subscription
 type PeerSubscriptionChecker func(peerID uint32, tgid uint32, timeslot int) bool
 
+// SystemSink delivers a packet that was routed to a system from another system
+type SystemSink func(packet *protocol.DMRDPacket, data []byte)
+
+// BridgeChange describes a static bridge rule switching on or off, linking
+// or unlinking its system on a talkgroup
+type BridgeChange struct {
+	Bridge   string
+	System   string
+	TGID     int
+	Timeslot int
+	Active   bool
+	Trigger  uint32 // Talkgroup whose traffic switched the rule
+}
+
+// StreamIdleTimeout is how long a talkgroup's active stream may go without
+// frames before it is considered ended even though no terminator was seen
+const StreamIdleTimeout = 2 * time.Second
+
 // Router manages conference bridge routing between systems
 type Router struct {
 	bridges             map[string]*BridgeRuleSet
@@ -19,9 +37,28 @@ type Router struct {
 	txLogger            *TransmissionLogger
 	subscriptionChecker PeerSubscriptionChecker
 	peerIDToSystemName  map[uint32]string // Maps peer IDs to system names
+	systems             map[string]SystemSink
+	masterSystems       map[string]bool        // Systems registered with RegisterMasterSystem
+	availability        map[string]func() bool // Optional checks of whether a system can deliver now
+	unloggedSystems     map[string]bool        // Source systems whose traffic is not persisted
+	priorities          map[priorityKey]int
+	preempted           map[uint32]*preemptedStream // streamID -> preemption state
+	onBridgeChange      func(BridgeChange)
 	mu                  sync.RWMutex
 }
 
+// priorityKey identifies a stream priority rule; tgid 0 matches any talkgroup
+type priorityKey struct {
+	sourceID uint32
+	tgid     uint32
+}
+
+// preemptedStream tracks a stream that lost its talkgroup to a higher-priority one
+type preemptedStream struct {
+	at       time.Time
+	notified bool // Caller has been told to terminate it downstream
+}
+
 // DynamicBridge represents an automatically created bridge for a talkgroup
 // Bridges are timeslot-agnostic - they track activity and subscribers across both timeslots
 type DynamicBridge struct {
@@ -32,6 +69,7 @@ type DynamicBridge struct {
 	LastActivityTS2 time.Time       // Most recent activity on TS2 (for diagnostics)
 	ActiveRadioID   uint32          // Radio ID currently transmitting (0 if none)
 	ActiveStreamID  uint32          // Active stream ID (0 if none)
+	ActivePriority  int             // Priority of the active stream's source
 	Subscribers     map[uint32]bool // Peer IDs subscribed to this TG on ANY timeslot
 	mu              sync.RWMutex
 }
@@ -43,7 +81,85 @@ func NewRouter() *Router {
 		dynamicBridges:     make(map[string]*DynamicBridge),
 		streamTracker:      NewStreamTracker(),
 		peerIDToSystemName: make(map[uint32]string),
+		systems:            make(map[string]SystemSink),
+		masterSystems:      make(map[string]bool),
+		availability:       make(map[string]func() bool),
+		unloggedSystems:    make(map[string]bool),
+		priorities:         make(map[priorityKey]int),
+		preempted:          make(map[uint32]*preemptedStream),
+	}
+}
+
+// SetStreamPriority sets the priority of a source radio ID on a talkgroup
+// (tgid 0 = all talkgroups). A voice header from a higher-priority source
+// preempts the active stream on a talkgroup instead of being rejected.
+func (r *Router) SetStreamPriority(sourceID, tgid uint32, priority int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.priorities[priorityKey{sourceID: sourceID, tgid: tgid}] = priority
+}
+
+// streamPriority returns the priority for a source on a talkgroup (default 0)
+func (r *Router) streamPriority(sourceID, tgid uint32) int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if prio, ok := r.priorities[priorityKey{sourceID: sourceID, tgid: tgid}]; ok {
+		return prio
+	}
+	return r.priorities[priorityKey{sourceID: sourceID}]
+}
+
+// PreemptedStream reports whether a packet belongs to a stream that was
+// preempted by a higher-priority source or revoked by an operator. notify is
+// true only the first time, so the caller can send a terminator downstream
+// once. The preemption is forgotten when the stream's own terminator arrives.
+func (r *Router) PreemptedStream(packet *protocol.DMRDPacket) (preempted, notify bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ps, ok := r.preempted[packet.StreamID]
+	if !ok {
+		return false, false
+	}
+	notify = !ps.notified
+	ps.notified = true
+	if packet.IsTerminator() {
+		delete(r.preempted, packet.StreamID)
 	}
+	return true, notify
+}
+
+// RevokeStream force-ends an active stream: its talkgroup is freed and the
+// rest of its frames are dropped, with a terminator sent downstream on the
+// next one as for a preempted stream. It reports whether the stream was active.
+func (r *Router) RevokeStream(streamID uint32) bool {
+	if streamID == 0 {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	found := false
+	for _, bridge := range r.dynamicBridges {
+		bridge.mu.Lock()
+		if bridge.ActiveStreamID == streamID {
+			bridge.ActiveRadioID = 0
+			bridge.ActiveStreamID = 0
+			bridge.ActivePriority = 0
+			found = true
+		}
+		bridge.mu.Unlock()
+	}
+	if found {
+		r.preempted[streamID] = &preemptedStream{at: time.Now()}
+	}
+	return found
+}
+
+// SetDedupPolicy sets how a stream arriving from more than one system is deduplicated
+func (r *Router) SetDedupPolicy(policy DedupPolicy) {
+	r.streamTracker.SetPolicy(policy)
 }
 
 // SetSubscriptionChecker sets the function to check peer subscriptions
@@ -60,6 +176,52 @@ func (r *Router) SetTransmissionLogger(logger *TransmissionLogger) {
 	r.txLogger = logger
 }
 
+// SetTransmissionLogging enables or disables persisting transmissions that
+// originate from a system. Logging is enabled for all systems by default.
+func (r *Router) SetTransmissionLogging(system string, enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if enabled {
+		delete(r.unloggedSystems, system)
+	} else {
+		r.unloggedSystems[system] = true
+	}
+}
+
+// SetBridgeChangeHandler sets the callback run each time ProcessActivation
+// or ProcessDeactivation switches a rule's state. Rules that were already
+// in the requested state don't fire it.
+func (r *Router) SetBridgeChangeHandler(fn func(BridgeChange)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onBridgeChange = fn
+}
+
+// notifyBridgeChanges runs the bridge change handler for each changed rule
+func notifyBridgeChanges(fn func(BridgeChange), changed map[string][]*BridgeRule, active bool, trigger uint32) {
+	if fn == nil {
+		return
+	}
+	names := make([]string, 0, len(changed))
+	for name := range changed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, rule := range changed[name] {
+			fn(BridgeChange{
+				Bridge:   name,
+				System:   rule.System,
+				TGID:     rule.TGID,
+				Timeslot: rule.Timeslot,
+				Active:   active,
+				Trigger:  trigger,
+			})
+		}
+	}
+}
+
 // RegisterPeer registers a peer ID to system name mapping
 func (r *Router) RegisterPeer(peerID uint32, systemName string) {
 	r.mu.Lock()
@@ -74,6 +236,89 @@ func (r *Router) UnregisterPeer(peerID uint32) {
 	delete(r.peerIDToSystemName, peerID)
 }
 
+// RegisterSystem registers the sink used to deliver packets routed to a system
+func (r *Router) RegisterSystem(name string, sink SystemSink) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.systems[name] = sink
+}
+
+// RegisterMasterSystem registers a MASTER system's sink. MASTER systems
+// share one peer manager, so a frame one of them delivers locally already
+// reaches the subscribers of the others.
+func (r *Router) RegisterMasterSystem(name string, sink SystemSink) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.systems[name] = sink
+	r.masterSystems[name] = true
+}
+
+// IsMasterSystem reports whether a system was registered as a MASTER
+func (r *Router) IsMasterSystem(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.masterSystems[name]
+}
+
+// SetSystemAvailability sets how a registered system reports whether it can
+// deliver right now, e.g. whether its link is up
+func (r *Router) SetSystemAvailability(name string, available func() bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.availability[name] = available
+}
+
+// UnregisterSystem removes a system's sink
+func (r *Router) UnregisterSystem(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.systems, name)
+	delete(r.masterSystems, name)
+	delete(r.availability, name)
+}
+
+// ForwardToSystems delivers a packet to the sinks of the given systems.
+// Returns the number of systems the packet was delivered to.
+func (r *Router) ForwardToSystems(targets []string, packet *protocol.DMRDPacket, data []byte) int {
+	r.mu.RLock()
+	sinks := make([]SystemSink, 0, len(targets))
+	for _, target := range targets {
+		if sink, ok := r.systems[target]; ok {
+			sinks = append(sinks, sink)
+		}
+	}
+	r.mu.RUnlock()
+
+	for _, sink := range sinks {
+		sink(packet, data)
+	}
+	return len(sinks)
+}
+
+// UnavailableSystems returns the targets that can't deliver, in the order
+// given: systems with no registered sink (disabled or not running) and those
+// whose availability check fails, such as a MASTER with no peers connected
+// or a PEER whose link to its master is down
+func (r *Router) UnavailableSystems(targets []string) []string {
+	r.mu.RLock()
+	registered := make([]bool, len(targets))
+	checks := make([]func() bool, len(targets))
+	for i, target := range targets {
+		_, registered[i] = r.systems[target]
+		checks[i] = r.availability[target]
+	}
+	r.mu.RUnlock()
+
+	// Checks take the systems' own locks, so they run outside the router's
+	var down []string
+	for i, target := range targets {
+		if !registered[i] || (checks[i] != nil && !checks[i]()) {
+			down = append(down, target)
+		}
+	}
+	return down
+}
+
 // AddBridge adds a bridge rule set to the router
 func (r *Router) AddBridge(bridge *BridgeRuleSet) {
 	r.mu.Lock()
@@ -91,10 +336,16 @@ func (r *Router) GetBridge(name string) *BridgeRuleSet {
 // RoutePacket routes a DMR packet based on bridge rules and peer subscriptions
 // Returns a list of target systems to forward the packet to
 func (r *Router) RoutePacket(packet *protocol.DMRDPacket, sourceSystem string) []string {
-	// Log the transmission if logger is configured
-	if r.txLogger != nil {
-		isTerminator := packet.FrameType == protocol.FrameTypeVoiceTerminator
-		r.txLogger.LogPacket(
+	// Log the transmission if logger is configured and the source system logs
+	r.mu.RLock()
+	txLogger := r.txLogger
+	if r.unloggedSystems[sourceSystem] {
+		txLogger = nil
+	}
+	r.mu.RUnlock()
+	if txLogger != nil {
+		isTerminator := packet.IsTerminator()
+		txLogger.LogPacket(
 			packet.StreamID,
 			packet.SourceID,
 			packet.DestinationID,
@@ -105,9 +356,22 @@ func (r *Router) RoutePacket(packet *protocol.DMRDPacket, sourceSystem string) [
 	}
 
 	// Check if this is a terminator frame
-	isTerminator := packet.FrameType == protocol.FrameTypeVoiceTerminator
+	isTerminator := packet.IsTerminator()
 	isVoiceHeader := packet.FrameType == protocol.FrameTypeVoiceHeader
 
+	// Frames of a preempted stream are no longer routed
+	r.mu.RLock()
+	_, wasPreempted := r.preempted[packet.StreamID]
+	r.mu.RUnlock()
+	if wasPreempted {
+		return []string{}
+	}
+
+	priority := 0
+	if isVoiceHeader {
+		priority = r.streamPriority(packet.SourceID, packet.DestinationID)
+	}
+
 	// Update LastActivity on the dynamic bridge for this talkgroup
 	// This allows the UI to show the bridge as "active" (red) during transmissions
 	// Track both overall activity and per-timeslot activity
@@ -117,15 +381,31 @@ func (r *Router) RoutePacket(packet *protocol.DMRDPacket, sourceSystem string) [
 	bridge, bridgeExists := r.dynamicBridges[key]
 	r.mu.RUnlock()
 
+	// Recorded once bridge.mu is released: r.mu is always taken before a
+	// bridge's lock, never after
+	var preemptedID uint32
+
 	if bridgeExists {
 		bridge.mu.Lock()
 
+		// A stream whose terminator never arrived ends once the talkgroup goes idle
+		if bridge.ActiveStreamID != 0 && time.Since(bridge.LastActivity) > StreamIdleTimeout {
+			bridge.ActiveRadioID = 0
+			bridge.ActiveStreamID = 0
+			bridge.ActivePriority = 0
+		}
+
 		// SINGLE-STREAM ENFORCEMENT: Check if there's already an active stream for this talkgroup
 		if isVoiceHeader && bridge.ActiveStreamID != 0 && bridge.ActiveStreamID != packet.StreamID {
-			// Another stream is already active on this talkgroup - reject this one
-			bridge.mu.Unlock()
-			// Don't route this packet - another stream is already active
-			return []string{}
+			if priority <= bridge.ActivePriority {
+				// Another stream is already active on this talkgroup - reject this one
+				bridge.mu.Unlock()
+				// Don't route this packet - another stream is already active
+				return []string{}
+			}
+
+			// Higher-priority source takes over; the old stream is cut off
+			preemptedID = bridge.ActiveStreamID
 		}
 
 		now := time.Now()
@@ -140,15 +420,24 @@ func (r *Router) RoutePacket(packet *protocol.DMRDPacket, sourceSystem string) [
 		if isVoiceHeader {
 			bridge.ActiveRadioID = packet.SourceID
 			bridge.ActiveStreamID = packet.StreamID
-		} else if isTerminator {
-			// Clear active transmission on terminator
+			bridge.ActivePriority = priority
+		} else if isTerminator && bridge.ActiveStreamID == packet.StreamID {
+			// Clear active transmission on terminator; a terminator for a stream
+			// whose header was never seen leaves the current talker in place
 			bridge.ActiveRadioID = 0
 			bridge.ActiveStreamID = 0
+			bridge.ActivePriority = 0
 		}
 
 		bridge.mu.Unlock()
 	}
 
+	if preemptedID != 0 {
+		r.mu.Lock()
+		r.preempted[preemptedID] = &preemptedStream{at: time.Now()}
+		r.mu.Unlock()
+	}
+
 	// End the stream after processing terminator
 	defer func() {
 		if isTerminator {
@@ -162,6 +451,17 @@ func (r *Router) RoutePacket(packet *protocol.DMRDPacket, sourceSystem string) [
 		return []string{}
 	}
 
+	return r.matchTargets(packet, sourceSystem)
+}
+
+// HasRoute reports whether a packet's talkgroup matches a static bridge rule
+// or a dynamic subscription on another system, without tracking the stream
+func (r *Router) HasRoute(packet *protocol.DMRDPacket, sourceSystem string) bool {
+	return len(r.matchTargets(packet, sourceSystem)) > 0
+}
+
+// matchTargets returns the systems a packet's talkgroup is routed to
+func (r *Router) matchTargets(packet *protocol.DMRDPacket, sourceSystem string) []string {
 	// Find matching bridge rules across all bridges
 	targets := make([]string, 0)
 	targetSet := make(map[string]bool) // Use set to avoid duplicates
@@ -200,21 +500,76 @@ func (r *Router) RoutePacket(packet *protocol.DMRDPacket, sourceSystem string) [
 	return targets
 }
 
+// RoutePrivateCall routes a private (unit-to-unit) call across static bridges.
+// A private call has no talkgroup to match, so it is offered to every system
+// linked to the source system by an active rule in any bridge. Systems that
+// have already sent us this stream are excluded so a call is never bounced
+// back across the bridge it arrived on.
+func (r *Router) RoutePrivateCall(packet *protocol.DMRDPacket, sourceSystem string) []string {
+	isTerminator := packet.IsTerminator()
+	defer func() {
+		if isTerminator {
+			r.streamTracker.EndStream(packet.StreamID)
+		}
+	}()
+
+	seen := make(map[string]bool)
+	for _, system := range r.streamTracker.GetStreamSystems(packet.StreamID) {
+		seen[system] = true
+	}
+	r.streamTracker.TrackStream(packet.StreamID, sourceSystem)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	targetSet := make(map[string]bool)
+	for _, bridge := range r.bridges {
+		if len(bridge.GetRulesForSystem(sourceSystem)) == 0 {
+			continue
+		}
+		bridge.mu.RLock()
+		for _, rule := range bridge.Rules {
+			if rule.System == sourceSystem || seen[rule.System] {
+				continue
+			}
+			rule.mu.RLock()
+			active := rule.Active
+			rule.mu.RUnlock()
+			if active {
+				targetSet[rule.System] = true
+			}
+		}
+		bridge.mu.RUnlock()
+	}
+
+	targets := make([]string, 0, len(targetSet))
+	for target := range targetSet {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	return targets
+}
+
 // ProcessActivation processes activation for the given TGID across all bridges
 // Returns a map of bridge names to lists of activated rules
 func (r *Router) ProcessActivation(tgid uint32) map[string][]*BridgeRule {
 	r.mu.RLock()
-	defer r.mu.RUnlock()
-
 	result := make(map[string][]*BridgeRule)
-
+	changed := make(map[string][]*BridgeRule)
 	for name, bridge := range r.bridges {
-		activated := bridge.ProcessActivation(tgid)
+		activated, switched := bridge.processActivation(tgid)
 		if len(activated) > 0 {
 			result[name] = activated
 		}
+		if len(switched) > 0 {
+			changed[name] = switched
+		}
 	}
+	fn := r.onBridgeChange
+	r.mu.RUnlock()
 
+	notifyBridgeChanges(fn, changed, true, tgid)
 	return result
 }
 
@@ -222,17 +577,21 @@ func (r *Router) ProcessActivation(tgid uint32) map[string][]*BridgeRule {
 // Returns a map of bridge names to lists of deactivated rules
 func (r *Router) ProcessDeactivation(tgid uint32) map[string][]*BridgeRule {
 	r.mu.RLock()
-	defer r.mu.RUnlock()
-
 	result := make(map[string][]*BridgeRule)
-
+	changed := make(map[string][]*BridgeRule)
 	for name, bridge := range r.bridges {
-		deactivated := bridge.ProcessDeactivation(tgid)
+		deactivated, switched := bridge.processDeactivation(tgid)
 		if len(deactivated) > 0 {
 			result[name] = deactivated
 		}
+		if len(switched) > 0 {
+			changed[name] = switched
+		}
 	}
+	fn := r.onBridgeChange
+	r.mu.RUnlock()
 
+	notifyBridgeChanges(fn, changed, false, tgid)
 	return result
 }
 
@@ -265,9 +624,24 @@ func (r *Router) GetActiveBridges() []*BridgeRuleSet {
 	return result
 }
 
+// ActiveStreamCount returns the number of streams the router is tracking
+func (r *Router) ActiveStreamCount() int {
+	return r.streamTracker.Count()
+}
+
 // CleanupStreams removes old streams from the tracker
 func (r *Router) CleanupStreams(maxAge time.Duration) {
 	r.streamTracker.CleanupOldStreams(maxAge)
+
+	// Forget preempted streams that never sent their terminator
+	cutoff := time.Now().Add(-maxAge)
+	r.mu.Lock()
+	for streamID, ps := range r.preempted {
+		if ps.at.Before(cutoff) {
+			delete(r.preempted, streamID)
+		}
+	}
+	r.mu.Unlock()
 }
 
 // GetOrCreateDynamicBridge gets or creates a dynamic bridge for a talkgroup
@@ -399,6 +773,13 @@ func (r *Router) GetDynamicBridgeSubscribers(tgid uint32) []uint32 {
 	return result
 }
 
+// DynamicBridgeCount returns the number of dynamic bridges
+func (r *Router) DynamicBridgeCount() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.dynamicBridges)
+}
+
 // GetAllDynamicBridges returns a snapshot of all dynamic bridges sorted by TGID
 func (r *Router) GetAllDynamicBridges() []*DynamicBridge {
 	r.mu.RLock()
@@ -1,8 +1,12 @@
 package bridge
 
 import (
+	"sort"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/dbehnke/dmr-nexus/pkg/config"
 	"github.com/dbehnke/dmr-nexus/pkg/protocol"
 )
 
@@ -90,6 +94,37 @@ func TestRouter_RoutePacket(t *testing.T) {
 	}
 }
 
+func TestRouter_RoutePacket_GroupMesh(t *testing.T) {
+	// The rules a bridge group of three systems expands to for one talkgroup
+	router := NewRouter()
+	router.AddBridge(NewBridgeRuleSetFromConfig("region-3100", []config.BridgeRule{
+		{System: "A", TGID: 3100, Timeslot: 1, Active: true},
+		{System: "B", TGID: 3100, Timeslot: 1, Active: true},
+		{System: "C", TGID: 3100, Timeslot: 1, Active: true},
+	}))
+
+	want := map[string][]string{
+		"A": {"B", "C"},
+		"B": {"A", "C"},
+		"C": {"A", "B"},
+	}
+	streamID := uint32(100)
+	for source, expected := range want {
+		streamID++
+		packet := &protocol.DMRDPacket{SourceID: 3120001, DestinationID: 3100, Timeslot: 1, StreamID: streamID}
+		targets := router.RoutePacket(packet, source)
+		sort.Strings(targets)
+		if len(targets) != len(expected) || targets[0] != expected[0] || targets[1] != expected[1] {
+			t.Errorf("From %s: targets %v, want %v", source, targets, expected)
+		}
+	}
+
+	packet := &protocol.DMRDPacket{SourceID: 3120001, DestinationID: 3100, Timeslot: 2, StreamID: 200}
+	if targets := router.RoutePacket(packet, "A"); len(targets) != 0 {
+		t.Errorf("Other timeslot should not route, got %v", targets)
+	}
+}
+
 func TestRouter_RoutePacket_NoMatch(t *testing.T) {
 	router := NewRouter()
 
@@ -166,6 +201,49 @@ func TestRouter_RoutePacket_DuplicateStream(t *testing.T) {
 	}
 }
 
+func TestRouter_RoutePacket_SecondPathByPolicy(t *testing.T) {
+	newRouter := func(policy DedupPolicy) *Router {
+		router := NewRouter()
+		router.SetDedupPolicy(policy)
+		bridge := NewBridgeRuleSet("NATIONWIDE")
+		for _, system := range []string{"SYSTEM1", "SYSTEM2", "SYSTEM3"} {
+			bridge.AddRule(&BridgeRule{System: system, TGID: 3100, Timeslot: 1, Active: true})
+		}
+		router.AddBridge(bridge)
+		return router
+	}
+
+	packet := &protocol.DMRDPacket{
+		SourceID:      3120001,
+		DestinationID: 3100,
+		RepeaterID:    312000,
+		Timeslot:      1,
+		CallType:      protocol.CallTypeGroup,
+		StreamID:      54321,
+	}
+
+	// Per-source: the stream arriving via SYSTEM2 is a second path and routes
+	router := newRouter(DedupPerSource)
+	if targets := router.RoutePacket(packet, "SYSTEM1"); len(targets) != 2 {
+		t.Fatalf("Expected 2 targets from SYSTEM1, got %d", len(targets))
+	}
+	if targets := router.RoutePacket(packet, "SYSTEM2"); len(targets) != 2 {
+		t.Errorf("Expected second path via SYSTEM2 to route to 2 targets, got %d", len(targets))
+	}
+	if targets := router.RoutePacket(packet, "SYSTEM2"); len(targets) != 0 {
+		t.Errorf("Expected true duplicate from SYSTEM2 to be dropped, got %d targets", len(targets))
+	}
+
+	// First-wins: SYSTEM1 owns the stream, so the copy via SYSTEM2 is a loop
+	router = newRouter(DedupFirstWins)
+	if targets := router.RoutePacket(packet, "SYSTEM1"); len(targets) != 2 {
+		t.Fatalf("Expected 2 targets from SYSTEM1, got %d", len(targets))
+	}
+	if targets := router.RoutePacket(packet, "SYSTEM2"); len(targets) != 0 {
+		t.Errorf("Expected copy via SYSTEM2 to be dropped under first_wins, got %d targets", len(targets))
+	}
+}
+
 func TestRouter_RoutePacket_StreamTerminator(t *testing.T) {
 	router := NewRouter()
 
@@ -206,6 +284,7 @@ func TestRouter_RoutePacket_StreamTerminator(t *testing.T) {
 
 	// Create a voice terminator packet
 	packet.FrameType = protocol.FrameTypeVoiceTerminator
+	packet.DataType = protocol.DataTypeTerminatorLC
 
 	// Route terminator - should route and end stream
 	targets = router.RoutePacket(packet, "SYSTEM1")
@@ -337,6 +416,31 @@ func TestRouter_CleanupStreams(t *testing.T) {
 	}
 }
 
+func TestRouter_ActiveStreamCount(t *testing.T) {
+	router := NewRouter()
+	bridge := NewBridgeRuleSet("NATIONWIDE")
+	bridge.AddRule(&BridgeRule{System: "SYSTEM1", TGID: 3100, Timeslot: 1, Active: true})
+	router.AddBridge(bridge)
+
+	for _, streamID := range []uint32{1001, 1002} {
+		router.RoutePacket(&protocol.DMRDPacket{
+			SourceID:      3120001,
+			DestinationID: 3100,
+			Timeslot:      1,
+			CallType:      protocol.CallTypeGroup,
+			StreamID:      streamID,
+		}, "SYSTEM1")
+	}
+	if n := router.ActiveStreamCount(); n != 2 {
+		t.Errorf("Expected 2 active streams, got %d", n)
+	}
+
+	router.CleanupStreams(0)
+	if n := router.ActiveStreamCount(); n != 0 {
+		t.Errorf("Expected no active streams after cleanup, got %d", n)
+	}
+}
+
 func TestRouter_RoutePacket_WithPeerSubscriptions(t *testing.T) {
 	router := NewRouter()
 
@@ -589,3 +693,342 @@ func TestRouter_GetAllDynamicBridges_Sorted(t *testing.T) {
 		}
 	}
 }
+
+func TestRouter_RoutePrivateCall(t *testing.T) {
+	router := NewRouter()
+
+	bridge := NewBridgeRuleSet("NATIONWIDE")
+	bridge.AddRule(&BridgeRule{System: "SYSTEM1", TGID: 3100, Timeslot: 1, Active: true})
+	bridge.AddRule(&BridgeRule{System: "SYSTEM2", TGID: 3100, Timeslot: 2, Active: true})
+	bridge.AddRule(&BridgeRule{System: "SYSTEM3", TGID: 3100, Timeslot: 1, Active: false})
+	router.AddBridge(bridge)
+
+	unrelated := NewBridgeRuleSet("REGIONAL")
+	unrelated.AddRule(&BridgeRule{System: "SYSTEM4", TGID: 3120, Timeslot: 1, Active: true})
+	unrelated.AddRule(&BridgeRule{System: "SYSTEM5", TGID: 3120, Timeslot: 1, Active: true})
+	router.AddBridge(unrelated)
+
+	packet := &protocol.DMRDPacket{
+		SourceID:      3120001,
+		DestinationID: 3120002,
+		Timeslot:      1,
+		CallType:      protocol.CallTypePrivate,
+		StreamID:      5555,
+	}
+
+	targets := router.RoutePrivateCall(packet, "SYSTEM1")
+	if len(targets) != 1 || targets[0] != "SYSTEM2" {
+		t.Fatalf("Expected [SYSTEM2], got %v", targets)
+	}
+
+	// The same stream arriving back from SYSTEM2 must not bounce to SYSTEM1
+	targets = router.RoutePrivateCall(packet, "SYSTEM2")
+	if len(targets) != 0 {
+		t.Errorf("Expected no targets for bounced stream, got %v", targets)
+	}
+}
+
+func TestRouter_ForwardToSystems(t *testing.T) {
+	router := NewRouter()
+
+	received := make(map[string]int)
+	router.RegisterSystem("SYSTEM1", func(_ *protocol.DMRDPacket, _ []byte) { received["SYSTEM1"]++ })
+	router.RegisterSystem("SYSTEM2", func(_ *protocol.DMRDPacket, _ []byte) { received["SYSTEM2"]++ })
+
+	packet := &protocol.DMRDPacket{StreamID: 1}
+	delivered := router.ForwardToSystems([]string{"SYSTEM2", "MISSING"}, packet, nil)
+
+	if delivered != 1 {
+		t.Errorf("Expected 1 delivery, got %d", delivered)
+	}
+	if received["SYSTEM2"] != 1 || received["SYSTEM1"] != 0 {
+		t.Errorf("Unexpected deliveries: %v", received)
+	}
+
+	router.UnregisterSystem("SYSTEM2")
+	if delivered := router.ForwardToSystems([]string{"SYSTEM2"}, packet, nil); delivered != 0 {
+		t.Errorf("Expected 0 deliveries after unregister, got %d", delivered)
+	}
+}
+
+func TestRouter_RegisterMasterSystem(t *testing.T) {
+	router := NewRouter()
+
+	delivered := 0
+	router.RegisterMasterSystem("MASTER-1", func(_ *protocol.DMRDPacket, _ []byte) { delivered++ })
+	router.RegisterSystem("UPSTREAM", func(_ *protocol.DMRDPacket, _ []byte) {})
+
+	if !router.IsMasterSystem("MASTER-1") || router.IsMasterSystem("UPSTREAM") {
+		t.Error("Expected only MASTER-1 marked as a MASTER")
+	}
+	if router.ForwardToSystems([]string{"MASTER-1"}, &protocol.DMRDPacket{}, nil); delivered != 1 {
+		t.Errorf("Expected a MASTER's sink to be used, got %d deliveries", delivered)
+	}
+
+	router.UnregisterSystem("MASTER-1")
+	if router.IsMasterSystem("MASTER-1") {
+		t.Error("Expected the MASTER mark cleared on unregister")
+	}
+}
+
+func TestRouter_UnavailableSystems(t *testing.T) {
+	router := NewRouter()
+	sink := func(_ *protocol.DMRDPacket, _ []byte) {}
+	router.RegisterSystem("SYSTEM1", sink)
+	router.RegisterSystem("SYSTEM2", sink)
+
+	linkUp := false
+	router.SetSystemAvailability("SYSTEM2", func() bool { return linkUp })
+
+	down := router.UnavailableSystems([]string{"SYSTEM1", "SYSTEM2", "MISSING"})
+	if len(down) != 2 || down[0] != "SYSTEM2" || down[1] != "MISSING" {
+		t.Fatalf("Expected SYSTEM2 and MISSING unavailable, got %v", down)
+	}
+
+	linkUp = true
+	if down := router.UnavailableSystems([]string{"SYSTEM1", "SYSTEM2"}); len(down) != 0 {
+		t.Errorf("Expected every system available once the link is up, got %v", down)
+	}
+}
+
+func TestRouter_TerminatorFormsClearActiveStream(t *testing.T) {
+	router := NewRouter()
+	bridge := router.GetOrCreateDynamicBridge(3100)
+
+	frame := func(streamID uint32, frameType, dataType byte) *protocol.DMRDPacket {
+		return &protocol.DMRDPacket{
+			SourceID:      3120000 + streamID,
+			DestinationID: 3100,
+			Timeslot:      1,
+			CallType:      protocol.CallTypeGroup,
+			StreamID:      streamID,
+			FrameType:     frameType,
+			DataType:      dataType,
+		}
+	}
+
+	const streamID = 12
+	router.RoutePacket(frame(streamID, protocol.FrameTypeVoiceHeader, 0), "SYSTEM1")
+	if bridge.ActiveStreamID != streamID {
+		t.Fatalf("Expected stream %d active, got %d", streamID, bridge.ActiveStreamID)
+	}
+
+	// A PI header or a CSBK on the data sync frame type is not the end of the call
+	for _, dataType := range []byte{protocol.DataTypePIHeader, 0x03} {
+		router.RoutePacket(frame(streamID, protocol.FrameTypeVoiceTerminator, dataType), "SYSTEM1")
+		if bridge.ActiveStreamID != streamID {
+			t.Fatalf("Data type %d cleared stream %d", dataType, streamID)
+		}
+	}
+
+	router.RoutePacket(frame(streamID, protocol.FrameTypeVoiceTerminator, protocol.DataTypeTerminatorLC), "SYSTEM1")
+	if bridge.ActiveStreamID != 0 {
+		t.Error("Terminator with LC did not clear the active stream")
+	}
+
+	// Missing terminator: the next caller gets the talkgroup once it is idle
+	router.RoutePacket(frame(20, protocol.FrameTypeVoiceHeader, 0), "SYSTEM1")
+	router.RoutePacket(frame(21, protocol.FrameTypeVoiceHeader, 0), "SYSTEM1")
+	if bridge.ActiveStreamID != 20 {
+		t.Fatalf("Expected stream 20 to hold the talkgroup, got %d", bridge.ActiveStreamID)
+	}
+	bridge.mu.Lock()
+	bridge.LastActivity = time.Now().Add(-StreamIdleTimeout - time.Second)
+	bridge.mu.Unlock()
+	router.RoutePacket(frame(21, protocol.FrameTypeVoiceHeader, 0), "SYSTEM1")
+	if bridge.ActiveStreamID != 21 {
+		t.Errorf("Expected idle stream 20 to give way to 21, got %d", bridge.ActiveStreamID)
+	}
+}
+
+func TestRouter_HeaderlessTerminator(t *testing.T) {
+	router := NewRouter()
+	bridge := router.GetOrCreateDynamicBridge(3100)
+
+	frame := func(streamID uint32, frameType, dataType byte) *protocol.DMRDPacket {
+		return &protocol.DMRDPacket{
+			SourceID:      3120000 + streamID,
+			DestinationID: 3100,
+			Timeslot:      1,
+			CallType:      protocol.CallTypeGroup,
+			StreamID:      streamID,
+			FrameType:     frameType,
+			DataType:      dataType,
+		}
+	}
+
+	// Only a terminator for a stream nobody saw start
+	router.RoutePacket(frame(40, protocol.FrameTypeVoiceTerminator, protocol.DataTypeTerminatorLC), "SYSTEM1")
+	if bridge.ActiveStreamID != 0 || bridge.ActiveRadioID != 0 {
+		t.Fatalf("Headerless terminator set active stream %d radio %d", bridge.ActiveStreamID, bridge.ActiveRadioID)
+	}
+	if router.streamTracker.IsActive(40) {
+		t.Error("Headerless terminator left stream 40 tracked")
+	}
+
+	// While another stream holds the talkgroup, the stray terminator is a no-op
+	router.RoutePacket(frame(41, protocol.FrameTypeVoiceHeader, 0), "SYSTEM1")
+	router.RoutePacket(frame(40, protocol.FrameTypeVoiceTerminator, protocol.DataTypeTerminatorLC), "SYSTEM1")
+	if bridge.ActiveStreamID != 41 {
+		t.Fatalf("Stray terminator cleared active stream, got %d", bridge.ActiveStreamID)
+	}
+	router.RoutePacket(frame(42, protocol.FrameTypeVoiceHeader, 0), "SYSTEM1")
+	if bridge.ActiveStreamID != 41 {
+		t.Errorf("Expected competing stream to stay blocked, got %d", bridge.ActiveStreamID)
+	}
+
+	router.RoutePacket(frame(41, protocol.FrameTypeVoiceTerminator, protocol.DataTypeTerminatorLC), "SYSTEM1")
+	if bridge.ActiveStreamID != 0 {
+		t.Errorf("Own terminator did not clear stream 41, got %d", bridge.ActiveStreamID)
+	}
+}
+
+func TestRouter_StreamPriorityPreemption(t *testing.T) {
+	router := NewRouter()
+	router.SetStreamPriority(3100001, 9911, 10)
+	bridge := router.GetOrCreateDynamicBridge(9911)
+
+	header := func(src, streamID uint32) *protocol.DMRDPacket {
+		return &protocol.DMRDPacket{
+			SourceID:      src,
+			DestinationID: 9911,
+			Timeslot:      2,
+			CallType:      protocol.CallTypeGroup,
+			StreamID:      streamID,
+			FrameType:     protocol.FrameTypeVoiceHeader,
+		}
+	}
+
+	// Low-priority stream takes the talkgroup
+	router.RoutePacket(header(3120001, 100), "SYSTEM1")
+	if bridge.ActiveStreamID != 100 {
+		t.Fatalf("Expected stream 100 active, got %d", bridge.ActiveStreamID)
+	}
+
+	// Another default-priority stream is still rejected
+	router.RoutePacket(header(3120002, 200), "SYSTEM1")
+	if bridge.ActiveStreamID != 100 {
+		t.Fatalf("Equal-priority stream must not preempt, active %d", bridge.ActiveStreamID)
+	}
+	if preempted, _ := router.PreemptedStream(header(3120001, 100)); preempted {
+		t.Fatal("Stream 100 should not be preempted yet")
+	}
+
+	// High-priority net control takes over
+	router.RoutePacket(header(3100001, 300), "SYSTEM1")
+	if bridge.ActiveStreamID != 300 || bridge.ActivePriority != 10 {
+		t.Fatalf("Expected stream 300 active at priority 10, got %d/%d", bridge.ActiveStreamID, bridge.ActivePriority)
+	}
+
+	// Remaining frames of the preempted stream are dropped; notify only once
+	voice := &protocol.DMRDPacket{SourceID: 3120001, DestinationID: 9911, Timeslot: 2, StreamID: 100, FrameType: protocol.FrameTypeVoice}
+	if preempted, notify := router.PreemptedStream(voice); !preempted || !notify {
+		t.Errorf("Expected first preempted frame to notify, got preempted=%v notify=%v", preempted, notify)
+	}
+	if preempted, notify := router.PreemptedStream(voice); !preempted || notify {
+		t.Errorf("Expected later frames preempted without notify, got preempted=%v notify=%v", preempted, notify)
+	}
+	if targets := router.RoutePacket(voice, "SYSTEM1"); len(targets) != 0 {
+		t.Errorf("Expected no targets for preempted stream, got %v", targets)
+	}
+
+	// The preempted stream's terminator clears its state
+	term := *voice
+	term.FrameType = protocol.FrameTypeVoiceTerminator
+	term.DataType = protocol.DataTypeTerminatorLC
+	router.PreemptedStream(&term)
+	if preempted, _ := router.PreemptedStream(voice); preempted {
+		t.Error("Preemption state should be cleared after terminator")
+	}
+	if bridge.ActiveStreamID != 300 {
+		t.Errorf("High-priority stream should still be active, got %d", bridge.ActiveStreamID)
+	}
+}
+
+func TestRouter_PreemptionConcurrentWithSnapshots(t *testing.T) {
+	// Run with -race: preempting takes the router lock, which must never be
+	// done while holding a bridge lock that GetAllDynamicBridges waits on
+	router := NewRouter()
+	router.SetStreamPriority(3100001, 9911, 10)
+	router.GetOrCreateDynamicBridge(9911)
+
+	packet := func(src, streamID uint32, frameType byte) *protocol.DMRDPacket {
+		return &protocol.DMRDPacket{
+			SourceID:      src,
+			DestinationID: 9911,
+			Timeslot:      2,
+			CallType:      protocol.CallTypeGroup,
+			StreamID:      streamID,
+			FrameType:     frameType,
+			DataType:      protocol.DataTypeTerminatorLC,
+		}
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				router.GetAllDynamicBridges()
+				router.CleanupInactiveDynamicBridges(time.Hour, func(uint32) int { return 1 })
+			}
+		}
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := uint32(0); i < 500; i++ {
+			low, high := 1000+2*i, 1001+2*i
+			router.RoutePacket(packet(3120001, low, protocol.FrameTypeVoiceHeader), "SYSTEM1")
+			router.RoutePacket(packet(3100001, high, protocol.FrameTypeVoiceHeader), "SYSTEM1")
+			router.RoutePacket(packet(3100001, high, protocol.FrameTypeVoiceTerminator), "SYSTEM1")
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("RoutePacket deadlocked against GetAllDynamicBridges")
+	}
+	close(stop)
+	wg.Wait()
+
+	if preempted, _ := router.PreemptedStream(packet(3120001, 1000, protocol.FrameTypeVoice)); !preempted {
+		t.Error("Expected the low-priority stream preempted")
+	}
+}
+
+func TestRouter_BridgeChangeHandler(t *testing.T) {
+	router := NewRouter()
+
+	bridge := NewBridgeRuleSet("NATIONWIDE")
+	bridge.AddRule(&BridgeRule{System: "SYSTEM1", TGID: 3100, Timeslot: 1, On: []int{3100}, Off: []int{3101}})
+	bridge.AddRule(&BridgeRule{System: "SYSTEM2", TGID: 3100, Timeslot: 2, Active: true, On: []int{3100}})
+	router.AddBridge(bridge)
+
+	var changes []BridgeChange
+	router.SetBridgeChangeHandler(func(change BridgeChange) {
+		changes = append(changes, change)
+	})
+
+	// Every frame of the transmission runs activation; only the first switches the rule
+	router.ProcessActivation(3100)
+	router.ProcessActivation(3100)
+	want := BridgeChange{Bridge: "NATIONWIDE", System: "SYSTEM1", TGID: 3100, Timeslot: 1, Active: true, Trigger: 3100}
+	if len(changes) != 1 || changes[0] != want {
+		t.Fatalf("Expected one activation %+v, got %+v", want, changes)
+	}
+
+	router.ProcessDeactivation(3101)
+	router.ProcessDeactivation(3101)
+	want.Active, want.Trigger = false, 3101
+	if len(changes) != 2 || changes[1] != want {
+		t.Fatalf("Expected one deactivation %+v, got %+v", want, changes)
+	}
+}
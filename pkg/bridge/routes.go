@@ -0,0 +1,109 @@
+package bridge
+
+import (
+	"sort"
+)
+
+// RouteEntry is one row of the effective routing table: traffic for TGID on
+// Timeslot is sent to Systems (static bridge rules) and Peers (dynamic subscribers)
+type RouteEntry struct {
+	TGID     uint32   `json:"tgid"`
+	Timeslot int      `json:"timeslot"`
+	Systems  []string `json:"systems"`
+	Peers    []uint32 `json:"peers"`
+}
+
+// BuildRouteTable derives the effective routing table from static bridge
+// snapshots and the talkgroups that have dynamic bridges. It mirrors the
+// forwarding decision: active static rules match on TGID and timeslot, while
+// dynamic bridges are timeslot-agnostic and reach every subscriber of the
+// talkgroup on either slot. subscribers returns the peers subscribed to a
+// talkgroup and may be nil. The source system is excluded at routing time, so
+// entries list every system on the route. Entries are sorted by TGID, then timeslot.
+func BuildRouteTable(bridges []BridgeRuleSetSnapshot, dynamicTGIDs []uint32, subscribers func(tgid uint32) []uint32) []RouteEntry {
+	type routeKey struct {
+		tgid     uint32
+		timeslot int
+	}
+	systems := make(map[routeKey]map[string]bool)
+	peers := make(map[routeKey][]uint32)
+
+	for _, br := range bridges {
+		for _, rule := range br.Rules {
+			if !rule.Active {
+				continue
+			}
+			key := routeKey{tgid: uint32(rule.TGID), timeslot: rule.Timeslot}
+			if systems[key] == nil {
+				systems[key] = make(map[string]bool)
+			}
+			systems[key][rule.System] = true
+		}
+	}
+
+	for _, tgid := range dynamicTGIDs {
+		var subs []uint32
+		if subscribers != nil {
+			subs = append(subs, subscribers(tgid)...)
+		}
+		sort.Slice(subs, func(i, j int) bool { return subs[i] < subs[j] })
+		for _, ts := range []int{1, 2} {
+			peers[routeKey{tgid: tgid, timeslot: ts}] = subs
+		}
+	}
+
+	keys := make([]routeKey, 0, len(systems)+len(peers))
+	seen := make(map[routeKey]bool)
+	for key := range systems {
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	for key := range peers {
+		if !seen[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].tgid != keys[j].tgid {
+			return keys[i].tgid < keys[j].tgid
+		}
+		return keys[i].timeslot < keys[j].timeslot
+	})
+
+	table := make([]RouteEntry, 0, len(keys))
+	for _, key := range keys {
+		entry := RouteEntry{
+			TGID:     key.tgid,
+			Timeslot: key.timeslot,
+			Systems:  make([]string, 0, len(systems[key])),
+			Peers:    make([]uint32, 0, len(peers[key])),
+		}
+		for system := range systems[key] {
+			entry.Systems = append(entry.Systems, system)
+		}
+		sort.Strings(entry.Systems)
+		entry.Peers = append(entry.Peers, peers[key]...)
+		table = append(table, entry)
+	}
+
+	return table
+}
+
+// RouteTable returns the effective routing table for the router's current state.
+// subscribers returns the peers subscribed to a talkgroup and may be nil.
+func (r *Router) RouteTable(subscribers func(tgid uint32) []uint32) []RouteEntry {
+	r.mu.RLock()
+	bridges := make([]BridgeRuleSetSnapshot, 0, len(r.bridges))
+	for _, br := range r.bridges {
+		bridges = append(bridges, br.Snapshot())
+	}
+	dynamicTGIDs := make([]uint32, 0, len(r.dynamicBridges))
+	for _, db := range r.dynamicBridges {
+		db.mu.RLock()
+		dynamicTGIDs = append(dynamicTGIDs, db.TGID)
+		db.mu.RUnlock()
+	}
+	r.mu.RUnlock()
+
+	return BuildRouteTable(bridges, dynamicTGIDs, subscribers)
+}
@@ -0,0 +1,100 @@
+package bridge
+
+import (
+	"testing"
+
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestBuildRouteTable_MatchesRoutePacket(t *testing.T) {
+	router := NewRouter()
+
+	nationwide := NewBridgeRuleSet("NATIONWIDE")
+	nationwide.AddRule(&BridgeRule{System: "SYSTEM1", TGID: 3100, Timeslot: 1, Active: true})
+	nationwide.AddRule(&BridgeRule{System: "SYSTEM2", TGID: 3100, Timeslot: 1, Active: true})
+	nationwide.AddRule(&BridgeRule{System: "SYSTEM3", TGID: 3100, Timeslot: 2, Active: true})
+	router.AddBridge(nationwide)
+
+	regional := NewBridgeRuleSet("REGIONAL")
+	regional.AddRule(&BridgeRule{System: "SYSTEM1", TGID: 3120, Timeslot: 2, Active: true})
+	regional.AddRule(&BridgeRule{System: "SYSTEM3", TGID: 3120, Timeslot: 2, Active: true})
+	regional.AddRule(&BridgeRule{System: "SYSTEM2", TGID: 3120, Timeslot: 2, Active: false})
+	router.AddBridge(regional)
+
+	router.GetOrCreateDynamicBridge(91)
+	subscribers := func(tgid uint32) []uint32 {
+		if tgid == 91 {
+			return []uint32{312002, 312001}
+		}
+		return nil
+	}
+
+	table := router.RouteTable(subscribers)
+	lookup := func(tgid uint32, ts int) *RouteEntry {
+		for i := range table {
+			if table[i].TGID == tgid && table[i].Timeslot == ts {
+				return &table[i]
+			}
+		}
+		return nil
+	}
+
+	cases := []struct {
+		tgid     uint32
+		timeslot int
+		source   string
+	}{
+		{3100, 1, "SYSTEM1"},
+		{3100, 2, "SYSTEM1"},
+		{3120, 2, "SYSTEM1"},
+		{3120, 1, "SYSTEM1"},
+		{9999, 1, "SYSTEM1"},
+	}
+
+	for i, tc := range cases {
+		packet := &protocol.DMRDPacket{
+			DestinationID: tc.tgid,
+			Timeslot:      tc.timeslot,
+			StreamID:      uint32(1000 + i),
+		}
+		routed := make(map[string]bool)
+		for _, target := range router.RoutePacket(packet, tc.source) {
+			routed[target] = true
+		}
+
+		expected := make(map[string]bool)
+		if entry := lookup(tc.tgid, tc.timeslot); entry != nil {
+			for _, system := range entry.Systems {
+				if system != tc.source {
+					expected[system] = true
+				}
+			}
+		}
+
+		if len(routed) != len(expected) {
+			t.Errorf("TG %d TS %d: table says %v, RoutePacket says %v", tc.tgid, tc.timeslot, expected, routed)
+			continue
+		}
+		for system := range expected {
+			if !routed[system] {
+				t.Errorf("TG %d TS %d: expected %s in routed targets %v", tc.tgid, tc.timeslot, system, routed)
+			}
+		}
+	}
+
+	// Dynamic bridges route to subscribers on both timeslots
+	for _, ts := range []int{1, 2} {
+		entry := lookup(91, ts)
+		if entry == nil {
+			t.Fatalf("Expected dynamic route for TG 91 TS %d", ts)
+		}
+		if len(entry.Peers) != 2 || entry.Peers[0] != 312001 || entry.Peers[1] != 312002 {
+			t.Errorf("Expected sorted peers [312001 312002] for TG 91 TS %d, got %v", ts, entry.Peers)
+		}
+	}
+
+	// Inactive rules do not appear
+	if entry := lookup(3120, 2); entry == nil || len(entry.Systems) != 2 {
+		t.Errorf("Expected TG 3120 TS 2 to route to two systems, got %+v", entry)
+	}
+}
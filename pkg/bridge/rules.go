@@ -2,6 +2,8 @@ package bridge
 
 import (
 	"sync"
+
+	"github.com/dbehnke/dmr-nexus/pkg/config"
 )
 
 // BridgeRule represents a single routing rule for a conference bridge
@@ -67,18 +69,22 @@ func (r *BridgeRule) ShouldDeactivate(tgid uint32) bool {
 	return false
 }
 
-// Activate activates this rule
-func (r *BridgeRule) Activate() {
+// Activate activates this rule and reports whether it was inactive
+func (r *BridgeRule) Activate() bool {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	changed := !r.Active
 	r.Active = true
+	return changed
 }
 
-// Deactivate deactivates this rule
-func (r *BridgeRule) Deactivate() {
+// Deactivate deactivates this rule and reports whether it was active
+func (r *BridgeRule) Deactivate() bool {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	changed := r.Active
 	r.Active = false
+	return changed
 }
 
 // BridgeRuleSet represents a named set of bridge rules
@@ -140,35 +146,53 @@ func (brs *BridgeRuleSet) GetMatchingRules(tgid uint32, timeslot int, excludeSys
 // ProcessActivation processes activation for the given TGID
 // Returns the list of rules that were activated
 func (brs *BridgeRuleSet) ProcessActivation(tgid uint32) []*BridgeRule {
+	activated, _ := brs.processActivation(tgid)
+	return activated
+}
+
+// processActivation activates the rules tgid switches on and returns them,
+// along with the subset that were inactive until now
+func (brs *BridgeRuleSet) processActivation(tgid uint32) (activated, changed []*BridgeRule) {
 	brs.mu.RLock()
 	defer brs.mu.RUnlock()
 
-	activated := make([]*BridgeRule, 0)
+	activated = make([]*BridgeRule, 0)
 	for _, rule := range brs.Rules {
 		if rule.ShouldActivate(tgid) {
-			rule.Activate()
+			if rule.Activate() {
+				changed = append(changed, rule)
+			}
 			activated = append(activated, rule)
 		}
 	}
 
-	return activated
+	return activated, changed
 }
 
 // ProcessDeactivation processes deactivation for the given TGID
 // Returns the list of rules that were deactivated
 func (brs *BridgeRuleSet) ProcessDeactivation(tgid uint32) []*BridgeRule {
+	deactivated, _ := brs.processDeactivation(tgid)
+	return deactivated
+}
+
+// processDeactivation deactivates the rules tgid switches off and returns
+// them, along with the subset that were active until now
+func (brs *BridgeRuleSet) processDeactivation(tgid uint32) (deactivated, changed []*BridgeRule) {
 	brs.mu.RLock()
 	defer brs.mu.RUnlock()
 
-	deactivated := make([]*BridgeRule, 0)
+	deactivated = make([]*BridgeRule, 0)
 	for _, rule := range brs.Rules {
 		if rule.ShouldDeactivate(tgid) {
-			rule.Deactivate()
+			if rule.Deactivate() {
+				changed = append(changed, rule)
+			}
 			deactivated = append(deactivated, rule)
 		}
 	}
 
-	return deactivated
+	return deactivated, changed
 }
 
 // BridgeRuleSnapshot is a read-only snapshot of a BridgeRule
@@ -203,3 +227,20 @@ func (brs *BridgeRuleSet) Snapshot() BridgeRuleSetSnapshot {
 	}
 	return out
 }
+
+// NewBridgeRuleSetFromConfig builds a rule set from a configured bridge
+func NewBridgeRuleSetFromConfig(name string, rules []config.BridgeRule) *BridgeRuleSet {
+	brs := NewBridgeRuleSet(name)
+	for _, r := range rules {
+		brs.AddRule(&BridgeRule{
+			System:   r.System,
+			TGID:     r.TGID,
+			Timeslot: r.Timeslot,
+			Active:   r.Active,
+			On:       r.On,
+			Off:      r.Off,
+			Timeout:  r.Timeout,
+		})
+	}
+	return brs
+}
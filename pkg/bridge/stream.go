@@ -1,33 +1,83 @@
 package bridge
 
 import (
+	"fmt"
 	"sync"
 	"time"
 )
 
+// DedupPolicy decides which copies of a stream count as duplicates
+type DedupPolicy string
+
+const (
+	// DedupPerSource keys deduplication on (stream, source system). Each
+	// system may deliver a stream once; the same stream re-entering from a
+	// different system is treated as a legitimate second path (for example a
+	// call heard by two independently linked networks) and is routed again.
+	// Only a repeat from a system that already delivered it is a duplicate.
+	DedupPerSource DedupPolicy = "per_source"
+
+	// DedupFirstWins keys deduplication on the stream alone. The first system
+	// to deliver a stream owns it and copies arriving from any other system
+	// are dropped. Use this when systems are meshed, so that any second path
+	// can only be a loop.
+	DedupFirstWins DedupPolicy = "first_wins"
+)
+
+// ParseDedupPolicy converts a configured policy name; empty selects DedupPerSource
+func ParseDedupPolicy(name string) (DedupPolicy, error) {
+	switch DedupPolicy(name) {
+	case "", DedupPerSource:
+		return DedupPerSource, nil
+	case DedupFirstWins:
+		return DedupFirstWins, nil
+	default:
+		return "", fmt.Errorf("unknown stream dedup policy %q", name)
+	}
+}
+
 // StreamInfo tracks information about an active stream
 type StreamInfo struct {
 	StreamID  uint32
 	Systems   map[string]bool // Systems that have seen this stream
+	Origin    string          // First system to deliver the stream
 	StartTime time.Time
 }
 
 // StreamTracker manages active DMR streams and prevents packet loops
 type StreamTracker struct {
 	streams map[uint32]*StreamInfo
+	policy  DedupPolicy
 	mu      sync.RWMutex
 }
 
-// NewStreamTracker creates a new stream tracker
+// NewStreamTracker creates a new stream tracker using DedupPerSource
 func NewStreamTracker() *StreamTracker {
 	return &StreamTracker{
 		streams: make(map[uint32]*StreamInfo),
+		policy:  DedupPerSource,
 	}
 }
 
+// SetPolicy changes how copies of a stream from different systems are deduplicated
+func (st *StreamTracker) SetPolicy(policy DedupPolicy) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.policy = policy
+}
+
+// Policy returns the active deduplication policy
+func (st *StreamTracker) Policy() DedupPolicy {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return st.policy
+}
+
 // TrackStream tracks a stream from a specific system.
 // Returns true if this is a new stream from this system (should forward),
-// false if we've already seen this stream from this system (duplicate, don't forward).
+// false if it is a duplicate under the tracker's policy (don't forward):
+// always when this system already delivered the stream, and with
+// DedupFirstWins also when another system delivered it first.
 func (st *StreamTracker) TrackStream(streamID uint32, system string) bool {
 	st.mu.Lock()
 	defer st.mu.Unlock()
@@ -39,6 +89,7 @@ func (st *StreamTracker) TrackStream(streamID uint32, system string) bool {
 		info = &StreamInfo{
 			StreamID:  streamID,
 			Systems:   make(map[string]bool),
+			Origin:    system,
 			StartTime: time.Now(),
 		}
 		st.streams[streamID] = info
@@ -50,6 +101,13 @@ func (st *StreamTracker) TrackStream(streamID uint32, system string) bool {
 		return false
 	}
 
+	// Under first-wins, a copy from any system but the origin is a loop. It
+	// is still recorded so private calls are never bounced back to it.
+	if st.policy == DedupFirstWins && system != info.Origin {
+		info.Systems[system] = true
+		return false
+	}
+
 	// Mark that this system has now seen the stream
 	info.Systems[system] = true
 	return true
@@ -72,6 +130,13 @@ func (st *StreamTracker) EndStream(streamID uint32) {
 	delete(st.streams, streamID)
 }
 
+// Count returns the number of streams being tracked
+func (st *StreamTracker) Count() int {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return len(st.streams)
+}
+
 // GetStreamSystems returns the list of systems that have seen this stream
 func (st *StreamTracker) GetStreamSystems(streamID uint32) []string {
 	st.mu.RLock()
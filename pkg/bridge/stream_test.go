@@ -183,3 +183,55 @@ func TestStreamTracker_ConcurrentAccess(t *testing.T) {
 		<-done
 	}
 }
+
+func TestStreamTracker_DedupPolicies(t *testing.T) {
+	tests := []struct {
+		policy         DedupPolicy
+		wantSecondPath bool
+	}{
+		{policy: DedupPerSource, wantSecondPath: true},
+		{policy: DedupFirstWins, wantSecondPath: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			tracker := NewStreamTracker()
+			tracker.SetPolicy(tt.policy)
+
+			if !tracker.TrackStream(777, "SYSTEM1") {
+				t.Fatal("Expected first delivery to be accepted")
+			}
+
+			// The same stream re-entering from a second system
+			if got := tracker.TrackStream(777, "SYSTEM2"); got != tt.wantSecondPath {
+				t.Errorf("Second path accepted = %v, want %v", got, tt.wantSecondPath)
+			}
+
+			// A repeat from a system that already delivered it is always a duplicate
+			if tracker.TrackStream(777, "SYSTEM1") || tracker.TrackStream(777, "SYSTEM2") {
+				t.Error("Expected repeats from the same system to be duplicates")
+			}
+
+			// Either way both systems are known to carry the stream
+			if systems := tracker.GetStreamSystems(777); len(systems) != 2 {
+				t.Errorf("Expected 2 systems recorded, got %v", systems)
+			}
+		})
+	}
+}
+
+func TestParseDedupPolicy(t *testing.T) {
+	for name, want := range map[string]DedupPolicy{
+		"":           DedupPerSource,
+		"per_source": DedupPerSource,
+		"first_wins": DedupFirstWins,
+	} {
+		got, err := ParseDedupPolicy(name)
+		if err != nil || got != want {
+			t.Errorf("ParseDedupPolicy(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseDedupPolicy("global"); err == nil {
+		t.Error("Expected error for unknown policy")
+	}
+}
@@ -1,18 +1,22 @@
 package bridge
 
 import (
+	"fmt"
 	"sync"
 	"time"
 
 	"github.com/dbehnke/dmr-nexus/pkg/database"
 	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/privacy"
 )
 
 // TransmissionLogger logs DMR transmissions to the database
 type TransmissionLogger struct {
 	repo          *database.TransmissionRepository
 	logger        *logger.Logger
+	anon          *privacy.Anonymizer // Applied to radio IDs before they are saved
 	activeStreams map[uint32]*activeStream
+	nets          map[uint32]string // Talkgroup -> name of the net running on it
 	mu            sync.RWMutex
 }
 
@@ -26,6 +30,7 @@ type activeStream struct {
 	startTime   time.Time
 	lastSeen    time.Time
 	packetCount int
+	netName     string // Net running on the talkgroup when the stream started
 }
 
 // NewTransmissionLogger creates a new transmission logger
@@ -34,9 +39,73 @@ func NewTransmissionLogger(repo *database.TransmissionRepository, log *logger.Lo
 		repo:          repo,
 		logger:        log,
 		activeStreams: make(map[uint32]*activeStream),
+		nets:          make(map[uint32]string),
 	}
 }
 
+// StartNet starts a named net session on a talkgroup: transmissions that
+// start on it until StopNet are saved tagged with the net's name
+func (tl *TransmissionLogger) StartNet(name string, talkgroupID uint32) error {
+	if name == "" {
+		return fmt.Errorf("net name is required")
+	}
+	if talkgroupID == 0 {
+		return fmt.Errorf("net talkgroup is required")
+	}
+
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	if running, ok := tl.nets[talkgroupID]; ok {
+		return fmt.Errorf("net %q is already running on talkgroup %d", running, talkgroupID)
+	}
+	for tg, running := range tl.nets {
+		if running == name {
+			return fmt.Errorf("net %q is already running on talkgroup %d", name, tg)
+		}
+	}
+	tl.nets[talkgroupID] = name
+	tl.logger.Info("Net started",
+		logger.String("net", name),
+		logger.Any("talkgroup_id", talkgroupID))
+	return nil
+}
+
+// StopNet ends a net session, reporting false if no net by that name is
+// running. Transmissions already in progress keep their tag.
+func (tl *TransmissionLogger) StopNet(name string) bool {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	for tg, running := range tl.nets {
+		if running == name {
+			delete(tl.nets, tg)
+			tl.logger.Info("Net stopped",
+				logger.String("net", name),
+				logger.Any("talkgroup_id", tg))
+			return true
+		}
+	}
+	return false
+}
+
+// ActiveNets returns the running nets by talkgroup
+func (tl *TransmissionLogger) ActiveNets() map[uint32]string {
+	tl.mu.RLock()
+	defer tl.mu.RUnlock()
+	nets := make(map[uint32]string, len(tl.nets))
+	for tg, name := range tl.nets {
+		nets[tg] = name
+	}
+	return nets
+}
+
+// SetAnonymizer anonymizes radio IDs in saved transmissions. Streams are
+// still tracked by their real IDs in memory.
+func (tl *TransmissionLogger) SetAnonymizer(anon *privacy.Anonymizer) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	tl.anon = anon
+}
+
 // LogPacket logs a DMR packet, tracking streams and creating transmission records
 func (tl *TransmissionLogger) LogPacket(streamID, radioID, talkgroupID, repeaterID uint32, timeslot int, isTerminator bool) {
 	tl.mu.Lock()
@@ -57,6 +126,7 @@ func (tl *TransmissionLogger) LogPacket(streamID, radioID, talkgroupID, repeater
 			startTime:   now,
 			lastSeen:    now,
 			packetCount: 1,
+			netName:     tl.nets[talkgroupID],
 		}
 		tl.activeStreams[streamID] = stream
 		tl.logger.Debug("Started tracking stream",
@@ -77,7 +147,7 @@ func (tl *TransmissionLogger) LogPacket(streamID, radioID, talkgroupID, repeater
 		// Very short transmissions are likely spurious or duplicate packets
 		if duration >= 0.5 {
 			tx := &database.Transmission{
-				RadioID:     stream.radioID,
+				RadioID:     tl.anon.RadioID(stream.radioID),
 				TalkgroupID: stream.talkgroupID,
 				Timeslot:    stream.timeslot,
 				Duration:    duration,
@@ -86,6 +156,7 @@ func (tl *TransmissionLogger) LogPacket(streamID, radioID, talkgroupID, repeater
 				EndTime:     stream.lastSeen,
 				RepeaterID:  stream.repeaterID,
 				PacketCount: stream.packetCount,
+				NetName:     stream.netName,
 			}
 
 			if err := tl.repo.Create(tx); err != nil {
@@ -128,7 +199,7 @@ func (tl *TransmissionLogger) CleanupStaleStreams(maxAge time.Duration) {
 			// Only save transmissions that are at least 0.5 seconds long
 			if duration >= 0.5 {
 				tx := &database.Transmission{
-					RadioID:     stream.radioID,
+					RadioID:     tl.anon.RadioID(stream.radioID),
 					TalkgroupID: stream.talkgroupID,
 					Timeslot:    stream.timeslot,
 					Duration:    duration,
@@ -137,6 +208,7 @@ func (tl *TransmissionLogger) CleanupStaleStreams(maxAge time.Duration) {
 					EndTime:     stream.lastSeen,
 					RepeaterID:  stream.repeaterID,
 					PacketCount: stream.packetCount,
+					NetName:     stream.netName,
 				}
 
 				if err := tl.repo.Create(tx); err != nil {
@@ -2,11 +2,14 @@ package bridge
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/dbehnke/dmr-nexus/pkg/database"
 	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/privacy"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
 )
 
 func TestTransmissionLogger_LogPacket(t *testing.T) {
@@ -206,3 +209,155 @@ func TestTransmissionLogger_CleanupStaleStreams(t *testing.T) {
 		t.Fatalf("Expected 1 transmission after cleanup, got %d", len(transmissions))
 	}
 }
+
+func TestRouter_TransmissionLoggingPerSystem(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	db, err := database.NewDB(database.Config{Path: filepath.Join(t.TempDir(), "txlog.db")}, log)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatalf("failed to close db: %v", err)
+		}
+	}()
+
+	repo := database.NewTransmissionRepository(db.GetDB())
+	router := NewRouter()
+	router.SetTransmissionLogger(NewTransmissionLogger(repo, log))
+	router.SetTransmissionLogging("QUIET", false)
+
+	packet := func(streamID, radioID uint32, frameType byte) *protocol.DMRDPacket {
+		return &protocol.DMRDPacket{
+			SourceID:      radioID,
+			DestinationID: 91,
+			RepeaterID:    3001,
+			Timeslot:      1,
+			FrameType:     frameType,
+			DataType:      protocol.DataTypeTerminatorLC,
+			StreamID:      streamID,
+		}
+	}
+
+	// One transmission from each system, long enough to be persisted
+	router.RoutePacket(packet(1, 3120001, protocol.FrameTypeVoiceHeader), "LOGGED")
+	router.RoutePacket(packet(2, 3120002, protocol.FrameTypeVoiceHeader), "QUIET")
+	time.Sleep(600 * time.Millisecond)
+	router.RoutePacket(packet(1, 3120001, protocol.FrameTypeVoiceTerminator), "LOGGED")
+	router.RoutePacket(packet(2, 3120002, protocol.FrameTypeVoiceTerminator), "QUIET")
+
+	transmissions, err := repo.GetRecent(10)
+	if err != nil {
+		t.Fatalf("Failed to get transmissions: %v", err)
+	}
+	if len(transmissions) != 1 {
+		t.Fatalf("Expected 1 transmission, got %d", len(transmissions))
+	}
+	if transmissions[0].RadioID != 3120001 {
+		t.Errorf("Expected only LOGGED system's traffic, got radio %d", transmissions[0].RadioID)
+	}
+}
+
+func TestRouter_TransmissionLoggingAnonymized(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	db, err := database.NewDB(database.Config{Path: filepath.Join(t.TempDir(), "txlog.db")}, log)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatalf("failed to close db: %v", err)
+		}
+	}()
+
+	anon := privacy.New(privacy.ModeHash, "secret")
+	repo := database.NewTransmissionRepository(db.GetDB())
+	txLogger := NewTransmissionLogger(repo, log)
+	txLogger.SetAnonymizer(anon)
+
+	router := NewRouter()
+	router.SetTransmissionLogger(txLogger)
+	rules := NewBridgeRuleSet("NATIONAL")
+	rules.AddRule(&BridgeRule{System: "SYS-A", TGID: 91, Timeslot: 1, Active: true})
+	rules.AddRule(&BridgeRule{System: "SYS-B", TGID: 91, Timeslot: 1, Active: true})
+	router.AddBridge(rules)
+
+	packet := func(frameType byte) *protocol.DMRDPacket {
+		return &protocol.DMRDPacket{
+			SourceID:      3120001,
+			DestinationID: 91,
+			RepeaterID:    3001,
+			Timeslot:      1,
+			FrameType:     frameType,
+			DataType:      protocol.DataTypeTerminatorLC,
+			StreamID:      7,
+		}
+	}
+
+	// Routing still sees the real source ID
+	if targets := router.RoutePacket(packet(protocol.FrameTypeVoiceHeader), "SYS-A"); len(targets) != 1 || targets[0] != "SYS-B" {
+		t.Fatalf("Expected routing to SYS-B, got %v", targets)
+	}
+	time.Sleep(600 * time.Millisecond)
+	router.RoutePacket(packet(protocol.FrameTypeVoiceTerminator), "SYS-A")
+
+	transmissions, err := repo.GetRecent(10)
+	if err != nil {
+		t.Fatalf("Failed to get transmissions: %v", err)
+	}
+	if len(transmissions) != 1 {
+		t.Fatalf("Expected 1 transmission, got %d", len(transmissions))
+	}
+	if got := transmissions[0].RadioID; got == 3120001 || got != anon.RadioID(3120001) {
+		t.Errorf("Expected the pseudonym for 3120001 to be saved, got %d", got)
+	}
+}
+
+func TestTransmissionLogger_NetTagging(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	db, err := database.NewDB(database.Config{Path: filepath.Join(t.TempDir(), "net.db")}, log)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	repo := database.NewTransmissionRepository(db.GetDB())
+	txLogger := NewTransmissionLogger(repo, log)
+
+	// transmit logs a one second stream without waiting for it
+	transmit := func(streamID, radioID, talkgroupID uint32) {
+		txLogger.LogPacket(streamID, radioID, talkgroupID, 3001, 1, false)
+		txLogger.mu.Lock()
+		txLogger.activeStreams[streamID].startTime = time.Now().Add(-time.Second)
+		txLogger.mu.Unlock()
+		txLogger.LogPacket(streamID, radioID, talkgroupID, 3001, 1, true)
+	}
+
+	if err := txLogger.StartNet("sunday", 3100); err != nil {
+		t.Fatalf("StartNet error: %v", err)
+	}
+	if err := txLogger.StartNet("other", 3100); err == nil {
+		t.Error("Expected a second net on the same talkgroup to be refused")
+	}
+	if err := txLogger.StartNet("sunday", 3101); err == nil {
+		t.Error("Expected a net name already running to be refused")
+	}
+
+	transmit(1, 3120001, 3100)
+	transmit(2, 3120002, 91) // Another talkgroup
+	if !txLogger.StopNet("sunday") {
+		t.Fatal("Expected StopNet to find the running net")
+	}
+	if txLogger.StopNet("sunday") {
+		t.Error("Expected StopNet on a stopped net to report false")
+	}
+	transmit(3, 3120003, 3100) // After the net ended
+
+	tagged, err := repo.GetByNet("sunday")
+	if err != nil {
+		t.Fatalf("GetByNet error: %v", err)
+	}
+	if len(tagged) != 1 || tagged[0].StreamID != 1 || tagged[0].RadioID != 3120001 {
+		t.Fatalf("Expected only stream 1 tagged with the net, got %+v", tagged)
+	}
+}
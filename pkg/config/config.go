@@ -9,14 +9,20 @@ import (
 
 // Config represents the application configuration
 type Config struct {
-	Global  GlobalConfig            `mapstructure:"global"`
-	Server  ServerConfig            `mapstructure:"server"`
-	Web     WebConfig               `mapstructure:"web"`
-	Systems map[string]SystemConfig `mapstructure:"systems"`
-	Bridges map[string][]BridgeRule `mapstructure:"bridges"`
-	MQTT    MQTTConfig              `mapstructure:"mqtt"`
-	Logging LoggingConfig           `mapstructure:"logging"`
-	Metrics MetricsConfig           `mapstructure:"metrics"`
+	Global     GlobalConfig            `mapstructure:"global"`
+	Server     ServerConfig            `mapstructure:"server"`
+	Web        WebConfig               `mapstructure:"web"`
+	Systems    map[string]SystemConfig `mapstructure:"systems"`
+	Bridges    map[string][]BridgeRule `mapstructure:"bridges"`
+	Groups     map[string]BridgeGroup  `mapstructure:"bridge_groups"` // Expanded into Bridges at load time
+	MQTT       MQTTConfig              `mapstructure:"mqtt"`
+	Logging    LoggingConfig           `mapstructure:"logging"`
+	Metrics    MetricsConfig           `mapstructure:"metrics"`
+	Database   DatabaseConfig          `mapstructure:"database"`
+	Privacy    PrivacyConfig           `mapstructure:"privacy"`
+	APRS       APRSConfig              `mapstructure:"aprs"`
+	Talkgroups TalkgroupsConfig        `mapstructure:"talkgroups"`
+	Runtime    RuntimeConfig           `mapstructure:"runtime"`
 }
 
 // GlobalConfig holds global DMR configuration
@@ -29,6 +35,43 @@ type GlobalConfig struct {
 	TG1ACL              string `mapstructure:"tg1_acl"`               // Talkgroup timeslot 1 ACL
 	TG2ACL              string `mapstructure:"tg2_acl"`               // Talkgroup timeslot 2 ACL
 	PrivateCallsEnabled bool   `mapstructure:"private_calls_enabled"` // Enable private call routing
+
+	StreamPriorities []StreamPriority `mapstructure:"stream_priorities"` // Sources that may preempt active streams
+	StreamDedup      string           `mapstructure:"stream_dedup"`      // "per_source" (default) or "first_wins"
+
+	// Announce static bridge rules linking or unlinking on MQTT
+	// (bridges/change) and to dashboard clients; they are always logged
+	AnnounceBridgeChanges bool `mapstructure:"announce_bridge_changes"`
+}
+
+// StreamPriority gives a source radio ID priority on a talkgroup. A voice
+// header from a higher-priority source preempts the active stream.
+type StreamPriority struct {
+	SourceID int `mapstructure:"source_id"`
+	TGID     int `mapstructure:"tgid"`     // 0 = all talkgroups
+	Priority int `mapstructure:"priority"` // Higher wins; unlisted sources are 0
+}
+
+// DatabaseConfig holds transmission database maintenance settings
+type DatabaseConfig struct {
+	RetentionDays  int  `mapstructure:"retention_days"`  // Delete transmissions, positions and messages older than this; 0 keeps everything
+	VacuumInterval int  `mapstructure:"vacuum_interval"` // Hours between VACUUM runs, which rewrite the whole file; 0 disables
+	StoreMessages  bool `mapstructure:"store_messages"`  // Keep text messages radios send for /api/messages
+}
+
+// RuntimeConfig tunes the Go runtime and packet handling for the CPUs the
+// process may use, e.g. under a container CPU limit
+type RuntimeConfig struct {
+	GOMAXPROCS    int `mapstructure:"gomaxprocs"`     // 0 keeps the Go default, which follows container CPU limits
+	PacketWorkers int `mapstructure:"packet_workers"` // Per MASTER system; 0 = 4 per GOMAXPROCS, -1 = a goroutine per packet
+}
+
+// PrivacyConfig controls anonymization of subscriber radio IDs and callsigns
+// in logs, the transmission database, the web API and MQTT. Routing always
+// uses the real IDs, which are kept in memory only.
+type PrivacyConfig struct {
+	Anonymize string `mapstructure:"anonymize"` // "hash", "omit", or empty to disable
+	Salt      string `mapstructure:"salt"`      // Secret keying the hashes; required for "hash"
 }
 
 // ServerConfig holds server identification
@@ -42,9 +85,34 @@ type WebConfig struct {
 	Enabled      bool   `mapstructure:"enabled"`
 	Host         string `mapstructure:"host"`
 	Port         int    `mapstructure:"port"`
+	UnixSocket   string `mapstructure:"unix_socket"` // Listen on this socket path instead of host:port
 	AuthRequired bool   `mapstructure:"auth_required"`
 	Username     string `mapstructure:"username"`
 	Password     string `mapstructure:"password"`
+	// WebSocket heartbeat: ping interval and pong timeout in seconds
+	WSPingInterval int `mapstructure:"ws_ping_interval"`
+	WSPongTimeout  int `mapstructure:"ws_pong_timeout"`
+	// Leave repeat-all (TG 777) monitor peers out of dashboard subscriber lists
+	ExcludeMonitorPeers bool `mapstructure:"exclude_monitor_peers"`
+	// Remote log tailing at GET /api/logs/stream (Server-Sent Events)
+	LogStream      bool   `mapstructure:"log_stream"`
+	LogStreamLevel string `mapstructure:"log_stream_level"` // Minimum level streamed; default info
+	// Cross-origin access for dashboards served elsewhere; same-origin only by default
+	CORS CORSConfig `mapstructure:"cors"`
+	// In-memory LRU of radio ID -> user lookups for the API; cleared on RadioID sync
+	UserCacheSize int `mapstructure:"user_cache_size"` // Entries; 0 disables
+	UserCacheTTL  int `mapstructure:"user_cache_ttl"`  // Seconds
+	// Absolute URL the dashboard is reached at, for links such as the RSS
+	// feed's; links are relative while unset
+	PublicURL string `mapstructure:"public_url"`
+}
+
+// CORSConfig lists what cross-origin browsers may do with the web API
+type CORSConfig struct {
+	AllowedOrigins []string `mapstructure:"allowed_origins"` // Exact origins, or "*" for any; empty disables CORS
+	AllowedMethods []string `mapstructure:"allowed_methods"` // Default GET, POST, DELETE
+	AllowedHeaders []string `mapstructure:"allowed_headers"` // Default Content-Type, Authorization
+	MaxAge         int      `mapstructure:"max_age"`         // Seconds browsers may cache a preflight; 0 omits it
 }
 
 // SystemConfig represents a single DMR system (MASTER, PEER, or OPENBRIDGE)
@@ -55,12 +123,141 @@ type SystemConfig struct {
 	// Common fields
 	IP         string `mapstructure:"ip"`
 	Port       int    `mapstructure:"port"`
+	ExtraPorts []int  `mapstructure:"extra_ports"` // Additional UDP ports sharing this system (e.g. legacy port)
+	ReusePort  bool   `mapstructure:"reuse_port"`  // Open ports with SO_REUSEPORT so several processes can share them (Linux, BSD, macOS)
 	Passphrase string `mapstructure:"passphrase"`
+	// Further passphrases a MASTER accepts, so credentials can be rotated
+	// peer by peer; listing any turns on RPTK challenge verification
+	Passphrases []string `mapstructure:"passphrases"`
 
 	// MASTER mode specific
-	Repeat              bool `mapstructure:"repeat"`
-	MaxPeers            int  `mapstructure:"max_peers"`
-	PrivateCallsEnabled bool `mapstructure:"private_calls_enabled"` // Enable private call routing
+	Repeat               bool `mapstructure:"repeat"`
+	MaxPeers             int  `mapstructure:"max_peers"`              // New logins beyond this many connected peers are refused
+	PrivateCallsEnabled  bool `mapstructure:"private_calls_enabled"`  // Enable private call routing
+	BridgePrivateCalls   bool `mapstructure:"bridge_private_calls"`   // Forward private calls across static bridges
+	PrivateCallsToPeers  bool `mapstructure:"private_calls_to_peers"` // Also deliver private calls addressed to a connected peer's ID
+	MaxStreamsPerPeer    int  `mapstructure:"max_streams_per_peer"`   // Concurrent streams per peer (one per slot); default 2
+	LogTransmissions     bool `mapstructure:"log_transmissions"`      // Persist this system's traffic to the database; default true
+	KeyupGuardMs         int  `mapstructure:"keyup_guard_ms"`         // After a terminator, drop other sources' key-ups on the TG for this long; 0 disables
+	PeerSendQueue        int  `mapstructure:"peer_send_queue"`        // Per-peer outbound queue length in frames; 0 writes inline
+	MaxDescriptionLength int  `mapstructure:"max_description_length"` // Clamp RPTC descriptions (OPTIONS: tail kept); 0 = field size
+	MetricsSampleRate    int  `mapstructure:"metrics_sample_rate"`    // Count 1 in N DMRD frames in packet/byte metrics, scaled by N; 0 or 1 counts all
+
+	// Refuse logins (MSTCL) from repeater IDs outside [min_peer_id,
+	// max_peer_id] or in the reserved block at the top of the 24-bit ID space
+	RejectInvalidPeerIDs bool `mapstructure:"reject_invalid_peer_ids"`
+	MinPeerID            int  `mapstructure:"min_peer_id"` // 0 means 100000, the lowest 6-digit repeater ID
+	MaxPeerID            int  `mapstructure:"max_peer_id"` // 0 means 999999999, the highest 9-digit hotspot ID
+
+	// Mirror every frame this system forwards, on any talkgroup, to a passive
+	// tap for recording or monitoring, whatever the tap subscribed to
+	TapSystem string `mapstructure:"tap_system"`  // System sent a copy of each frame; empty disables
+	TapPeerID int    `mapstructure:"tap_peer_id"` // Connected peer sent a copy of each frame; 0 disables
+	TapACL    string `mapstructure:"tap_acl"`     // Talkgroups mirrored (with use_acl); empty mirrors all
+
+	// Talkgroups that match no bridge rule and have no subscriber
+	UnknownTGTarget string `mapstructure:"unknown_tg_target"` // System that receives their traffic (e.g. a monitor); empty drops it
+	LogUnknownTGs   bool   `mapstructure:"log_unknown_tgs"`   // Log the first transmission seen on each unknown TG
+
+	// Check transmitting radio IDs against the RadioID user database:
+	// "strict" drops unregistered IDs, "grace" forwards but logs and counts them
+	SourceIDCheck string `mapstructure:"source_id_check"` // Empty disables
+
+	// Announce who is talking: a clip of the caller's callsign, assembled from
+	// pre-rendered per-character AMBE clips, plays before their audio
+	AnnounceCaller   bool   `mapstructure:"announce_caller"`
+	AnnounceClipsDir string `mapstructure:"announce_clips_dir"` // Holds A.ambe..Z.ambe and 0.ambe..9.ambe
+
+	// Read a peer's restored static talkgroups back to it, spelled from the
+	// announce clips, shortly after it connects
+	SubscriptionSummary   bool `mapstructure:"subscription_summary"`
+	SubscriptionSummaryTG int  `mapstructure:"subscription_summary_tg"` // Talkgroup the summary plays on; 0 means 9
+
+	// Announcement-only talkgroups: peers may listen but only the listed sources may transmit
+	ReceiveOnlyTGs []ReceiveOnlyTG `mapstructure:"receive_only_tgs"`
+
+	// Refuse to forward streams whose voice header has the privacy
+	// (encryption) service option set
+	DropEncrypted bool `mapstructure:"drop_encrypted"`
+
+	// Drop a peer's dynamic talkgroup once it hasn't transmitted on it for
+	// this many minutes, whatever AUTO TTL the peer set
+	UnlinkIdleMinutes int `mapstructure:"unlink_idle_minutes"` // 0 disables
+
+	// A peer sent the start of a stream keeps receiving it to the end, even
+	// if its subscription expires mid-call; it isn't picked for the next one
+	HoldStreamTargets bool `mapstructure:"hold_stream_targets"`
+
+	// Echo test: a transmission on this talkgroup is recorded and played back
+	// to the transmitting peer only
+	EchoTG int `mapstructure:"echo_tg"` // 0 disables
+
+	// Raise an event (MQTT users/first_heard) the first time each radio ID
+	// transmits on a given day, for welcome messages
+	FirstHeardGreeting bool `mapstructure:"first_heard_greeting"`
+
+	// Rewrite private (unit-to-unit) calls into group calls on this
+	// talkgroup, for networks that don't allow them
+	PrivateToGroupTG int `mapstructure:"private_to_group_tg"` // 0 disables
+
+	// One stream per receiving peer timeslot, group or private: "first_wins"
+	// drops whichever stream arrives second, "private_priority" lets a private
+	// call take the slot from a group call
+	SlotContention string `mapstructure:"slot_contention"` // Empty handles call types independently
+
+	// A voice header for a new stream while the same source's stream to the
+	// same destination is still active on that timeslot ends the old stream
+	// (with a terminator sent downstream) instead of being dropped as an overlap
+	ImplicitTerminators bool `mapstructure:"implicit_terminators"`
+
+	// On controlled shutdown, point connected peers at this master
+	// ("host:port") before closing their connections
+	BackupMaster string `mapstructure:"backup_master"`
+
+	// On controlled shutdown, send connected peers MSTCL before closing the
+	// socket so they reconnect at once; default true. With backup_master set
+	// and this off, peers get only the MSTRDR.
+	SendCloseOnShutdown bool `mapstructure:"send_close_on_shutdown"`
+
+	// Group calls longer than this are cut off, like a repeater's time-out
+	// timer; call_limits overrides it per talkgroup
+	MaxCallSeconds int         `mapstructure:"max_call_seconds"` // 0 = unlimited
+	CallLimits     []CallLimit `mapstructure:"call_limits"`
+
+	// Rewrite the color code of frames forwarded on these talkgroups, for
+	// repeaters that only accept their own color code
+	TGColorCodes []TGColorCode `mapstructure:"tg_color_codes"`
+
+	// Daily transmit time allowed per source ID; once used up, the source's
+	// traffic is dropped until local midnight. Usage is saved to the database.
+	DailyAirtimeMinutes int `mapstructure:"daily_airtime_minutes"` // 0 = unlimited
+
+	// Kerchunk-prone talkgroups: a transmission must last min_ms before it
+	// activates or deactivates bridge rules
+	TalkerHoldTGs []TalkerHoldTG `mapstructure:"talker_hold_tgs"`
+
+	// Per-peer talkgroup whitelists; override ALLOW= sent in the peer's OPTIONS
+	PeerAllowedTGs []PeerAllowedTGs `mapstructure:"peer_allowed_tgs"`
+
+	// Strict subscriptions: a key-up on a talkgroup the peer is not subscribed
+	// to, that no bridge rule carries and that is not in strict_allowed_tgs is
+	// dropped instead of subscribing the peer
+	StrictSubscriptions bool  `mapstructure:"strict_subscriptions"`
+	StrictAllowedTGs    []int `mapstructure:"strict_allowed_tgs"` // Talkgroups any peer may key up on
+
+	// Experimental, lossy: peers that only receive header, terminator and the
+	// first voice burst of each superframe (for monitoring over thin links)
+	LowBandwidthPeers []int `mapstructure:"low_bandwidth_peers"`
+
+	// Peers that also receive their own traffic back, for simulcast and
+	// voting receivers. A peer can ask for the same with OPTIONS ECHO=1.
+	EchoPeers []int `mapstructure:"echo_peers"`
+
+	// External authorization webhook consulted when a peer sends RPTC
+	AuthWebhook         string `mapstructure:"auth_webhook"`           // http(s) URL; empty disables
+	AuthWebhookTimeout  int    `mapstructure:"auth_webhook_timeout"`   // Seconds; default 5
+	AuthWebhookCacheTTL int    `mapstructure:"auth_webhook_cache_ttl"` // Seconds a decision is reused; default 60
+	AuthWebhookFailOpen bool   `mapstructure:"auth_webhook_fail_open"` // Allow peers if the webhook is unreachable
 
 	// PEER mode specific
 	Loose       bool    `mapstructure:"loose"`
@@ -80,23 +277,78 @@ type SystemConfig struct {
 	URL         string  `mapstructure:"url"`
 	SoftwareID  string  `mapstructure:"software_id"`
 	PackageID   string  `mapstructure:"package_id"`
+	// Re-register with the master after this many seconds without hearing
+	// from it, e.g. after a NAT rebind changed this client's public port
+	MasterSilenceTimeout int `mapstructure:"master_silence_timeout"` // Seconds; 0 disables
 
 	// OPENBRIDGE mode specific
 	TargetIP   string `mapstructure:"target_ip"`
 	TargetPort int    `mapstructure:"target_port"`
 	NetworkID  int    `mapstructure:"network_id"`
 	BothSlots  bool   `mapstructure:"both_slots"`
+	// Network ID inbound frames must carry, so links sharing a host and port
+	// can't cross-wire; frames from any other network are dropped
+	PartnerNetworkID int `mapstructure:"partner_network_id"` // 0 means network_id; -1 accepts any
+	// Drop frames whose (stream, sequence) was already received this many
+	// milliseconds ago; OpenBridge HMAC has no timestamp to stop replays
+	ReplayWindowMs int `mapstructure:"replay_window_ms"` // 0 disables
 
 	// Common settings
-	GroupHangtime int    `mapstructure:"group_hangtime"` // Seconds
-	UseACL        bool   `mapstructure:"use_acl"`
-	RegACL        string `mapstructure:"reg_acl"`
-	SubACL        string `mapstructure:"sub_acl"`
-	TG1ACL        string `mapstructure:"tg1_acl"`
-	TG2ACL        string `mapstructure:"tg2_acl"`
-	TGACL         string `mapstructure:"tg_acl"` // For OPENBRIDGE
+	GroupHangtime  int    `mapstructure:"group_hangtime"` // Seconds
+	UseACL         bool   `mapstructure:"use_acl"`
+	RegACL         string `mapstructure:"reg_acl"`
+	SubACL         string `mapstructure:"sub_acl"`
+	TG1ACL         string `mapstructure:"tg1_acl"`
+	TG2ACL         string `mapstructure:"tg2_acl"`
+	TGACL          string `mapstructure:"tg_acl"`           // For OPENBRIDGE
+	PrivateCallACL string `mapstructure:"private_call_acl"` // Destination IDs callable across bridges
 	// MSTNAK behavior: cooldown in seconds between MSTNAK replies to the same peer:addr
 	MstNakCooldown int `mapstructure:"mst_nak_cooldown"`
+	// A rejected peer that keeps sending this many packets regardless is
+	// answered only once per mst_nak_silence seconds
+	MstNakSilenceAfter int `mapstructure:"mst_nak_silence_after"` // 0 means 20; -1 disables
+	MstNakSilence      int `mapstructure:"mst_nak_silence"`       // 0 means 3600
+
+	// Housekeeping timers; 0 uses the default shown
+	PingTimeout           int `mapstructure:"ping_timeout"`            // Seconds without a ping before a peer is dropped; default 30
+	CleanupInterval       int `mapstructure:"cleanup_interval"`        // Seconds between cleanup passes; default 10
+	SubscriberLocationTTL int `mapstructure:"subscriber_location_ttl"` // Seconds a radio's last-heard peer is kept for private calls; default 900
+	MuteWindowMs          int `mapstructure:"mute_window_ms"`          // Idle ms before a muted first key-up stream is released; default 2000
+
+	// Latency alarm: warn and count packets that take longer than this from
+	// receipt to the end of their handling (overload, GC pauses)
+	MaxPacketLatencyMs int `mapstructure:"max_packet_latency_ms"` // 0 disables
+}
+
+// ReceiveOnlyTG marks a talkgroup as one-way (e.g. a news feed)
+type ReceiveOnlyTG struct {
+	TGID    int   `mapstructure:"tgid"`
+	Sources []int `mapstructure:"sources"` // Radio or peer IDs allowed to transmit
+}
+
+// CallLimit overrides max_call_seconds for one talkgroup
+type CallLimit struct {
+	TGID       int `mapstructure:"tgid"`
+	MaxSeconds int `mapstructure:"max_seconds"` // 0 = unlimited on this talkgroup
+}
+
+// TGColorCode sets the color code of frames forwarded on one talkgroup
+type TGColorCode struct {
+	TGID      int `mapstructure:"tgid"`
+	ColorCode int `mapstructure:"color_code"` // 0-15
+}
+
+// TalkerHoldTG sets the minimum transmission length on a talkgroup before it
+// counts toward bridge activation
+type TalkerHoldTG struct {
+	TGID  int `mapstructure:"tgid"`
+	MinMs int `mapstructure:"min_ms"`
+}
+
+// PeerAllowedTGs restricts a peer to subscribing to a fixed set of talkgroups
+type PeerAllowedTGs struct {
+	PeerID int   `mapstructure:"peer_id"`
+	TGs    []int `mapstructure:"tgs"`
 }
 
 // BridgeRule represents a conference bridge routing rule
@@ -111,6 +363,15 @@ type BridgeRule struct {
 	ToType   string `mapstructure:"to_type"` // ON or OFF
 }
 
+// BridgeGroup links every listed system to every other on each listed
+// talkgroup. Groups are expanded at load time into one always-active bridge
+// per talkgroup, named "<group>-<tgid>".
+type BridgeGroup struct {
+	Systems  []string `mapstructure:"systems"`
+	TGIDs    []int    `mapstructure:"tgids"`
+	Timeslot int      `mapstructure:"timeslot"`
+}
+
 // MQTTConfig holds MQTT client configuration
 type MQTTConfig struct {
 	Enabled     bool   `mapstructure:"enabled"`
@@ -121,6 +382,21 @@ type MQTTConfig struct {
 	Password    string `mapstructure:"password"`
 	QoS         byte   `mapstructure:"qos"`
 	Retained    bool   `mapstructure:"retained"`
+	// Add the source's last known coordinates (from its LRRP reports) to traffic events
+	IncludePosition bool `mapstructure:"include_position"`
+	// Seconds between node heartbeats on <topic_prefix>/status; 0 disables
+	StatusInterval int `mapstructure:"status_interval"`
+}
+
+// APRSConfig holds the APRS-IS uplink configuration. Heard stations with a
+// known position are sent to APRS-IS as objects.
+type APRSConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Server   string `mapstructure:"server"`   // APRS-IS host:port
+	Callsign string `mapstructure:"callsign"` // Login callsign, also the sender of each object
+	Passcode string `mapstructure:"passcode"` // APRS-IS passcode for callsign
+	Comment  string `mapstructure:"comment"`  // Appended to each object
+	Interval int    `mapstructure:"interval"` // Minimum seconds between objects for one station
 }
 
 // LoggingConfig holds logging configuration
@@ -137,17 +413,37 @@ type LoggingConfig struct {
 type MetricsConfig struct {
 	Enabled    bool             `mapstructure:"enabled"`
 	Prometheus PrometheusConfig `mapstructure:"prometheus"`
+	StatsD     StatsDConfig     `mapstructure:"statsd"`
+	// Upper bounds (bytes) of the dmr_packet_size_bytes histogram buckets;
+	// empty uses one bucket per protocol packet size
+	PacketSizeBuckets []int `mapstructure:"packet_size_buckets"`
 }
 
 // PrometheusConfig holds Prometheus metrics configuration
 type PrometheusConfig struct {
-	Enabled bool   `mapstructure:"enabled"`
-	Port    int    `mapstructure:"port"`
-	Path    string `mapstructure:"path"`
+	Enabled    bool   `mapstructure:"enabled"`
+	Port       int    `mapstructure:"port"`
+	Path       string `mapstructure:"path"`
+	UnixSocket string `mapstructure:"unix_socket"` // Listen on this socket path instead of port
 }
 
-// Load loads configuration from file and environment variables
+// StatsDConfig holds StatsD push configuration
+type StatsDConfig struct {
+	Enabled       bool   `mapstructure:"enabled"`
+	Address       string `mapstructure:"address"`        // host:port of the StatsD agent
+	Prefix        string `mapstructure:"prefix"`         // Metric name prefix
+	FlushInterval int    `mapstructure:"flush_interval"` // Seconds; default 10
+	DogStatsD     bool   `mapstructure:"dogstatsd"`      // Send tags DogStatsD-style instead of folding them into names
+}
+
+// Load loads configuration from file and environment variables.
+// If configFile is an http(s) URL the configuration is fetched remotely
+// (see loadRemote).
 func Load(configFile string) (*Config, error) {
+	if isRemoteConfig(configFile) {
+		return loadRemote(configFile)
+	}
+
 	// Set defaults
 	setDefaults()
 
@@ -177,6 +473,12 @@ func Load(configFile string) (*Config, error) {
 		}
 	}
 
+	return decode()
+}
+
+// decode unmarshals the loaded viper state into a Config, applies
+// system-level defaults and validates the result
+func decode() (*Config, error) {
 	// Unmarshal to struct
 	var config Config
 	if err := viper.Unmarshal(&config); err != nil {
@@ -185,12 +487,24 @@ func Load(configFile string) (*Config, error) {
 
 	// Apply system-level defaults for any systems that didn't set them explicitly.
 	// This uses the viper-provided default `system_defaults.mst_nak_cooldown`.
+	// Transmission logging and closing peers on shutdown default to on unless
+	// the system sets them.
 	defaultMstNak := viper.GetInt("system_defaults.mst_nak_cooldown")
 	for name, sys := range config.Systems {
 		if sys.MstNakCooldown == 0 {
 			sys.MstNakCooldown = defaultMstNak
-			config.Systems[name] = sys
 		}
+		if !viper.IsSet("systems." + name + ".log_transmissions") {
+			sys.LogTransmissions = true
+		}
+		if !viper.IsSet("systems." + name + ".send_close_on_shutdown") {
+			sys.SendCloseOnShutdown = true
+		}
+		config.Systems[name] = sys
+	}
+
+	if err := loadTalkgroupsFile(&config.Talkgroups); err != nil {
+		return nil, err
 	}
 
 	// Validate configuration
@@ -198,6 +512,8 @@ func Load(configFile string) (*Config, error) {
 		return nil, fmt.Errorf("config validation failed: %w", err)
 	}
 
+	expandBridgeGroups(&config)
+
 	return &config, nil
 }
 
@@ -212,6 +528,7 @@ func setDefaults() {
 	viper.SetDefault("global.tg1_acl", "PERMIT:ALL")
 	viper.SetDefault("global.tg2_acl", "PERMIT:ALL")
 	viper.SetDefault("global.private_calls_enabled", false)
+	viper.SetDefault("global.stream_dedup", "per_source")
 
 	// Server defaults
 	viper.SetDefault("server.name", "DMR-Nexus")
@@ -222,6 +539,11 @@ func setDefaults() {
 	viper.SetDefault("web.host", "0.0.0.0")
 	viper.SetDefault("web.port", 8080)
 	viper.SetDefault("web.auth_required", false)
+	viper.SetDefault("web.ws_ping_interval", 30)
+	viper.SetDefault("web.ws_pong_timeout", 60)
+	viper.SetDefault("web.log_stream_level", "info")
+	viper.SetDefault("web.user_cache_size", 10000)
+	viper.SetDefault("web.user_cache_ttl", 300)
 
 	// MQTT defaults
 	viper.SetDefault("mqtt.enabled", false)
@@ -229,6 +551,12 @@ func setDefaults() {
 	viper.SetDefault("mqtt.client_id", "dmr-nexus")
 	viper.SetDefault("mqtt.qos", 1)
 	viper.SetDefault("mqtt.retained", false)
+	viper.SetDefault("mqtt.status_interval", 60)
+
+	// APRS-IS defaults
+	viper.SetDefault("aprs.enabled", false)
+	viper.SetDefault("aprs.server", "rotate.aprs2.net:14580")
+	viper.SetDefault("aprs.interval", 600)
 
 	// Logging defaults
 	viper.SetDefault("logging.level", "info")
@@ -237,11 +565,18 @@ func setDefaults() {
 	viper.SetDefault("logging.max_backups", 3)
 	viper.SetDefault("logging.max_age", 7)
 
+	// Database defaults
+	viper.SetDefault("database.retention_days", 0)
+	viper.SetDefault("database.vacuum_interval", 0)
+	viper.SetDefault("database.store_messages", false)
+
 	// Metrics defaults
 	viper.SetDefault("metrics.enabled", true)
 	viper.SetDefault("metrics.prometheus.enabled", true)
 	viper.SetDefault("metrics.prometheus.port", 9090)
 	viper.SetDefault("metrics.prometheus.path", "/metrics")
+	viper.SetDefault("metrics.statsd.prefix", "dmr")
+	viper.SetDefault("metrics.statsd.flush_interval", 10)
 
 	// System-level defaults
 	// Default cooldown (seconds) between MSTNAK replies to the same peer:addr
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/spf13/viper"
@@ -34,6 +36,9 @@ func TestLoad_UsesDefaults_WhenNoFile(t *testing.T) {
 	if cfg.Metrics.Prometheus.Port != 9090 {
 		t.Errorf("expected Prometheus.Port default 9090, got %d", cfg.Metrics.Prometheus.Port)
 	}
+	if cfg.Database.VacuumInterval != 0 {
+		t.Errorf("expected Database.VacuumInterval default 0 (off), got %d", cfg.Database.VacuumInterval)
+	}
 }
 
 func TestValidate_Errors(t *testing.T) {
@@ -44,6 +49,13 @@ func TestValidate_Errors(t *testing.T) {
 		}
 	})
 
+	t.Run("unknown stream dedup policy", func(t *testing.T) {
+		cfg := &Config{Global: GlobalConfig{PingTime: 1, MaxMissed: 1, StreamDedup: "global"}, Web: WebConfig{Enabled: false}}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for unknown global.stream_dedup")
+		}
+	})
+
 	t.Run("invalid web port when enabled", func(t *testing.T) {
 		cfg := &Config{
 			Global: GlobalConfig{PingTime: 1, MaxMissed: 1},
@@ -54,6 +66,16 @@ func TestValidate_Errors(t *testing.T) {
 		}
 	})
 
+	t.Run("relative web public_url", func(t *testing.T) {
+		cfg := &Config{
+			Global: GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Web:    WebConfig{Enabled: true, Port: 8080, PublicURL: "dmr.example.org"},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for a web.public_url without a scheme")
+		}
+	})
+
 	t.Run("peer system missing master_ip", func(t *testing.T) {
 		cfg := &Config{
 			Global: GlobalConfig{PingTime: 1, MaxMissed: 1},
@@ -78,6 +100,306 @@ func TestValidate_Errors(t *testing.T) {
 		}
 	})
 
+	t.Run("extra port duplicates primary port", func(t *testing.T) {
+		cfg := &Config{
+			Global: GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Systems: map[string]SystemConfig{
+				"m1": {Enabled: true, Mode: "MASTER", Port: 62031, ExtraPorts: []int{62031}, Passphrase: "x", MaxPeers: 1},
+			},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for extra_ports repeating the primary port")
+		}
+	})
+
+	t.Run("cleanup interval longer than ping timeout", func(t *testing.T) {
+		cfg := &Config{
+			Global: GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Systems: map[string]SystemConfig{
+				"m1": {Enabled: true, Mode: "MASTER", Port: 62031, Passphrase: "x", MaxPeers: 1, PingTimeout: 10, CleanupInterval: 20},
+			},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for cleanup_interval exceeding ping_timeout")
+		}
+	})
+
+	t.Run("unknown TG target is not a system", func(t *testing.T) {
+		cfg := &Config{
+			Global: GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Systems: map[string]SystemConfig{
+				"m1": {Enabled: true, Mode: "MASTER", Port: 62031, Passphrase: "x", MaxPeers: 1, UnknownTGTarget: "MONITOR"},
+			},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for unknown_tg_target naming a missing system")
+		}
+	})
+
+	t.Run("empty passphrases entry", func(t *testing.T) {
+		cfg := &Config{
+			Global: GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Systems: map[string]SystemConfig{
+				"m1": {Enabled: true, Mode: "MASTER", Port: 62031, Passphrase: "x", Passphrases: []string{""}, MaxPeers: 1},
+			},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for an empty passphrases entry")
+		}
+	})
+
+	t.Run("non-positive strict_allowed_tgs entry", func(t *testing.T) {
+		cfg := &Config{
+			Global: GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Systems: map[string]SystemConfig{
+				"m1": {Enabled: true, Mode: "MASTER", Port: 62031, Passphrase: "x", MaxPeers: 1, StrictSubscriptions: true, StrictAllowedTGs: []int{91, 0}},
+			},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for a zero strict_allowed_tgs entry")
+		}
+	})
+
+	t.Run("negative max_packet_latency_ms", func(t *testing.T) {
+		cfg := &Config{
+			Global: GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Systems: map[string]SystemConfig{
+				"m1": {Enabled: true, Mode: "MASTER", Port: 62031, Passphrase: "x", MaxPeers: 1, MaxPacketLatencyMs: -1},
+			},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for negative max_packet_latency_ms")
+		}
+	})
+
+	t.Run("invalid bind ip", func(t *testing.T) {
+		cfg := &Config{
+			Global: GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Systems: map[string]SystemConfig{
+				"m1": {Enabled: true, Mode: "MASTER", IP: "10.0.0.300", Port: 62031, Passphrase: "x", MaxPeers: 1},
+			},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for invalid ip")
+		}
+	})
+
+	t.Run("invalid partner_network_id", func(t *testing.T) {
+		cfg := &Config{
+			Global: GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Systems: map[string]SystemConfig{
+				"obp": {Enabled: true, Mode: "OPENBRIDGE", Port: 62035, TargetIP: "127.0.0.1", TargetPort: 62031, NetworkID: 1, Passphrase: "x", PartnerNetworkID: -2},
+			},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for partner_network_id below -1")
+		}
+	})
+
+	t.Run("tap_system missing", func(t *testing.T) {
+		cfg := &Config{
+			Global: GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Systems: map[string]SystemConfig{
+				"m1": {Enabled: true, Mode: "MASTER", Port: 62031, Passphrase: "x", MaxPeers: 1, TapSystem: "RECORDER"},
+			},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for tap_system naming a missing system")
+		}
+	})
+
+	t.Run("talker hold without duration", func(t *testing.T) {
+		cfg := &Config{
+			Global: GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Systems: map[string]SystemConfig{
+				"m1": {Enabled: true, Mode: "MASTER", Port: 62031, Passphrase: "x", MaxPeers: 1, TalkerHoldTGs: []TalkerHoldTG{{TGID: 3100}}},
+			},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for talker_hold_tgs entry without min_ms")
+		}
+	})
+
+	t.Run("statsd without address", func(t *testing.T) {
+		cfg := &Config{
+			Global:  GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Metrics: MetricsConfig{StatsD: StatsDConfig{Enabled: true}},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for statsd enabled without an address")
+		}
+	})
+
+	t.Run("invalid source_id_check", func(t *testing.T) {
+		cfg := &Config{
+			Global: GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Systems: map[string]SystemConfig{
+				"m1": {Enabled: true, Mode: "MASTER", Port: 62031, Passphrase: "x", MaxPeers: 1, SourceIDCheck: "loose"},
+			},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for unrecognized source_id_check")
+		}
+	})
+
+	t.Run("invalid slot_contention", func(t *testing.T) {
+		cfg := &Config{
+			Global: GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Systems: map[string]SystemConfig{
+				"m1": {Enabled: true, Mode: "MASTER", Port: 62031, Passphrase: "x", MaxPeers: 1, SlotContention: "last_wins"},
+			},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for unrecognized slot_contention")
+		}
+	})
+
+	t.Run("subscription_summary without clips dir", func(t *testing.T) {
+		cfg := &Config{
+			Global: GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Systems: map[string]SystemConfig{
+				"m1": {Enabled: true, Mode: "MASTER", Port: 62031, Passphrase: "x", MaxPeers: 1, SubscriptionSummary: true},
+			},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for subscription_summary without announce_clips_dir")
+		}
+	})
+
+	t.Run("backup_master without port", func(t *testing.T) {
+		cfg := &Config{
+			Global: GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Systems: map[string]SystemConfig{
+				"m1": {Enabled: true, Mode: "MASTER", Port: 62031, Passphrase: "x", MaxPeers: 1, BackupMaster: "backup.example.net"},
+			},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for backup_master without a port")
+		}
+	})
+
+	t.Run("call limit with negative max_seconds", func(t *testing.T) {
+		cfg := &Config{
+			Global: GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Systems: map[string]SystemConfig{
+				"m1": {Enabled: true, Mode: "MASTER", Port: 62031, Passphrase: "x", MaxPeers: 1, CallLimits: []CallLimit{{TGID: 3100, MaxSeconds: -1}}},
+			},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for negative call_limits max_seconds")
+		}
+	})
+
+	t.Run("tg color code out of range", func(t *testing.T) {
+		cfg := &Config{
+			Global: GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Systems: map[string]SystemConfig{
+				"m1": {Enabled: true, Mode: "MASTER", Port: 62031, Passphrase: "x", MaxPeers: 1, TGColorCodes: []TGColorCode{{TGID: 3100, ColorCode: 16}}},
+			},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for tg_color_codes color_code above 15")
+		}
+	})
+
+	t.Run("min_peer_id above max_peer_id", func(t *testing.T) {
+		cfg := &Config{
+			Global: GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Systems: map[string]SystemConfig{
+				"m1": {Enabled: true, Mode: "MASTER", Port: 62031, Passphrase: "x", MaxPeers: 1, MinPeerID: 2000000, MaxPeerID: 1000000},
+			},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for min_peer_id above max_peer_id")
+		}
+	})
+
+	t.Run("negative daily_airtime_minutes", func(t *testing.T) {
+		cfg := &Config{
+			Global: GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Systems: map[string]SystemConfig{
+				"m1": {Enabled: true, Mode: "MASTER", Port: 62031, Passphrase: "x", MaxPeers: 1, DailyAirtimeMinutes: -1},
+			},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for negative daily_airtime_minutes")
+		}
+	})
+
+	t.Run("negative master_silence_timeout", func(t *testing.T) {
+		cfg := &Config{
+			Global: GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Systems: map[string]SystemConfig{
+				"p1": {Enabled: true, Mode: "PEER", MasterIP: "127.0.0.1", MasterPort: 62031, Passphrase: "x", RadioID: 312000, MasterSilenceTimeout: -1},
+			},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for negative master_silence_timeout")
+		}
+	})
+
+	t.Run("packet_workers below -1", func(t *testing.T) {
+		cfg := &Config{
+			Global:  GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Runtime: RuntimeConfig{PacketWorkers: -2},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for packet_workers below -1")
+		}
+	})
+
+	t.Run("talkgroup listed twice", func(t *testing.T) {
+		cfg := &Config{
+			Global:     GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Talkgroups: TalkgroupsConfig{Entries: []Talkgroup{{ID: 9, Name: "Local"}, {ID: 9, Name: "Local 2"}}},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for duplicate talkgroup directory entry")
+		}
+	})
+
+	t.Run("first_heard_greeting without mqtt", func(t *testing.T) {
+		cfg := &Config{
+			Global: GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Systems: map[string]SystemConfig{
+				"m1": {Enabled: true, Mode: "MASTER", Port: 62031, Passphrase: "x", MaxPeers: 1, FirstHeardGreeting: true},
+			},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for first_heard_greeting without mqtt")
+		}
+	})
+
+	t.Run("hash anonymization without salt", func(t *testing.T) {
+		cfg := &Config{
+			Global:  GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Privacy: PrivacyConfig{Anonymize: "hash"},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for privacy.anonymize hash without a salt")
+		}
+	})
+
+	t.Run("unknown anonymization mode", func(t *testing.T) {
+		cfg := &Config{
+			Global:  GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Privacy: PrivacyConfig{Anonymize: "scramble"},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for unknown privacy.anonymize mode")
+		}
+	})
+
+	t.Run("aprs without passcode", func(t *testing.T) {
+		cfg := &Config{
+			Global: GlobalConfig{PingTime: 1, MaxMissed: 1},
+			APRS:   APRSConfig{Enabled: true, Server: "rotate.aprs2.net:14580", Callsign: "N0CALL"},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for aprs without passcode")
+		}
+	})
+
 	t.Run("bridge references unknown system", func(t *testing.T) {
 		cfg := &Config{
 			Global:  GlobalConfig{PingTime: 1, MaxMissed: 1},
@@ -90,4 +412,239 @@ func TestValidate_Errors(t *testing.T) {
 			t.Fatal("expected error for bridge system not found")
 		}
 	})
+
+	t.Run("bridge group references unknown system", func(t *testing.T) {
+		cfg := &Config{
+			Global:  GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Systems: map[string]SystemConfig{"m1": {Enabled: true, Mode: "MASTER", Port: 1234, Passphrase: "x", MaxPeers: 1}},
+			Groups: map[string]BridgeGroup{
+				"g": {Systems: []string{"m1", "nope"}, TGIDs: []int{3100}, Timeslot: 1},
+			},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for bridge group system not found")
+		}
+	})
+
+	t.Run("bridge group collides with bridge", func(t *testing.T) {
+		cfg := &Config{
+			Global: GlobalConfig{PingTime: 1, MaxMissed: 1},
+			Systems: map[string]SystemConfig{
+				"m1": {Enabled: true, Mode: "MASTER", Port: 1234, Passphrase: "x", MaxPeers: 1},
+				"m2": {Enabled: true, Mode: "MASTER", Port: 1235, Passphrase: "x", MaxPeers: 1},
+			},
+			Bridges: map[string][]BridgeRule{"g-3100": {{System: "m1", TGID: 3100, Timeslot: 1}}},
+			Groups: map[string]BridgeGroup{
+				"g": {Systems: []string{"m1", "m2"}, TGIDs: []int{3100}, Timeslot: 1},
+			},
+		}
+		if err := validate(cfg); err == nil {
+			t.Fatal("expected error for bridge group name collision")
+		}
+	})
+}
+
+func TestLoad_BridgeGroupsExpandToMesh(t *testing.T) {
+	viper.Reset()
+
+	path := filepath.Join(t.TempDir(), "dmr-nexus.yaml")
+	yaml := `systems:
+  a:
+    mode: MASTER
+    enabled: true
+    port: 62031
+    passphrase: "x"
+    max_peers: 1
+  b:
+    mode: MASTER
+    enabled: true
+    port: 62032
+    passphrase: "x"
+    max_peers: 1
+  c:
+    mode: MASTER
+    enabled: true
+    port: 62033
+    passphrase: "x"
+    max_peers: 1
+bridges:
+  manual:
+    - system: a
+      tgid: 9
+      timeslot: 2
+bridge_groups:
+  region:
+    systems: [a, b, c]
+    tgids: [3100, 3120]
+    timeslot: 1
+`
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if len(cfg.Bridges) != 3 {
+		t.Fatalf("expected manual bridge plus one per group talkgroup, got %d bridges", len(cfg.Bridges))
+	}
+	for _, tgid := range []int{3100, 3120} {
+		rules := cfg.Bridges[groupBridgeName("region", tgid)]
+		if len(rules) != 3 {
+			t.Fatalf("TG %d: expected a rule per system, got %d", tgid, len(rules))
+		}
+		for i, system := range []string{"a", "b", "c"} {
+			r := rules[i]
+			if r.System != system || r.TGID != tgid || r.Timeslot != 1 || !r.Active {
+				t.Errorf("TG %d rule %d = %+v", tgid, i, r)
+			}
+		}
+	}
+}
+
+func TestLoad_LogTransmissionsDefaultsOn(t *testing.T) {
+	viper.Reset()
+
+	path := filepath.Join(t.TempDir(), "dmr-nexus.yaml")
+	yaml := `systems:
+  LOGGED:
+    mode: MASTER
+    enabled: true
+    port: 62031
+    passphrase: "x"
+    max_peers: 1
+  QUIET:
+    mode: MASTER
+    enabled: true
+    port: 62032
+    passphrase: "x"
+    max_peers: 1
+    log_transmissions: false
+`
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if !cfg.Systems["logged"].LogTransmissions {
+		t.Error("expected log_transmissions to default to true")
+	}
+	if cfg.Systems["quiet"].LogTransmissions {
+		t.Error("expected explicit log_transmissions: false to be kept")
+	}
+}
+
+func TestLoad_SendCloseOnShutdownDefaultsOn(t *testing.T) {
+	viper.Reset()
+
+	path := filepath.Join(t.TempDir(), "dmr-nexus.yaml")
+	yaml := `systems:
+  POLITE:
+    mode: MASTER
+    enabled: true
+    port: 62031
+    passphrase: "x"
+    max_peers: 1
+  ABRUPT:
+    mode: MASTER
+    enabled: true
+    port: 62032
+    passphrase: "x"
+    max_peers: 1
+    send_close_on_shutdown: false
+`
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if !cfg.Systems["polite"].SendCloseOnShutdown {
+		t.Error("expected send_close_on_shutdown to default to true")
+	}
+	if cfg.Systems["abrupt"].SendCloseOnShutdown {
+		t.Error("expected explicit send_close_on_shutdown: false to be kept")
+	}
+}
+
+func TestLoad_TalkgroupDirectoryFile(t *testing.T) {
+	viper.Reset()
+
+	dir := t.TempDir()
+	tgFile := filepath.Join(dir, "talkgroups.json")
+	if err := os.WriteFile(tgFile, []byte(`[
+  {"id": 3100, "name": "USA", "description": "Nationwide"},
+  {"id": 9, "name": "Local"}
+]`), 0o600); err != nil {
+		t.Fatalf("write talkgroups: %v", err)
+	}
+
+	path := filepath.Join(dir, "dmr-nexus.yaml")
+	yaml := `talkgroups:
+  file: ` + tgFile + `
+  entries:
+    - id: 3100
+      name: "US"
+    - id: 91
+      name: "Worldwide"
+`
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	want := []Talkgroup{
+		{ID: 9, Name: "Local"},
+		{ID: 91, Name: "Worldwide"},
+		{ID: 3100, Name: "USA", Description: "Nationwide"},
+	}
+	if len(cfg.Talkgroups.Entries) != len(want) {
+		t.Fatalf("expected %d talkgroups, got %+v", len(want), cfg.Talkgroups.Entries)
+	}
+	for i, tg := range want {
+		if cfg.Talkgroups.Entries[i] != tg {
+			t.Errorf("talkgroup %d = %+v, want %+v", i, cfg.Talkgroups.Entries[i], tg)
+		}
+	}
+}
+
+func TestSanitized_RedactsSecrets(t *testing.T) {
+	cfg := &Config{
+		Web:  WebConfig{Username: "admin", Password: "webpw"},
+		MQTT: MQTTConfig{Password: "mqttpw"},
+		APRS: APRSConfig{Callsign: "N0CALL", Passcode: "13023"},
+		Systems: map[string]SystemConfig{
+			"MASTER-1": {Mode: "MASTER", Passphrase: "s3cret", Passphrases: []string{"old"}, AuthWebhook: "https://auth.example/?token=t"},
+			"OPEN":     {Mode: "MASTER"},
+		},
+	}
+
+	s := cfg.Sanitized()
+	if s.Web.Password != redacted || s.MQTT.Password != redacted {
+		t.Errorf("Passwords not redacted: web=%q mqtt=%q", s.Web.Password, s.MQTT.Password)
+	}
+	if s.APRS.Passcode != redacted || s.APRS.Callsign != "N0CALL" {
+		t.Errorf("APRS passcode not redacted: %+v", s.APRS)
+	}
+	if s.Web.Username != "admin" {
+		t.Errorf("Username should be kept, got %q", s.Web.Username)
+	}
+	if sys := s.Systems["MASTER-1"]; sys.Passphrase != redacted || sys.Passphrases[0] != redacted || sys.AuthWebhook != redacted {
+		t.Errorf("System secrets not redacted: %+v", sys)
+	}
+	if s.Systems["OPEN"].Passphrase != "" {
+		t.Error("Empty passphrase should stay empty")
+	}
+	if cfg.Systems["MASTER-1"].Passphrase != "s3cret" || cfg.Systems["MASTER-1"].Passphrases[0] != "old" {
+		t.Error("Sanitized modified the original configuration")
+	}
 }
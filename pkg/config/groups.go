@@ -0,0 +1,34 @@
+package config
+
+import "fmt"
+
+// groupBridgeName names the bridge a group generates for one talkgroup
+func groupBridgeName(group string, tgid int) string {
+	return fmt.Sprintf("%s-%d", group, tgid)
+}
+
+// expandBridgeGroups compiles each bridge group into ordinary bridges: for
+// every talkgroup, one bridge holding an active rule per member system, so
+// traffic from any member reaches all the others.
+func expandBridgeGroups(cfg *Config) {
+	if len(cfg.Groups) == 0 {
+		return
+	}
+	if cfg.Bridges == nil {
+		cfg.Bridges = make(map[string][]BridgeRule)
+	}
+	for groupName, group := range cfg.Groups {
+		for _, tgid := range group.TGIDs {
+			rules := make([]BridgeRule, 0, len(group.Systems))
+			for _, system := range group.Systems {
+				rules = append(rules, BridgeRule{
+					System:   system,
+					TGID:     tgid,
+					Timeslot: group.Timeslot,
+					Active:   true,
+				})
+			}
+			cfg.Bridges[groupBridgeName(groupName, tgid)] = rules
+		}
+	}
+}
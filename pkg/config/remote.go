@@ -0,0 +1,129 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+var (
+	// RemoteConfigCachePath is where the last good remote configuration is
+	// cached so a node can still start when the central store is unreachable
+	RemoteConfigCachePath = filepath.Join("data", "config-cache")
+
+	// RemoteConfigTimeout bounds how long fetching a remote configuration may take
+	RemoteConfigTimeout = 10 * time.Second
+)
+
+// isRemoteConfig reports whether the config location is an http(s) URL
+func isRemoteConfig(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// remoteConfigType derives the viper config type from the URL path extension,
+// defaulting to yaml
+func remoteConfigType(location string) string {
+	u, err := url.Parse(location)
+	if err != nil {
+		return "yaml"
+	}
+	switch strings.ToLower(strings.TrimPrefix(path.Ext(u.Path), ".")) {
+	case "json":
+		return "json"
+	case "toml":
+		return "toml"
+	default:
+		return "yaml"
+	}
+}
+
+// loadRemote fetches the configuration from a URL. A fetched configuration is
+// only applied after it validates, at which point it is also written to the
+// cache. If the fetch fails or the fetched configuration is invalid, the last
+// cached configuration is used instead.
+func loadRemote(location string) (*Config, error) {
+	configType := remoteConfigType(location)
+
+	data, err := fetchRemoteConfig(location)
+	if err == nil {
+		cfg, decodeErr := loadFromBytes(data, configType)
+		if decodeErr == nil {
+			// A cache write failure doesn't prevent startup; the node just
+			// won't have a fallback until the next successful fetch
+			_ = writeConfigCache(data)
+			return cfg, nil
+		}
+		err = fmt.Errorf("remote config rejected: %w", decodeErr)
+	}
+
+	cached, cacheErr := os.ReadFile(RemoteConfigCachePath)
+	if cacheErr != nil {
+		return nil, fmt.Errorf("failed to load config from %s: %w (no cached config: %v)", location, err, cacheErr)
+	}
+
+	cfg, decodeErr := loadFromBytes(cached, configType)
+	if decodeErr != nil {
+		return nil, fmt.Errorf("failed to load config from %s: %w (cached config invalid: %v)", location, err, decodeErr)
+	}
+	return cfg, nil
+}
+
+// fetchRemoteConfig downloads the raw configuration document
+func fetchRemoteConfig(location string) ([]byte, error) {
+	client := &http.Client{Timeout: RemoteConfigTimeout}
+
+	resp, err := client.Get(location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch config: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch config: unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config response: %w", err)
+	}
+	return data, nil
+}
+
+// loadFromBytes parses and validates a configuration document
+func loadFromBytes(data []byte, configType string) (*Config, error) {
+	viper.Reset()
+	setDefaults()
+	viper.SetEnvPrefix("DMR")
+	viper.AutomaticEnv()
+	viper.SetConfigType(configType)
+
+	if err := viper.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	return decode()
+}
+
+// writeConfigCache atomically replaces the cached configuration
+func writeConfigCache(data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(RemoteConfigCachePath), 0o755); err != nil {
+		return err
+	}
+
+	tmp := RemoteConfigCachePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, RemoteConfigCachePath)
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const remoteValidConfig = `
+server:
+  name: "Remote-Nexus"
+web:
+  enabled: true
+  port: 8181
+`
+
+const remoteInvalidConfig = `
+global:
+  ping_time: 0
+`
+
+// withRemoteCache points the remote config cache at a temp dir for the test
+func withRemoteCache(t *testing.T) string {
+	t.Helper()
+	cachePath := filepath.Join(t.TempDir(), "config-cache")
+	previous := RemoteConfigCachePath
+	RemoteConfigCachePath = cachePath
+	t.Cleanup(func() { RemoteConfigCachePath = previous })
+	return cachePath
+}
+
+func serveConfig(status int, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+}
+
+func TestLoad_RemoteValidConfig(t *testing.T) {
+	cachePath := withRemoteCache(t)
+	srv := serveConfig(http.StatusOK, remoteValidConfig)
+	defer srv.Close()
+
+	cfg, err := Load(srv.URL + "/dmr-nexus.yaml")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if cfg.Server.Name != "Remote-Nexus" {
+		t.Errorf("expected server name Remote-Nexus, got %q", cfg.Server.Name)
+	}
+	if cfg.Web.Port != 8181 {
+		t.Errorf("expected web port 8181, got %d", cfg.Web.Port)
+	}
+	if cfg.Global.PingTime != 5 {
+		t.Errorf("expected defaults to apply to remote config, got ping_time %d", cfg.Global.PingTime)
+	}
+
+	cached, err := os.ReadFile(cachePath)
+	if err != nil {
+		t.Fatalf("expected remote config to be cached: %v", err)
+	}
+	if string(cached) != remoteValidConfig {
+		t.Error("cached config does not match fetched config")
+	}
+}
+
+func TestLoad_RemoteInvalidConfigRejected(t *testing.T) {
+	cachePath := withRemoteCache(t)
+	srv := serveConfig(http.StatusOK, remoteInvalidConfig)
+	defer srv.Close()
+
+	if _, err := Load(srv.URL + "/dmr-nexus.yaml"); err == nil {
+		t.Fatal("expected error for invalid remote config with no cache")
+	}
+	if _, err := os.Stat(cachePath); !os.IsNotExist(err) {
+		t.Error("invalid remote config must not be cached")
+	}
+}
+
+func TestLoad_RemoteFallsBackToCache(t *testing.T) {
+	cachePath := withRemoteCache(t)
+	if err := os.WriteFile(cachePath, []byte(remoteValidConfig), 0o600); err != nil {
+		t.Fatalf("failed to seed cache: %v", err)
+	}
+
+	t.Run("fetch fails", func(t *testing.T) {
+		srv := serveConfig(http.StatusInternalServerError, "")
+		defer srv.Close()
+
+		cfg, err := Load(srv.URL + "/dmr-nexus.yaml")
+		if err != nil {
+			t.Fatalf("expected cache fallback, got error: %v", err)
+		}
+		if cfg.Server.Name != "Remote-Nexus" {
+			t.Errorf("expected cached server name, got %q", cfg.Server.Name)
+		}
+	})
+
+	t.Run("fetched config invalid", func(t *testing.T) {
+		srv := serveConfig(http.StatusOK, remoteInvalidConfig)
+		defer srv.Close()
+
+		cfg, err := Load(srv.URL + "/dmr-nexus.yaml")
+		if err != nil {
+			t.Fatalf("expected cache fallback, got error: %v", err)
+		}
+		if cfg.Global.PingTime != 5 {
+			t.Errorf("expected cached config to be applied, got ping_time %d", cfg.Global.PingTime)
+		}
+	})
+}
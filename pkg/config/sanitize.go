@@ -0,0 +1,37 @@
+package config
+
+// redacted replaces secrets in sanitized configuration
+const redacted = "REDACTED"
+
+// Sanitized returns a copy of the configuration with passphrases, passwords,
+// passcodes and webhook URLs (which may embed tokens) redacted, safe to hand to support
+func (c *Config) Sanitized() Config {
+	out := *c
+
+	out.Web.Password = redactIfSet(c.Web.Password)
+	out.MQTT.Password = redactIfSet(c.MQTT.Password)
+	out.APRS.Passcode = redactIfSet(c.APRS.Passcode)
+	out.Privacy.Salt = redactIfSet(c.Privacy.Salt)
+
+	out.Systems = make(map[string]SystemConfig, len(c.Systems))
+	for name, sys := range c.Systems {
+		sys.Passphrase = redactIfSet(sys.Passphrase)
+		if len(sys.Passphrases) > 0 {
+			passphrases := make([]string, len(sys.Passphrases))
+			for i, p := range sys.Passphrases {
+				passphrases[i] = redactIfSet(p)
+			}
+			sys.Passphrases = passphrases
+		}
+		sys.AuthWebhook = redactIfSet(sys.AuthWebhook)
+		out.Systems[name] = sys
+	}
+	return out
+}
+
+func redactIfSet(s string) string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// TalkgroupsConfig is the talkgroup directory: a catalog of talkgroup names
+// served at /api/talkgroups and published to MQTT at startup
+type TalkgroupsConfig struct {
+	File    string      `mapstructure:"file"`    // JSON list of entries; replaces inline entries with the same ID
+	Entries []Talkgroup `mapstructure:"entries"` // Inline entries
+}
+
+// Talkgroup is one talkgroup directory entry
+type Talkgroup struct {
+	ID          int    `mapstructure:"id" json:"id"`
+	Name        string `mapstructure:"name" json:"name"`
+	Description string `mapstructure:"description" json:"description"`
+}
+
+// loadTalkgroupsFile merges the directory file, if one is set, into the
+// inline entries and sorts the result by ID
+func loadTalkgroupsFile(tgs *TalkgroupsConfig) error {
+	if tgs.File != "" {
+		data, err := os.ReadFile(tgs.File)
+		if err != nil {
+			return fmt.Errorf("failed to read talkgroups file: %w", err)
+		}
+		var fromFile []Talkgroup
+		if err := json.Unmarshal(data, &fromFile); err != nil {
+			return fmt.Errorf("failed to parse talkgroups file %s: %w", tgs.File, err)
+		}
+
+		inline := make(map[int]int, len(tgs.Entries))
+		for i, tg := range tgs.Entries {
+			if _, dup := inline[tg.ID]; !dup {
+				inline[tg.ID] = i
+			}
+		}
+		for _, tg := range fromFile {
+			if i, ok := inline[tg.ID]; ok {
+				tgs.Entries[i] = tg
+				continue
+			}
+			tgs.Entries = append(tgs.Entries, tg)
+		}
+	}
+
+	sort.SliceStable(tgs.Entries, func(i, j int) bool {
+		return tgs.Entries[i].ID < tgs.Entries[j].ID
+	})
+	return nil
+}
@@ -2,6 +2,9 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"strconv"
 	"strings"
 )
 
@@ -15,11 +18,44 @@ func validate(cfg *Config) error {
 		return fmt.Errorf("global.max_missed must be positive")
 	}
 
+	for i, sp := range cfg.Global.StreamPriorities {
+		if sp.SourceID <= 0 {
+			return fmt.Errorf("global.stream_priorities[%d]: source_id must be positive", i)
+		}
+		if sp.TGID < 0 {
+			return fmt.Errorf("global.stream_priorities[%d]: tgid must not be negative", i)
+		}
+		if sp.Priority <= 0 {
+			return fmt.Errorf("global.stream_priorities[%d]: priority must be positive", i)
+		}
+	}
+
+	switch cfg.Global.StreamDedup {
+	case "", "per_source", "first_wins":
+	default:
+		return fmt.Errorf("global.stream_dedup must be per_source or first_wins, got %q", cfg.Global.StreamDedup)
+	}
+
 	// Validate web config
 	if cfg.Web.Enabled {
-		if cfg.Web.Port <= 0 || cfg.Web.Port > 65535 {
+		if cfg.Web.UnixSocket == "" && (cfg.Web.Port <= 0 || cfg.Web.Port > 65535) {
 			return fmt.Errorf("web.port must be between 1 and 65535")
 		}
+		if cfg.Web.WSPingInterval > 0 && cfg.Web.WSPongTimeout > 0 && cfg.Web.WSPongTimeout <= cfg.Web.WSPingInterval {
+			return fmt.Errorf("web.ws_pong_timeout must be greater than web.ws_ping_interval")
+		}
+		if cfg.Web.CORS.MaxAge < 0 {
+			return fmt.Errorf("web.cors.max_age must not be negative")
+		}
+		if cfg.Web.UserCacheSize < 0 || cfg.Web.UserCacheTTL < 0 {
+			return fmt.Errorf("web.user_cache_size and web.user_cache_ttl must not be negative")
+		}
+		if cfg.Web.PublicURL != "" {
+			u, err := url.Parse(cfg.Web.PublicURL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("web.public_url must be an absolute http or https URL, got %q", cfg.Web.PublicURL)
+			}
+		}
 	}
 
 	// Validate MQTT config
@@ -27,6 +63,74 @@ func validate(cfg *Config) error {
 		if cfg.MQTT.Broker == "" {
 			return fmt.Errorf("mqtt.broker is required when mqtt is enabled")
 		}
+		if cfg.MQTT.StatusInterval < 0 {
+			return fmt.Errorf("mqtt.status_interval must not be negative")
+		}
+	}
+
+	// Validate APRS-IS uplink
+	if cfg.APRS.Enabled {
+		if cfg.APRS.Server == "" || cfg.APRS.Callsign == "" || cfg.APRS.Passcode == "" {
+			return fmt.Errorf("aprs.server, aprs.callsign and aprs.passcode are required when aprs is enabled")
+		}
+		if cfg.APRS.Interval < 0 {
+			return fmt.Errorf("aprs.interval must not be negative")
+		}
+	}
+
+	// Validate runtime tuning
+	if cfg.Runtime.GOMAXPROCS < 0 {
+		return fmt.Errorf("runtime.gomaxprocs must not be negative")
+	}
+	if cfg.Runtime.PacketWorkers < -1 {
+		return fmt.Errorf("runtime.packet_workers must be -1, 0 or positive")
+	}
+
+	// Validate talkgroup directory
+	seenTGs := make(map[int]bool, len(cfg.Talkgroups.Entries))
+	for i, tg := range cfg.Talkgroups.Entries {
+		if tg.ID <= 0 || tg.Name == "" {
+			return fmt.Errorf("talkgroups.entries[%d]: id must be positive and name set", i)
+		}
+		if seenTGs[tg.ID] {
+			return fmt.Errorf("talkgroups: talkgroup %d listed more than once", tg.ID)
+		}
+		seenTGs[tg.ID] = true
+	}
+
+	// Validate StatsD export
+	if cfg.Metrics.StatsD.Enabled {
+		if cfg.Metrics.StatsD.Address == "" {
+			return fmt.Errorf("metrics.statsd.address is required when statsd is enabled")
+		}
+		if cfg.Metrics.StatsD.FlushInterval < 0 {
+			return fmt.Errorf("metrics.statsd.flush_interval must not be negative")
+		}
+	}
+
+	for _, bound := range cfg.Metrics.PacketSizeBuckets {
+		if bound <= 0 {
+			return fmt.Errorf("metrics.packet_size_buckets must be positive, got %d", bound)
+		}
+	}
+
+	// Validate database maintenance
+	if cfg.Database.RetentionDays < 0 {
+		return fmt.Errorf("database.retention_days must not be negative")
+	}
+	if cfg.Database.VacuumInterval < 0 {
+		return fmt.Errorf("database.vacuum_interval must not be negative")
+	}
+
+	// Validate privacy mode
+	switch cfg.Privacy.Anonymize {
+	case "", "omit":
+	case "hash":
+		if cfg.Privacy.Salt == "" {
+			return fmt.Errorf("privacy.salt is required when privacy.anonymize is hash")
+		}
+	default:
+		return fmt.Errorf("privacy.anonymize must be hash or omit, got %q", cfg.Privacy.Anonymize)
 	}
 
 	// Validate systems
@@ -52,9 +156,27 @@ func validate(cfg *Config) error {
 			if sys.Passphrase == "" {
 				return fmt.Errorf("system %s: passphrase is required for MASTER mode", name)
 			}
+			for i, passphrase := range sys.Passphrases {
+				if passphrase == "" {
+					return fmt.Errorf("system %s: passphrases[%d] must not be empty", name, i)
+				}
+			}
+			if sys.IP != "" && net.ParseIP(sys.IP) == nil {
+				return fmt.Errorf("system %s: ip %q is not a valid IPv4 or IPv6 address", name, sys.IP)
+			}
 			if sys.MaxPeers <= 0 {
 				return fmt.Errorf("system %s: max_peers must be positive", name)
 			}
+			seen := map[int]bool{sys.Port: true}
+			for _, port := range sys.ExtraPorts {
+				if port <= 0 || port > 65535 {
+					return fmt.Errorf("system %s: extra_ports must be between 1 and 65535", name)
+				}
+				if seen[port] {
+					return fmt.Errorf("system %s: extra_ports contains duplicate port %d", name, port)
+				}
+				seen[port] = true
+			}
 
 		case "PEER":
 			if sys.MasterIP == "" {
@@ -69,6 +191,9 @@ func validate(cfg *Config) error {
 			if sys.RadioID <= 0 {
 				return fmt.Errorf("system %s: radio_id is required for PEER mode", name)
 			}
+			if sys.MasterSilenceTimeout < 0 {
+				return fmt.Errorf("system %s: master_silence_timeout must not be negative", name)
+			}
 
 		case "OPENBRIDGE":
 			if sys.TargetIP == "" {
@@ -80,15 +205,168 @@ func validate(cfg *Config) error {
 			if sys.NetworkID <= 0 {
 				return fmt.Errorf("system %s: network_id is required for OPENBRIDGE mode", name)
 			}
+			if sys.PartnerNetworkID < -1 {
+				return fmt.Errorf("system %s: partner_network_id must be -1, 0 or positive", name)
+			}
 			if sys.Passphrase == "" {
 				return fmt.Errorf("system %s: passphrase is required for OPENBRIDGE mode", name)
 			}
 		}
 
+		if sys.PingTimeout < 0 || sys.CleanupInterval < 0 || sys.SubscriberLocationTTL < 0 || sys.MuteWindowMs < 0 {
+			return fmt.Errorf("system %s: ping_timeout, cleanup_interval, subscriber_location_ttl and mute_window_ms must not be negative", name)
+		}
+		if sys.PingTimeout > 0 && sys.CleanupInterval > sys.PingTimeout {
+			return fmt.Errorf("system %s: cleanup_interval must not exceed ping_timeout", name)
+		}
+		if sys.MaxPacketLatencyMs < 0 {
+			return fmt.Errorf("system %s: max_packet_latency_ms must not be negative", name)
+		}
+
+		if sys.MaxDescriptionLength < 0 {
+			return fmt.Errorf("system %s: max_description_length must not be negative", name)
+		}
+
+		if sys.MetricsSampleRate < 0 {
+			return fmt.Errorf("system %s: metrics_sample_rate must not be negative", name)
+		}
+
+		if sys.PeerSendQueue < 0 {
+			return fmt.Errorf("system %s: peer_send_queue must not be negative", name)
+		}
+
+		if sys.KeyupGuardMs < 0 {
+			return fmt.Errorf("system %s: keyup_guard_ms must not be negative", name)
+		}
+
+		if sys.UnknownTGTarget != "" {
+			if sys.UnknownTGTarget == name {
+				return fmt.Errorf("system %s: unknown_tg_target must be a different system", name)
+			}
+			if _, ok := cfg.Systems[sys.UnknownTGTarget]; !ok {
+				return fmt.Errorf("system %s: unknown_tg_target %q not found", name, sys.UnknownTGTarget)
+			}
+		}
+
+		if sys.TapSystem != "" {
+			if sys.TapSystem == name {
+				return fmt.Errorf("system %s: tap_system must be a different system", name)
+			}
+			if _, ok := cfg.Systems[sys.TapSystem]; !ok {
+				return fmt.Errorf("system %s: tap_system %q not found", name, sys.TapSystem)
+			}
+		}
+		if sys.TapPeerID < 0 {
+			return fmt.Errorf("system %s: tap_peer_id must not be negative", name)
+		}
+
+		switch sys.SourceIDCheck {
+		case "", "strict", "grace":
+		default:
+			return fmt.Errorf("system %s: source_id_check must be strict or grace, got %q", name, sys.SourceIDCheck)
+		}
+
+		switch sys.SlotContention {
+		case "", "first_wins", "private_priority":
+		default:
+			return fmt.Errorf("system %s: slot_contention must be first_wins or private_priority, got %q", name, sys.SlotContention)
+		}
+
+		if sys.AnnounceCaller && sys.AnnounceClipsDir == "" {
+			return fmt.Errorf("system %s: announce_caller requires announce_clips_dir", name)
+		}
+		if sys.SubscriptionSummary && sys.AnnounceClipsDir == "" {
+			return fmt.Errorf("system %s: subscription_summary requires announce_clips_dir", name)
+		}
+		if sys.ReplayWindowMs < 0 {
+			return fmt.Errorf("system %s: replay_window_ms must not be negative", name)
+		}
+		if sys.UnlinkIdleMinutes < 0 {
+			return fmt.Errorf("system %s: unlink_idle_minutes must not be negative", name)
+		}
+		if sys.EchoTG < 0 {
+			return fmt.Errorf("system %s: echo_tg must not be negative", name)
+		}
+		if sys.SubscriptionSummaryTG < 0 {
+			return fmt.Errorf("system %s: subscription_summary_tg must not be negative", name)
+		}
+		if sys.FirstHeardGreeting && !cfg.MQTT.Enabled {
+			return fmt.Errorf("system %s: first_heard_greeting requires mqtt to be enabled", name)
+		}
+		if sys.PrivateToGroupTG < 0 {
+			return fmt.Errorf("system %s: private_to_group_tg must not be negative", name)
+		}
+		if sys.BackupMaster != "" {
+			host, port, err := net.SplitHostPort(sys.BackupMaster)
+			if n, perr := strconv.Atoi(port); err != nil || host == "" || perr != nil || n < 1 || n > 65535 {
+				return fmt.Errorf("system %s: backup_master must be host:port, got %q", name, sys.BackupMaster)
+			}
+		}
+
+		for i, ro := range sys.ReceiveOnlyTGs {
+			if ro.TGID <= 0 {
+				return fmt.Errorf("system %s: receive_only_tgs[%d]: tgid must be positive", name, i)
+			}
+		}
+
+		if sys.MaxCallSeconds < 0 {
+			return fmt.Errorf("system %s: max_call_seconds must not be negative", name)
+		}
+		for i, cl := range sys.CallLimits {
+			if cl.TGID <= 0 || cl.MaxSeconds < 0 {
+				return fmt.Errorf("system %s: call_limits[%d]: tgid must be positive and max_seconds not negative", name, i)
+			}
+		}
+		for i, tc := range sys.TGColorCodes {
+			if tc.TGID <= 0 || tc.ColorCode < 0 || tc.ColorCode > 15 {
+				return fmt.Errorf("system %s: tg_color_codes[%d]: tgid must be positive and color_code 0-15", name, i)
+			}
+		}
+
+		if sys.MstNakSilenceAfter < -1 || sys.MstNakSilence < 0 {
+			return fmt.Errorf("system %s: mst_nak_silence_after must be -1 or more and mst_nak_silence not negative", name)
+		}
+
+		if sys.MinPeerID < 0 || sys.MaxPeerID < 0 {
+			return fmt.Errorf("system %s: min_peer_id and max_peer_id must not be negative", name)
+		}
+		if sys.MaxPeerID > 0 && sys.MinPeerID > sys.MaxPeerID {
+			return fmt.Errorf("system %s: min_peer_id must not exceed max_peer_id", name)
+		}
+
+		if sys.DailyAirtimeMinutes < 0 {
+			return fmt.Errorf("system %s: daily_airtime_minutes must not be negative", name)
+		}
+
+		for i, th := range sys.TalkerHoldTGs {
+			if th.TGID <= 0 || th.MinMs <= 0 {
+				return fmt.Errorf("system %s: talker_hold_tgs[%d]: tgid and min_ms must be positive", name, i)
+			}
+		}
+
+		if sys.AuthWebhook != "" && !strings.HasPrefix(sys.AuthWebhook, "http://") && !strings.HasPrefix(sys.AuthWebhook, "https://") {
+			return fmt.Errorf("system %s: auth_webhook must be an http(s) URL", name)
+		}
+
+		for i, pa := range sys.PeerAllowedTGs {
+			if pa.PeerID <= 0 {
+				return fmt.Errorf("system %s: peer_allowed_tgs[%d]: peer_id must be positive", name, i)
+			}
+			if len(pa.TGs) == 0 {
+				return fmt.Errorf("system %s: peer_allowed_tgs[%d]: tgs must not be empty", name, i)
+			}
+		}
+
+		for i, tg := range sys.StrictAllowedTGs {
+			if tg <= 0 {
+				return fmt.Errorf("system %s: strict_allowed_tgs[%d]: talkgroup must be positive", name, i)
+			}
+		}
+
 		// Validate ACLs if enabled
 		if sys.UseACL || cfg.Global.UseACL {
 			// Just basic format check for now
-			acls := []string{sys.RegACL, sys.SubACL, sys.TG1ACL, sys.TG2ACL, sys.TGACL}
+			acls := []string{sys.RegACL, sys.SubACL, sys.TG1ACL, sys.TG2ACL, sys.TGACL, sys.PrivateCallACL, sys.TapACL}
 			for _, acl := range acls {
 				if acl != "" {
 					if !strings.HasPrefix(acl, "PERMIT:") && !strings.HasPrefix(acl, "DENY:") {
@@ -120,5 +398,36 @@ func validate(cfg *Config) error {
 		}
 	}
 
+	// Validate bridge groups
+	for groupName, group := range cfg.Groups {
+		if len(group.Systems) < 2 {
+			return fmt.Errorf("bridge group %s: at least two systems are required", groupName)
+		}
+		seen := make(map[string]bool, len(group.Systems))
+		for _, system := range group.Systems {
+			if _, exists := cfg.Systems[system]; !exists {
+				return fmt.Errorf("bridge group %s: system %s not found", groupName, system)
+			}
+			if seen[system] {
+				return fmt.Errorf("bridge group %s: system %s listed twice", groupName, system)
+			}
+			seen[system] = true
+		}
+		if len(group.TGIDs) == 0 {
+			return fmt.Errorf("bridge group %s: at least one tgid is required", groupName)
+		}
+		for _, tgid := range group.TGIDs {
+			if tgid <= 0 {
+				return fmt.Errorf("bridge group %s: tgid must be positive", groupName)
+			}
+			if _, exists := cfg.Bridges[groupBridgeName(groupName, tgid)]; exists {
+				return fmt.Errorf("bridge group %s: bridge %s already defined", groupName, groupBridgeName(groupName, tgid))
+			}
+		}
+		if group.Timeslot != 1 && group.Timeslot != 2 {
+			return fmt.Errorf("bridge group %s: timeslot must be 1 or 2", groupName)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,34 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// AirtimeRepository handles daily airtime usage database operations
+type AirtimeRepository struct {
+	db *gorm.DB
+}
+
+// NewAirtimeRepository creates a new airtime repository
+func NewAirtimeRepository(db *gorm.DB) *AirtimeRepository {
+	return &AirtimeRepository{db: db}
+}
+
+// Save inserts or updates a radio ID's usage for the day
+func (r *AirtimeRepository) Save(usage *AirtimeUsage) error {
+	return r.db.Save(usage).Error
+}
+
+// GetDay retrieves every radio ID's usage on a system for one day
+func (r *AirtimeRepository) GetDay(system, day string) ([]AirtimeUsage, error) {
+	var usage []AirtimeUsage
+	err := r.db.Where("system = ? AND day = ?", system, day).Find(&usage).Error
+	return usage, err
+}
+
+// DeleteBefore removes usage from days before day and returns how many rows
+// were deleted
+func (r *AirtimeRepository) DeleteBefore(day string) (int64, error) {
+	result := r.db.Where("day < ?", day).Delete(&AirtimeUsage{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+)
+
+func TestAirtimeRepository(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	db, err := NewDB(Config{Path: filepath.Join(t.TempDir(), "airtime.db")}, log)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	repo := NewAirtimeRepository(db.GetDB())
+	rows := []AirtimeUsage{
+		{System: "MASTER-1", RadioID: 3120001, Day: "2026-03-08", Seconds: 300},
+		{System: "MASTER-1", RadioID: 3120001, Day: "2026-03-09", Seconds: 60},
+		{System: "MASTER-2", RadioID: 3120001, Day: "2026-03-09", Seconds: 90},
+	}
+	for i := range rows {
+		if err := repo.Save(&rows[i]); err != nil {
+			t.Fatalf("Save error: %v", err)
+		}
+	}
+	if err := repo.Save(&AirtimeUsage{System: "MASTER-1", RadioID: 3120001, Day: "2026-03-09", Seconds: 75}); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+
+	usage, err := repo.GetDay("MASTER-1", "2026-03-09")
+	if err != nil {
+		t.Fatalf("GetDay error: %v", err)
+	}
+	if len(usage) != 1 || usage[0].RadioID != 3120001 || usage[0].Seconds != 75 {
+		t.Errorf("GetDay = %+v, want the updated row for MASTER-1 only", usage)
+	}
+
+	deleted, err := repo.DeleteBefore("2026-03-09")
+	if err != nil || deleted != 1 {
+		t.Errorf("DeleteBefore = %d, %v; want 1 deleted", deleted, err)
+	}
+}
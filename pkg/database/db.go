@@ -83,7 +83,7 @@ func NewDB(cfg Config, log *logger.Logger) (*DB, error) {
 	}
 
 	// Run migrations
-	if err := db.AutoMigrate(&Transmission{}, &DMRUser{}); err != nil {
+	if err := db.AutoMigrate(&Transmission{}, &DMRUser{}, &UserPosition{}, &Position{}, &Message{}, &AirtimeUsage{}); err != nil {
 		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
 
@@ -2,6 +2,7 @@ package database
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -510,3 +511,62 @@ func TestTransmissionRepository_DeleteOlderThan(t *testing.T) {
 		t.Errorf("Expected 1 remaining transmission, got %d", len(transmissions))
 	}
 }
+
+func TestTransmissionRepository_Vacuum(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	dbPath := filepath.Join(t.TempDir(), "vacuum.db")
+
+	db, err := NewDB(Config{Path: dbPath}, log)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatalf("failed to close db: %v", err)
+		}
+	}()
+
+	repo := NewTransmissionRepository(db.GetDB())
+
+	old := time.Now().Add(-48 * time.Hour)
+	rows := make([]Transmission, 5000)
+	for i := range rows {
+		rows[i] = Transmission{
+			RadioID:     uint32(3100000 + i),
+			TalkgroupID: 91,
+			Timeslot:    1,
+			StreamID:    uint32(i),
+			StartTime:   old,
+			EndTime:     old.Add(time.Second),
+		}
+	}
+	if err := db.GetDB().CreateInBatches(rows, 500).Error; err != nil {
+		t.Fatalf("Failed to insert transmissions: %v", err)
+	}
+	if err := db.GetDB().Exec("PRAGMA wal_checkpoint(TRUNCATE)").Error; err != nil {
+		t.Fatalf("Failed to checkpoint: %v", err)
+	}
+	before, err := os.Stat(dbPath)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+
+	deleted, err := repo.DeleteOlderThan(time.Now().Add(-24 * time.Hour))
+	if err != nil {
+		t.Fatalf("DeleteOlderThan: %v", err)
+	}
+	if deleted != int64(len(rows)) {
+		t.Fatalf("Expected %d rows deleted, got %d", len(rows), deleted)
+	}
+
+	if err := repo.Vacuum(); err != nil {
+		t.Fatalf("Vacuum: %v", err)
+	}
+	after, err := os.Stat(dbPath)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if after.Size() >= before.Size() {
+		t.Errorf("Expected file to shrink after vacuum: before=%d after=%d", before.Size(), after.Size())
+	}
+}
@@ -1,6 +1,8 @@
 package database
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -63,6 +65,26 @@ func (r *DMRUserRepository) GetByCallsign(callsign string) (*DMRUser, error) {
 	return &user, nil
 }
 
+// SetPosition stores the last known location of a radio ID
+func (r *DMRUserRepository) SetPosition(radioID uint32, latitude, longitude float64) error {
+	return r.db.Save(&UserPosition{
+		RadioID:   radioID,
+		Latitude:  latitude,
+		Longitude: longitude,
+		UpdatedAt: time.Now(),
+	}).Error
+}
+
+// GetPosition retrieves the last known location of a radio ID
+func (r *DMRUserRepository) GetPosition(radioID uint32) (*UserPosition, error) {
+	var pos UserPosition
+	err := r.db.Where("radio_id = ?", radioID).First(&pos).Error
+	if err != nil {
+		return nil, err
+	}
+	return &pos, nil
+}
+
 // Count returns the total number of users in the database
 func (r *DMRUserRepository) Count() (int64, error) {
 	var count int64
@@ -310,3 +310,46 @@ func TestDMRUserRepository_UpsertBatch(t *testing.T) {
 		t.Errorf("Expected 100 users, got %d", count)
 	}
 }
+
+func TestDMRUserRepository_Position(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	dbPath := "/tmp/test_dmr_user_position.db"
+	defer func() {
+		if err := os.Remove(dbPath); err != nil && !os.IsNotExist(err) {
+			t.Logf("Failed to remove test database: %v", err)
+		}
+	}()
+
+	cfg := Config{Path: dbPath}
+	db, err := NewDB(cfg, log)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Logf("Failed to close database: %v", err)
+		}
+	}()
+
+	repo := NewDMRUserRepository(db.GetDB())
+
+	if _, err := repo.GetPosition(3138617); err == nil {
+		t.Fatal("Expected error for radio ID without a position")
+	}
+
+	if err := repo.SetPosition(3138617, 42.33, -83.05); err != nil {
+		t.Fatalf("Failed to set position: %v", err)
+	}
+	// A user sync must not touch the stored position
+	if err := repo.Upsert(&DMRUser{RadioID: 3138617, Callsign: "K7ABC"}); err != nil {
+		t.Fatalf("Failed to upsert user: %v", err)
+	}
+
+	pos, err := repo.GetPosition(3138617)
+	if err != nil {
+		t.Fatalf("Failed to get position: %v", err)
+	}
+	if pos.Latitude != 42.33 || pos.Longitude != -83.05 {
+		t.Errorf("Expected 42.33,-83.05, got %v,%v", pos.Latitude, pos.Longitude)
+	}
+}
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MessageRepository handles radio text message database operations
+type MessageRepository struct {
+	db *gorm.DB
+}
+
+// NewMessageRepository creates a new message repository
+func NewMessageRepository(db *gorm.DB) *MessageRepository {
+	return &MessageRepository{db: db}
+}
+
+// Create adds a text message
+func (r *MessageRepository) Create(msg *Message) error {
+	return r.db.Create(msg).Error
+}
+
+// GetRecent retrieves the most recent text messages, newest first
+func (r *MessageRepository) GetRecent(limit int) ([]Message, error) {
+	var messages []Message
+	err := r.db.Order("timestamp DESC").
+		Limit(limit).
+		Find(&messages).Error
+	return messages, err
+}
+
+// GetByTalkgroup retrieves the text messages sent to a talkgroup, newest
+// first
+func (r *MessageRepository) GetByTalkgroup(tgid uint32, limit int) ([]Message, error) {
+	var messages []Message
+	err := r.db.Where("destination_id = ? AND is_group = ?", tgid, true).
+		Order("timestamp DESC").
+		Limit(limit).
+		Find(&messages).Error
+	return messages, err
+}
+
+// DeleteOlderThan removes text messages from before the cutoff and returns
+// how many were deleted
+func (r *MessageRepository) DeleteOlderThan(before time.Time) (int64, error) {
+	result := r.db.Where("timestamp < ?", before).Delete(&Message{})
+	return result.RowsAffected, result.Error
+}
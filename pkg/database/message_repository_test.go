@@ -0,0 +1,52 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+)
+
+func TestMessageRepository(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	db, err := NewDB(Config{Path: filepath.Join(t.TempDir(), "messages.db")}, log)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	repo := NewMessageRepository(db.GetDB())
+	now := time.Now().UTC()
+	messages := []Message{
+		{SourceID: 3120001, DestinationID: 3100, Group: true, Text: "good morning", Timestamp: now.Add(-2 * time.Hour)},
+		{SourceID: 3120002, DestinationID: 3120001, Text: "call me", Timestamp: now.Add(-time.Hour)},
+		{SourceID: 3120002, DestinationID: 3100, Group: true, Text: "QRV", Timestamp: now},
+	}
+	for i := range messages {
+		if err := repo.Create(&messages[i]); err != nil {
+			t.Fatalf("Create error: %v", err)
+		}
+	}
+
+	recent, err := repo.GetRecent(2)
+	if err != nil {
+		t.Fatalf("GetRecent error: %v", err)
+	}
+	if len(recent) != 2 || recent[0].Text != "QRV" || recent[1].Text != "call me" {
+		t.Errorf("GetRecent = %+v, want the two newest messages", recent)
+	}
+
+	group, err := repo.GetByTalkgroup(3100, 10)
+	if err != nil {
+		t.Fatalf("GetByTalkgroup error: %v", err)
+	}
+	if len(group) != 2 || group[0].Text != "QRV" || group[1].Text != "good morning" {
+		t.Errorf("GetByTalkgroup = %+v, want both talkgroup messages newest first", group)
+	}
+
+	deleted, err := repo.DeleteOlderThan(now.Add(-90 * time.Minute))
+	if err != nil || deleted != 1 {
+		t.Errorf("DeleteOlderThan = %d, %v; want 1 deleted", deleted, err)
+	}
+}
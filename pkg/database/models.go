@@ -18,6 +18,7 @@ type Transmission struct {
 	EndTime     time.Time `gorm:"not null" json:"end_time"`
 	RepeaterID  uint32    `gorm:"index" json:"repeater_id"`
 	PacketCount int       `gorm:"default:0" json:"packet_count"`
+	NetName     string    `gorm:"index" json:"net_name,omitempty"` // Net session running on the talkgroup, if any
 	CreatedAt   time.Time `json:"created_at"`
 }
 
@@ -52,6 +53,50 @@ type DMRUser struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// UserPosition is the last known location of a radio ID, updated from its
+// LRRP reports and kept separately from DMRUser so RadioID syncs don't
+// overwrite it
+type UserPosition struct {
+	RadioID   uint32    `gorm:"primarykey;not null" json:"radio_id"`
+	Latitude  float64   `gorm:"not null" json:"latitude"`
+	Longitude float64   `gorm:"not null" json:"longitude"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for UserPosition
+func (UserPosition) TableName() string {
+	return "user_positions"
+}
+
+// Position is a location report sent by a radio over the air (LRRP)
+type Position struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	RadioID   uint32    `gorm:"index;not null" json:"radio_id"`
+	Latitude  float64   `gorm:"not null" json:"latitude"`
+	Longitude float64   `gorm:"not null" json:"longitude"`
+	Timestamp time.Time `gorm:"index;not null" json:"timestamp"`
+}
+
+// TableName specifies the table name for Position
+func (Position) TableName() string {
+	return "positions"
+}
+
+// Message is a text message sent by a radio over the air (TMS)
+type Message struct {
+	ID            uint      `gorm:"primarykey" json:"id"`
+	SourceID      uint32    `gorm:"index;not null" json:"source_id"`
+	DestinationID uint32    `gorm:"index;not null" json:"destination_id"`
+	Group         bool      `gorm:"column:is_group;not null" json:"group"` // DestinationID is a talkgroup
+	Text          string    `gorm:"not null" json:"text"`
+	Timestamp     time.Time `gorm:"index;not null" json:"timestamp"`
+}
+
+// TableName specifies the table name for Message
+func (Message) TableName() string {
+	return "messages"
+}
+
 // TableName specifies the table name for DMRUser
 func (DMRUser) TableName() string {
 	return "dmr_users"
@@ -92,3 +137,18 @@ func (u *DMRUser) Location() string {
 	}
 	return result
 }
+
+// AirtimeUsage is a radio ID's transmit time on one system for one day, kept
+// so daily airtime budgets survive a restart
+type AirtimeUsage struct {
+	System    string    `gorm:"primarykey;size:64" json:"system"`
+	RadioID   uint32    `gorm:"primarykey;autoIncrement:false" json:"radio_id"`
+	Day       string    `gorm:"primarykey;size:10" json:"day"` // Local date, "2006-01-02"
+	Seconds   float64   `gorm:"not null" json:"seconds"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for AirtimeUsage
+func (AirtimeUsage) TableName() string {
+	return "airtime_usage"
+}
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PositionRepository handles radio position report database operations
+type PositionRepository struct {
+	db *gorm.DB
+}
+
+// NewPositionRepository creates a new position repository
+func NewPositionRepository(db *gorm.DB) *PositionRepository {
+	return &PositionRepository{db: db}
+}
+
+// Create adds a position report
+func (r *PositionRepository) Create(pos *Position) error {
+	return r.db.Create(pos).Error
+}
+
+// GetLatest retrieves the most recent position of each radio, newest first
+func (r *PositionRepository) GetLatest(limit int) ([]Position, error) {
+	var positions []Position
+	latest := r.db.Model(&Position{}).Select("MAX(id)").Group("radio_id")
+	err := r.db.Where("id IN (?)", latest).
+		Order("timestamp DESC").
+		Limit(limit).
+		Find(&positions).Error
+	return positions, err
+}
+
+// GetByRadioID retrieves a radio's position history, newest first
+func (r *PositionRepository) GetByRadioID(radioID uint32, limit int) ([]Position, error) {
+	var positions []Position
+	err := r.db.Where("radio_id = ?", radioID).
+		Order("timestamp DESC").
+		Limit(limit).
+		Find(&positions).Error
+	return positions, err
+}
+
+// DeleteOlderThan removes position reports from before the cutoff and
+// returns how many were deleted
+func (r *PositionRepository) DeleteOlderThan(before time.Time) (int64, error) {
+	result := r.db.Where("timestamp < ?", before).Delete(&Position{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+)
+
+func TestPositionRepository(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	db, err := NewDB(Config{Path: filepath.Join(t.TempDir(), "positions.db")}, log)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	repo := NewPositionRepository(db.GetDB())
+	now := time.Now().UTC()
+	reports := []Position{
+		{RadioID: 3120001, Latitude: 42.30, Longitude: -83.00, Timestamp: now.Add(-2 * time.Hour)},
+		{RadioID: 3120002, Latitude: 41.00, Longitude: -82.00, Timestamp: now.Add(-time.Hour)},
+		{RadioID: 3120001, Latitude: 42.33, Longitude: -83.05, Timestamp: now},
+	}
+	for i := range reports {
+		if err := repo.Create(&reports[i]); err != nil {
+			t.Fatalf("Create error: %v", err)
+		}
+	}
+
+	latest, err := repo.GetLatest(10)
+	if err != nil {
+		t.Fatalf("GetLatest error: %v", err)
+	}
+	if len(latest) != 2 || latest[0].RadioID != 3120001 || latest[0].Latitude != 42.33 || latest[1].RadioID != 3120002 {
+		t.Errorf("GetLatest = %+v, want newest position of each radio", latest)
+	}
+
+	history, err := repo.GetByRadioID(3120001, 10)
+	if err != nil {
+		t.Fatalf("GetByRadioID error: %v", err)
+	}
+	if len(history) != 2 || history[0].Latitude != 42.33 || history[1].Latitude != 42.30 {
+		t.Errorf("GetByRadioID = %+v, want both reports newest first", history)
+	}
+
+	deleted, err := repo.DeleteOlderThan(now.Add(-90 * time.Minute))
+	if err != nil || deleted != 1 {
+		t.Errorf("DeleteOlderThan = %d, %v; want 1 deleted", deleted, err)
+	}
+}
@@ -68,6 +68,15 @@ func (r *TransmissionRepository) GetByTalkgroup(tgID uint32, limit int) ([]Trans
 	return transmissions, err
 }
 
+// GetByNet retrieves the transmissions tagged with a net name, oldest first
+func (r *TransmissionRepository) GetByNet(name string) ([]Transmission, error) {
+	var transmissions []Transmission
+	err := r.db.Where("net_name = ?", name).
+		Order("start_time ASC").
+		Find(&transmissions).Error
+	return transmissions, err
+}
+
 // GetByTimeRange retrieves transmissions within a time range
 func (r *TransmissionRepository) GetByTimeRange(start, end time.Time, limit int) ([]Transmission, error) {
 	var transmissions []Transmission
@@ -84,6 +93,15 @@ func (r *TransmissionRepository) DeleteOlderThan(before time.Time) (int64, error
 	return result.RowsAffected, result.Error
 }
 
+// Vacuum rebuilds the database file to reclaim space freed by deletes, then
+// checkpoints the WAL so the main file shrinks on disk
+func (r *TransmissionRepository) Vacuum() error {
+	if err := r.db.Exec("VACUUM").Error; err != nil {
+		return err
+	}
+	return r.db.Exec("PRAGMA wal_checkpoint(TRUNCATE)").Error
+}
+
 // GetActiveStreamIDs retrieves stream IDs that are currently active (within last N seconds)
 func (r *TransmissionRepository) GetActiveStreamIDs(withinSeconds int) ([]uint32, error) {
 	var streamIDs []uint32
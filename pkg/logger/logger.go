@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"strings"
+	"time"
 )
 
 // Level represents log level
@@ -23,6 +24,10 @@ type Config struct {
 	Level  string
 	Format string
 	Output io.Writer
+	Ring   *Ring // Optional: also keep emitted lines here for remote tailing
+	// Optional: rewrites each field before it is written, e.g. to anonymize
+	// radio IDs
+	Redact func(Field) Field
 }
 
 // Logger represents a structured logger
@@ -30,6 +35,9 @@ type Logger struct {
 	level  Level
 	format string
 	logger *log.Logger
+	ring   *Ring
+	fields []Field // Added to every line, ahead of the call's own fields
+	redact func(Field) Field
 }
 
 // Field represents a structured logging field
@@ -51,6 +59,8 @@ func New(cfg Config) *Logger {
 		level:  level,
 		format: cfg.Format,
 		logger: log.New(output, "", log.LstdFlags),
+		ring:   cfg.Ring,
+		redact: cfg.Redact,
 	}
 }
 
@@ -60,49 +70,74 @@ func (l *Logger) WithComponent(component string) *Logger {
 		level:  l.level,
 		format: l.format,
 		logger: log.New(l.logger.Writer(), fmt.Sprintf("[%s] ", component), log.LstdFlags),
+		ring:   l.ring,
+		fields: l.fields,
+		redact: l.redact,
 	}
 }
 
+// With creates a child logger that adds fields to every line, e.g. the
+// system a server belongs to. The fields survive WithComponent.
+func (l *Logger) With(fields ...Field) *Logger {
+	child := *l
+	child.fields = append(append([]Field{}, l.fields...), fields...)
+	return &child
+}
+
 // Debug logs a debug message
 func (l *Logger) Debug(msg string, fields ...Field) {
 	if l.level <= DebugLevel {
-		l.log("DEBUG", msg, fields...)
+		l.log(DebugLevel, "DEBUG", msg, fields...)
 	}
 }
 
 // Info logs an info message
 func (l *Logger) Info(msg string, fields ...Field) {
 	if l.level <= InfoLevel {
-		l.log("INFO", msg, fields...)
+		l.log(InfoLevel, "INFO", msg, fields...)
 	}
 }
 
 // Warn logs a warning message
 func (l *Logger) Warn(msg string, fields ...Field) {
 	if l.level <= WarnLevel {
-		l.log("WARN", msg, fields...)
+		l.log(WarnLevel, "WARN", msg, fields...)
 	}
 }
 
 // Error logs an error message
 func (l *Logger) Error(msg string, fields ...Field) {
 	if l.level <= ErrorLevel {
-		l.log("ERROR", msg, fields...)
+		l.log(ErrorLevel, "ERROR", msg, fields...)
 	}
 }
 
-func (l *Logger) log(level, msg string, fields ...Field) {
-	if len(fields) == 0 {
-		l.logger.Printf("[%s] %s", level, msg)
-		return
+func (l *Logger) log(level Level, name, msg string, fields ...Field) {
+	line := fmt.Sprintf("[%s] %s", name, msg)
+	if len(l.fields) > 0 {
+		fields = append(append([]Field{}, l.fields...), fields...)
+	}
+	if len(fields) > 0 {
+		var fieldStrs []string
+		for _, f := range fields {
+			if l.redact != nil {
+				f = l.redact(f)
+			}
+			fieldStrs = append(fieldStrs, fmt.Sprintf("%s=%v", f.Key, f.Value))
+		}
+		line += " " + strings.Join(fieldStrs, " ")
 	}
 
-	var fieldStrs []string
-	for _, f := range fields {
-		fieldStrs = append(fieldStrs, fmt.Sprintf("%s=%v", f.Key, f.Value))
+	l.logger.Print(line)
+	if l.ring != nil {
+		l.ring.add(Entry{Time: time.Now(), Level: level, Line: l.logger.Prefix() + line})
 	}
+}
 
-	l.logger.Printf("[%s] %s %s", level, msg, strings.Join(fieldStrs, " "))
+// ParseLevel converts a level name (debug, info, warn, error) to a Level,
+// defaulting to InfoLevel
+func ParseLevel(level string) Level {
+	return parseLevel(level)
 }
 
 func parseLevel(level string) Level {
@@ -39,3 +39,66 @@ func TestLogger_WithComponentPrefix(t *testing.T) {
 		t.Fatalf("expected info message in output, got: %s", out)
 	}
 }
+
+func TestRing_KeepsRecentLinesAndFansOut(t *testing.T) {
+	ring := NewRing(2)
+	log := New(Config{Level: "info", Output: &bytes.Buffer{}, Ring: ring})
+
+	log.Info("one")
+	log.Debug("dropped by level")
+	log.Info("two")
+	log.WithComponent("web").Warn("three")
+
+	recent, lines, cancel := ring.Subscribe(1)
+	if len(recent) != 2 || recent[0].Line != "[INFO] two" || recent[1].Line != "[web] [WARN] three" {
+		t.Fatalf("expected the last two lines oldest first, got %+v", recent)
+	}
+	if recent[1].Level != WarnLevel {
+		t.Fatalf("expected warn level, got %v", recent[1].Level)
+	}
+
+	log.Error("four")
+	if e := <-lines; e.Line != "[ERROR] four" {
+		t.Fatalf("expected live line, got %+v", e)
+	}
+
+	cancel()
+	log.Error("after cancel")
+	if _, ok := <-lines; ok {
+		t.Fatal("expected channel closed after cancel")
+	}
+}
+
+func TestLogger_WithFieldsSurviveComponent(t *testing.T) {
+	var buf bytes.Buffer
+	base := New(Config{Level: "info", Output: &buf}).With(String("system", "MASTER-1"))
+	comp := base.WithComponent("network.server")
+
+	comp.Info("started", Int("port", 62031))
+	base.Info("plain")
+
+	out := buf.String()
+	if !strings.Contains(out, "[network.server] ") || !strings.Contains(out, "[INFO] started system=MASTER-1 port=62031") {
+		t.Fatalf("expected system field ahead of call fields under the component, got: %s", out)
+	}
+	if !strings.Contains(out, "[INFO] plain system=MASTER-1") {
+		t.Fatalf("expected system field on the parent logger, got: %s", out)
+	}
+}
+
+func TestLogger_RedactSurvivesComponent(t *testing.T) {
+	var buf bytes.Buffer
+	redact := func(f Field) Field {
+		if f.Key == "src" {
+			return Field{Key: f.Key, Value: "-"}
+		}
+		return f
+	}
+	log := New(Config{Level: "info", Output: &buf, Redact: redact}).WithComponent("network.server")
+
+	log.Info("call", Int("src", 3120001), Int("tg", 3100))
+
+	if out := buf.String(); !strings.Contains(out, "[INFO] call src=- tg=3100") {
+		t.Fatalf("expected src redacted and tg untouched, got: %s", out)
+	}
+}
@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// Entry is one log line kept by a Ring
+type Entry struct {
+	Time  time.Time
+	Level Level
+	Line  string // Component prefix, level and message, without the timestamp
+}
+
+// Ring keeps the most recent log lines in memory and fans new ones out to
+// subscribers, for remote log tailing
+type Ring struct {
+	mu      sync.Mutex
+	entries []Entry
+	next    int
+	full    bool
+	subs    map[chan Entry]struct{}
+}
+
+// NewRing creates a ring holding the last size lines
+func NewRing(size int) *Ring {
+	if size <= 0 {
+		size = 1
+	}
+	return &Ring{
+		entries: make([]Entry, size),
+		subs:    make(map[chan Entry]struct{}),
+	}
+}
+
+// add stores a line and offers it to subscribers. A subscriber whose buffer
+// is full misses the line rather than blocking the logger.
+func (r *Ring) add(e Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries[r.next] = e
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+	for ch := range r.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// Recent returns the buffered lines, oldest first
+func (r *Ring) Recent() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var recent []Entry
+	if r.full {
+		recent = append(recent, r.entries[r.next:]...)
+	}
+	return append(recent, r.entries[:r.next]...)
+}
+
+// Subscribe returns the buffered lines, oldest first, and a channel of lines
+// logged from now on. Call cancel to stop receiving; the channel is closed.
+func (r *Ring) Subscribe(buffer int) (recent []Entry, lines <-chan Entry, cancel func()) {
+	ch := make(chan Entry, buffer)
+
+	r.mu.Lock()
+	if r.full {
+		recent = append(recent, r.entries[r.next:]...)
+	}
+	recent = append(recent, r.entries[:r.next]...)
+	r.subs[ch] = struct{}{}
+	r.mu.Unlock()
+
+	var once sync.Once
+	cancel = func() {
+		once.Do(func() {
+			r.mu.Lock()
+			delete(r.subs, ch)
+			r.mu.Unlock()
+			close(ch)
+		})
+	}
+	return recent, ch, cancel
+}
@@ -1,9 +1,25 @@
 package metrics
 
 import (
+	"sort"
 	"sync"
 )
 
+// DefaultPacketSizeBuckets are the upper bounds, in bytes, of the inbound
+// packet size histogram: one per packet size the protocol defines, so each
+// bucket counts one kind of packet and anything in between stands out
+var DefaultPacketSizeBuckets = []int{
+	8,   // RPTL
+	9,   // RPTCL
+	11,  // RPTPING
+	40,  // RPTK
+	53,  // DMRD
+	55,  // DMRD with BER and RSSI
+	73,  // OpenBridge DMRD with HMAC
+	302, // RPTC
+	512, // RPTO and anything larger than the fixed packets
+}
+
 // Collector collects DMR-Nexus metrics
 type Collector struct {
 	mu sync.RWMutex
@@ -26,6 +42,44 @@ type Collector struct {
 
 	// Talkgroup metrics
 	activeTalkgroups map[string]bool // key: "tgid:timeslot"
+
+	// UDP socket health metrics
+	udpReadErrors            uint64
+	udpConsecutiveReadErrors map[string]int // by system
+	udpRebinds               uint64
+
+	// Dropped packet metrics by reason
+	packetsDropped map[string]uint64
+
+	// User database lookups that failed for reasons other than "not found"
+	userLookupFailures uint64
+
+	// Transmissions from radio IDs missing from the user database
+	unregisteredSources uint64
+
+	// Voice/data frames missing from inbound streams, from sequence gaps
+	framesLost uint64
+
+	// Rejected peers that kept sending until they were no longer answered
+	persistentUnknownPeers uint64
+
+	// Packets whose handling took longer than the latency alarm threshold
+	slowPackets uint64
+
+	// Transmissions on talkgroups with no bridge rule or subscriber, by TG
+	unknownTGs map[uint32]uint64
+
+	// Transmissions matching a bridge rule whose target system was unavailable, by system
+	unavailableTargets map[string]uint64
+
+	// Peers reaching each stage of the login handshake, by system
+	handshakes map[string]*HandshakeFunnel
+
+	// Inbound packet sizes: bucket upper bounds, a count per bucket plus one
+	// for larger packets, and the total bytes observed
+	packetSizeBuckets []int
+	packetSizeCounts  []uint64
+	packetSizeSum     uint64
 }
 
 // NewCollector creates a new metrics collector
@@ -34,9 +88,31 @@ func NewCollector() *Collector {
 		activePeers:      make(map[uint32]bool),
 		activeStreams:    make(map[uint32]bool),
 		activeTalkgroups: make(map[string]bool),
+		packetsDropped:   make(map[string]uint64),
+		unknownTGs:       make(map[uint32]uint64),
+
+		unavailableTargets:       make(map[string]uint64),
+		udpConsecutiveReadErrors: make(map[string]int),
+		handshakes:               make(map[string]*HandshakeFunnel),
+		packetSizeBuckets:        DefaultPacketSizeBuckets,
+		packetSizeCounts:         make([]uint64, len(DefaultPacketSizeBuckets)+1),
 	}
 }
 
+// SetPacketSizeBuckets replaces the packet size histogram's bucket upper
+// bounds and clears its counts
+func (c *Collector) SetPacketSizeBuckets(bounds []int) {
+	sorted := append([]int(nil), bounds...)
+	sort.Ints(sorted)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.packetSizeBuckets = sorted
+	c.packetSizeCounts = make([]uint64, len(sorted)+1)
+	c.packetSizeSum = 0
+}
+
 // PeerConnected records a peer connection
 func (c *Collector) PeerConnected(peerID uint32) {
 	c.mu.Lock()
@@ -70,6 +146,22 @@ func (c *Collector) PacketSent(packetType string) {
 	c.packetsSent++
 }
 
+// PacketsReceived records n received packets at once, for sampled counting
+func (c *Collector) PacketsReceived(n uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.packetsReceived += n
+}
+
+// PacketsSent records n sent packets at once, for sampled counting
+func (c *Collector) PacketsSent(n uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.packetsSent += n
+}
+
 // BytesReceived records received bytes
 func (c *Collector) BytesReceived(bytes uint64) {
 	c.mu.Lock()
@@ -128,6 +220,190 @@ func (c *Collector) TalkgroupInactive(tgid uint32, timeslot uint8) {
 	delete(c.activeTalkgroups, key)
 }
 
+// UDPReadError records a non-timeout UDP read error along with the
+// system's current run of consecutive errors
+func (c *Collector) UDPReadError(system string, consecutive int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.udpReadErrors++
+	c.udpConsecutiveReadErrors[system] = consecutive
+}
+
+// UDPReadRecovered records a system's successful read after one or more errors
+func (c *Collector) UDPReadRecovered(system string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.udpConsecutiveReadErrors[system] = 0
+}
+
+// UDPRebind records a system's UDP listener being re-opened
+func (c *Collector) UDPRebind(system string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.udpRebinds++
+	c.udpConsecutiveReadErrors[system] = 0
+}
+
+// PacketSize records the size of an inbound packet in the size histogram
+func (c *Collector) PacketSize(size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := sort.SearchInts(c.packetSizeBuckets, size)
+	c.packetSizeCounts[i]++
+	c.packetSizeSum += uint64(size)
+}
+
+// PacketDropped records a packet dropped for the given reason
+func (c *Collector) PacketDropped(reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.packetsDropped[reason]++
+}
+
+// UserLookupFailed records a user database lookup that failed
+func (c *Collector) UserLookupFailed() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.userLookupFailures++
+}
+
+// UnregisteredSource records a transmission from a radio ID that is not in
+// the user database
+func (c *Collector) UnregisteredSource() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.unregisteredSources++
+}
+
+// FramesLost records frames missing from an inbound stream
+func (c *Collector) FramesLost(n uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.framesLost += n
+}
+
+// PersistentUnknownPeer records a rejected peer that ignored MSTNAK/MSTCL
+// and kept sending until the server stopped answering it
+func (c *Collector) PersistentUnknownPeer() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.persistentUnknownPeers++
+}
+
+// SlowPacket records a packet that took longer than the latency alarm
+// threshold from receipt to the end of its handling
+func (c *Collector) SlowPacket() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.slowPackets++
+}
+
+// UnknownTalkgroup records a transmission on a talkgroup nothing routes
+func (c *Collector) UnknownTalkgroup(tgid uint32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.unknownTGs[tgid]++
+}
+
+// BridgeTargetUnavailable records a transmission that matched a bridge rule
+// whose target system was not available to receive it
+func (c *Collector) BridgeTargetUnavailable(system string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.unavailableTargets[system]++
+}
+
+// HandshakeStage is a step of the peer login handshake
+type HandshakeStage int
+
+const (
+	// HandshakeLogin is an RPTL login request
+	HandshakeLogin HandshakeStage = iota
+	// HandshakeKey is an RPTK authentication response
+	HandshakeKey
+	// HandshakeConfig is an RPTC configuration packet
+	HandshakeConfig
+	// HandshakeConnected is a peer completing the handshake
+	HandshakeConnected
+)
+
+// String returns the stage's metric label
+func (s HandshakeStage) String() string {
+	switch s {
+	case HandshakeLogin:
+		return "rptl"
+	case HandshakeKey:
+		return "rptk"
+	case HandshakeConfig:
+		return "rptc"
+	case HandshakeConnected:
+		return "connected"
+	default:
+		return "unknown"
+	}
+}
+
+// HandshakeFunnel counts how many handshakes reached each stage
+type HandshakeFunnel struct {
+	Login     uint64 `json:"rptl"`
+	Key       uint64 `json:"rptk"`
+	Config    uint64 `json:"rptc"`
+	Connected uint64 `json:"connected"`
+}
+
+// Stage returns the count for a single stage
+func (f HandshakeFunnel) Stage(stage HandshakeStage) uint64 {
+	switch stage {
+	case HandshakeLogin:
+		return f.Login
+	case HandshakeKey:
+		return f.Key
+	case HandshakeConfig:
+		return f.Config
+	case HandshakeConnected:
+		return f.Connected
+	default:
+		return 0
+	}
+}
+
+// HandshakeStages lists the handshake stages in the order peers reach them
+var HandshakeStages = []HandshakeStage{HandshakeLogin, HandshakeKey, HandshakeConfig, HandshakeConnected}
+
+// HandshakeStageReached records a peer on a system reaching a handshake stage
+func (c *Collector) HandshakeStageReached(system string, stage HandshakeStage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f := c.handshakes[system]
+	if f == nil {
+		f = &HandshakeFunnel{}
+		c.handshakes[system] = f
+	}
+	switch stage {
+	case HandshakeLogin:
+		f.Login++
+	case HandshakeKey:
+		f.Key++
+	case HandshakeConfig:
+		f.Config++
+	case HandshakeConnected:
+		f.Connected++
+	}
+}
+
 // Reset resets all metrics (useful for testing)
 func (c *Collector) Reset() {
 	c.mu.Lock()
@@ -142,6 +418,23 @@ func (c *Collector) Reset() {
 
 // Getters for metrics
 
+// GetPacketSizes returns the packet size histogram: the bucket upper
+// bounds, the cumulative count for each bound, the total count (the +Inf
+// bucket) and the sum of all sizes
+func (c *Collector) GetPacketSizes() (bounds []int, cumulative []uint64, count, sum uint64) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	bounds = append([]int(nil), c.packetSizeBuckets...)
+	cumulative = make([]uint64, len(bounds))
+	for i := range bounds {
+		count += c.packetSizeCounts[i]
+		cumulative[i] = count
+	}
+	count += c.packetSizeCounts[len(bounds)]
+	return bounds, cumulative, count, c.packetSizeSum
+}
+
 // GetTotalPeers returns total peer connections
 func (c *Collector) GetTotalPeers() uint64 {
 	c.mu.RLock()
@@ -205,6 +498,129 @@ func (c *Collector) GetActiveTalkgroups() int {
 	return len(c.activeTalkgroups)
 }
 
+// GetUDPReadErrors returns total non-timeout UDP read errors
+func (c *Collector) GetUDPReadErrors() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.udpReadErrors
+}
+
+// GetUDPConsecutiveReadErrors returns each system's current run of
+// consecutive UDP read errors
+func (c *Collector) GetUDPConsecutiveReadErrors() map[string]int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	runs := make(map[string]int, len(c.udpConsecutiveReadErrors))
+	for system, n := range c.udpConsecutiveReadErrors {
+		runs[system] = n
+	}
+	return runs
+}
+
+// GetUDPRebinds returns how many times a UDP listener was re-opened
+func (c *Collector) GetUDPRebinds() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.udpRebinds
+}
+
+// GetPacketsDropped returns dropped packet counts for the given reason
+func (c *Collector) GetPacketsDropped(reason string) uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.packetsDropped[reason]
+}
+
+// GetUserLookupFailures returns the number of failed user database lookups
+func (c *Collector) GetUserLookupFailures() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.userLookupFailures
+}
+
+// GetUnregisteredSources returns the number of transmissions from radio IDs
+// missing from the user database
+func (c *Collector) GetUnregisteredSources() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.unregisteredSources
+}
+
+// GetFramesLost returns the number of frames missing from inbound streams
+func (c *Collector) GetFramesLost() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.framesLost
+}
+
+// GetPersistentUnknownPeers returns the number of rejected peers that were
+// silenced for ignoring MSTNAK/MSTCL
+func (c *Collector) GetPersistentUnknownPeers() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.persistentUnknownPeers
+}
+
+// GetSlowPackets returns the number of packets that set off the latency
+// alarm
+func (c *Collector) GetSlowPackets() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.slowPackets
+}
+
+// GetDropReasons returns all reasons packets have been dropped for, sorted
+func (c *Collector) GetDropReasons() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	reasons := make([]string, 0, len(c.packetsDropped))
+	for reason := range c.packetsDropped {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	return reasons
+}
+
+// GetUnknownTalkgroups returns transmission counts per unknown talkgroup
+func (c *Collector) GetUnknownTalkgroups() map[uint32]uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	counts := make(map[uint32]uint64, len(c.unknownTGs))
+	for tgid, n := range c.unknownTGs {
+		counts[tgid] = n
+	}
+	return counts
+}
+
+// GetBridgeTargetsUnavailable returns, per target system, how many
+// transmissions matched a bridge rule while the system was unavailable
+func (c *Collector) GetBridgeTargetsUnavailable() map[string]uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	counts := make(map[string]uint64, len(c.unavailableTargets))
+	for system, n := range c.unavailableTargets {
+		counts[system] = n
+	}
+	return counts
+}
+
+// GetHandshakeFunnels returns, per system, how many handshakes reached
+// each stage
+func (c *Collector) GetHandshakeFunnels() map[string]HandshakeFunnel {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	funnels := make(map[string]HandshakeFunnel, len(c.handshakes))
+	for system, f := range c.handshakes {
+		funnels[system] = *f
+	}
+	return funnels
+}
+
 func talkgroupKey(tgid uint32, timeslot uint8) string {
 	return string([]byte{
 		byte(tgid >> 24),
@@ -169,3 +169,25 @@ func TestCollector_Concurrent(t *testing.T) {
 		t.Error("Expected at least 10 received packets")
 	}
 }
+
+// TestCollector_PacketSizeBuckets tests custom histogram buckets
+func TestCollector_PacketSizeBuckets(t *testing.T) {
+	collector := NewCollector()
+	collector.PacketSize(53)
+	collector.SetPacketSizeBuckets([]int{100, 50})
+
+	for _, size := range []int{10, 50, 51, 100, 101} {
+		collector.PacketSize(size)
+	}
+
+	bounds, cumulative, count, sum := collector.GetPacketSizes()
+	if len(bounds) != 2 || bounds[0] != 50 || bounds[1] != 100 {
+		t.Fatalf("Expected sorted bounds [50 100], got %v", bounds)
+	}
+	if cumulative[0] != 2 || cumulative[1] != 4 || count != 5 {
+		t.Errorf("Expected cumulative [2 4] of 5, got %v of %d", cumulative, count)
+	}
+	if sum != 312 {
+		t.Errorf("Expected sum 312 (counts cleared with new buckets), got %d", sum)
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/dbehnke/dmr-nexus/internal/netlisten"
 	"github.com/dbehnke/dmr-nexus/pkg/logger"
 )
 
@@ -16,6 +18,8 @@ type PrometheusConfig struct {
 	Enabled bool
 	Port    int
 	Path    string
+	// UnixSocket, when set, is listened on instead of Port
+	UnixSocket string
 }
 
 // PrometheusHandler handles Prometheus metrics HTTP requests
@@ -78,6 +82,106 @@ func (h *PrometheusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	output.WriteString("# TYPE dmr_talkgroups_active gauge\n")
 	output.WriteString(fmt.Sprintf("dmr_talkgroups_active %d\n", h.collector.GetActiveTalkgroups()))
 
+	// UDP socket health metrics
+	output.WriteString("# HELP dmr_udp_read_errors_total Total non-timeout UDP read errors\n")
+	output.WriteString("# TYPE dmr_udp_read_errors_total counter\n")
+	output.WriteString(fmt.Sprintf("dmr_udp_read_errors_total %d\n", h.collector.GetUDPReadErrors()))
+
+	output.WriteString("# HELP dmr_udp_read_errors_consecutive Current run of consecutive UDP read errors\n")
+	output.WriteString("# TYPE dmr_udp_read_errors_consecutive gauge\n")
+	readRuns := h.collector.GetUDPConsecutiveReadErrors()
+	readSystems := make([]string, 0, len(readRuns))
+	for system := range readRuns {
+		readSystems = append(readSystems, system)
+	}
+	sort.Strings(readSystems)
+	for _, system := range readSystems {
+		output.WriteString(fmt.Sprintf("dmr_udp_read_errors_consecutive{system=\"%s\"} %d\n", system, readRuns[system]))
+	}
+
+	output.WriteString("# HELP dmr_udp_rebinds_total Total UDP listener rebinds after persistent read errors\n")
+	output.WriteString("# TYPE dmr_udp_rebinds_total counter\n")
+	output.WriteString(fmt.Sprintf("dmr_udp_rebinds_total %d\n", h.collector.GetUDPRebinds()))
+
+	// Dropped packet metrics
+	output.WriteString("# HELP dmr_packets_dropped_total Total packets dropped by reason\n")
+	output.WriteString("# TYPE dmr_packets_dropped_total counter\n")
+	for _, reason := range h.collector.GetDropReasons() {
+		output.WriteString(fmt.Sprintf("dmr_packets_dropped_total{reason=%q} %d\n", reason, h.collector.GetPacketsDropped(reason)))
+	}
+
+	// User database metrics
+	output.WriteString("# HELP dmr_user_lookup_failures_total Total user database lookups that failed\n")
+	output.WriteString("# TYPE dmr_user_lookup_failures_total counter\n")
+	output.WriteString(fmt.Sprintf("dmr_user_lookup_failures_total %d\n", h.collector.GetUserLookupFailures()))
+
+	output.WriteString("# HELP dmr_unregistered_source_transmissions_total Transmissions from radio IDs missing from the user database\n")
+	output.WriteString("# TYPE dmr_unregistered_source_transmissions_total counter\n")
+	output.WriteString(fmt.Sprintf("dmr_unregistered_source_transmissions_total %d\n", h.collector.GetUnregisteredSources()))
+
+	output.WriteString("# HELP dmr_frames_lost_total Frames missing from inbound streams, from DMRD sequence gaps\n")
+	output.WriteString("# TYPE dmr_frames_lost_total counter\n")
+	output.WriteString(fmt.Sprintf("dmr_frames_lost_total %d\n", h.collector.GetFramesLost()))
+
+	output.WriteString("# HELP dmr_persistent_unknown_peers_total Rejected peers that ignored MSTNAK/MSTCL and were silenced\n")
+	output.WriteString("# TYPE dmr_persistent_unknown_peers_total counter\n")
+	output.WriteString(fmt.Sprintf("dmr_persistent_unknown_peers_total %d\n", h.collector.GetPersistentUnknownPeers()))
+
+	output.WriteString("# HELP dmr_slow_packets_total Packets whose handling exceeded max_packet_latency_ms from receipt\n")
+	output.WriteString("# TYPE dmr_slow_packets_total counter\n")
+	output.WriteString(fmt.Sprintf("dmr_slow_packets_total %d\n", h.collector.GetSlowPackets()))
+
+	// Unknown talkgroup discovery
+	output.WriteString("# HELP dmr_unknown_talkgroup_transmissions_total Transmissions on talkgroups with no bridge rule or subscriber\n")
+	output.WriteString("# TYPE dmr_unknown_talkgroup_transmissions_total counter\n")
+	unknown := h.collector.GetUnknownTalkgroups()
+	tgids := make([]uint32, 0, len(unknown))
+	for tgid := range unknown {
+		tgids = append(tgids, tgid)
+	}
+	sort.Slice(tgids, func(i, j int) bool { return tgids[i] < tgids[j] })
+	for _, tgid := range tgids {
+		output.WriteString(fmt.Sprintf("dmr_unknown_talkgroup_transmissions_total{tgid=\"%d\"} %d\n", tgid, unknown[tgid]))
+	}
+
+	// Inbound packet sizes
+	output.WriteString("# HELP dmr_packet_size_bytes Size of inbound UDP packets\n")
+	output.WriteString("# TYPE dmr_packet_size_bytes histogram\n")
+	bounds, cumulative, count, sum := h.collector.GetPacketSizes()
+	for i, bound := range bounds {
+		output.WriteString(fmt.Sprintf("dmr_packet_size_bytes_bucket{le=\"%d\"} %d\n", bound, cumulative[i]))
+	}
+	output.WriteString(fmt.Sprintf("dmr_packet_size_bytes_bucket{le=\"+Inf\"} %d\n", count))
+	output.WriteString(fmt.Sprintf("dmr_packet_size_bytes_sum %d\n", sum))
+	output.WriteString(fmt.Sprintf("dmr_packet_size_bytes_count %d\n", count))
+
+	// Bridge rules whose target system is down
+	output.WriteString("# HELP dmr_bridge_target_unavailable_total Transmissions matching a bridge rule whose target system was unavailable\n")
+	output.WriteString("# TYPE dmr_bridge_target_unavailable_total counter\n")
+	unavailable := h.collector.GetBridgeTargetsUnavailable()
+	systems := make([]string, 0, len(unavailable))
+	for system := range unavailable {
+		systems = append(systems, system)
+	}
+	sort.Strings(systems)
+	for _, system := range systems {
+		output.WriteString(fmt.Sprintf("dmr_bridge_target_unavailable_total{system=\"%s\"} %d\n", system, unavailable[system]))
+	}
+
+	output.WriteString("# HELP dmr_handshake_stage_total Peer handshakes reaching each login stage\n")
+	output.WriteString("# TYPE dmr_handshake_stage_total counter\n")
+	funnels := h.collector.GetHandshakeFunnels()
+	systems = systems[:0]
+	for system := range funnels {
+		systems = append(systems, system)
+	}
+	sort.Strings(systems)
+	for _, system := range systems {
+		for _, stage := range HandshakeStages {
+			output.WriteString(fmt.Sprintf("dmr_handshake_stage_total{system=\"%s\",stage=\"%s\"} %d\n", system, stage, funnels[system].Stage(stage)))
+		}
+	}
+
 	if _, err := w.Write([]byte(output.String())); err != nil {
 		// Writing metrics failed - log for visibility
 		// Handler shouldn't fail the request lifecycle, so just log
@@ -121,20 +225,24 @@ func (s *PrometheusServer) Start(ctx context.Context) error {
 
 	// Use a listener to get the actual port (useful for testing with port 0)
 	addr := fmt.Sprintf(":%d", s.config.Port)
-	listener, err := net.Listen("tcp", addr)
+	listener, err := netlisten.Listen(addr, s.config.UnixSocket)
 	if err != nil {
 		return fmt.Errorf("failed to listen on %s: %w", addr, err)
 	}
 
-	actualPort := listener.Addr().(*net.TCPAddr).Port
-
 	s.server = &http.Server{
 		Handler: mux,
 	}
 
-	s.log.Info("Starting Prometheus metrics server",
-		logger.Int("port", actualPort),
-		logger.String("path", s.config.Path))
+	if tcpAddr, ok := listener.Addr().(*net.TCPAddr); ok {
+		s.log.Info("Starting Prometheus metrics server",
+			logger.Int("port", tcpAddr.Port),
+			logger.String("path", s.config.Path))
+	} else {
+		s.log.Info("Starting Prometheus metrics server",
+			logger.String("socket", s.config.UnixSocket),
+			logger.String("path", s.config.Path))
+	}
 
 	// Start server
 	errChan := make(chan error, 1)
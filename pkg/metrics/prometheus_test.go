@@ -3,8 +3,11 @@ package metrics
 import (
 	"context"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -29,6 +32,19 @@ func TestPrometheusHandler_ServeHTTP(t *testing.T) {
 	collector.PeerConnected(312000)
 	collector.PacketReceived("DMRD")
 	collector.BytesReceived(1024)
+	collector.UnknownTalkgroup(9999)
+	collector.UnregisteredSource()
+	collector.FramesLost(3)
+	collector.PersistentUnknownPeer()
+	collector.SlowPacket()
+	collector.BridgeTargetUnavailable("OBP-1")
+	collector.UDPReadError("MASTER-1", 1)
+	collector.UDPReadError("MASTER-1", 2)
+	collector.UDPReadError("MASTER-2", 1)
+	collector.UDPReadRecovered("MASTER-2")
+	collector.HandshakeStageReached("MASTER-1", HandshakeLogin)
+	collector.HandshakeStageReached("MASTER-1", HandshakeLogin)
+	collector.HandshakeStageReached("MASTER-1", HandshakeKey)
 
 	req := httptest.NewRequest("GET", "/metrics", nil)
 	w := httptest.NewRecorder()
@@ -49,6 +65,18 @@ func TestPrometheusHandler_ServeHTTP(t *testing.T) {
 		"dmr_peers_active",
 		"dmr_packets_received_total",
 		"dmr_bytes_received_total",
+		`dmr_unknown_talkgroup_transmissions_total{tgid="9999"} 1`,
+		"dmr_unregistered_source_transmissions_total 1",
+		"dmr_frames_lost_total 3",
+		"dmr_persistent_unknown_peers_total 1",
+		"dmr_slow_packets_total 1",
+		`dmr_bridge_target_unavailable_total{system="OBP-1"} 1`,
+		`dmr_udp_read_errors_total 3`,
+		`dmr_udp_read_errors_consecutive{system="MASTER-1"} 2`,
+		`dmr_udp_read_errors_consecutive{system="MASTER-2"} 0`,
+		`dmr_handshake_stage_total{system="MASTER-1",stage="rptl"} 2`,
+		`dmr_handshake_stage_total{system="MASTER-1",stage="rptk"} 1`,
+		`dmr_handshake_stage_total{system="MASTER-1",stage="connected"} 0`,
 	}
 
 	for _, metric := range expectedMetrics {
@@ -119,6 +147,53 @@ func TestPrometheusServer(t *testing.T) {
 	}
 }
 
+func TestPrometheusServer_UnixSocket(t *testing.T) {
+	dir, err := os.MkdirTemp("", "nexus")
+	if err != nil {
+		t.Fatalf("MkdirTemp error: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	socketPath := filepath.Join(dir, "metrics.sock")
+
+	collector := NewCollector()
+	collector.PeerConnected(111)
+	server := NewPrometheusServer(PrometheusConfig{Enabled: true, Path: "/metrics", UnixSocket: socketPath}, collector, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- server.Start(ctx)
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socketPath)
+		},
+	}}
+	resp, err := client.Get("http://nexus/metrics")
+	if err != nil {
+		t.Fatalf("Failed to scrape over socket: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if !strings.Contains(string(body), "dmr_peers_active 1") {
+		t.Errorf("Expected metrics body over socket, got:\n%s", body)
+	}
+
+	cancel()
+	select {
+	case err := <-errChan:
+		if err != nil && err != context.Canceled && err != http.ErrServerClosed {
+			t.Errorf("Unexpected error from server: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Server did not stop in time")
+	}
+}
+
 // TestPrometheusServer_Disabled tests that disabled server doesn't start
 func TestPrometheusServer_Disabled(t *testing.T) {
 	collector := NewCollector()
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Tag is a dimension on a metric series, e.g. reason=bad_hmac
+type Tag struct {
+	Key   string
+	Value string
+}
+
+// Sink receives a point-in-time copy of the collector's metrics. Counters
+// are cumulative since startup; gauges are current values.
+type Sink interface {
+	Counter(name string, value uint64, tags ...Tag)
+	Gauge(name string, value int64, tags ...Tag)
+}
+
+// Export writes the key metrics to s, under the same names the Prometheus
+// endpoint uses without the dmr_ prefix
+func (c *Collector) Export(s Sink) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	s.Counter("peers_total", c.totalPeers)
+	s.Gauge("peers_active", int64(len(c.activePeers)))
+	s.Counter("packets_received_total", c.packetsReceived)
+	s.Counter("packets_sent_total", c.packetsSent)
+	s.Counter("bytes_received_total", c.bytesReceived)
+	s.Counter("bytes_sent_total", c.bytesSent)
+	s.Gauge("streams_active", int64(len(c.activeStreams)))
+	s.Counter("bridge_routes_total", c.bridgeRoutes)
+	s.Gauge("talkgroups_active", int64(len(c.activeTalkgroups)))
+	s.Counter("udp_read_errors_total", c.udpReadErrors)
+	s.Counter("udp_rebinds_total", c.udpRebinds)
+	s.Counter("user_lookup_failures_total", c.userLookupFailures)
+	s.Counter("unregistered_source_transmissions_total", c.unregisteredSources)
+	s.Counter("frames_lost_total", c.framesLost)
+	s.Counter("persistent_unknown_peers_total", c.persistentUnknownPeers)
+	s.Counter("slow_packets_total", c.slowPackets)
+
+	reasons := make([]string, 0, len(c.packetsDropped))
+	for reason := range c.packetsDropped {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	for _, reason := range reasons {
+		s.Counter("packets_dropped_total", c.packetsDropped[reason], Tag{Key: "reason", Value: reason})
+	}
+
+	tgids := make([]uint32, 0, len(c.unknownTGs))
+	for tgid := range c.unknownTGs {
+		tgids = append(tgids, tgid)
+	}
+	sort.Slice(tgids, func(i, j int) bool { return tgids[i] < tgids[j] })
+	for _, tgid := range tgids {
+		s.Counter("unknown_talkgroup_transmissions_total", c.unknownTGs[tgid], Tag{Key: "tgid", Value: strconv.FormatUint(uint64(tgid), 10)})
+	}
+
+	systems := make([]string, 0, len(c.unavailableTargets))
+	for system := range c.unavailableTargets {
+		systems = append(systems, system)
+	}
+	sort.Strings(systems)
+	for _, system := range systems {
+		s.Counter("bridge_target_unavailable_total", c.unavailableTargets[system], Tag{Key: "system", Value: system})
+	}
+
+	systems = systems[:0]
+	for system := range c.handshakes {
+		systems = append(systems, system)
+	}
+	sort.Strings(systems)
+	for _, system := range systems {
+		for _, stage := range HandshakeStages {
+			s.Counter("handshake_stage_total", c.handshakes[system].Stage(stage), Tag{Key: "system", Value: system}, Tag{Key: "stage", Value: stage.String()})
+		}
+	}
+}
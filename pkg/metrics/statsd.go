@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+)
+
+// statsdMaxPacket keeps each datagram within a typical Ethernet MTU
+const statsdMaxPacket = 1432
+
+// StatsDConfig holds StatsD exporter configuration
+type StatsDConfig struct {
+	Address       string        // host:port of the StatsD agent
+	Prefix        string        // Prepended to every metric name, e.g. "dmr"
+	FlushInterval time.Duration // How often metrics are pushed
+	DogStatsD     bool          // Send tags as |#key:value; otherwise they are folded into the name
+}
+
+// StatsDExporter periodically pushes the collector's metrics to a StatsD
+// agent over UDP. Cumulative counters are sent as deltas since the last flush.
+type StatsDExporter struct {
+	config    StatsDConfig
+	collector *Collector
+	log       *logger.Logger
+	conn      net.Conn
+
+	last  map[string]uint64 // series -> counter value at the last flush
+	lines []string
+}
+
+// NewStatsDExporter creates a StatsD exporter
+func NewStatsDExporter(config StatsDConfig, collector *Collector, log *logger.Logger) *StatsDExporter {
+	if log == nil {
+		log = logger.New(logger.Config{Level: "info", Format: "text"})
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 10 * time.Second
+	}
+
+	return &StatsDExporter{
+		config:    config,
+		collector: collector,
+		log:       log.WithComponent("statsd"),
+		last:      make(map[string]uint64),
+	}
+}
+
+// Start pushes metrics every flush interval until ctx is cancelled, with a
+// final flush on the way out
+func (e *StatsDExporter) Start(ctx context.Context) error {
+	conn, err := net.Dial("udp", e.config.Address)
+	if err != nil {
+		return fmt.Errorf("failed to dial statsd %s: %w", e.config.Address, err)
+	}
+	e.conn = conn
+	defer func() { _ = conn.Close() }()
+
+	e.log.Info("Starting StatsD exporter",
+		logger.String("address", e.config.Address),
+		logger.String("interval", e.config.FlushInterval.String()))
+
+	ticker := time.NewTicker(e.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := e.Flush(); err != nil {
+				e.log.Debug("Final StatsD flush failed", logger.Error(err))
+			}
+			return ctx.Err()
+		case <-ticker.C:
+			if err := e.Flush(); err != nil {
+				e.log.Debug("StatsD flush failed", logger.Error(err))
+			}
+		}
+	}
+}
+
+// Flush sends one snapshot of the collector's metrics
+func (e *StatsDExporter) Flush() error {
+	e.lines = e.lines[:0]
+	e.collector.Export(e)
+
+	var packet strings.Builder
+	for _, line := range e.lines {
+		if packet.Len() > 0 && packet.Len()+1+len(line) > statsdMaxPacket {
+			if _, err := e.conn.Write([]byte(packet.String())); err != nil {
+				return err
+			}
+			packet.Reset()
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line)
+	}
+	if packet.Len() > 0 {
+		if _, err := e.conn.Write([]byte(packet.String())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Counter implements Sink, queueing the increase since the last flush. A
+// counter below its last value was reset, so all of it is new.
+func (e *StatsDExporter) Counter(name string, value uint64, tags ...Tag) {
+	series, suffix := e.series(name, tags)
+	key := series + suffix
+	delta := value
+	if last := e.last[key]; value >= last {
+		delta = value - last
+	}
+	e.last[key] = value
+	if delta == 0 {
+		return
+	}
+	e.lines = append(e.lines, fmt.Sprintf("%s:%d|c%s", series, delta, suffix))
+}
+
+// Gauge implements Sink, queueing the current value
+func (e *StatsDExporter) Gauge(name string, value int64, tags ...Tag) {
+	series, suffix := e.series(name, tags)
+	e.lines = append(e.lines, fmt.Sprintf("%s:%d|g%s", series, value, suffix))
+}
+
+// series returns the metric name to send and any DogStatsD tag suffix.
+// Plain StatsD has no tags, so their values become name segments.
+func (e *StatsDExporter) series(name string, tags []Tag) (string, string) {
+	if e.config.Prefix != "" {
+		name = e.config.Prefix + "." + name
+	}
+	if len(tags) == 0 {
+		return name, ""
+	}
+
+	if e.config.DogStatsD {
+		parts := make([]string, len(tags))
+		for i, t := range tags {
+			parts[i] = t.Key + ":" + t.Value
+		}
+		return name, "|#" + strings.Join(parts, ",")
+	}
+
+	for _, t := range tags {
+		name += "." + statsdSanitize(t.Value)
+	}
+	return name, ""
+}
+
+// statsdSanitize replaces characters that are significant in the StatsD line
+// protocol
+func statsdSanitize(s string) string {
+	return strings.NewReplacer(":", "_", "|", "_", "@", "_", "\n", "_", ".", "_").Replace(s)
+}
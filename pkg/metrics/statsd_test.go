@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readStatsD collects metric lines from datagrams until the listener is idle
+func readStatsD(t *testing.T, conn *net.UDPConn) []string {
+	t.Helper()
+	var lines []string
+	buf := make([]byte, 2048)
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond)); err != nil {
+			t.Fatalf("SetReadDeadline error: %v", err)
+		}
+		n, _, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			return lines
+		}
+		lines = append(lines, strings.Split(string(buf[:n]), "\n")...)
+	}
+}
+
+func TestStatsDExporter_FlushSendsDeltasAndGauges(t *testing.T) {
+	agent, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = agent.Close() }()
+
+	collector := NewCollector()
+	collector.PeerConnected(311001)
+	collector.PeerConnected(311002)
+	collector.PacketDropped("bad_hmac")
+	collector.UnknownTalkgroup(9999)
+
+	exporter := NewStatsDExporter(StatsDConfig{Address: agent.LocalAddr().String(), Prefix: "dmr"}, collector, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = exporter.Start(ctx)
+	}()
+
+	// Final flush on shutdown
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+
+	lines := readStatsD(t, agent)
+	for _, want := range []string{
+		"dmr.peers_total:2|c",
+		"dmr.peers_active:2|g",
+		"dmr.packets_dropped_total.bad_hmac:1|c",
+		"dmr.unknown_talkgroup_transmissions_total.9999:1|c",
+	} {
+		if !contains(lines, want) {
+			t.Errorf("Expected %q in %v", want, lines)
+		}
+	}
+
+	// Counters are sent as increases since the last flush; unchanged ones are skipped
+	conn, err := net.Dial("udp", agent.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Dial error: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	exporter.conn = conn
+	collector.PeerDisconnected(311002)
+	collector.PacketDropped("bad_hmac")
+	collector.PacketDropped("bad_hmac")
+	if err := exporter.Flush(); err != nil {
+		t.Fatalf("Flush error: %v", err)
+	}
+
+	lines = readStatsD(t, agent)
+	for _, want := range []string{"dmr.peers_active:1|g", "dmr.packets_dropped_total.bad_hmac:2|c"} {
+		if !contains(lines, want) {
+			t.Errorf("Expected %q in %v", want, lines)
+		}
+	}
+	for _, line := range lines {
+		if strings.HasPrefix(line, "dmr.peers_total:") {
+			t.Errorf("Expected unchanged counter to be skipped, got %q", line)
+		}
+	}
+
+	// A counter that went backwards was reset; its whole value is the increase
+	exporter.collector = NewCollector()
+	exporter.collector.PacketDropped("bad_hmac")
+	if err := exporter.Flush(); err != nil {
+		t.Fatalf("Flush error: %v", err)
+	}
+	lines = readStatsD(t, agent)
+	if !contains(lines, "dmr.packets_dropped_total.bad_hmac:1|c") {
+		t.Errorf("Expected the increase since the reset, got %v", lines)
+	}
+}
+
+func TestStatsDExporter_DogStatsDTags(t *testing.T) {
+	agent, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = agent.Close() }()
+
+	collector := NewCollector()
+	collector.PacketDropped("bad_hmac")
+	collector.PacketDropped("concurrent_stream")
+
+	exporter := NewStatsDExporter(StatsDConfig{Address: agent.LocalAddr().String(), DogStatsD: true}, collector, nil)
+	conn, err := net.Dial("udp", agent.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Dial error: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	exporter.conn = conn
+	if err := exporter.Flush(); err != nil {
+		t.Fatalf("Flush error: %v", err)
+	}
+
+	lines := readStatsD(t, agent)
+	for _, want := range []string{
+		"packets_dropped_total:1|c|#reason:bad_hmac",
+		"packets_dropped_total:1|c|#reason:concurrent_stream",
+	} {
+		if !contains(lines, want) {
+			t.Errorf("Expected %q in %v", want, lines)
+		}
+	}
+}
+
+func contains(lines []string, want string) bool {
+	for _, l := range lines {
+		if l == want {
+			return true
+		}
+	}
+	return false
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/privacy"
 )
 
 // Config holds MQTT publisher configuration
@@ -20,12 +21,39 @@ type Config struct {
 	Password    string
 	QoS         byte
 	Retained    bool
+	// IncludePosition adds the source's last known coordinates to traffic events
+	IncludePosition bool
+	// StatusInterval is how often the node heartbeat goes to <prefix>/status;
+	// zero disables it
+	StatusInterval time.Duration
 }
 
+// PositionLookup returns the last known coordinates of a radio ID, if any
+type PositionLookup func(radioID uint32) (lat, lon float64, ok bool)
+
+// StatusSource reports the node's current state for the heartbeat
+type StatusSource func() StatusEvent
+
 // Publisher handles MQTT event publishing
 type Publisher struct {
-	config Config
-	log    *logger.Logger
+	config         Config
+	log            *logger.Logger
+	positionLookup PositionLookup
+	statusSource   StatusSource
+	anon           *privacy.Anonymizer
+	started        time.Time
+
+	// send delivers a payload to the broker
+	send func(topic string, payload []byte, retained bool) error
+}
+
+// Will is the last-will message registered on connect; the broker publishes
+// it if the node drops without disconnecting cleanly
+type Will struct {
+	Topic    string
+	Payload  []byte
+	QoS      byte
+	Retained bool
 }
 
 // Event types for MQTT publishing
@@ -45,12 +73,32 @@ type PeerDisconnectEvent struct {
 	Timestamp time.Time `json:"timestamp"`
 }
 
-// TrafficEvent represents DMR traffic
+// TrafficEvent represents a transmission starting (Active) or ending
 type TrafficEvent struct {
 	SourceID  uint32    `json:"source_id"`
 	DestID    uint32    `json:"dest_id"`
 	Timeslot  uint8     `json:"timeslot"`
 	StreamID  uint32    `json:"stream_id"`
+	Active    bool      `json:"active"`
+	Latitude  *float64  `json:"latitude,omitempty"`
+	Longitude *float64  `json:"longitude,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// FirstHeardEvent marks a radio ID's first transmission of the day
+type FirstHeardEvent struct {
+	RadioID   uint32    `json:"radio_id"`
+	DestID    uint32    `json:"dest_id"`
+	PeerID    uint32    `json:"peer_id"`
+	System    string    `json:"system"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// AirtimeEvent represents a radio ID using up its daily airtime
+type AirtimeEvent struct {
+	RadioID   uint32    `json:"radio_id"`
+	System    string    `json:"system"`
+	Seconds   float64   `json:"seconds"`
 	Timestamp time.Time `json:"timestamp"`
 }
 
@@ -64,15 +112,73 @@ type BridgeEvent struct {
 	Timestamp  time.Time `json:"timestamp"`
 }
 
+// Talkgroup is one entry of the talkgroup directory
+type Talkgroup struct {
+	ID          uint32 `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// TalkgroupDirectoryEvent is the node's talkgroup catalog
+type TalkgroupDirectoryEvent struct {
+	Talkgroups []Talkgroup `json:"talkgroups"`
+	Timestamp  time.Time   `json:"timestamp"`
+}
+
+// StatusEvent is the node presence heartbeat. Online is false in the
+// last-will message and the final message sent on shutdown.
+type StatusEvent struct {
+	Online        bool      `json:"online"`
+	Version       string    `json:"version,omitempty"`
+	UptimeSeconds int64     `json:"uptime_seconds"`
+	Peers         int       `json:"peers"`
+	ActiveStreams int       `json:"active_streams"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
 // New creates a new MQTT publisher
 func New(config Config, log *logger.Logger) *Publisher {
 	if log == nil {
 		log = logger.New(logger.Config{Level: "info", Format: "text"})
 	}
 
-	return &Publisher{
-		config: config,
-		log:    log.WithComponent("mqtt"),
+	p := &Publisher{
+		config:  config,
+		log:     log.WithComponent("mqtt"),
+		started: time.Now(),
+	}
+	p.send = p.logPublish
+	return p
+}
+
+// SetPositionLookup sets where traffic event coordinates come from when
+// IncludePosition is enabled
+func (p *Publisher) SetPositionLookup(lookup PositionLookup) {
+	p.positionLookup = lookup
+}
+
+// SetAnonymizer anonymizes source radio IDs in traffic and first-heard
+// events. Anonymized traffic never carries coordinates.
+func (p *Publisher) SetAnonymizer(anon *privacy.Anonymizer) {
+	p.anon = anon
+}
+
+// SetStatusSource sets where the heartbeat's peer, stream and version
+// figures come from. Call before Start.
+func (p *Publisher) SetStatusSource(source StatusSource) {
+	p.statusSource = source
+}
+
+// LastWill returns the will to register when connecting: a retained
+// offline status, so subscribers to <prefix>/status see a crashed node go
+// offline
+func (p *Publisher) LastWill() Will {
+	payload, _ := p.serializeEvent(StatusEvent{Online: false})
+	return Will{
+		Topic:    p.formatTopic("status"),
+		Payload:  payload,
+		QoS:      p.config.QoS,
+		Retained: true,
 	}
 }
 
@@ -91,7 +197,49 @@ func (p *Publisher) Start(ctx context.Context) error {
 	// For now, this is a no-op stub that allows the application to start
 	p.log.Warn("MQTT connection not yet implemented - events will not be published")
 
-	return nil
+	will := p.LastWill()
+	p.log.Info("MQTT last will set", logger.String("topic", will.Topic))
+
+	if p.config.StatusInterval <= 0 {
+		return nil
+	}
+	return p.heartbeatLoop(ctx)
+}
+
+// heartbeatLoop publishes the node status at once and then every
+// StatusInterval until ctx is done
+func (p *Publisher) heartbeatLoop(ctx context.Context) error {
+	ticker := time.NewTicker(p.config.StatusInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.publishStatus(true); err != nil {
+			p.log.Warn("Failed to publish node status", logger.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishStatus publishes the node status, retained so late subscribers
+// see the current state
+func (p *Publisher) publishStatus(online bool) error {
+	var event StatusEvent
+	if online && p.statusSource != nil {
+		event = p.statusSource()
+	}
+	event.Online = online
+	event.UptimeSeconds = int64(time.Since(p.started).Seconds())
+	event.Timestamp = time.Now()
+
+	payload, err := p.serializeEvent(event)
+	if err != nil {
+		return err
+	}
+	return p.send(p.formatTopic("status"), payload, true)
 }
 
 // Stop stops the MQTT publisher
@@ -101,6 +249,10 @@ func (p *Publisher) Stop() {
 	}
 
 	p.log.Info("Stopping MQTT publisher")
+	// A clean disconnect doesn't trigger the will, so say goodbye first
+	if err := p.publishStatus(false); err != nil {
+		p.log.Warn("Failed to publish offline status", logger.Error(err))
+	}
 	// TODO: Disconnect MQTT client when implemented
 }
 
@@ -131,6 +283,53 @@ func (p *Publisher) PublishTraffic(event TrafficEvent) error {
 	}
 
 	topic := p.formatTopic("traffic")
+	return p.publish(topic, p.anonymizeTraffic(p.withPosition(event)))
+}
+
+// anonymizeTraffic replaces the source with its pseudonym and drops the
+// coordinates, which would identify it just as well
+func (p *Publisher) anonymizeTraffic(event TrafficEvent) TrafficEvent {
+	if !p.anon.Enabled() {
+		return event
+	}
+	event.SourceID = p.anon.RadioID(event.SourceID)
+	event.Latitude = nil
+	event.Longitude = nil
+	return event
+}
+
+// withPosition fills in the source's coordinates when enabled, known, and not
+// already carried by the event itself
+func (p *Publisher) withPosition(event TrafficEvent) TrafficEvent {
+	if !p.config.IncludePosition || p.positionLookup == nil || event.Latitude != nil {
+		return event
+	}
+	if lat, lon, ok := p.positionLookup(event.SourceID); ok {
+		event.Latitude = &lat
+		event.Longitude = &lon
+	}
+	return event
+}
+
+// PublishFirstHeard publishes a radio ID's first transmission of the day
+func (p *Publisher) PublishFirstHeard(event FirstHeardEvent) error {
+	if !p.config.Enabled {
+		return nil
+	}
+
+	event.RadioID = p.anon.RadioID(event.RadioID)
+	topic := p.formatTopic("users/first_heard")
+	return p.publish(topic, event)
+}
+
+// PublishAirtimeExceeded publishes a radio ID using up its daily airtime
+func (p *Publisher) PublishAirtimeExceeded(event AirtimeEvent) error {
+	if !p.config.Enabled {
+		return nil
+	}
+
+	event.RadioID = p.anon.RadioID(event.RadioID)
+	topic := p.formatTopic("users/airtime_exceeded")
 	return p.publish(topic, event)
 }
 
@@ -144,6 +343,20 @@ func (p *Publisher) PublishBridgeChange(event BridgeEvent) error {
 	return p.publish(topic, event)
 }
 
+// PublishTalkgroupDirectory publishes the talkgroup directory, always
+// retained so integrations that connect later still get the catalog
+func (p *Publisher) PublishTalkgroupDirectory(event TalkgroupDirectoryEvent) error {
+	if !p.config.Enabled {
+		return nil
+	}
+
+	payload, err := p.serializeEvent(event)
+	if err != nil {
+		return err
+	}
+	return p.send(p.formatTopic("talkgroups/directory"), payload, true)
+}
+
 // publish publishes an event to a topic
 func (p *Publisher) publish(topic string, event interface{}) error {
 	payload, err := p.serializeEvent(event)
@@ -154,10 +367,16 @@ func (p *Publisher) publish(topic string, event interface{}) error {
 		return err
 	}
 
+	return p.send(topic, payload, p.config.Retained)
+}
+
+// logPublish stands in for the broker connection
+func (p *Publisher) logPublish(topic string, payload []byte, retained bool) error {
 	// TODO: Implement actual MQTT publish when paho.mqtt library is added
 	p.log.Debug("Would publish MQTT event",
 		logger.String("topic", topic),
-		logger.Int("payload_size", len(payload)))
+		logger.Int("payload_size", len(payload)),
+		logger.Bool("retained", retained))
 
 	return nil
 }
@@ -2,8 +2,11 @@ package mqtt
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/privacy"
 )
 
 // TestNewPublisher tests creating a new MQTT publisher
@@ -90,6 +93,7 @@ func TestPublisher_PublishTrafficEvent(t *testing.T) {
 		DestID:    3100,
 		Timeslot:  1,
 		StreamID:  12345678,
+		Active:    true,
 		Timestamp: time.Now(),
 	}
 
@@ -225,3 +229,173 @@ func TestEventSerialization(t *testing.T) {
 		})
 	}
 }
+
+// TestPublisher_TrafficEventPosition tests coordinates are added to traffic events when known
+func TestPublisher_TrafficEventPosition(t *testing.T) {
+	pub := New(Config{Enabled: true, IncludePosition: true}, nil)
+	pub.SetPositionLookup(func(radioID uint32) (float64, float64, bool) {
+		if radioID == 3120001 {
+			return 42.33, -83.05, true
+		}
+		return 0, 0, false
+	})
+
+	payload := func(event TrafficEvent) map[string]interface{} {
+		data, err := pub.serializeEvent(pub.withPosition(event))
+		if err != nil {
+			t.Fatalf("Failed to serialize event: %v", err)
+		}
+		var decoded map[string]interface{}
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Failed to decode event: %v", err)
+		}
+		return decoded
+	}
+
+	known := payload(TrafficEvent{SourceID: 3120001, DestID: 3100, Timestamp: time.Now()})
+	if known["latitude"] != 42.33 || known["longitude"] != -83.05 {
+		t.Errorf("Expected coordinates for known source, got %v", known)
+	}
+
+	unknown := payload(TrafficEvent{SourceID: 3120002, DestID: 3100, Timestamp: time.Now()})
+	if _, ok := unknown["latitude"]; ok {
+		t.Errorf("Expected no coordinates for unknown source, got %v", unknown)
+	}
+
+	// Disabled: coordinates are never looked up
+	pub.config.IncludePosition = false
+	if off := payload(TrafficEvent{SourceID: 3120001}); off["latitude"] != nil {
+		t.Errorf("Expected no coordinates when include_position is off, got %v", off)
+	}
+}
+
+// TestPublisher_AnonymizedTraffic tests privacy mode hides the source and its position
+func TestPublisher_AnonymizedTraffic(t *testing.T) {
+	anon := privacy.New(privacy.ModeHash, "secret")
+	pub := New(Config{Enabled: true, TopicPrefix: "dmr", IncludePosition: true}, nil)
+	pub.SetAnonymizer(anon)
+	pub.SetPositionLookup(func(radioID uint32) (float64, float64, bool) {
+		return 42.33, -83.05, true
+	})
+
+	var payload []byte
+	pub.send = func(topic string, data []byte, retained bool) error {
+		payload = data
+		return nil
+	}
+
+	if err := pub.PublishTraffic(TrafficEvent{SourceID: 3120001, DestID: 3100, Timestamp: time.Now()}); err != nil {
+		t.Fatalf("PublishTraffic error: %v", err)
+	}
+	var event TrafficEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		t.Fatalf("Failed to decode event: %v", err)
+	}
+	if event.SourceID != anon.RadioID(3120001) || event.DestID != 3100 {
+		t.Errorf("Expected pseudonymous source and real destination, got %+v", event)
+	}
+	if event.Latitude != nil || event.Longitude != nil {
+		t.Errorf("Expected no coordinates in anonymized traffic, got %+v", event)
+	}
+}
+
+// TestPublisher_StatusHeartbeat tests the node heartbeat is published, retained, on <prefix>/status
+func TestPublisher_StatusHeartbeat(t *testing.T) {
+	pub := New(Config{Enabled: true, TopicPrefix: "dmr/test", StatusInterval: 10 * time.Millisecond}, nil)
+	pub.SetStatusSource(func() StatusEvent {
+		return StatusEvent{Version: "1.2.3", Peers: 4, ActiveStreams: 1}
+	})
+
+	type message struct {
+		topic    string
+		payload  []byte
+		retained bool
+	}
+	sent := make(chan message, 16)
+	pub.send = func(topic string, payload []byte, retained bool) error {
+		select {
+		case sent <- message{topic, payload, retained}:
+		default:
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- pub.Start(ctx) }()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case msg := <-sent:
+			if msg.topic != "dmr/test/status" || !msg.retained {
+				t.Fatalf("Expected retained dmr/test/status, got %q retained=%v", msg.topic, msg.retained)
+			}
+			var status StatusEvent
+			if err := json.Unmarshal(msg.payload, &status); err != nil {
+				t.Fatalf("Failed to decode status: %v", err)
+			}
+			if !status.Online || status.Version != "1.2.3" || status.Peers != 4 || status.ActiveStreams != 1 {
+				t.Errorf("Unexpected status: %+v", status)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Heartbeat %d not published", i+1)
+		}
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+// TestPublisher_TalkgroupDirectory tests the directory is published, retained, on <prefix>/talkgroups/directory
+func TestPublisher_TalkgroupDirectory(t *testing.T) {
+	pub := New(Config{Enabled: true, TopicPrefix: "dmr/test"}, nil)
+
+	var topic string
+	var payload []byte
+	var retained bool
+	pub.send = func(tp string, data []byte, r bool) error {
+		topic, payload, retained = tp, data, r
+		return nil
+	}
+
+	err := pub.PublishTalkgroupDirectory(TalkgroupDirectoryEvent{
+		Talkgroups: []Talkgroup{{ID: 9, Name: "Local"}, {ID: 3100, Name: "USA", Description: "Nationwide"}},
+		Timestamp:  time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("PublishTalkgroupDirectory error: %v", err)
+	}
+	if topic != "dmr/test/talkgroups/directory" || !retained {
+		t.Fatalf("Expected retained dmr/test/talkgroups/directory, got %q retained=%v", topic, retained)
+	}
+
+	var got TalkgroupDirectoryEvent
+	if err := json.Unmarshal(payload, &got); err != nil {
+		t.Fatalf("Failed to decode directory: %v", err)
+	}
+	if len(got.Talkgroups) != 2 || got.Talkgroups[1].ID != 3100 || got.Talkgroups[1].Description != "Nationwide" {
+		t.Errorf("Unexpected directory: %+v", got.Talkgroups)
+	}
+}
+
+// TestPublisher_LastWill tests the will marks the node offline on <prefix>/status
+func TestPublisher_LastWill(t *testing.T) {
+	pub := New(Config{Enabled: true, TopicPrefix: "dmr/test/", QoS: 1}, nil)
+
+	will := pub.LastWill()
+	if will.Topic != "dmr/test/status" {
+		t.Errorf("Expected will topic dmr/test/status, got %q", will.Topic)
+	}
+	if !will.Retained || will.QoS != 1 {
+		t.Errorf("Expected a retained QoS 1 will, got retained=%v qos=%d", will.Retained, will.QoS)
+	}
+	var status StatusEvent
+	if err := json.Unmarshal(will.Payload, &status); err != nil {
+		t.Fatalf("Failed to decode will payload: %v", err)
+	}
+	if status.Online {
+		t.Error("Expected the will to mark the node offline")
+	}
+}
@@ -0,0 +1,221 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/database"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+// voiceBurstAirtime is the audio carried by one voice burst, A (voice sync)
+// through F; counting bursts rather than wall-clock time keeps jitter and
+// lost frames out of the budget
+const voiceBurstAirtime = 60 * time.Millisecond
+
+// airtimeStream is a transmission being charged to its source ID
+type airtimeStream struct {
+	sourceID uint32
+	last     time.Time
+	blocked  bool // The source was already out of airtime when it keyed up
+	cutOff   bool // The source ran out of airtime during this transmission
+}
+
+// airtimeBudget totals each source ID's transmit time for the current (local)
+// day against daily_airtime_minutes
+type airtimeBudget struct {
+	limit time.Duration
+	now   func() time.Time
+
+	mu      sync.Mutex
+	day     string                    // Date being totalled, "2006-01-02"
+	pruned  string                    // Date saved usage was last pruned before
+	used    map[uint32]time.Duration  // radio ID -> airtime today
+	saved   map[uint32]time.Duration  // stored radio ID -> airtime saved before a restart
+	streams map[uint32]*airtimeStream // streamID -> transmission
+}
+
+func newAirtimeBudget(limit time.Duration) *airtimeBudget {
+	return &airtimeBudget{
+		limit:   limit,
+		now:     time.Now,
+		used:    make(map[uint32]time.Duration),
+		saved:   make(map[uint32]time.Duration),
+		streams: make(map[uint32]*airtimeStream),
+	}
+}
+
+// rollover starts a new day's totals once the date changes. Caller holds mu.
+func (b *airtimeBudget) rollover(now time.Time) {
+	today := now.Format("2006-01-02")
+	if b.day != today {
+		b.day = today
+		b.used = make(map[uint32]time.Duration)
+		b.saved = make(map[uint32]time.Duration)
+	}
+}
+
+// restore adds usage saved before a restart to a source's total. Usage is
+// saved under the anonymized ID in privacy mode, so it is only matched up
+// once the source keys up. Caller holds mu.
+func (b *airtimeBudget) restore(radioID, storedID uint32) {
+	if used, ok := b.saved[storedID]; ok {
+		b.used[radioID] += used
+		delete(b.saved, storedID)
+	}
+}
+
+// WithAirtimeRepo injects where daily airtime is saved, and restores today's
+// totals so a restart doesn't hand every source a fresh budget
+func (s *Server) WithAirtimeRepo(repo *database.AirtimeRepository) *Server {
+	s.airtimeRepo = repo
+	if s.airtime == nil || repo == nil {
+		return s
+	}
+
+	b := s.airtime
+	b.mu.Lock()
+	b.rollover(b.now())
+	day := b.day
+	b.mu.Unlock()
+
+	usage, err := repo.GetDay(s.systemName, day)
+	if err != nil {
+		s.log.Warn("Failed to load saved airtime", logger.Error(err))
+		return s
+	}
+	b.mu.Lock()
+	for _, u := range usage {
+		b.saved[u.RadioID] = time.Duration(u.Seconds * float64(time.Second))
+	}
+	b.mu.Unlock()
+	return s
+}
+
+// SetAirtimeExceededHandler sets the callback run when a source ID uses up
+// its daily airtime
+func (s *Server) SetAirtimeExceededHandler(fn func(radioID uint32, used time.Duration)) {
+	s.onAirtimeExceeded = fn
+}
+
+// airtimeExhausted charges a frame's voice to its source ID and reports
+// whether the frame should be dropped because the source is out of airtime
+// for the day. A transmission that runs out part way is cut off, but its
+// terminator still passes so receivers close the stream cleanly; later
+// transmissions from the source are dropped whole until the day rolls over.
+func (s *Server) airtimeExhausted(dmrd *protocol.DMRDPacket) bool {
+	b := s.airtime
+	if b == nil {
+		return false
+	}
+
+	now := b.now()
+	b.mu.Lock()
+	b.rollover(now)
+	stream, ok := b.streams[dmrd.StreamID]
+	if !ok {
+		b.restore(dmrd.SourceID, s.anon.RadioID(dmrd.SourceID))
+		stream = &airtimeStream{sourceID: dmrd.SourceID, blocked: b.used[dmrd.SourceID] >= b.limit}
+		b.streams[dmrd.StreamID] = stream
+	}
+	stream.last = now
+
+	exceeded := false
+	voice := dmrd.FrameType == protocol.FrameTypeVoice || dmrd.FrameType == protocol.FrameTypeVoiceHeader
+	if voice && !stream.blocked && !stream.cutOff {
+		b.used[dmrd.SourceID] += voiceBurstAirtime
+		if b.used[dmrd.SourceID] >= b.limit {
+			stream.cutOff = true
+			exceeded = true
+		}
+	}
+	terminator := dmrd.IsTerminator()
+	if terminator {
+		delete(b.streams, dmrd.StreamID)
+	}
+	used, day := b.used[dmrd.SourceID], b.day
+	b.mu.Unlock()
+
+	if exceeded {
+		s.log.Info("Source ID used up its daily airtime, cutting off",
+			logger.Int("src_id", int(dmrd.SourceID)),
+			logger.Int("tg", int(dmrd.DestinationID)),
+			logger.Int("ts", dmrd.Timeslot),
+			logger.String("limit", b.limit.String()))
+		if s.onAirtimeExceeded != nil {
+			s.onAirtimeExceeded(dmrd.SourceID, used)
+		}
+	}
+	if exceeded || (terminator && !stream.blocked && !stream.cutOff) {
+		s.saveAirtime(dmrd.SourceID, day, used)
+	}
+
+	drop := stream.blocked || (stream.cutOff && !terminator)
+	if !drop {
+		return false
+	}
+	if stream.blocked && dmrd.FrameType == protocol.FrameTypeVoiceHeader {
+		s.log.Debug("Source ID is out of daily airtime, transmission dropped",
+			logger.Int("src_id", int(dmrd.SourceID)),
+			logger.Int("tg", int(dmrd.DestinationID)))
+	}
+	if s.metrics != nil {
+		s.metrics.PacketDropped("airtime_budget")
+	}
+	return true
+}
+
+// saveAirtime records a source ID's usage so far today, under its
+// anonymized ID in privacy mode. Without an ID to file it under (omit mode)
+// usage is not saved.
+func (s *Server) saveAirtime(radioID uint32, day string, used time.Duration) {
+	storedID := s.anon.RadioID(radioID)
+	if s.airtimeRepo == nil || storedID == 0 {
+		return
+	}
+	if err := s.airtimeRepo.Save(&database.AirtimeUsage{
+		System:  s.systemName,
+		RadioID: storedID,
+		Day:     day,
+		Seconds: used.Seconds(),
+	}); err != nil {
+		s.log.Warn("Failed to save airtime",
+			logger.Int("src_id", int(radioID)),
+			logger.Error(err))
+	}
+}
+
+// cleanupAirtime saves and forgets transmissions that ended without a
+// terminator, and drops saved usage from earlier days once a day
+func (s *Server) cleanupAirtime(now time.Time) {
+	b := s.airtime
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	b.rollover(now)
+	day := b.day
+	ended := make(map[uint32]time.Duration)
+	for streamID, stream := range b.streams {
+		if now.Sub(stream.last) > s.muteWindow {
+			delete(b.streams, streamID)
+			if !stream.blocked && !stream.cutOff {
+				ended[stream.sourceID] = b.used[stream.sourceID]
+			}
+		}
+	}
+	prune := b.pruned != day
+	b.pruned = day
+	b.mu.Unlock()
+
+	for radioID, used := range ended {
+		s.saveAirtime(radioID, day, used)
+	}
+	if prune && s.airtimeRepo != nil {
+		if _, err := s.airtimeRepo.DeleteBefore(day); err != nil {
+			s.log.Warn("Failed to prune saved airtime", logger.Error(err))
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package network
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/database"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/metrics"
+	"github.com/dbehnke/dmr-nexus/pkg/privacy"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_DailyAirtimeBudget(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	db, err := database.NewDB(database.Config{Path: filepath.Join(t.TempDir(), "airtime.db")}, log)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	repo := database.NewAirtimeRepository(db.GetDB())
+
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65040}
+	today := time.Date(2026, 3, 9, 14, 0, 0, 0, time.Local)
+
+	// Five voice bursts (300ms) a day; daily_airtime_minutes is too coarse
+	// to exercise here, so the budget is swapped in directly
+	setup := func(t *testing.T) (*Server, *metrics.Collector, *net.UDPConn) {
+		t.Helper()
+		cfg := config.SystemConfig{Mode: "MASTER", Repeat: true, DailyAirtimeMinutes: 1}
+		m := metrics.NewCollector()
+		srv := NewServer(cfg, "test-system", log).WithMetrics(m)
+		srv.airtime = newAirtimeBudget(5 * voiceBurstAirtime)
+		srv.airtime.now = func() time.Time { return today }
+		srv.WithAirtimeRepo(repo)
+
+		serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+		if err != nil {
+			t.Fatalf("ListenUDP error: %v", err)
+		}
+		srv.conn = serverConn
+		t.Cleanup(func() { _ = serverConn.Close() })
+
+		listenConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+		if err != nil {
+			t.Fatalf("ListenUDP error: %v", err)
+		}
+		t.Cleanup(func() { _ = listenConn.Close() })
+		srv.peerManager.AddPeer(222, listenConn.LocalAddr().(*net.UDPAddr)).SetConnected()
+		srv.peerManager.AddPeer(111, srcAddr).SetConnected()
+		return srv, m, listenConn
+	}
+
+	send := func(t *testing.T, srv *Server, src, stream uint32, frameType uint8) {
+		t.Helper()
+		dmrd := &protocol.DMRDPacket{
+			SourceID:      src,
+			DestinationID: 9,
+			RepeaterID:    111,
+			Timeslot:      1,
+			CallType:      protocol.CallTypeGroup,
+			FrameType:     frameType,
+			StreamID:      stream,
+			Payload:       make([]byte, 33),
+		}
+		if frameType == protocol.FrameTypeVoiceTerminator {
+			dmrd.DataType = protocol.DataTypeTerminatorLC
+		}
+		data, err := dmrd.Encode()
+		if err != nil {
+			t.Fatalf("Encode DMRD error: %v", err)
+		}
+		srv.handleDMRD(data, srcAddr)
+	}
+
+	received := func(t *testing.T, conn *net.UDPConn) bool {
+		t.Helper()
+		buf := make([]byte, 512)
+		_ = conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		_, _, err := conn.ReadFromUDP(buf)
+		return err == nil
+	}
+
+	srv, m, conn := setup(t)
+	var exceeded []uint32
+	srv.SetAirtimeExceededHandler(func(radioID uint32, used time.Duration) {
+		exceeded = append(exceeded, radioID)
+		if used != 5*voiceBurstAirtime {
+			t.Errorf("Exceeded after %s, want %s", used, 5*voiceBurstAirtime)
+		}
+	})
+
+	// The voice sync burst carries audio too
+	send(t, srv, 3120001, 1, protocol.FrameTypeVoiceHeader)
+	for i := 0; i < 3; i++ {
+		send(t, srv, 3120001, 1, protocol.FrameTypeVoice)
+	}
+	for i := 0; i < 4; i++ {
+		if !received(t, conn) {
+			t.Fatalf("Expected frame %d inside the budget", i)
+		}
+	}
+
+	send(t, srv, 3120001, 1, protocol.FrameTypeVoice)
+	if received(t, conn) {
+		t.Fatal("Expected the burst that used up the budget to be dropped")
+	}
+	if len(exceeded) != 1 || exceeded[0] != 3120001 {
+		t.Errorf("Exceeded handler calls = %v, want [3120001]", exceeded)
+	}
+	send(t, srv, 3120001, 1, protocol.FrameTypeVoiceTerminator)
+	if !received(t, conn) {
+		t.Fatal("Expected the cut-off call's terminator to pass")
+	}
+
+	// The source's next transmission is dropped whole
+	send(t, srv, 3120001, 2, protocol.FrameTypeVoiceHeader)
+	send(t, srv, 3120001, 2, protocol.FrameTypeVoice)
+	send(t, srv, 3120001, 2, protocol.FrameTypeVoiceTerminator)
+	if received(t, conn) {
+		t.Fatal("Expected a transmission over budget to be dropped")
+	}
+	if dropped := m.GetPacketsDropped("airtime_budget"); dropped != 4 {
+		t.Errorf("Expected 4 airtime drops, got %d", dropped)
+	}
+
+	// Other sources have their own budget
+	send(t, srv, 3120002, 3, protocol.FrameTypeVoiceHeader)
+	if !received(t, conn) {
+		t.Fatal("Expected another source to pass")
+	}
+
+	// A restart keeps the day's usage
+	usage, err := repo.GetDay("test-system", today.Format("2006-01-02"))
+	if err != nil || len(usage) != 1 || usage[0].Seconds != 0.3 {
+		t.Fatalf("Saved usage = %+v, %v; want 0.3s for 3120001", usage, err)
+	}
+	restarted, _, conn2 := setup(t)
+	send(t, restarted, 3120001, 4, protocol.FrameTypeVoiceHeader)
+	send(t, restarted, 3120001, 4, protocol.FrameTypeVoiceTerminator)
+	if received(t, conn2) {
+		t.Fatal("Expected the budget to survive a restart")
+	}
+
+	// And a new day starts afresh
+	restarted.airtime.now = func() time.Time { return today.Add(24 * time.Hour) }
+	send(t, restarted, 3120001, 5, protocol.FrameTypeVoiceHeader)
+	if !received(t, conn2) {
+		t.Fatal("Expected the budget to reset the next day")
+	}
+}
+
+func TestServer_DailyAirtimeAnonymized(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	db, err := database.NewDB(database.Config{Path: filepath.Join(t.TempDir(), "airtime.db")}, log)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	repo := database.NewAirtimeRepository(db.GetDB())
+	anon := privacy.New(privacy.ModeHash, "salt")
+
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65041}
+	today := time.Date(2026, 3, 9, 14, 0, 0, 0, time.Local)
+
+	setup := func(limit time.Duration) (*Server, *metrics.Collector) {
+		cfg := config.SystemConfig{Mode: "MASTER", DailyAirtimeMinutes: 1}
+		m := metrics.NewCollector()
+		srv := NewServer(cfg, "test-system", log).WithAnonymizer(anon).WithMetrics(m)
+		srv.airtime = newAirtimeBudget(limit)
+		srv.airtime.now = func() time.Time { return today }
+		srv.WithAirtimeRepo(repo)
+
+		serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+		if err != nil {
+			t.Fatalf("ListenUDP error: %v", err)
+		}
+		srv.conn = serverConn
+		t.Cleanup(func() { _ = serverConn.Close() })
+		srv.peerManager.AddPeer(111, srcAddr).SetConnected()
+		return srv, m
+	}
+	transmit := func(srv *Server, stream uint32) {
+		for _, ft := range []byte{protocol.FrameTypeVoiceHeader, protocol.FrameTypeVoice, protocol.FrameTypeVoiceTerminator} {
+			dmrd := &protocol.DMRDPacket{
+				SourceID:      3120001,
+				DestinationID: 9,
+				RepeaterID:    111,
+				Timeslot:      1,
+				CallType:      protocol.CallTypeGroup,
+				FrameType:     ft,
+				StreamID:      stream,
+				Payload:       make([]byte, 33),
+			}
+			if ft == protocol.FrameTypeVoiceTerminator {
+				dmrd.DataType = protocol.DataTypeTerminatorLC
+			}
+			data, err := dmrd.Encode()
+			if err != nil {
+				t.Fatalf("Encode DMRD error: %v", err)
+			}
+			srv.handleDMRD(data, srcAddr)
+		}
+	}
+
+	// Two bursts inside a three-burst budget, saved under the pseudonym
+	srv, _ := setup(3 * voiceBurstAirtime)
+	transmit(srv, 1)
+	usage, err := repo.GetDay("test-system", today.Format("2006-01-02"))
+	if err != nil || len(usage) != 1 {
+		t.Fatalf("Saved usage = %+v, %v; want one row", usage, err)
+	}
+	if usage[0].RadioID != anon.RadioID(3120001) {
+		t.Fatalf("Expected usage saved under the anonymized ID, got radio ID %d", usage[0].RadioID)
+	}
+
+	// After a restart with a two-burst budget the saved usage is matched
+	// back to the real source, which is out of airtime
+	restarted, m := setup(2 * voiceBurstAirtime)
+	transmit(restarted, 2)
+	if dropped := m.GetPacketsDropped("airtime_budget"); dropped != 3 {
+		t.Errorf("Expected the restored source's transmission dropped whole, got %d drops", dropped)
+	}
+}
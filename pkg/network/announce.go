@@ -0,0 +1,241 @@
+package network
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/peer"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+const (
+	// announceBurstSize is one pre-rendered DMR voice burst (a DMRD payload)
+	announceBurstSize = 33
+	// announceFrameInterval paces announcement bursts at the air rate
+	announceFrameInterval = 60 * time.Millisecond
+	// maxAnnounceHeld bounds the caller frames buffered while a clip plays
+	maxAnnounceHeld = 200
+)
+
+// announceClipChars is the fixed phonetic clip set
+const announceClipChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// AnnounceClips holds pre-rendered AMBE voice bursts for each character
+type AnnounceClips struct {
+	clips map[rune][][]byte
+}
+
+// LoadAnnounceClips reads <char>.ambe for each letter and digit in dir, plus
+// an optional space.ambe pause between words. Each file is a sequence of
+// 33-byte voice bursts; missing characters are skipped when text is assembled.
+func LoadAnnounceClips(dir string) (*AnnounceClips, error) {
+	c := &AnnounceClips{clips: make(map[rune][][]byte)}
+	for _, ch := range announceClipChars + " " {
+		name := string(ch)
+		if ch == ' ' {
+			name = "space"
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name+".ambe"))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read announce clip %c: %w", ch, err)
+		}
+		if len(data) == 0 || len(data)%announceBurstSize != 0 {
+			return nil, fmt.Errorf("announce clip %c: size %d is not a multiple of %d", ch, len(data), announceBurstSize)
+		}
+		for off := 0; off < len(data); off += announceBurstSize {
+			c.clips[ch] = append(c.clips[ch], data[off:off+announceBurstSize])
+		}
+	}
+	if len(c.clips) == 0 {
+		return nil, fmt.Errorf("no announce clips found in %s", dir)
+	}
+	return c, nil
+}
+
+// Assemble returns the voice bursts spelling text, skipping characters
+// without a clip
+func (c *AnnounceClips) Assemble(text string) [][]byte {
+	var bursts [][]byte
+	for _, ch := range strings.ToUpper(text) {
+		bursts = append(bursts, c.clips[ch]...)
+	}
+	return bursts
+}
+
+// callerAnnouncement buffers a caller's frames while their callsign plays,
+// then marks the stream as announced until its terminator
+type callerAnnouncement struct {
+	held    [][]byte
+	playing bool      // Caller frames are held until the clip ends
+	ended   bool      // The caller's terminator is among the held frames
+	last    time.Time // Last frame of the caller's stream
+}
+
+// SetCallerAnnouncement enables announcing callers: on each voice header the
+// caller's callsign (from lookup) is spelled from clips as a short stream to
+// local listeners before the caller's own audio, which is held meanwhile.
+func (s *Server) SetCallerAnnouncement(clips *AnnounceClips, lookup func(radioID uint32) string) {
+	s.announceMu.Lock()
+	defer s.announceMu.Unlock()
+	s.announceClips = clips
+	s.callsignLookup = lookup
+}
+
+// holdForAnnouncement reports whether a frame was taken to be delivered after
+// a callsign announcement. A voice header with a known callsign starts one;
+// the voice sync bursts repeating that frame type through the call don't.
+func (s *Server) holdForAnnouncement(dmrd *protocol.DMRDPacket, data []byte, sourcePeerID uint32) bool {
+	s.announceMu.Lock()
+	defer s.announceMu.Unlock()
+
+	if a, ok := s.announcing[dmrd.StreamID]; ok {
+		a.last = time.Now()
+		if !a.playing {
+			if dmrd.IsTerminator() {
+				delete(s.announcing, dmrd.StreamID)
+			}
+			return false
+		}
+		if dmrd.IsTerminator() {
+			a.ended = true
+		}
+		if len(a.held) >= maxAnnounceHeld {
+			if s.metrics != nil {
+				s.metrics.PacketDropped("announce_overflow")
+			}
+			return true
+		}
+		a.held = append(a.held, append([]byte(nil), data...))
+		return true
+	}
+
+	if s.announceClips == nil || s.callsignLookup == nil || dmrd.FrameType != protocol.FrameTypeVoiceHeader {
+		return false
+	}
+	callsign := s.callsignLookup(dmrd.SourceID)
+	bursts := s.announceClips.Assemble(callsign)
+	if len(bursts) == 0 {
+		return false
+	}
+
+	s.announcing[dmrd.StreamID] = &callerAnnouncement{
+		held:    [][]byte{append([]byte(nil), data...)},
+		playing: true,
+		last:    time.Now(),
+	}
+	header := *dmrd
+	go s.playAnnouncement(&header, bursts, sourcePeerID)
+
+	s.log.Debug("Announcing caller",
+		logger.Int("src", int(dmrd.SourceID)),
+		logger.String("callsign", callsign),
+		logger.Int("tg", int(dmrd.DestinationID)),
+		logger.Int("bursts", len(bursts)))
+	return true
+}
+
+// playAnnouncement sends the callsign as its own stream (header, bursts,
+// terminator) reusing the caller's voice header, then releases the caller's
+// held frames in order
+func (s *Server) playAnnouncement(header *protocol.DMRDPacket, bursts [][]byte, sourcePeerID uint32) {
+	callerStream := header.StreamID
+
+	frames := announcementFrames(*header, bursts)
+	for i := range frames {
+		data, err := frames[i].Encode()
+		if err != nil {
+			s.log.Error("Failed to encode announcement frame", logger.Error(err))
+			break
+		}
+		s.deliverLocal(&frames[i], data, sourcePeerID)
+		if s.announceInterval > 0 {
+			time.Sleep(s.announceInterval)
+		}
+	}
+
+	// Drain the caller's frames; new ones keep queuing behind until empty.
+	// The stream stays marked as announced until its terminator.
+	for {
+		s.announceMu.Lock()
+		a := s.announcing[callerStream]
+		held := a.held
+		a.held = nil
+		if len(held) == 0 {
+			a.playing = false
+			if a.ended {
+				delete(s.announcing, callerStream)
+			}
+			s.announceMu.Unlock()
+			return
+		}
+		s.announceMu.Unlock()
+
+		for _, data := range held {
+			dmrd, err := protocol.ParseDMRD(data)
+			if err != nil {
+				continue
+			}
+			s.deliverLocal(dmrd, data, sourcePeerID)
+		}
+	}
+}
+
+// announcementFrames builds a stream of its own (header, bursts, terminator)
+// from a voice header template
+func announcementFrames(header protocol.DMRDPacket, bursts [][]byte) []protocol.DMRDPacket {
+	var id [4]byte
+	_, _ = rand.Read(id[:])
+	header.StreamID = binary.BigEndian.Uint32(id[:])
+	header.FrameType = protocol.FrameTypeVoiceHeader
+	header.HMAC = nil
+
+	frames := make([]protocol.DMRDPacket, 0, len(bursts)+2)
+	frames = append(frames, header)
+	for i, burst := range bursts {
+		f := header
+		f.FrameType = protocol.FrameTypeVoice
+		f.DataType = byte(i % 6)
+		f.Payload = burst
+		frames = append(frames, f)
+	}
+	term := header
+	term.FrameType = protocol.FrameTypeVoiceTerminator
+	term.DataType = protocol.DataTypeTerminatorLC
+	frames = append(frames, term)
+
+	for i := range frames {
+		frames[i].Sequence = byte(i)
+	}
+	return frames
+}
+
+// cleanupAnnouncements forgets announced streams that went idle without a
+// terminator
+func (s *Server) cleanupAnnouncements(now time.Time) {
+	s.announceMu.Lock()
+	defer s.announceMu.Unlock()
+	for streamID, a := range s.announcing {
+		if !a.playing && now.Sub(a.last) > peer.StreamIdleTimeout {
+			delete(s.announcing, streamID)
+		}
+	}
+}
+
+// deliverLocal sends a frame to this system's dynamic subscribers and, with
+// repeat enabled, to every other peer
+func (s *Server) deliverLocal(dmrd *protocol.DMRDPacket, data []byte, sourcePeerID uint32) {
+	targets := s.findDynamicSubscribers(dmrd.DestinationID, uint8(dmrd.Timeslot), sourcePeerID)
+	s.forwardToDynamicSubscribers(dmrd, data, targets)
+	if s.config.Repeat {
+		s.forwardDMRD(dmrd, data, sourcePeerID)
+	}
+}
@@ -0,0 +1,142 @@
+package network
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_AnnouncementPrecedesCallerAudio(t *testing.T) {
+	// One distinguishable burst per clip character
+	dir := t.TempDir()
+	clipBytes := map[string]byte{"W": 0xa1, "1": 0xb1, "A": 0xc1}
+	for ch, b := range clipBytes {
+		if err := os.WriteFile(filepath.Join(dir, ch+".ambe"), bytes.Repeat([]byte{b}, announceBurstSize), 0o644); err != nil {
+			t.Fatalf("WriteFile error: %v", err)
+		}
+	}
+	clips, err := LoadAnnounceClips(dir)
+	if err != nil {
+		t.Fatalf("LoadAnnounceClips error: %v", err)
+	}
+
+	cfg := config.SystemConfig{Mode: "MASTER"}
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(cfg, "test-system", log).WithRouter(bridge.NewRouter())
+	srv.announceInterval = 0
+	srv.SetCallerAnnouncement(clips, func(radioID uint32) string {
+		if radioID == 3120001 {
+			return "w1aw"
+		}
+		return ""
+	})
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	listenConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = listenConn.Close() }()
+	listener := srv.peerManager.AddPeer(222, listenConn.LocalAddr().(*net.UDPAddr))
+	listener.SetConnected()
+	listener.Subscriptions.AddDynamic(3100, 1)
+
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65010}
+	source := srv.peerManager.AddPeer(111, srcAddr)
+	source.SetConnected()
+	source.Subscriptions.AddDynamic(3100, 1)
+
+	// The caller's voice sync bursts repeat the header's frame type every
+	// superframe; only the first starts an announcement
+	callerPayload := bytes.Repeat([]byte{0x55}, 33)
+	callerFrames := []byte{protocol.FrameTypeVoiceHeader, protocol.FrameTypeVoice,
+		protocol.FrameTypeVoiceHeader, protocol.FrameTypeVoice, protocol.FrameTypeVoiceTerminator}
+	send := func(streamID uint32) {
+		for _, ft := range callerFrames {
+			dmrd := &protocol.DMRDPacket{
+				SourceID:      3120001,
+				DestinationID: 3100,
+				RepeaterID:    111,
+				Timeslot:      1,
+				CallType:      protocol.CallTypeGroup,
+				FrameType:     ft,
+				StreamID:      streamID,
+				Payload:       callerPayload,
+			}
+			if ft == protocol.FrameTypeVoiceTerminator {
+				dmrd.DataType = protocol.DataTypeTerminatorLC
+			}
+			data, err := dmrd.Encode()
+			if err != nil {
+				t.Fatalf("Encode DMRD error: %v", err)
+			}
+			srv.handleDMRD(data, srcAddr)
+		}
+	}
+	send(900)
+
+	var got []*protocol.DMRDPacket
+	buf := make([]byte, 2048)
+	for {
+		if err := listenConn.SetReadDeadline(time.Now().Add(200 * time.Millisecond)); err != nil {
+			t.Fatalf("SetReadDeadline error: %v", err)
+		}
+		n, _, err := listenConn.ReadFromUDP(buf)
+		if err != nil {
+			break
+		}
+		pkt, err := protocol.ParseDMRD(buf[:n])
+		if err != nil {
+			t.Fatalf("ParseDMRD error: %v", err)
+		}
+		got = append(got, pkt)
+	}
+
+	// Announcement: header, W 1 A W bursts, terminator; then the caller's 5 frames
+	if len(got) != 11 {
+		t.Fatalf("Expected 11 frames, got %d", len(got))
+	}
+	announceStream := got[0].StreamID
+	if announceStream == 900 || got[0].FrameType != protocol.FrameTypeVoiceHeader {
+		t.Fatalf("Expected announcement header on its own stream first, got stream %d type %d", got[0].StreamID, got[0].FrameType)
+	}
+	for i, want := range []byte{0xa1, 0xb1, 0xc1, 0xa1} {
+		f := got[1+i]
+		if f.StreamID != announceStream || f.FrameType != protocol.FrameTypeVoice || f.Payload[0] != want {
+			t.Errorf("Announcement burst %d: stream %d type %d payload %#x, want clip %#x", i, f.StreamID, f.FrameType, f.Payload[0], want)
+		}
+		if f.SourceID != 3120001 || f.DestinationID != 3100 {
+			t.Errorf("Announcement burst %d: src=%d dst=%d", i, f.SourceID, f.DestinationID)
+		}
+	}
+	if got[5].StreamID != announceStream || !got[5].IsTerminator() || got[5].DataType != protocol.DataTypeTerminatorLC {
+		t.Errorf("Expected announcement terminator with LC, got stream %d type %d/%d", got[5].StreamID, got[5].FrameType, got[5].DataType)
+	}
+	for i, ft := range callerFrames {
+		f := got[6+i]
+		if f.StreamID != 900 || f.FrameType != ft || !bytes.Equal(f.Payload, callerPayload) {
+			t.Errorf("Caller frame %d out of order: stream %d type %d", i, f.StreamID, f.FrameType)
+		}
+	}
+
+	srv.announceMu.Lock()
+	_, tracked := srv.announcing[900]
+	srv.announceMu.Unlock()
+	if tracked {
+		t.Error("Expected the announced stream forgotten after its terminator")
+	}
+}
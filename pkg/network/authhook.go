@@ -0,0 +1,116 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultAuthWebhookTimeout bounds a single authorization request
+	DefaultAuthWebhookTimeout = 5 * time.Second
+	// DefaultAuthWebhookCacheTTL is how long a webhook decision is reused
+	DefaultAuthWebhookCacheTTL = 60 * time.Second
+)
+
+// AuthRequest is the JSON body POSTed to the authorization webhook
+type AuthRequest struct {
+	PeerID   uint32 `json:"peer_id"`
+	Callsign string `json:"callsign"`
+	Address  string `json:"address"`
+	System   string `json:"system"`
+}
+
+// AuthDecision is the JSON response expected from the authorization webhook
+type AuthDecision struct {
+	Allow   bool   `json:"allow"`
+	Options string `json:"options,omitempty"` // Forced OPTIONS, e.g. "TS2=91;ALLOW=91,3100"
+	Reason  string `json:"reason,omitempty"`
+}
+
+type authCacheEntry struct {
+	decision AuthDecision
+	expires  time.Time
+}
+
+// AuthWebhook asks an external HTTP service whether a peer may log in and
+// caches the answer briefly so reconnect storms don't hammer it
+type AuthWebhook struct {
+	url    string
+	client *http.Client
+	ttl    time.Duration
+
+	cache map[string]authCacheEntry
+	mu    sync.Mutex
+}
+
+// NewAuthWebhook creates a webhook client. Non-positive timeout or ttl use the defaults.
+func NewAuthWebhook(url string, timeout, ttl time.Duration) *AuthWebhook {
+	if timeout <= 0 {
+		timeout = DefaultAuthWebhookTimeout
+	}
+	if ttl <= 0 {
+		ttl = DefaultAuthWebhookCacheTTL
+	}
+	return &AuthWebhook{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+		ttl:    ttl,
+		cache:  make(map[string]authCacheEntry),
+	}
+}
+
+// Authorize returns the webhook's decision for a peer, using a cached answer
+// for the same peer ID, callsign and address when one is still fresh
+func (w *AuthWebhook) Authorize(ctx context.Context, req AuthRequest) (*AuthDecision, error) {
+	key := fmt.Sprintf("%d|%s|%s", req.PeerID, req.Callsign, req.Address)
+	now := time.Now()
+
+	w.mu.Lock()
+	if entry, ok := w.cache[key]; ok && now.Before(entry.expires) {
+		w.mu.Unlock()
+		decision := entry.decision
+		return &decision, nil
+	}
+	w.mu.Unlock()
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode auth request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create auth request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("auth webhook request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("auth webhook returned status %d", resp.StatusCode)
+	}
+
+	var decision AuthDecision
+	if err := json.NewDecoder(resp.Body).Decode(&decision); err != nil {
+		return nil, fmt.Errorf("failed to decode auth webhook response: %w", err)
+	}
+
+	w.mu.Lock()
+	w.cache[key] = authCacheEntry{decision: decision, expires: now.Add(w.ttl)}
+	for k, entry := range w.cache {
+		if now.After(entry.expires) {
+			delete(w.cache, k)
+		}
+	}
+	w.mu.Unlock()
+
+	return &decision, nil
+}
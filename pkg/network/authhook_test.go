@@ -0,0 +1,62 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAuthWebhook_CachesDecision(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		var req AuthRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		_ = json.NewEncoder(w).Encode(AuthDecision{Allow: req.Callsign == "W1ABC"})
+	}))
+	defer ts.Close()
+
+	hook := NewAuthWebhook(ts.URL, time.Second, time.Minute)
+	req := AuthRequest{PeerID: 312000, Callsign: "W1ABC", Address: "127.0.0.1:50000"}
+
+	for i := 0; i < 3; i++ {
+		decision, err := hook.Authorize(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Authorize error: %v", err)
+		}
+		if !decision.Allow {
+			t.Fatal("Expected allow decision")
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected 1 webhook call with caching, got %d", got)
+	}
+
+	// A different callsign is a different cache entry
+	req.Callsign = "N0CALL"
+	decision, err := hook.Authorize(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Authorize error: %v", err)
+	}
+	if decision.Allow {
+		t.Error("Expected deny decision for other callsign")
+	}
+}
+
+func TestAuthWebhook_ErrorStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	hook := NewAuthWebhook(ts.URL, time.Second, time.Minute)
+	if _, err := hook.Authorize(context.Background(), AuthRequest{PeerID: 1}); err == nil {
+		t.Fatal("Expected error for non-200 webhook response")
+	}
+}
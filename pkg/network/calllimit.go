@@ -0,0 +1,78 @@
+package network
+
+import (
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+// timedCall is a group call being timed against its talkgroup's limit
+type timedCall struct {
+	start  time.Time
+	last   time.Time
+	cutOff bool
+}
+
+// callLimit returns how long a group call on tgid may last: the talkgroup's
+// call_limits entry if it has one, otherwise max_call_seconds. Zero means
+// unlimited.
+func (s *Server) callLimit(tgid uint32) time.Duration {
+	if limit, ok := s.callLimits[tgid]; ok {
+		return limit
+	}
+	return time.Duration(s.config.MaxCallSeconds) * time.Second
+}
+
+// callTimedOut reports whether a group call frame should be dropped because
+// the call has outlasted its limit. The terminator still passes so
+// receivers close the stream cleanly.
+func (s *Server) callTimedOut(dmrd *protocol.DMRDPacket) bool {
+	if dmrd.CallType != protocol.CallTypeGroup {
+		return false
+	}
+	limit := s.callLimit(dmrd.DestinationID)
+	if limit <= 0 {
+		return false
+	}
+
+	s.timedCallMu.Lock()
+	defer s.timedCallMu.Unlock()
+
+	now := time.Now()
+	call, ok := s.timedCalls[dmrd.StreamID]
+	if !ok {
+		call = &timedCall{start: now}
+		s.timedCalls[dmrd.StreamID] = call
+	}
+	call.last = now
+
+	if dmrd.IsTerminator() {
+		delete(s.timedCalls, dmrd.StreamID)
+		return false
+	}
+
+	if !call.cutOff && now.Sub(call.start) > limit {
+		call.cutOff = true
+		s.log.Info("Call exceeded talkgroup time limit, cutting off",
+			logger.Int("src_id", int(dmrd.SourceID)),
+			logger.Int("tg", int(dmrd.DestinationID)),
+			logger.Int("ts", dmrd.Timeslot),
+			logger.String("limit", limit.String()))
+	}
+	if call.cutOff && s.metrics != nil {
+		s.metrics.PacketDropped("call_timeout")
+	}
+	return call.cutOff
+}
+
+// cleanupTimedCalls forgets calls that ended without a terminator
+func (s *Server) cleanupTimedCalls(now time.Time) {
+	s.timedCallMu.Lock()
+	defer s.timedCallMu.Unlock()
+	for streamID, call := range s.timedCalls {
+		if now.Sub(call.last) > s.muteWindow {
+			delete(s.timedCalls, streamID)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package network
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_CallLimitsPerTalkgroup(t *testing.T) {
+	cfg := config.SystemConfig{
+		Mode:           "MASTER",
+		MaxCallSeconds: 60,
+		CallLimits: []config.CallLimit{
+			{TGID: 3100, MaxSeconds: 180},
+			{TGID: 9000, MaxSeconds: 0},
+		},
+	}
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(cfg, "test-system", log).WithRouter(bridge.NewRouter())
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	listenConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = listenConn.Close() }()
+	listener := srv.peerManager.AddPeer(222, listenConn.LocalAddr().(*net.UDPAddr))
+	listener.SetConnected()
+
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65016}
+	source := srv.peerManager.AddPeer(111, srcAddr)
+	source.SetConnected()
+
+	send := func(tg, streamID uint32, frameType byte) {
+		dmrd := &protocol.DMRDPacket{
+			SourceID:      3120001,
+			DestinationID: tg,
+			RepeaterID:    111,
+			Timeslot:      1,
+			CallType:      protocol.CallTypeGroup,
+			FrameType:     frameType,
+			StreamID:      streamID,
+			Payload:       make([]byte, 33),
+		}
+		if frameType == protocol.FrameTypeVoiceTerminator {
+			dmrd.DataType = protocol.DataTypeTerminatorLC
+		}
+		data, err := dmrd.Encode()
+		if err != nil {
+			t.Fatalf("Encode DMRD error: %v", err)
+		}
+		srv.handleDMRD(data, srcAddr)
+	}
+
+	// received drains the listener and reports whether a voice frame arrived
+	received := func() bool {
+		buf := make([]byte, 512)
+		gotVoice := false
+		for {
+			_ = listenConn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+			n, _, err := listenConn.ReadFromUDP(buf)
+			if err != nil {
+				return gotVoice
+			}
+			if pkt, err := protocol.ParseDMRD(buf[:n]); err == nil && pkt.FrameType == protocol.FrameTypeVoice {
+				gotVoice = true
+			}
+		}
+	}
+
+	cases := []struct {
+		tg        uint32
+		wantVoice bool
+	}{
+		{91, false},  // Global 60s limit
+		{3100, true}, // 180s override
+		{9000, true}, // Unlimited override
+	}
+	for i, tc := range cases {
+		streamID := uint32(100 + i)
+		listener.Subscriptions.AddDynamic(tc.tg, 1)
+		source.Subscriptions.AddDynamic(tc.tg, 1)
+
+		send(tc.tg, streamID, protocol.FrameTypeVoiceHeader)
+		// Pretend the call has been going for two minutes
+		srv.timedCallMu.Lock()
+		if call, ok := srv.timedCalls[streamID]; ok {
+			call.start = call.start.Add(-2 * time.Minute)
+		}
+		srv.timedCallMu.Unlock()
+		send(tc.tg, streamID, protocol.FrameTypeVoice)
+
+		if got := received(); got != tc.wantVoice {
+			t.Errorf("TG %d: voice forwarded after two minutes = %v, want %v", tc.tg, got, tc.wantVoice)
+		}
+		send(tc.tg, streamID, protocol.FrameTypeVoiceTerminator)
+		received()
+	}
+
+	srv.timedCallMu.Lock()
+	defer srv.timedCallMu.Unlock()
+	if len(srv.timedCalls) != 0 {
+		t.Errorf("Expected terminators to clear timed calls, %d left", len(srv.timedCalls))
+	}
+}
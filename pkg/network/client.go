@@ -3,12 +3,14 @@ package network
 import (
 	"context"
 	"crypto/sha256"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"net"
 	"sync"
 	"time"
 
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
 	"github.com/dbehnke/dmr-nexus/pkg/config"
 	"github.com/dbehnke/dmr-nexus/pkg/logger"
 	"github.com/dbehnke/dmr-nexus/pkg/protocol"
@@ -40,6 +42,12 @@ type Client struct {
 	lastPingMu  sync.RWMutex
 }
 
+// Reconnect backoff for Run: doubles after each failed attempt up to the cap
+const (
+	clientRetryMin = time.Second
+	clientRetryMax = time.Minute
+)
+
 // NewClient creates a new UDP client for PEER mode
 func NewClient(cfg config.SystemConfig, log *logger.Logger) *Client {
 	return &Client{
@@ -50,6 +58,70 @@ func NewClient(cfg config.SystemConfig, log *logger.Logger) *Client {
 	}
 }
 
+// WithRouter connects the client to a bridge router as system systemName:
+// DMRD from the master is routed to other systems, and traffic bridged to
+// this system is sent up to the master
+func (c *Client) WithRouter(r *bridge.Router, systemName string) *Client {
+	r.RegisterSystem(systemName, c.deliverBridged)
+	r.SetSystemAvailability(systemName, func() bool { return c.getState() == StateConnected })
+	r.RegisterPeer(uint32(c.config.RadioID), systemName)
+	r.SetTransmissionLogging(systemName, c.config.LogTransmissions)
+	c.OnDMRD(func(packet *protocol.DMRDPacket) {
+		targets := r.RoutePacket(packet, systemName)
+		if len(targets) == 0 {
+			return
+		}
+		data, err := packet.Encode()
+		if err != nil {
+			c.log.Error("Failed to encode DMRD", logger.Error(err))
+			return
+		}
+		r.ForwardToSystems(targets, packet, data)
+	})
+	return c
+}
+
+// deliverBridged sends traffic another system bridged here up to the
+// master, as coming from this client's repeater ID
+func (c *Client) deliverBridged(dmrd *protocol.DMRDPacket, data []byte) {
+	packet := *dmrd
+	packet.RepeaterID = uint32(c.config.RadioID)
+	if err := c.SendDMRD(&packet); err != nil {
+		c.log.Debug("Dropping bridged DMRD", logger.Error(err))
+	}
+}
+
+// Run keeps the client connected to the master until ctx is cancelled.
+// Failed or dropped connections are retried with exponential backoff.
+func (c *Client) Run(ctx context.Context) error {
+	delay := clientRetryMin
+	for {
+		started := time.Now()
+		err := c.Start(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// A connection that held for a while starts the backoff over
+		if time.Since(started) > clientRetryMax {
+			delay = clientRetryMin
+		}
+		c.log.Warn("Connection to master lost, retrying",
+			logger.Error(err),
+			logger.String("retry_in", delay.String()))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > clientRetryMax {
+			delay = clientRetryMax
+		}
+	}
+}
+
 // Start starts the client and connects to the master
 func (c *Client) Start(ctx context.Context) error {
 	// Resolve master address
@@ -57,7 +129,6 @@ func (c *Client) Start(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to resolve master address: %w", err)
 	}
-	c.masterAddr = masterAddr
 
 	// Create local UDP address
 	localAddr := &net.UDPAddr{
@@ -70,9 +141,13 @@ func (c *Client) Start(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to create UDP connection: %w", err)
 	}
+	c.stateMu.Lock()
 	c.conn = conn
+	c.masterAddr = masterAddr
+	c.stateMu.Unlock()
 	defer func() {
-		_ = c.conn.Close()
+		c.setState(StateDisconnected)
+		_ = conn.Close()
 	}()
 
 	c.log.Info("Client started",
@@ -84,26 +159,50 @@ func (c *Client) Start(ctx context.Context) error {
 		return fmt.Errorf("authentication failed: %w", err)
 	}
 
-	// Start goroutines for receiving and keepalive
+	c.updateLastPing()
+
+	// Start goroutines for receiving and keepalive; both stop before the
+	// connection is closed
+	loopCtx, stop := context.WithCancel(ctx)
+	var loops sync.WaitGroup
+	defer loops.Wait()
+	defer stop()
 	errChan := make(chan error, 2)
 
+	loops.Add(2)
 	go func() {
-		errChan <- c.receiveLoop(ctx)
+		defer loops.Done()
+		errChan <- c.receiveLoop(loopCtx)
 	}()
 
 	go func() {
-		errChan <- c.keepaliveLoop(ctx)
+		defer loops.Done()
+		errChan <- c.keepaliveLoop(loopCtx)
 	}()
 
 	// Wait for context cancellation or error
 	select {
 	case <-ctx.Done():
+		c.sendClose()
 		return ctx.Err()
 	case err := <-errChan:
 		return err
 	}
 }
 
+// sendClose tells the master this client is going away
+func (c *Client) sendClose() {
+	if c.getState() != StateConnected {
+		return
+	}
+	data := make([]byte, protocol.RPTCLPacketSize)
+	copy(data, protocol.PacketTypeRPTCL)
+	binary.BigEndian.PutUint32(data[5:], uint32(c.config.RadioID))
+	if _, err := c.conn.WriteToUDP(data, c.masterAddr); err != nil {
+		c.log.Debug("Failed to send RPTCL", logger.Error(err))
+	}
+}
+
 // authenticate performs the authentication handshake with the master
 func (c *Client) authenticate() error {
 	// Step 1: Send RPTL (login request)
@@ -271,11 +370,15 @@ func (c *Client) receiveLoop(ctx context.Context) error {
 		n, _, err := c.conn.ReadFromUDP(buffer)
 		if err != nil {
 			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				c.checkMasterSilence()
 				continue
 			}
 			return fmt.Errorf("read error: %w", err)
 		}
 
+		// Anything from the master shows the path to it is still open
+		c.updateLastPing()
+
 		// Process received packet
 		c.handlePacket(buffer[:n])
 	}
@@ -316,6 +419,12 @@ func (c *Client) handlePacket(data []byte) {
 		c.log.Debug("Received MSTPONG")
 		c.updateLastPing()
 
+	case len(data) > protocol.MSTRDRMinPacketSize && string(data[0:6]) == protocol.PacketTypeMSTRDR:
+		// MSTRDR - master shutting down, suggests another master
+		if rdr, err := protocol.ParseMSTRDR(data); err == nil {
+			c.log.Warn("Master is redirecting peers", logger.String("backup_master", rdr.Address))
+		}
+
 	case len(data) >= protocol.MSTCLPacketSize && string(data[0:5]) == protocol.PacketTypeMSTCL:
 		// MSTCL - master closing connection
 		c.log.Warn("Received MSTCL - master closing connection")
@@ -326,6 +435,32 @@ func (c *Client) handlePacket(data []byte) {
 	}
 }
 
+// checkMasterSilence re-registers once nothing has been heard from the master
+// for master_silence_timeout. After a NAT rebind the master no longer knows
+// this client's public address, so pings go unanswered until a fresh RPTL
+// registers the new one. Runs on the receive goroutine, so the handshake's
+// reads don't race the receive loop.
+func (c *Client) checkMasterSilence() {
+	timeout := time.Duration(c.config.MasterSilenceTimeout) * time.Second
+	if timeout <= 0 {
+		return
+	}
+	silence := time.Since(c.getLastPing())
+	if silence < timeout {
+		return
+	}
+
+	c.log.Warn("No traffic from master, re-registering",
+		logger.String("master", c.masterAddr.String()),
+		logger.String("silence", silence.Round(time.Second).String()))
+	c.setState(StateDisconnected)
+	if err := c.authenticate(); err != nil {
+		c.log.Warn("Re-registration with master failed", logger.Error(err))
+	}
+	// Wait a full timeout before trying again
+	c.updateLastPing()
+}
+
 // keepaliveLoop sends periodic RPTPING packets
 func (c *Client) keepaliveLoop(ctx context.Context) error {
 	ticker := time.NewTicker(5 * time.Second)
@@ -363,7 +498,10 @@ func (c *Client) keepaliveLoop(ctx context.Context) error {
 
 // SendDMRD sends a DMRD packet to the master
 func (c *Client) SendDMRD(packet *protocol.DMRDPacket) error {
-	if c.getState() != StateConnected {
+	c.stateMu.RLock()
+	conn, masterAddr, state := c.conn, c.masterAddr, c.state
+	c.stateMu.RUnlock()
+	if state != StateConnected {
 		return fmt.Errorf("not connected to master")
 	}
 
@@ -372,7 +510,7 @@ func (c *Client) SendDMRD(packet *protocol.DMRDPacket) error {
 		return fmt.Errorf("failed to encode DMRD: %w", err)
 	}
 
-	_, err = c.conn.WriteToUDP(data, c.masterAddr)
+	_, err = conn.WriteToUDP(data, masterAddr)
 	if err != nil {
 		return fmt.Errorf("failed to send DMRD: %w", err)
 	}
@@ -418,3 +556,9 @@ func (c *Client) updateLastPing() {
 	c.lastPing = time.Now()
 	c.lastPingMu.Unlock()
 }
+
+func (c *Client) getLastPing() time.Time {
+	c.lastPingMu.RLock()
+	defer c.lastPingMu.RUnlock()
+	return c.lastPing
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
 	"github.com/dbehnke/dmr-nexus/pkg/config"
 	"github.com/dbehnke/dmr-nexus/pkg/logger"
 	"github.com/dbehnke/dmr-nexus/pkg/protocol"
@@ -569,3 +570,167 @@ func TestClient_Race(t *testing.T) {
 		t.Fatal("Timeout waiting for client shutdown")
 	}
 }
+
+func TestClient_ReregistersAfterMasterSilence(t *testing.T) {
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("Failed to create mock server: %v", err)
+	}
+	defer func() { _ = serverConn.Close() }()
+
+	cfg := config.SystemConfig{
+		Mode:                 "PEER",
+		MasterIP:             "127.0.0.1",
+		MasterPort:           serverConn.LocalAddr().(*net.UDPAddr).Port,
+		Port:                 0,
+		RadioID:              312000,
+		Passphrase:           "test",
+		MasterSilenceTimeout: 1,
+	}
+	client := NewClient(cfg, logger.New(logger.Config{Level: "error"}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() { _ = client.Start(ctx) }()
+
+	// The mock master completes every handshake, then goes silent
+	ack, _ := (&protocol.RPTACKPacket{RepeaterID: 312000, Salt: []byte{0x01, 0x02, 0x03, 0x04}}).Encode()
+	logins := 0
+	buffer := make([]byte, 1024)
+	for logins < 2 {
+		if err := serverConn.SetReadDeadline(time.Now().Add(3 * time.Second)); err != nil {
+			t.Fatalf("SetReadDeadline error: %v", err)
+		}
+		n, addr, err := serverConn.ReadFromUDP(buffer)
+		if err != nil {
+			t.Fatalf("Expected the client to re-register after master silence (logins = %d): %v", logins, err)
+		}
+		switch string(buffer[0:4]) {
+		case "RPTL":
+			logins++
+		case "RPTK":
+		case "RPTC":
+			if n < protocol.RPTCPacketSize {
+				continue
+			}
+		default:
+			continue
+		}
+		if _, err := serverConn.WriteToUDP(ack, addr); err != nil {
+			t.Fatalf("WriteToUDP error: %v", err)
+		}
+	}
+}
+
+func TestClient_RunRetriesAndRoutes(t *testing.T) {
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("Failed to create mock server: %v", err)
+	}
+	defer func() { _ = serverConn.Close() }()
+
+	// UPSTREAM (this client) and LOCAL share TG 3100
+	router := bridge.NewRouter()
+	router.AddBridge(bridge.NewBridgeRuleSetFromConfig("NATIONWIDE", []config.BridgeRule{
+		{System: "UPSTREAM", TGID: 3100, Timeslot: 1, Active: true},
+		{System: "LOCAL", TGID: 3100, Timeslot: 1, Active: true},
+	}))
+	local := make(chan *protocol.DMRDPacket, 4)
+	router.RegisterSystem("LOCAL", func(p *protocol.DMRDPacket, _ []byte) { local <- p })
+
+	cfg := config.SystemConfig{
+		Mode:       "PEER",
+		MasterIP:   "127.0.0.1",
+		MasterPort: serverConn.LocalAddr().(*net.UDPAddr).Port,
+		Port:       0,
+		RadioID:    312000,
+		Passphrase: "test",
+	}
+	client := NewClient(cfg, logger.New(logger.Config{Level: "error"})).WithRouter(router, "UPSTREAM")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- client.Run(ctx) }()
+
+	read := func() (string, []byte, *net.UDPAddr) {
+		t.Helper()
+		buffer := make([]byte, 1024)
+		if err := serverConn.SetReadDeadline(time.Now().Add(3 * time.Second)); err != nil {
+			t.Fatalf("SetReadDeadline error: %v", err)
+		}
+		n, addr, err := serverConn.ReadFromUDP(buffer)
+		if err != nil {
+			t.Fatalf("ReadFromUDP error: %v", err)
+		}
+		return string(buffer[0:4]), buffer[:n], addr
+	}
+
+	// The first login is refused; the client comes back after a backoff
+	if kind, _, addr := read(); kind != "RPTL" {
+		t.Fatalf("Expected RPTL, got %s", kind)
+	} else if _, err := serverConn.WriteToUDP([]byte("MSTNAK\x00\x04\xc2\xc0"), addr); err != nil {
+		t.Fatalf("WriteToUDP error: %v", err)
+	}
+
+	ack, _ := (&protocol.RPTACKPacket{RepeaterID: 312000, Salt: []byte{0x01, 0x02, 0x03, 0x04}}).Encode()
+	var clientAddr *net.UDPAddr
+	for _, want := range []string{"RPTL", "RPTK", "RPTC"} {
+		kind, _, addr := read()
+		if kind != want {
+			t.Fatalf("Expected %s, got %s", want, kind)
+		}
+		clientAddr = addr
+		if _, err := serverConn.WriteToUDP(ack, addr); err != nil {
+			t.Fatalf("WriteToUDP error: %v", err)
+		}
+	}
+
+	// Traffic from the master is routed to LOCAL
+	down := &protocol.DMRDPacket{SourceID: 3120002, DestinationID: 3100, RepeaterID: 1, Timeslot: 1,
+		FrameType: protocol.FrameTypeVoiceHeader, StreamID: 0x7001, Payload: make([]byte, 33)}
+	data, _ := down.Encode()
+	if _, err := serverConn.WriteToUDP(data, clientAddr); err != nil {
+		t.Fatalf("WriteToUDP error: %v", err)
+	}
+	select {
+	case p := <-local:
+		if p.StreamID != 0x7001 {
+			t.Errorf("LOCAL got stream %#x, want 0x7001", p.StreamID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the master's traffic to reach LOCAL")
+	}
+
+	// Traffic bridged to UPSTREAM goes to the master under the client's ID
+	up := &protocol.DMRDPacket{SourceID: 3120003, DestinationID: 3100, RepeaterID: 312099, Timeslot: 1,
+		FrameType: protocol.FrameTypeVoiceHeader, StreamID: 0x7002, Payload: make([]byte, 33)}
+	data, _ = up.Encode()
+	router.ForwardToSystems([]string{"UPSTREAM"}, up, data)
+	for {
+		kind, frame, _ := read()
+		if kind != "DMRD" {
+			continue // Pings
+		}
+		got := &protocol.DMRDPacket{}
+		if err := got.Parse(frame); err != nil {
+			t.Fatalf("Parse error: %v", err)
+		}
+		if got.StreamID != 0x7002 || got.RepeaterID != 312000 {
+			t.Errorf("Master got stream %#x from repeater %d, want 0x7002 from 312000", got.StreamID, got.RepeaterID)
+		}
+		break
+	}
+
+	// Shutting down closes the session with the master
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Run returned %v, want context.Canceled", err)
+	}
+	for {
+		_, frame, _ := read()
+		if string(frame[:5]) == protocol.PacketTypeRPTCL {
+			break
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package network
+
+import (
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+// withTGColorCode returns the frame with its color code rewritten for the
+// talkgroup's tg_color_codes entry. The frame is copied first since the
+// original may be delivered elsewhere unchanged; frames without a mapping
+// or without a color code (voice sync) come back as they are.
+func (s *Server) withTGColorCode(dmrd *protocol.DMRDPacket, data []byte) []byte {
+	if dmrd.CallType != protocol.CallTypeGroup || len(data) < protocol.DMRDOffsetPayload+33 {
+		return data
+	}
+	cc, ok := s.tgColorCodes[dmrd.DestinationID]
+	if !ok {
+		return data
+	}
+
+	recolored := make([]byte, len(data))
+	copy(recolored, data)
+	burst := *dmrd
+	burst.Payload = recolored[protocol.DMRDOffsetPayload : protocol.DMRDOffsetPayload+33]
+	if !burst.SetColorCode(cc) {
+		return data
+	}
+	return recolored
+}
@@ -0,0 +1,94 @@
+package network
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_TGColorCodeRewrittenOnForward(t *testing.T) {
+	cfg := config.SystemConfig{
+		Mode:         "MASTER",
+		TGColorCodes: []config.TGColorCode{{TGID: 3100, ColorCode: 5}},
+	}
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(cfg, "test-system", log).WithRouter(bridge.NewRouter())
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	listenConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = listenConn.Close() }()
+	listener := srv.peerManager.AddPeer(222, listenConn.LocalAddr().(*net.UDPAddr))
+	listener.SetConnected()
+	listener.Subscriptions.AddDynamic(3100, 1)
+	listener.Subscriptions.AddDynamic(3200, 2)
+
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65021}
+	source := srv.peerManager.AddPeer(111, srcAddr)
+	source.SetConnected()
+	source.Subscriptions.AddDynamic(3100, 1)
+	source.Subscriptions.AddDynamic(3200, 2)
+
+	receive := func(tgid uint32, timeslot int, frameType, dataType byte, streamID uint32) *protocol.DMRDPacket {
+		t.Helper()
+		dmrd := &protocol.DMRDPacket{
+			SourceID:      3120001,
+			DestinationID: tgid,
+			RepeaterID:    111,
+			Timeslot:      timeslot,
+			CallType:      protocol.CallTypeGroup,
+			FrameType:     frameType,
+			DataType:      dataType,
+			StreamID:      streamID,
+			Payload:       bytes.Repeat([]byte{0x5a}, 33),
+		}
+		dmrd.SetColorCode(1)
+		data, err := dmrd.Encode()
+		if err != nil {
+			t.Fatalf("Encode DMRD error: %v", err)
+		}
+		srv.handleDMRD(data, srcAddr)
+
+		buf := make([]byte, 512)
+		_ = listenConn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		n, _, err := listenConn.ReadFromUDP(buf)
+		if err != nil {
+			t.Fatalf("Expected a forwarded frame on TG %d: %v", tgid, err)
+		}
+		got, err := protocol.ParseDMRD(buf[:n])
+		if err != nil {
+			t.Fatalf("ParseDMRD error: %v", err)
+		}
+		return got
+	}
+
+	// Mapped talkgroup: LC header slot type and voice burst EMB both rewritten
+	got := receive(3100, 1, protocol.FrameTypeVoiceTerminator, protocol.DataTypeVoiceLCHeader, 5001)
+	if cc, ok := got.ColorCode(); !ok || cc != 5 {
+		t.Errorf("LC header color code = %d, want 5", cc)
+	}
+	got = receive(3100, 1, protocol.FrameTypeVoice, 1, 5001)
+	if cc, ok := got.ColorCode(); !ok || cc != 5 {
+		t.Errorf("Voice burst color code = %d, want 5", cc)
+	}
+
+	// Unmapped talkgroup keeps the source's color code
+	got = receive(3200, 2, protocol.FrameTypeVoiceTerminator, protocol.DataTypeVoiceLCHeader, 5002)
+	if cc, ok := got.ColorCode(); !ok || cc != 1 {
+		t.Errorf("Unmapped TG color code = %d, want 1", cc)
+	}
+}
@@ -0,0 +1,47 @@
+package network
+
+import (
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/peer"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+// drainPeers runs on controlled shutdown, before the socket is closed: each
+// peer connected to this system is sent MSTCL so it reconnects (to a backup
+// master, if it has one) at once instead of waiting out its ping timeout.
+// With a backup master configured each peer is first sent its address
+// (MSTRDR); send_close_on_shutdown off then leaves out the MSTCL.
+func (s *Server) drainPeers() {
+	redirect := s.config.BackupMaster != ""
+	if s.getConn() == nil || (!redirect && !s.config.SendCloseOnShutdown) {
+		return
+	}
+
+	drained := 0
+	for _, p := range s.peerManager.GetAllPeers() {
+		if p.GetState() != peer.StateConnected || !s.ownsPeer(p) {
+			continue
+		}
+
+		if redirect {
+			rdr := &protocol.MSTRDRPacket{RepeaterID: p.ID, Address: s.config.BackupMaster}
+			if data, err := rdr.Encode(); err == nil {
+				if _, err := s.connFor(p.Address).WriteToUDP(data, p.Address); err != nil {
+					s.log.Debug("Failed to send MSTRDR", logger.Error(err))
+				}
+			}
+		}
+		if s.config.SendCloseOnShutdown {
+			s.sendMSTCL(p.ID, p.Address)
+		}
+		drained++
+	}
+
+	if redirect {
+		s.log.Info("Redirected peers to backup master",
+			logger.String("backup_master", s.config.BackupMaster),
+			logger.Int("peers", drained))
+		return
+	}
+	s.log.Info("Closed peer connections for shutdown", logger.Int("peers", drained))
+}
@@ -0,0 +1,220 @@
+package network
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/peer"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_ShutdownRedirectsPeersToBackupMaster(t *testing.T) {
+	cfg := config.SystemConfig{
+		Mode:                "MASTER",
+		Port:                0,
+		Passphrase:          "test",
+		BackupMaster:        "backup.example.net:62031",
+		SendCloseOnShutdown: true,
+	}
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(cfg, "test-system", log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- srv.Start(ctx)
+	}()
+	if err := srv.WaitStarted(ctx); err != nil {
+		t.Fatalf("server failed to start: %v", err)
+	}
+
+	peerConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = peerConn.Close() }()
+	p := srv.peerManager.AddPeer(312000, peerConn.LocalAddr().(*net.UDPAddr))
+	p.SetSystem("test-system")
+	p.SetConnected()
+
+	cancel()
+	if err := <-errChan; err != nil && err != context.Canceled {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	buf := make([]byte, 512)
+	_ = peerConn.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+	n, _, err := peerConn.ReadFromUDP(buf)
+	if err != nil {
+		t.Fatalf("Expected MSTRDR on shutdown: %v", err)
+	}
+	rdr, err := protocol.ParseMSTRDR(buf[:n])
+	if err != nil {
+		t.Fatalf("ParseMSTRDR error: %v", err)
+	}
+	if rdr.RepeaterID != 312000 || rdr.Address != "backup.example.net:62031" {
+		t.Errorf("Unexpected redirect: %+v", rdr)
+	}
+
+	_ = peerConn.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+	n, _, err = peerConn.ReadFromUDP(buf)
+	if err != nil {
+		t.Fatalf("Expected MSTCL after the redirect: %v", err)
+	}
+	if _, err := protocol.ParseMSTCL(buf[:n]); err != nil {
+		t.Errorf("ParseMSTCL error: %v", err)
+	}
+}
+
+func TestServer_ShutdownClosesPeers(t *testing.T) {
+	for _, sendClose := range []bool{true, false} {
+		cfg := config.SystemConfig{
+			Mode:                "MASTER",
+			Port:                0,
+			Passphrase:          "test",
+			SendCloseOnShutdown: sendClose,
+		}
+		srv := NewServer(cfg, "test-system", logger.New(logger.Config{Level: "error"}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		errChan := make(chan error, 1)
+		go func() {
+			errChan <- srv.Start(ctx)
+		}()
+		if err := srv.WaitStarted(ctx); err != nil {
+			cancel()
+			t.Fatalf("server failed to start: %v", err)
+		}
+
+		peerConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+		if err != nil {
+			cancel()
+			t.Fatalf("ListenUDP error: %v", err)
+		}
+		p := srv.peerManager.AddPeer(312000, peerConn.LocalAddr().(*net.UDPAddr))
+		p.SetSystem("test-system")
+		p.SetConnected()
+		// Peers still logging in have no connection to close
+		srv.peerManager.AddPeer(312001, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65051}).SetSystem("test-system")
+
+		cancel()
+		if err := <-errChan; err != nil && err != context.Canceled {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		buf := make([]byte, 512)
+		_ = peerConn.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+		n, _, err := peerConn.ReadFromUDP(buf)
+		_ = peerConn.Close()
+		if !sendClose {
+			if err == nil {
+				t.Errorf("Expected nothing on shutdown with send_close_on_shutdown off, got %q", buf[:n])
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Expected MSTCL on shutdown: %v", err)
+		}
+		cl, err := protocol.ParseMSTCL(buf[:n])
+		if err != nil {
+			t.Fatalf("ParseMSTCL error: %v", err)
+		}
+		if cl.RepeaterID != 312000 {
+			t.Errorf("Expected MSTCL for peer 312000, got %d", cl.RepeaterID)
+		}
+	}
+}
+
+func TestServer_ShutdownRedirectWithoutClose(t *testing.T) {
+	cfg := config.SystemConfig{
+		Mode:         "MASTER",
+		Port:         0,
+		Passphrase:   "test",
+		BackupMaster: "backup.example.net:62031",
+	}
+	srv := NewServer(cfg, "test-system", logger.New(logger.Config{Level: "error"}))
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	srv.conn = conn
+
+	peerConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = peerConn.Close() }()
+	p := srv.peerManager.AddPeer(312000, peerConn.LocalAddr().(*net.UDPAddr))
+	p.SetSystem("test-system")
+	p.SetConnected()
+
+	srv.drainPeers()
+
+	// send_close_on_shutdown off: the redirect only
+	buf := make([]byte, 512)
+	_ = peerConn.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+	n, _, err := peerConn.ReadFromUDP(buf)
+	if err != nil {
+		t.Fatalf("Expected MSTRDR on shutdown: %v", err)
+	}
+	if _, err := protocol.ParseMSTRDR(buf[:n]); err != nil {
+		t.Fatalf("ParseMSTRDR error: %v", err)
+	}
+	_ = peerConn.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+	if n, _, err := peerConn.ReadFromUDP(buf); err == nil {
+		t.Errorf("Expected no MSTCL with send_close_on_shutdown off, got %q", buf[:n])
+	}
+}
+
+func TestServer_ShutdownDrainsOnlyOwnPeers(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	cfg := config.SystemConfig{Mode: "MASTER", Port: 0, Passphrase: "test", SendCloseOnShutdown: true}
+	shared := peer.NewPeerManager()
+	srvA := NewServer(cfg, "MASTER-A", log).WithPeerManager(shared)
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	srvA.conn = conn
+
+	peerConns := make(map[string]*net.UDPConn)
+	for i, system := range []string{"MASTER-A", "MASTER-B"} {
+		peerConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+		if err != nil {
+			t.Fatalf("ListenUDP error: %v", err)
+		}
+		defer func() { _ = peerConn.Close() }()
+		peerConns[system] = peerConn
+		p := shared.AddPeer(uint32(312000+i), peerConn.LocalAddr().(*net.UDPAddr))
+		p.SetSystem(system)
+		p.SetConnected()
+	}
+
+	srvA.drainPeers()
+
+	buf := make([]byte, 512)
+	_ = peerConns["MASTER-A"].SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+	n, _, err := peerConns["MASTER-A"].ReadFromUDP(buf)
+	if err != nil {
+		t.Fatalf("Expected MSTCL for MASTER-A's peer: %v", err)
+	}
+	if cl, err := protocol.ParseMSTCL(buf[:n]); err != nil || cl.RepeaterID != 312000 {
+		t.Errorf("Expected MSTCL for peer 312000, got %v (err %v)", cl, err)
+	}
+
+	// The peer logged in to MASTER-B stays connected there
+	_ = peerConns["MASTER-B"].SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+	if n, _, err := peerConns["MASTER-B"].ReadFromUDP(buf); err == nil {
+		t.Errorf("Expected nothing for MASTER-B's peer, got %q", buf[:n])
+	}
+}
@@ -0,0 +1,95 @@
+package network
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/peer"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+const (
+	// echoMaxFrames caps a recording at about a minute of voice
+	echoMaxFrames = 1000
+	// echoReplayDelay gives the radio time to drop back to receive
+	echoReplayDelay = time.Second
+)
+
+// echoRecording is a transmission on the echo talkgroup being recorded
+type echoRecording struct {
+	frames []protocol.DMRDPacket
+	last   time.Time
+}
+
+// handleEcho reports whether a frame was taken by the echo talkgroup. Frames
+// are recorded per stream and, on the terminator, played back to the
+// transmitting peer only; they are never routed or subscribed to.
+func (s *Server) handleEcho(dmrd *protocol.DMRDPacket, p *peer.Peer) bool {
+	if s.config.EchoTG <= 0 || dmrd.CallType != protocol.CallTypeGroup || dmrd.DestinationID != uint32(s.config.EchoTG) {
+		return false
+	}
+
+	s.echoMu.Lock()
+	rec, ok := s.echoes[dmrd.StreamID]
+	if !ok {
+		rec = &echoRecording{}
+		s.echoes[dmrd.StreamID] = rec
+	}
+	rec.last = time.Now()
+	if len(rec.frames) < echoMaxFrames {
+		rec.frames = append(rec.frames, *dmrd)
+	}
+	if !dmrd.IsTerminator() {
+		s.echoMu.Unlock()
+		return true
+	}
+	delete(s.echoes, dmrd.StreamID)
+	s.echoMu.Unlock()
+
+	s.log.Info("Playing back echo test",
+		logger.Int("peer_id", int(p.ID)),
+		logger.Int("src_id", int(dmrd.SourceID)),
+		logger.Int("frames", len(rec.frames)))
+
+	go s.playEcho(p, rec.frames)
+	return true
+}
+
+// playEcho replays a recording to the peer that made it, on a fresh stream
+func (s *Server) playEcho(p *peer.Peer, frames []protocol.DMRDPacket) {
+	if s.echoDelay > 0 {
+		time.Sleep(s.echoDelay)
+	}
+
+	var id [4]byte
+	_, _ = rand.Read(id[:])
+	streamID := binary.BigEndian.Uint32(id[:])
+
+	for i, f := range frames {
+		f.StreamID = streamID
+		f.Sequence = byte(i)
+		f.HMAC = nil
+		data, err := f.Encode()
+		if err != nil {
+			s.log.Error("Failed to encode echo frame", logger.Error(err))
+			return
+		}
+		s.sendToPeer(p, data)
+		if s.announceInterval > 0 {
+			time.Sleep(s.announceInterval)
+		}
+	}
+}
+
+// cleanupEchoes drops recordings whose terminator never arrived
+func (s *Server) cleanupEchoes(now time.Time) {
+	s.echoMu.Lock()
+	defer s.echoMu.Unlock()
+	for streamID, rec := range s.echoes {
+		if now.Sub(rec.last) > s.muteWindow {
+			delete(s.echoes, streamID)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package network
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_EchoPlaysBackToSourceOnly(t *testing.T) {
+	cfg := config.SystemConfig{Mode: "MASTER", EchoTG: 9990}
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(cfg, "test-system", log).WithRouter(bridge.NewRouter())
+	srv.echoDelay = 0
+	srv.announceInterval = 0
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	sourceConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = sourceConn.Close() }()
+	sourceAddr := sourceConn.LocalAddr().(*net.UDPAddr)
+	source := srv.peerManager.AddPeer(111, sourceAddr)
+	source.SetConnected()
+
+	// Another peer listening on the echo talkgroup must hear nothing
+	listenConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = listenConn.Close() }()
+	listener := srv.peerManager.AddPeer(222, listenConn.LocalAddr().(*net.UDPAddr))
+	listener.SetConnected()
+	listener.Subscriptions.AddDynamic(9990, 2)
+
+	frameTypes := []byte{protocol.FrameTypeVoiceHeader, protocol.FrameTypeVoice, protocol.FrameTypeVoice, protocol.FrameTypeVoiceTerminator}
+	for i, ft := range frameTypes {
+		dmrd := &protocol.DMRDPacket{
+			Sequence:      byte(i),
+			SourceID:      3120001,
+			DestinationID: 9990,
+			RepeaterID:    111,
+			Timeslot:      2,
+			CallType:      protocol.CallTypeGroup,
+			FrameType:     ft,
+			StreamID:      700,
+			Payload:       bytes.Repeat([]byte{byte(0x10 + i)}, 33),
+		}
+		if ft == protocol.FrameTypeVoiceTerminator {
+			dmrd.DataType = protocol.DataTypeTerminatorLC
+		}
+		data, err := dmrd.Encode()
+		if err != nil {
+			t.Fatalf("Encode DMRD error: %v", err)
+		}
+		srv.handleDMRD(data, sourceAddr)
+	}
+
+	read := func(conn *net.UDPConn) []*protocol.DMRDPacket {
+		var got []*protocol.DMRDPacket
+		buf := make([]byte, 2048)
+		for {
+			if err := conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond)); err != nil {
+				t.Fatalf("SetReadDeadline error: %v", err)
+			}
+			n, _, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return got
+			}
+			if pkt, err := protocol.ParseDMRD(buf[:n]); err == nil {
+				got = append(got, pkt)
+			}
+		}
+	}
+
+	echoed := read(sourceConn)
+	if len(echoed) != len(frameTypes) {
+		t.Fatalf("Expected %d echoed frames, got %d", len(frameTypes), len(echoed))
+	}
+	for i, f := range echoed {
+		if f.FrameType != frameTypes[i] || f.Payload[0] != byte(0x10+i) {
+			t.Errorf("Echo frame %d: type %d payload %#x", i, f.FrameType, f.Payload[0])
+		}
+		if f.StreamID == 700 || f.StreamID != echoed[0].StreamID {
+			t.Errorf("Echo frame %d: expected one fresh stream, got %d", i, f.StreamID)
+		}
+		if f.DestinationID != 9990 || f.Timeslot != 2 {
+			t.Errorf("Echo frame %d: dst %d ts %d", i, f.DestinationID, f.Timeslot)
+		}
+	}
+
+	if got := read(listenConn); len(got) != 0 {
+		t.Errorf("Expected no echo traffic to other peers, got %d frames", len(got))
+	}
+	if source.Subscriptions.HasTalkgroup(9990, 2) {
+		t.Error("Keying the echo talkgroup should not subscribe the peer")
+	}
+}
@@ -0,0 +1,77 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+// firstHeard remembers the day each radio ID was last greeted, so a
+// first-heard-today event fires once per ID per (local) day
+type firstHeard struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	greeted map[uint32]string // radio ID -> date last greeted, "2006-01-02"
+}
+
+func newFirstHeard() *firstHeard {
+	return &firstHeard{
+		now:     time.Now,
+		greeted: make(map[uint32]string),
+	}
+}
+
+// check reports whether radioID has not yet been heard today, and marks it
+// greeted if so
+func (f *firstHeard) check(radioID uint32) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	today := f.now().Format("2006-01-02")
+	if f.greeted[radioID] == today {
+		return false
+	}
+	f.greeted[radioID] = today
+	return true
+}
+
+// cleanup forgets IDs greeted before today
+func (f *firstHeard) cleanup() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	today := f.now().Format("2006-01-02")
+	for radioID, day := range f.greeted {
+		if day != today {
+			delete(f.greeted, radioID)
+		}
+	}
+}
+
+// SetFirstHeardHandler sets the callback run the first time each radio ID
+// starts a transmission on a given day, when first_heard_greeting is on
+func (s *Server) SetFirstHeardHandler(fn func(radioID, dst, peerID uint32)) {
+	s.onFirstHeard = fn
+}
+
+// SetHeardHandler sets the callback run once at the start of every
+// transmission
+func (s *Server) SetHeardHandler(fn func(radioID, dst, peerID uint32)) {
+	s.onHeard = fn
+}
+
+// noteFirstHeard fires the heard handler for a stream that just started, and
+// the first-heard handler if it is the radio ID's first transmission of the
+// day
+func (s *Server) noteFirstHeard(dmrd *protocol.DMRDPacket, peerID uint32) {
+	if s.onHeard != nil {
+		s.onHeard(dmrd.SourceID, dmrd.DestinationID, peerID)
+	}
+	if s.firstHeard == nil {
+		return
+	}
+	if s.firstHeard.check(dmrd.SourceID) && s.onFirstHeard != nil {
+		s.onFirstHeard(dmrd.SourceID, dmrd.DestinationID, peerID)
+	}
+}
@@ -0,0 +1,93 @@
+package network
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_FirstHeardOncePerDay(t *testing.T) {
+	cfg := config.SystemConfig{Mode: "MASTER", FirstHeardGreeting: true}
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(cfg, "test-system", log).WithRouter(bridge.NewRouter())
+
+	clock := time.Date(2024, 5, 1, 9, 0, 0, 0, time.Local)
+	srv.firstHeard.now = func() time.Time { return clock }
+
+	var greeted []uint32
+	srv.SetFirstHeardHandler(func(radioID, dst, peerID uint32) {
+		greeted = append(greeted, radioID)
+	})
+	heard := 0
+	srv.SetHeardHandler(func(radioID, dst, peerID uint32) {
+		heard++
+	})
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65021}
+	source := srv.peerManager.AddPeer(111, srcAddr)
+	source.SetConnected()
+	source.Subscriptions.AddDynamic(3100, 1)
+
+	streamID := uint32(0)
+	transmit := func(src uint32) {
+		streamID++
+		// Voice sync repeats every superframe; heard fires once per stream
+		for _, ft := range []byte{protocol.FrameTypeVoiceHeader, protocol.FrameTypeVoice, protocol.FrameTypeVoiceHeader, protocol.FrameTypeVoice, protocol.FrameTypeVoiceTerminator} {
+			dmrd := &protocol.DMRDPacket{
+				SourceID:      src,
+				DestinationID: 3100,
+				RepeaterID:    111,
+				Timeslot:      1,
+				CallType:      protocol.CallTypeGroup,
+				FrameType:     ft,
+				StreamID:      streamID,
+				Payload:       make([]byte, 33),
+			}
+			if ft == protocol.FrameTypeVoiceTerminator {
+				dmrd.DataType = protocol.DataTypeTerminatorLC
+			}
+			data, err := dmrd.Encode()
+			if err != nil {
+				t.Fatalf("Encode DMRD error: %v", err)
+			}
+			srv.handleDMRD(data, srcAddr)
+		}
+	}
+
+	transmit(3120001)
+	transmit(3120001)
+	transmit(3120002)
+	if len(greeted) != 2 || greeted[0] != 3120001 || greeted[1] != 3120002 {
+		t.Fatalf("Expected one greeting per radio ID, got %v", greeted)
+	}
+
+	// Later the same day: no repeat
+	clock = clock.Add(14 * time.Hour)
+	transmit(3120001)
+	if len(greeted) != 2 {
+		t.Fatalf("Expected no second greeting the same day, got %v", greeted)
+	}
+
+	// Next day: greeted again
+	clock = clock.Add(2 * time.Hour)
+	srv.firstHeard.cleanup()
+	transmit(3120001)
+	if len(greeted) != 3 || greeted[2] != 3120001 {
+		t.Errorf("Expected a new greeting the next day, got %v", greeted)
+	}
+	if heard != 5 {
+		t.Errorf("Expected the heard handler on every transmission, got %d", heard)
+	}
+}
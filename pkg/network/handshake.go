@@ -0,0 +1,20 @@
+package network
+
+import "sync"
+
+// handshakeLockStripes is the number of mutexes handshake packets are
+// serialized on; packets for one repeater ID always share a stripe
+const handshakeLockStripes = 64
+
+// handshakeLocks serializes RPTL/RPTK/RPTC/RPTO/RPTCL handling per repeater
+// ID. Packets are handled on their own goroutines, so without it a
+// retransmitted RPTL can reset a peer halfway through its key exchange.
+type handshakeLocks [handshakeLockStripes]sync.Mutex
+
+// lockHandshake locks the handshake stripe for peerID and returns the unlock
+// function
+func (s *Server) lockHandshake(peerID uint32) func() {
+	mu := &s.handshakeLocks[peerID%handshakeLockStripes]
+	mu.Lock()
+	return mu.Unlock
+}
@@ -0,0 +1,168 @@
+package network
+
+import (
+	"context"
+	"crypto/sha256"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/metrics"
+	"github.com/dbehnke/dmr-nexus/pkg/peer"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func encodeHandshake(t *testing.T, peerID uint32) (rptl, rptk []byte) {
+	t.Helper()
+	rptl, err := (&protocol.RPTLPacket{RepeaterID: peerID}).Encode()
+	if err != nil {
+		t.Fatalf("Encode RPTL error: %v", err)
+	}
+	rptk, err = (&protocol.RPTKPacket{RepeaterID: peerID, Challenge: make([]byte, 32)}).Encode()
+	if err != nil {
+		t.Fatalf("Encode RPTK error: %v", err)
+	}
+	return rptl, rptk
+}
+
+func TestServer_RPTKImmediatelyAfterRPTL(t *testing.T) {
+	cfg := config.SystemConfig{Mode: "MASTER", Port: 0, Passphrase: "test"}
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(cfg, "test-system", log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() { _ = srv.Start(ctx) }()
+	if err := srv.WaitStarted(ctx); err != nil {
+		t.Fatalf("server failed to start: %v", err)
+	}
+
+	serverAddr, err := srv.Addr()
+	if err != nil {
+		t.Fatalf("Addr error: %v", err)
+	}
+
+	for i := uint32(0); i < 20; i++ {
+		peerID := 312100 + i
+		conn, err := net.DialUDP("udp", nil, serverAddr)
+		if err != nil {
+			t.Fatalf("DialUDP error: %v", err)
+		}
+
+		rptl, _ := encodeHandshake(t, peerID)
+		if _, err := conn.Write(rptl); err != nil {
+			t.Fatalf("Write RPTL error: %v", err)
+		}
+
+		// Answer the salt the moment it arrives
+		buf := make([]byte, 512)
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatalf("peer %d: expected RPTACK with salt: %v", peerID, err)
+		}
+		challenge, err := answerChallenge(buf[:n], cfg.Passphrase)
+		if err != nil {
+			t.Fatalf("peer %d: answerChallenge error: %v", peerID, err)
+		}
+		rptk, err := (&protocol.RPTKPacket{RepeaterID: peerID, Challenge: challenge}).Encode()
+		if err != nil {
+			t.Fatalf("Encode RPTK error: %v", err)
+		}
+		if _, err := conn.Write(rptk); err != nil {
+			t.Fatalf("Write RPTK error: %v", err)
+		}
+
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, err = conn.Read(buf)
+		if err != nil {
+			t.Fatalf("peer %d: expected RPTACK for the key: %v", peerID, err)
+		}
+		if !strings.HasPrefix(string(buf[:n]), protocol.PacketTypeRPTACK) {
+			t.Fatalf("peer %d: expected RPTACK, got %q", peerID, buf[:4])
+		}
+		_ = conn.Close()
+
+		p := srv.peerManager.GetPeer(peerID)
+		if p == nil || p.GetState() != peer.StateAuthenticated {
+			t.Fatalf("peer %d: handshake did not complete", peerID)
+		}
+	}
+}
+
+func TestServer_RPTKAheadOfRPTLIsNAKed(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(config.SystemConfig{Mode: "MASTER"}, "test-system", log)
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	peerConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = peerConn.Close() }()
+
+	_, rptk := encodeHandshake(t, 312300)
+	srv.handlePacket(rptk, peerConn.LocalAddr().(*net.UDPAddr))
+	if srv.peerManager.GetPeer(312300) != nil {
+		t.Fatal("RPTK must not register a peer")
+	}
+
+	buf := make([]byte, 512)
+	_ = peerConn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	n, _, err := peerConn.ReadFromUDP(buf)
+	if err != nil {
+		t.Fatalf("Expected an immediate MSTNAK: %v", err)
+	}
+	if !strings.HasPrefix(string(buf[:n]), protocol.PacketTypeMSTNAK) {
+		t.Fatalf("Expected MSTNAK, got %q", buf[:n])
+	}
+}
+
+func TestServer_HandshakeFunnel(t *testing.T) {
+	collector := metrics.NewCollector()
+	cfg := config.SystemConfig{Mode: "MASTER", Passphrase: "test"}
+	srv := NewServer(cfg, "test-system", logger.New(logger.Config{Level: "error"})).WithMetrics(collector)
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	// One peer stalls after logging in, another completes the handshake
+	stalled := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65041}
+	rptl, _ := encodeHandshake(t, 312201)
+	srv.handleRPTL(rptl, stalled)
+
+	full := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65042}
+	rptl, _ = encodeHandshake(t, 312202)
+	srv.handleRPTL(rptl, full)
+	h := sha256.New()
+	h.Write(srv.peerManager.GetPeer(312202).Salt)
+	h.Write([]byte(cfg.Passphrase))
+	rptk, err := (&protocol.RPTKPacket{RepeaterID: 312202, Challenge: h.Sum(nil)}).Encode()
+	if err != nil {
+		t.Fatalf("Encode RPTK error: %v", err)
+	}
+	rptc, err := (&protocol.RPTCPacket{RepeaterID: 312202, Callsign: "W1ABC"}).Encode()
+	if err != nil {
+		t.Fatalf("Encode RPTC error: %v", err)
+	}
+	srv.handleRPTK(rptk, full)
+	srv.handleRPTC(rptc, full)
+
+	want := metrics.HandshakeFunnel{Login: 2, Key: 1, Config: 1, Connected: 1}
+	if got := collector.GetHandshakeFunnels()["test-system"]; got != want {
+		t.Errorf("Expected funnel %+v, got %+v", want, got)
+	}
+}
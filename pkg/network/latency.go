@@ -0,0 +1,54 @@
+package network
+
+import (
+	"net"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+)
+
+// latencyWarnInterval spaces out latency alarm warnings, so an overloaded
+// server isn't also flooding its log. Every slow packet is still counted.
+const latencyWarnInterval = 10 * time.Second
+
+// handleReceived handles a packet, then raises the latency alarm if the
+// time since it was received (queueing included) exceeds
+// max_packet_latency_ms
+func (s *Server) handleReceived(data []byte, addr *net.UDPAddr, received time.Time) {
+	s.handlePacket(data, addr)
+	if s.config.MaxPacketLatencyMs > 0 {
+		s.checkPacketLatency(data, addr, time.Since(received))
+	}
+}
+
+// checkPacketLatency counts and logs a packet that took too long
+func (s *Server) checkPacketLatency(data []byte, addr *net.UDPAddr, latency time.Duration) {
+	threshold := time.Duration(s.config.MaxPacketLatencyMs) * time.Millisecond
+	if latency <= threshold {
+		return
+	}
+	if s.metrics != nil {
+		s.metrics.SlowPacket()
+	}
+
+	now := time.Now()
+	s.latencyWarnMu.Lock()
+	warn := now.Sub(s.lastLatencyWarn) >= latencyWarnInterval
+	if warn {
+		s.lastLatencyWarn = now
+	}
+	s.latencyWarnMu.Unlock()
+	if !warn {
+		return
+	}
+
+	kind := ""
+	if len(data) >= 4 {
+		kind = string(data[:4])
+	}
+	s.log.Warn("Packet handling exceeded latency threshold, server may be overloaded",
+		logger.String("latency", latency.String()),
+		logger.String("threshold", threshold.String()),
+		logger.String("type", kind),
+		logger.String("addr", addr.String()))
+}
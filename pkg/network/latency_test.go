@@ -0,0 +1,57 @@
+package network
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/metrics"
+)
+
+func TestServer_PacketLatencyAlarm(t *testing.T) {
+	var logs bytes.Buffer
+	collector := metrics.NewCollector()
+	cfg := config.SystemConfig{Mode: "MASTER", MaxPacketLatencyMs: 20}
+	srv := NewServer(cfg, "test-system", logger.New(logger.Config{Level: "warn", Output: &logs})).
+		WithMetrics(collector)
+
+	addr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65041}
+	junk := []byte("JUNKPACKET")
+
+	// Handled as soon as it arrived
+	srv.handleReceived(junk, addr, time.Now())
+	if got := collector.GetSlowPackets(); got != 0 {
+		t.Fatalf("Expected no slow packets, got %d", got)
+	}
+
+	// Held up 50ms before handling, e.g. behind a GC pause
+	srv.handleReceived(junk, addr, time.Now().Add(-50*time.Millisecond))
+	if got := collector.GetSlowPackets(); got != 1 {
+		t.Errorf("Expected 1 slow packet, got %d", got)
+	}
+	if !strings.Contains(logs.String(), "exceeded latency threshold") {
+		t.Errorf("Expected a latency warning, got log %q", logs.String())
+	}
+
+	// Further slow packets are counted but not logged again right away
+	logs.Reset()
+	srv.handleReceived(junk, addr, time.Now().Add(-50*time.Millisecond))
+	if got := collector.GetSlowPackets(); got != 2 {
+		t.Errorf("Expected 2 slow packets, got %d", got)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("Expected the repeat warning to be held back, got %q", logs.String())
+	}
+
+	// Disabled by default
+	off := NewServer(config.SystemConfig{Mode: "MASTER"}, "test-system", logger.New(logger.Config{Level: "error"})).
+		WithMetrics(collector)
+	off.handleReceived(junk, addr, time.Now().Add(-time.Second))
+	if got := collector.GetSlowPackets(); got != 2 {
+		t.Errorf("Expected the alarm to be off without max_packet_latency_ms, got %d", got)
+	}
+}
@@ -0,0 +1,89 @@
+package network
+
+import (
+	"sync"
+	"time"
+)
+
+// locationShards is how many independently locked parts the subscriber
+// location map is split into. Every DMRD updates it, so with thousands of
+// active radios a single lock would serialize packet handling.
+const locationShards = 32
+
+// subscriberLocation tracks where a subscriber (radio) was last seen
+type subscriberLocation struct {
+	peerID   uint32    // Which peer the subscriber is behind
+	lastSeen time.Time // When we last saw traffic from this subscriber
+}
+
+// locationShard is one part of the subscriber location map
+type locationShard struct {
+	mu        sync.RWMutex
+	locations map[uint32]subscriberLocation
+}
+
+// subscriberLocations maps radio IDs to the peer each was last heard
+// behind, sharded by radio ID. Radio IDs are handed out in blocks, so the
+// low bits spread them evenly.
+type subscriberLocations struct {
+	shards [locationShards]locationShard
+}
+
+func newSubscriberLocations() *subscriberLocations {
+	l := &subscriberLocations{}
+	for i := range l.shards {
+		l.shards[i].locations = make(map[uint32]subscriberLocation)
+	}
+	return l
+}
+
+func (l *subscriberLocations) shard(radioID uint32) *locationShard {
+	return &l.shards[radioID%locationShards]
+}
+
+// set records that radioID was heard behind peerID at seen
+func (l *subscriberLocations) set(radioID, peerID uint32, seen time.Time) {
+	sh := l.shard(radioID)
+	sh.mu.Lock()
+	sh.locations[radioID] = subscriberLocation{peerID: peerID, lastSeen: seen}
+	sh.mu.Unlock()
+}
+
+// get returns where radioID was last heard
+func (l *subscriberLocations) get(radioID uint32) (subscriberLocation, bool) {
+	sh := l.shard(radioID)
+	sh.mu.RLock()
+	loc, ok := sh.locations[radioID]
+	sh.mu.RUnlock()
+	return loc, ok
+}
+
+// removeIf deletes the locations matching fn, one shard at a time, and
+// returns how many were deleted
+func (l *subscriberLocations) removeIf(fn func(subscriberLocation) bool) int {
+	removed := 0
+	for i := range l.shards {
+		sh := &l.shards[i]
+		sh.mu.Lock()
+		for radioID, loc := range sh.locations {
+			if fn(loc) {
+				delete(sh.locations, radioID)
+				removed++
+			}
+		}
+		sh.mu.Unlock()
+	}
+	return removed
+}
+
+// len returns how many radios have a known location
+func (l *subscriberLocations) len() int {
+	n := 0
+	for i := range l.shards {
+		sh := &l.shards[i]
+		sh.mu.RLock()
+		n += len(sh.locations)
+		sh.mu.RUnlock()
+	}
+	return n
+}
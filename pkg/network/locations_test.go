@@ -0,0 +1,96 @@
+package network
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSubscriberLocations_Shards(t *testing.T) {
+	locs := newSubscriberLocations()
+	now := time.Now()
+
+	// Consecutive radio IDs land in every shard
+	for i := uint32(0); i < 4*locationShards; i++ {
+		seen := now
+		if i%2 == 1 {
+			seen = now.Add(-20 * time.Minute)
+		}
+		locs.set(3120000+i, 312000+i%3, seen)
+	}
+	for i := range locs.shards {
+		if len(locs.shards[i].locations) != 4 {
+			t.Fatalf("Shard %d holds %d radios, want 4", i, len(locs.shards[i].locations))
+		}
+	}
+
+	if loc, ok := locs.get(3120005); !ok || loc.peerID != 312002 {
+		t.Errorf("get(3120005) = %+v, %v; want peer 312002", loc, ok)
+	}
+	locs.set(3120005, 312009, now)
+	if loc, _ := locs.get(3120005); loc.peerID != 312009 {
+		t.Errorf("Expected a radio heard again to move to peer 312009, got %d", loc.peerID)
+	}
+	if _, ok := locs.get(3129999); ok {
+		t.Error("Expected no location for an unheard radio")
+	}
+
+	// Cleanup reaches every shard
+	srv := &Server{subscriberLocations: locs}
+	srv.cleanupStaleSubscriberLocations(15 * time.Minute)
+	if n := locs.len(); n != 2*locationShards+1 {
+		t.Errorf("Expected %d fresh locations after cleanup, got %d", 2*locationShards+1, n)
+	}
+	if _, ok := locs.get(3120001); ok {
+		t.Error("Expected stale radio 3120001 cleaned up")
+	}
+
+	srv.clearSubscriberLocationsForPeer(312000)
+	if removed := locs.removeIf(func(loc subscriberLocation) bool { return loc.peerID == 312000 }); removed != 0 {
+		t.Errorf("Expected no locations left behind peer 312000, found %d", removed)
+	}
+	if _, ok := locs.get(3120002); !ok {
+		t.Error("Expected radios behind other peers to stay")
+	}
+}
+
+func TestSubscriberLocations_Concurrent(t *testing.T) {
+	locs := newSubscriberLocations()
+	var wg sync.WaitGroup
+	for w := uint32(0); w < 8; w++ {
+		wg.Add(1)
+		go func(w uint32) {
+			defer wg.Done()
+			for i := uint32(0); i < 1000; i++ {
+				radioID := 3100000 + w*1000 + i
+				locs.set(radioID, w, time.Now())
+				if loc, ok := locs.get(radioID); !ok || loc.peerID != w {
+					t.Errorf("Lost location of radio %d", radioID)
+					return
+				}
+				if i%100 == 0 {
+					locs.removeIf(func(loc subscriberLocation) bool { return time.Since(loc.lastSeen) > time.Hour })
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	if n := locs.len(); n != 8000 {
+		t.Errorf("Expected 8000 locations, got %d", n)
+	}
+}
+
+// BenchmarkSubscriberLocations measures concurrent tracking and lookups
+// across many radios, as every DMRD does
+func BenchmarkSubscriberLocations(b *testing.B) {
+	locs := newSubscriberLocations()
+	b.RunParallel(func(pb *testing.PB) {
+		now := time.Now()
+		radioID := uint32(3100000)
+		for pb.Next() {
+			radioID = 3100000 + (radioID*7919+1)%5000
+			locs.set(radioID, radioID%50, now)
+			locs.get(radioID ^ 1)
+		}
+	})
+}
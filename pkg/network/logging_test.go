@@ -0,0 +1,43 @@
+package network
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_LogLinesCarrySystemName(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(logger.Config{Level: "debug", Output: &buf})
+	srv := NewServer(config.SystemConfig{Mode: "MASTER", Passphrase: "test"}, "SYS-A", log.WithComponent("network.SYS-A"))
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	addr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65017}
+	rptl, _ := (&protocol.RPTLPacket{RepeaterID: 312000}).Encode()
+	srv.handlePacket(rptl, addr)
+	ping, _ := (&protocol.RPTPINGPacket{RepeaterID: 312999}).Encode()
+	srv.handlePacket(ping, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65018})
+	dmrd, _ := (&protocol.DMRDPacket{SourceID: 1, DestinationID: 91, RepeaterID: 312998, Timeslot: 1}).Encode()
+	srv.handlePacket(dmrd, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65019})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) < 3 {
+		t.Fatalf("Expected handler log lines, got: %q", buf.String())
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, "system=SYS-A") {
+			t.Errorf("Log line missing system field: %s", line)
+		}
+	}
+}
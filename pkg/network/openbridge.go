@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"net"
 	"sync"
+	"time"
 
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
 	"github.com/dbehnke/dmr-nexus/pkg/config"
 	"github.com/dbehnke/dmr-nexus/pkg/logger"
 	"github.com/dbehnke/dmr-nexus/pkg/protocol"
@@ -23,14 +25,74 @@ type OpenBridgeClient struct {
 	targetMu    sync.RWMutex
 	dmrdHandler func(*protocol.DMRDPacket)
 	handlerMu   sync.RWMutex
+
+	// Optional: drop authenticated frames seen again within the replay window
+	replays *replayCache
+
+	// Whether the last frame from the target passed HMAC verification, for
+	// logging when authentication starts or stops working
+	authState obAuthState
+	authMu    sync.Mutex
+
+	// Last unexpected network ID seen, so a cross-wired partner is warned
+	// about once rather than on every frame
+	foreignNetworkID uint32
+	foreignMu        sync.Mutex
 }
 
+// obAuthState is what the last received frame showed about the shared key
+type obAuthState int
+
+const (
+	obAuthUnknown obAuthState = iota // Nothing received yet
+	obAuthOK
+	obAuthFailing
+)
+
 // NewOpenBridgeClient creates a new OpenBridge client
 func NewOpenBridgeClient(cfg config.SystemConfig, log *logger.Logger) *OpenBridgeClient {
-	return &OpenBridgeClient{
+	c := &OpenBridgeClient{
 		config: cfg,
 		log:    log.WithComponent("network.openbridge"),
 	}
+	if cfg.ReplayWindowMs > 0 {
+		c.replays = newReplayCache(time.Duration(cfg.ReplayWindowMs)*time.Millisecond, replayCacheMax)
+	}
+	return c
+}
+
+// WithRouter connects the client to a bridge router as system systemName:
+// frames from the target are routed to other systems, and traffic bridged
+// to this system is sent to the target
+func (c *OpenBridgeClient) WithRouter(r *bridge.Router, systemName string) *OpenBridgeClient {
+	r.RegisterSystem(systemName, c.deliverBridged)
+	r.SetSystemAvailability(systemName, c.linkUp)
+	r.SetTransmissionLogging(systemName, c.config.LogTransmissions)
+	c.SetDMRDHandler(func(packet *protocol.DMRDPacket) {
+		targets := r.RoutePacket(packet, systemName)
+		if len(targets) == 0 {
+			return
+		}
+		// Other systems get a plain HomeBrew frame
+		packet.HMAC = nil
+		data, err := packet.Encode()
+		if err != nil {
+			c.log.Error("Failed to encode DMRD", logger.Error(err))
+			return
+		}
+		r.ForwardToSystems(targets, packet, data)
+	})
+	return c
+}
+
+// deliverBridged sends traffic another system bridged here to the target.
+// SendDMRD rewrites the frame, so it gets a copy.
+func (c *OpenBridgeClient) deliverBridged(dmrd *protocol.DMRDPacket, data []byte) {
+	packet := *dmrd
+	packet.HMAC = nil
+	if err := c.SendDMRD(&packet); err != nil {
+		c.log.Debug("Dropping bridged DMRD", logger.Error(err))
+	}
 }
 
 // Start starts the OpenBridge client
@@ -59,6 +121,9 @@ func (c *OpenBridgeClient) Start(ctx context.Context) error {
 	c.conn = conn
 	c.connMu.Unlock()
 	defer func() {
+		c.connMu.Lock()
+		c.conn = nil
+		c.connMu.Unlock()
 		_ = conn.Close()
 	}()
 
@@ -111,8 +176,10 @@ func (c *OpenBridgeClient) receiveLoop(ctx context.Context) error {
 			continue
 		}
 
-		// Process packet
-		go c.handlePacket(buf[:n], addr)
+		// Process packet; buf is reused by the next read
+		data := make([]byte, n)
+		copy(data, buf[:n])
+		go c.handlePacket(data, addr)
 	}
 }
 
@@ -144,12 +211,46 @@ func (c *OpenBridgeClient) handlePacket(data []byte, addr *net.UDPAddr) {
 
 	// Verify HMAC
 	if !packet.VerifyOpenBridgeHMAC(c.config.Passphrase) {
-		c.log.Warn("HMAC verification failed",
+		if c.setAuthState(obAuthFailing) {
+			c.log.Warn("OpenBridge authentication failing, check the passphrase",
+				logger.String("from", addr.String()),
+				logger.Int("network_id", c.config.NetworkID))
+		}
+		c.log.Debug("HMAC verification failed",
 			logger.String("from", addr.String()),
 			logger.Uint64("src", uint64(packet.SourceID)),
 			logger.Uint64("dst", uint64(packet.DestinationID)))
 		return
 	}
+	if c.setAuthState(obAuthOK) {
+		c.log.Info("OpenBridge traffic authenticated",
+			logger.String("from", addr.String()),
+			logger.Int("network_id", c.config.NetworkID))
+	}
+
+	if !c.expectedNetwork(packet.RepeaterID) {
+		c.log.Debug("Dropping frame from unexpected network ID",
+			logger.Uint64("network_id", uint64(packet.RepeaterID)),
+			logger.Uint64("stream", uint64(packet.StreamID)))
+		return
+	}
+
+	// HMAC covers no timestamp, so a captured frame verifies just as well
+	// when sent again
+	if c.replays != nil && c.replays.replayed(packet.StreamID, packet.Sequence, time.Now()) {
+		c.log.Debug("Dropping replayed OpenBridge frame",
+			logger.String("from", addr.String()),
+			logger.Uint64("stream", uint64(packet.StreamID)),
+			logger.Int("seq", int(packet.Sequence)))
+		return
+	}
+
+	// Without both_slots only TS1 carries group calls, as on send
+	if !c.config.BothSlots && packet.Timeslot == protocol.Timeslot2 && packet.CallType == protocol.CallTypeGroup {
+		c.log.Debug("Filtering received TS2 group call (both_slots=false)",
+			logger.Uint64("dst", uint64(packet.DestinationID)))
+		return
+	}
 
 	c.log.Debug("Received DMRD packet",
 		logger.Uint64("src", uint64(packet.SourceID)),
@@ -226,6 +327,52 @@ func (c *OpenBridgeClient) SendDMRD(packet *protocol.DMRDPacket) error {
 	return nil
 }
 
+// linkUp reports whether the client is running and the target's frames, if
+// any arrived yet, pass authentication. OpenBridge has no keepalive, so a
+// failing passphrase is the only sign of a broken link.
+func (c *OpenBridgeClient) linkUp() bool {
+	c.connMu.RLock()
+	running := c.conn != nil
+	c.connMu.RUnlock()
+
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+	return running && c.authState != obAuthFailing
+}
+
+// setAuthState records what the latest frame showed and reports whether
+// that changed
+func (c *OpenBridgeClient) setAuthState(state obAuthState) bool {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+	changed := c.authState != state
+	c.authState = state
+	return changed
+}
+
+// expectedNetwork reports whether an inbound frame's network ID is the
+// partner's, warning when frames from another network start arriving
+func (c *OpenBridgeClient) expectedNetwork(networkID uint32) bool {
+	expected := c.config.PartnerNetworkID
+	if expected == 0 {
+		expected = c.config.NetworkID
+	}
+	if expected < 0 || networkID == uint32(expected) {
+		return true
+	}
+
+	c.foreignMu.Lock()
+	first := c.foreignNetworkID != networkID
+	c.foreignNetworkID = networkID
+	c.foreignMu.Unlock()
+	if first {
+		c.log.Warn("Dropping OpenBridge traffic from unexpected network ID",
+			logger.Uint64("network_id", uint64(networkID)),
+			logger.Int("expected", expected))
+	}
+	return false
+}
+
 // SetDMRDHandler sets the handler for received DMRD packets
 func (c *OpenBridgeClient) SetDMRDHandler(handler func(*protocol.DMRDPacket)) {
 	c.handlerMu.Lock()
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
 	"github.com/dbehnke/dmr-nexus/pkg/config"
 	"github.com/dbehnke/dmr-nexus/pkg/logger"
 	"github.com/dbehnke/dmr-nexus/pkg/protocol"
@@ -411,3 +412,312 @@ func TestOpenBridgeClient_BothSlots(t *testing.T) {
 		})
 	}
 }
+
+func TestOpenBridgeClient_WithRouter(t *testing.T) {
+	targetConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("Failed to create target connection: %v", err)
+	}
+	defer func() { _ = targetConn.Close() }()
+
+	// OBP and LOCAL share TG 91 on both slots
+	router := bridge.NewRouter()
+	router.AddBridge(bridge.NewBridgeRuleSetFromConfig("WORLDWIDE", []config.BridgeRule{
+		{System: "OBP", TGID: 91, Timeslot: 1, Active: true},
+		{System: "LOCAL", TGID: 91, Timeslot: 1, Active: true},
+	}))
+	router.AddBridge(bridge.NewBridgeRuleSetFromConfig("WORLDWIDE-TS2", []config.BridgeRule{
+		{System: "OBP", TGID: 91, Timeslot: 2, Active: true},
+		{System: "LOCAL", TGID: 91, Timeslot: 2, Active: true},
+	}))
+	local := make(chan []byte, 4)
+	router.RegisterSystem("LOCAL", func(_ *protocol.DMRDPacket, data []byte) { local <- data })
+
+	cfg := config.SystemConfig{
+		Mode:       "OPENBRIDGE",
+		Port:       0,
+		TargetIP:   "127.0.0.1",
+		TargetPort: targetConn.LocalAddr().(*net.UDPAddr).Port,
+		NetworkID:  3129999,
+		Passphrase: "password",
+	}
+	client := NewOpenBridgeClient(cfg, logger.New(logger.Config{Level: "error"})).WithRouter(router, "OBP")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = client.Start(ctx) }()
+	time.Sleep(100 * time.Millisecond)
+	clientAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: client.GetLocalAddr().(*net.UDPAddr).Port}
+
+	send := func(timeslot int, streamID uint32) {
+		packet := &protocol.DMRDPacket{SourceID: 2345678, DestinationID: 91, RepeaterID: uint32(cfg.NetworkID),
+			Timeslot: timeslot, CallType: protocol.CallTypeGroup, FrameType: protocol.FrameTypeVoiceHeader,
+			StreamID: streamID, Payload: make([]byte, 33)}
+		if err := packet.AddOpenBridgeHMAC(cfg.Passphrase); err != nil {
+			t.Fatalf("AddOpenBridgeHMAC() failed: %v", err)
+		}
+		data, _ := packet.Encode()
+		if _, err := targetConn.WriteToUDP(data, clientAddr); err != nil {
+			t.Fatalf("WriteToUDP error: %v", err)
+		}
+	}
+
+	// TS1 group traffic from the target reaches LOCAL as a plain frame
+	send(protocol.Timeslot1, 0x9101)
+	select {
+	case data := <-local:
+		if len(data) == protocol.DMRDOpenBridgePacketSize {
+			t.Errorf("Expected the HMAC stripped before routing, got %d bytes", len(data))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected TS1 traffic from the target to reach LOCAL")
+	}
+
+	// TS2 group traffic is not accepted without both_slots
+	send(protocol.Timeslot2, 0x9102)
+	select {
+	case <-local:
+		t.Error("Expected TS2 group traffic to be filtered with both_slots=false")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Traffic bridged to OBP is sent to the target, signed, as the network ID
+	up := &protocol.DMRDPacket{SourceID: 3120001, DestinationID: 91, RepeaterID: 312000,
+		Timeslot: protocol.Timeslot1, CallType: protocol.CallTypeGroup, FrameType: protocol.FrameTypeVoiceHeader,
+		StreamID: 0x9103, Payload: make([]byte, 33)}
+	data, _ := up.Encode()
+	router.ForwardToSystems([]string{"OBP"}, up, data)
+	buf := make([]byte, 1024)
+	if err := targetConn.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("SetReadDeadline error: %v", err)
+	}
+	n, _, err := targetConn.ReadFromUDP(buf)
+	if err != nil {
+		t.Fatalf("Expected bridged traffic at the target: %v", err)
+	}
+	got := &protocol.DMRDPacket{}
+	if err := got.Parse(buf[:n]); err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if got.RepeaterID != 3129999 || !got.VerifyOpenBridgeHMAC(cfg.Passphrase) {
+		t.Errorf("Target got repeater %d (HMAC ok: %v), want network ID 3129999 signed", got.RepeaterID, got.VerifyOpenBridgeHMAC(cfg.Passphrase))
+	}
+	if up.RepeaterID != 312000 {
+		t.Errorf("Bridged packet modified in place, repeater now %d", up.RepeaterID)
+	}
+}
+
+func TestOpenBridgeClient_ReceivesMasterTraffic(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	targetConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("Failed to create target connection: %v", err)
+	}
+	defer func() { _ = targetConn.Close() }()
+
+	// The MASTER and OBP share TG 91 on TS1
+	router := bridge.NewRouter()
+	router.AddBridge(bridge.NewBridgeRuleSetFromConfig("WORLDWIDE", []config.BridgeRule{
+		{System: "MASTER-1", TGID: 91, Timeslot: 1, Active: true},
+		{System: "OBP", TGID: 91, Timeslot: 1, Active: true},
+	}))
+
+	cfg := config.SystemConfig{
+		Mode:       "OPENBRIDGE",
+		Port:       0,
+		TargetIP:   "127.0.0.1",
+		TargetPort: targetConn.LocalAddr().(*net.UDPAddr).Port,
+		NetworkID:  3129999,
+		Passphrase: "password",
+	}
+	client := NewOpenBridgeClient(cfg, log).WithRouter(router, "OBP")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = client.Start(ctx) }()
+	time.Sleep(100 * time.Millisecond)
+
+	srv := NewServer(config.SystemConfig{Mode: "MASTER"}, "MASTER-1", log).WithRouter(router)
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65022}
+	source := srv.peerManager.AddPeer(312100, srcAddr)
+	source.SetConnected()
+	source.Subscriptions.AddDynamic(91, 1)
+
+	// A key-up from a repeater on the MASTER is sent to the target
+	dmrd := &protocol.DMRDPacket{SourceID: 3120001, DestinationID: 91, RepeaterID: 312100,
+		Timeslot: protocol.Timeslot1, CallType: protocol.CallTypeGroup, FrameType: protocol.FrameTypeVoiceHeader,
+		StreamID: 0x9104, Payload: make([]byte, 33)}
+	data, err := dmrd.Encode()
+	if err != nil {
+		t.Fatalf("Encode DMRD error: %v", err)
+	}
+	srv.handleDMRD(data, srcAddr)
+
+	buf := make([]byte, 1024)
+	if err := targetConn.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("SetReadDeadline error: %v", err)
+	}
+	n, _, err := targetConn.ReadFromUDP(buf)
+	if err != nil {
+		t.Fatalf("Expected MASTER traffic at the target: %v", err)
+	}
+	got := &protocol.DMRDPacket{}
+	if err := got.Parse(buf[:n]); err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if got.StreamID != 0x9104 || got.RepeaterID != 3129999 || !got.VerifyOpenBridgeHMAC(cfg.Passphrase) {
+		t.Errorf("Target got stream %#x from repeater %d (HMAC ok: %v), want 0x9104 signed as 3129999",
+			got.StreamID, got.RepeaterID, got.VerifyOpenBridgeHMAC(cfg.Passphrase))
+	}
+}
+
+func TestOpenBridgeClient_DropsReplayedFrames(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+
+	cfg := config.SystemConfig{
+		Mode:           "OPENBRIDGE",
+		Port:           0,
+		TargetIP:       "127.0.0.1",
+		TargetPort:     62037,
+		NetworkID:      3129999,
+		Passphrase:     "password",
+		ReplayWindowMs: 5000,
+	}
+	client := NewOpenBridgeClient(cfg, log)
+
+	received := make(chan *protocol.DMRDPacket, 4)
+	client.SetDMRDHandler(func(p *protocol.DMRDPacket) {
+		received <- p
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() { _ = client.Start(ctx) }()
+	time.Sleep(100 * time.Millisecond)
+
+	senderConn, err := net.DialUDP("udp", nil, client.GetLocalAddr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatalf("Failed to create sender connection: %v", err)
+	}
+	defer func() { _ = senderConn.Close() }()
+
+	frame := func(seq byte) []byte {
+		packet := &protocol.DMRDPacket{
+			Sequence:      seq,
+			SourceID:      3120001,
+			DestinationID: 91,
+			RepeaterID:    uint32(cfg.NetworkID),
+			Timeslot:      protocol.Timeslot1,
+			CallType:      protocol.CallTypeGroup,
+			FrameType:     protocol.FrameTypeVoice,
+			StreamID:      54321,
+			Payload:       make([]byte, 33),
+		}
+		if err := packet.AddOpenBridgeHMAC(cfg.Passphrase); err != nil {
+			t.Fatalf("AddOpenBridgeHMAC() failed: %v", err)
+		}
+		data, err := packet.Encode()
+		if err != nil {
+			t.Fatalf("Encode() failed: %v", err)
+		}
+		return data
+	}
+	send := func(data []byte) *protocol.DMRDPacket {
+		if _, err := senderConn.Write(data); err != nil {
+			t.Fatalf("Failed to send packet: %v", err)
+		}
+		select {
+		case p := <-received:
+			return p
+		case <-time.After(300 * time.Millisecond):
+			return nil
+		}
+	}
+
+	captured := frame(1)
+	if p := send(captured); p == nil {
+		t.Fatal("Original frame should be delivered")
+	}
+	if p := send(captured); p != nil {
+		t.Fatal("Replayed frame should be dropped")
+	}
+	if p := send(frame(2)); p == nil || p.Sequence != 2 {
+		t.Fatal("Fresh frame in the same stream should be delivered")
+	}
+}
+
+func TestReplayCache_Bounded(t *testing.T) {
+	cache := newReplayCache(time.Minute, 4)
+	now := time.Now()
+
+	for seq := byte(0); seq < 10; seq++ {
+		if cache.replayed(1, seq, now) {
+			t.Fatalf("Frame %d wrongly reported as replay", seq)
+		}
+	}
+	if len(cache.seen) > 4 || len(cache.order) > 4 {
+		t.Errorf("Cache grew past its bound: %d seen, %d queued", len(cache.seen), len(cache.order))
+	}
+	if !cache.replayed(1, 9, now) {
+		t.Error("Recent frame should still be remembered")
+	}
+
+	// Outside the window a frame is accepted again
+	if cache.replayed(1, 9, now.Add(2*time.Minute)) {
+		t.Error("Frame outside the window should be accepted")
+	}
+}
+
+func TestOpenBridgeClient_NetworkIDMismatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		partner   int
+		networkID uint32 // Network ID carried by the inbound frame
+		want      bool
+	}{
+		{"matches network_id", 0, 3129999, true},
+		{"differs from network_id", 0, 3128888, false},
+		{"matches partner_network_id", 3128888, 3128888, true},
+		{"own network_id with a partner set", 3128888, 3129999, false},
+		{"any accepted", -1, 3127777, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.SystemConfig{
+				Mode:             "OPENBRIDGE",
+				NetworkID:        3129999,
+				PartnerNetworkID: tt.partner,
+				Passphrase:       "password",
+			}
+			client := NewOpenBridgeClient(cfg, logger.New(logger.Config{Level: "error"}))
+			delivered := 0
+			client.SetDMRDHandler(func(*protocol.DMRDPacket) { delivered++ })
+
+			packet := &protocol.DMRDPacket{SourceID: 3120001, DestinationID: 91, RepeaterID: tt.networkID,
+				Timeslot: protocol.Timeslot1, CallType: protocol.CallTypeGroup, FrameType: protocol.FrameTypeVoiceHeader,
+				StreamID: 0x0B1D, Payload: make([]byte, 33)}
+			if err := packet.AddOpenBridgeHMAC(cfg.Passphrase); err != nil {
+				t.Fatalf("AddOpenBridgeHMAC() failed: %v", err)
+			}
+			data, err := packet.Encode()
+			if err != nil {
+				t.Fatalf("Encode() failed: %v", err)
+			}
+			from := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 62031}
+			client.handlePacket(data, from)
+			client.handlePacket(data, from)
+
+			if got := delivered > 0; got != tt.want {
+				t.Errorf("Expected delivered=%v for network ID %d, got %d frames", tt.want, tt.networkID, delivered)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package network
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+)
+
+// requiresAuth reports whether peers must prove they know a passphrase.
+// Config validation requires one for MASTER systems; only a system built
+// without any is open.
+func (s *Server) requiresAuth() bool {
+	if s.config.Passphrase != "" {
+		return true
+	}
+	for _, passphrase := range s.config.Passphrases {
+		if passphrase != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyChallenge reports whether an RPTK challenge is SHA256(salt +
+// passphrase) for the system passphrase or any of the additional ones. Every
+// passphrase is checked so the time taken doesn't reveal which one matched.
+func (s *Server) verifyChallenge(salt, challenge []byte) bool {
+	passphrases := append([]string{s.config.Passphrase}, s.config.Passphrases...)
+	matched := 0
+	for _, passphrase := range passphrases {
+		if passphrase == "" {
+			continue
+		}
+		h := sha256.New()
+		h.Write(salt)
+		h.Write([]byte(passphrase))
+		matched |= subtle.ConstantTimeCompare(h.Sum(nil), challenge)
+	}
+	return matched == 1
+}
@@ -0,0 +1,138 @@
+package network
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/peer"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_AcceptsAnyConfiguredPassphrase(t *testing.T) {
+	cfg := config.SystemConfig{Mode: "MASTER", Passphrase: "new-secret", Passphrases: []string{"old-secret"}}
+	srv := NewServer(cfg, "test-system", logger.New(logger.Config{Level: "error"}))
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	// login runs RPTL and RPTK with the given passphrase and returns the
+	// reply to RPTK
+	login := func(t *testing.T, peerID uint32, passphrase string) string {
+		t.Helper()
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+		if err != nil {
+			t.Fatalf("ListenUDP error: %v", err)
+		}
+		defer func() { _ = conn.Close() }()
+		addr := conn.LocalAddr().(*net.UDPAddr)
+		buf := make([]byte, 64)
+		read := func() []byte {
+			_ = conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+			n, _, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				t.Fatalf("No reply from server: %v", err)
+			}
+			return buf[:n]
+		}
+
+		rptl, err := (&protocol.RPTLPacket{RepeaterID: peerID}).Encode()
+		if err != nil {
+			t.Fatalf("Encode RPTL error: %v", err)
+		}
+		srv.handleRPTL(rptl, addr)
+		ack := &protocol.RPTACKPacket{}
+		if err := ack.Parse(read()); err != nil || len(ack.Salt) == 0 {
+			t.Fatalf("Expected RPTACK with salt: %v", err)
+		}
+
+		h := sha256.New()
+		h.Write(ack.Salt)
+		h.Write([]byte(passphrase))
+		rptk, err := (&protocol.RPTKPacket{RepeaterID: peerID, Challenge: h.Sum(nil)}).Encode()
+		if err != nil {
+			t.Fatalf("Encode RPTK error: %v", err)
+		}
+		srv.handleRPTK(rptk, addr)
+		return string(read())
+	}
+
+	for _, tc := range []struct {
+		peerID     uint32
+		passphrase string
+	}{
+		{312001, "new-secret"},
+		{312002, "old-secret"},
+	} {
+		if reply := login(t, tc.peerID, tc.passphrase); reply[:6] != protocol.PacketTypeRPTACK {
+			t.Errorf("Peer using %q got %q, want RPTACK", tc.passphrase, reply)
+		}
+		if p := srv.peerManager.GetPeer(tc.peerID); p == nil || p.GetState() != peer.StateAuthenticated {
+			t.Errorf("Expected peer %d authenticated", tc.peerID)
+		}
+	}
+
+	if reply := login(t, 312003, "wrong"); reply[:6] != protocol.PacketTypeMSTNAK {
+		t.Errorf("Peer using a wrong passphrase got %q, want MSTNAK", reply)
+	}
+	if srv.peerManager.GetPeer(312003) != nil {
+		t.Error("Expected the peer with a wrong passphrase to be dropped")
+	}
+}
+
+func TestServer_VerifyChallengeKnownVector(t *testing.T) {
+	cfg := config.SystemConfig{Mode: "MASTER", Passphrase: "passw0rd"}
+	srv := NewServer(cfg, "test-system", logger.New(logger.Config{Level: "error"}))
+
+	salt := []byte{0x12, 0x34, 0x56, 0x78}
+	// SHA256(0x12345678 || "passw0rd")
+	hash, err := hex.DecodeString("1b62774a1d39478330a0eb983912f567067b1753766ff1d3dc302d7cb6cf8dc8")
+	if err != nil {
+		t.Fatalf("DecodeString error: %v", err)
+	}
+
+	if !srv.verifyChallenge(salt, hash) {
+		t.Error("Expected the known hash to verify")
+	}
+	if srv.verifyChallenge([]byte{0x12, 0x34, 0x56, 0x79}, hash) {
+		t.Error("Expected the hash not to verify against another salt")
+	}
+	if srv.verifyChallenge(salt, make([]byte, sha256.Size)) {
+		t.Error("Expected a zero challenge to be rejected")
+	}
+}
+
+func TestServer_RejectsWrongPassphrase(t *testing.T) {
+	cfg := config.SystemConfig{Mode: "MASTER", Passphrase: "passw0rd"}
+	srv := NewServer(cfg, "test-system", logger.New(logger.Config{Level: "error"}))
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	addr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65061}
+	rptl, err := (&protocol.RPTLPacket{RepeaterID: 312004}).Encode()
+	if err != nil {
+		t.Fatalf("Encode RPTL error: %v", err)
+	}
+	srv.handleRPTL(rptl, addr)
+
+	// Without additional passphrases configured the single one is enforced
+	rptk, err := (&protocol.RPTKPacket{RepeaterID: 312004, Challenge: make([]byte, sha256.Size)}).Encode()
+	if err != nil {
+		t.Fatalf("Encode RPTK error: %v", err)
+	}
+	srv.handleRPTK(rptk, addr)
+	if srv.peerManager.GetPeer(312004) != nil {
+		t.Error("Expected a peer failing the challenge to be dropped")
+	}
+}
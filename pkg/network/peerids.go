@@ -0,0 +1,31 @@
+package network
+
+// Default repeater ID range for reject_invalid_peer_ids: 6-digit repeater
+// IDs up to 9-digit hotspot IDs (a 7-digit radio ID plus a 2-digit suffix)
+const (
+	defaultMinPeerID = 100000
+	defaultMaxPeerID = 999999999
+)
+
+// Reserved block at the top of the 24-bit DMR ID space, used for all-call
+// and gateway addresses; no repeater may log in with one of these
+const (
+	reservedPeerIDMin = 0xFFFCE0
+	reservedPeerIDMax = 0xFFFFFF
+)
+
+// validPeerID reports whether a repeater ID is inside the configured range
+// and outside the reserved block
+func (s *Server) validPeerID(id uint32) bool {
+	minID, maxID := uint32(defaultMinPeerID), uint32(defaultMaxPeerID)
+	if s.config.MinPeerID > 0 {
+		minID = uint32(s.config.MinPeerID)
+	}
+	if s.config.MaxPeerID > 0 {
+		maxID = uint32(s.config.MaxPeerID)
+	}
+	if id == 0 || id < minID || id > maxID {
+		return false
+	}
+	return id < reservedPeerIDMin || id > reservedPeerIDMax
+}
@@ -0,0 +1,73 @@
+package network
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_RejectInvalidPeerIDs(t *testing.T) {
+	login := func(t *testing.T, cfg config.SystemConfig, id uint32) bool {
+		t.Helper()
+		srv := NewServer(cfg, "test-system", logger.New(logger.Config{Level: "error"}))
+		serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+		if err != nil {
+			t.Fatalf("ListenUDP error: %v", err)
+		}
+		srv.conn = serverConn
+		defer func() { _ = serverConn.Close() }()
+
+		peerConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+		if err != nil {
+			t.Fatalf("ListenUDP error: %v", err)
+		}
+		defer func() { _ = peerConn.Close() }()
+
+		data, err := (&protocol.RPTLPacket{RepeaterID: id}).Encode()
+		if err != nil {
+			t.Fatalf("Encode RPTL error: %v", err)
+		}
+		srv.handleRPTL(data, peerConn.LocalAddr().(*net.UDPAddr))
+
+		buf := make([]byte, 64)
+		_ = peerConn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		n, _, err := peerConn.ReadFromUDP(buf)
+		if err != nil {
+			t.Fatalf("No reply to RPTL from %d: %v", id, err)
+		}
+		return strings.HasPrefix(string(buf[:n]), protocol.PacketTypeRPTACK)
+	}
+
+	tests := []struct {
+		name   string
+		cfg    config.SystemConfig
+		id     uint32
+		accept bool
+	}{
+		{"zero", config.SystemConfig{RejectInvalidPeerIDs: true}, 0, false},
+		{"below default range", config.SystemConfig{RejectInvalidPeerIDs: true}, 99999, false},
+		{"lowest repeater ID", config.SystemConfig{RejectInvalidPeerIDs: true}, 100000, true},
+		{"below reserved block", config.SystemConfig{RejectInvalidPeerIDs: true}, 0xFFFCDF, true},
+		{"reserved block start", config.SystemConfig{RejectInvalidPeerIDs: true}, 0xFFFCE0, false},
+		{"all call", config.SystemConfig{RejectInvalidPeerIDs: true}, 0xFFFFFF, false},
+		{"highest hotspot ID", config.SystemConfig{RejectInvalidPeerIDs: true}, 999999999, true},
+		{"above default range", config.SystemConfig{RejectInvalidPeerIDs: true}, 1000000000, false},
+		{"custom minimum", config.SystemConfig{RejectInvalidPeerIDs: true, MinPeerID: 3100000}, 312000, false},
+		{"custom maximum", config.SystemConfig{RejectInvalidPeerIDs: true, MaxPeerID: 9999999}, 312000101, false},
+		{"inside custom range", config.SystemConfig{RejectInvalidPeerIDs: true, MinPeerID: 3100000, MaxPeerID: 3199999}, 3120001, true},
+		{"disabled", config.SystemConfig{}, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Mode = "MASTER"
+			if got := login(t, tt.cfg, tt.id); got != tt.accept {
+				t.Errorf("peer %d accepted = %v, want %v", tt.id, got, tt.accept)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package network
+
+import (
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+// dataCallTimeout is how long a packet data call may go without a block
+// before its partial message is dropped
+const dataCallTimeout = 10 * time.Second
+
+// dataCall collects the rate 1/2 blocks of one packet data call
+type dataCall struct {
+	header *protocol.DataHeader
+	blocks [][]byte
+	last   time.Time
+}
+
+// SetPositionHandler sets the callback run for each LRRP position report a
+// radio sends. Packet data is only reassembled while a handler is set.
+func (s *Server) SetPositionHandler(fn func(radioID uint32, report *protocol.LRRPReport)) {
+	s.onPosition = fn
+}
+
+// SetMessageHandler sets the callback run for each TMS text message a radio
+// sends; dst is a talkgroup when group is set
+func (s *Server) SetMessageHandler(fn func(src, dst uint32, group bool, text string)) {
+	s.onMessage = fn
+}
+
+// notePacketData collects the data header and blocks of IP packet data
+// calls and hands any LRRP position report or text message they carry to
+// its handler. The frames themselves are routed as usual.
+func (s *Server) notePacketData(dmrd *protocol.DMRDPacket) {
+	if s.onPosition == nil && s.onMessage == nil {
+		return
+	}
+	// Packet data travels in data sync bursts, frame type 2 on the wire
+	// (the same as terminators), told apart by data type
+	if dmrd.FrameType != protocol.FrameTypeVoiceTerminator {
+		return
+	}
+	switch dmrd.DataType {
+	case protocol.DataTypeDataHeader, protocol.DataTypeRate12Data, protocol.DataTypeRate34Data:
+	default:
+		return
+	}
+
+	var complete *dataCall
+	s.dataCallMu.Lock()
+	switch dmrd.DataType {
+	case protocol.DataTypeDataHeader:
+		delete(s.dataCalls, dmrd.StreamID)
+		h, err := protocol.ParseDataHeader(dmrd.Payload)
+		if err == nil && h.SAP == protocol.SAPIPPacketData && h.BlocksToFollow > 0 {
+			s.dataCalls[dmrd.StreamID] = &dataCall{header: h, last: time.Now()}
+		}
+	case protocol.DataTypeRate12Data:
+		call := s.dataCalls[dmrd.StreamID]
+		if call == nil {
+			break
+		}
+		block, err := protocol.ExtractDataBlock(dmrd.Payload)
+		if err != nil {
+			delete(s.dataCalls, dmrd.StreamID)
+			break
+		}
+		call.blocks = append(call.blocks, block)
+		call.last = time.Now()
+		if len(call.blocks) == call.header.BlocksToFollow {
+			delete(s.dataCalls, dmrd.StreamID)
+			complete = call
+		}
+	case protocol.DataTypeRate34Data:
+		// Trellis coded blocks aren't decoded; the call can't be completed
+		delete(s.dataCalls, dmrd.StreamID)
+	}
+	s.dataCallMu.Unlock()
+
+	if complete != nil {
+		s.decodePacketData(complete)
+	}
+}
+
+// decodePacketData unwraps a completed data call down to the UDP datagram
+// it carries and decodes it by destination port
+func (s *Server) decodePacketData(call *dataCall) {
+	data, err := protocol.AssembleData(call.header, call.blocks)
+	if err != nil {
+		s.log.Debug("Dropping malformed packet data", logger.Error(err))
+		return
+	}
+	port, payload, err := protocol.UDPPayload(data)
+	if err != nil {
+		return
+	}
+	switch port {
+	case protocol.LRRPPort:
+		if s.onPosition != nil {
+			s.decodePosition(call, payload)
+		}
+	case protocol.TMSPort:
+		if s.onMessage != nil {
+			s.decodeMessage(call, payload)
+		}
+	}
+}
+
+// decodePosition hands an LRRP report to the position handler
+func (s *Server) decodePosition(call *dataCall, payload []byte) {
+	report, err := protocol.ParseLRRP(payload)
+	if err != nil {
+		s.log.Debug("Ignoring LRRP message",
+			logger.Int("radio_id", int(call.header.SourceID)),
+			logger.Error(err))
+		return
+	}
+	s.onPosition(call.header.SourceID, report)
+}
+
+// decodeMessage hands a TMS text message to the message handler
+func (s *Server) decodeMessage(call *dataCall, payload []byte) {
+	text, err := protocol.ParseTMS(payload)
+	if err != nil {
+		s.log.Debug("Ignoring TMS message",
+			logger.Int("radio_id", int(call.header.SourceID)),
+			logger.Error(err))
+		return
+	}
+	s.onMessage(call.header.SourceID, call.header.DestinationID, call.header.Group, text)
+}
+
+// cleanupDataCalls drops packet data calls whose blocks stopped arriving
+func (s *Server) cleanupDataCalls(now time.Time) {
+	s.dataCallMu.Lock()
+	defer s.dataCallMu.Unlock()
+
+	for streamID, call := range s.dataCalls {
+		if now.Sub(call.last) > dataCallTimeout {
+			delete(s.dataCalls, streamID)
+		}
+	}
+}
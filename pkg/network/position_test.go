@@ -0,0 +1,208 @@
+package network
+
+import (
+	"encoding/binary"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+// lrrpDataCall builds the bursts of an unconfirmed IP packet data call
+// carrying an LRRP point-2d report for lat, lon
+func lrrpDataCall(t *testing.T, src uint32, lat, lon float64) [][]byte {
+	t.Helper()
+	lrrp := []byte{0x0D, 0x09, 0x66}
+	lrrp = binary.BigEndian.AppendUint32(lrrp, uint32(int32(math.Round(lat*(1<<31)/90))))
+	lrrp = binary.BigEndian.AppendUint32(lrrp, uint32(int32(math.Round(lon*(1<<31)/180))))
+	return udpDataCall(t, src, 9999, false, protocol.LRRPPort, lrrp)
+}
+
+// udpDataCall builds the bursts of an unconfirmed IP packet data call
+// carrying one UDP datagram to port
+func udpDataCall(t *testing.T, src, dst uint32, group bool, port uint16, payload []byte) [][]byte {
+	t.Helper()
+	udp := []byte{byte(port >> 8), byte(port), byte(port >> 8), byte(port), 0, byte(8 + len(payload)), 0, 0}
+	ip := []byte{0x45, 0, 0, byte(20 + len(udp) + len(payload)), 0, 0, 0, 0, 64, 17, 0, 0,
+		12, 0x2f, 0xa1, 0x81, 13, 0, 0, 1}
+	message := append(append(ip, udp...), payload...)
+
+	blocks := (len(message) + 4 + 11) / 12
+	pad := blocks*12 - len(message) - 4
+	message = append(message, make([]byte, pad+4)...)
+
+	header := &protocol.DataHeader{
+		Group:          group,
+		Format:         protocol.DataFormatUnconfirmed,
+		SAP:            protocol.SAPIPPacketData,
+		PadOctets:      pad,
+		DestinationID:  dst,
+		SourceID:       src,
+		BlocksToFollow: blocks,
+	}
+	bursts := [][]byte{header.Encode()}
+	for i := 0; i < blocks; i++ {
+		bursts = append(bursts, message[i*12:(i+1)*12])
+	}
+
+	payloads := make([][]byte, len(bursts))
+	for i, b := range bursts {
+		payloads[i] = make([]byte, 33)
+		if err := protocol.EmbedDataBlock(b, payloads[i]); err != nil {
+			t.Fatalf("EmbedDataBlock error: %v", err)
+		}
+	}
+	return payloads
+}
+
+func TestServer_DecodesLRRPPosition(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(config.SystemConfig{Mode: "MASTER"}, "test-system", log).WithRouter(bridge.NewRouter())
+
+	type fix struct {
+		radioID  uint32
+		lat, lon float64
+	}
+	var fixes []fix
+	srv.SetPositionHandler(func(radioID uint32, report *protocol.LRRPReport) {
+		fixes = append(fixes, fix{radioID, report.Latitude, report.Longitude})
+	})
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65022}
+	srv.peerManager.AddPeer(111, srcAddr).SetConnected()
+
+	// Byte 15 as a repeater sends it for a TS1 private data call: call type
+	// bit set, frame type 2 (data sync), then the data header (data type 6)
+	// and rate 1/2 blocks (data type 7)
+	const headerSlot, rate12Slot = 0x66, 0x67
+	send := func(streamID uint32, payloads [][]byte) {
+		for i, payload := range payloads {
+			dmrd := &protocol.DMRDPacket{
+				SourceID:      3120001,
+				DestinationID: 9999,
+				RepeaterID:    111,
+				StreamID:      streamID,
+				Payload:       payload,
+			}
+			data, err := dmrd.Encode()
+			if err != nil {
+				t.Fatalf("Encode DMRD error: %v", err)
+			}
+			data[protocol.DMRDOffsetSlot] = rate12Slot
+			if i == 0 {
+				data[protocol.DMRDOffsetSlot] = headerSlot
+			}
+			srv.handleDMRD(data, srcAddr)
+		}
+	}
+
+	call := lrrpDataCall(t, 3120001, 42.33, -83.05)
+	send(1, call)
+	if len(fixes) != 1 || fixes[0].radioID != 3120001 ||
+		math.Abs(fixes[0].lat-42.33) > 1e-6 || math.Abs(fixes[0].lon+83.05) > 1e-6 {
+		t.Fatalf("Expected one fix for 3120001 at 42.33, -83.05, got %+v", fixes)
+	}
+
+	// A call missing its last block yields nothing
+	send(2, call[:len(call)-1])
+	if len(fixes) != 1 {
+		t.Errorf("Expected no fix from an incomplete call, got %+v", fixes)
+	}
+
+	// Nor does one whose blocks turn out rate 3/4 (data type 8)
+	send(3, call[:len(call)-1])
+	last, err := (&protocol.DMRDPacket{SourceID: 3120001, DestinationID: 9999, RepeaterID: 111,
+		StreamID: 3, Payload: call[len(call)-1]}).Encode()
+	if err != nil {
+		t.Fatalf("Encode DMRD error: %v", err)
+	}
+	last[protocol.DMRDOffsetSlot] = 0x68
+	srv.handleDMRD(last, srcAddr)
+	srv.dataCallMu.Lock()
+	_, held := srv.dataCalls[3]
+	srv.dataCallMu.Unlock()
+	if len(fixes) != 1 || held {
+		t.Errorf("Expected a rate 3/4 block to drop the call, got fixes %+v (held %v)", fixes, held)
+	}
+	srv.cleanupDataCalls(time.Now().Add(2 * dataCallTimeout))
+	srv.dataCallMu.Lock()
+	pending := len(srv.dataCalls)
+	srv.dataCallMu.Unlock()
+	if pending != 0 {
+		t.Errorf("Expected the stalled call to be dropped, %d pending", pending)
+	}
+}
+
+func TestServer_DecodesTextMessage(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(config.SystemConfig{Mode: "MASTER"}, "test-system", log).WithRouter(bridge.NewRouter())
+
+	type message struct {
+		src, dst uint32
+		group    bool
+		text     string
+	}
+	var messages []message
+	srv.SetMessageHandler(func(src, dst uint32, group bool, text string) {
+		messages = append(messages, message{src, dst, group, text})
+	})
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65023}
+	srv.peerManager.AddPeer(111, srcAddr).SetConnected()
+
+	// Byte 15 as a repeater sends it for a TS2 group data call: timeslot bit
+	// set, frame type 2 (data sync), then the data header (data type 6) and
+	// rate 1/2 blocks (data type 7)
+	const headerSlot, rate12Slot = 0xA6, 0xA7
+	send := func(streamID uint32, payloads [][]byte) {
+		for i, payload := range payloads {
+			dmrd := &protocol.DMRDPacket{
+				SourceID:      3120001,
+				DestinationID: 3100,
+				RepeaterID:    111,
+				StreamID:      streamID,
+				Payload:       payload,
+			}
+			data, err := dmrd.Encode()
+			if err != nil {
+				t.Fatalf("Encode DMRD error: %v", err)
+			}
+			data[protocol.DMRDOffsetSlot] = rate12Slot
+			if i == 0 {
+				data[protocol.DMRDOffsetSlot] = headerSlot
+			}
+			srv.handleDMRD(data, srcAddr)
+		}
+	}
+
+	send(1, udpDataCall(t, 3120001, 3100, true, protocol.TMSPort, protocol.EncodeTMS("QRV on 3100", 1)))
+	want := message{3120001, 3100, true, "QRV on 3100"}
+	if len(messages) != 1 || messages[0] != want {
+		t.Fatalf("Expected %+v, got %+v", want, messages)
+	}
+
+	// Position reports are not text messages
+	send(2, lrrpDataCall(t, 3120001, 42.33, -83.05))
+	if len(messages) != 1 {
+		t.Errorf("Expected an LRRP report to be ignored, got %+v", messages)
+	}
+}
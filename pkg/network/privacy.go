@@ -0,0 +1,52 @@
+package network
+
+import (
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+// dropEncrypted reports whether a frame belongs to an encrypted stream that
+// this system refuses to forward. The privacy flag is only carried in the
+// voice LC header, so the stream is remembered until its terminator.
+func (s *Server) dropEncrypted(dmrd *protocol.DMRDPacket) bool {
+	if !s.config.DropEncrypted {
+		return false
+	}
+
+	s.encryptedMu.Lock()
+	defer s.encryptedMu.Unlock()
+
+	if _, ok := s.encryptedStreams[dmrd.StreamID]; !ok {
+		if dmrd.DataType != protocol.DataTypeVoiceLCHeader || !dmrd.IsEncrypted() {
+			return false
+		}
+		s.log.Info("Dropping encrypted stream",
+			logger.Int("src_id", int(dmrd.SourceID)),
+			logger.Int("tg", int(dmrd.DestinationID)),
+			logger.Int("ts", dmrd.Timeslot),
+			logger.Uint64("stream", uint64(dmrd.StreamID)))
+	}
+	if s.metrics != nil {
+		s.metrics.PacketDropped("encrypted")
+	}
+
+	if dmrd.IsTerminator() {
+		delete(s.encryptedStreams, dmrd.StreamID)
+	} else {
+		s.encryptedStreams[dmrd.StreamID] = time.Now()
+	}
+	return true
+}
+
+// cleanupEncrypted forgets encrypted streams that ended without a terminator
+func (s *Server) cleanupEncrypted(now time.Time) {
+	s.encryptedMu.Lock()
+	defer s.encryptedMu.Unlock()
+	for streamID, last := range s.encryptedStreams {
+		if now.Sub(last) > s.muteWindow {
+			delete(s.encryptedStreams, streamID)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package network
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/metrics"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_DropEncryptedStream(t *testing.T) {
+	cfg := config.SystemConfig{Mode: "MASTER", DropEncrypted: true}
+	log := logger.New(logger.Config{Level: "error"})
+	collector := metrics.NewCollector()
+	srv := NewServer(cfg, "test-system", log).WithRouter(bridge.NewRouter()).WithMetrics(collector)
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	listenConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = listenConn.Close() }()
+	listener := srv.peerManager.AddPeer(222, listenConn.LocalAddr().(*net.UDPAddr))
+	listener.SetConnected()
+	listener.Subscriptions.AddDynamic(3100, 1)
+
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65012}
+	source := srv.peerManager.AddPeer(111, srcAddr)
+	source.SetConnected()
+	source.Subscriptions.AddDynamic(3100, 1)
+
+	transmit := func(streamID uint32, serviceOptions byte) {
+		headerPayload := make([]byte, 33)
+		lc := make([]byte, 12)
+		lc[2] = serviceOptions
+		if err := protocol.EmbedFullLC(lc, headerPayload); err != nil {
+			t.Fatalf("EmbedFullLC error: %v", err)
+		}
+		frames := []protocol.DMRDPacket{
+			{FrameType: protocol.FrameTypeVoiceTerminator, DataType: protocol.DataTypeVoiceLCHeader, Payload: headerPayload},
+			{FrameType: protocol.FrameTypeVoice, DataType: 0, Payload: make([]byte, 33)},
+			{FrameType: protocol.FrameTypeVoice, DataType: 1, Payload: make([]byte, 33)},
+			{FrameType: protocol.FrameTypeVoiceTerminator, DataType: protocol.DataTypeTerminatorLC, Payload: make([]byte, 33)},
+		}
+		if serviceOptions&protocol.ServiceOptionPrivacy != 0 {
+			// Encrypted calls carry a PI header between the voice LC header and
+			// the first voice burst; it must not be mistaken for the end of the call
+			pi := protocol.DMRDPacket{FrameType: protocol.FrameTypeVoiceTerminator, DataType: protocol.DataTypePIHeader, Payload: make([]byte, 33)}
+			frames = append(frames[:1], append([]protocol.DMRDPacket{pi}, frames[1:]...)...)
+		}
+		for i, f := range frames {
+			f.Sequence = byte(i)
+			f.SourceID = 3120001
+			f.DestinationID = 3100
+			f.RepeaterID = 111
+			f.Timeslot = 1
+			f.CallType = protocol.CallTypeGroup
+			f.StreamID = streamID
+			data, err := f.Encode()
+			if err != nil {
+				t.Fatalf("Encode DMRD error: %v", err)
+			}
+			srv.handleDMRD(data, srcAddr)
+		}
+	}
+	count := func() int {
+		n := 0
+		buf := make([]byte, 2048)
+		for {
+			if err := listenConn.SetReadDeadline(time.Now().Add(200 * time.Millisecond)); err != nil {
+				t.Fatalf("SetReadDeadline error: %v", err)
+			}
+			if _, _, err := listenConn.ReadFromUDP(buf); err != nil {
+				return n
+			}
+			n++
+		}
+	}
+
+	transmit(900, protocol.ServiceOptionPrivacy)
+	if got := count(); got != 0 {
+		t.Fatalf("Expected encrypted stream to be dropped, listener got %d frames", got)
+	}
+	if got := collector.GetPacketsDropped("encrypted"); got != 5 {
+		t.Errorf("Expected 5 frames counted as dropped, got %d", got)
+	}
+
+	transmit(901, 0)
+	if got := count(); got != 4 {
+		t.Errorf("Expected clear stream forwarded whole, listener got %d frames", got)
+	}
+}
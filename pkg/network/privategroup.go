@@ -0,0 +1,36 @@
+package network
+
+import (
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+// privateToGroup rewrites a private call frame into a group call on the
+// configured talkgroup, returning the re-encoded frame. Any bytes past the
+// standard frame (e.g. BER/RSSI) are kept.
+func (s *Server) privateToGroup(dmrd *protocol.DMRDPacket, data []byte) ([]byte, bool) {
+	unit := dmrd.DestinationID
+	tg := uint32(s.config.PrivateToGroupTG)
+	if err := dmrd.ConvertToGroupCall(tg); err != nil {
+		s.log.Debug("Failed to convert private call LC", logger.Error(err))
+		return nil, false
+	}
+
+	encoded, err := dmrd.Encode()
+	if err != nil {
+		s.log.Debug("Failed to encode converted private call", logger.Error(err))
+		return nil, false
+	}
+	converted := make([]byte, len(data))
+	copy(converted, data)
+	copy(converted, encoded[:protocol.DMRDPacketSize])
+
+	if dmrd.FrameType == protocol.FrameTypeVoiceHeader {
+		s.log.Info("Converting private call to group call",
+			logger.Int("src_id", int(dmrd.SourceID)),
+			logger.Int("unit", int(unit)),
+			logger.Int("tg", int(tg)),
+			logger.Int("ts", dmrd.Timeslot))
+	}
+	return converted, true
+}
@@ -0,0 +1,69 @@
+package network
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_PrivateCallConvertedToGroup(t *testing.T) {
+	cfg := config.SystemConfig{Mode: "MASTER", PrivateCallsEnabled: true, PrivateToGroupTG: 9}
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(cfg, "test-system", log).WithRouter(bridge.NewRouter())
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	listenConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = listenConn.Close() }()
+	listener := srv.peerManager.AddPeer(222, listenConn.LocalAddr().(*net.UDPAddr))
+	listener.SetConnected()
+	listener.Subscriptions.AddDynamic(9, 1)
+
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65020}
+	source := srv.peerManager.AddPeer(111, srcAddr)
+	source.SetConnected()
+	source.Subscriptions.AddDynamic(9, 1)
+
+	dmrd := &protocol.DMRDPacket{
+		SourceID:      3120001,
+		DestinationID: 3120002,
+		RepeaterID:    111,
+		Timeslot:      1,
+		CallType:      protocol.CallTypePrivate,
+		FrameType:     protocol.FrameTypeVoiceHeader,
+		StreamID:      4242,
+		Payload:       make([]byte, 33),
+	}
+	data, err := dmrd.Encode()
+	if err != nil {
+		t.Fatalf("Encode DMRD error: %v", err)
+	}
+	srv.handleDMRD(data, srcAddr)
+
+	buf := make([]byte, 512)
+	_ = listenConn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	n, _, err := listenConn.ReadFromUDP(buf)
+	if err != nil {
+		t.Fatalf("Expected the converted call on TG 9: %v", err)
+	}
+	got, err := protocol.ParseDMRD(buf[:n])
+	if err != nil {
+		t.Fatalf("ParseDMRD error: %v", err)
+	}
+	if got.CallType != protocol.CallTypeGroup || got.DestinationID != 9 || got.SourceID != 3120001 {
+		t.Errorf("Expected group call 3120001 -> TG 9, got type %d %d -> %d", got.CallType, got.SourceID, got.DestinationID)
+	}
+}
@@ -0,0 +1,111 @@
+package network
+
+import (
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/peer"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+// streamDelivery records which peers have been sent part of a stream
+type streamDelivery struct {
+	peers map[uint32]bool
+	last  time.Time
+
+	// Caller and destination, for streams that arrived here from a peer
+	sourceID      uint32
+	destinationID uint32
+}
+
+// noteStream records that a stream has started arriving, before any of it is
+// forwarded. Terminators of streams never seen before stand alone and are
+// forwarded as usual.
+func (s *Server) noteStream(dmrd *protocol.DMRDPacket) {
+	if dmrd.IsTerminator() {
+		return
+	}
+	s.deliveriesMu.Lock()
+	defer s.deliveriesMu.Unlock()
+
+	d, ok := s.deliveries[dmrd.StreamID]
+	if !ok {
+		d = &streamDelivery{
+			peers:         make(map[uint32]bool),
+			sourceID:      dmrd.SourceID,
+			destinationID: dmrd.DestinationID,
+		}
+		s.deliveries[dmrd.StreamID] = d
+	}
+	d.last = time.Now()
+}
+
+// shouldDeliver reports whether a frame may be sent to a peer, and records
+// the delivery. A terminator of a stream seen arriving only goes to peers
+// that were sent part of it: a peer that never saw the stream (a first key-up
+// muted stream, or one the peer subscribed to only as it ended) would
+// otherwise be left with a dangling terminator.
+func (s *Server) shouldDeliver(dmrd *protocol.DMRDPacket, peerID uint32) bool {
+	s.deliveriesMu.Lock()
+	defer s.deliveriesMu.Unlock()
+
+	d, ok := s.deliveries[dmrd.StreamID]
+	if dmrd.IsTerminator() {
+		return !ok || d.peers[peerID]
+	}
+	if !ok {
+		d = &streamDelivery{peers: make(map[uint32]bool)}
+		s.deliveries[dmrd.StreamID] = d
+	}
+	d.peers[peerID] = true
+	d.last = time.Now()
+	return true
+}
+
+// cleanupDeliveries forgets streams idle longer than the mute window. Entries
+// outlive their terminator so repeated terminator frames still get through.
+func (s *Server) cleanupDeliveries(now time.Time) {
+	s.deliveriesMu.Lock()
+	defer s.deliveriesMu.Unlock()
+	for streamID, d := range s.deliveries {
+		if now.Sub(d.last) > s.muteWindow {
+			delete(s.deliveries, streamID)
+		}
+	}
+}
+
+// withStreamTargets adds to a frame's subscribers the peers that were sent
+// earlier frames of its stream, so a peer whose subscription expires mid-call
+// hears the call out. A new stream has no deliveries yet, so it only goes to
+// peers still subscribed.
+func (s *Server) withStreamTargets(dmrd *protocol.DMRDPacket, targets []*peer.Peer, sourcePeerID uint32) []*peer.Peer {
+	s.deliveriesMu.Lock()
+	d, ok := s.deliveries[dmrd.StreamID]
+	var held []uint32
+	if ok {
+		for peerID := range d.peers {
+			held = append(held, peerID)
+		}
+	}
+	s.deliveriesMu.Unlock()
+
+	for _, peerID := range held {
+		if peerID == sourcePeerID || containsPeer(targets, peerID) {
+			continue
+		}
+		p := s.peerManager.GetPeer(peerID)
+		if p == nil || p.GetState() != peer.StateConnected {
+			continue
+		}
+		targets = append(targets, p)
+	}
+	return targets
+}
+
+func containsPeer(peers []*peer.Peer, peerID uint32) bool {
+	for _, p := range peers {
+		if p.ID == peerID {
+			return true
+		}
+	}
+	return false
+}
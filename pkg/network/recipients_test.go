@@ -0,0 +1,223 @@
+package network
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_MutedStreamTerminatorNotLeaked(t *testing.T) {
+	cfg := config.SystemConfig{Mode: "MASTER"}
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(cfg, "test-system", log).WithRouter(bridge.NewRouter())
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	listenConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = listenConn.Close() }()
+	listener := srv.peerManager.AddPeer(222, listenConn.LocalAddr().(*net.UDPAddr))
+	listener.SetConnected()
+	listener.Subscriptions.AddDynamic(3100, 1)
+
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65011}
+	srv.peerManager.AddPeer(111, srcAddr).SetConnected()
+
+	transmit := func(streamID uint32) {
+		for _, ft := range []byte{protocol.FrameTypeVoiceHeader, protocol.FrameTypeVoice, protocol.FrameTypeVoiceTerminator} {
+			dmrd := &protocol.DMRDPacket{
+				SourceID:      3120001,
+				DestinationID: 3100,
+				RepeaterID:    111,
+				Timeslot:      1,
+				CallType:      protocol.CallTypeGroup,
+				FrameType:     ft,
+				StreamID:      streamID,
+				Payload:       make([]byte, 33),
+			}
+			if ft == protocol.FrameTypeVoiceTerminator {
+				dmrd.DataType = protocol.DataTypeTerminatorLC
+			}
+			data, err := dmrd.Encode()
+			if err != nil {
+				t.Fatalf("Encode DMRD error: %v", err)
+			}
+			srv.handleDMRD(data, srcAddr)
+		}
+	}
+	read := func() []*protocol.DMRDPacket {
+		var got []*protocol.DMRDPacket
+		buf := make([]byte, 2048)
+		for {
+			if err := listenConn.SetReadDeadline(time.Now().Add(200 * time.Millisecond)); err != nil {
+				t.Fatalf("SetReadDeadline error: %v", err)
+			}
+			n, _, err := listenConn.ReadFromUDP(buf)
+			if err != nil {
+				return got
+			}
+			if pkt, err := protocol.ParseDMRD(buf[:n]); err == nil {
+				got = append(got, pkt)
+			}
+		}
+	}
+
+	// First key-up subscribes the source and is muted end to end, terminator included
+	transmit(800)
+	if got := read(); len(got) != 0 {
+		t.Fatalf("Muted first key-up leaked %d frames (first type %d)", len(got), got[0].FrameType)
+	}
+	srv.mutedStreamsMu.Lock()
+	remaining := len(srv.mutedStreams)
+	srv.mutedStreamsMu.Unlock()
+	if remaining != 0 {
+		t.Errorf("Expected the terminator to unmute the stream, %d still muted", remaining)
+	}
+
+	// A terminator repeated after the muted stream ended still doesn't leak
+	term := &protocol.DMRDPacket{
+		SourceID:      3120001,
+		DestinationID: 3100,
+		RepeaterID:    111,
+		Timeslot:      1,
+		CallType:      protocol.CallTypeGroup,
+		FrameType:     protocol.FrameTypeVoiceTerminator,
+		DataType:      protocol.DataTypeTerminatorLC,
+		StreamID:      800,
+		Payload:       make([]byte, 33),
+	}
+	data, err := term.Encode()
+	if err != nil {
+		t.Fatalf("Encode DMRD error: %v", err)
+	}
+	srv.handleDMRD(data, srcAddr)
+	if got := read(); len(got) != 0 {
+		t.Fatalf("Repeated terminator of muted stream leaked %d frames", len(got))
+	}
+
+	// The next transmission is forwarded whole
+	transmit(801)
+	got := read()
+	if len(got) != 3 {
+		t.Fatalf("Expected header, voice and terminator, got %d frames", len(got))
+	}
+	if got[0].FrameType != protocol.FrameTypeVoiceHeader || !got[2].IsTerminator() {
+		t.Errorf("Expected stream framed by header and terminator, got types %d..%d", got[0].FrameType, got[2].FrameType)
+	}
+}
+
+func TestServer_HoldStreamTargets(t *testing.T) {
+	setup := func(t *testing.T, hold bool) (*Server, *net.UDPConn, *net.UDPAddr, func()) {
+		t.Helper()
+		cfg := config.SystemConfig{Mode: "MASTER", HoldStreamTargets: hold}
+		srv := NewServer(cfg, "test-system", logger.New(logger.Config{Level: "error"})).WithRouter(bridge.NewRouter())
+
+		serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+		if err != nil {
+			t.Fatalf("ListenUDP error: %v", err)
+		}
+		srv.conn = serverConn
+		t.Cleanup(func() { _ = serverConn.Close() })
+
+		listenConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+		if err != nil {
+			t.Fatalf("ListenUDP error: %v", err)
+		}
+		t.Cleanup(func() { _ = listenConn.Close() })
+		listener := srv.peerManager.AddPeer(222, listenConn.LocalAddr().(*net.UDPAddr))
+		listener.SetConnected()
+		listener.Subscriptions.AddDynamic(3100, 1)
+
+		srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65013}
+		source := srv.peerManager.AddPeer(111, srcAddr)
+		source.SetConnected()
+		source.Subscriptions.AddDynamic(3100, 1)
+
+		expire := func() {
+			listener.Subscriptions.TS1[3100] = time.Now().Add(-time.Second)
+		}
+		return srv, listenConn, srcAddr, expire
+	}
+
+	send := func(t *testing.T, srv *Server, srcAddr *net.UDPAddr, streamID uint32, frameType, dataType byte) {
+		t.Helper()
+		dmrd := &protocol.DMRDPacket{
+			SourceID:      3120001,
+			DestinationID: 3100,
+			RepeaterID:    111,
+			Timeslot:      1,
+			CallType:      protocol.CallTypeGroup,
+			FrameType:     frameType,
+			DataType:      dataType,
+			StreamID:      streamID,
+			Payload:       make([]byte, 33),
+		}
+		data, err := dmrd.Encode()
+		if err != nil {
+			t.Fatalf("Encode DMRD error: %v", err)
+		}
+		srv.handleDMRD(data, srcAddr)
+	}
+	count := func(t *testing.T, conn *net.UDPConn) int {
+		t.Helper()
+		n := 0
+		buf := make([]byte, 2048)
+		for {
+			_ = conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+			if _, _, err := conn.ReadFromUDP(buf); err != nil {
+				return n
+			}
+			n++
+		}
+	}
+
+	t.Run("held", func(t *testing.T) {
+		srv, conn, srcAddr, expire := setup(t, true)
+
+		send(t, srv, srcAddr, 900, protocol.FrameTypeVoiceHeader, protocol.DataTypeVoiceLCHeader)
+		send(t, srv, srcAddr, 900, protocol.FrameTypeVoice, 0)
+		if got := count(t, conn); got != 2 {
+			t.Fatalf("Expected header and voice before expiry, got %d frames", got)
+		}
+
+		expire()
+		send(t, srv, srcAddr, 900, protocol.FrameTypeVoice, 1)
+		send(t, srv, srcAddr, 900, protocol.FrameTypeVoiceTerminator, protocol.DataTypeTerminatorLC)
+		if got := count(t, conn); got != 2 {
+			t.Fatalf("Expected the rest of the call after expiry, got %d frames", got)
+		}
+
+		send(t, srv, srcAddr, 901, protocol.FrameTypeVoiceHeader, protocol.DataTypeVoiceLCHeader)
+		send(t, srv, srcAddr, 901, protocol.FrameTypeVoice, 0)
+		if got := count(t, conn); got != 0 {
+			t.Fatalf("Expected the next call not to reach the expired peer, got %d frames", got)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		srv, conn, srcAddr, expire := setup(t, false)
+
+		send(t, srv, srcAddr, 900, protocol.FrameTypeVoiceHeader, protocol.DataTypeVoiceLCHeader)
+		if got := count(t, conn); got != 1 {
+			t.Fatalf("Expected the header before expiry, got %d frames", got)
+		}
+
+		expire()
+		send(t, srv, srcAddr, 900, protocol.FrameTypeVoice, 0)
+		if got := count(t, conn); got != 0 {
+			t.Fatalf("Expected the call cut off at expiry, got %d frames", got)
+		}
+	})
+}
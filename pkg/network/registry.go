@@ -0,0 +1,85 @@
+package network
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/peer"
+)
+
+// Registry holds the running MASTER systems by name so other packages (the
+// web API) can act on their peers
+type Registry struct {
+	servers map[string]*Server
+	mu      sync.RWMutex
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{servers: make(map[string]*Server)}
+}
+
+// Register adds a server under its system name, replacing any earlier one
+func (r *Registry) Register(s *Server) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.servers[s.systemName] = s
+}
+
+// Get returns the server for a system, or nil
+func (r *Registry) Get(name string) *Server {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.servers[name]
+}
+
+// DisconnectPeer closes a connected peer on the system it logged in to and
+// returns that system's name. ok is false if no system has the peer
+// connected.
+func (r *Registry) DisconnectPeer(id uint32) (system string, ok bool) {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.servers))
+	for name := range r.servers {
+		names = append(names, name)
+	}
+	r.mu.RUnlock()
+	sort.Strings(names)
+
+	// Systems share the peer manager; only the peer's own one closes it
+	for _, name := range names {
+		if s := r.Get(name); s != nil && s.DisconnectPeer(id) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// DisconnectPeer kicks a connected peer: it is sent MSTCL and removed, the
+// same as if it had sent RPTCL. It returns false if the peer isn't
+// connected to this system. Most repeaters log in again after MSTCL; a peer that should
+// stay out needs blocking in REG_ACL as well.
+func (s *Server) DisconnectPeer(id uint32) bool {
+	defer s.lockHandshake(id)()
+
+	p := s.peerManager.GetPeer(id)
+	if p == nil || p.GetState() != peer.StateConnected || !s.ownsPeer(p) {
+		return false
+	}
+
+	s.log.Info("Disconnecting peer on request",
+		logger.Int("peer_id", int(id)),
+		logger.String("callsign", p.Callsign),
+		logger.String("addr", p.Address.String()))
+
+	if s.getConn() != nil {
+		s.sendMSTCL(id, p.Address)
+	}
+	s.clearSubscriberLocationsForPeer(id)
+	s.peerManager.RemovePeer(id)
+
+	if s.onPeerDisconnected != nil {
+		s.onPeerDisconnected(id)
+	}
+	return true
+}
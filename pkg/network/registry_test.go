@@ -0,0 +1,128 @@
+package network
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/peer"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestRegistry_DisconnectPeer(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(config.SystemConfig{Mode: "MASTER"}, "test-system", log)
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	peerConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = peerConn.Close() }()
+	p := srv.peerManager.AddPeer(312000, peerConn.LocalAddr().(*net.UDPAddr))
+	p.SetSystem("test-system")
+	p.SetConnected()
+	srv.peerManager.AddPeer(312001, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65030}).SetSystem("test-system") // still logging in
+
+	var disconnected []uint32
+	srv.SetPeerEventHandlers(nil, func(id uint32) { disconnected = append(disconnected, id) })
+
+	reg := NewRegistry()
+	reg.Register(srv)
+
+	system, ok := reg.DisconnectPeer(312000)
+	if !ok || system != "test-system" {
+		t.Fatalf("DisconnectPeer = %q, %v; want test-system, true", system, ok)
+	}
+
+	buf := make([]byte, 64)
+	_ = peerConn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	n, _, err := peerConn.ReadFromUDP(buf)
+	if err != nil {
+		t.Fatalf("Expected MSTCL at the peer: %v", err)
+	}
+	cl, err := protocol.ParseMSTCL(buf[:n])
+	if err != nil {
+		t.Fatalf("ParseMSTCL error: %v", err)
+	}
+	if cl.RepeaterID != 312000 {
+		t.Errorf("Expected MSTCL for peer 312000, got %d", cl.RepeaterID)
+	}
+	if srv.peerManager.GetPeer(312000) != nil {
+		t.Error("Expected the peer removed from the manager")
+	}
+	if len(disconnected) != 1 || disconnected[0] != 312000 {
+		t.Errorf("Expected one disconnect event for 312000, got %v", disconnected)
+	}
+
+	// Gone, never connected, and unknown peers are all not found
+	for _, id := range []uint32{312000, 312001, 999} {
+		if _, ok := reg.DisconnectPeer(id); ok {
+			t.Errorf("DisconnectPeer(%d) succeeded, want not found", id)
+		}
+	}
+	if srv.peerManager.GetPeer(312001) == nil {
+		t.Error("A peer still logging in should be left alone")
+	}
+}
+
+func TestRegistry_DisconnectPeerOnOwningSystem(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	shared := peer.NewPeerManager()
+	reg := NewRegistry()
+	servers := make(map[string]*Server)
+	for _, name := range []string{"MASTER-A", "MASTER-B"} {
+		srv := NewServer(config.SystemConfig{Mode: "MASTER"}, name, log).WithPeerManager(shared)
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+		if err != nil {
+			t.Fatalf("ListenUDP error: %v", err)
+		}
+		defer func() { _ = conn.Close() }()
+		srv.conn = conn
+		servers[name] = srv
+		reg.Register(srv)
+	}
+
+	peerConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = peerConn.Close() }()
+	p := shared.AddPeer(312000, peerConn.LocalAddr().(*net.UDPAddr))
+	p.SetSystem("MASTER-B")
+	p.SetConnected()
+
+	// MASTER-A sorts first but doesn't hold the peer
+	if servers["MASTER-A"].DisconnectPeer(312000) {
+		t.Fatal("MASTER-A disconnected a peer logged in to MASTER-B")
+	}
+	system, ok := reg.DisconnectPeer(312000)
+	if !ok || system != "MASTER-B" {
+		t.Fatalf("DisconnectPeer = %q, %v; want MASTER-B, true", system, ok)
+	}
+
+	// The MSTCL comes from the port the peer logged in to
+	buf := make([]byte, 64)
+	_ = peerConn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	n, from, err := peerConn.ReadFromUDP(buf)
+	if err != nil {
+		t.Fatalf("Expected MSTCL at the peer: %v", err)
+	}
+	if _, err := protocol.ParseMSTCL(buf[:n]); err != nil {
+		t.Fatalf("ParseMSTCL error: %v", err)
+	}
+	if want := servers["MASTER-B"].conn.LocalAddr().(*net.UDPAddr); from.Port != want.Port {
+		t.Errorf("MSTCL sent from port %d, want MASTER-B's %d", from.Port, want.Port)
+	}
+	if shared.GetPeer(312000) != nil {
+		t.Error("Expected the peer removed from the manager")
+	}
+}
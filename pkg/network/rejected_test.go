@@ -0,0 +1,79 @@
+package network
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/metrics"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_SilencesPeerIgnoringRejections(t *testing.T) {
+	cfg := config.SystemConfig{Mode: "MASTER", MstNakSilenceAfter: 3}
+	collector := metrics.NewCollector()
+	srv := NewServer(cfg, "test-system", logger.New(logger.Config{Level: "error"})).WithMetrics(collector)
+	// Answer every packet until the peer is silenced
+	srv.mstNakCooldown = 0
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	peerConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = peerConn.Close() }()
+	peerAddr := peerConn.LocalAddr().(*net.UDPAddr)
+
+	ping := make([]byte, protocol.RPTPINGPacketSize)
+	copy(ping, protocol.PacketTypeRPTPING)
+	binary.BigEndian.PutUint32(ping[7:11], 312000)
+	answered := func() bool {
+		srv.handleRPTPING(ping, peerAddr)
+		buf := make([]byte, 64)
+		_ = peerConn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		_, _, err := peerConn.ReadFromUDP(buf)
+		return err == nil
+	}
+
+	// The first rejection and two more pings ignoring it are answered
+	for i := 0; i < 3; i++ {
+		if !answered() {
+			t.Fatalf("Expected MSTNAK for ping %d", i+1)
+		}
+	}
+	if got := collector.GetPersistentUnknownPeers(); got != 0 {
+		t.Fatalf("Expected no persistent unknown peers yet, got %d", got)
+	}
+
+	// The third ignored rejection silences it
+	for i := 0; i < 3; i++ {
+		if answered() {
+			t.Fatalf("Expected silence for ping %d after escalation", i+4)
+		}
+	}
+	if got := collector.GetPersistentUnknownPeers(); got != 1 {
+		t.Errorf("Expected the peer counted once as persistent unknown, got %d", got)
+	}
+
+	// Logging in properly clears the history
+	rptl, err := (&protocol.RPTLPacket{RepeaterID: 312000}).Encode()
+	if err != nil {
+		t.Fatalf("Encode RPTL error: %v", err)
+	}
+	srv.handleRPTL(rptl, peerAddr)
+	srv.rejectedPeersMu.Lock()
+	remaining := len(srv.rejectedPeers)
+	srv.rejectedPeersMu.Unlock()
+	if remaining != 0 {
+		t.Errorf("Expected login to clear the rejection history, %d entries left", remaining)
+	}
+}
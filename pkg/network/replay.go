@@ -0,0 +1,69 @@
+package network
+
+import (
+	"sync"
+	"time"
+)
+
+// replayCacheMax bounds how many recent frames the replay cache remembers.
+// A busy OpenBridge link carries a few hundred frames a second, so this
+// covers several seconds of traffic.
+const replayCacheMax = 8192
+
+// replayKey identifies a frame within a stream
+type replayKey struct {
+	streamID uint32
+	sequence byte
+}
+
+// replaySeen is a remembered frame, in arrival order
+type replaySeen struct {
+	key replayKey
+	at  time.Time
+}
+
+// replayCache remembers recently seen (stream, sequence) pairs so a frame
+// captured off the wire and sent again within the window can be dropped.
+// The window must stay well below the time a stream takes to wrap its 8-bit
+// sequence (about 15s of voice).
+type replayCache struct {
+	window time.Duration
+	max    int
+
+	mu    sync.Mutex
+	seen  map[replayKey]time.Time
+	order []replaySeen
+}
+
+// newReplayCache creates a replay cache with the given window and size bound
+func newReplayCache(window time.Duration, max int) *replayCache {
+	return &replayCache{
+		window: window,
+		max:    max,
+		seen:   make(map[replayKey]time.Time),
+	}
+}
+
+// replayed reports whether a frame was already seen within the window, and
+// otherwise remembers it
+func (c *replayCache) replayed(streamID uint32, sequence byte, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Forget frames that have left the window, and the oldest beyond the bound
+	for len(c.order) > 0 && (now.Sub(c.order[0].at) > c.window || len(c.order) >= c.max) {
+		old := c.order[0]
+		c.order = c.order[1:]
+		if at, ok := c.seen[old.key]; ok && at.Equal(old.at) {
+			delete(c.seen, old.key)
+		}
+	}
+
+	key := replayKey{streamID: streamID, sequence: sequence}
+	if at, ok := c.seen[key]; ok && now.Sub(at) <= c.window {
+		return true
+	}
+	c.seen[key] = now
+	c.order = append(c.order, replaySeen{key: key, at: now})
+	return false
+}
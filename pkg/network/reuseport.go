@@ -0,0 +1,41 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// listenUDP opens a UDP listener on addr. With reusePort set the socket is
+// opened with SO_REUSEPORT, so several processes can bind the same port and
+// the kernel spreads peers across them. Each process keeps its own peer
+// table; the kernel hashes on the source address, so a peer keeps landing
+// on the same process.
+func listenUDP(addr *net.UDPAddr, reusePort bool) (*net.UDPConn, error) {
+	if !reusePort {
+		return net.ListenUDP("udp", addr)
+	}
+	if !reusePortSupported {
+		return nil, fmt.Errorf("reuse_port is not supported on this platform")
+	}
+
+	lc := net.ListenConfig{Control: reusePortControl}
+	pc, err := lc.ListenPacket(context.Background(), "udp", addr.String())
+	if err != nil {
+		return nil, err
+	}
+	return pc.(*net.UDPConn), nil
+}
+
+// listenIP returns the address a system binds to: ip when set, or every
+// IPv4 interface when blank. IPv6 literals such as "::" are accepted.
+func listenIP(ip string) (net.IP, error) {
+	if ip == "" {
+		return net.IPv4zero, nil
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return nil, fmt.Errorf("invalid bind address %q", ip)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,12 @@
+//go:build !linux && !darwin && !freebsd
+
+package network
+
+import "syscall"
+
+const reusePortSupported = false
+
+// reusePortControl is never called where SO_REUSEPORT is unavailable
+func reusePortControl(network, address string, c syscall.RawConn) error {
+	return nil
+}
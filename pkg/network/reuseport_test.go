@@ -0,0 +1,30 @@
+package network
+
+import (
+	"net"
+	"testing"
+)
+
+func TestListenUDP_ReusePort(t *testing.T) {
+	if !reusePortSupported {
+		t.Skip("SO_REUSEPORT not supported on this platform")
+	}
+
+	first, err := listenUDP(&net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0}, true)
+	if err != nil {
+		t.Fatalf("listenUDP error: %v", err)
+	}
+	defer func() { _ = first.Close() }()
+	addr := first.LocalAddr().(*net.UDPAddr)
+
+	second, err := listenUDP(addr, true)
+	if err != nil {
+		t.Fatalf("Expected a second reuse_port listener on %s: %v", addr, err)
+	}
+	defer func() { _ = second.Close() }()
+
+	if conn, err := listenUDP(addr, false); err == nil {
+		_ = conn.Close()
+		t.Fatal("Expected a plain listener on a reused port to fail")
+	}
+}
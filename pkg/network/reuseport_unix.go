@@ -0,0 +1,22 @@
+//go:build linux || darwin || freebsd
+
+package network
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+const reusePortSupported = true
+
+// reusePortControl sets SO_REUSEPORT on a socket before it is bound
+func reusePortControl(network, address string, c syscall.RawConn) error {
+	var sockErr error
+	if err := c.Control(func(fd uintptr) {
+		sockErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+	}); err != nil {
+		return err
+	}
+	return sockErr
+}
@@ -0,0 +1,50 @@
+package network
+
+import "sync/atomic"
+
+// metricSampler counts one in every rate events and scales each sample by
+// rate, so hot per-frame counters cost one atomic add per frame
+type metricSampler struct {
+	rate uint64
+	n    atomic.Uint64
+}
+
+func newMetricSampler(rate int) *metricSampler {
+	if rate < 1 {
+		rate = 1
+	}
+	return &metricSampler{rate: uint64(rate)}
+}
+
+// sample reports whether this event is counted and how many events it stands for
+func (m *metricSampler) sample() (uint64, bool) {
+	if m.rate == 1 {
+		return 1, true
+	}
+	if m.n.Add(1)%m.rate != 0 {
+		return 0, false
+	}
+	return m.rate, true
+}
+
+// countDMRDReceived records a received DMRD frame, sampled
+func (s *Server) countDMRDReceived(size int) {
+	if s.metrics == nil {
+		return
+	}
+	if scale, ok := s.rxSampler.sample(); ok {
+		s.metrics.PacketsReceived(scale)
+		s.metrics.BytesReceived(uint64(size) * scale)
+	}
+}
+
+// countDMRDSent records a forwarded DMRD frame, sampled
+func (s *Server) countDMRDSent(size int) {
+	if s.metrics == nil {
+		return
+	}
+	if scale, ok := s.txSampler.sample(); ok {
+		s.metrics.PacketsSent(scale)
+		s.metrics.BytesSent(uint64(size) * scale)
+	}
+}
@@ -0,0 +1,142 @@
+package network
+
+import (
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/metrics"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_DMRDMetricsSampling(t *testing.T) {
+	const rate, frames = 10, 1003
+
+	collector := metrics.NewCollector()
+	cfg := config.SystemConfig{Mode: "MASTER", MetricsSampleRate: rate}
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(cfg, "test-system", log).
+		WithRouter(bridge.NewRouter()).
+		WithMetrics(collector)
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	// The listener's frames are written to a socket nobody reads
+	listenConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = listenConn.Close() }()
+	listener := srv.peerManager.AddPeer(222, listenConn.LocalAddr().(*net.UDPAddr))
+	listener.SetConnected()
+	listener.Subscriptions.AddDynamic(3100, 1)
+
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65016}
+	source := srv.peerManager.AddPeer(111, srcAddr)
+	source.SetConnected()
+	source.Subscriptions.AddDynamic(3100, 1)
+
+	var size int
+	for i := 0; i < frames; i++ {
+		ft := byte(protocol.FrameTypeVoice)
+		if i == 0 {
+			ft = protocol.FrameTypeVoiceHeader
+		}
+		dmrd := &protocol.DMRDPacket{
+			Sequence:      byte(i),
+			SourceID:      3120001,
+			DestinationID: 3100,
+			RepeaterID:    111,
+			Timeslot:      1,
+			CallType:      protocol.CallTypeGroup,
+			FrameType:     ft,
+			DataType:      byte(i % 6),
+			StreamID:      1,
+			Payload:       make([]byte, 33),
+		}
+		data, err := dmrd.Encode()
+		if err != nil {
+			t.Fatalf("Encode DMRD error: %v", err)
+		}
+		size = len(data)
+		srv.handleDMRD(data, srcAddr)
+	}
+
+	// Each sample stands for rate frames, so totals are within one sample of exact
+	within := func(name string, got, want uint64, slack uint64) {
+		t.Helper()
+		if got > want || want-got >= slack {
+			t.Errorf("%s: got %d, want %d (within %d)", name, got, want, slack)
+		}
+	}
+	within("packets received", collector.GetPacketsReceived(), frames, rate)
+	within("bytes received", collector.GetBytesReceived(), frames*uint64(size), rate*uint64(size))
+	within("packets sent", collector.GetPacketsSent(), frames, rate)
+	within("bytes sent", collector.GetBytesSent(), frames*uint64(size), rate*uint64(size))
+
+	// Only the samples were counted: totals are whole multiples of the rate
+	if collector.GetPacketsReceived()%rate != 0 {
+		t.Errorf("Expected sampled count to be a multiple of %d, got %d", rate, collector.GetPacketsReceived())
+	}
+}
+
+func TestServer_PacketSizeHistogram(t *testing.T) {
+	collector := metrics.NewCollector()
+	cfg := config.SystemConfig{Mode: "MASTER", Passphrase: "test"}
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(cfg, "test-system", log).WithMetrics(collector)
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+	addr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65040}
+
+	rptl, err := (&protocol.RPTLPacket{RepeaterID: 312000}).Encode()
+	if err != nil {
+		t.Fatalf("Encode RPTL error: %v", err)
+	}
+	dmrd, err := (&protocol.DMRDPacket{RepeaterID: 312000, Payload: make([]byte, 33)}).Encode()
+	if err != nil {
+		t.Fatalf("Encode DMRD error: %v", err)
+	}
+	for _, data := range [][]byte{
+		rptl,                     // 8
+		dmrd,                     // 53
+		dmrd,                     // 53
+		[]byte("RPTZ1234567890"), // 14, no such packet
+		make([]byte, 1400),       // Oversized
+	} {
+		srv.handlePacket(data, addr)
+	}
+
+	w := httptest.NewRecorder()
+	metrics.NewPrometheusHandler(collector).ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body := w.Body.String()
+	for _, want := range []string{
+		"# TYPE dmr_packet_size_bytes histogram",
+		`dmr_packet_size_bytes_bucket{le="8"} 1`,
+		`dmr_packet_size_bytes_bucket{le="11"} 1`,
+		`dmr_packet_size_bytes_bucket{le="40"} 2`,
+		`dmr_packet_size_bytes_bucket{le="53"} 4`,
+		`dmr_packet_size_bytes_bucket{le="512"} 4`,
+		`dmr_packet_size_bytes_bucket{le="+Inf"} 5`,
+		"dmr_packet_size_bytes_sum 1528",
+		"dmr_packet_size_bytes_count 5",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected %q in metrics output", want)
+		}
+	}
+}
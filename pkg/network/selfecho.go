@@ -0,0 +1,63 @@
+package network
+
+import (
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/peer"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+// selfEcho tracks a stream being sent back to the peer transmitting it
+type selfEcho struct {
+	last  byte
+	ended bool
+	at    time.Time
+}
+
+// echoesOwnTraffic reports whether a peer gets its own traffic back, from
+// echo_peers or its OPTIONS
+func (s *Server) echoesOwnTraffic(p *peer.Peer) bool {
+	return s.echoPeers[p.ID] || p.GetEchoOwn()
+}
+
+// echoOwnTraffic sends a frame back to the peer that transmitted it, for
+// simulcast and voting receivers. Each frame is echoed once: a frame the
+// peer reflects back, which does not advance the stream's sequence or
+// arrives after its terminator, is not echoed again, so a peer looping its
+// input to its output cannot ping-pong a stream with the server.
+func (s *Server) echoOwnTraffic(dmrd *protocol.DMRDPacket, data []byte, p *peer.Peer) {
+	if !s.echoesOwnTraffic(p) || p.GetState() != peer.StateConnected {
+		return
+	}
+
+	s.selfEchoesMu.Lock()
+	echo, ok := s.selfEchoes[dmrd.StreamID]
+	if ok {
+		step := dmrd.Sequence - echo.last
+		if echo.ended || step == 0 || step > 128 {
+			s.selfEchoesMu.Unlock()
+			return
+		}
+	} else {
+		echo = &selfEcho{}
+		s.selfEchoes[dmrd.StreamID] = echo
+	}
+	echo.last = dmrd.Sequence
+	echo.ended = dmrd.IsTerminator()
+	echo.at = time.Now()
+	s.selfEchoesMu.Unlock()
+
+	s.sendToPeer(p, data)
+}
+
+// cleanupSelfEchoes forgets echoed streams once they have been idle for the
+// mute window, including ended ones kept to ignore reflected terminators
+func (s *Server) cleanupSelfEchoes(now time.Time) {
+	s.selfEchoesMu.Lock()
+	defer s.selfEchoesMu.Unlock()
+	for streamID, echo := range s.selfEchoes {
+		if now.Sub(echo.at) > s.muteWindow {
+			delete(s.selfEchoes, streamID)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package network
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_EchoOwnTraffic(t *testing.T) {
+	encodeCall := func(t *testing.T, streamID uint32) [][]byte {
+		t.Helper()
+		var frames [][]byte
+		for i, ft := range []byte{protocol.FrameTypeVoiceHeader, protocol.FrameTypeVoice, protocol.FrameTypeVoiceTerminator} {
+			dmrd := &protocol.DMRDPacket{
+				Sequence:      byte(i),
+				SourceID:      3120001,
+				DestinationID: 3100,
+				RepeaterID:    111,
+				Timeslot:      1,
+				CallType:      protocol.CallTypeGroup,
+				FrameType:     ft,
+				StreamID:      streamID,
+				Payload:       make([]byte, 33),
+			}
+			if ft == protocol.FrameTypeVoiceTerminator {
+				dmrd.DataType = protocol.DataTypeTerminatorLC
+			}
+			data, err := dmrd.Encode()
+			if err != nil {
+				t.Fatalf("Encode DMRD error: %v", err)
+			}
+			frames = append(frames, data)
+		}
+		return frames
+	}
+
+	countReceived := func(conn *net.UDPConn) int {
+		n := 0
+		buf := make([]byte, 512)
+		for {
+			_ = conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+			if _, err := conn.Read(buf); err != nil {
+				return n
+			}
+			n++
+		}
+	}
+
+	tests := []struct {
+		name      string
+		echoPeers []int
+		options   bool // Peer asks for its own traffic with OPTIONS ECHO=1
+		want      int
+	}{
+		{"not requested", nil, false, 0},
+		{"echo_peers", []int{111}, false, 3},
+		{"OPTIONS ECHO=1", nil, true, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.SystemConfig{Mode: "MASTER", EchoPeers: tt.echoPeers}
+			srv := NewServer(cfg, "test-system", logger.New(logger.Config{Level: "error"})).WithRouter(bridge.NewRouter())
+
+			serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+			if err != nil {
+				t.Fatalf("ListenUDP error: %v", err)
+			}
+			srv.conn = serverConn
+			defer func() { _ = serverConn.Close() }()
+
+			peerConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+			if err != nil {
+				t.Fatalf("ListenUDP error: %v", err)
+			}
+			defer func() { _ = peerConn.Close() }()
+			peerAddr := peerConn.LocalAddr().(*net.UDPAddr)
+			source := srv.peerManager.AddPeer(111, peerAddr)
+			source.SetConnected()
+			source.Subscriptions.AddDynamic(3100, 1)
+			source.SetEchoOwn(tt.options)
+
+			frames := encodeCall(t, 0xEC0)
+			for _, data := range frames {
+				srv.handleDMRD(data, peerAddr)
+			}
+			if got := countReceived(peerConn); got != tt.want {
+				t.Fatalf("Expected %d frames echoed back, got %d", tt.want, got)
+			}
+
+			// A peer reflecting the echo back must not set up a loop
+			for _, data := range frames {
+				srv.handleDMRD(data, peerAddr)
+			}
+			if got := countReceived(peerConn); got != 0 {
+				t.Errorf("Expected reflected frames not to be echoed again, got %d", got)
+			}
+		})
+	}
+}
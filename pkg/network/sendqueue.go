@@ -0,0 +1,114 @@
+package network
+
+import (
+	"net"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/peer"
+)
+
+// sendQueueIdleTimeout is how long a peer's writer waits for work before exiting
+const sendQueueIdleTimeout = 30 * time.Second
+
+// queuedPacket is a forwarded frame waiting for a peer's writer
+type queuedPacket struct {
+	peer *peer.Peer
+	addr *net.UDPAddr
+	data []byte
+}
+
+// peerSendQueue is one peer's bounded outbound queue
+type peerSendQueue struct {
+	packets chan queuedPacket
+	done    chan struct{} // Closed to stop the writer without draining
+}
+
+// sendToPeer forwards data to a peer. Without send queues the write happens
+// inline; with them the frame is copied onto the peer's bounded queue and
+// written by that peer's own goroutine, so one slow peer can't stall the
+// handler. Returns false if the frame was dropped because the queue is full.
+func (s *Server) sendToPeer(p *peer.Peer, data []byte) bool {
+	if s.sendQueueSize <= 0 {
+		s.writeToPeer(p, p.Address, data)
+		return true
+	}
+
+	pkt := queuedPacket{peer: p, addr: p.Address, data: append([]byte(nil), data...)}
+
+	s.sendQueuesMu.Lock()
+	defer s.sendQueuesMu.Unlock()
+
+	queue, ok := s.sendQueues[p.ID]
+	if !ok {
+		queue = &peerSendQueue{
+			packets: make(chan queuedPacket, s.sendQueueSize),
+			done:    make(chan struct{}),
+		}
+		s.sendQueues[p.ID] = queue
+		go s.sendQueueWriter(p.ID, queue)
+	}
+
+	select {
+	case queue.packets <- pkt:
+		return true
+	default:
+		if s.metrics != nil {
+			s.metrics.PacketDropped("send_queue_full")
+		}
+		s.log.Debug("Peer send queue full, frame dropped",
+			logger.Int("peer_id", int(p.ID)))
+		return false
+	}
+}
+
+// sendQueueWriter drains one peer's queue and exits once it has been idle
+func (s *Server) sendQueueWriter(peerID uint32, queue *peerSendQueue) {
+	idle := time.NewTimer(sendQueueIdleTimeout)
+	defer idle.Stop()
+
+	for {
+		select {
+		case <-queue.done:
+			return
+		case pkt := <-queue.packets:
+			s.writeToPeer(pkt.peer, pkt.addr, pkt.data)
+			idle.Reset(sendQueueIdleTimeout)
+		case <-idle.C:
+			// Producers enqueue under the lock, so an empty queue here stays empty
+			s.sendQueuesMu.Lock()
+			if len(queue.packets) == 0 {
+				if s.sendQueues[peerID] == queue {
+					delete(s.sendQueues, peerID)
+				}
+				s.sendQueuesMu.Unlock()
+				return
+			}
+			s.sendQueuesMu.Unlock()
+			idle.Reset(sendQueueIdleTimeout)
+		}
+	}
+}
+
+// closeSendQueues stops all peer writers, discarding anything still queued
+func (s *Server) closeSendQueues() {
+	s.sendQueuesMu.Lock()
+	defer s.sendQueuesMu.Unlock()
+	for peerID, queue := range s.sendQueues {
+		close(queue.done)
+		delete(s.sendQueues, peerID)
+	}
+}
+
+// writeToPeer writes a forwarded frame and updates the peer's counters
+func (s *Server) writeToPeer(p *peer.Peer, addr *net.UDPAddr, data []byte) {
+	if _, err := s.connFor(addr).WriteToUDP(data, addr); err != nil {
+		s.log.Error("Failed to forward DMRD",
+			logger.Int("peer_id", int(p.ID)),
+			logger.Error(err))
+		return
+	}
+	p.IncrementPacketsSent()
+	p.AddBytesSent(uint64(len(data)))
+	s.countDMRDSent(len(data))
+}
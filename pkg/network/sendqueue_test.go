@@ -0,0 +1,92 @@
+package network
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/metrics"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+// slowConn delays every write to one address to simulate a blocked peer
+type slowConn struct {
+	*net.UDPConn
+	slow  string
+	delay time.Duration
+}
+
+func (c *slowConn) WriteToUDP(b []byte, addr *net.UDPAddr) (int, error) {
+	if addr.String() == c.slow {
+		time.Sleep(c.delay)
+	}
+	return c.UDPConn.WriteToUDP(b, addr)
+}
+
+func TestServer_PeerSendQueueIsolatesSlowPeer(t *testing.T) {
+	cfg := config.SystemConfig{Mode: "MASTER", Repeat: true, PeerSendQueue: 4}
+	log := logger.New(logger.Config{Level: "error"})
+	collector := metrics.NewCollector()
+	srv := NewServer(cfg, "test-system", log).WithMetrics(collector)
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = serverConn.Close() }()
+
+	fastConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = fastConn.Close() }()
+	slowAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65009}
+
+	srv.conn = &slowConn{UDPConn: serverConn, slow: slowAddr.String(), delay: 200 * time.Millisecond}
+	defer srv.closeSendQueues()
+	srv.peerManager.AddPeer(222, fastConn.LocalAddr().(*net.UDPAddr)).SetConnected()
+	srv.peerManager.AddPeer(333, slowAddr).SetConnected()
+
+	// Frames arrive paced as on air; the slow peer's queue of 4 overflows
+	const frames = 10
+	for i := 0; i < frames; i++ {
+		dmrd := &protocol.DMRDPacket{
+			Sequence:      byte(i),
+			SourceID:      3120001,
+			DestinationID: 3100,
+			RepeaterID:    111,
+			Timeslot:      1,
+			FrameType:     protocol.FrameTypeVoice,
+			StreamID:      42,
+			Payload:       make([]byte, 33),
+		}
+		data, err := dmrd.Encode()
+		if err != nil {
+			t.Fatalf("Encode DMRD error: %v", err)
+		}
+		start := time.Now()
+		srv.forwardDMRD(dmrd, data, 111)
+		if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+			t.Errorf("Forwarding frame %d stalled behind slow peer: %v", i, elapsed)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// The fast peer gets every frame promptly
+	buf := make([]byte, 2048)
+	for i := 0; i < frames; i++ {
+		if err := fastConn.SetReadDeadline(time.Now().Add(100 * time.Millisecond)); err != nil {
+			t.Fatalf("SetReadDeadline error: %v", err)
+		}
+		if _, _, err := fastConn.ReadFromUDP(buf); err != nil {
+			t.Fatalf("Fast peer missed frame %d: %v", i, err)
+		}
+	}
+
+	// The slow peer's queue overflowed and the excess was metered
+	if dropped := collector.GetPacketsDropped("send_queue_full"); dropped == 0 {
+		t.Error("Expected frames dropped for the slow peer's full queue")
+	}
+}
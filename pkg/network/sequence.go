@@ -0,0 +1,67 @@
+package network
+
+import (
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+// streamSequence is the last in-order sequence number seen on a stream
+type streamSequence struct {
+	last byte
+	at   time.Time
+}
+
+// sequenceGap returns how many frames are missing between two sequence
+// numbers of a stream, prev then next. The sequence is a byte that wraps
+// from 255 to 0 within long transmissions, so the distance is taken modulo
+// 256: 255 then 0 is consecutive. A step of more than half the range is a
+// late or repeated frame rather than loss and counts as no gap.
+func sequenceGap(prev, next byte) int {
+	step := next - prev
+	if step == 0 || step > 128 {
+		return 0
+	}
+	return int(step) - 1
+}
+
+// trackSequence counts frames lost from an inbound stream by the gaps in its
+// sequence numbers
+func (s *Server) trackSequence(dmrd *protocol.DMRDPacket) {
+	now := time.Now()
+
+	s.sequencesMu.Lock()
+	seq, ok := s.sequences[dmrd.StreamID]
+	if !ok {
+		seq = &streamSequence{last: dmrd.Sequence}
+		s.sequences[dmrd.StreamID] = seq
+	}
+	seq.at = now
+
+	lost := 0
+	if ok {
+		lost = sequenceGap(seq.last, dmrd.Sequence)
+		if step := dmrd.Sequence - seq.last; step != 0 && step <= 128 {
+			seq.last = dmrd.Sequence
+		}
+	}
+	if dmrd.IsTerminator() {
+		delete(s.sequences, dmrd.StreamID)
+	}
+	s.sequencesMu.Unlock()
+
+	if lost > 0 && s.metrics != nil {
+		s.metrics.FramesLost(uint64(lost))
+	}
+}
+
+// cleanupSequences forgets streams that ended without a terminator
+func (s *Server) cleanupSequences(now time.Time) {
+	s.sequencesMu.Lock()
+	defer s.sequencesMu.Unlock()
+	for streamID, seq := range s.sequences {
+		if now.Sub(seq.at) > s.muteWindow {
+			delete(s.sequences, streamID)
+		}
+	}
+}
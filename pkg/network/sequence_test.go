@@ -0,0 +1,76 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/metrics"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestSequenceGap(t *testing.T) {
+	tests := []struct {
+		prev, next byte
+		want       int
+	}{
+		{0, 1, 0},
+		{254, 255, 0},
+		{255, 0, 0}, // Wrap
+		{255, 1, 1}, // Frame 0 lost across the wrap
+		{250, 2, 7},
+		{254, 0, 1},
+		{10, 10, 0}, // Repeated
+		{0, 255, 0}, // Late frame from before the wrap
+		{100, 90, 0},
+		{0, 128, 127},
+	}
+	for _, tt := range tests {
+		if got := sequenceGap(tt.prev, tt.next); got != tt.want {
+			t.Errorf("sequenceGap(%d, %d) = %d, want %d", tt.prev, tt.next, got, tt.want)
+		}
+	}
+}
+
+func TestServer_SequenceWrapNoFalseLoss(t *testing.T) {
+	m := metrics.NewCollector()
+	srv := NewServer(config.SystemConfig{Mode: "MASTER"}, "test-system", logger.New(logger.Config{Level: "error"})).
+		WithMetrics(m)
+
+	frame := func(stream uint32, seq byte, frameType uint8) *protocol.DMRDPacket {
+		return &protocol.DMRDPacket{
+			Sequence:      seq,
+			SourceID:      3120001,
+			DestinationID: 9,
+			Timeslot:      1,
+			FrameType:     frameType,
+			StreamID:      stream,
+		}
+	}
+
+	// A long transmission: 600 frames, wrapping twice
+	for i := 0; i < 600; i++ {
+		srv.trackSequence(frame(1, byte(i), protocol.FrameTypeVoice))
+	}
+	if lost := m.GetFramesLost(); lost != 0 {
+		t.Fatalf("Expected no loss across the sequence wrap, got %d", lost)
+	}
+
+	// Two frames lost either side of the wrap, then a late and a repeated frame
+	for _, seq := range []byte{253, 254, 1, 2, 0, 2, 3} {
+		srv.trackSequence(frame(2, seq, protocol.FrameTypeVoice))
+	}
+	if lost := m.GetFramesLost(); lost != 2 {
+		t.Errorf("Expected 2 frames lost, got %d", lost)
+	}
+
+	term := frame(2, 4, protocol.FrameTypeVoiceTerminator)
+	term.DataType = protocol.DataTypeTerminatorLC
+	srv.trackSequence(term)
+	srv.sequencesMu.Lock()
+	_, tracked := srv.sequences[2]
+	srv.sequencesMu.Unlock()
+	if tracked {
+		t.Error("Expected the stream to be forgotten after its terminator")
+	}
+}
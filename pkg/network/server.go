@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"net"
 	"sync"
@@ -11,57 +12,237 @@ import (
 
 	"github.com/dbehnke/dmr-nexus/pkg/bridge"
 	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/database"
 	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/metrics"
 	"github.com/dbehnke/dmr-nexus/pkg/peer"
+	"github.com/dbehnke/dmr-nexus/pkg/privacy"
 	"github.com/dbehnke/dmr-nexus/pkg/protocol"
 )
 
-// rejectedPeer tracks a peer that was rejected with MSTNAK
+// defaultMaxConsecutiveReadErrors is the number of back-to-back non-timeout
+// read errors after which the UDP listener is closed and re-opened.
+const defaultMaxConsecutiveReadErrors = 10
+
+// udpConn is the subset of *net.UDPConn used by the server. It exists so
+// tests can inject a connection that fails reads.
+type udpConn interface {
+	ReadFromUDP(b []byte) (int, *net.UDPAddr, error)
+	WriteToUDP(b []byte, addr *net.UDPAddr) (int, error)
+	SetReadDeadline(t time.Time) error
+	LocalAddr() net.Addr
+	Close() error
+}
+
+// rejectedPeer tracks a peer that was rejected with MSTNAK or MSTCL
 type rejectedPeer struct {
 	peerID     uint32
 	addr       string
 	lastMSTNAK time.Time
+	lastSeen   time.Time // Last packet received from it
+	ignored    int       // Packets received since its first rejection
+	silenced   bool      // Ignored rejections long enough to stop being answered
 }
 
 // Server represents a UDP server for MASTER mode
 type Server struct {
-	config          config.SystemConfig
-	systemName      string // Name of this system (from config key)
-	log             *logger.Logger
-	conn            *net.UDPConn
+	config     config.SystemConfig
+	systemName string // Name of this system (from config key)
+	log        *logger.Logger
+	conn       udpConn
+	connMu     sync.RWMutex
+	// Additional listeners (e.g. a legacy port during migration) and the
+	// socket each remote address last arrived on, so replies leave the same way
+	extraConns      []udpConn
+	replyConns      map[string]*replyConn
 	peerManager     *peer.PeerManager
 	router          *bridge.Router
+	metrics         *metrics.Collector
 	pingTimeout     time.Duration
 	cleanupInterval time.Duration
+	muteWindow      time.Duration // Idle time before a muted or held stream is released
+	locationTTL     time.Duration // How long a radio's last-heard peer is kept for private calls
 	regACL          *peer.ACL
 	subACL          *peer.ACL
 	tg1ACL          *peer.ACL
 	tg2ACL          *peer.ACL
+	privateCallACL  *peer.ACL
+	tapACL          *peer.ACL // Talkgroups mirrored to the tap
 	// started is closed once the UDP listener is bound and ready
 	started chan struct{}
 
 	// Optional hooks for events
 	onPeerConnected    func(id uint32, callsign string, addr string)
 	onPeerDisconnected func(id uint32)
+	onFirstHeard       func(radioID, dst, peerID uint32)
+	onHeard            func(radioID, dst, peerID uint32)
+	onPosition         func(radioID uint32, report *protocol.LRRPReport)
+	onMessage          func(src, dst uint32, group bool, text string)
+	onTraffic          func(streamID, src, dst uint32, timeslot int, active bool)
+
+	// First-heard-today tracking; nil unless first_heard_greeting is set
+	firstHeard *firstHeard
+
+	// Applied to radio IDs before they are saved; nil passes them through
+	anon *privacy.Anonymizer
 
-	// Mute map: streamID -> expiry of mute (2s idle or until terminator)
+	// Daily airtime per source ID; nil unless daily_airtime_minutes is set
+	airtime           *airtimeBudget
+	airtimeRepo       *database.AirtimeRepository
+	onAirtimeExceeded func(radioID uint32, used time.Duration)
+
+	// Mute map: streamID -> expiry of mute (muteWindow idle or until terminator)
 	mutedStreams   map[uint32]time.Time
 	mutedStreamsMu sync.Mutex
 
+	// Inbound stream sequence tracking for loss metrics: streamID -> last in-order frame
+	sequences   map[uint32]*streamSequence
+	sequencesMu sync.Mutex
+
+	// streamID -> peers sent part of the stream, so terminators only reach
+	// peers that saw it start
+	deliveries   map[uint32]*streamDelivery
+	deliveriesMu sync.Mutex
+
+	// Key-up guard: after a terminator on a TG, key-ups from other sources are
+	// held for keyupGuard so near-simultaneous transmissions don't double
+	keyupGuard      time.Duration
+	lastTerminators map[uint32]lastTerminator // tgid -> most recent end of transmission
+	heldStreams     map[uint32]time.Time      // streamID -> expiry (muteWindow idle or until terminator)
+	keyupGuardMu    sync.Mutex
+
+	// Caller announcements: streamID -> caller frames held while the clip plays
+	announceClips    *AnnounceClips
+	callsignLookup   func(radioID uint32) string
+	announcing       map[uint32]*callerAnnouncement
+	announceInterval time.Duration
+	announceMu       sync.Mutex
+	summaryDelay     time.Duration
+
+	// Source ID check against the user database: radioID -> cached answer
+	userRepo    *database.DMRUserRepository
+	sourceIDs   map[uint32]sourceIDEntry
+	sourceIDsMu sync.Mutex
+
+	// Unknown talkgroups already logged for discovery
+	unknownTGs   map[uint32]bool
+	unknownTGsMu sync.Mutex
+
+	// Streams whose unavailable bridge targets were reported: streamID ->
+	// last voice sync
+	unavailableReported   map[uint32]time.Time
+	unavailableReportedMu sync.Mutex
+
+	// DMRD packet/byte metrics count 1 in N frames (scaled by N)
+	rxSampler *metricSampler
+	txSampler *metricSampler
+
+	// Received packets are handled by packetWorkers workers, one queue each
+	// (0 = a goroutine per packet)
+	packetWorkers int
+	packetQueues  []chan packetJob
+
+	// Per-peer outbound queues (0 = write inline): peerID -> queue
+	sendQueueSize int
+	sendQueues    map[uint32]*peerSendQueue
+	sendQueuesMu  sync.Mutex
+
 	// Subscriber location tracking for private calls: radioID -> subscriberLocation
-	subscriberLocations   map[uint32]*subscriberLocation
-	subscriberLocationsMu sync.RWMutex
+	subscriberLocations *subscriberLocations
 
 	// Track rejected peers to avoid sending repeated MSTNAK
 	rejectedPeers   map[string]*rejectedPeer // key: "peerID:addr"
 	rejectedPeersMu sync.Mutex
 	mstNakCooldown  time.Duration
+	silenceAfter    int           // Packets a rejected peer may send before it is silenced; 0 never
+	silenceCooldown time.Duration // Cooldown between rejections of a silenced peer
+
+	// Maximum streams a single peer may transmit at once (one per timeslot)
+	maxStreamsPerPeer int
+
+	// Talker hold: tgid -> how long a transmission must last before it
+	// counts toward bridge activation; streamID -> transmission in progress
+	talkerHolds       map[uint32]time.Duration
+	talkerHoldStreams map[uint32]*talkerHoldStream
+	talkerHoldMu      sync.Mutex
+
+	// Echo test talkgroup: streamID -> transmission being recorded for
+	// playback to its own peer
+	echoes    map[uint32]*echoRecording
+	echoDelay time.Duration
+	echoMu    sync.Mutex
+
+	// Encrypted streams being dropped: streamID -> last frame seen
+	encryptedStreams map[uint32]time.Time
+	encryptedMu      sync.Mutex
+
+	// Call time limits: tgid -> per-talkgroup override of max_call_seconds
+	// (0 = unlimited); streamID -> group call being timed
+	callLimits  map[uint32]time.Duration
+	timedCalls  map[uint32]*timedCall
+	timedCallMu sync.Mutex
+
+	// Color code rewrites on forward: tgid -> color code
+	tgColorCodes map[uint32]uint8
+
+	// Last latency alarm warning, for spacing them out
+	lastLatencyWarn time.Time
+	latencyWarnMu   sync.Mutex
+
+	// Packet data calls being reassembled for position reports and text
+	// messages: streamID -> call
+	dataCalls  map[uint32]*dataCall
+	dataCallMu sync.Mutex
+
+	// Transmissions in progress, for the heard and traffic handlers:
+	// streamID -> stream
+	trafficStreams map[uint32]*trafficStream
+	trafficMu      sync.Mutex
+
+	// Slot contention: a receiving peer's timeslot -> the stream it carries
+	slots   map[slotKey]*slotOwner
+	slotsMu sync.Mutex
+
+	// Handshake packets are serialized per repeater ID
+	handshakeLocks handshakeLocks
+
+	// Receive-only talkgroups: tgid -> radio/peer IDs allowed to transmit
+	receiveOnlyTGs map[uint32]map[uint32]bool
+
+	// Per-peer talkgroup whitelists from config; override OPTIONS ALLOW=
+	peerAllowedTGs map[uint32][]uint32
+
+	// Talkgroups any peer may key up on under strict_subscriptions
+	strictAllowedTGs map[uint32]bool
+
+	// Low-bandwidth peers only receive the first voice burst of each superframe
+	lowBandwidthPeers map[uint32]bool
+
+	// Peers sent their own traffic back, and the streams being echoed to them
+	echoPeers    map[uint32]bool
+	selfEchoes   map[uint32]*selfEcho
+	selfEchoesMu sync.Mutex
+
+	// Optional external authorization on RPTC. Peers given forced OPTIONS
+	// by the webhook have their own RPTO ignored.
+	authWebhook     *AuthWebhook
+	forcedOptions   map[uint32]bool
+	forcedOptionsMu sync.RWMutex
+
+	// UDP socket health: listen re-opens the listener after
+	// maxConsecutiveReadErrors back-to-back read failures
+	listen                   func() (udpConn, error)
+	maxConsecutiveReadErrors int
+	consecutiveReadErrors    int
+	readErrors               uint64
+	rebinds                  uint64
+	socketStatsMu            sync.RWMutex
 }
 
-// subscriberLocation tracks where a subscriber (radio) was last seen
-type subscriberLocation struct {
-	peerID   uint32    // Which peer the subscriber is behind
-	lastSeen time.Time // When we last saw traffic from this subscriber
+// replyConn records which listener a remote address was last heard on
+type replyConn struct {
+	conn     udpConn
+	lastSeen time.Time
 }
 
 // CleanupMutedStreamsOnce runs a single cleanup pass for mutedStreams (for testing)
@@ -83,18 +264,163 @@ func NewServer(cfg config.SystemConfig, systemName string, log *logger.Logger) *
 		cooldown = time.Duration(cfg.MstNakCooldown) * time.Second
 	}
 
+	// A rejected peer ignoring its rejections is silenced after 20 packets
+	silenceAfter := 20
+	switch {
+	case cfg.MstNakSilenceAfter > 0:
+		silenceAfter = cfg.MstNakSilenceAfter
+	case cfg.MstNakSilenceAfter < 0:
+		silenceAfter = 0
+	}
+	silenceCooldown := time.Hour
+	if cfg.MstNakSilence > 0 {
+		silenceCooldown = time.Duration(cfg.MstNakSilence) * time.Second
+	}
+
+	pingTimeout := 30 * time.Second
+	if cfg.PingTimeout > 0 {
+		pingTimeout = time.Duration(cfg.PingTimeout) * time.Second
+	}
+	cleanupInterval := 10 * time.Second
+	if cfg.CleanupInterval > 0 {
+		cleanupInterval = time.Duration(cfg.CleanupInterval) * time.Second
+	}
+	muteWindow := 2 * time.Second
+	if cfg.MuteWindowMs > 0 {
+		muteWindow = time.Duration(cfg.MuteWindowMs) * time.Millisecond
+	}
+	locationTTL := 15 * time.Minute
+	if cfg.SubscriberLocationTTL > 0 {
+		locationTTL = time.Duration(cfg.SubscriberLocationTTL) * time.Second
+	}
+
+	// Default to one stream per timeslot
+	maxStreams := 2
+	if cfg.MaxStreamsPerPeer > 0 {
+		maxStreams = cfg.MaxStreamsPerPeer
+	}
+
+	receiveOnly := make(map[uint32]map[uint32]bool)
+	for _, ro := range cfg.ReceiveOnlyTGs {
+		sources := make(map[uint32]bool, len(ro.Sources))
+		for _, src := range ro.Sources {
+			sources[uint32(src)] = true
+		}
+		receiveOnly[uint32(ro.TGID)] = sources
+	}
+
+	talkerHolds := make(map[uint32]time.Duration, len(cfg.TalkerHoldTGs))
+	for _, th := range cfg.TalkerHoldTGs {
+		talkerHolds[uint32(th.TGID)] = time.Duration(th.MinMs) * time.Millisecond
+	}
+
+	var greeter *firstHeard
+	if cfg.FirstHeardGreeting {
+		greeter = newFirstHeard()
+	}
+
+	var airtime *airtimeBudget
+	if cfg.DailyAirtimeMinutes > 0 {
+		airtime = newAirtimeBudget(time.Duration(cfg.DailyAirtimeMinutes) * time.Minute)
+	}
+
+	callLimits := make(map[uint32]time.Duration, len(cfg.CallLimits))
+	for _, cl := range cfg.CallLimits {
+		callLimits[uint32(cl.TGID)] = time.Duration(cl.MaxSeconds) * time.Second
+	}
+
+	tgColorCodes := make(map[uint32]uint8, len(cfg.TGColorCodes))
+	for _, tc := range cfg.TGColorCodes {
+		tgColorCodes[uint32(tc.TGID)] = uint8(tc.ColorCode)
+	}
+
+	strictAllowed := make(map[uint32]bool, len(cfg.StrictAllowedTGs))
+	for _, tg := range cfg.StrictAllowedTGs {
+		strictAllowed[uint32(tg)] = true
+	}
+
+	peerAllowed := make(map[uint32][]uint32)
+	for _, pa := range cfg.PeerAllowedTGs {
+		tgs := make([]uint32, 0, len(pa.TGs))
+		for _, tg := range pa.TGs {
+			tgs = append(tgs, uint32(tg))
+		}
+		peerAllowed[uint32(pa.PeerID)] = tgs
+	}
+
+	lowBandwidth := make(map[uint32]bool, len(cfg.LowBandwidthPeers))
+	for _, id := range cfg.LowBandwidthPeers {
+		lowBandwidth[uint32(id)] = true
+	}
+
+	echoPeers := make(map[uint32]bool, len(cfg.EchoPeers))
+	for _, id := range cfg.EchoPeers {
+		echoPeers[uint32(id)] = true
+	}
+
+	var authWebhook *AuthWebhook
+	if cfg.AuthWebhook != "" {
+		authWebhook = NewAuthWebhook(cfg.AuthWebhook,
+			time.Duration(cfg.AuthWebhookTimeout)*time.Second,
+			time.Duration(cfg.AuthWebhookCacheTTL)*time.Second)
+	}
+
 	return &Server{
 		config:              cfg,
 		systemName:          systemName,
-		log:                 log.WithComponent("network.server"),
+		log:                 log.WithComponent("network.server").With(logger.String("system", systemName)),
 		peerManager:         peer.NewPeerManager(),
-		pingTimeout:         30 * time.Second, // Default timeout
-		cleanupInterval:     10 * time.Second, // Default cleanup interval
+		pingTimeout:         pingTimeout,
+		cleanupInterval:     cleanupInterval,
+		muteWindow:          muteWindow,
+		locationTTL:         locationTTL,
 		started:             make(chan struct{}),
 		mutedStreams:        make(map[uint32]time.Time),
-		subscriberLocations: make(map[uint32]*subscriberLocation),
+		deliveries:          make(map[uint32]*streamDelivery),
+		sequences:           make(map[uint32]*streamSequence),
+		keyupGuard:          time.Duration(cfg.KeyupGuardMs) * time.Millisecond,
+		lastTerminators:     make(map[uint32]lastTerminator),
+		heldStreams:         make(map[uint32]time.Time),
+		announcing:          make(map[uint32]*callerAnnouncement),
+		unknownTGs:          make(map[uint32]bool),
+		unavailableReported: make(map[uint32]time.Time),
+		sourceIDs:           make(map[uint32]sourceIDEntry),
+		announceInterval:    announceFrameInterval,
+		summaryDelay:        subscriptionSummaryDelay,
+		rxSampler:           newMetricSampler(cfg.MetricsSampleRate),
+		txSampler:           newMetricSampler(cfg.MetricsSampleRate),
+		sendQueueSize:       cfg.PeerSendQueue,
+		sendQueues:          make(map[uint32]*peerSendQueue),
+		subscriberLocations: newSubscriberLocations(),
+		replyConns:          make(map[string]*replyConn),
 		rejectedPeers:       make(map[string]*rejectedPeer),
 		mstNakCooldown:      cooldown,
+		silenceAfter:        silenceAfter,
+		silenceCooldown:     silenceCooldown,
+		maxStreamsPerPeer:   maxStreams,
+		receiveOnlyTGs:      receiveOnly,
+		talkerHolds:         talkerHolds,
+		talkerHoldStreams:   make(map[uint32]*talkerHoldStream),
+		echoes:              make(map[uint32]*echoRecording),
+		echoDelay:           echoReplayDelay,
+		encryptedStreams:    make(map[uint32]time.Time),
+		callLimits:          callLimits,
+		tgColorCodes:        tgColorCodes,
+		firstHeard:          greeter,
+		airtime:             airtime,
+		timedCalls:          make(map[uint32]*timedCall),
+		dataCalls:           make(map[uint32]*dataCall),
+		trafficStreams:      make(map[uint32]*trafficStream),
+		slots:               make(map[slotKey]*slotOwner),
+		peerAllowedTGs:      peerAllowed,
+		strictAllowedTGs:    strictAllowed,
+		authWebhook:         authWebhook,
+		lowBandwidthPeers:   lowBandwidth,
+		echoPeers:           echoPeers,
+		selfEchoes:          make(map[uint32]*selfEcho),
+		forcedOptions:       make(map[uint32]bool),
+
+		maxConsecutiveReadErrors: defaultMaxConsecutiveReadErrors,
 	}
 }
 
@@ -109,9 +435,27 @@ func (s *Server) WithPeerManager(pm *peer.PeerManager) *Server {
 	return s
 }
 
-// WithRouter injects a bridge router for routing packets between systems
+// WithRouter injects a bridge router for routing packets between systems.
+// The server registers itself with the router so other systems can deliver
+// bridged traffic to it.
 func (s *Server) WithRouter(r *bridge.Router) *Server {
 	s.router = r
+	r.RegisterMasterSystem(s.systemName, s.deliverBridged)
+	r.SetSystemAvailability(s.systemName, s.hasConnectedPeers)
+	r.SetTransmissionLogging(s.systemName, s.config.LogTransmissions)
+	return s
+}
+
+// WithMetrics injects a metrics collector for socket and traffic counters
+func (s *Server) WithMetrics(m *metrics.Collector) *Server {
+	s.metrics = m
+	return s
+}
+
+// WithAnonymizer anonymizes the radio IDs the server saves (daily airtime)
+// when privacy mode is on
+func (s *Server) WithAnonymizer(anon *privacy.Anonymizer) *Server {
+	s.anon = anon
 	return s
 }
 
@@ -156,41 +500,97 @@ func (s *Server) Start(ctx context.Context) error {
 			}
 			s.tg2ACL = acl
 		}
+
+		if s.config.PrivateCallACL != "" {
+			acl, err := peer.ParseACL(s.config.PrivateCallACL)
+			if err != nil {
+				return fmt.Errorf("failed to parse PRIVATE_CALL_ACL: %w", err)
+			}
+			s.privateCallACL = acl
+		}
+
+		if s.config.TapACL != "" {
+			acl, err := peer.ParseACL(s.config.TapACL)
+			if err != nil {
+				return fmt.Errorf("failed to parse TAP_ACL: %w", err)
+			}
+			s.tapACL = acl
+		}
 	}
 
 	// Create local UDP address
+	bindIP, err := listenIP(s.config.IP)
+	if err != nil {
+		return err
+	}
 	localAddr := &net.UDPAddr{
-		IP:   net.ParseIP("0.0.0.0"),
+		IP:   bindIP,
 		Port: s.config.Port,
 	}
 
 	// Create UDP connection
-	conn, err := net.ListenUDP("udp", localAddr)
+	s.listen = func() (udpConn, error) {
+		return listenUDP(localAddr, s.config.ReusePort)
+	}
+	conn, err := s.listen()
 	if err != nil {
 		return fmt.Errorf("failed to listen on UDP: %w", err)
 	}
-	s.conn = conn
+	s.setConn(conn)
+	defer func() {
+		s.closeSendQueues()
+		_ = s.getConn().Close()
+	}()
+
+	// Bind any additional ports into the same pipeline
+	extraConns := make([]udpConn, 0, len(s.config.ExtraPorts))
+	for _, port := range s.config.ExtraPorts {
+		extra, err := listenUDP(&net.UDPAddr{IP: localAddr.IP, Port: port}, s.config.ReusePort)
+		if err != nil {
+			for _, c := range extraConns {
+				_ = c.Close()
+			}
+			return fmt.Errorf("failed to listen on extra UDP port %d: %w", port, err)
+		}
+		extraConns = append(extraConns, extra)
+	}
+	s.connMu.Lock()
+	s.extraConns = extraConns
+	s.connMu.Unlock()
+	defer func() {
+		for _, c := range extraConns {
+			_ = c.Close()
+		}
+	}()
+
+	s.startPacketWorkers(ctx)
+
 	// Signal that the server is ready to accept packets
 	select {
 	case <-s.started: // already closed
 	default:
 		close(s.started)
 	}
-	defer func() {
-		_ = s.conn.Close()
-	}()
 
 	s.log.Info("Server started",
 		logger.String("addr", conn.LocalAddr().String()),
-		logger.Int("max_peers", s.config.MaxPeers))
+		logger.Int("extra_ports", len(extraConns)),
+		logger.Int("max_peers", s.config.MaxPeers),
+		logger.Int("packet_workers", s.packetWorkers))
 
 	// Start goroutines for receiving and cleanup
-	errChan := make(chan error, 2)
+	errChan := make(chan error, 2+len(extraConns))
 
 	go func() {
 		errChan <- s.receiveLoop(ctx)
 	}()
 
+	for _, extra := range extraConns {
+		go func(c udpConn) {
+			errChan <- s.receiveExtraLoop(ctx, c)
+		}(extra)
+	}
+
 	go func() {
 		errChan <- s.cleanupLoop(ctx)
 	}()
@@ -198,6 +598,7 @@ func (s *Server) Start(ctx context.Context) error {
 	// Wait for context cancellation or error
 	select {
 	case <-ctx.Done():
+		s.drainPeers()
 		return ctx.Err()
 	case err := <-errChan:
 		return err
@@ -216,10 +617,11 @@ func (s *Server) WaitStarted(ctx context.Context) error {
 
 // Addr returns the local UDP address the server is bound to. It should be called after WaitStarted.
 func (s *Server) Addr() (*net.UDPAddr, error) {
-	if s.conn == nil {
+	conn := s.getConn()
+	if conn == nil {
 		return nil, fmt.Errorf("server not started")
 	}
-	addr := s.conn.LocalAddr()
+	addr := conn.LocalAddr()
 	udpAddr, ok := addr.(*net.UDPAddr)
 	if !ok {
 		return nil, fmt.Errorf("not a UDP address")
@@ -238,31 +640,243 @@ func (s *Server) receiveLoop(ctx context.Context) error {
 		default:
 		}
 
+		conn := s.getConn()
+
 		// Set read deadline to allow context checking
-		if err := s.conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond)); err != nil {
+		if err := conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond)); err != nil {
 			s.log.Warn("Failed to set read deadline", logger.Error(err))
-			continue
 		}
-		n, addr, err := s.conn.ReadFromUDP(buffer)
+		n, addr, err := conn.ReadFromUDP(buffer)
 		if err != nil {
 			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
 				continue
 			}
 			s.log.Error("Failed to read from UDP", logger.Error(err))
+			if s.recordReadError() {
+				if err := s.rebind(); err != nil {
+					return err
+				}
+			}
 			continue
 		}
+		s.resetReadErrors()
+		s.trackReplyConn(addr, nil)
+
+		// Process packet on a copy; the buffer is reused by the next read
+		packet := make([]byte, n)
+		copy(packet, buffer[:n])
+		s.dispatchPacket(packet, addr)
+	}
+}
+
+// receiveExtraLoop receives packets on an additional listen port and feeds
+// them into the same handling pipeline as the primary listener
+func (s *Server) receiveExtraLoop(ctx context.Context, conn udpConn) error {
+	buffer := make([]byte, 4096)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		if err := conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond)); err != nil {
+			s.log.Warn("Failed to set read deadline", logger.Error(err))
+		}
+		n, addr, err := conn.ReadFromUDP(buffer)
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				continue
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return fmt.Errorf("extra UDP listener %s closed: %w", conn.LocalAddr(), err)
+			}
+			s.log.Error("Failed to read from UDP",
+				logger.String("listener", conn.LocalAddr().String()),
+				logger.Error(err))
+			continue
+		}
+		s.trackReplyConn(addr, conn)
+
+		// Process packet on a copy; the buffer is reused by the next read
+		packet := make([]byte, n)
+		copy(packet, buffer[:n])
+		s.dispatchPacket(packet, addr)
+	}
+}
+
+// trackReplyConn remembers which listener addr was heard on. A nil conn
+// means the primary listener. Only needed when extra ports are bound.
+func (s *Server) trackReplyConn(addr *net.UDPAddr, conn udpConn) {
+	s.connMu.RLock()
+	multi := len(s.extraConns) > 0
+	s.connMu.RUnlock()
+	if !multi {
+		return
+	}
+
+	key := addr.String()
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	if conn == nil {
+		delete(s.replyConns, key)
+		return
+	}
+	if rc, ok := s.replyConns[key]; ok && rc.conn == conn {
+		rc.lastSeen = time.Now()
+		return
+	}
+	s.replyConns[key] = &replyConn{conn: conn, lastSeen: time.Now()}
+}
+
+// connFor returns the listener to send to addr on: the one addr was last
+// heard on, or the primary listener
+func (s *Server) connFor(addr *net.UDPAddr) udpConn {
+	s.connMu.RLock()
+	defer s.connMu.RUnlock()
+	if addr != nil {
+		if rc, ok := s.replyConns[addr.String()]; ok {
+			return rc.conn
+		}
+	}
+	return s.conn
+}
+
+// cleanupReplyConns forgets listener mappings for addresses not heard from within ttl
+func (s *Server) cleanupReplyConns(ttl time.Duration) {
+	now := time.Now()
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	for key, rc := range s.replyConns {
+		if now.Sub(rc.lastSeen) > ttl {
+			delete(s.replyConns, key)
+		}
+	}
+}
+
+// Addrs returns the local UDP addresses of the primary and any extra listeners
+func (s *Server) Addrs() []*net.UDPAddr {
+	s.connMu.RLock()
+	defer s.connMu.RUnlock()
+	conns := make([]udpConn, 0, 1+len(s.extraConns))
+	if s.conn != nil {
+		conns = append(conns, s.conn)
+	}
+	conns = append(conns, s.extraConns...)
+	addrs := make([]*net.UDPAddr, 0, len(conns))
+	for _, c := range conns {
+		if a, ok := c.LocalAddr().(*net.UDPAddr); ok {
+			addrs = append(addrs, a)
+		}
+	}
+	return addrs
+}
+
+// getConn returns the current UDP connection
+func (s *Server) getConn() udpConn {
+	s.connMu.RLock()
+	defer s.connMu.RUnlock()
+	return s.conn
+}
+
+// setConn replaces the current UDP connection
+func (s *Server) setConn(conn udpConn) {
+	s.connMu.Lock()
+	s.conn = conn
+	s.connMu.Unlock()
+}
+
+// recordReadError counts a non-timeout read error and reports whether the
+// consecutive error threshold has been reached
+func (s *Server) recordReadError() bool {
+	s.socketStatsMu.Lock()
+	s.readErrors++
+	s.consecutiveReadErrors++
+	consecutive := s.consecutiveReadErrors
+	s.socketStatsMu.Unlock()
+
+	if s.metrics != nil {
+		s.metrics.UDPReadError(s.systemName, consecutive)
+	}
+	return s.maxConsecutiveReadErrors > 0 && consecutive >= s.maxConsecutiveReadErrors
+}
 
-		// Process packet
-		go s.handlePacket(buffer[:n], addr)
+// resetReadErrors clears the consecutive read error gauge after a good read
+func (s *Server) resetReadErrors() {
+	s.socketStatsMu.Lock()
+	if s.consecutiveReadErrors == 0 {
+		s.socketStatsMu.Unlock()
+		return
+	}
+	s.consecutiveReadErrors = 0
+	s.socketStatsMu.Unlock()
+
+	if s.metrics != nil {
+		s.metrics.UDPReadRecovered(s.systemName)
 	}
 }
 
+// rebind closes the current UDP listener and opens a new one in its place
+func (s *Server) rebind() error {
+	if s.listen == nil {
+		return fmt.Errorf("UDP listener unhealthy and no rebind function configured")
+	}
+
+	s.log.Warn("Too many consecutive UDP read errors, rebinding listener",
+		logger.Int("threshold", s.maxConsecutiveReadErrors))
+
+	_ = s.getConn().Close()
+	conn, err := s.listen()
+	if err != nil {
+		return fmt.Errorf("failed to rebind UDP listener: %w", err)
+	}
+	s.setConn(conn)
+
+	s.socketStatsMu.Lock()
+	s.rebinds++
+	s.consecutiveReadErrors = 0
+	s.socketStatsMu.Unlock()
+
+	if s.metrics != nil {
+		s.metrics.UDPRebind(s.systemName)
+	}
+
+	s.log.Info("UDP listener rebound",
+		logger.String("addr", conn.LocalAddr().String()))
+	return nil
+}
+
+// ReadErrorCount returns the total number of non-timeout UDP read errors
+func (s *Server) ReadErrorCount() uint64 {
+	s.socketStatsMu.RLock()
+	defer s.socketStatsMu.RUnlock()
+	return s.readErrors
+}
+
+// ConsecutiveReadErrors returns the current run of back-to-back UDP read errors
+func (s *Server) ConsecutiveReadErrors() int {
+	s.socketStatsMu.RLock()
+	defer s.socketStatsMu.RUnlock()
+	return s.consecutiveReadErrors
+}
+
+// RebindCount returns how many times the UDP listener has been re-opened
+func (s *Server) RebindCount() uint64 {
+	s.socketStatsMu.RLock()
+	defer s.socketStatsMu.RUnlock()
+	return s.rebinds
+}
+
 // handlePacket processes a received packet
 func (s *Server) handlePacket(data []byte, addr *net.UDPAddr) {
 	if len(data) == 0 {
 		// Empty UDP packets can happen (spurious wake-ups, etc.) - ignore silently
 		return
 	}
+	if s.metrics != nil {
+		s.metrics.PacketSize(len(data))
+	}
 	if len(data) < 4 {
 		s.log.Debug("Packet too small", logger.Int("size", len(data)))
 		return
@@ -330,6 +944,13 @@ func (s *Server) handlePacket(data []byte, addr *net.UDPAddr) {
 	}
 }
 
+// noteHandshake records a peer reaching a stage of the login handshake
+func (s *Server) noteHandshake(stage metrics.HandshakeStage) {
+	if s.metrics != nil {
+		s.metrics.HandshakeStageReached(s.systemName, stage)
+	}
+}
+
 // handleRPTL handles login requests from peers
 func (s *Server) handleRPTL(data []byte, addr *net.UDPAddr) {
 	rptl, err := protocol.ParseRPTL(data)
@@ -341,19 +962,38 @@ func (s *Server) handleRPTL(data []byte, addr *net.UDPAddr) {
 	s.log.Info("Received RPTL",
 		logger.Int("peer_id", int(rptl.RepeaterID)),
 		logger.String("addr", addr.String()))
+	s.noteHandshake(metrics.HandshakeLogin)
+
+	defer s.lockHandshake(rptl.RepeaterID)()
+
+	if s.config.RejectInvalidPeerIDs && !s.validPeerID(rptl.RepeaterID) {
+		s.log.Warn("Peer denied: invalid or reserved repeater ID",
+			logger.Int("peer_id", int(rptl.RepeaterID)))
+		s.denyLogin(rptl.RepeaterID, addr)
+		return
+	}
 
 	// Check REG_ACL
 	if s.config.UseACL && s.regACL != nil {
 		if !s.regACL.Check(rptl.RepeaterID) {
 			s.log.Warn("Peer denied by REG_ACL",
 				logger.Int("peer_id", int(rptl.RepeaterID)))
-			s.sendMSTCL(rptl.RepeaterID, addr)
+			s.denyLogin(rptl.RepeaterID, addr)
 			return
 		}
 	}
 
-	// Add or update peer
-	p := s.peerManager.AddPeer(rptl.RepeaterID, addr)
+	// Add or update peer, unless the system is full (max_peers <= 0 is unlimited).
+	// This also claims the peer for this system.
+	p, ok := s.peerManager.AddPeerLimited(rptl.RepeaterID, addr, s.systemName, s.config.MaxPeers)
+	if !ok {
+		s.log.Warn("Peer denied: max_peers reached",
+			logger.Int("peer_id", int(rptl.RepeaterID)),
+			logger.Int("max_peers", s.config.MaxPeers))
+		s.denyLogin(rptl.RepeaterID, addr)
+		return
+	}
+	s.forgetRejection(rptl.RepeaterID, addr)
 	p.SetState(peer.StateRPTLReceived)
 	p.UpdateLastHeard()
 
@@ -382,6 +1022,9 @@ func (s *Server) handleRPTK(data []byte, addr *net.UDPAddr) {
 	s.log.Info("Received RPTK",
 		logger.Int("peer_id", int(rptk.RepeaterID)),
 		logger.String("addr", addr.String()))
+	s.noteHandshake(metrics.HandshakeKey)
+
+	defer s.lockHandshake(rptk.RepeaterID)()
 
 	// Get peer
 	p := s.peerManager.GetPeer(rptk.RepeaterID)
@@ -391,8 +1034,15 @@ func (s *Server) handleRPTK(data []byte, addr *net.UDPAddr) {
 		return
 	}
 
-	// Store challenge for verification (in real implementation, verify it)
-	p.Salt = rptk.Challenge
+	if s.requiresAuth() && !s.verifyChallenge(p.Salt, rptk.Challenge) {
+		s.log.Warn("RPTK challenge matches no passphrase, sending MSTNAK",
+			logger.Int("peer_id", int(rptk.RepeaterID)),
+			logger.String("addr", addr.String()))
+		s.peerManager.RemovePeer(rptk.RepeaterID)
+		s.sendMSTNAK(rptk.RepeaterID, addr)
+		return
+	}
+
 	p.SetState(peer.StateAuthenticated)
 	p.UpdateLastHeard()
 
@@ -412,6 +1062,9 @@ func (s *Server) handleRPTC(data []byte, addr *net.UDPAddr) {
 		logger.Int("peer_id", int(rptc.RepeaterID)),
 		logger.String("callsign", rptc.Callsign),
 		logger.String("location", rptc.Location))
+	s.noteHandshake(metrics.HandshakeConfig)
+
+	defer s.lockHandshake(rptc.RepeaterID)()
 
 	// Get peer
 	p := s.peerManager.GetPeer(rptc.RepeaterID)
@@ -421,9 +1074,25 @@ func (s *Server) handleRPTC(data []byte, addr *net.UDPAddr) {
 		return
 	}
 
+	// Ask the external authorization webhook, if configured
+	decision, allowed := s.authorizePeer(rptc, addr)
+	if !allowed {
+		s.sendMSTCL(rptc.RepeaterID, addr)
+		s.peerManager.RemovePeer(rptc.RepeaterID)
+		return
+	}
+
 	// Update peer configuration
+	if s.config.MaxDescriptionLength > 0 {
+		rptc.Description = protocol.SanitizeDescription(rptc.Description, s.config.MaxDescriptionLength)
+	}
 	p.SetConfig(rptc)
+	if tgs, ok := s.peerAllowedTGs[rptc.RepeaterID]; ok && p.Subscriptions != nil {
+		p.Subscriptions.SetAllowedOverride(tgs)
+	}
+	s.applyForcedOptions(p, decision)
 	p.SetConnected()
+	s.noteHandshake(metrics.HandshakeConnected)
 	p.UpdateLastHeard()
 
 	s.log.Info("Peer connected",
@@ -438,6 +1107,74 @@ func (s *Server) handleRPTC(data []byte, addr *net.UDPAddr) {
 	// Send RPTACK
 	// The client enters DMR_CONF state and expects RPTACK to trigger setup_connection()
 	s.sendRPTACK(rptc.RepeaterID, addr)
+
+	s.scheduleSubscriptionSummary(rptc.RepeaterID)
+}
+
+// authorizePeer consults the auth webhook for a configuring peer. It returns
+// the decision (nil when no webhook is configured or it failed open) and
+// whether the peer may connect.
+func (s *Server) authorizePeer(rptc *protocol.RPTCPacket, addr *net.UDPAddr) (*AuthDecision, bool) {
+	if s.authWebhook == nil {
+		return nil, true
+	}
+
+	decision, err := s.authWebhook.Authorize(context.Background(), AuthRequest{
+		PeerID:   rptc.RepeaterID,
+		Callsign: rptc.Callsign,
+		Address:  addr.String(),
+		System:   s.systemName,
+	})
+	if err != nil {
+		s.log.Error("Auth webhook failed",
+			logger.Int("peer_id", int(rptc.RepeaterID)),
+			logger.Bool("fail_open", s.config.AuthWebhookFailOpen),
+			logger.Error(err))
+		return nil, s.config.AuthWebhookFailOpen
+	}
+
+	if !decision.Allow {
+		s.log.Warn("Peer denied by auth webhook",
+			logger.Int("peer_id", int(rptc.RepeaterID)),
+			logger.String("callsign", rptc.Callsign),
+			logger.String("reason", decision.Reason))
+		return decision, false
+	}
+	return decision, true
+}
+
+// applyForcedOptions replaces a peer's subscriptions with OPTIONS returned by
+// the auth webhook and pins them against later RPTO packets
+func (s *Server) applyForcedOptions(p *peer.Peer, decision *AuthDecision) {
+	forced := false
+	if decision != nil && decision.Options != "" && p.Subscriptions != nil {
+		if opts, err := peer.ParseOptions(decision.Options); err != nil {
+			s.log.Warn("Invalid OPTIONS from auth webhook",
+				logger.Int("peer_id", int(p.ID)),
+				logger.String("options", decision.Options),
+				logger.Error(err))
+		} else {
+			p.Subscriptions.Clear()
+			if err := p.Subscriptions.Update(opts); err != nil {
+				s.log.Warn("Failed to apply OPTIONS from auth webhook",
+					logger.Int("peer_id", int(p.ID)),
+					logger.Error(err))
+			} else {
+				forced = true
+				s.log.Info("Applied OPTIONS from auth webhook",
+					logger.Int("peer_id", int(p.ID)),
+					logger.String("options", decision.Options))
+			}
+		}
+	}
+
+	s.forcedOptionsMu.Lock()
+	if forced {
+		s.forcedOptions[p.ID] = true
+	} else {
+		delete(s.forcedOptions, p.ID)
+	}
+	s.forcedOptionsMu.Unlock()
 }
 
 // handleRPTO handles OPTIONS packets from peers
@@ -450,6 +1187,7 @@ func (s *Server) handleRPTO(data []byte, addr *net.UDPAddr) {
 
 	// Extract repeater ID (bytes 4-8)
 	peerID := binary.BigEndian.Uint32(data[4:8])
+	defer s.lockHandshake(peerID)()
 
 	// Get peer
 	p := s.peerManager.GetPeer(peerID)
@@ -469,6 +1207,15 @@ func (s *Server) handleRPTO(data []byte, addr *net.UDPAddr) {
 		logger.Int("peer_id", int(peerID)),
 		logger.String("options", optionsStr))
 
+	s.forcedOptionsMu.RLock()
+	forced := s.forcedOptions[peerID]
+	s.forcedOptionsMu.RUnlock()
+	if forced {
+		s.log.Info("Ignoring RPTO, OPTIONS forced by auth webhook",
+			logger.Int("peer_id", int(peerID)))
+		return
+	}
+
 	// Parse and update peer subscriptions if OPTIONS provided
 	if optionsStr != "" {
 		if opts, err := peer.ParseOptions(optionsStr); err == nil {
@@ -478,10 +1225,21 @@ func (s *Server) handleRPTO(data []byte, addr *net.UDPAddr) {
 						logger.Int("peer_id", int(peerID)),
 						logger.Error(err))
 				} else {
+					p.SetEchoOwn(opts.Echo)
 					s.log.Debug("Updated peer subscriptions from RPTO",
 						logger.Int("peer_id", int(peerID)),
 						logger.Int("ts1_count", len(opts.TS1)),
 						logger.Int("ts2_count", len(opts.TS2)))
+
+					// UNLINK=ALL behaves like keying TG 4000
+					if opts.UnlinkAll {
+						p.SetRepeatMode(false)
+						if s.router != nil {
+							s.router.RemoveSubscriberFromAllDynamicBridges(p.ID)
+						}
+						s.log.Info("Peer unlinked from all dynamic talkgroups via OPTIONS",
+							logger.Int("peer_id", int(peerID)))
+					}
 				}
 			}
 		} else {
@@ -529,6 +1287,28 @@ func (s *Server) handleRPTPING(data []byte, addr *net.UDPAddr) {
 		return
 	}
 
+	// A peer that pings before finishing RPTC is told to log in again rather
+	// than ponged, which would look like a completed connection. Its last
+	// heard time is left alone so it times out if it never does.
+	if p.GetState() != peer.StateConnected {
+		send, remaining := s.shouldRejectAndRecord(peerID, addr)
+		if !send {
+			s.log.Debug("Ignoring RPTPING from recently rejected non-connected peer (cooldown active)",
+				logger.Uint64("peer_id", uint64(peerID)),
+				logger.String("addr", addr.String()),
+				logger.String("cooldown_remaining", remaining.String()))
+			return
+		}
+
+		s.log.Warn("RPTPING before handshake completed, sending MSTNAK",
+			logger.Uint64("peer_id", uint64(peerID)),
+			logger.String("addr", addr.String()),
+			logger.String("state", p.GetState().String()))
+
+		s.sendMSTNAK(peerID, addr)
+		return
+	}
+
 	s.log.Debug("Received RPTPING",
 		logger.Uint64("peer_id", uint64(peerID)),
 		logger.String("addr", addr.String()))
@@ -548,6 +1328,7 @@ func (s *Server) handleRPTCL(data []byte, addr *net.UDPAddr) {
 
 	// Extract repeater ID (bytes 5-9 after "RPTCL")
 	peerID := binary.BigEndian.Uint32(data[5:9])
+	defer s.lockHandshake(peerID)()
 
 	s.log.Info("Peer disconnect (RPTCL)",
 		logger.Uint64("peer_id", uint64(peerID)),
@@ -647,10 +1428,33 @@ func (s *Server) handleDMRD(data []byte, addr *net.UDPAddr) {
 		return
 	}
 
+	// End the peer's last stream if the client keyed up again without
+	// terminating it
+	s.terminateSupersededStream(dmrd, p, addr)
+
 	// Update stats
 	p.UpdateLastHeard()
 	p.IncrementPacketsReceived()
 	p.AddBytesReceived(uint64(len(data)))
+	s.countDMRDReceived(len(data))
+
+	// Enforce the per-peer concurrent stream limit
+	if !p.TrackStream(dmrd.StreamID, dmrd.Timeslot, s.maxStreamsPerPeer, time.Now()) {
+		s.log.Debug("Dropping concurrent stream from peer",
+			logger.Int("peer_id", int(p.ID)),
+			logger.Uint64("stream", uint64(dmrd.StreamID)),
+			logger.Int("ts", dmrd.Timeslot),
+			logger.Int("active_streams", p.ActiveStreamCount()))
+		if s.metrics != nil {
+			s.metrics.PacketDropped("concurrent_stream")
+		}
+		return
+	}
+	if dmrd.IsTerminator() {
+		defer p.EndStream(dmrd.StreamID)
+	}
+	s.noteStream(dmrd)
+	s.trackSequence(dmrd)
 
 	// Check SUB_ACL
 	if s.config.UseACL && s.subACL != nil {
@@ -661,12 +1465,50 @@ func (s *Server) handleDMRD(data []byte, addr *net.UDPAddr) {
 		}
 	}
 
+	// Drop or flag sources missing from the user database
+	if !s.allowSourceID(dmrd) {
+		s.log.Debug("Dropping frame from unregistered source ID",
+			logger.Int("src_id", int(dmrd.SourceID)))
+		return
+	}
+
+	// Encrypted audio can't be decoded downstream and may not be allowed
+	if s.dropEncrypted(dmrd) {
+		return
+	}
+
+	// Networks without unit-to-unit calling turn them into a group call
+	if s.config.PrivateToGroupTG > 0 && dmrd.CallType == protocol.CallTypePrivate {
+		converted, ok := s.privateToGroup(dmrd, data)
+		if !ok {
+			return
+		}
+		data = converted
+	}
+
+	// Repeaters on some talkgroups only accept their own color code
+	data = s.withTGColorCode(dmrd, data)
+
+	// Cut off group calls that outlast their talkgroup's time limit
+	if s.callTimedOut(dmrd) {
+		return
+	}
+
+	// Stop sources that have used up their daily airtime
+	if s.airtimeExhausted(dmrd) {
+		return
+	}
+
 	// Track subscriber location for private call routing
 	// Always update location on every DMRD packet to keep it fresh
 	s.log.Debug("Tracking subscriber location",
 		logger.Int("radio_id", int(dmrd.SourceID)),
 		logger.Int("peer_id", int(p.ID)))
 	s.trackSubscriberLocation(dmrd.SourceID, p.ID)
+	if s.noteTraffic(dmrd) {
+		s.noteFirstHeard(dmrd, p.ID)
+	}
+	s.notePacketData(dmrd)
 
 	// Handle private calls if enabled
 	if s.config.PrivateCallsEnabled && dmrd.CallType == protocol.CallTypePrivate {
@@ -692,6 +1534,14 @@ func (s *Server) handleDMRD(data []byte, addr *net.UDPAddr) {
 		}
 	}
 
+	// The echo test talkgroup plays a transmission back to its own peer
+	if s.handleEcho(dmrd, p) {
+		return
+	}
+
+	// Receive-only talkgroups accept listeners but not transmissions
+	receiveOnlyDenied := s.isReceiveOnlyDenied(dmrd, p)
+
 	// Process bridge activation/deactivation if router is configured
 	if s.router != nil {
 		// Special handling for TG 777 - enable "repeat everything" mode
@@ -730,24 +1580,44 @@ func (s *Server) handleDMRD(data []byte, addr *net.UDPAddr) {
 			return
 		}
 
+		// Strict subscriptions: key-ups on talkgroups the peer has no business
+		// on are dropped before they can subscribe it
+		if s.strictlyDenied(dmrd, p) {
+			return
+		}
+
 		// Touch the transmitting peer's subscription to this talkgroup
 		// AddDynamic returns true if this is a NEW subscription (first key-up)
 		// First key-up subscribes but doesn't forward audio (subscription activation)
 		// Uses the peer's AutoTTL from OPTIONS, or unlimited if not set
+		// Talkgroups outside the peer's whitelist are accepted but never
+		// subscribe the peer or create a dynamic bridge
 		isNewSubscription := false
-		if p.Subscriptions != nil {
-			isNewSubscription = p.Subscriptions.AddDynamic(dmrd.DestinationID, uint8(dmrd.Timeslot))
-		}
+		if p.Subscriptions != nil && !p.Subscriptions.IsAllowed(dmrd.DestinationID) {
+			if dmrd.FrameType == protocol.FrameTypeVoiceHeader {
+				s.log.Debug("Talkgroup not in peer whitelist, not subscribing",
+					logger.Int("peer_id", int(p.ID)),
+					logger.Int("tg", int(dmrd.DestinationID)))
+			}
+		} else {
+			if p.Subscriptions != nil {
+				isNewSubscription = p.Subscriptions.AddDynamic(dmrd.DestinationID, uint8(dmrd.Timeslot))
+			}
 
-		// Create/update dynamic bridge for dashboard visibility
-		// This doesn't affect forwarding logic - it's just for tracking/display
-		// Bridges are now timeslot-agnostic
-		s.router.GetOrCreateDynamicBridge(dmrd.DestinationID)
+			// Create/update dynamic bridge for dashboard visibility
+			// This doesn't affect forwarding logic - it's just for tracking/display
+			// Bridges are now timeslot-agnostic
+			s.router.GetOrCreateDynamicBridge(dmrd.DestinationID)
+		}
 
 		// If this is the first key-up (new subscription), mark this stream muted
 		if isNewSubscription {
-			// Mute for the duration of this transmission: until voice terminator or 2s idle
-			s.mutedStreams[dmrd.StreamID] = time.Now().Add(2 * time.Second)
+			// Mute for the duration of this transmission: until voice terminator or muteWindow idle
+			s.mutedStreamsMu.Lock()
+			if !dmrd.IsTerminator() {
+				s.mutedStreams[dmrd.StreamID] = time.Now().Add(s.muteWindow)
+			}
+			s.mutedStreamsMu.Unlock()
 			s.log.Info("Peer subscribed to talkgroup (first key-up muted for this transmission)",
 				logger.Int("peer_id", int(p.ID)),
 				logger.String("callsign", p.Callsign),
@@ -758,57 +1628,83 @@ func (s *Server) handleDMRD(data []byte, addr *net.UDPAddr) {
 			return
 		}
 
+		if receiveOnlyDenied {
+			return
+		}
+
 		s.log.Debug("Dynamic bridge activity",
 			logger.Int("peer_id", int(p.ID)),
 			logger.Int("tg", int(dmrd.DestinationID)),
 			logger.Int("ts", dmrd.Timeslot),
 			logger.Int("src", int(dmrd.SourceID)))
 
-		// Check if this TGID should activate any static bridge rules
-		activated := s.router.ProcessActivation(dmrd.DestinationID)
-		if len(activated) > 0 {
-			for bridgeName, rules := range activated {
-				for _, rule := range rules {
-					s.log.Info("Bridge rule activated",
-						logger.String("bridge", bridgeName),
-						logger.String("system", rule.System),
-						logger.Int("tg", rule.TGID),
-						logger.Int("ts", rule.Timeslot))
-				}
-			}
+		// Check if this TGID should activate or deactivate any static bridge
+		// rules, once a talker-hold TG's transmission has lasted long enough.
+		// Rules that change state are reported through the router's bridge
+		// change handler.
+		if !s.activationHeld(dmrd) {
+			s.router.ProcessActivation(dmrd.DestinationID)
+			s.router.ProcessDeactivation(dmrd.DestinationID)
 		}
 
-		// Check if this TGID should deactivate any static bridge rules
-		deactivated := s.router.ProcessDeactivation(dmrd.DestinationID)
-		if len(deactivated) > 0 {
-			for bridgeName, rules := range deactivated {
-				for _, rule := range rules {
-					s.log.Info("Bridge rule deactivated",
-						logger.String("bridge", bridgeName),
-						logger.String("system", rule.System),
-						logger.Int("tg", rule.TGID),
-						logger.Int("ts", rule.Timeslot))
-				}
+		// A higher-priority source took this talkgroup over, or an operator
+		// revoked the stream: close it downstream once and drop the rest of it
+		if preempted, notify := s.router.PreemptedStream(dmrd); preempted {
+			if notify {
+				s.sendPreemptionTerminator(dmrd, p.ID)
 			}
+			return
+		}
+
+		if s.keyupGuarded(dmrd) {
+			return
 		}
 
 		// Update or clear stream mute based on frames
-		if _, muted := s.mutedStreams[dmrd.StreamID]; muted {
+		s.mutedStreamsMu.Lock()
+		_, muted := s.mutedStreams[dmrd.StreamID]
+		if muted {
 			// Extend mute window with activity
-			s.mutedStreams[dmrd.StreamID] = time.Now().Add(2 * time.Second)
+			s.mutedStreams[dmrd.StreamID] = time.Now().Add(s.muteWindow)
 			// If this is a terminator frame, unmute by deleting
-			if dmrd.FrameType == protocol.FrameTypeVoiceTerminator {
+			if dmrd.IsTerminator() {
 				delete(s.mutedStreams, dmrd.StreamID)
 			}
+		}
+		s.mutedStreamsMu.Unlock()
+		if muted {
 			// Suppress forwarding while muted
 			return
 		}
 
 		// Route packet using bridge rules and dynamic bridges
 		targets := s.router.RoutePacket(dmrd, s.systemName)
+		if len(targets) > 0 && dmrd.FrameType == protocol.FrameTypeVoiceHeader {
+			s.reportUnavailableTargets(dmrd, targets)
+		}
+
+		// Send to the PEER and OPENBRIDGE systems bridged to this talkgroup
+		s.forwardToSystems(dmrd, data, targets)
+
+		// Hold the caller's audio while their callsign is announced
+		if s.holdForAnnouncement(dmrd, data, p.ID) {
+			return
+		}
 
 		// Forward to dynamically subscribed peers
 		dynamicTargets := s.findDynamicSubscribers(dmrd.DestinationID, uint8(dmrd.Timeslot), p.ID)
+		if s.config.HoldStreamTargets {
+			dynamicTargets = s.withStreamTargets(dmrd, dynamicTargets, p.ID)
+		}
+
+		// Talkgroups nothing routes are metered and optionally sent to a catch-all system
+		if !hasSubscriber(dynamicTargets) && !s.router.HasRoute(dmrd, s.systemName) {
+			s.handleUnknownTalkgroup(dmrd, data)
+		}
+
+		// A recording tap gets every forwarded frame, subscribed or not
+		dynamicTargets = s.withTapPeer(dmrd, dynamicTargets, p.ID)
+		s.mirrorToTapSystem(dmrd, data, targets)
 
 		if len(targets) > 0 || len(dynamicTargets) > 0 {
 			s.log.Debug("Routing DMRD packet",
@@ -826,9 +1722,79 @@ func (s *Server) handleDMRD(data []byte, addr *net.UDPAddr) {
 	}
 
 	// Forward to other peers if repeat is enabled
-	if s.config.Repeat {
+	if s.config.Repeat && !receiveOnlyDenied {
 		s.forwardDMRD(dmrd, data, p.ID)
 	}
+
+	// Simulcast and voting receivers get their own traffic back
+	if !receiveOnlyDenied {
+		s.echoOwnTraffic(dmrd, data, p)
+	}
+}
+
+// lastTerminator records who last finished transmitting on a talkgroup
+type lastTerminator struct {
+	sourceID uint32
+	at       time.Time
+}
+
+// keyupGuarded reports whether a frame belongs to a stream held back by the
+// key-up guard: a different source keying up on a talkgroup within
+// keyupGuard of the previous transmission's terminator. The whole stream is
+// dropped, not just its header.
+func (s *Server) keyupGuarded(dmrd *protocol.DMRDPacket) bool {
+	if s.keyupGuard <= 0 {
+		return false
+	}
+
+	s.keyupGuardMu.Lock()
+	defer s.keyupGuardMu.Unlock()
+
+	now := time.Now()
+	if _, held := s.heldStreams[dmrd.StreamID]; held {
+		if dmrd.IsTerminator() {
+			delete(s.heldStreams, dmrd.StreamID)
+		} else {
+			s.heldStreams[dmrd.StreamID] = now.Add(s.muteWindow)
+		}
+		return true
+	}
+
+	switch {
+	case dmrd.FrameType == protocol.FrameTypeVoiceHeader:
+		last, ok := s.lastTerminators[dmrd.DestinationID]
+		if ok && last.sourceID != dmrd.SourceID && now.Sub(last.at) < s.keyupGuard {
+			s.heldStreams[dmrd.StreamID] = now.Add(s.muteWindow)
+			s.log.Debug("Key-up within guard window, stream dropped",
+				logger.Int("src", int(dmrd.SourceID)),
+				logger.Int("previous_src", int(last.sourceID)),
+				logger.Int("tg", int(dmrd.DestinationID)),
+				logger.Uint64("stream", uint64(dmrd.StreamID)))
+			return true
+		}
+	case dmrd.IsTerminator():
+		s.lastTerminators[dmrd.DestinationID] = lastTerminator{sourceID: dmrd.SourceID, at: now}
+	}
+	return false
+}
+
+// isReceiveOnlyDenied reports whether a transmission targets a receive-only
+// talkgroup from a source that is not allowed to transmit on it
+func (s *Server) isReceiveOnlyDenied(dmrd *protocol.DMRDPacket, p *peer.Peer) bool {
+	sources, receiveOnly := s.receiveOnlyTGs[dmrd.DestinationID]
+	if !receiveOnly || sources[dmrd.SourceID] || sources[p.ID] {
+		return false
+	}
+
+	// Log once per transmission rather than once per frame
+	if dmrd.FrameType == protocol.FrameTypeVoiceHeader {
+		s.log.Info("Talkgroup is receive-only, transmission not forwarded",
+			logger.Int("tg", int(dmrd.DestinationID)),
+			logger.Int("src", int(dmrd.SourceID)),
+			logger.Int("peer_id", int(p.ID)),
+			logger.String("callsign", p.Callsign))
+	}
+	return true
 }
 
 // findDynamicSubscribers finds all peers that are subscribed to a talkgroup on ANY timeslot
@@ -896,10 +1862,24 @@ func (s *Server) handlePrivateCall(dmrd *protocol.DMRDPacket, data []byte, sourc
 		logger.Int("ts", dmrd.Timeslot),
 		logger.Int("source_peer", int(sourcePeer.ID)))
 
-	// Look up where the destination subscriber is located
-	targetPeer, found := s.lookupSubscriberLocation(dmrd.DestinationID)
+	// Look up where the destination subscriber is located and, with
+	// private_calls_to_peers, whether the destination is a peer ID itself
+	var targets []*peer.Peer
+	if targetPeer, found := s.lookupSubscriberLocation(dmrd.DestinationID); found {
+		targets = append(targets, targetPeer)
+	}
+	if targetPeer, found := s.lookupPeerDestination(dmrd.DestinationID); found &&
+		(len(targets) == 0 || targets[0].ID != targetPeer.ID) {
+		targets = append(targets, targetPeer)
+	}
+
+	if len(targets) == 0 {
+		// Not reachable locally - try other systems across static bridges
+		if s.config.BridgePrivateCalls && s.router != nil {
+			s.bridgePrivateCall(dmrd, data)
+			return
+		}
 
-	if !found {
 		// Destination not found or stale
 		s.log.Debug("Private call destination not found",
 			logger.Int("dst", int(dmrd.DestinationID)),
@@ -907,6 +1887,26 @@ func (s *Server) handlePrivateCall(dmrd *protocol.DMRDPacket, data []byte, sourc
 		return
 	}
 
+	for _, targetPeer := range targets {
+		s.forwardPrivateCall(dmrd, data, sourcePeer, targetPeer)
+	}
+}
+
+// lookupPeerDestination returns the connected peer whose ID a private call is
+// addressed to, when private_calls_to_peers is set
+func (s *Server) lookupPeerDestination(dstID uint32) (*peer.Peer, bool) {
+	if !s.config.PrivateCallsToPeers {
+		return nil, false
+	}
+	p := s.peerManager.GetPeer(dstID)
+	if p == nil || p.GetState() != peer.StateConnected {
+		return nil, false
+	}
+	return p, true
+}
+
+// forwardPrivateCall sends a private call frame to one destination peer
+func (s *Server) forwardPrivateCall(dmrd *protocol.DMRDPacket, data []byte, sourcePeer, targetPeer *peer.Peer) {
 	// Don't send back to the source peer
 	if targetPeer.ID == sourcePeer.ID {
 		s.log.Debug("Private call destination is on same peer as source, not forwarding",
@@ -922,8 +1922,13 @@ func (s *Server) handlePrivateCall(dmrd *protocol.DMRDPacket, data []byte, sourc
 		logger.Int("target_peer", int(targetPeer.ID)),
 		logger.String("target_callsign", targetPeer.Callsign))
 
+	// The target's timeslot may already be carrying another stream
+	if !s.claimSlot(dmrd, targetPeer) {
+		return
+	}
+
 	// Forward the packet to the target peer
-	_, err := s.conn.WriteToUDP(data, targetPeer.Address)
+	_, err := s.connFor(targetPeer.Address).WriteToUDP(data, targetPeer.Address)
 	if err != nil {
 		s.log.Error("Failed to forward private call",
 			logger.Int("target_peer", int(targetPeer.ID)),
@@ -936,6 +1941,108 @@ func (s *Server) handlePrivateCall(dmrd *protocol.DMRDPacket, data []byte, sourc
 	targetPeer.AddBytesSent(uint64(len(data)))
 }
 
+// bridgePrivateCall forwards a private call to the systems linked to this one by static bridges
+func (s *Server) bridgePrivateCall(dmrd *protocol.DMRDPacket, data []byte) {
+	if s.privateCallACL != nil && !s.privateCallACL.Check(dmrd.DestinationID) {
+		s.log.Debug("Bridged private call denied by PRIVATE_CALL_ACL",
+			logger.Int("dst", int(dmrd.DestinationID)))
+		return
+	}
+
+	targets := s.router.RoutePrivateCall(dmrd, s.systemName)
+	if len(targets) == 0 {
+		s.log.Debug("Private call destination not found locally and no bridged systems",
+			logger.Int("dst", int(dmrd.DestinationID)),
+			logger.Int("src", int(dmrd.SourceID)))
+		return
+	}
+
+	delivered := s.router.ForwardToSystems(targets, dmrd, data)
+	s.log.Debug("Bridged private call",
+		logger.Int("src", int(dmrd.SourceID)),
+		logger.Int("dst", int(dmrd.DestinationID)),
+		logger.Int("targets", len(targets)),
+		logger.Int("delivered", delivered))
+}
+
+// deliverBridged handles a packet routed to this system from another system.
+// Bridged traffic is only delivered to local peers and never re-bridged.
+func (s *Server) deliverBridged(dmrd *protocol.DMRDPacket, data []byte) {
+	if s.getConn() == nil {
+		return
+	}
+
+	if dmrd.CallType != protocol.CallTypePrivate {
+		// Group calls arrive from static bridge rules, and from systems that
+		// use this one as their unknown TG or tap target
+		s.deliverLocal(dmrd, s.withTGColorCode(dmrd, data), 0)
+		return
+	}
+
+	if !s.config.BridgePrivateCalls {
+		return
+	}
+	if s.privateCallACL != nil && !s.privateCallACL.Check(dmrd.DestinationID) {
+		s.log.Debug("Bridged private call denied by PRIVATE_CALL_ACL",
+			logger.Int("dst", int(dmrd.DestinationID)))
+		return
+	}
+
+	targetPeer, found := s.lookupSubscriberLocation(dmrd.DestinationID)
+	if !found || !s.claimSlot(dmrd, targetPeer) {
+		return
+	}
+
+	s.log.Info("Delivering bridged private call",
+		logger.Int("src", int(dmrd.SourceID)),
+		logger.Int("dst", int(dmrd.DestinationID)),
+		logger.Int("target_peer", int(targetPeer.ID)))
+
+	if _, err := s.connFor(targetPeer.Address).WriteToUDP(data, targetPeer.Address); err != nil {
+		s.log.Error("Failed to deliver bridged private call",
+			logger.Int("target_peer", int(targetPeer.ID)),
+			logger.Error(err))
+		return
+	}
+
+	targetPeer.IncrementPacketsSent()
+	targetPeer.AddBytesSent(uint64(len(data)))
+}
+
+// ownsPeer reports whether the peer logged in to this system rather than to
+// another MASTER sharing the peer manager
+func (s *Server) ownsPeer(p *peer.Peer) bool {
+	return p.GetSystem() == s.systemName
+}
+
+// hasConnectedPeers reports whether any peer logged in to this system is
+// connected, i.e. whether traffic bridged here reaches anyone
+func (s *Server) hasConnectedPeers() bool {
+	for _, p := range s.peerManager.GetAllPeers() {
+		if s.ownsPeer(p) && p.GetState() == peer.StateConnected {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardToSystems hands a frame from a local peer to the PEER and
+// OPENBRIDGE systems the router matched it to. MASTER systems, this one
+// included, are left out: they share the peer manager, so the local
+// delivery already reached their subscribers, and handing them the frame
+// again would echo it to the talker and double it for everyone else.
+func (s *Server) forwardToSystems(dmrd *protocol.DMRDPacket, data []byte, targets []string) {
+	others := make([]string, 0, len(targets))
+	for _, target := range targets {
+		if target != s.systemName && !s.router.IsMasterSystem(target) {
+			others = append(others, target)
+		}
+	}
+	if len(others) > 0 {
+		s.router.ForwardToSystems(others, dmrd, data)
+	}
+}
+
 // countTalkgroupSubscribers counts how many peers are subscribed to a talkgroup (any timeslot)
 func (s *Server) countTalkgroupSubscribers(tgid uint32) int {
 	allPeers := s.peerManager.GetAllPeers()
@@ -954,26 +2061,60 @@ func (s *Server) countTalkgroupSubscribers(tgid uint32) int {
 	return count
 }
 
+// downsampled reports whether a frame should be withheld from a low-bandwidth
+// peer. Header, terminator and data frames always pass, as does voice burst A
+// (the sync burst that starts each superframe), so the receiver stays locked.
+func (s *Server) downsampled(dmrd *protocol.DMRDPacket, peerID uint32) bool {
+	if dmrd == nil || !s.lowBandwidthPeers[peerID] {
+		return false
+	}
+	return dmrd.FrameType == protocol.FrameTypeVoice && dmrd.DataType != 0
+}
+
+// sendPreemptionTerminator sends a synthesized voice terminator for a stream
+// that was preempted or revoked, so downstream radios stop playing it
+func (s *Server) sendPreemptionTerminator(dmrd *protocol.DMRDPacket, sourcePeerID uint32) {
+	term, data, err := encodeTerminator(dmrd)
+	if err != nil {
+		s.log.Error("Failed to encode preemption terminator", logger.Error(err))
+		return
+	}
+
+	s.log.Info("Stream cut off by preemption or revocation, sending terminator",
+		logger.Int("src", int(dmrd.SourceID)),
+		logger.Int("tg", int(dmrd.DestinationID)),
+		logger.Int("ts", dmrd.Timeslot))
+
+	targets := s.findDynamicSubscribers(dmrd.DestinationID, uint8(dmrd.Timeslot), sourcePeerID)
+	s.forwardToDynamicSubscribers(term, data, targets)
+	if s.config.Repeat {
+		s.forwardDMRD(term, data, sourcePeerID)
+	}
+}
+
+// encodeTerminator builds the terminator with LC that closes dmrd's stream
+func encodeTerminator(dmrd *protocol.DMRDPacket) (*protocol.DMRDPacket, []byte, error) {
+	term := *dmrd
+	term.FrameType = protocol.FrameTypeVoiceTerminator
+	term.DataType = protocol.DataTypeTerminatorLC
+	term.HMAC = nil
+	data, err := term.Encode()
+	return &term, data, err
+}
+
 // forwardToDynamicSubscribers forwards a DMRD packet to dynamic subscribers
-func (s *Server) forwardToDynamicSubscribers(_ *protocol.DMRDPacket, data []byte, targetPeers []*peer.Peer) {
+func (s *Server) forwardToDynamicSubscribers(dmrd *protocol.DMRDPacket, data []byte, targetPeers []*peer.Peer) {
 	for _, targetPeer := range targetPeers {
-		// Send packet
-		_, err := s.conn.WriteToUDP(data, targetPeer.Address)
-		if err != nil {
-			s.log.Error("Failed to forward DMRD to dynamic subscriber",
-				logger.Int("peer_id", int(targetPeer.ID)),
-				logger.Error(err))
+		if s.downsampled(dmrd, targetPeer.ID) || !s.claimSlot(dmrd, targetPeer) || !s.shouldDeliver(dmrd, targetPeer.ID) {
 			continue
 		}
 
-		// Update stats
-		targetPeer.IncrementPacketsSent()
-		targetPeer.AddBytesSent(uint64(len(data)))
+		s.sendToPeer(targetPeer, data)
 	}
 }
 
 // forwardDMRD forwards a DMRD packet to all other connected peers
-func (s *Server) forwardDMRD(_ *protocol.DMRDPacket, data []byte, sourcePeerID uint32) {
+func (s *Server) forwardDMRD(dmrd *protocol.DMRDPacket, data []byte, sourcePeerID uint32) {
 	peers := s.peerManager.GetAllPeers()
 	for _, p := range peers {
 		// Don't send back to source
@@ -986,18 +2127,11 @@ func (s *Server) forwardDMRD(_ *protocol.DMRDPacket, data []byte, sourcePeerID u
 			continue
 		}
 
-		// Send packet
-		_, err := s.conn.WriteToUDP(data, p.Address)
-		if err != nil {
-			s.log.Error("Failed to forward DMRD",
-				logger.Int("peer_id", int(p.ID)),
-				logger.Error(err))
+		if s.downsampled(dmrd, p.ID) || !s.claimSlot(dmrd, p) || !s.shouldDeliver(dmrd, p.ID) {
 			continue
 		}
 
-		// Update stats
-		p.IncrementPacketsSent()
-		p.AddBytesSent(uint64(len(data)))
+		s.sendToPeer(p, data)
 	}
 }
 
@@ -1012,7 +2146,7 @@ func (s *Server) sendRPTACK(peerID uint32, addr *net.UDPAddr) {
 		return
 	}
 
-	_, err = s.conn.WriteToUDP(data, addr)
+	_, err = s.connFor(addr).WriteToUDP(data, addr)
 	if err != nil {
 		s.log.Error("Failed to send RPTACK", logger.Error(err))
 	}
@@ -1030,7 +2164,7 @@ func (s *Server) sendRPTACKWithSalt(peerID uint32, salt []byte, addr *net.UDPAdd
 		return
 	}
 
-	_, err = s.conn.WriteToUDP(data, addr)
+	_, err = s.connFor(addr).WriteToUDP(data, addr)
 	if err != nil {
 		s.log.Error("Failed to send RPTACK with salt", logger.Error(err))
 	}
@@ -1042,7 +2176,7 @@ func (s *Server) sendMSTPONG(peerID uint32, addr *net.UDPAddr) {
 	copy(pong[0:7], protocol.PacketTypeMSTPONG)
 	binary.BigEndian.PutUint32(pong[7:11], peerID)
 
-	_, err := s.conn.WriteToUDP(pong, addr)
+	_, err := s.connFor(addr).WriteToUDP(pong, addr)
 	if err != nil {
 		s.log.Debug("Failed to send MSTPONG", logger.Error(err))
 	}
@@ -1054,7 +2188,7 @@ func (s *Server) sendMSTNAK(peerID uint32, addr *net.UDPAddr) {
 	copy(nak[0:6], protocol.PacketTypeMSTNAK)
 	binary.BigEndian.PutUint32(nak[6:10], peerID)
 
-	_, err := s.conn.WriteToUDP(nak, addr)
+	_, err := s.connFor(addr).WriteToUDP(nak, addr)
 	if err != nil {
 		s.log.Debug("Failed to send MSTNAK", logger.Error(err))
 	}
@@ -1066,7 +2200,7 @@ func (s *Server) sendMSTCL(peerID uint32, addr *net.UDPAddr) {
 	copy(cl[0:5], protocol.PacketTypeMSTCL)
 	binary.BigEndian.PutUint32(cl[5:9], peerID)
 
-	_, err := s.conn.WriteToUDP(cl, addr)
+	_, err := s.connFor(addr).WriteToUDP(cl, addr)
 	if err != nil {
 		s.log.Debug("Failed to send MSTCL", logger.Error(err))
 	}
@@ -1077,6 +2211,10 @@ func (s *Server) sendMSTCL(peerID uint32, addr *net.UDPAddr) {
 // the caller SHOULD send an MSTNAK; if false is returned the caller SHOULD
 // silently ignore the packet. When true is returned the rejection timestamp
 // is recorded so repeated MSTNAK are suppressed for s.mstNakCooldown.
+//
+// Some clients ignore MSTNAK/MSTCL and keep pinging forever. Once a rejected
+// peer has sent s.silenceAfter more packets it is silenced: the cooldown
+// grows to s.silenceCooldown and it is counted as a persistent unknown peer.
 func (s *Server) shouldRejectAndRecord(peerID uint32, addr *net.UDPAddr) (bool, time.Duration) {
 	peerKey := peerKey(peerID, addr)
 
@@ -1084,22 +2222,64 @@ func (s *Server) shouldRejectAndRecord(peerID uint32, addr *net.UDPAddr) (bool,
 	defer s.rejectedPeersMu.Unlock()
 
 	now := time.Now()
-	if rejected, exists := s.rejectedPeers[peerKey]; exists {
-		if now.Sub(rejected.lastMSTNAK) < s.mstNakCooldown {
-			// still in cooldown - report remaining time
-			return false, s.mstNakCooldown - now.Sub(rejected.lastMSTNAK)
+	rejected, exists := s.rejectedPeers[peerKey]
+	if !exists {
+		s.rejectedPeers[peerKey] = &rejectedPeer{
+			peerID:     peerID,
+			addr:       addr.String(),
+			lastMSTNAK: now,
+			lastSeen:   now,
 		}
+		return true, 0
 	}
 
-	// record this rejection now
-	s.rejectedPeers[peerKey] = &rejectedPeer{
-		peerID:     peerID,
-		addr:       addr.String(),
-		lastMSTNAK: now,
+	rejected.lastSeen = now
+	rejected.ignored++
+	if !rejected.silenced && s.silenceAfter > 0 && rejected.ignored >= s.silenceAfter {
+		rejected.silenced = true
+		if s.metrics != nil {
+			s.metrics.PersistentUnknownPeer()
+		}
+		s.log.Warn("Peer keeps sending after being rejected, no longer answering it",
+			logger.Uint64("peer_id", uint64(peerID)),
+			logger.String("addr", addr.String()),
+			logger.Int("packets", rejected.ignored),
+			logger.String("silence", s.silenceCooldown.String()))
+	}
+
+	cooldown := s.rejectionCooldown(rejected)
+	if elapsed := now.Sub(rejected.lastMSTNAK); elapsed < cooldown {
+		// still in cooldown - report remaining time
+		return false, cooldown - elapsed
 	}
+	rejected.lastMSTNAK = now
 	return true, 0
 }
 
+// rejectionCooldown is the time between rejections sent to a peer; caller
+// must hold s.rejectedPeersMu
+func (s *Server) rejectionCooldown(rejected *rejectedPeer) time.Duration {
+	if rejected.silenced {
+		return s.silenceCooldown
+	}
+	return s.mstNakCooldown
+}
+
+// denyLogin answers a refused login with MSTCL, rate limited and silenced
+// like MSTNAK so a client retrying regardless isn't answered forever
+func (s *Server) denyLogin(peerID uint32, addr *net.UDPAddr) {
+	if send, _ := s.shouldRejectAndRecord(peerID, addr); send {
+		s.sendMSTCL(peerID, addr)
+	}
+}
+
+// forgetRejection clears a peer's rejection history once it logs in properly
+func (s *Server) forgetRejection(peerID uint32, addr *net.UDPAddr) {
+	s.rejectedPeersMu.Lock()
+	delete(s.rejectedPeers, peerKey(peerID, addr))
+	s.rejectedPeersMu.Unlock()
+}
+
 // cleanupLoop periodically cleans up timed out peers
 func (s *Server) cleanupLoop(ctx context.Context) error {
 	ticker := time.NewTicker(s.cleanupInterval)
@@ -1124,20 +2304,52 @@ func (s *Server) cleanupLoop(ctx context.Context) error {
 						logger.Int("count", len(removedBridges)))
 				}
 			}
-			// Cleanup expired muted streams (idle > 2s)
+			// Forget which listener idle addresses arrived on
+			s.cleanupReplyConns(s.pingTimeout)
+
+			// Cleanup expired muted streams (idle > muteWindow)
 			now := time.Now()
-			for streamID, expiry := range s.mutedStreams {
+			s.CleanupMutedStreamsOnce(now)
+			s.cleanupDeliveries(now)
+
+			// Cleanup key-up guard state
+			s.keyupGuardMu.Lock()
+			for streamID, expiry := range s.heldStreams {
 				if now.After(expiry) {
-					delete(s.mutedStreams, streamID)
+					delete(s.heldStreams, streamID)
+				}
+			}
+			for tgid, last := range s.lastTerminators {
+				if now.Sub(last.at) > s.keyupGuard {
+					delete(s.lastTerminators, tgid)
 				}
 			}
+			s.keyupGuardMu.Unlock()
+
+			// Forget cached source ID lookups and unterminated held transmissions
+			s.cleanupSourceIDs(now)
+			s.cleanupTalkerHolds(now)
+			s.cleanupEchoes(now)
+			s.cleanupEncrypted(now)
+			s.cleanupTimedCalls(now)
+			s.cleanupDataCalls(now)
+			s.cleanupAnnouncements(now)
+			s.cleanupTraffic(now)
+			s.cleanupUnavailableReports(now)
+			s.cleanupSlots(now)
+			s.cleanupSequences(now)
+			s.cleanupSelfEchoes(now)
+			s.cleanupAirtime(now)
+			if s.firstHeard != nil {
+				s.firstHeard.cleanup()
+			}
+			s.unlinkIdleTalkgroups(now)
 
-			// Cleanup expired rejected peers (cooldown + grace period expired)
+			// Cleanup rejected peers quiet for their cooldown + a grace period
 			s.rejectedPeersMu.Lock()
 			expiredKeys := make([]string, 0)
-			cleanupThreshold := s.mstNakCooldown + (5 * time.Minute) // Keep for cooldown + 5 minutes
 			for key, rejected := range s.rejectedPeers {
-				if now.Sub(rejected.lastMSTNAK) > cleanupThreshold {
+				if now.Sub(rejected.lastSeen) > s.rejectionCooldown(rejected)+5*time.Minute {
 					expiredKeys = append(expiredKeys, key)
 				}
 			}
@@ -1151,29 +2363,20 @@ func (s *Server) cleanupLoop(ctx context.Context) error {
 			}
 
 			// Cleanup stale subscriber locations (not seen for 15 minutes)
-			s.cleanupStaleSubscriberLocations(15 * time.Minute)
+			s.cleanupStaleSubscriberLocations(s.locationTTL)
 		}
 	}
 }
 
 // trackSubscriberLocation records where a subscriber (radio) was last seen
 func (s *Server) trackSubscriberLocation(radioID uint32, peerID uint32) {
-	s.subscriberLocationsMu.Lock()
-	defer s.subscriberLocationsMu.Unlock()
-
-	s.subscriberLocations[radioID] = &subscriberLocation{
-		peerID:   peerID,
-		lastSeen: time.Now(),
-	}
+	s.subscriberLocations.set(radioID, peerID, time.Now())
 }
 
 // lookupSubscriberLocation finds which peer a subscriber is behind
 // Returns the peer and true if found, or nil and false if not found or stale
 func (s *Server) lookupSubscriberLocation(radioID uint32) (*peer.Peer, bool) {
-	s.subscriberLocationsMu.RLock()
-	loc, exists := s.subscriberLocations[radioID]
-	s.subscriberLocationsMu.RUnlock()
-
+	loc, exists := s.subscriberLocations.get(radioID)
 	if !exists {
 		return nil, false
 	}
@@ -1199,27 +2402,17 @@ func (s *Server) lookupSubscriberLocation(radioID uint32) (*peer.Peer, bool) {
 
 // cleanupStaleSubscriberLocations removes subscriber locations not seen within the TTL
 func (s *Server) cleanupStaleSubscriberLocations(ttl time.Duration) {
-	s.subscriberLocationsMu.Lock()
-	defer s.subscriberLocationsMu.Unlock()
-
 	now := time.Now()
-	for radioID, loc := range s.subscriberLocations {
-		if now.Sub(loc.lastSeen) > ttl {
-			delete(s.subscriberLocations, radioID)
-		}
-	}
+	s.subscriberLocations.removeIf(func(loc subscriberLocation) bool {
+		return now.Sub(loc.lastSeen) > ttl
+	})
 }
 
 // clearSubscriberLocationsForPeer removes all subscriber locations associated with a peer
 func (s *Server) clearSubscriberLocationsForPeer(peerID uint32) {
-	s.subscriberLocationsMu.Lock()
-	defer s.subscriberLocationsMu.Unlock()
-
-	for radioID, loc := range s.subscriberLocations {
-		if loc.peerID == peerID {
-			delete(s.subscriberLocations, radioID)
-		}
-	}
+	s.subscriberLocations.removeIf(func(loc subscriberLocation) bool {
+		return loc.peerID == peerID
+	})
 }
 
 // Verify challenge (used during authentication)
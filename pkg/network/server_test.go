@@ -1,14 +1,23 @@
 package network
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
 	"github.com/dbehnke/dmr-nexus/pkg/config"
 	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/metrics"
 	"github.com/dbehnke/dmr-nexus/pkg/peer"
 	"github.com/dbehnke/dmr-nexus/pkg/protocol"
 )
@@ -32,6 +41,42 @@ func TestServer_New(t *testing.T) {
 	}
 }
 
+func TestServer_NewAppliesTimers(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+
+	// Zero values keep the defaults
+	srv := NewServer(config.SystemConfig{Mode: "MASTER"}, "test-system", log)
+	if srv.pingTimeout != 30*time.Second || srv.cleanupInterval != 10*time.Second ||
+		srv.muteWindow != 2*time.Second || srv.locationTTL != 15*time.Minute || srv.mstNakCooldown != 15*time.Second {
+		t.Errorf("Unexpected defaults: ping=%v cleanup=%v mute=%v location=%v mstnak=%v",
+			srv.pingTimeout, srv.cleanupInterval, srv.muteWindow, srv.locationTTL, srv.mstNakCooldown)
+	}
+
+	srv = NewServer(config.SystemConfig{
+		Mode:                  "MASTER",
+		PingTimeout:           60,
+		CleanupInterval:       5,
+		MuteWindowMs:          1500,
+		SubscriberLocationTTL: 300,
+		MstNakCooldown:        30,
+	}, "test-system", log)
+	if srv.pingTimeout != 60*time.Second {
+		t.Errorf("Expected ping timeout 60s, got %v", srv.pingTimeout)
+	}
+	if srv.cleanupInterval != 5*time.Second {
+		t.Errorf("Expected cleanup interval 5s, got %v", srv.cleanupInterval)
+	}
+	if srv.muteWindow != 1500*time.Millisecond {
+		t.Errorf("Expected mute window 1.5s, got %v", srv.muteWindow)
+	}
+	if srv.locationTTL != 5*time.Minute {
+		t.Errorf("Expected location TTL 5m, got %v", srv.locationTTL)
+	}
+	if srv.mstNakCooldown != 30*time.Second {
+		t.Errorf("Expected MSTNAK cooldown 30s, got %v", srv.mstNakCooldown)
+	}
+}
+
 func TestServer_StartStop(t *testing.T) {
 	cfg := config.SystemConfig{
 		Mode:       "MASTER",
@@ -152,6 +197,9 @@ func TestServer_HandleRPTL(t *testing.T) {
 	if peer == nil {
 		t.Fatal("Peer not found in manager")
 	}
+	if got := peer.GetSystem(); got != "test-system" {
+		t.Errorf("Expected peer owned by test-system, got %q", got)
+	}
 }
 
 func TestServer_HandleRPTK(t *testing.T) {
@@ -204,14 +252,15 @@ func TestServer_HandleRPTK(t *testing.T) {
 	if err := clientConn.SetReadDeadline(time.Now().Add(2 * time.Second)); err != nil {
 		t.Fatalf("SetReadDeadline error: %v", err)
 	}
-	if _, err := clientConn.Read(buffer); err != nil {
+	n, err := clientConn.Read(buffer)
+	if err != nil {
 		t.Fatalf("Read error: %v", err)
 	}
 
 	// Send RPTK
-	challenge := make([]byte, 32)
-	for i := range challenge {
-		challenge[i] = byte(i)
+	challenge, err := answerChallenge(buffer[:n], "test")
+	if err != nil {
+		t.Fatalf("answerChallenge error: %v", err)
 	}
 	rptk := &protocol.RPTKPacket{
 		RepeaterID: 312000,
@@ -226,10 +275,7 @@ func TestServer_HandleRPTK(t *testing.T) {
 	if err := clientConn.SetReadDeadline(time.Now().Add(2 * time.Second)); err != nil {
 		t.Fatalf("SetReadDeadline error: %v", err)
 	}
-	n, err := clientConn.Read(buffer)
-	if err != nil {
-		t.Fatalf("Read error: %v", err)
-	}
+	n, err = clientConn.Read(buffer)
 	if err != nil {
 		t.Fatalf("Failed to receive RPTACK after RPTK: %v", err)
 	}
@@ -288,12 +334,16 @@ func TestServer_HandleRPTC(t *testing.T) {
 	if err := clientConn.SetReadDeadline(time.Now().Add(2 * time.Second)); err != nil {
 		t.Fatalf("SetReadDeadline error: %v", err)
 	}
-	if _, err := clientConn.Read(buffer); err != nil {
+	n, err := clientConn.Read(buffer)
+	if err != nil {
 		t.Fatalf("Read error: %v", err)
 	}
 
 	// Send RPTK
-	challenge := make([]byte, 32)
+	challenge, err := answerChallenge(buffer[:n], "test")
+	if err != nil {
+		t.Fatalf("answerChallenge error: %v", err)
+	}
 	rptk := &protocol.RPTKPacket{RepeaterID: 312000, Challenge: challenge}
 	data, _ = rptk.Encode()
 	if _, err := clientConn.Write(data); err != nil {
@@ -322,10 +372,7 @@ func TestServer_HandleRPTC(t *testing.T) {
 	if err := clientConn.SetReadDeadline(time.Now().Add(2 * time.Second)); err != nil {
 		t.Fatalf("SetReadDeadline error: %v", err)
 	}
-	n, err := clientConn.Read(buffer)
-	if err != nil {
-		t.Fatalf("Read error: %v", err)
-	}
+	n, err = clientConn.Read(buffer)
 	if err != nil {
 		t.Fatalf("Failed to receive RPTACK after RPTC: %v", err)
 	}
@@ -602,6 +649,69 @@ func TestServer_HandleRPTPING_SendsMSTPONG(t *testing.T) {
 	}
 }
 
+// A peer that pings after RPTK but before RPTC is NAKed so it re-runs the
+// handshake, and is not kept alive by its pings
+func TestServer_HandleRPTPING_BeforeRPTC_SendsMSTNAK(t *testing.T) {
+	cfg := config.SystemConfig{Mode: "MASTER"}
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(cfg, "test-system", log)
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	senderConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("sender ListenUDP error: %v", err)
+	}
+	defer func() { _ = senderConn.Close() }()
+
+	// Logged in and authenticated, but no RPTC yet
+	peerID := uint32(312001)
+	p := srv.peerManager.AddPeer(peerID, senderConn.LocalAddr().(*net.UDPAddr))
+	p.SetState(peer.StateAuthenticated)
+	lastHeard := time.Now().Add(-10 * time.Second)
+	p.LastHeard = lastHeard
+
+	ping := make([]byte, protocol.RPTPINGPacketSize)
+	copy(ping[0:7], protocol.PacketTypeRPTPING)
+	binary.BigEndian.PutUint32(ping[7:11], peerID)
+
+	if err := senderConn.SetReadDeadline(time.Now().Add(1 * time.Second)); err != nil {
+		t.Fatalf("SetReadDeadline error: %v", err)
+	}
+	srv.handleRPTPING(ping, senderConn.LocalAddr().(*net.UDPAddr))
+
+	buf := make([]byte, 64)
+	n, _, err := senderConn.ReadFromUDP(buf)
+	if err != nil {
+		t.Fatalf("sender ReadFromUDP error: %v", err)
+	}
+	if string(buf[0:6]) != protocol.PacketTypeMSTNAK {
+		t.Fatalf("expected MSTNAK, got %q", string(buf[0:n]))
+	}
+	if got := p.GetLastHeard(); !got.Equal(lastHeard) {
+		t.Errorf("expected last heard unchanged by a pre-RPTC ping, got %v", got)
+	}
+
+	// Once configured, the same peer is ponged
+	p.SetConnected()
+	if err := senderConn.SetReadDeadline(time.Now().Add(1 * time.Second)); err != nil {
+		t.Fatalf("SetReadDeadline error: %v", err)
+	}
+	srv.handleRPTPING(ping, senderConn.LocalAddr().(*net.UDPAddr))
+	n, _, err = senderConn.ReadFromUDP(buf)
+	if err != nil {
+		t.Fatalf("sender ReadFromUDP error: %v", err)
+	}
+	if string(buf[0:7]) != protocol.PacketTypeMSTPONG {
+		t.Fatalf("expected MSTPONG after RPTC, got %q", string(buf[0:n]))
+	}
+}
+
 func TestServer_HandleRPTPING_UnknownPeer_CooldownBehavior(t *testing.T) {
 	cfg := config.SystemConfig{Mode: "MASTER"}
 	log := logger.New(logger.Config{Level: "info"})
@@ -935,6 +1045,148 @@ func TestStreamMuteFirstTransmission_AAA(t *testing.T) {
 	}
 }
 
+func TestServer_TerminatorFormsUnmuteFirstKeyup(t *testing.T) {
+	cfg := config.SystemConfig{Mode: "MASTER"}
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(cfg, "test-system", log).WithRouter(bridge.NewRouter())
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	addr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65012}
+	srv.peerManager.AddPeer(111, addr).SetConnected()
+
+	send := func(tgid, streamID uint32, frameType, dataType byte) {
+		dmrd := &protocol.DMRDPacket{
+			SourceID:      3120001,
+			DestinationID: tgid,
+			RepeaterID:    111,
+			Timeslot:      1,
+			CallType:      protocol.CallTypeGroup,
+			FrameType:     frameType,
+			DataType:      dataType,
+			StreamID:      streamID,
+			Payload:       make([]byte, 33),
+		}
+		data, err := dmrd.Encode()
+		if err != nil {
+			t.Fatalf("Encode DMRD error: %v", err)
+		}
+		srv.handleDMRD(data, addr)
+	}
+	muted := func(streamID uint32) bool {
+		_, ok := srv.mutedStreams[streamID]
+		return ok
+	}
+
+	// A first key-up on a new TG is muted until its terminator
+	const tgid, streamID = 3100, 500
+	send(tgid, streamID, protocol.FrameTypeVoiceHeader, 0)
+	if !muted(streamID) {
+		t.Fatalf("Expected first key-up stream %d muted", streamID)
+	}
+	// A PI header or a CSBK is not the end of the call
+	for _, dataType := range []byte{protocol.DataTypePIHeader, 0x03} {
+		send(tgid, streamID, protocol.FrameTypeVoiceTerminator, dataType)
+		if !muted(streamID) {
+			t.Fatalf("Data type %d unmuted stream %d", dataType, streamID)
+		}
+	}
+	send(tgid, streamID, protocol.FrameTypeVoiceTerminator, protocol.DataTypeTerminatorLC)
+	if muted(streamID) {
+		t.Errorf("Terminator with LC did not unmute stream %d", streamID)
+	}
+}
+
+func TestServer_RevokedStreamTerminatedDownstream(t *testing.T) {
+	router := bridge.NewRouter()
+	cfg := config.SystemConfig{Mode: "MASTER"}
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(cfg, "test-system", log).WithRouter(router)
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	listenConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = listenConn.Close() }()
+	listener := srv.peerManager.AddPeer(222, listenConn.LocalAddr().(*net.UDPAddr))
+	listener.SetConnected()
+	listener.Subscriptions.AddDynamic(3100, 1)
+
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65015}
+	source := srv.peerManager.AddPeer(111, srcAddr)
+	source.SetConnected()
+	source.Subscriptions.AddDynamic(3100, 1)
+	router.GetOrCreateDynamicBridge(3100)
+
+	send := func(frameType byte) {
+		dmrd := &protocol.DMRDPacket{
+			SourceID:      3120001,
+			DestinationID: 3100,
+			RepeaterID:    111,
+			Timeslot:      1,
+			CallType:      protocol.CallTypeGroup,
+			FrameType:     frameType,
+			StreamID:      700,
+			Payload:       make([]byte, 33),
+		}
+		data, err := dmrd.Encode()
+		if err != nil {
+			t.Fatalf("Encode DMRD error: %v", err)
+		}
+		srv.handleDMRD(data, srcAddr)
+	}
+
+	send(protocol.FrameTypeVoiceHeader)
+	send(protocol.FrameTypeVoice)
+	if !router.RevokeStream(700) {
+		t.Fatal("Expected stream 700 to be active")
+	}
+	// A jammed PTT keeps sending
+	send(protocol.FrameTypeVoice)
+	send(protocol.FrameTypeVoice)
+
+	var got []byte
+	var last *protocol.DMRDPacket
+	buf := make([]byte, 2048)
+	for {
+		if err := listenConn.SetReadDeadline(time.Now().Add(200 * time.Millisecond)); err != nil {
+			t.Fatalf("SetReadDeadline error: %v", err)
+		}
+		n, _, err := listenConn.ReadFromUDP(buf)
+		if err != nil {
+			break
+		}
+		pkt, err := protocol.ParseDMRD(buf[:n])
+		if err != nil {
+			t.Fatalf("ParseDMRD error: %v", err)
+		}
+		got = append(got, pkt.FrameType)
+		last = pkt
+	}
+
+	want := []byte{protocol.FrameTypeVoiceHeader, protocol.FrameTypeVoice, protocol.FrameTypeVoiceTerminator}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Expected header, voice, then one forced terminator; got frame types %v", got)
+	}
+	// Data type 0 on that frame type is a PI header, which radios don't
+	// take as the end of the call
+	if last != nil && last.DataType != protocol.DataTypeTerminatorLC {
+		t.Errorf("Expected the forced terminator to carry data type %d, got %d", protocol.DataTypeTerminatorLC, last.DataType)
+	}
+}
+
 // TestServer_PrivateCallRouting tests private call routing between two peers
 func TestServer_PrivateCallRouting(t *testing.T) {
 	cfg := config.SystemConfig{
@@ -1068,9 +1320,7 @@ func TestServer_PrivateCallRouting(t *testing.T) {
 	time.Sleep(500 * time.Millisecond)
 
 	// Debug: check the subscriber map directly
-	srv.subscriberLocationsMu.RLock()
-	t.Logf("Subscriber locations map: %+v", srv.subscriberLocations)
-	srv.subscriberLocationsMu.RUnlock()
+	t.Logf("Subscriber locations tracked: %d", srv.subscriberLocations.len())
 
 	// Verify subscriber locations are tracked
 	loc1, found1 := srv.lookupSubscriberLocation(radio1ID)
@@ -1324,6 +1574,95 @@ func TestServer_PrivateCallUnknownDestination(t *testing.T) {
 	}
 }
 
+// TestServer_PrivateCallToPeerID tests private calls addressed to a peer ID
+func TestServer_PrivateCallToPeerID(t *testing.T) {
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65041}
+
+	setup := func(t *testing.T, toPeers bool) (*Server, *net.UDPConn, *net.UDPConn) {
+		t.Helper()
+		cfg := config.SystemConfig{Mode: "MASTER", PrivateCallsEnabled: true, PrivateCallsToPeers: toPeers}
+		srv := NewServer(cfg, "test-system", logger.New(logger.Config{Level: "error"}))
+
+		serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+		if err != nil {
+			t.Fatalf("ListenUDP error: %v", err)
+		}
+		srv.conn = serverConn
+		t.Cleanup(func() { _ = serverConn.Close() })
+
+		listen := func(id uint32) *net.UDPConn {
+			conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+			if err != nil {
+				t.Fatalf("ListenUDP error: %v", err)
+			}
+			t.Cleanup(func() { _ = conn.Close() })
+			srv.peerManager.AddPeer(id, conn.LocalAddr().(*net.UDPAddr)).SetConnected()
+			return conn
+		}
+		repeater := listen(312002)
+		elsewhere := listen(312003)
+		srv.peerManager.AddPeer(312001, srcAddr).SetConnected()
+		return srv, repeater, elsewhere
+	}
+
+	send := func(t *testing.T, srv *Server, stream uint32) {
+		t.Helper()
+		dmrd := &protocol.DMRDPacket{
+			SourceID:      3120001,
+			DestinationID: 312002,
+			RepeaterID:    312001,
+			Timeslot:      1,
+			CallType:      protocol.CallTypePrivate,
+			FrameType:     protocol.FrameTypeVoiceHeader,
+			StreamID:      stream,
+			Payload:       make([]byte, 33),
+		}
+		data, err := dmrd.Encode()
+		if err != nil {
+			t.Fatalf("Encode DMRD error: %v", err)
+		}
+		srv.handleDMRD(data, srcAddr)
+	}
+
+	received := func(conn *net.UDPConn) bool {
+		buf := make([]byte, 512)
+		_ = conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		_, _, err := conn.ReadFromUDP(buf)
+		return err == nil
+	}
+
+	t.Run("delivered to the addressed peer", func(t *testing.T) {
+		srv, repeater, elsewhere := setup(t, true)
+		send(t, srv, 1)
+		if !received(repeater) {
+			t.Error("Expected the private call on the peer it is addressed to")
+		}
+		if received(elsewhere) {
+			t.Error("Private call must not reach other peers")
+		}
+	})
+
+	t.Run("and to a radio heard with the same ID", func(t *testing.T) {
+		srv, repeater, elsewhere := setup(t, true)
+		srv.trackSubscriberLocation(312002, 312003)
+		send(t, srv, 2)
+		if !received(elsewhere) {
+			t.Error("Expected the private call where the radio ID was last heard")
+		}
+		if !received(repeater) {
+			t.Error("Expected the private call on the addressed peer too")
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		srv, repeater, _ := setup(t, false)
+		send(t, srv, 3)
+		if received(repeater) {
+			t.Error("Private calls to peer IDs must not be delivered unless enabled")
+		}
+	})
+}
+
 // TestServer_SubscriberLocationCleanup tests that stale subscriber locations are cleaned up
 func TestServer_SubscriberLocationCleanup(t *testing.T) {
 	cfg := config.SystemConfig{
@@ -1342,27 +1681,39 @@ func TestServer_SubscriberLocationCleanup(t *testing.T) {
 	srv.trackSubscriberLocation(radioID, peerID)
 
 	// Verify it exists
-	_, found := srv.subscriberLocations[radioID]
+	_, found := srv.subscriberLocations.get(radioID)
 	if !found {
 		t.Fatal("Subscriber location should be tracked")
 	}
 
 	// Manually set the last seen time to 20 minutes ago
-	srv.subscriberLocationsMu.Lock()
-	srv.subscriberLocations[radioID].lastSeen = time.Now().Add(-20 * time.Minute)
-	srv.subscriberLocationsMu.Unlock()
+	srv.subscriberLocations.set(radioID, peerID, time.Now().Add(-20*time.Minute))
 
 	// Run cleanup with 15 minute TTL
 	srv.cleanupStaleSubscriberLocations(15 * time.Minute)
 
 	// Verify it's cleaned up
-	_, found = srv.subscriberLocations[radioID]
+	_, found = srv.subscriberLocations.get(radioID)
 	if found {
 		t.Error("Stale subscriber location should be cleaned up")
 	}
 }
 
-// connectPeer performs a full connection handshake for a peer
+// answerChallenge computes a peer's RPTK challenge from the server's RPTACK
+// reply to RPTL: SHA256(salt + passphrase)
+func answerChallenge(rptack []byte, passphrase string) ([]byte, error) {
+	ack := &protocol.RPTACKPacket{}
+	if err := ack.Parse(rptack); err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	h.Write(ack.Salt)
+	h.Write([]byte(passphrase))
+	return h.Sum(nil), nil
+}
+
+// connectPeer runs the full login handshake against a server whose
+// passphrase is "test"
 func connectPeer(conn *net.UDPConn, peerID uint32, callsign string) error {
 	buffer := make([]byte, 1024)
 
@@ -1377,12 +1728,16 @@ func connectPeer(conn *net.UDPConn, peerID uint32, callsign string) error {
 	if err := conn.SetReadDeadline(time.Now().Add(2 * time.Second)); err != nil {
 		return err
 	}
-	if _, err := conn.Read(buffer); err != nil {
+	n, err := conn.Read(buffer)
+	if err != nil {
 		return err
 	}
 
 	// Send RPTK
-	challenge := make([]byte, 32)
+	challenge, err := answerChallenge(buffer[:n], "test")
+	if err != nil {
+		return err
+	}
 	rptk := &protocol.RPTKPacket{
 		RepeaterID: peerID,
 		Challenge:  challenge,
@@ -1590,3 +1945,1027 @@ func TestServer_HandleDMRD_KnownNonConnectedPeer_ReceivesMSTNAK(t *testing.T) {
 		t.Fatalf("Expected timeout error, got: %v", err)
 	}
 }
+
+// timeoutError is a net.Error that reports a read deadline expiry
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// fakeUDPConn is a udpConn whose reads always fail with readErr
+type fakeUDPConn struct {
+	readErr error
+	closed  chan struct{}
+}
+
+func newFakeUDPConn(readErr error) *fakeUDPConn {
+	return &fakeUDPConn{readErr: readErr, closed: make(chan struct{})}
+}
+
+func (f *fakeUDPConn) ReadFromUDP(b []byte) (int, *net.UDPAddr, error) {
+	time.Sleep(time.Millisecond)
+	return 0, nil, f.readErr
+}
+
+func (f *fakeUDPConn) WriteToUDP(b []byte, addr *net.UDPAddr) (int, error) {
+	return len(b), nil
+}
+
+func (f *fakeUDPConn) SetReadDeadline(t time.Time) error { return nil }
+
+func (f *fakeUDPConn) LocalAddr() net.Addr {
+	return &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 62031}
+}
+
+func (f *fakeUDPConn) Close() error {
+	select {
+	case <-f.closed:
+	default:
+		close(f.closed)
+	}
+	return nil
+}
+
+func TestServer_ReceiveLoop_RebindsAfterConsecutiveReadErrors(t *testing.T) {
+	cfg := config.SystemConfig{Mode: "MASTER"}
+	log := logger.New(logger.Config{Level: "error"})
+	collector := metrics.NewCollector()
+	srv := NewServer(cfg, "test-system", log).WithMetrics(collector)
+	srv.maxConsecutiveReadErrors = 3
+
+	sick := newFakeUDPConn(errors.New("socket is sick"))
+	healthy := newFakeUDPConn(timeoutError{})
+	srv.conn = sick
+	srv.listen = func() (udpConn, error) {
+		return healthy, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- srv.receiveLoop(ctx)
+	}()
+
+	select {
+	case <-sick.closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("sick connection was never closed")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for srv.RebindCount() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	cancel()
+	if err := <-errChan; err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+
+	if srv.getConn() != healthy {
+		t.Error("Expected server to use the rebound connection")
+	}
+	if got := srv.RebindCount(); got != 1 {
+		t.Errorf("Expected 1 rebind, got %d", got)
+	}
+	if got := srv.ReadErrorCount(); got != 3 {
+		t.Errorf("Expected 3 read errors, got %d", got)
+	}
+	if got := srv.ConsecutiveReadErrors(); got != 0 {
+		t.Errorf("Expected consecutive read errors reset to 0, got %d", got)
+	}
+	if got := collector.GetUDPReadErrors(); got != 3 {
+		t.Errorf("Expected collector to record 3 read errors, got %d", got)
+	}
+	if got := collector.GetUDPRebinds(); got != 1 {
+		t.Errorf("Expected collector to record 1 rebind, got %d", got)
+	}
+}
+
+func TestServer_ReceiveLoop_RebindFailureStopsLoop(t *testing.T) {
+	cfg := config.SystemConfig{Mode: "MASTER"}
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(cfg, "test-system", log)
+	srv.maxConsecutiveReadErrors = 2
+	srv.conn = newFakeUDPConn(errors.New("socket is sick"))
+	srv.listen = func() (udpConn, error) {
+		return nil, errors.New("address in use")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	err := srv.receiveLoop(ctx)
+	if err == nil || err == context.DeadlineExceeded {
+		t.Fatalf("Expected rebind error, got %v", err)
+	}
+	if got := srv.ReadErrorCount(); got != 2 {
+		t.Errorf("Expected 2 read errors, got %d", got)
+	}
+}
+
+// TestServer_PrivateCallAcrossStaticBridge tests a private call crossing a static bridge between two systems
+func TestServer_PrivateCallAcrossStaticBridge(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	router := bridge.NewRouter()
+	rules := bridge.NewBridgeRuleSet("LINK")
+	rules.AddRule(&bridge.BridgeRule{System: "SYSTEM-A", TGID: 3100, Timeslot: 1, Active: true})
+	rules.AddRule(&bridge.BridgeRule{System: "SYSTEM-B", TGID: 3100, Timeslot: 1, Active: true})
+	router.AddBridge(rules)
+
+	cfg := config.SystemConfig{
+		Mode:                "MASTER",
+		PrivateCallsEnabled: true,
+		BridgePrivateCalls:  true,
+	}
+	srvA := NewServer(cfg, "SYSTEM-A", log).WithRouter(router)
+	srvB := NewServer(cfg, "SYSTEM-B", log).WithRouter(router)
+
+	for _, srv := range []*Server{srvA, srvB} {
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+		if err != nil {
+			t.Fatalf("ListenUDP error: %v", err)
+		}
+		srv.conn = conn
+		defer func() { _ = conn.Close() }()
+	}
+
+	// Calling radio behind a peer on system A
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65001}
+	srcPeer := srvA.peerManager.AddPeer(111, srcAddr)
+	srcPeer.SetConnected()
+
+	// Called radio behind a peer on system B
+	destConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("dest ListenUDP error: %v", err)
+	}
+	defer func() { _ = destConn.Close() }()
+	destPeer := srvB.peerManager.AddPeer(222, destConn.LocalAddr().(*net.UDPAddr))
+	destPeer.SetConnected()
+	srvB.trackSubscriberLocation(3120002, destPeer.ID)
+
+	dmrd := &protocol.DMRDPacket{
+		Sequence:      1,
+		SourceID:      3120001,
+		DestinationID: 3120002,
+		RepeaterID:    111,
+		Timeslot:      1,
+		CallType:      protocol.CallTypePrivate,
+		StreamID:      424242,
+		Payload:       make([]byte, 33),
+	}
+	data, err := dmrd.Encode()
+	if err != nil {
+		t.Fatalf("Encode DMRD error: %v", err)
+	}
+
+	srvA.handleDMRD(data, srcAddr)
+
+	if err := destConn.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("SetReadDeadline error: %v", err)
+	}
+	buf := make([]byte, 2048)
+	n, _, err := destConn.ReadFromUDP(buf)
+	if err != nil {
+		t.Fatalf("Peer on system B should receive bridged private call: %v", err)
+	}
+	received, err := protocol.ParseDMRD(buf[:n])
+	if err != nil {
+		t.Fatalf("Failed to parse received DMRD: %v", err)
+	}
+	if received.CallType != protocol.CallTypePrivate || received.DestinationID != 3120002 {
+		t.Errorf("Unexpected packet received: call_type=%d dst=%d", received.CallType, received.DestinationID)
+	}
+	if got := destPeer.Snapshot(false).PacketsTx; got != 1 {
+		t.Errorf("Expected 1 packet sent to destination peer, got %d", got)
+	}
+}
+
+func TestServer_GroupCallReachesBridgedPeerSystem(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	router := bridge.NewRouter()
+	router.AddBridge(bridge.NewBridgeRuleSetFromConfig("NATIONWIDE", []config.BridgeRule{
+		{System: "test-system", TGID: 3100, Timeslot: 1, Active: true},
+		{System: "UPSTREAM", TGID: 3100, Timeslot: 1, Active: true},
+	}))
+	upstream := make(chan *protocol.DMRDPacket, 4)
+	router.RegisterSystem("UPSTREAM", func(p *protocol.DMRDPacket, _ []byte) { upstream <- p })
+	self := make(chan *protocol.DMRDPacket, 4)
+
+	srv := NewServer(config.SystemConfig{Mode: "MASTER"}, "test-system", log).WithRouter(router)
+	router.RegisterSystem("test-system", func(p *protocol.DMRDPacket, _ []byte) { self <- p })
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65021}
+	source := srv.peerManager.AddPeer(111, srcAddr)
+	source.SetConnected()
+	source.Subscriptions.AddDynamic(3100, 1)
+
+	dmrd := &protocol.DMRDPacket{
+		SourceID:      3120001,
+		DestinationID: 3100,
+		RepeaterID:    111,
+		Timeslot:      1,
+		CallType:      protocol.CallTypeGroup,
+		FrameType:     protocol.FrameTypeVoiceHeader,
+		StreamID:      0x3100,
+		Payload:       make([]byte, 33),
+	}
+	data, err := dmrd.Encode()
+	if err != nil {
+		t.Fatalf("Encode DMRD error: %v", err)
+	}
+	srv.handleDMRD(data, srcAddr)
+
+	// A local key-up on a bridged talkgroup goes up to the PEER system
+	select {
+	case p := <-upstream:
+		if p.StreamID != 0x3100 || p.SourceID != 3120001 {
+			t.Errorf("UPSTREAM got stream %#x from %d, want 0x3100 from 3120001", p.StreamID, p.SourceID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected local traffic on TG 3100 to reach UPSTREAM")
+	}
+
+	// ...and is not handed back to the system it came from
+	select {
+	case <-self:
+		t.Error("Expected the source system to be left out of its own targets")
+	default:
+	}
+}
+
+func TestServer_GroupCallAcrossMasterSystemsDeliveredOnce(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	router := bridge.NewRouter()
+	router.AddBridge(bridge.NewBridgeRuleSetFromConfig("NATIONWIDE", []config.BridgeRule{
+		{System: "MASTER-A", TGID: 3100, Timeslot: 1, Active: true},
+		{System: "MASTER-B", TGID: 3100, Timeslot: 1, Active: true},
+	}))
+
+	shared := peer.NewPeerManager()
+	servers := make(map[string]*Server)
+	for _, name := range []string{"MASTER-A", "MASTER-B"} {
+		srv := NewServer(config.SystemConfig{Mode: "MASTER"}, name, log).WithPeerManager(shared).WithRouter(router)
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+		if err != nil {
+			t.Fatalf("ListenUDP error: %v", err)
+		}
+		defer func() { _ = conn.Close() }()
+		srv.conn = conn
+		servers[name] = srv
+	}
+
+	// The talker is on MASTER-A, a listener on MASTER-B; both follow TG 3100
+	peerConns := make(map[uint32]*net.UDPConn)
+	for id, system := range map[uint32]string{111: "MASTER-A", 222: "MASTER-B"} {
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+		if err != nil {
+			t.Fatalf("ListenUDP error: %v", err)
+		}
+		defer func() { _ = conn.Close() }()
+		peerConns[id] = conn
+		p := shared.AddPeer(id, conn.LocalAddr().(*net.UDPAddr))
+		p.SetSystem(system)
+		p.SetConnected()
+		p.Subscriptions.AddDynamic(3100, 1)
+	}
+
+	dmrd := &protocol.DMRDPacket{
+		SourceID:      3120001,
+		DestinationID: 3100,
+		RepeaterID:    111,
+		Timeslot:      1,
+		CallType:      protocol.CallTypeGroup,
+		FrameType:     protocol.FrameTypeVoiceHeader,
+		StreamID:      0x3101,
+		Payload:       make([]byte, 33),
+	}
+	data, err := dmrd.Encode()
+	if err != nil {
+		t.Fatalf("Encode DMRD error: %v", err)
+	}
+	servers["MASTER-A"].handleDMRD(data, peerConns[111].LocalAddr().(*net.UDPAddr))
+
+	count := func(conn *net.UDPConn) int {
+		n := 0
+		buf := make([]byte, 2048)
+		for {
+			_ = conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+			if _, _, err := conn.ReadFromUDP(buf); err != nil {
+				return n
+			}
+			n++
+		}
+	}
+	if got := count(peerConns[222]); got != 1 {
+		t.Errorf("Listener on MASTER-B got the frame %d times, want once", got)
+	}
+	if got := count(peerConns[111]); got != 0 {
+		t.Errorf("Talker got their own frame back %d times", got)
+	}
+}
+
+// TestServer_DropsOverlappingStreamFromPeer tests that a second concurrent stream on the same slot is dropped
+func TestServer_DropsOverlappingStreamFromPeer(t *testing.T) {
+	cfg := config.SystemConfig{Mode: "MASTER", Repeat: true}
+	log := logger.New(logger.Config{Level: "error"})
+	collector := metrics.NewCollector()
+	srv := NewServer(cfg, "test-system", log).WithMetrics(collector)
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	destConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("dest ListenUDP error: %v", err)
+	}
+	defer func() { _ = destConn.Close() }()
+	srv.peerManager.AddPeer(222, destConn.LocalAddr().(*net.UDPAddr)).SetConnected()
+
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65002}
+	srv.peerManager.AddPeer(111, srcAddr).SetConnected()
+
+	send := func(streamID uint32) {
+		dmrd := &protocol.DMRDPacket{
+			Sequence:      1,
+			SourceID:      3120001,
+			DestinationID: 3100,
+			RepeaterID:    111,
+			Timeslot:      1,
+			CallType:      protocol.CallTypeGroup,
+			StreamID:      streamID,
+			Payload:       make([]byte, 33),
+		}
+		data, err := dmrd.Encode()
+		if err != nil {
+			t.Fatalf("Encode DMRD error: %v", err)
+		}
+		srv.handleDMRD(data, srcAddr)
+	}
+
+	send(1001)
+	send(1002)
+
+	buf := make([]byte, 2048)
+	if err := destConn.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("SetReadDeadline error: %v", err)
+	}
+	n, _, err := destConn.ReadFromUDP(buf)
+	if err != nil {
+		t.Fatalf("Expected first stream to be forwarded: %v", err)
+	}
+	first, err := protocol.ParseDMRD(buf[:n])
+	if err != nil {
+		t.Fatalf("ParseDMRD error: %v", err)
+	}
+	if first.StreamID != 1001 {
+		t.Errorf("Expected stream 1001 forwarded, got %d", first.StreamID)
+	}
+
+	if err := destConn.SetReadDeadline(time.Now().Add(200 * time.Millisecond)); err != nil {
+		t.Fatalf("SetReadDeadline error: %v", err)
+	}
+	if _, _, err := destConn.ReadFromUDP(buf); err == nil {
+		t.Error("Overlapping stream should have been dropped")
+	}
+
+	if got := collector.GetPacketsDropped("concurrent_stream"); got != 1 {
+		t.Errorf("Expected 1 concurrent stream drop, got %d", got)
+	}
+}
+
+// TestServer_ReceiveOnlyTalkgroup tests that inbound transmissions on a receive-only TG are dropped
+// while traffic from the designated source is still forwarded
+func TestServer_ReceiveOnlyTalkgroup(t *testing.T) {
+	cfg := config.SystemConfig{
+		Mode:   "MASTER",
+		Repeat: true,
+		ReceiveOnlyTGs: []config.ReceiveOnlyTG{
+			{TGID: 9911, Sources: []int{3120099}},
+		},
+	}
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(cfg, "test-system", log)
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	destConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("dest ListenUDP error: %v", err)
+	}
+	defer func() { _ = destConn.Close() }()
+	srv.peerManager.AddPeer(222, destConn.LocalAddr().(*net.UDPAddr)).SetConnected()
+
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65003}
+	srv.peerManager.AddPeer(111, srcAddr).SetConnected()
+
+	send := func(sourceID, streamID uint32) {
+		dmrd := &protocol.DMRDPacket{
+			Sequence:      1,
+			SourceID:      sourceID,
+			DestinationID: 9911,
+			RepeaterID:    111,
+			Timeslot:      1,
+			CallType:      protocol.CallTypeGroup,
+			FrameType:     protocol.FrameTypeVoiceTerminator,
+			DataType:      protocol.DataTypeTerminatorLC,
+			StreamID:      streamID,
+			Payload:       make([]byte, 33),
+		}
+		data, err := dmrd.Encode()
+		if err != nil {
+			t.Fatalf("Encode DMRD error: %v", err)
+		}
+		srv.handleDMRD(data, srcAddr)
+	}
+
+	buf := make([]byte, 2048)
+
+	// Ordinary user transmitting into the feed is dropped
+	send(3120001, 5001)
+	if err := destConn.SetReadDeadline(time.Now().Add(200 * time.Millisecond)); err != nil {
+		t.Fatalf("SetReadDeadline error: %v", err)
+	}
+	if _, _, err := destConn.ReadFromUDP(buf); err == nil {
+		t.Fatal("Transmission on receive-only TG should have been dropped")
+	}
+
+	// Designated source is forwarded
+	send(3120099, 5002)
+	if err := destConn.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("SetReadDeadline error: %v", err)
+	}
+	n, _, err := destConn.ReadFromUDP(buf)
+	if err != nil {
+		t.Fatalf("Designated source should be forwarded: %v", err)
+	}
+	received, err := protocol.ParseDMRD(buf[:n])
+	if err != nil {
+		t.Fatalf("ParseDMRD error: %v", err)
+	}
+	if received.SourceID != 3120099 {
+		t.Errorf("Expected source 3120099, got %d", received.SourceID)
+	}
+}
+
+func TestServer_PeerTalkgroupWhitelist(t *testing.T) {
+	cfg := config.SystemConfig{
+		Mode: "MASTER",
+		PeerAllowedTGs: []config.PeerAllowedTGs{
+			{PeerID: 111, TGs: []int{3100}},
+		},
+	}
+	log := logger.New(logger.Config{Level: "error"})
+	router := bridge.NewRouter()
+	srv := NewServer(cfg, "test-system", log).WithRouter(router)
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65004}
+	p := srv.peerManager.AddPeer(111, srcAddr)
+	rptc := &protocol.RPTCPacket{
+		RepeaterID:  111,
+		Callsign:    "W1ABC",
+		ColorCode:   "1",
+		Description: "Test Peer",
+	}
+	rptcData, err := rptc.Encode()
+	if err != nil {
+		t.Fatalf("Encode RPTC error: %v", err)
+	}
+	srv.handleRPTC(rptcData, srcAddr)
+
+	keyUp := func(tgid, streamID uint32) {
+		dmrd := &protocol.DMRDPacket{
+			Sequence:      1,
+			SourceID:      3120001,
+			DestinationID: tgid,
+			RepeaterID:    111,
+			Timeslot:      2,
+			CallType:      protocol.CallTypeGroup,
+			FrameType:     protocol.FrameTypeVoiceTerminator,
+			DataType:      protocol.DataTypeTerminatorLC,
+			StreamID:      streamID,
+			Payload:       make([]byte, 33),
+		}
+		data, err := dmrd.Encode()
+		if err != nil {
+			t.Fatalf("Encode DMRD error: %v", err)
+		}
+		srv.handleDMRD(data, srcAddr)
+	}
+
+	keyUp(9999, 6001)
+	if p.Subscriptions.HasTalkgroup(9999, 2) {
+		t.Error("Key-up on non-allowed TG should not subscribe the peer")
+	}
+
+	keyUp(3100, 6002)
+	if !p.Subscriptions.HasTalkgroup(3100, 2) {
+		t.Error("Key-up on allowed TG should subscribe the peer")
+	}
+
+	for _, db := range router.GetAllDynamicBridges() {
+		if db.TGID == 9999 {
+			t.Error("Key-up on non-allowed TG should not create a dynamic bridge")
+		}
+	}
+}
+
+func TestServer_MultipleListenPorts(t *testing.T) {
+	cfg := config.SystemConfig{
+		Mode:       "MASTER",
+		Port:       0,
+		ExtraPorts: []int{0},
+		Passphrase: "test",
+		RegACL:     "PERMIT:ALL",
+	}
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(cfg, "test-system", log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	go func() {
+		if err := srv.Start(ctx); err != nil && err != context.Canceled {
+			t.Logf("srv.Start error: %v", err)
+		}
+	}()
+	if err := srv.WaitStarted(ctx); err != nil {
+		t.Fatalf("server failed to start: %v", err)
+	}
+
+	addrs := srv.Addrs()
+	if len(addrs) != 2 {
+		t.Fatalf("Expected 2 listen addresses, got %d", len(addrs))
+	}
+	if addrs[0].Port == addrs[1].Port {
+		t.Fatalf("Expected distinct ports, got %d twice", addrs[0].Port)
+	}
+
+	// Clients are connected UDP sockets, so a reply from the wrong listener
+	// would be discarded and the handshake would time out
+	for i, addr := range addrs {
+		peerID := uint32(312100 + i)
+		clientConn, err := net.DialUDP("udp", nil, addr)
+		if err != nil {
+			t.Fatalf("Failed to create client connection: %v", err)
+		}
+		defer func() { _ = clientConn.Close() }()
+
+		if err := connectPeer(clientConn, peerID, "W1ABC"); err != nil {
+			t.Fatalf("Handshake on port %d failed: %v", addr.Port, err)
+		}
+
+		p := srv.peerManager.GetPeer(peerID)
+		if p == nil || p.GetState() != peer.StateConnected {
+			t.Errorf("Peer %d should be connected via port %d", peerID, addr.Port)
+		}
+	}
+
+	if got := srv.peerManager.Count(); got != 2 {
+		t.Errorf("Expected 2 peers in shared manager, got %d", got)
+	}
+}
+
+func TestServer_MaxPeers(t *testing.T) {
+	const maxPeers = 3
+	cfg := config.SystemConfig{Mode: "MASTER", Passphrase: "test", MaxPeers: maxPeers}
+	srv := NewServer(cfg, "test-system", logger.New(logger.Config{Level: "error"}))
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	login := func(peerID uint32) string {
+		t.Helper()
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+		if err != nil {
+			t.Fatalf("ListenUDP error: %v", err)
+		}
+		defer func() { _ = conn.Close() }()
+
+		rptl, err := (&protocol.RPTLPacket{RepeaterID: peerID}).Encode()
+		if err != nil {
+			t.Fatalf("Encode RPTL error: %v", err)
+		}
+		srv.handleRPTL(rptl, conn.LocalAddr().(*net.UDPAddr))
+
+		buf := make([]byte, 64)
+		_ = conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		n, _, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			t.Fatalf("No reply to RPTL from peer %d: %v", peerID, err)
+		}
+		return string(buf[:n])
+	}
+
+	// Peers of another MASTER sharing the peer manager don't fill the system
+	other := srv.peerManager.AddPeer(312200, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65061})
+	other.SetSystem("other-system")
+	other.SetConnected()
+
+	for i := uint32(0); i < maxPeers; i++ {
+		if reply := login(312100 + i); !strings.HasPrefix(reply, protocol.PacketTypeRPTACK) {
+			t.Fatalf("Peer %d got %q, want RPTACK", 312100+i, reply)
+		}
+	}
+
+	// Nor do logins that never finished
+	if reply := login(312100 + maxPeers); !strings.HasPrefix(reply, protocol.PacketTypeRPTACK) {
+		t.Fatalf("Peer beyond %d pending logins got %q, want RPTACK", maxPeers, reply)
+	}
+	srv.peerManager.RemovePeer(312100 + maxPeers)
+	for i := uint32(0); i < maxPeers; i++ {
+		srv.peerManager.GetPeer(312100 + i).SetConnected()
+	}
+
+	if reply := login(312100 + maxPeers); !strings.HasPrefix(reply, protocol.PacketTypeMSTCL) {
+		t.Errorf("Peer over max_peers got %q, want MSTCL", reply)
+	}
+	if srv.peerManager.GetPeer(312100+maxPeers) != nil {
+		t.Error("Peer over max_peers must not be added")
+	}
+	if got := srv.peerManager.Count(); got != maxPeers+1 {
+		t.Errorf("Expected %d peers, got %d", maxPeers+1, got)
+	}
+
+	// A peer already registered may log in again when the system is full
+	if reply := login(312100); !strings.HasPrefix(reply, protocol.PacketTypeRPTACK) {
+		t.Errorf("Known peer logging in again got %q, want RPTACK", reply)
+	}
+}
+
+func TestServer_BindAddress(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+
+	for _, ip := range []string{"127.0.0.1", "::1"} {
+		if ip == "::1" {
+			c, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv6loopback})
+			if err != nil {
+				t.Logf("Skipping %s: IPv6 unavailable: %v", ip, err)
+				continue
+			}
+			_ = c.Close()
+		}
+
+		cfg := config.SystemConfig{Mode: "MASTER", IP: ip, Port: 0, Passphrase: "test"}
+		srv := NewServer(cfg, "test-system", log)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		go func() { _ = srv.Start(ctx) }()
+		if err := srv.WaitStarted(ctx); err != nil {
+			cancel()
+			t.Fatalf("%s: server failed to start: %v", ip, err)
+		}
+
+		addr, err := srv.Addr()
+		cancel()
+		if err != nil {
+			t.Fatalf("%s: Addr error: %v", ip, err)
+		}
+		if !addr.IP.Equal(net.ParseIP(ip)) {
+			t.Errorf("Expected server bound to %s, got %s", ip, addr.IP)
+		}
+	}
+
+	cfg := config.SystemConfig{Mode: "MASTER", IP: "not-an-ip", Port: 0, Passphrase: "test"}
+	err := NewServer(cfg, "test-system", log).Start(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "not-an-ip") {
+		t.Errorf("Expected an invalid bind address error, got %v", err)
+	}
+}
+
+func TestServer_AuthWebhook(t *testing.T) {
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req AuthRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		var decision AuthDecision
+		switch req.PeerID {
+		case 311001:
+			decision = AuthDecision{Allow: true}
+		case 311002:
+			decision = AuthDecision{Allow: false, Reason: "suspended"}
+		case 311003:
+			decision = AuthDecision{Allow: true, Options: "TS2=91"}
+		}
+		_ = json.NewEncoder(w).Encode(decision)
+	}))
+	defer hook.Close()
+
+	cfg := config.SystemConfig{Mode: "MASTER", AuthWebhook: hook.URL}
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(cfg, "test-system", log)
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	configure := func(peerID uint32, description string) *peer.Peer {
+		addr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: int(peerID % 65536)}
+		srv.peerManager.AddPeer(peerID, addr)
+		rptc := &protocol.RPTCPacket{
+			RepeaterID:  peerID,
+			Callsign:    "W1ABC",
+			ColorCode:   "1",
+			Description: description,
+		}
+		data, err := rptc.Encode()
+		if err != nil {
+			t.Fatalf("Encode RPTC error: %v", err)
+		}
+		srv.handleRPTC(data, addr)
+		return srv.peerManager.GetPeer(peerID)
+	}
+
+	if p := configure(311001, "Allowed"); p == nil || p.GetState() != peer.StateConnected {
+		t.Error("Peer allowed by webhook should be connected")
+	}
+
+	if p := configure(311002, "Denied"); p != nil {
+		t.Error("Peer denied by webhook should be removed")
+	}
+
+	p := configure(311003, "OPTIONS:TS1=3100")
+	if p == nil || p.GetState() != peer.StateConnected {
+		t.Fatal("Peer with forced OPTIONS should be connected")
+	}
+	if !p.Subscriptions.HasTalkgroup(91, 2) {
+		t.Error("Forced OPTIONS should subscribe TS2/91")
+	}
+	if p.Subscriptions.HasTalkgroup(3100, 1) {
+		t.Error("Forced OPTIONS should replace OPTIONS from the description")
+	}
+
+	// The peer's own RPTO is ignored while OPTIONS are forced
+	rpto := append([]byte("RPTO"), 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(rpto[4:8], 311003)
+	rpto = append(rpto, []byte("TS1=9")...)
+	srv.handleRPTO(rpto, p.Address)
+	if p.Subscriptions.HasTalkgroup(9, 1) {
+		t.Error("RPTO should not override forced OPTIONS")
+	}
+}
+
+func TestServer_LowBandwidthPeerDownsampling(t *testing.T) {
+	cfg := config.SystemConfig{
+		Mode:              "MASTER",
+		Repeat:            true,
+		LowBandwidthPeers: []int{333},
+	}
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(cfg, "test-system", log)
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	listen := func(id uint32) *net.UDPConn {
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+		if err != nil {
+			t.Fatalf("ListenUDP error: %v", err)
+		}
+		srv.peerManager.AddPeer(id, conn.LocalAddr().(*net.UDPAddr)).SetConnected()
+		return conn
+	}
+	fullConn := listen(222)
+	defer func() { _ = fullConn.Close() }()
+	thinConn := listen(333)
+	defer func() { _ = thinConn.Close() }()
+
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65005}
+	srv.peerManager.AddPeer(111, srcAddr).SetConnected()
+
+	send := func(seq byte, frameType, dataType byte) {
+		dmrd := &protocol.DMRDPacket{
+			Sequence:      seq,
+			SourceID:      3120001,
+			DestinationID: 3100,
+			RepeaterID:    111,
+			Timeslot:      1,
+			CallType:      protocol.CallTypeGroup,
+			FrameType:     frameType,
+			DataType:      dataType,
+			StreamID:      7001,
+			Payload:       make([]byte, 33),
+		}
+		data, err := dmrd.Encode()
+		if err != nil {
+			t.Fatalf("Encode DMRD error: %v", err)
+		}
+		srv.handleDMRD(data, srcAddr)
+	}
+
+	// Header, two superframes of voice (bursts A-F), terminator
+	seq := byte(0)
+	send(seq, protocol.FrameTypeVoiceHeader, 0)
+	for sf := 0; sf < 2; sf++ {
+		for burst := byte(0); burst < 6; burst++ {
+			seq++
+			send(seq, protocol.FrameTypeVoice, burst)
+		}
+	}
+	seq++
+	send(seq, protocol.FrameTypeVoiceTerminator, protocol.DataTypeTerminatorLC)
+
+	collect := func(conn *net.UDPConn) []*protocol.DMRDPacket {
+		var frames []*protocol.DMRDPacket
+		buf := make([]byte, 2048)
+		for {
+			if err := conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond)); err != nil {
+				t.Fatalf("SetReadDeadline error: %v", err)
+			}
+			n, _, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return frames
+			}
+			pkt, err := protocol.ParseDMRD(buf[:n])
+			if err != nil {
+				t.Fatalf("ParseDMRD error: %v", err)
+			}
+			frames = append(frames, pkt)
+		}
+	}
+
+	full := collect(fullConn)
+	thin := collect(thinConn)
+
+	if len(full) != 14 {
+		t.Errorf("Full-rate peer expected 14 frames, got %d", len(full))
+	}
+	if len(thin) != 4 {
+		t.Errorf("Low-bandwidth peer expected 4 frames (header, 2 sync bursts, terminator), got %d", len(thin))
+	}
+
+	var header, terminator bool
+	for _, f := range thin {
+		switch f.FrameType {
+		case protocol.FrameTypeVoiceHeader:
+			header = true
+		case protocol.FrameTypeVoiceTerminator:
+			terminator = true
+		case protocol.FrameTypeVoice:
+			if f.DataType != 0 {
+				t.Errorf("Low-bandwidth peer received non-sync voice burst %d", f.DataType)
+			}
+		}
+	}
+	if !header || !terminator {
+		t.Errorf("Low-bandwidth peer must receive header and terminator (header=%v terminator=%v)", header, terminator)
+	}
+}
+
+func TestServer_RPTOUnlinkAll(t *testing.T) {
+	cfg := config.SystemConfig{Mode: "MASTER"}
+	log := logger.New(logger.Config{Level: "error"})
+	router := bridge.NewRouter()
+	srv := NewServer(cfg, "test-system", log).WithRouter(router)
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	addr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65006}
+	p := srv.peerManager.AddPeer(111, addr)
+	p.SetConnected()
+	p.SetRepeatMode(true)
+	p.Subscriptions.AddDynamic(9990, 1)
+	p.Subscriptions.AddDynamic(9991, 2)
+	router.AddSubscriberToDynamicBridge(9990, 111)
+	router.AddSubscriberToDynamicBridge(9991, 111)
+
+	rpto := append([]byte("RPTO"), 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(rpto[4:8], 111)
+	rpto = append(rpto, []byte("UNLINK=ALL")...)
+	srv.handleRPTO(rpto, addr)
+
+	if p.Subscriptions.HasTalkgroup(9990, 1) || p.Subscriptions.HasTalkgroup(9991, 2) {
+		t.Error("UNLINK=ALL should clear dynamic subscriptions on both timeslots")
+	}
+	if p.GetRepeatMode() {
+		t.Error("UNLINK=ALL should disable repeat mode like TG 4000")
+	}
+	if subs := router.GetDynamicBridgeSubscribers(9990); len(subs) != 0 {
+		t.Errorf("Peer should be removed from dynamic bridges, got %v", subs)
+	}
+}
+
+func TestServer_KeyupGuard(t *testing.T) {
+	cfg := config.SystemConfig{Mode: "MASTER", KeyupGuardMs: 100}
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(cfg, "test-system", log).WithRouter(bridge.NewRouter())
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	listenConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = listenConn.Close() }()
+	listener := srv.peerManager.AddPeer(222, listenConn.LocalAddr().(*net.UDPAddr))
+	listener.SetConnected()
+	listener.Subscriptions.AddDynamic(3100, 1)
+
+	// Both sources are already subscribed so their key-ups are not muted
+	addrA := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65007}
+	peerA := srv.peerManager.AddPeer(111, addrA)
+	peerA.SetConnected()
+	peerA.Subscriptions.AddDynamic(3100, 1)
+	addrB := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65008}
+	peerB := srv.peerManager.AddPeer(112, addrB)
+	peerB.SetConnected()
+	peerB.Subscriptions.AddDynamic(3100, 1)
+
+	transmit := func(addr *net.UDPAddr, peerID, src, streamID uint32) {
+		for _, ft := range []byte{protocol.FrameTypeVoiceHeader, protocol.FrameTypeVoice, protocol.FrameTypeVoiceTerminator} {
+			dmrd := &protocol.DMRDPacket{
+				SourceID:      src,
+				DestinationID: 3100,
+				RepeaterID:    peerID,
+				Timeslot:      1,
+				CallType:      protocol.CallTypeGroup,
+				FrameType:     ft,
+				StreamID:      streamID,
+				Payload:       make([]byte, 33),
+			}
+			if ft == protocol.FrameTypeVoiceTerminator {
+				dmrd.DataType = protocol.DataTypeTerminatorLC
+			}
+			data, err := dmrd.Encode()
+			if err != nil {
+				t.Fatalf("Encode DMRD error: %v", err)
+			}
+			srv.handleDMRD(data, addr)
+		}
+	}
+
+	transmit(addrA, 111, 3120001, 1)
+	transmit(addrB, 112, 3120002, 2) // Different source inside the guard: dropped
+	transmit(addrA, 111, 3120001, 3) // Same source may key up again
+	time.Sleep(150 * time.Millisecond)
+	transmit(addrB, 112, 3120002, 4) // Guard expired
+
+	counts := make(map[uint32]int)
+	buf := make([]byte, 2048)
+	for {
+		if err := listenConn.SetReadDeadline(time.Now().Add(200 * time.Millisecond)); err != nil {
+			t.Fatalf("SetReadDeadline error: %v", err)
+		}
+		n, _, err := listenConn.ReadFromUDP(buf)
+		if err != nil {
+			break
+		}
+		pkt, err := protocol.ParseDMRD(buf[:n])
+		if err != nil {
+			t.Fatalf("ParseDMRD error: %v", err)
+		}
+		counts[pkt.StreamID]++
+	}
+
+	if counts[1] != 3 || counts[3] != 3 || counts[4] != 3 {
+		t.Errorf("Expected streams 1, 3 and 4 forwarded in full, got %v", counts)
+	}
+	if counts[2] != 0 {
+		t.Errorf("Expected stream 2 held by key-up guard, got %d frames", counts[2])
+	}
+}
@@ -0,0 +1,112 @@
+package network
+
+import (
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/peer"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+// Slot contention modes (SystemConfig.SlotContention)
+const (
+	SlotContentionFirstWins       = "first_wins"       // The first stream to reach a peer's timeslot keeps it, group or private
+	SlotContentionPrivatePriority = "private_priority" // As first_wins, but a private call takes the slot from a group call
+)
+
+// slotKey identifies one timeslot of a receiving peer
+type slotKey struct {
+	peerID   uint32
+	timeslot int
+}
+
+// slotOwner is the stream a receiving peer's timeslot is carrying
+type slotOwner struct {
+	last    protocol.DMRDPacket // Most recent frame, to synthesize a terminator if the stream is cut off
+	private bool
+	at      time.Time
+}
+
+// claimSlot reports whether a frame may be sent on a peer's timeslot. A
+// repeater can only key one stream per slot, so with slot contention enabled
+// a stream keeps the slot until its terminator, or until it goes idle, and
+// frames of any other stream for that slot are dropped, whatever their call
+// type. Under private_priority a private call takes the slot from a group
+// call, which is closed on the peer with a synthesized terminator.
+func (s *Server) claimSlot(dmrd *protocol.DMRDPacket, p *peer.Peer) bool {
+	mode := s.config.SlotContention
+	if mode == "" {
+		return true
+	}
+
+	private := dmrd.CallType == protocol.CallTypePrivate
+	key := slotKey{peerID: p.ID, timeslot: dmrd.Timeslot}
+	now := time.Now()
+
+	s.slotsMu.Lock()
+	owner, busy := s.slots[key]
+	if busy && owner.last.StreamID != dmrd.StreamID && now.Sub(owner.at) <= peer.StreamIdleTimeout {
+		if mode != SlotContentionPrivatePriority || !private || owner.private {
+			s.slotsMu.Unlock()
+			if s.metrics != nil {
+				s.metrics.PacketDropped("slot_contention")
+			}
+			if dmrd.FrameType == protocol.FrameTypeVoiceHeader {
+				s.log.Debug("Timeslot busy on peer, stream dropped",
+					logger.Int("peer_id", int(p.ID)),
+					logger.Int("ts", dmrd.Timeslot),
+					logger.Int("src", int(dmrd.SourceID)),
+					logger.Uint64("stream", uint64(dmrd.StreamID)),
+					logger.Uint64("active_stream", uint64(owner.last.StreamID)))
+			}
+			return false
+		}
+
+		// A private call cuts the group call off this peer's slot
+		cut := owner.last
+		delete(s.slots, key)
+		s.slotsMu.Unlock()
+		s.sendSlotTerminator(&cut, p)
+		s.slotsMu.Lock()
+	}
+
+	switch {
+	case dmrd.IsTerminator():
+		// Only the owner's terminator frees the slot
+		if owner, ok := s.slots[key]; ok && owner.last.StreamID == dmrd.StreamID {
+			delete(s.slots, key)
+		}
+	default:
+		s.slots[key] = &slotOwner{last: *dmrd, private: private, at: now}
+	}
+	s.slotsMu.Unlock()
+	return true
+}
+
+// sendSlotTerminator closes a group stream on a peer whose slot was taken by
+// a private call
+func (s *Server) sendSlotTerminator(dmrd *protocol.DMRDPacket, p *peer.Peer) {
+	_, data, err := encodeTerminator(dmrd)
+	if err != nil {
+		s.log.Error("Failed to encode slot terminator", logger.Error(err))
+		return
+	}
+
+	s.log.Info("Private call took the timeslot from a group call",
+		logger.Int("peer_id", int(p.ID)),
+		logger.Int("ts", dmrd.Timeslot),
+		logger.Int("tg", int(dmrd.DestinationID)),
+		logger.Uint64("stream", uint64(dmrd.StreamID)))
+	s.sendToPeer(p, data)
+}
+
+// cleanupSlots frees timeslots whose stream went idle without a terminator
+func (s *Server) cleanupSlots(now time.Time) {
+	s.slotsMu.Lock()
+	defer s.slotsMu.Unlock()
+	for key, owner := range s.slots {
+		if now.Sub(owner.at) > peer.StreamIdleTimeout {
+			delete(s.slots, key)
+		}
+	}
+}
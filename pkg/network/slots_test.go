@@ -0,0 +1,149 @@
+package network
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/metrics"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_SlotContention(t *testing.T) {
+	groupAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65030}
+	privateAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65031}
+
+	frame := func(t *testing.T, private bool, frameType uint8, stream uint32) []byte {
+		t.Helper()
+		dmrd := &protocol.DMRDPacket{
+			SourceID:      3120001,
+			DestinationID: 9,
+			RepeaterID:    111,
+			Timeslot:      1,
+			CallType:      protocol.CallTypeGroup,
+			FrameType:     frameType,
+			DataType:      protocol.DataTypeTerminatorLC,
+			StreamID:      stream,
+			Payload:       make([]byte, 33),
+		}
+		if frameType == protocol.FrameTypeVoice {
+			dmrd.DataType = 0
+		}
+		if private {
+			dmrd.SourceID, dmrd.DestinationID, dmrd.RepeaterID = 3120002, 3120003, 112
+			dmrd.CallType = protocol.CallTypePrivate
+		}
+		data, err := dmrd.Encode()
+		if err != nil {
+			t.Fatalf("Encode DMRD error: %v", err)
+		}
+		return data
+	}
+
+	setup := func(t *testing.T, mode string) (*Server, *metrics.Collector, *net.UDPConn) {
+		t.Helper()
+		cfg := config.SystemConfig{Mode: "MASTER", Repeat: true, PrivateCallsEnabled: true, SlotContention: mode}
+		log := logger.New(logger.Config{Level: "error"})
+		m := metrics.NewCollector()
+		srv := NewServer(cfg, "test-system", log).WithMetrics(m)
+
+		serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+		if err != nil {
+			t.Fatalf("ListenUDP error: %v", err)
+		}
+		srv.conn = serverConn
+		t.Cleanup(func() { _ = serverConn.Close() })
+
+		listenConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+		if err != nil {
+			t.Fatalf("ListenUDP error: %v", err)
+		}
+		t.Cleanup(func() { _ = listenConn.Close() })
+		srv.peerManager.AddPeer(222, listenConn.LocalAddr().(*net.UDPAddr)).SetConnected()
+		srv.peerManager.AddPeer(111, groupAddr).SetConnected()
+		srv.peerManager.AddPeer(112, privateAddr).SetConnected()
+		srv.trackSubscriberLocation(3120003, 222)
+		return srv, m, listenConn
+	}
+
+	read := func(t *testing.T, conn *net.UDPConn) *protocol.DMRDPacket {
+		t.Helper()
+		buf := make([]byte, 512)
+		_ = conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		n, _, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			return nil
+		}
+		got, err := protocol.ParseDMRD(buf[:n])
+		if err != nil {
+			t.Fatalf("ParseDMRD error: %v", err)
+		}
+		return got
+	}
+
+	t.Run("first_wins", func(t *testing.T) {
+		srv, m, conn := setup(t, SlotContentionFirstWins)
+
+		srv.handleDMRD(frame(t, false, protocol.FrameTypeVoiceHeader, 1), groupAddr)
+		if got := read(t, conn); got == nil || got.StreamID != 1 {
+			t.Fatal("Expected the group call header")
+		}
+
+		srv.handleDMRD(frame(t, true, protocol.FrameTypeVoiceHeader, 2), privateAddr)
+		if got := read(t, conn); got != nil {
+			t.Fatalf("Private call must not share the busy slot, got stream %d", got.StreamID)
+		}
+		if dropped := m.GetPacketsDropped("slot_contention"); dropped != 1 {
+			t.Errorf("Expected 1 slot contention drop, got %d", dropped)
+		}
+
+		srv.handleDMRD(frame(t, false, protocol.FrameTypeVoiceTerminator, 1), groupAddr)
+		if got := read(t, conn); got == nil || got.StreamID != 1 || !got.IsTerminator() {
+			t.Fatal("Expected the group call terminator")
+		}
+
+		srv.handleDMRD(frame(t, true, protocol.FrameTypeVoice, 2), privateAddr)
+		if got := read(t, conn); got == nil || got.StreamID != 2 {
+			t.Fatal("Expected the private call once the slot is free")
+		}
+	})
+
+	t.Run("private_priority", func(t *testing.T) {
+		srv, m, conn := setup(t, SlotContentionPrivatePriority)
+
+		srv.handleDMRD(frame(t, false, protocol.FrameTypeVoiceHeader, 1), groupAddr)
+		if got := read(t, conn); got == nil || got.StreamID != 1 {
+			t.Fatal("Expected the group call header")
+		}
+
+		srv.handleDMRD(frame(t, true, protocol.FrameTypeVoiceHeader, 2), privateAddr)
+		if got := read(t, conn); got == nil || got.StreamID != 1 || got.DataType != protocol.DataTypeTerminatorLC || !got.IsTerminator() {
+			t.Fatal("Expected a terminator with LC closing the group call")
+		}
+		if got := read(t, conn); got == nil || got.StreamID != 2 || got.CallType != protocol.CallTypePrivate {
+			t.Fatal("Expected the private call header")
+		}
+
+		srv.handleDMRD(frame(t, false, protocol.FrameTypeVoice, 1), groupAddr)
+		if got := read(t, conn); got != nil {
+			t.Fatalf("Group call must not return to the slot, got stream %d", got.StreamID)
+		}
+		if dropped := m.GetPacketsDropped("slot_contention"); dropped != 1 {
+			t.Errorf("Expected 1 slot contention drop, got %d", dropped)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		srv, _, conn := setup(t, "")
+
+		srv.handleDMRD(frame(t, false, protocol.FrameTypeVoiceHeader, 1), groupAddr)
+		srv.handleDMRD(frame(t, true, protocol.FrameTypeVoiceHeader, 2), privateAddr)
+		for _, want := range []uint32{1, 2} {
+			if got := read(t, conn); got == nil || got.StreamID != want {
+				t.Fatalf("Expected stream %d with contention disabled", want)
+			}
+		}
+	})
+}
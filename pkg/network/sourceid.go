@@ -0,0 +1,103 @@
+package network
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/database"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+	"gorm.io/gorm"
+)
+
+// Source ID check modes (SystemConfig.SourceIDCheck)
+const (
+	SourceIDCheckStrict = "strict" // drop frames from radio IDs not in the user database
+	SourceIDCheckGrace  = "grace"  // forward them, but log and count each transmission
+)
+
+// sourceIDCacheTTL is how long a user database answer is reused, so a
+// transmission costs at most one lookup rather than one per frame
+const sourceIDCacheTTL = 5 * time.Minute
+
+// sourceIDEntry caches whether a radio ID is registered
+type sourceIDEntry struct {
+	registered bool
+	checked    time.Time
+}
+
+// WithUserRepo injects the user database used to check source IDs
+func (s *Server) WithUserRepo(repo *database.DMRUserRepository) *Server {
+	s.userRepo = repo
+	return s
+}
+
+// allowSourceID reports whether a frame passes the source ID check. In strict
+// mode unregistered sources are dropped; the peer is not sent MSTNAK, since
+// that would log out a repeater for one unregistered radio.
+func (s *Server) allowSourceID(dmrd *protocol.DMRDPacket) bool {
+	mode := s.config.SourceIDCheck
+	if mode == "" || s.userRepo == nil || s.isSourceIDRegistered(dmrd.SourceID) {
+		return true
+	}
+
+	if dmrd.FrameType == protocol.FrameTypeVoiceHeader {
+		if s.metrics != nil {
+			s.metrics.UnregisteredSource()
+		}
+		s.log.Warn("Transmission from unregistered source ID",
+			logger.Int("src", int(dmrd.SourceID)),
+			logger.Int("tg", int(dmrd.DestinationID)),
+			logger.Int("peer_id", int(dmrd.RepeaterID)),
+			logger.String("mode", mode))
+	}
+
+	if mode != SourceIDCheckStrict {
+		return true
+	}
+	if s.metrics != nil {
+		s.metrics.PacketDropped("unregistered_source")
+	}
+	return false
+}
+
+// isSourceIDRegistered looks up radioID in the user database, caching the
+// answer. Lookup failures other than "not found" are treated as registered
+// and not cached, so a database problem doesn't silence the network.
+func (s *Server) isSourceIDRegistered(radioID uint32) bool {
+	now := time.Now()
+	s.sourceIDsMu.Lock()
+	entry, ok := s.sourceIDs[radioID]
+	s.sourceIDsMu.Unlock()
+	if ok && now.Sub(entry.checked) < sourceIDCacheTTL {
+		return entry.registered
+	}
+
+	_, err := s.userRepo.GetByRadioID(radioID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		if s.metrics != nil {
+			s.metrics.UserLookupFailed()
+		}
+		s.log.Debug("Source ID lookup failed",
+			logger.Int("radio_id", int(radioID)),
+			logger.Error(err))
+		return true
+	}
+
+	registered := err == nil
+	s.sourceIDsMu.Lock()
+	s.sourceIDs[radioID] = sourceIDEntry{registered: registered, checked: now}
+	s.sourceIDsMu.Unlock()
+	return registered
+}
+
+// cleanupSourceIDs forgets cached answers older than sourceIDCacheTTL
+func (s *Server) cleanupSourceIDs(now time.Time) {
+	s.sourceIDsMu.Lock()
+	defer s.sourceIDsMu.Unlock()
+	for radioID, entry := range s.sourceIDs {
+		if now.Sub(entry.checked) >= sourceIDCacheTTL {
+			delete(s.sourceIDs, radioID)
+		}
+	}
+}
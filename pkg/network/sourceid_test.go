@@ -0,0 +1,128 @@
+package network
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/database"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/metrics"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_SourceIDCheck(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	db, err := database.NewDB(database.Config{Path: filepath.Join(t.TempDir(), "users.db")}, log)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	userRepo := database.NewDMRUserRepository(db.GetDB())
+	if err := userRepo.Upsert(&database.DMRUser{RadioID: 3120001, Callsign: "W1AW"}); err != nil {
+		t.Fatalf("Upsert error: %v", err)
+	}
+
+	tests := []struct {
+		mode              string
+		wantKnown         int
+		wantUnknown       int
+		wantFlagged       uint64
+		wantDroppedFrames uint64
+	}{
+		{mode: "", wantKnown: 3, wantUnknown: 3},
+		{mode: SourceIDCheckGrace, wantKnown: 3, wantUnknown: 3, wantFlagged: 1},
+		{mode: SourceIDCheckStrict, wantKnown: 3, wantUnknown: 0, wantFlagged: 1, wantDroppedFrames: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run("mode="+tt.mode, func(t *testing.T) {
+			collector := metrics.NewCollector()
+			cfg := config.SystemConfig{Mode: "MASTER", SourceIDCheck: tt.mode}
+			srv := NewServer(cfg, "test-system", log).
+				WithRouter(bridge.NewRouter()).
+				WithMetrics(collector).
+				WithUserRepo(userRepo)
+
+			serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+			if err != nil {
+				t.Fatalf("ListenUDP error: %v", err)
+			}
+			srv.conn = serverConn
+			defer func() { _ = serverConn.Close() }()
+
+			listenConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+			if err != nil {
+				t.Fatalf("ListenUDP error: %v", err)
+			}
+			defer func() { _ = listenConn.Close() }()
+			listener := srv.peerManager.AddPeer(222, listenConn.LocalAddr().(*net.UDPAddr))
+			listener.SetConnected()
+			listener.Subscriptions.AddDynamic(3100, 1)
+
+			srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65013}
+			source := srv.peerManager.AddPeer(111, srcAddr)
+			source.SetConnected()
+			source.Subscriptions.AddDynamic(3100, 1)
+
+			transmit := func(sourceID, streamID uint32) {
+				for _, ft := range []byte{protocol.FrameTypeVoiceHeader, protocol.FrameTypeVoice, protocol.FrameTypeVoiceTerminator} {
+					dmrd := &protocol.DMRDPacket{
+						SourceID:      sourceID,
+						DestinationID: 3100,
+						RepeaterID:    111,
+						Timeslot:      1,
+						CallType:      protocol.CallTypeGroup,
+						FrameType:     ft,
+						StreamID:      streamID,
+						Payload:       make([]byte, 33),
+					}
+					if ft == protocol.FrameTypeVoiceTerminator {
+						dmrd.DataType = protocol.DataTypeTerminatorLC
+					}
+					data, err := dmrd.Encode()
+					if err != nil {
+						t.Fatalf("Encode DMRD error: %v", err)
+					}
+					srv.handleDMRD(data, srcAddr)
+				}
+			}
+
+			transmit(3120001, 1) // registered
+			transmit(3129999, 2) // not in the user database
+
+			counts := make(map[uint32]int)
+			buf := make([]byte, 2048)
+			for {
+				if err := listenConn.SetReadDeadline(time.Now().Add(200 * time.Millisecond)); err != nil {
+					t.Fatalf("SetReadDeadline error: %v", err)
+				}
+				n, _, err := listenConn.ReadFromUDP(buf)
+				if err != nil {
+					break
+				}
+				pkt, err := protocol.ParseDMRD(buf[:n])
+				if err != nil {
+					t.Fatalf("ParseDMRD error: %v", err)
+				}
+				counts[pkt.SourceID]++
+			}
+
+			if counts[3120001] != tt.wantKnown {
+				t.Errorf("Expected %d frames from the registered ID, got %d", tt.wantKnown, counts[3120001])
+			}
+			if counts[3129999] != tt.wantUnknown {
+				t.Errorf("Expected %d frames from the unregistered ID, got %d", tt.wantUnknown, counts[3129999])
+			}
+			if got := collector.GetUnregisteredSources(); got != tt.wantFlagged {
+				t.Errorf("Expected %d unregistered transmissions flagged, got %d", tt.wantFlagged, got)
+			}
+			if got := collector.GetPacketsDropped("unregistered_source"); got != tt.wantDroppedFrames {
+				t.Errorf("Expected %d frames dropped, got %d", tt.wantDroppedFrames, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package network
+
+import (
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/peer"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+// strictlyDenied reports whether strict_subscriptions drops a group call
+// frame: the peer is not subscribed to the talkgroup on this timeslot, no
+// bridge rule carries it and it is not in strict_allowed_tgs. Without strict
+// subscriptions the key-up would subscribe the peer instead.
+func (s *Server) strictlyDenied(dmrd *protocol.DMRDPacket, p *peer.Peer) bool {
+	if !s.config.StrictSubscriptions || s.strictAllowedTGs[dmrd.DestinationID] {
+		return false
+	}
+	if p.Subscriptions != nil && p.Subscriptions.HasTalkgroup(dmrd.DestinationID, uint8(dmrd.Timeslot)) {
+		return false
+	}
+	if s.router.HasRoute(dmrd, s.systemName) {
+		return false
+	}
+
+	if s.metrics != nil {
+		s.metrics.PacketDropped("strict_subscription")
+	}
+	if dmrd.FrameType == protocol.FrameTypeVoiceHeader {
+		s.log.Info("Dropped key-up on unsubscribed talkgroup (strict subscriptions)",
+			logger.Int("peer_id", int(p.ID)),
+			logger.String("callsign", p.Callsign),
+			logger.Int("tg", int(dmrd.DestinationID)),
+			logger.Int("ts", dmrd.Timeslot),
+			logger.Int("src", int(dmrd.SourceID)))
+	}
+	return true
+}
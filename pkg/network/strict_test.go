@@ -0,0 +1,132 @@
+package network
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/metrics"
+	"github.com/dbehnke/dmr-nexus/pkg/peer"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_StrictSubscriptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		strict     bool
+		tgid       uint32
+		subscribed bool // Key-up subscribes the peer rather than being dropped
+	}{
+		{"permissive unknown TG", false, 9999, true},
+		{"strict unknown TG", true, 9999, false},
+		{"strict allowlisted TG", true, 91, true},
+		{"strict bridged TG", true, 3120, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := bridge.NewRouter()
+			router.AddBridge(bridge.NewBridgeRuleSetFromConfig("STATEWIDE", []config.BridgeRule{
+				{System: "test-system", TGID: 3120, Timeslot: 1, Active: true},
+				{System: "OBP", TGID: 3120, Timeslot: 1, Active: true},
+			}))
+			collector := metrics.NewCollector()
+			cfg := config.SystemConfig{Mode: "MASTER", StrictSubscriptions: tt.strict, StrictAllowedTGs: []int{91}}
+			srv := NewServer(cfg, "test-system", logger.New(logger.Config{Level: "error"})).
+				WithRouter(router).
+				WithMetrics(collector)
+
+			serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+			if err != nil {
+				t.Fatalf("ListenUDP error: %v", err)
+			}
+			srv.conn = serverConn
+			defer func() { _ = serverConn.Close() }()
+
+			srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65031}
+			source := srv.peerManager.AddPeer(111, srcAddr)
+			source.SetConnected()
+
+			dmrd := &protocol.DMRDPacket{
+				SourceID:      3120001,
+				DestinationID: tt.tgid,
+				RepeaterID:    111,
+				Timeslot:      1,
+				CallType:      protocol.CallTypeGroup,
+				FrameType:     protocol.FrameTypeVoiceHeader,
+				StreamID:      0x5101,
+				Payload:       make([]byte, 33),
+			}
+			data, err := dmrd.Encode()
+			if err != nil {
+				t.Fatalf("Encode DMRD error: %v", err)
+			}
+			srv.handleDMRD(data, srcAddr)
+
+			if got := source.Subscriptions.HasTalkgroup(tt.tgid, 1); got != tt.subscribed {
+				t.Errorf("Subscribed to TG %d = %v, want %v", tt.tgid, got, tt.subscribed)
+			}
+			dropped := collector.GetPacketsDropped("strict_subscription")
+			if tt.subscribed && dropped != 0 || !tt.subscribed && dropped != 1 {
+				t.Errorf("strict_subscription drops = %d", dropped)
+			}
+		})
+	}
+
+	t.Run("strict static subscription forwards", func(t *testing.T) {
+		cfg := config.SystemConfig{Mode: "MASTER", StrictSubscriptions: true}
+		srv := NewServer(cfg, "test-system", logger.New(logger.Config{Level: "error"})).WithRouter(bridge.NewRouter())
+
+		serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+		if err != nil {
+			t.Fatalf("ListenUDP error: %v", err)
+		}
+		srv.conn = serverConn
+		defer func() { _ = serverConn.Close() }()
+
+		listenConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+		if err != nil {
+			t.Fatalf("ListenUDP error: %v", err)
+		}
+		defer func() { _ = listenConn.Close() }()
+		listener := srv.peerManager.AddPeer(222, listenConn.LocalAddr().(*net.UDPAddr))
+		listener.SetConnected()
+		if err := listener.Subscriptions.Update(&peer.SubscriptionOptions{TS2: []uint32{3100}}); err != nil {
+			t.Fatalf("Update error: %v", err)
+		}
+
+		srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65032}
+		source := srv.peerManager.AddPeer(111, srcAddr)
+		source.SetConnected()
+		if err := source.Subscriptions.Update(&peer.SubscriptionOptions{TS2: []uint32{3100}}); err != nil {
+			t.Fatalf("Update error: %v", err)
+		}
+
+		dmrd := &protocol.DMRDPacket{
+			SourceID:      3120001,
+			DestinationID: 3100,
+			RepeaterID:    111,
+			Timeslot:      2,
+			CallType:      protocol.CallTypeGroup,
+			FrameType:     protocol.FrameTypeVoiceHeader,
+			StreamID:      0x5102,
+			Payload:       make([]byte, 33),
+		}
+		data, err := dmrd.Encode()
+		if err != nil {
+			t.Fatalf("Encode DMRD error: %v", err)
+		}
+		srv.handleDMRD(data, srcAddr)
+
+		if err := listenConn.SetReadDeadline(time.Now().Add(200 * time.Millisecond)); err != nil {
+			t.Fatalf("SetReadDeadline error: %v", err)
+		}
+		buf := make([]byte, 128)
+		if _, _, err := listenConn.ReadFromUDP(buf); err != nil {
+			t.Errorf("Expected the static subscriber's key-up to be forwarded: %v", err)
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package network
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/peer"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+const (
+	// subscriptionSummaryDelay lets OPTIONS sent right after RPTC arrive
+	// before a newly connected peer's statics are read back to it
+	subscriptionSummaryDelay = 3 * time.Second
+	// defaultSummaryTG is the talkgroup the summary plays on (local TG 9 is
+	// in most radios' receive lists)
+	defaultSummaryTG = 9
+)
+
+// SetAnnounceClips sets the clips used to spell announcements, without
+// enabling caller announcements
+func (s *Server) SetAnnounceClips(clips *AnnounceClips) {
+	s.announceMu.Lock()
+	defer s.announceMu.Unlock()
+	s.announceClips = clips
+}
+
+// scheduleSubscriptionSummary reads a newly connected peer's static
+// talkgroups back to it shortly after it connects, if enabled
+func (s *Server) scheduleSubscriptionSummary(peerID uint32) {
+	if !s.config.SubscriptionSummary {
+		return
+	}
+	time.AfterFunc(s.summaryDelay, func() { s.sendSubscriptionSummary(peerID) })
+}
+
+// sendSubscriptionSummary spells each timeslot's static talkgroups as a short
+// transmission on that timeslot, to the peer only
+func (s *Server) sendSubscriptionSummary(peerID uint32) {
+	s.announceMu.Lock()
+	clips := s.announceClips
+	s.announceMu.Unlock()
+
+	p := s.peerManager.GetPeer(peerID)
+	if clips == nil || p == nil || p.GetState() != peer.StateConnected || p.Subscriptions == nil {
+		return
+	}
+
+	tg := uint32(defaultSummaryTG)
+	if s.config.SubscriptionSummaryTG > 0 {
+		tg = uint32(s.config.SubscriptionSummaryTG)
+	}
+
+	for _, ts := range []uint8{1, 2} {
+		tgids := p.Subscriptions.GetStaticTalkgroups(ts)
+		if len(tgids) == 0 {
+			continue
+		}
+		words := make([]string, len(tgids))
+		for i, tgid := range tgids {
+			words[i] = strconv.FormatUint(uint64(tgid), 10)
+		}
+		bursts := clips.Assemble(strings.Join(words, " "))
+		if len(bursts) == 0 {
+			continue
+		}
+
+		s.log.Info("Sending subscription summary",
+			logger.Int("peer_id", int(peerID)),
+			logger.Int("ts", int(ts)),
+			logger.String("talkgroups", strings.Join(words, ",")))
+
+		header := protocol.DMRDPacket{
+			SourceID:      peerID,
+			DestinationID: tg,
+			RepeaterID:    peerID,
+			Timeslot:      int(ts),
+			CallType:      protocol.CallTypeGroup,
+			DataType:      protocol.DataTypeVoiceLCHeader,
+		}
+		for _, f := range announcementFrames(header, bursts) {
+			data, err := f.Encode()
+			if err != nil {
+				s.log.Error("Failed to encode summary frame", logger.Error(err))
+				return
+			}
+			s.sendToPeer(p, data)
+			if s.announceInterval > 0 {
+				time.Sleep(s.announceInterval)
+			}
+		}
+	}
+}
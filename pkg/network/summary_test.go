@@ -0,0 +1,120 @@
+package network
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_SubscriptionSummaryOnReconnect(t *testing.T) {
+	dir := t.TempDir()
+	clipBytes := map[string]byte{"0": 0xa0, "1": 0xa1, "3": 0xa3, "9": 0xa9, "space": 0xee}
+	for name, b := range clipBytes {
+		if err := os.WriteFile(filepath.Join(dir, name+".ambe"), bytes.Repeat([]byte{b}, announceBurstSize), 0o644); err != nil {
+			t.Fatalf("WriteFile error: %v", err)
+		}
+	}
+	clips, err := LoadAnnounceClips(dir)
+	if err != nil {
+		t.Fatalf("LoadAnnounceClips error: %v", err)
+	}
+
+	cfg := config.SystemConfig{Mode: "MASTER", SubscriptionSummary: true, AnnounceClipsDir: dir}
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(cfg, "test-system", log).WithRouter(bridge.NewRouter())
+	srv.announceInterval = 0
+	srv.summaryDelay = 100 * time.Millisecond
+	srv.SetAnnounceClips(clips)
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	peerConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = peerConn.Close() }()
+	peerAddr := peerConn.LocalAddr().(*net.UDPAddr)
+
+	// Reconnecting peer: RPTC, then its statics arrive in OPTIONS
+	srv.peerManager.AddPeer(111, peerAddr)
+	rptc := &protocol.RPTCPacket{
+		RepeaterID:  111,
+		Callsign:    "W1ABC",
+		ColorCode:   "1",
+		Description: "Test Peer",
+	}
+	rptcData, err := rptc.Encode()
+	if err != nil {
+		t.Fatalf("Encode RPTC error: %v", err)
+	}
+	srv.handleRPTC(rptcData, peerAddr)
+
+	rpto := make([]byte, 8)
+	copy(rpto, "RPTO")
+	binary.BigEndian.PutUint32(rpto[4:8], 111)
+	rpto = append(rpto, []byte("TS1=3100,91;TS2=9")...)
+	srv.handleRPTO(rpto, peerAddr)
+
+	var got []*protocol.DMRDPacket
+	buf := make([]byte, 2048)
+	for {
+		if err := peerConn.SetReadDeadline(time.Now().Add(500 * time.Millisecond)); err != nil {
+			t.Fatalf("SetReadDeadline error: %v", err)
+		}
+		n, _, err := peerConn.ReadFromUDP(buf)
+		if err != nil {
+			break
+		}
+		if pkt, err := protocol.ParseDMRD(buf[:n]); err == nil {
+			got = append(got, pkt)
+		}
+	}
+
+	// TS1 spells "91 3100", TS2 spells "9", each framed by header and terminator
+	want := []struct {
+		ts     int
+		bursts []byte
+	}{
+		{1, []byte{0xa9, 0xa1, 0xee, 0xa3, 0xa1, 0xa0, 0xa0}},
+		{2, []byte{0xa9}},
+	}
+	idx := 0
+	for _, w := range want {
+		if idx+len(w.bursts)+2 > len(got) {
+			t.Fatalf("Expected summary on TS%d, got %d frames total", w.ts, len(got))
+		}
+		header := got[idx]
+		if header.FrameType != protocol.FrameTypeVoiceHeader || header.Timeslot != w.ts ||
+			header.DestinationID != defaultSummaryTG || header.CallType != protocol.CallTypeGroup {
+			t.Fatalf("TS%d summary header: type %d ts %d dst %d", w.ts, header.FrameType, header.Timeslot, header.DestinationID)
+		}
+		for i, b := range w.bursts {
+			f := got[idx+1+i]
+			if f.StreamID != header.StreamID || f.FrameType != protocol.FrameTypeVoice || f.Payload[0] != b {
+				t.Errorf("TS%d burst %d: stream %d type %d payload %#x, want %#x", w.ts, i, f.StreamID, f.FrameType, f.Payload[0], b)
+			}
+		}
+		term := got[idx+1+len(w.bursts)]
+		if term.StreamID != header.StreamID || term.FrameType != protocol.FrameTypeVoiceTerminator {
+			t.Errorf("TS%d: expected terminator, got stream %d type %d", w.ts, term.StreamID, term.FrameType)
+		}
+		idx += len(w.bursts) + 2
+	}
+	if idx != len(got) {
+		t.Errorf("Expected %d summary frames, got %d", idx, len(got))
+	}
+}
@@ -0,0 +1,56 @@
+package network
+
+import (
+	"net"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/peer"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+// terminateSupersededStream handles clients that key up again without
+// terminating their last stream. When implicit_terminators is set and a
+// voice header arrives for a new stream while the peer's stream on that
+// timeslot, from the same source to the same destination, is still active,
+// the old stream is ended with a synthesized terminator. The terminator goes
+// through the normal DMRD path, so per-stream state is cleared and
+// downstream peers and bridges close the old stream before the new one
+// starts, rather than the new stream being dropped as an overlap.
+func (s *Server) terminateSupersededStream(dmrd *protocol.DMRDPacket, p *peer.Peer, addr *net.UDPAddr) {
+	if !s.config.ImplicitTerminators || dmrd.FrameType != protocol.FrameTypeVoiceHeader {
+		return
+	}
+	oldStreamID, active := p.StreamOnTimeslot(dmrd.Timeslot, time.Now())
+	if !active || oldStreamID == dmrd.StreamID {
+		return
+	}
+
+	s.deliveriesMu.Lock()
+	d, ok := s.deliveries[oldStreamID]
+	sameCall := ok && d.sourceID == dmrd.SourceID && d.destinationID == dmrd.DestinationID
+	s.deliveriesMu.Unlock()
+	if !sameCall {
+		return
+	}
+
+	term := *dmrd
+	term.StreamID = oldStreamID
+	term.FrameType = protocol.FrameTypeVoiceTerminator
+	term.DataType = protocol.DataTypeTerminatorLC
+	term.HMAC = nil
+	data, err := term.Encode()
+	if err != nil {
+		s.log.Error("Failed to encode implicit terminator", logger.Error(err))
+		return
+	}
+
+	s.log.Debug("New stream without a terminator for the last, ending it",
+		logger.Int("peer_id", int(p.ID)),
+		logger.Int("src", int(dmrd.SourceID)),
+		logger.Int("tg", int(dmrd.DestinationID)),
+		logger.Int("ts", dmrd.Timeslot),
+		logger.Uint64("old_stream", uint64(oldStreamID)),
+		logger.Uint64("stream", uint64(dmrd.StreamID)))
+	s.handleDMRD(data, addr)
+}
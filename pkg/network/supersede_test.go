@@ -0,0 +1,127 @@
+package network
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_ImplicitTerminators(t *testing.T) {
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65042}
+
+	setup := func(t *testing.T, implicit bool) (*Server, *net.UDPConn) {
+		t.Helper()
+		cfg := config.SystemConfig{Mode: "MASTER", Repeat: true, ImplicitTerminators: implicit}
+		srv := NewServer(cfg, "test-system", logger.New(logger.Config{Level: "error"}))
+
+		serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+		if err != nil {
+			t.Fatalf("ListenUDP error: %v", err)
+		}
+		srv.conn = serverConn
+		t.Cleanup(func() { _ = serverConn.Close() })
+
+		listenConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+		if err != nil {
+			t.Fatalf("ListenUDP error: %v", err)
+		}
+		t.Cleanup(func() { _ = listenConn.Close() })
+		srv.peerManager.AddPeer(222, listenConn.LocalAddr().(*net.UDPAddr)).SetConnected()
+		srv.peerManager.AddPeer(111, srcAddr).SetConnected()
+		return srv, listenConn
+	}
+
+	send := func(t *testing.T, srv *Server, stream uint32, frameType uint8) {
+		t.Helper()
+		dmrd := &protocol.DMRDPacket{
+			SourceID:      3120001,
+			DestinationID: 9,
+			RepeaterID:    111,
+			Timeslot:      1,
+			CallType:      protocol.CallTypeGroup,
+			FrameType:     frameType,
+			StreamID:      stream,
+			Payload:       make([]byte, 33),
+		}
+		data, err := dmrd.Encode()
+		if err != nil {
+			t.Fatalf("Encode DMRD error: %v", err)
+		}
+		srv.handleDMRD(data, srcAddr)
+	}
+
+	read := func(t *testing.T, conn *net.UDPConn) *protocol.DMRDPacket {
+		t.Helper()
+		buf := make([]byte, 512)
+		_ = conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		n, _, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			return nil
+		}
+		got, err := protocol.ParseDMRD(buf[:n])
+		if err != nil {
+			t.Fatalf("ParseDMRD error: %v", err)
+		}
+		return got
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		srv, conn := setup(t, true)
+
+		send(t, srv, 1, protocol.FrameTypeVoiceHeader)
+		send(t, srv, 1, protocol.FrameTypeVoice)
+		send(t, srv, 2, protocol.FrameTypeVoiceHeader)
+		send(t, srv, 2, protocol.FrameTypeVoice)
+
+		want := []struct {
+			stream     uint32
+			terminator bool
+		}{{1, false}, {1, false}, {1, true}, {2, false}, {2, false}}
+		for i, w := range want {
+			got := read(t, conn)
+			if got == nil {
+				t.Fatalf("Frame %d: expected stream %d, got nothing", i, w.stream)
+			}
+			if got.StreamID != w.stream || got.IsTerminator() != w.terminator {
+				t.Errorf("Frame %d = stream %d terminator=%v, want stream %d terminator=%v",
+					i, got.StreamID, got.IsTerminator(), w.stream, w.terminator)
+			}
+		}
+	})
+
+	t.Run("other source keeps the slot", func(t *testing.T) {
+		srv, conn := setup(t, true)
+
+		send(t, srv, 1, protocol.FrameTypeVoiceHeader)
+		if read(t, conn) == nil {
+			t.Fatal("Expected the first header")
+		}
+		dmrd := &protocol.DMRDPacket{
+			SourceID: 3120002, DestinationID: 9, RepeaterID: 111, Timeslot: 1,
+			CallType: protocol.CallTypeGroup, FrameType: protocol.FrameTypeVoiceHeader,
+			StreamID: 2, Payload: make([]byte, 33),
+		}
+		data, _ := dmrd.Encode()
+		srv.handleDMRD(data, srcAddr)
+		if got := read(t, conn); got != nil {
+			t.Errorf("Expected a different source's overlapping stream to be dropped, got stream %d", got.StreamID)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		srv, conn := setup(t, false)
+
+		send(t, srv, 1, protocol.FrameTypeVoiceHeader)
+		if read(t, conn) == nil {
+			t.Fatal("Expected the first header")
+		}
+		send(t, srv, 2, protocol.FrameTypeVoiceHeader)
+		if got := read(t, conn); got != nil {
+			t.Errorf("Expected the overlapping stream to be dropped, got stream %d", got.StreamID)
+		}
+	})
+}
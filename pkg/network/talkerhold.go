@@ -0,0 +1,49 @@
+package network
+
+import (
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+// talkerHoldStream tracks a transmission on a talker-hold talkgroup
+type talkerHoldStream struct {
+	start time.Time
+	last  time.Time
+}
+
+// activationHeld reports whether a frame is too early in its transmission to
+// count toward bridge activation, so a kerchunk on a talker-hold talkgroup
+// doesn't switch bridge rules
+func (s *Server) activationHeld(dmrd *protocol.DMRDPacket) bool {
+	hold, ok := s.talkerHolds[dmrd.DestinationID]
+	if !ok {
+		return false
+	}
+
+	now := time.Now()
+	s.talkerHoldMu.Lock()
+	defer s.talkerHoldMu.Unlock()
+
+	st, ok := s.talkerHoldStreams[dmrd.StreamID]
+	if !ok {
+		st = &talkerHoldStream{start: now}
+		s.talkerHoldStreams[dmrd.StreamID] = st
+	}
+	st.last = now
+	if dmrd.IsTerminator() {
+		delete(s.talkerHoldStreams, dmrd.StreamID)
+	}
+	return now.Sub(st.start) < hold
+}
+
+// cleanupTalkerHolds forgets transmissions that ended without a terminator
+func (s *Server) cleanupTalkerHolds(now time.Time) {
+	s.talkerHoldMu.Lock()
+	defer s.talkerHoldMu.Unlock()
+	for streamID, st := range s.talkerHoldStreams {
+		if now.Sub(st.last) > s.muteWindow {
+			delete(s.talkerHoldStreams, streamID)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package network
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_TalkerHoldDelaysBridgeActivation(t *testing.T) {
+	router := bridge.NewRouter()
+	rules := bridge.NewBridgeRuleSet("REGIONAL")
+	rule := &bridge.BridgeRule{System: "OTHER", TGID: 3100, Timeslot: 1, On: []int{3100}}
+	rules.AddRule(rule)
+	router.AddBridge(rules)
+
+	cfg := config.SystemConfig{
+		Mode:          "MASTER",
+		TalkerHoldTGs: []config.TalkerHoldTG{{TGID: 3100, MinMs: 100}},
+	}
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(cfg, "test-system", log).WithRouter(router)
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65014}
+	source := srv.peerManager.AddPeer(111, srcAddr)
+	source.SetConnected()
+	source.Subscriptions.AddDynamic(3100, 1)
+
+	send := func(streamID uint32, frameType byte) {
+		dmrd := &protocol.DMRDPacket{
+			SourceID:      3120001,
+			DestinationID: 3100,
+			RepeaterID:    111,
+			Timeslot:      1,
+			CallType:      protocol.CallTypeGroup,
+			FrameType:     frameType,
+			StreamID:      streamID,
+			Payload:       make([]byte, 33),
+		}
+		if frameType == protocol.FrameTypeVoiceTerminator {
+			dmrd.DataType = protocol.DataTypeTerminatorLC
+		}
+		data, err := dmrd.Encode()
+		if err != nil {
+			t.Fatalf("Encode DMRD error: %v", err)
+		}
+		srv.handleDMRD(data, srcAddr)
+	}
+
+	// A kerchunk shorter than the hold leaves the rule alone
+	send(1, protocol.FrameTypeVoiceHeader)
+	send(1, protocol.FrameTypeVoice)
+	send(1, protocol.FrameTypeVoiceTerminator)
+	if rule.Active {
+		t.Fatal("Expected a sub-threshold transmission not to activate the rule")
+	}
+
+	// A transmission that outlasts the hold activates it
+	send(2, protocol.FrameTypeVoiceHeader)
+	if rule.Active {
+		t.Fatal("Expected the rule to stay inactive during the hold")
+	}
+	time.Sleep(150 * time.Millisecond)
+	send(2, protocol.FrameTypeVoice)
+	if !rule.Active {
+		t.Error("Expected the rule activated once the transmission passed the hold")
+	}
+	send(2, protocol.FrameTypeVoiceTerminator)
+
+	srv.talkerHoldMu.Lock()
+	defer srv.talkerHoldMu.Unlock()
+	if len(srv.talkerHoldStreams) != 0 {
+		t.Errorf("Expected held transmissions forgotten after their terminators, got %d", len(srv.talkerHoldStreams))
+	}
+}
@@ -0,0 +1,40 @@
+package network
+
+import (
+	"github.com/dbehnke/dmr-nexus/pkg/peer"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+// tapped reports whether a frame's talkgroup is mirrored to the tap
+func (s *Server) tapped(dmrd *protocol.DMRDPacket) bool {
+	return s.tapACL == nil || s.tapACL.Check(dmrd.DestinationID)
+}
+
+// withTapPeer adds the tap peer to a frame's targets. The tap is a passive
+// recorder: it gets every forwarded frame whether or not it subscribed to
+// the talkgroup, but never its own traffic back.
+func (s *Server) withTapPeer(dmrd *protocol.DMRDPacket, targets []*peer.Peer, sourcePeerID uint32) []*peer.Peer {
+	tapID := uint32(s.config.TapPeerID)
+	if tapID == 0 || tapID == sourcePeerID || containsPeer(targets, tapID) || !s.tapped(dmrd) {
+		return targets
+	}
+	tap := s.peerManager.GetPeer(tapID)
+	if tap == nil || tap.GetState() != peer.StateConnected {
+		return targets
+	}
+	return append(targets, tap)
+}
+
+// mirrorToTapSystem sends a frame to the tap system, unless a bridge rule
+// already routed it there
+func (s *Server) mirrorToTapSystem(dmrd *protocol.DMRDPacket, data []byte, routed []string) {
+	if s.config.TapSystem == "" || !s.tapped(dmrd) {
+		return
+	}
+	for _, system := range routed {
+		if system == s.config.TapSystem {
+			return
+		}
+	}
+	s.router.ForwardToSystems([]string{s.config.TapSystem}, dmrd, data)
+}
@@ -0,0 +1,134 @@
+package network
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/peer"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_TapReceivesAllTalkgroups(t *testing.T) {
+	setup := func(t *testing.T, tapACL string) (*Server, *net.UDPConn, func() map[uint32]int, *net.UDPAddr) {
+		t.Helper()
+		cfg := config.SystemConfig{Mode: "MASTER", TapSystem: "RECORDER", TapPeerID: 333}
+		router := bridge.NewRouter()
+		srv := NewServer(cfg, "test-system", logger.New(logger.Config{Level: "error"})).WithRouter(router)
+		if tapACL != "" {
+			acl, err := peer.ParseACL(tapACL)
+			if err != nil {
+				t.Fatalf("ParseACL error: %v", err)
+			}
+			srv.tapACL = acl
+		}
+
+		var mu sync.Mutex
+		recorded := make(map[uint32]int)
+		router.RegisterSystem("RECORDER", func(dmrd *protocol.DMRDPacket, _ []byte) {
+			mu.Lock()
+			recorded[dmrd.DestinationID]++
+			mu.Unlock()
+		})
+		systemFrames := func() map[uint32]int {
+			mu.Lock()
+			defer mu.Unlock()
+			got := make(map[uint32]int, len(recorded))
+			for tg, n := range recorded {
+				got[tg] = n
+			}
+			return got
+		}
+
+		serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+		if err != nil {
+			t.Fatalf("ListenUDP error: %v", err)
+		}
+		srv.conn = serverConn
+		t.Cleanup(func() { _ = serverConn.Close() })
+
+		tapConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+		if err != nil {
+			t.Fatalf("ListenUDP error: %v", err)
+		}
+		t.Cleanup(func() { _ = tapConn.Close() })
+		srv.peerManager.AddPeer(333, tapConn.LocalAddr().(*net.UDPAddr)).SetConnected()
+
+		srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65014}
+		source := srv.peerManager.AddPeer(111, srcAddr)
+		source.SetConnected()
+		source.Subscriptions.AddDynamic(3100, 1)
+		source.Subscriptions.AddDynamic(91, 2)
+		return srv, tapConn, systemFrames, srcAddr
+	}
+
+	transmit := func(t *testing.T, srv *Server, srcAddr *net.UDPAddr, streamID, tg uint32, ts int) {
+		t.Helper()
+		for _, ft := range []byte{protocol.FrameTypeVoiceHeader, protocol.FrameTypeVoice, protocol.FrameTypeVoiceTerminator} {
+			dmrd := &protocol.DMRDPacket{
+				SourceID:      3120001,
+				DestinationID: tg,
+				RepeaterID:    111,
+				Timeslot:      ts,
+				CallType:      protocol.CallTypeGroup,
+				FrameType:     ft,
+				StreamID:      streamID,
+				Payload:       make([]byte, 33),
+			}
+			if ft == protocol.FrameTypeVoiceTerminator {
+				dmrd.DataType = protocol.DataTypeTerminatorLC
+			}
+			data, err := dmrd.Encode()
+			if err != nil {
+				t.Fatalf("Encode DMRD error: %v", err)
+			}
+			srv.handleDMRD(data, srcAddr)
+		}
+	}
+	peerFrames := func(t *testing.T, conn *net.UDPConn) map[uint32]int {
+		t.Helper()
+		got := make(map[uint32]int)
+		buf := make([]byte, 512)
+		for {
+			_ = conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+			n, _, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return got
+			}
+			if pkt, err := protocol.ParseDMRD(buf[:n]); err == nil {
+				got[pkt.DestinationID]++
+			}
+		}
+	}
+
+	t.Run("all talkgroups", func(t *testing.T) {
+		srv, tapConn, systemFrames, srcAddr := setup(t, "")
+		transmit(t, srv, srcAddr, 1, 3100, 1)
+		transmit(t, srv, srcAddr, 2, 91, 2)
+
+		got := peerFrames(t, tapConn)
+		if got[3100] != 3 || got[91] != 3 {
+			t.Errorf("Tap peer frames by TG = %v, want 3 on each of 3100 and 91", got)
+		}
+		if got := systemFrames(); got[3100] != 3 || got[91] != 3 {
+			t.Errorf("Tap system frames by TG = %v, want 3 on each of 3100 and 91", got)
+		}
+	})
+
+	t.Run("acl", func(t *testing.T) {
+		srv, tapConn, systemFrames, srcAddr := setup(t, "PERMIT:3100")
+		transmit(t, srv, srcAddr, 1, 3100, 1)
+		transmit(t, srv, srcAddr, 2, 91, 2)
+
+		if got := peerFrames(t, tapConn); got[3100] != 3 || got[91] != 0 {
+			t.Errorf("Tap peer frames by TG = %v, want only TG 3100", got)
+		}
+		if got := systemFrames(); got[3100] != 3 || got[91] != 0 {
+			t.Errorf("Tap system frames by TG = %v, want only TG 3100", got)
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package network
+
+import (
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/peer"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+// trafficStream is a transmission seen starting, tracked until it ends so
+// the heard and traffic handlers fire once per stream
+type trafficStream struct {
+	src      uint32
+	dst      uint32
+	timeslot int
+	last     time.Time
+}
+
+// SetTrafficHandler sets the callback run when a transmission starts
+// (active) and when it ends, by terminator or by going idle
+func (s *Server) SetTrafficHandler(fn func(streamID, src, dst uint32, timeslot int, active bool)) {
+	s.onTraffic = fn
+}
+
+// noteTraffic reports whether a frame starts a stream, firing the traffic
+// handler on the first frame of each stream and on its terminator
+func (s *Server) noteTraffic(dmrd *protocol.DMRDPacket) bool {
+	// A terminator only ends a stream that was seen starting
+	var started, ended bool
+	s.trafficMu.Lock()
+	t, seen := s.trafficStreams[dmrd.StreamID]
+	switch {
+	case dmrd.IsTerminator():
+		delete(s.trafficStreams, dmrd.StreamID)
+		ended = seen
+	case seen:
+		t.last = time.Now()
+	default:
+		s.trafficStreams[dmrd.StreamID] = &trafficStream{
+			src:      dmrd.SourceID,
+			dst:      dmrd.DestinationID,
+			timeslot: dmrd.Timeslot,
+			last:     time.Now(),
+		}
+		started = true
+	}
+	s.trafficMu.Unlock()
+
+	if (started || ended) && s.onTraffic != nil {
+		s.onTraffic(dmrd.StreamID, dmrd.SourceID, dmrd.DestinationID, dmrd.Timeslot, started)
+	}
+	return started
+}
+
+// cleanupTraffic ends streams that went idle without a terminator
+func (s *Server) cleanupTraffic(now time.Time) {
+	s.trafficMu.Lock()
+	ended := make(map[uint32]*trafficStream)
+	for streamID, t := range s.trafficStreams {
+		if now.Sub(t.last) > peer.StreamIdleTimeout {
+			ended[streamID] = t
+			delete(s.trafficStreams, streamID)
+		}
+	}
+	s.trafficMu.Unlock()
+
+	if s.onTraffic == nil {
+		return
+	}
+	for streamID, t := range ended {
+		s.onTraffic(streamID, t.src, t.dst, t.timeslot, false)
+	}
+}
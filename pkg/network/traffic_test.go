@@ -0,0 +1,85 @@
+package network
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/peer"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_TrafficStartAndEnd(t *testing.T) {
+	cfg := config.SystemConfig{Mode: "MASTER"}
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(cfg, "test-system", log).WithRouter(bridge.NewRouter())
+
+	type event struct {
+		streamID uint32
+		active   bool
+	}
+	var events []event
+	srv.SetTrafficHandler(func(streamID, src, dst uint32, timeslot int, active bool) {
+		if src != 3120001 || dst != 3100 || timeslot != 1 {
+			t.Errorf("Unexpected traffic event src=%d dst=%d ts=%d", src, dst, timeslot)
+		}
+		events = append(events, event{streamID, active})
+	})
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65024}
+	source := srv.peerManager.AddPeer(111, srcAddr)
+	source.SetConnected()
+	source.Subscriptions.AddDynamic(3100, 1)
+
+	send := func(streamID uint32, ft byte) {
+		dmrd := &protocol.DMRDPacket{
+			SourceID:      3120001,
+			DestinationID: 3100,
+			RepeaterID:    111,
+			Timeslot:      1,
+			CallType:      protocol.CallTypeGroup,
+			FrameType:     ft,
+			StreamID:      streamID,
+			Payload:       make([]byte, 33),
+		}
+		if ft == protocol.FrameTypeVoiceTerminator {
+			dmrd.DataType = protocol.DataTypeTerminatorLC
+		}
+		data, err := dmrd.Encode()
+		if err != nil {
+			t.Fatalf("Encode DMRD error: %v", err)
+		}
+		srv.handleDMRD(data, srcAddr)
+	}
+
+	// Voice sync repeats every superframe but the stream starts once
+	send(1, protocol.FrameTypeVoiceHeader)
+	send(1, protocol.FrameTypeVoice)
+	send(1, protocol.FrameTypeVoiceHeader)
+	send(1, protocol.FrameTypeVoice)
+	send(1, protocol.FrameTypeVoiceTerminator)
+	if len(events) != 2 || events[0] != (event{1, true}) || events[1] != (event{1, false}) {
+		t.Fatalf("Expected one start and one end event, got %+v", events)
+	}
+
+	// A stream that goes quiet without a terminator still ends
+	send(2, protocol.FrameTypeVoiceHeader)
+	srv.cleanupTraffic(time.Now())
+	if len(events) != 3 {
+		t.Fatalf("Expected the live stream left running, got %+v", events)
+	}
+	srv.cleanupTraffic(time.Now().Add(peer.StreamIdleTimeout + time.Second))
+	if len(events) != 4 || events[3] != (event{2, false}) {
+		t.Errorf("Expected the idle stream ended by cleanup, got %+v", events)
+	}
+}
@@ -0,0 +1,113 @@
+package network
+
+import (
+	"net"
+	"testing"
+
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/metrics"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_ReportsUnavailableBridgeTarget(t *testing.T) {
+	router := bridge.NewRouter()
+	rules := bridge.NewBridgeRuleSet("REGIONAL")
+	rules.AddRule(&bridge.BridgeRule{System: "test-system", TGID: 3100, Timeslot: 1, Active: true})
+	rules.AddRule(&bridge.BridgeRule{System: "DOWNLINK", TGID: 3100, Timeslot: 1, Active: true})
+	rules.AddRule(&bridge.BridgeRule{System: "EMPTY-MASTER", TGID: 3100, Timeslot: 1, Active: true})
+	router.AddBridge(rules)
+
+	collector := metrics.NewCollector()
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(config.SystemConfig{Mode: "MASTER"}, "test-system", log).
+		WithRouter(router).
+		WithMetrics(collector)
+	// Running, but none of the shared manager's peers logged in to it
+	NewServer(config.SystemConfig{Mode: "MASTER"}, "EMPTY-MASTER", log).
+		WithPeerManager(srv.peerManager).
+		WithRouter(router)
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65015}
+	source := srv.peerManager.AddPeer(111, srcAddr)
+	source.SetSystem("test-system")
+	source.SetConnected()
+	source.Subscriptions.AddDynamic(3100, 1)
+
+	send := func(frameType byte) {
+		dmrd := &protocol.DMRDPacket{
+			SourceID:      3120001,
+			DestinationID: 3100,
+			RepeaterID:    111,
+			Timeslot:      1,
+			CallType:      protocol.CallTypeGroup,
+			FrameType:     frameType,
+			StreamID:      4242,
+			Payload:       make([]byte, 33),
+		}
+		if frameType == protocol.FrameTypeVoiceTerminator {
+			dmrd.DataType = protocol.DataTypeTerminatorLC
+		}
+		data, err := dmrd.Encode()
+		if err != nil {
+			t.Fatalf("Encode DMRD error: %v", err)
+		}
+		srv.handleDMRD(data, srcAddr)
+	}
+
+	// Voice sync repeats every superframe; the stream is reported once
+	send(protocol.FrameTypeVoiceHeader)
+	send(protocol.FrameTypeVoice)
+	send(protocol.FrameTypeVoiceHeader)
+	send(protocol.FrameTypeVoiceTerminator)
+
+	down := collector.GetBridgeTargetsUnavailable()
+	if down["DOWNLINK"] != 1 {
+		t.Errorf("Expected one unavailable-target report for DOWNLINK, got %d", down["DOWNLINK"])
+	}
+	if down["EMPTY-MASTER"] != 1 {
+		t.Errorf("Expected one unavailable-target report for the MASTER without peers, got %d", down["EMPTY-MASTER"])
+	}
+	if _, ok := down["test-system"]; ok {
+		t.Error("The running source system should not be reported unavailable")
+	}
+}
+
+func TestUnavailableSystems_LinkState(t *testing.T) {
+	router := bridge.NewRouter()
+	log := logger.New(logger.Config{Level: "error"})
+	client := NewClient(config.SystemConfig{Mode: "PEER", RadioID: 312000}, log).WithRouter(router, "UPLINK")
+	obClient := NewOpenBridgeClient(config.SystemConfig{Mode: "OPENBRIDGE"}, log).WithRouter(router, "OBP")
+	targets := []string{"UPLINK", "OBP"}
+
+	// Neither link is up before the clients start
+	if down := router.UnavailableSystems(targets); len(down) != 2 {
+		t.Fatalf("Expected both links reported down, got %v", down)
+	}
+
+	client.setState(StateConnected)
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	obClient.conn = conn
+	if down := router.UnavailableSystems(targets); len(down) != 0 {
+		t.Fatalf("Expected both links up, got %v", down)
+	}
+
+	// A lost master, or a partner whose frames fail authentication
+	client.setState(StateDisconnected)
+	obClient.setAuthState(obAuthFailing)
+	if down := router.UnavailableSystems(targets); len(down) != 2 || down[0] != "UPLINK" || down[1] != "OBP" {
+		t.Errorf("Expected both links reported down again, got %v", down)
+	}
+}
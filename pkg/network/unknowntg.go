@@ -0,0 +1,85 @@
+package network
+
+import (
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/peer"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+// hasSubscriber reports whether any target is subscribed rather than only
+// receiving everything in repeat-all (TG 777) mode
+func hasSubscriber(targets []*peer.Peer) bool {
+	for _, p := range targets {
+		if !p.GetRepeatMode() {
+			return true
+		}
+	}
+	return false
+}
+
+// handleUnknownTalkgroup records a frame on a talkgroup with no bridge rule
+// or subscriber for discovery, and forwards it to the catch-all system if one
+// is configured
+func (s *Server) handleUnknownTalkgroup(dmrd *protocol.DMRDPacket, data []byte) {
+	if dmrd.FrameType == protocol.FrameTypeVoiceHeader {
+		if s.metrics != nil {
+			s.metrics.UnknownTalkgroup(dmrd.DestinationID)
+		}
+
+		if s.config.LogUnknownTGs {
+			s.unknownTGsMu.Lock()
+			first := !s.unknownTGs[dmrd.DestinationID]
+			s.unknownTGs[dmrd.DestinationID] = true
+			s.unknownTGsMu.Unlock()
+
+			if first {
+				s.log.Info("Discovered unknown talkgroup",
+					logger.Int("tg", int(dmrd.DestinationID)),
+					logger.Int("ts", dmrd.Timeslot),
+					logger.Int("src", int(dmrd.SourceID)),
+					logger.Int("peer_id", int(dmrd.RepeaterID)))
+			}
+		}
+	}
+
+	if s.config.UnknownTGTarget != "" {
+		s.router.ForwardToSystems([]string{s.config.UnknownTGTarget}, dmrd, data)
+	}
+}
+
+// reportUnavailableTargets meters and logs each routed target system that
+// can't deliver, so a down link is distinguishable from a misconfigured rule.
+// Called on each voice sync; a stream is reported once.
+func (s *Server) reportUnavailableTargets(dmrd *protocol.DMRDPacket, targets []string) {
+	s.unavailableReportedMu.Lock()
+	_, reported := s.unavailableReported[dmrd.StreamID]
+	s.unavailableReported[dmrd.StreamID] = time.Now()
+	s.unavailableReportedMu.Unlock()
+	if reported {
+		return
+	}
+
+	for _, target := range s.router.UnavailableSystems(targets) {
+		if s.metrics != nil {
+			s.metrics.BridgeTargetUnavailable(target)
+		}
+		s.log.Warn("Bridge rule matched but target system is unavailable",
+			logger.String("target", target),
+			logger.Int("tg", int(dmrd.DestinationID)),
+			logger.Int("ts", dmrd.Timeslot),
+			logger.Int("src", int(dmrd.SourceID)))
+	}
+}
+
+// cleanupUnavailableReports forgets streams that stopped sending voice syncs
+func (s *Server) cleanupUnavailableReports(now time.Time) {
+	s.unavailableReportedMu.Lock()
+	defer s.unavailableReportedMu.Unlock()
+	for streamID, last := range s.unavailableReported {
+		if now.Sub(last) > s.muteWindow {
+			delete(s.unavailableReported, streamID)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package network
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/metrics"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_UnknownTalkgroupCatchAllAndDiscovery(t *testing.T) {
+	router := bridge.NewRouter()
+	collector := metrics.NewCollector()
+	var logs bytes.Buffer
+
+	srcCfg := config.SystemConfig{Mode: "MASTER", UnknownTGTarget: "MONITOR", LogUnknownTGs: true}
+	srv := NewServer(srcCfg, "MASTER-A", logger.New(logger.Config{Level: "info", Output: &logs})).
+		WithRouter(router).
+		WithMetrics(collector)
+	monitor := NewServer(config.SystemConfig{Mode: "MASTER"}, "MONITOR", logger.New(logger.Config{Level: "error"})).
+		WithRouter(router)
+
+	for _, s := range []*Server{srv, monitor} {
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+		if err != nil {
+			t.Fatalf("ListenUDP error: %v", err)
+		}
+		s.conn = conn
+		defer func() { _ = conn.Close() }()
+	}
+
+	// A monitoring client on the catch-all system, subscribed to nothing
+	monitorConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = monitorConn.Close() }()
+	monitor.peerManager.AddPeer(333, monitorConn.LocalAddr().(*net.UDPAddr)).SetConnected()
+	monitor.peerManager.GetPeer(333).SetRepeatMode(true)
+
+	// On the source system, TG 3100 has a listener; TG 9999 has none
+	listenConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	defer func() { _ = listenConn.Close() }()
+	listener := srv.peerManager.AddPeer(222, listenConn.LocalAddr().(*net.UDPAddr))
+	listener.SetConnected()
+	listener.Subscriptions.AddDynamic(3100, 2)
+
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65011}
+	source := srv.peerManager.AddPeer(111, srcAddr)
+	source.SetConnected()
+	source.Subscriptions.AddDynamic(9999, 1)
+	source.Subscriptions.AddDynamic(3100, 2)
+
+	transmit := func(tgid uint32, timeslot int, streamID uint32) {
+		for _, ft := range []byte{protocol.FrameTypeVoiceHeader, protocol.FrameTypeVoice, protocol.FrameTypeVoiceTerminator} {
+			dmrd := &protocol.DMRDPacket{
+				SourceID:      3120001,
+				DestinationID: tgid,
+				RepeaterID:    111,
+				Timeslot:      timeslot,
+				CallType:      protocol.CallTypeGroup,
+				FrameType:     ft,
+				StreamID:      streamID,
+				Payload:       make([]byte, 33),
+			}
+			if ft == protocol.FrameTypeVoiceTerminator {
+				dmrd.DataType = protocol.DataTypeTerminatorLC
+			}
+			data, err := dmrd.Encode()
+			if err != nil {
+				t.Fatalf("Encode DMRD error: %v", err)
+			}
+			srv.handleDMRD(data, srcAddr)
+		}
+	}
+
+	transmit(9999, 1, 1)
+	transmit(3100, 2, 2)
+	transmit(9999, 1, 3)
+
+	counts := make(map[uint32]int)
+	buf := make([]byte, 2048)
+	for {
+		if err := monitorConn.SetReadDeadline(time.Now().Add(200 * time.Millisecond)); err != nil {
+			t.Fatalf("SetReadDeadline error: %v", err)
+		}
+		n, _, err := monitorConn.ReadFromUDP(buf)
+		if err != nil {
+			break
+		}
+		pkt, err := protocol.ParseDMRD(buf[:n])
+		if err != nil {
+			t.Fatalf("ParseDMRD error: %v", err)
+		}
+		counts[pkt.DestinationID]++
+	}
+
+	// Only the unknown talkgroup reaches the catch-all system
+	if counts[9999] != 6 {
+		t.Errorf("Expected 6 frames for TG 9999 on the catch-all system, got %d", counts[9999])
+	}
+	if counts[3100] != 0 {
+		t.Errorf("Expected TG 3100 (has a subscriber) not to be caught, got %d frames", counts[3100])
+	}
+
+	// Discovery: one metric per transmission, one log line per talkgroup
+	unknown := collector.GetUnknownTalkgroups()
+	if unknown[9999] != 2 || unknown[3100] != 0 {
+		t.Errorf("Expected 2 unknown transmissions on TG 9999 only, got %v", unknown)
+	}
+	if n := strings.Count(logs.String(), "Discovered unknown talkgroup"); n != 1 {
+		t.Errorf("Expected TG 9999 discovery logged once, got %d", n)
+	}
+}
@@ -0,0 +1,34 @@
+package network
+
+import (
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+)
+
+// unlinkIdleTalkgroups drops dynamic subscriptions a peer hasn't transmitted
+// on within the configured window, so abandoned links clean up even for
+// peers that set no AUTO TTL. Peers of other MASTERs sharing the peer
+// manager follow their own system's setting.
+func (s *Server) unlinkIdleTalkgroups(now time.Time) {
+	if s.config.UnlinkIdleMinutes <= 0 {
+		return
+	}
+	idle := time.Duration(s.config.UnlinkIdleMinutes) * time.Minute
+
+	for _, p := range s.peerManager.GetAllPeers() {
+		if p.Subscriptions == nil || !s.ownsPeer(p) {
+			continue
+		}
+		for _, tgid := range p.Subscriptions.RemoveInactiveDynamic(idle, now) {
+			if s.router != nil && !p.Subscriptions.IsSubscribedToTalkgroup(tgid) {
+				s.router.RemoveSubscriberFromDynamicBridge(tgid, p.ID)
+			}
+			s.log.Info("Unlinked idle dynamic talkgroup",
+				logger.Int("peer_id", int(p.ID)),
+				logger.String("callsign", p.Callsign),
+				logger.Int("tg", int(tgid)),
+				logger.Int("idle_minutes", s.config.UnlinkIdleMinutes))
+		}
+	}
+}
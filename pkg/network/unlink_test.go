@@ -0,0 +1,76 @@
+package network
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestServer_UnlinkIdleDynamicTalkgroup(t *testing.T) {
+	cfg := config.SystemConfig{Mode: "MASTER", UnlinkIdleMinutes: 10}
+	log := logger.New(logger.Config{Level: "error"})
+	router := bridge.NewRouter()
+	srv := NewServer(cfg, "test-system", log).WithRouter(router)
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0})
+	if err != nil {
+		t.Fatalf("ListenUDP error: %v", err)
+	}
+	srv.conn = serverConn
+	defer func() { _ = serverConn.Close() }()
+
+	srcAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65013}
+	p := srv.peerManager.AddPeer(111, srcAddr)
+	p.SetSystem("test-system")
+	p.SetConnected()
+
+	// A peer of another MASTER sharing the manager, without unlink_idle_minutes
+	other := srv.peerManager.AddPeer(222, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65014})
+	other.SetSystem("OTHER-MASTER")
+	other.SetConnected()
+	other.Subscriptions.AddDynamic(3100, 1)
+
+	// Key up on TG 3100: the peer (no AUTO TTL) is linked with no expiry
+	dmrd := &protocol.DMRDPacket{
+		SourceID:      3120001,
+		DestinationID: 3100,
+		RepeaterID:    111,
+		Timeslot:      1,
+		CallType:      protocol.CallTypeGroup,
+		FrameType:     protocol.FrameTypeVoiceHeader,
+		StreamID:      1001,
+		Payload:       make([]byte, 33),
+	}
+	data, err := dmrd.Encode()
+	if err != nil {
+		t.Fatalf("Encode DMRD error: %v", err)
+	}
+	srv.handleDMRD(data, srcAddr)
+	if !p.Subscriptions.IsSubscribed(3100, 1) {
+		t.Fatal("Key-up should link the peer to TG 3100")
+	}
+	router.AddSubscriberToDynamicBridge(3100, p.ID)
+
+	srv.unlinkIdleTalkgroups(time.Now().Add(5 * time.Minute))
+	if !p.Subscriptions.IsSubscribed(3100, 1) {
+		t.Fatal("Link should survive inside the inactivity window")
+	}
+
+	srv.unlinkIdleTalkgroups(time.Now().Add(11 * time.Minute))
+	if p.Subscriptions.IsSubscribed(3100, 1) {
+		t.Error("Link should be removed after the inactivity window")
+	}
+	for _, id := range router.GetDynamicBridgeSubscribers(3100) {
+		if id == p.ID {
+			t.Error("Peer should be removed from the dynamic bridge")
+		}
+	}
+	if !other.Subscriptions.IsSubscribed(3100, 1) {
+		t.Error("Another MASTER's peer should keep its link")
+	}
+}
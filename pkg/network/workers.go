@@ -0,0 +1,99 @@
+package network
+
+import (
+	"context"
+	"hash/fnv"
+	"net"
+	"runtime"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+)
+
+// packetWorkersPerProc sizes the default worker pool: handlers mostly wait on
+// locks and socket writes, so a few workers per usable CPU keep it busy
+const packetWorkersPerProc = 4
+
+// packetWorkerQueue is how many received packets each worker may have
+// waiting; more are dropped rather than stalling the receive loop
+const packetWorkerQueue = 256
+
+// packetJob is a received datagram waiting for a worker
+type packetJob struct {
+	data     []byte
+	addr     *net.UDPAddr
+	received time.Time
+}
+
+// PacketWorkerCount returns the worker pool size for a configured
+// runtime.packet_workers: positive values are used as is, 0 picks a few
+// workers per GOMAXPROCS (which follows container CPU limits), and a negative
+// value means no pool, one goroutine per packet.
+func PacketWorkerCount(configured int) int {
+	switch {
+	case configured > 0:
+		return configured
+	case configured == 0:
+		return runtime.GOMAXPROCS(0) * packetWorkersPerProc
+	default:
+		return 0
+	}
+}
+
+// WithPacketWorkers sets how many workers handle received packets (0 handles
+// each packet on its own goroutine). Call before Start.
+func (s *Server) WithPacketWorkers(n int) *Server {
+	s.packetWorkers = n
+	return s
+}
+
+// startPacketWorkers starts the worker pool, if one is configured. Packets
+// are sharded by source address, so one peer's packets are handled in the
+// order they arrived and a busy peer can't take over every worker.
+func (s *Server) startPacketWorkers(ctx context.Context) {
+	if s.packetWorkers <= 0 {
+		return
+	}
+	queues := make([]chan packetJob, s.packetWorkers)
+	for i := range queues {
+		queues[i] = make(chan packetJob, packetWorkerQueue)
+		go s.packetWorker(ctx, queues[i])
+	}
+	s.packetQueues = queues
+}
+
+func (s *Server) packetWorker(ctx context.Context, jobs <-chan packetJob) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-jobs:
+			s.handleReceived(job.data, job.addr, job.received)
+		}
+	}
+}
+
+// dispatchPacket hands a received packet to its worker, or to a new
+// goroutine when there is no pool
+func (s *Server) dispatchPacket(data []byte, addr *net.UDPAddr) {
+	received := time.Now()
+	if len(s.packetQueues) == 0 {
+		go s.handleReceived(data, addr, received)
+		return
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write(addr.IP)
+	_, _ = h.Write([]byte{byte(addr.Port >> 8), byte(addr.Port)})
+	queue := s.packetQueues[h.Sum32()%uint32(len(s.packetQueues))]
+
+	select {
+	case queue <- packetJob{data: data, addr: addr, received: received}:
+	default:
+		if s.metrics != nil {
+			s.metrics.PacketDropped("worker_queue_full")
+		}
+		s.log.Debug("Packet worker queue full, packet dropped",
+			logger.String("addr", addr.String()))
+	}
+}
@@ -0,0 +1,63 @@
+package network
+
+import (
+	"context"
+	"net"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestPacketWorkerCount(t *testing.T) {
+	if got := PacketWorkerCount(6); got != 6 {
+		t.Errorf("PacketWorkerCount(6) = %d, want 6", got)
+	}
+	if got, want := PacketWorkerCount(0), runtime.GOMAXPROCS(0)*packetWorkersPerProc; got != want {
+		t.Errorf("PacketWorkerCount(0) = %d, want %d", got, want)
+	}
+	if got := PacketWorkerCount(-1); got != 0 {
+		t.Errorf("PacketWorkerCount(-1) = %d, want 0 (no pool)", got)
+	}
+}
+
+func TestServer_PacketWorkerPool(t *testing.T) {
+	cfg := config.SystemConfig{Mode: "MASTER", Passphrase: "test", MaxPeers: 10}
+	srv := NewServer(cfg, "test-system", logger.New(logger.Config{Level: "error"})).
+		WithPacketWorkers(PacketWorkerCount(3))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = srv.Start(ctx) }()
+	if err := srv.WaitStarted(ctx); err != nil {
+		t.Fatalf("server failed to start: %v", err)
+	}
+
+	if n := len(srv.packetQueues); n != 3 {
+		t.Fatalf("Expected 3 packet workers, got %d", n)
+	}
+
+	serverAddr, err := srv.Addr()
+	if err != nil {
+		t.Fatalf("Addr error: %v", err)
+	}
+	conn, err := net.DialUDP("udp", nil, serverAddr)
+	if err != nil {
+		t.Fatalf("DialUDP error: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	rptl, _ := (&protocol.RPTLPacket{RepeaterID: 312000}).Encode()
+	if _, err := conn.Write(rptl); err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+	buf := make([]byte, 64)
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := conn.Read(buf)
+	if err != nil || string(buf[:6]) != protocol.PacketTypeRPTACK {
+		t.Fatalf("Expected RPTACK through the worker pool, got %q, %v", buf[:n], err)
+	}
+}
@@ -37,6 +37,42 @@ func (pm *PeerManager) AddPeer(id uint32, addr *net.UDPAddr) *Peer {
 	return peer
 }
 
+// AddPeerLimited adds a peer like AddPeer and records the system it is
+// logging in to, unless it is new to that system and the system already has
+// max connected peers. Peers still logging in and peers of other systems
+// sharing the manager don't count. max <= 0 means no limit. It reports
+// whether the peer was added or updated.
+func (pm *PeerManager) AddPeerLimited(id uint32, addr *net.UDPAddr, system string, max int) (*Peer, bool) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	peer, exists := pm.peers[id]
+	rejoining := exists && peer.GetSystem() == system
+	if !rejoining && max > 0 && pm.countConnectedLocked(system) >= max {
+		return nil, false
+	}
+
+	if exists {
+		peer.Address = addr
+	} else {
+		peer = NewPeer(id, addr)
+		pm.peers[id] = peer
+	}
+	peer.SetSystem(system)
+	return peer, true
+}
+
+// countConnectedLocked counts the system's connected peers; pm.mu must be held
+func (pm *PeerManager) countConnectedLocked(system string) int {
+	count := 0
+	for _, peer := range pm.peers {
+		if peer.GetSystem() == system && peer.GetState() == StateConnected {
+			count++
+		}
+	}
+	return count
+}
+
 // GetPeer retrieves a peer by ID
 func (pm *PeerManager) GetPeer(id uint32) *Peer {
 	pm.mu.RLock()
@@ -300,3 +300,46 @@ func TestPeerManager_AddPeer_UpdatesExisting(t *testing.T) {
 		t.Error("Expected callsign to be preserved when updating peer")
 	}
 }
+
+func TestPeerManager_AddPeerLimited(t *testing.T) {
+	mgr := NewPeerManager()
+	addr := &net.UDPAddr{IP: net.ParseIP("192.168.1.100"), Port: 62031}
+
+	for id := uint32(1); id <= 2; id++ {
+		p, ok := mgr.AddPeerLimited(id, addr, "MASTER-1", 2)
+		if !ok {
+			t.Fatalf("Expected peer %d to fit under the limit", id)
+		}
+		if p.GetSystem() != "MASTER-1" {
+			t.Errorf("Expected peer %d claimed by MASTER-1, got %q", id, p.GetSystem())
+		}
+	}
+
+	// Peers still logging in don't fill the system
+	if _, ok := mgr.AddPeerLimited(3, addr, "MASTER-1", 2); !ok {
+		t.Fatal("Expected pending logins not to count toward the limit")
+	}
+	mgr.RemovePeer(3)
+
+	mgr.GetPeer(1).SetConnected()
+	mgr.GetPeer(2).SetConnected()
+	if p, ok := mgr.AddPeerLimited(3, addr, "MASTER-1", 2); ok || p != nil {
+		t.Error("Expected a new peer over the limit to be refused")
+	}
+
+	// Another system sharing the manager has its own limit
+	if _, ok := mgr.AddPeerLimited(3, addr, "MASTER-2", 2); !ok {
+		t.Error("Expected MASTER-2 not to count MASTER-1's peers")
+	}
+
+	moved := &net.UDPAddr{IP: net.ParseIP("192.168.1.101"), Port: 62031}
+	if p, ok := mgr.AddPeerLimited(1, moved, "MASTER-1", 2); !ok || p.Address != moved {
+		t.Error("Expected a known peer to be updated at the limit")
+	}
+	if _, ok := mgr.AddPeerLimited(4, addr, "MASTER-1", 0); !ok {
+		t.Error("Expected no limit with max 0")
+	}
+	if mgr.Count() != 4 {
+		t.Errorf("Expected 4 peers, got %d", mgr.Count())
+	}
+}
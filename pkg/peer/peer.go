@@ -43,6 +43,10 @@ type Peer struct {
 	Address *net.UDPAddr
 	State   ConnectionState
 
+	// System is the MASTER system the peer logged in to. Systems share one
+	// peer manager, so this tells their peers apart.
+	System string
+
 	// Configuration from RPTC packet
 	Callsign    string
 	RXFreq      string
@@ -75,6 +79,12 @@ type Peer struct {
 	// Repeat mode - when enabled, peer receives all traffic regardless of subscriptions
 	RepeatMode bool
 
+	// EchoOwn - when enabled, peer also receives its own traffic back
+	EchoOwn bool
+
+	// Streams currently being transmitted by this peer: streamID -> stream
+	activeStreams map[uint32]*activeStream
+
 	mu sync.RWMutex
 }
 
@@ -91,6 +101,8 @@ type Snapshot struct {
 	BytesRx       uint64    `json:"bytes_rx"`
 	PacketsTx     uint64    `json:"packets_tx"`
 	BytesTx       uint64    `json:"bytes_tx"`
+	Talking       bool      `json:"talking"`     // A call from this peer is in progress
+	CallFrames    int       `json:"call_frames"` // Frames received in the calls in progress
 	Subscriptions struct {
 		TS1 []uint32 `json:"ts1,omitempty"`
 		TS2 []uint32 `json:"ts2,omitempty"`
@@ -114,6 +126,7 @@ func (p *Peer) Snapshot(includeSubscriptions bool) Snapshot {
 		PacketsTx:   p.PacketsSent,
 		BytesTx:     p.BytesSent,
 	}
+	snap.Talking, snap.CallFrames = p.transmitActivityLocked(time.Now())
 	if p.Address != nil {
 		snap.Address = p.Address.String()
 	}
@@ -125,6 +138,44 @@ func (p *Peer) Snapshot(includeSubscriptions bool) Snapshot {
 	return snap
 }
 
+// RepeaterConfig is the configuration a peer reported in its RPTC packet
+type RepeaterConfig struct {
+	Callsign    string
+	RXFreq      string
+	TXFreq      string
+	TXPower     string
+	ColorCode   string
+	Latitude    string
+	Longitude   string
+	Height      string
+	Location    string
+	Description string
+	URL         string
+	SoftwareID  string
+	PackageID   string
+}
+
+// GetRepeaterConfig returns a copy of the peer's reported RPTC configuration
+func (p *Peer) GetRepeaterConfig() RepeaterConfig {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return RepeaterConfig{
+		Callsign:    p.Callsign,
+		RXFreq:      p.RXFreq,
+		TXFreq:      p.TXFreq,
+		TXPower:     p.TXPower,
+		ColorCode:   p.ColorCode,
+		Latitude:    p.Latitude,
+		Longitude:   p.Longitude,
+		Height:      p.Height,
+		Location:    p.Location,
+		Description: p.Description,
+		URL:         p.URL,
+		SoftwareID:  p.SoftwareID,
+		PackageID:   p.PackageID,
+	}
+}
+
 // NewPeer creates a new peer with the given ID and address
 func NewPeer(id uint32, addr *net.UDPAddr) *Peer {
 	return &Peer{
@@ -149,6 +200,20 @@ func (p *Peer) GetState() ConnectionState {
 	return p.State
 }
 
+// SetSystem records the MASTER system the peer logged in to
+func (p *Peer) SetSystem(system string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.System = system
+}
+
+// GetSystem returns the MASTER system the peer logged in to
+func (p *Peer) GetSystem() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.System
+}
+
 // UpdateLastHeard updates the last heard timestamp to now
 func (p *Peer) UpdateLastHeard() {
 	p.mu.Lock()
@@ -216,6 +281,7 @@ func (p *Peer) SetConfig(config *protocol.RPTCPacket) {
 		if opts, err := ParseOptions(optionsStr); err == nil {
 			// Update subscriptions (ignoring errors for backward compatibility)
 			_ = p.Subscriptions.Update(opts)
+			p.EchoOwn = opts.Echo
 		}
 	}
 }
@@ -308,3 +374,17 @@ func (p *Peer) GetRepeatMode() bool {
 	defer p.mu.RUnlock()
 	return p.RepeatMode
 }
+
+// SetEchoOwn enables or disables sending this peer its own traffic back
+func (p *Peer) SetEchoOwn(enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.EchoOwn = enabled
+}
+
+// GetEchoOwn returns whether this peer receives its own traffic back
+func (p *Peer) GetEchoOwn() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.EchoOwn
+}
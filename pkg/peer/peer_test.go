@@ -60,6 +60,18 @@ func TestPeer_SetState(t *testing.T) {
 	}
 }
 
+func TestPeer_SetSystem(t *testing.T) {
+	peer := NewPeer(312000, &net.UDPAddr{IP: net.ParseIP("192.168.1.100"), Port: 62031})
+	if got := peer.GetSystem(); got != "" {
+		t.Errorf("Expected no system before login, got %q", got)
+	}
+
+	peer.SetSystem("MASTER-1")
+	if got := peer.GetSystem(); got != "MASTER-1" {
+		t.Errorf("Expected system MASTER-1, got %q", got)
+	}
+}
+
 func TestPeer_UpdateLastHeard(t *testing.T) {
 	addr := &net.UDPAddr{IP: net.ParseIP("192.168.1.100"), Port: 62031}
 	peer := NewPeer(312000, addr)
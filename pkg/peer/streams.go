@@ -0,0 +1,109 @@
+package peer
+
+import (
+	"time"
+)
+
+// StreamIdleTimeout is how long a peer stream may go without packets before
+// it no longer counts against the peer's concurrent stream limit. Voice
+// frames arrive every 60ms, so a stream silent for this long has ended even
+// if its terminator was lost.
+const StreamIdleTimeout = 500 * time.Millisecond
+
+// activeStream tracks a stream currently being transmitted by a peer
+type activeStream struct {
+	timeslot int
+	lastSeen time.Time
+	frames   int // Frames received so far
+}
+
+// TrackStream records a packet for a stream transmitted by this peer.
+// A peer may only carry one stream per timeslot, and at most maxStreams
+// streams overall (no overall limit if maxStreams <= 0).
+// Returns false if the stream would exceed either limit and should be dropped.
+func (p *Peer) TrackStream(streamID uint32, timeslot int, maxStreams int, now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.activeStreams == nil {
+		p.activeStreams = make(map[uint32]*activeStream)
+	}
+
+	// Expire idle streams that never sent a terminator
+	for id, stream := range p.activeStreams {
+		if now.Sub(stream.lastSeen) > StreamIdleTimeout {
+			delete(p.activeStreams, id)
+		}
+	}
+
+	if stream, exists := p.activeStreams[streamID]; exists {
+		stream.lastSeen = now
+		stream.frames++
+		return true
+	}
+
+	for _, stream := range p.activeStreams {
+		if stream.timeslot == timeslot {
+			return false
+		}
+	}
+
+	if maxStreams > 0 && len(p.activeStreams) >= maxStreams {
+		return false
+	}
+
+	p.activeStreams[streamID] = &activeStream{
+		timeslot: timeslot,
+		lastSeen: now,
+		frames:   1,
+	}
+	return true
+}
+
+// StreamOnTimeslot returns the stream this peer is transmitting on a
+// timeslot, if one is active
+func (p *Peer) StreamOnTimeslot(timeslot int, now time.Time) (uint32, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for id, stream := range p.activeStreams {
+		if stream.timeslot == timeslot && now.Sub(stream.lastSeen) <= StreamIdleTimeout {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// EndStream stops tracking a stream for this peer (called on terminator)
+func (p *Peer) EndStream(streamID uint32) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.activeStreams, streamID)
+}
+
+// TransmitActivity reports whether this peer is transmitting, and how many
+// frames its current calls have carried. A call stops counting at its
+// terminator or once it goes idle.
+func (p *Peer) TransmitActivity(now time.Time) (bool, int) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.transmitActivityLocked(now)
+}
+
+// transmitActivityLocked is TransmitActivity; caller must hold p.mu
+func (p *Peer) transmitActivityLocked(now time.Time) (bool, int) {
+	talking, frames := false, 0
+	for _, stream := range p.activeStreams {
+		if now.Sub(stream.lastSeen) <= StreamIdleTimeout {
+			talking = true
+			frames += stream.frames
+		}
+	}
+	return talking, frames
+}
+
+// ActiveStreamCount returns the number of streams this peer is currently transmitting
+func (p *Peer) ActiveStreamCount() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.activeStreams)
+}
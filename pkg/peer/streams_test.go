@@ -0,0 +1,104 @@
+package peer
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestPeer_TrackStream_OnePerTimeslot(t *testing.T) {
+	p := NewPeer(312000, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 62031})
+	now := time.Now()
+
+	if !p.TrackStream(1001, 1, 2, now) {
+		t.Fatal("First stream on TS1 should be accepted")
+	}
+	if !p.TrackStream(1001, 1, 2, now.Add(60*time.Millisecond)) {
+		t.Error("Continuing stream should be accepted")
+	}
+	if p.TrackStream(1002, 1, 2, now.Add(120*time.Millisecond)) {
+		t.Error("Overlapping stream on the same timeslot should be rejected")
+	}
+	if !p.TrackStream(2001, 2, 2, now.Add(120*time.Millisecond)) {
+		t.Error("Stream on the other timeslot should be accepted")
+	}
+	if got := p.ActiveStreamCount(); got != 2 {
+		t.Errorf("Expected 2 active streams, got %d", got)
+	}
+}
+
+func TestPeer_TrackStream_MaxStreams(t *testing.T) {
+	p := NewPeer(312000, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 62031})
+	now := time.Now()
+
+	if !p.TrackStream(1001, 1, 1, now) {
+		t.Fatal("First stream should be accepted")
+	}
+	if p.TrackStream(2001, 2, 1, now) {
+		t.Error("Second stream should be rejected when max is 1")
+	}
+
+	p.EndStream(1001)
+	if !p.TrackStream(2001, 2, 1, now) {
+		t.Error("Stream should be accepted after the previous one ended")
+	}
+}
+
+func TestPeer_TrackStream_IdleStreamExpires(t *testing.T) {
+	p := NewPeer(312000, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 62031})
+	now := time.Now()
+
+	p.TrackStream(1001, 1, 2, now)
+	if !p.TrackStream(1002, 1, 2, now.Add(StreamIdleTimeout+time.Millisecond)) {
+		t.Error("New stream should replace an idle stream that lost its terminator")
+	}
+}
+
+func TestPeer_StreamOnTimeslot(t *testing.T) {
+	p := NewPeer(312000, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 62031})
+	now := time.Now()
+
+	if _, ok := p.StreamOnTimeslot(1, now); ok {
+		t.Fatal("Expected no stream before any is tracked")
+	}
+	p.TrackStream(1001, 1, 2, now)
+	if id, ok := p.StreamOnTimeslot(1, now.Add(60*time.Millisecond)); !ok || id != 1001 {
+		t.Errorf("StreamOnTimeslot(1) = %d, %v; want 1001", id, ok)
+	}
+	if _, ok := p.StreamOnTimeslot(2, now); ok {
+		t.Error("Expected no stream on the other timeslot")
+	}
+	if _, ok := p.StreamOnTimeslot(1, now.Add(StreamIdleTimeout+time.Millisecond)); ok {
+		t.Error("Expected an idle stream not to count")
+	}
+}
+
+func TestPeer_TransmitActivity(t *testing.T) {
+	p := NewPeer(312000, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 62031})
+	now := time.Now()
+
+	if talking, _ := p.TransmitActivity(now); talking {
+		t.Fatal("Expected an idle peer not to be talking")
+	}
+
+	for i := 0; i < 3; i++ {
+		p.TrackStream(1001, 1, 2, now.Add(time.Duration(i)*60*time.Millisecond))
+	}
+	at := now.Add(150 * time.Millisecond)
+	if talking, frames := p.TransmitActivity(at); !talking || frames != 3 {
+		t.Fatalf("TransmitActivity() = %v, %d during a call, want true, 3", talking, frames)
+	}
+	if snap := p.Snapshot(false); !snap.Talking || snap.CallFrames != 3 {
+		t.Errorf("Snapshot talking=%v frames=%d during a call, want true, 3", snap.Talking, snap.CallFrames)
+	}
+
+	p.EndStream(1001)
+	if talking, frames := p.TransmitActivity(at); talking || frames != 0 {
+		t.Errorf("TransmitActivity() = %v, %d after the terminator, want false, 0", talking, frames)
+	}
+
+	p.TrackStream(1002, 1, 2, now)
+	if talking, frames := p.TransmitActivity(now.Add(StreamIdleTimeout + time.Millisecond)); talking || frames != 0 {
+		t.Errorf("TransmitActivity() = %v, %d after the call went idle, want false, 0", talking, frames)
+	}
+}
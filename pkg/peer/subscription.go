@@ -2,6 +2,7 @@ package peer
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -17,11 +18,14 @@ const (
 
 // SubscriptionOptions represents parsed OPTIONS from a peer
 type SubscriptionOptions struct {
-	TS1      []uint32 // Static talkgroups for timeslot 1
-	TS2      []uint32 // Static talkgroups for timeslot 2
-	Auto     int      // Auto-static TTL in seconds
-	DropAll  bool     // Clear all static talkgroups
-	UnlinkTS uint8    // Unlink specific timeslot (1 or 2)
+	TS1       []uint32 // Static talkgroups for timeslot 1
+	TS2       []uint32 // Static talkgroups for timeslot 2
+	Auto      int      // Auto-static TTL in seconds
+	DropAll   bool     // Clear all static talkgroups
+	UnlinkTS  uint8    // Unlink specific timeslot (1 or 2)
+	UnlinkAll bool     // Clear dynamic subscriptions on both timeslots
+	Allow     []uint32 // Talkgroups the peer may subscribe to (empty = any)
+	Echo      bool     // Receive own traffic back (simulcast/voting receivers)
 }
 
 // SubscriptionState tracks dynamic talkgroup subscriptions for a peer
@@ -30,14 +34,27 @@ type SubscriptionState struct {
 	TS2         map[uint32]time.Time // Talkgroup -> expiry time for TS2
 	AutoTTL     time.Duration        // Auto-static TTL
 	LastUpdated time.Time            // Last update timestamp
-	mu          sync.RWMutex
+	// Allowed restricts which talkgroups may be subscribed to (nil = any).
+	// When set by the server it overrides ALLOW= from the peer's OPTIONS.
+	Allowed         map[uint32]bool
+	allowedOverride bool
+	// When the peer last transmitted on each dynamic talkgroup
+	activity map[activityKey]time.Time
+	mu       sync.RWMutex
+}
+
+// activityKey identifies a talkgroup on a timeslot
+type activityKey struct {
+	tgid     uint32
+	timeslot uint8
 }
 
 // NewSubscriptionState creates a new subscription state
 func NewSubscriptionState() *SubscriptionState {
 	return &SubscriptionState{
-		TS1: make(map[uint32]time.Time),
-		TS2: make(map[uint32]time.Time),
+		TS1:      make(map[uint32]time.Time),
+		TS2:      make(map[uint32]time.Time),
+		activity: make(map[activityKey]time.Time),
 	}
 }
 
@@ -68,6 +85,17 @@ func (s *SubscriptionState) Update(opts *SubscriptionOptions) error {
 	case 2:
 		s.TS2 = make(map[uint32]time.Time)
 	}
+	if opts.UnlinkAll {
+		s.clearDynamicLocked()
+	}
+
+	// Update talkgroup whitelist unless the server has pinned one
+	if len(opts.Allow) > 0 && !s.allowedOverride {
+		s.Allowed = make(map[uint32]bool, len(opts.Allow))
+		for _, tgid := range opts.Allow {
+			s.Allowed[tgid] = true
+		}
+	}
 
 	// Update auto TTL
 	if opts.Auto > 0 {
@@ -84,20 +112,61 @@ func (s *SubscriptionState) Update(opts *SubscriptionOptions) error {
 	// Update TS1 talkgroups
 	if len(opts.TS1) > 0 {
 		for _, tgid := range opts.TS1 {
-			s.TS1[tgid] = expiryTime
+			if s.allowedLocked(tgid) {
+				s.TS1[tgid] = expiryTime
+			}
 		}
 	}
 
 	// Update TS2 talkgroups
 	if len(opts.TS2) > 0 {
 		for _, tgid := range opts.TS2 {
-			s.TS2[tgid] = expiryTime
+			if s.allowedLocked(tgid) {
+				s.TS2[tgid] = expiryTime
+			}
 		}
 	}
 
 	return nil
 }
 
+// SetAllowedOverride pins the talkgroup whitelist from server configuration.
+// ALLOW= in later OPTIONS is ignored. Existing subscriptions to talkgroups
+// outside the whitelist are removed.
+func (s *SubscriptionState) SetAllowedOverride(tgids []uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Allowed = make(map[uint32]bool, len(tgids))
+	for _, tgid := range tgids {
+		s.Allowed[tgid] = true
+	}
+	s.allowedOverride = true
+
+	for tgid := range s.TS1 {
+		if !s.Allowed[tgid] {
+			delete(s.TS1, tgid)
+		}
+	}
+	for tgid := range s.TS2 {
+		if !s.Allowed[tgid] {
+			delete(s.TS2, tgid)
+		}
+	}
+}
+
+// IsAllowed reports whether the peer may subscribe to a talkgroup
+func (s *SubscriptionState) IsAllowed(tgid uint32) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.allowedLocked(tgid)
+}
+
+// allowedLocked checks the whitelist; caller must hold s.mu
+func (s *SubscriptionState) allowedLocked(tgid uint32) bool {
+	return s.Allowed == nil || s.Allowed[tgid]
+}
+
 // HasTalkgroup checks if a talkgroup is in the subscription for the given timeslot
 func (s *SubscriptionState) HasTalkgroup(tgid uint32, timeslot uint8) bool {
 	s.mu.RLock()
@@ -165,6 +234,32 @@ func (s *SubscriptionState) GetTalkgroups(timeslot uint8) []uint32 {
 	return result
 }
 
+// GetStaticTalkgroups returns the static (non-expiring) talkgroups for the
+// given timeslot, sorted
+func (s *SubscriptionState) GetStaticTalkgroups(timeslot uint8) []uint32 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var tgMap map[uint32]time.Time
+	switch timeslot {
+	case 1:
+		tgMap = s.TS1
+	case 2:
+		tgMap = s.TS2
+	default:
+		return []uint32{}
+	}
+
+	result := make([]uint32, 0, len(tgMap))
+	for tgid, expiryTime := range tgMap {
+		if expiryTime.IsZero() {
+			result = append(result, tgid)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
 // IsExpired checks if the subscription has expired based on TTL
 func (s *SubscriptionState) IsExpired() bool {
 	s.mu.RLock()
@@ -216,10 +311,15 @@ func (s *SubscriptionState) CleanupExpired() {
 // Only allows one dynamic TG per timeslot - clears other dynamic TGs in the same slot
 // Uses the peer's AutoTTL setting (from OPTIONS) or unlimited if AutoTTL is 0
 // Returns true if this is a NEW subscription (first key-up), false if already subscribed
+// or if the talkgroup is not in the peer's whitelist
 func (s *SubscriptionState) AddDynamic(tgid uint32, timeslot uint8) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if !s.allowedLocked(tgid) {
+		return false
+	}
+
 	var tgMap map[uint32]time.Time
 	switch timeslot {
 	case 1:
@@ -230,6 +330,11 @@ func (s *SubscriptionState) AddDynamic(tgid uint32, timeslot uint8) bool {
 		return false
 	}
 
+	if s.activity == nil {
+		s.activity = make(map[activityKey]time.Time)
+	}
+	s.activity[activityKey{tgid: tgid, timeslot: timeslot}] = time.Now()
+
 	// Check if already subscribed to this TG (return false = not new)
 	if expiry, exists := tgMap[tgid]; exists {
 		// Already subscribed - just extend/refresh the TTL
@@ -290,6 +395,39 @@ func (s *SubscriptionState) TouchDynamic(tgid uint32, timeslot uint8, ttl time.D
 	}
 }
 
+// RemoveInactiveDynamic removes dynamic subscriptions the peer has not
+// transmitted on for longer than idle, regardless of AutoTTL. Static
+// subscriptions are kept. It returns the talkgroups removed.
+func (s *SubscriptionState) RemoveInactiveDynamic(idle time.Duration, now time.Time) []uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var removed []uint32
+	for key, last := range s.activity {
+		var tgMap map[uint32]time.Time
+		if key.timeslot == 1 {
+			tgMap = s.TS1
+		} else {
+			tgMap = s.TS2
+		}
+		expiry, exists := tgMap[key.tgid]
+		if !exists || expiry.IsZero() {
+			// Unsubscribed since, or now static: nothing to time out
+			delete(s.activity, key)
+			continue
+		}
+		if now.Sub(last) > idle {
+			delete(tgMap, key.tgid)
+			delete(s.activity, key)
+			removed = append(removed, key.tgid)
+		}
+	}
+	if len(removed) > 0 {
+		s.LastUpdated = now
+	}
+	return removed
+}
+
 // ClearAllDynamic removes all dynamic subscriptions while keeping static ones (from RPTC OPTIONS)
 // This is used when a peer transmits on the special disconnect TG (4000)
 // Static subscriptions have time.Time{} (zero value)
@@ -298,6 +436,14 @@ func (s *SubscriptionState) ClearAllDynamic() int {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	count := s.clearDynamicLocked()
+	s.LastUpdated = time.Now()
+	return count
+}
+
+// clearDynamicLocked removes dynamic subscriptions on both timeslots and
+// returns how many were removed; caller must hold s.mu
+func (s *SubscriptionState) clearDynamicLocked() int {
 	count := 0
 
 	// Clear TS1 dynamic subscriptions (non-zero expiry time)
@@ -318,7 +464,6 @@ func (s *SubscriptionState) ClearAllDynamic() int {
 		}
 	}
 
-	s.LastUpdated = time.Now()
 	return count
 }
 
@@ -385,7 +530,7 @@ func (s *SubscriptionState) IsSubscribedToTalkgroup(tgid uint32) bool {
 }
 
 // ParseOptions parses an OPTIONS string into SubscriptionOptions
-// Format: TS1=3100,3101;TS2=91;AUTO=600;DROP=ALL;UNLINK=TS1
+// Format: TS1=3100,3101;TS2=91;AUTO=600;DROP=ALL;UNLINK=TS1|TS2|ALL;ALLOW=3100,3101,91;ECHO=1
 func ParseOptions(input string) (*SubscriptionOptions, error) {
 	opts := &SubscriptionOptions{
 		TS1: []uint32{},
@@ -441,6 +586,19 @@ func ParseOptions(input string) (*SubscriptionOptions, error) {
 				opts.DropAll = true
 			}
 
+		case "ALLOW":
+			tgs, err := parseTalkgroupList(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ALLOW value: %w", err)
+			}
+			opts.Allow = tgs
+
+		case "ECHO":
+			switch strings.ToUpper(value) {
+			case "1", "ON", "YES", "TRUE":
+				opts.Echo = true
+			}
+
 		case "UNLINK":
 			ts := strings.ToUpper(value)
 			switch ts {
@@ -448,6 +606,8 @@ func ParseOptions(input string) (*SubscriptionOptions, error) {
 				opts.UnlinkTS = 1
 			case "TS2":
 				opts.UnlinkTS = 2
+			case "ALL":
+				opts.UnlinkAll = true
 			}
 		}
 	}
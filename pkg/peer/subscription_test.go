@@ -171,6 +171,42 @@ func TestParseOptions(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:  "With ALLOW whitelist",
+			input: "TS1=3100;ALLOW=3100,91",
+			want: &SubscriptionOptions{
+				TS1:   []uint32{3100},
+				TS2:   []uint32{},
+				Allow: []uint32{3100, 91},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "With UNLINK=ALL",
+			input: "UNLINK=ALL",
+			want: &SubscriptionOptions{
+				TS1:       []uint32{},
+				TS2:       []uint32{},
+				UnlinkAll: true,
+			},
+			wantErr: false,
+		},
+		{
+			name:  "With ECHO=1",
+			input: "TS1=3100;ECHO=1",
+			want: &SubscriptionOptions{
+				TS1:  []uint32{3100},
+				TS2:  []uint32{},
+				Echo: true,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Invalid ALLOW value",
+			input:   "ALLOW=abc",
+			want:    nil,
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -293,6 +329,54 @@ func TestSubscriptionState_Update(t *testing.T) {
 	}
 }
 
+func TestSubscriptionState_AllowedTalkgroups(t *testing.T) {
+	state := NewSubscriptionState()
+
+	if !state.IsAllowed(9) {
+		t.Error("All talkgroups should be allowed without a whitelist")
+	}
+
+	err := state.Update(&SubscriptionOptions{
+		TS1:   []uint32{3100, 3200},
+		Allow: []uint32{3100, 91},
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	if _, ok := state.TS1[3200]; ok {
+		t.Error("Static subscription outside whitelist should be ignored")
+	}
+	if _, ok := state.TS1[3100]; !ok {
+		t.Error("Static subscription inside whitelist should be kept")
+	}
+
+	if state.AddDynamic(9, 2) {
+		t.Error("AddDynamic should refuse a talkgroup outside the whitelist")
+	}
+	if state.HasTalkgroup(9, 2) {
+		t.Error("Talkgroup outside whitelist should not be subscribed")
+	}
+	if !state.AddDynamic(91, 2) {
+		t.Error("AddDynamic should accept a whitelisted talkgroup")
+	}
+
+	// Server override replaces OPTIONS and survives later ALLOW=
+	state.SetAllowedOverride([]uint32{91})
+	if _, ok := state.TS1[3100]; ok {
+		t.Error("Override should remove subscriptions outside the new whitelist")
+	}
+	if err := state.Update(&SubscriptionOptions{Allow: []uint32{3100}}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if state.IsAllowed(3100) {
+		t.Error("ALLOW= in OPTIONS should not replace the server override")
+	}
+	if !state.IsAllowed(91) {
+		t.Error("Override talkgroup should be allowed")
+	}
+}
+
 func TestSubscriptionState_UpdateWithDropAll(t *testing.T) {
 	state := NewSubscriptionState()
 
@@ -366,6 +450,33 @@ func TestSubscriptionState_UpdateWithUnlink(t *testing.T) {
 	}
 }
 
+func TestSubscriptionState_UpdateWithUnlinkAll(t *testing.T) {
+	state := NewSubscriptionState()
+
+	// Static subscriptions on both slots
+	if err := state.Update(&SubscriptionOptions{TS1: []uint32{3100}, TS2: []uint32{91}}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	// Dynamic subscriptions on both slots
+	state.AddDynamic(9990, 1)
+	state.AddDynamic(9991, 2)
+
+	opts, err := ParseOptions("UNLINK=ALL")
+	if err != nil {
+		t.Fatalf("ParseOptions() error = %v", err)
+	}
+	if err := state.Update(opts); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	if state.HasTalkgroup(9990, 1) || state.HasTalkgroup(9991, 2) {
+		t.Error("UNLINK=ALL should clear dynamic subscriptions on both timeslots")
+	}
+	if !state.HasTalkgroup(3100, 1) || !state.HasTalkgroup(91, 2) {
+		t.Error("UNLINK=ALL should keep static subscriptions")
+	}
+}
+
 func TestSubscriptionState_HasTalkgroup(t *testing.T) {
 	state := NewSubscriptionState()
 
@@ -638,3 +749,37 @@ func TestSentinelUnlimitedDynamic_AAA(t *testing.T) {
 		t.Errorf("GetTalkgroups should return the sentinel dynamic TG: %v", groups)
 	}
 }
+
+func TestSubscriptionState_RemoveInactiveDynamic(t *testing.T) {
+	s := NewSubscriptionState()
+	if err := s.Update(&SubscriptionOptions{TS1: []uint32{91}}); err != nil {
+		t.Fatalf("Update error: %v", err)
+	}
+	s.AddDynamic(3100, 1)
+	s.AddDynamic(3120, 2)
+
+	now := time.Now()
+	if removed := s.RemoveInactiveDynamic(10*time.Minute, now); len(removed) != 0 {
+		t.Fatalf("Nothing should be idle yet, removed %v", removed)
+	}
+
+	// Keep TS2's talkgroup active
+	s.AddDynamic(3120, 2)
+	s.mu.Lock()
+	s.activity[activityKey{tgid: 3120, timeslot: 2}] = now.Add(9 * time.Minute)
+	s.mu.Unlock()
+
+	removed := s.RemoveInactiveDynamic(10*time.Minute, now.Add(11*time.Minute))
+	if len(removed) != 1 || removed[0] != 3100 {
+		t.Fatalf("Expected TG 3100 unlinked, got %v", removed)
+	}
+	if s.IsSubscribed(3100, 1) {
+		t.Error("Idle dynamic talkgroup should be removed")
+	}
+	if !s.IsSubscribed(3120, 2) {
+		t.Error("Active dynamic talkgroup should be kept")
+	}
+	if !s.IsSubscribed(91, 1) {
+		t.Error("Static talkgroup should never be unlinked")
+	}
+}
@@ -0,0 +1,76 @@
+package privacy
+
+import (
+	"testing"
+
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+)
+
+func TestAnonymizer_NilPassesThrough(t *testing.T) {
+	var a *Anonymizer
+	if a.Enabled() {
+		t.Fatal("nil Anonymizer must be disabled")
+	}
+	if got := a.RadioID(3120001); got != 3120001 {
+		t.Errorf("RadioID = %d, want 3120001", got)
+	}
+	if got := a.Callsign("N0CALL"); got != "N0CALL" {
+		t.Errorf("Callsign = %q, want N0CALL", got)
+	}
+	if New("", "salt") != nil {
+		t.Error("New with no mode must return nil")
+	}
+}
+
+func TestAnonymizer_HashIsStableAndOutOfRange(t *testing.T) {
+	a := New(ModeHash, "secret")
+
+	id := a.RadioID(3120001)
+	if id == 3120001 || id <= maxRadioID {
+		t.Fatalf("pseudonym %d must leave the 24-bit DMR range", id)
+	}
+	if a.RadioID(3120001) != id {
+		t.Error("pseudonyms must be stable")
+	}
+	if a.RadioID(id) != id {
+		t.Error("pseudonyms must not be hashed again")
+	}
+	if a.RadioID(3120002) == id {
+		t.Error("distinct IDs must get distinct pseudonyms")
+	}
+	if New(ModeHash, "other").RadioID(3120001) == id {
+		t.Error("pseudonyms must depend on the salt")
+	}
+
+	cs := a.Callsign("n0call")
+	if cs == "" || cs == "n0call" || cs != a.Callsign("N0CALL") {
+		t.Errorf("unexpected callsign pseudonym %q", cs)
+	}
+}
+
+func TestAnonymizer_Omit(t *testing.T) {
+	a := New(ModeOmit, "")
+	if got := a.RadioID(3120001); got != 0 {
+		t.Errorf("RadioID = %d, want 0", got)
+	}
+	if got := a.Callsign("N0CALL"); got != "" {
+		t.Errorf("Callsign = %q, want empty", got)
+	}
+}
+
+func TestAnonymizer_LogField(t *testing.T) {
+	a := New(ModeHash, "secret")
+
+	if f := a.LogField(logger.Int("src", 3120001)); f.Value != a.RadioID(3120001) {
+		t.Errorf("src = %v, want pseudonym", f.Value)
+	}
+	if f := a.LogField(logger.Any("radio_id", uint32(3120001))); f.Value != a.RadioID(3120001) {
+		t.Errorf("radio_id = %v, want pseudonym", f.Value)
+	}
+	if f := a.LogField(logger.Int("peer_id", 312000)); f.Value != 312000 {
+		t.Errorf("peer_id = %v, repeater IDs must be kept", f.Value)
+	}
+	if f := New(ModeOmit, "").LogField(logger.Int("src_id", 3120001)); f.Value != omitted {
+		t.Errorf("src_id = %v, want %q", f.Value, omitted)
+	}
+}
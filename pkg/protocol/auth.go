@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"strings"
+	"unicode/utf8"
 )
 
 // RPTLPacket represents a login request from a peer
@@ -102,20 +103,28 @@ func (p *RPTCPacket) Parse(data []byte) error {
 	p.RepeaterID = binary.BigEndian.Uint32(data[4:8])
 
 	// Parse fixed-length string fields (trim spaces and nulls)
-	p.Callsign = strings.TrimSpace(string(data[8:16]))
-	p.RXFreq = strings.TrimSpace(string(data[16:25]))
-	p.TXFreq = strings.TrimSpace(string(data[25:34]))
-	p.TXPower = strings.TrimSpace(string(data[34:36]))
-	p.ColorCode = strings.TrimSpace(string(data[36:38]))
-	p.Latitude = strings.TrimSpace(string(data[38:46]))
-	p.Longitude = strings.TrimSpace(string(data[46:55]))
-	p.Height = strings.TrimSpace(string(data[55:58]))
-	p.Location = strings.TrimSpace(string(data[58:78]))
-	p.Description = strings.TrimSpace(string(data[78:97]))
-	p.Slots = strings.TrimSpace(string(data[97:98]))
-	p.URL = strings.TrimSpace(string(data[98:222]))
-	p.SoftwareID = strings.TrimSpace(string(data[222:262]))
-	p.PackageID = strings.TrimSpace(string(data[262:302]))
+	p.Callsign = trimField(data[8:16])
+	p.RXFreq = trimField(data[16:25])
+	p.TXFreq = trimField(data[25:34])
+	p.TXPower = trimField(data[34:36])
+	p.ColorCode = trimField(data[36:38])
+	p.Latitude = trimField(data[38:46])
+	p.Longitude = trimField(data[46:55])
+	p.Height = trimField(data[55:58])
+	p.Location = trimField(data[58:78])
+	p.Description = SanitizeDescription(trimField(data[78:97]), 0)
+	p.Slots = trimField(data[97:98])
+	p.URL = trimField(data[98:222])
+	p.SoftwareID = trimField(data[222:262])
+	p.PackageID = trimField(data[262:302])
+
+	// Some clients pad or shift the header slightly; if the fixed offset
+	// yields garbage, scan the start of the header for the first ASCII token
+	if p.Callsign != "" && !isCallsign(p.Callsign) {
+		if cs := scanCallsign(data[8:34]); cs != "" {
+			p.Callsign = cs
+		}
+	}
 
 	return nil
 }
@@ -155,6 +164,106 @@ func (p *RPTCPacket) Encode() ([]byte, error) {
 	return data, nil
 }
 
+// trimField converts a fixed-width field to a string, trimming the space and
+// null padding used by different clients
+func trimField(b []byte) string {
+	return strings.Trim(string(b), " \t\r\n\x00")
+}
+
+// SanitizeDescription strips control characters from a peer description so it
+// is safe to log and display, and clamps it to maxLen bytes (0 = no limit).
+// An "OPTIONS:" tail is kept whole when clamping, since subscriptions are
+// parsed from it; only the free text in front of it is shortened.
+func SanitizeDescription(s string, maxLen int) string {
+	clean := strings.TrimSpace(strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, s))
+	if maxLen <= 0 || len(clean) <= maxLen {
+		return clean
+	}
+
+	idx := indexFoldASCII(clean, "OPTIONS:")
+	if idx == -1 {
+		return truncateUTF8(clean, maxLen)
+	}
+	tail := clean[idx:]
+	if len(tail) >= maxLen {
+		return tail
+	}
+	return truncateUTF8(clean[:idx], maxLen-len(tail)) + tail
+}
+
+// indexFoldASCII returns the byte offset in s of the first case-insensitive
+// match of the ASCII string substr, or -1. Searching strings.ToUpper(s)
+// instead gives offsets that are wrong for s once upper-casing changes a
+// rune's length.
+func indexFoldASCII(s, substr string) int {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if strings.EqualFold(s[i:i+len(substr)], substr) {
+			return i
+		}
+	}
+	return -1
+}
+
+// truncateUTF8 shortens s to at most n bytes without splitting a rune
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// isCallsignChar reports whether c may appear in a callsign
+func isCallsignChar(c byte) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '/' || c == '-'
+}
+
+// isCallsign reports whether s looks like a callsign: 3-8 callsign
+// characters with at least one letter
+func isCallsign(s string) bool {
+	if len(s) < 3 || len(s) > 8 {
+		return false
+	}
+	hasLetter := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !isCallsignChar(c) {
+			return false
+		}
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') {
+			hasLetter = true
+		}
+	}
+	return hasLetter
+}
+
+// scanCallsign returns the first token in b that looks like a callsign, or ""
+func scanCallsign(b []byte) string {
+	start := -1
+	for i := 0; i <= len(b); i++ {
+		if i < len(b) && isCallsignChar(b[i]) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			if token := string(b[start:i]); isCallsign(token) {
+				return token
+			}
+			start = -1
+		}
+	}
+	return ""
+}
+
 // RPTACKPacket represents an acknowledgement from master
 type RPTACKPacket struct {
 	RepeaterID uint32
@@ -284,6 +393,40 @@ func (p *MSTCLPacket) Encode() ([]byte, error) {
 	return data, nil
 }
 
+// MSTRDRPacket tells a peer, ahead of MSTCL, which master to reconnect to.
+// It is not part of HomeBrew; clients that don't know it ignore it.
+type MSTRDRPacket struct {
+	RepeaterID uint32
+	Address    string // "host:port"
+}
+
+// Parse parses an MSTRDR packet from raw bytes
+func (p *MSTRDRPacket) Parse(data []byte) error {
+	if len(data) <= MSTRDRMinPacketSize {
+		return fmt.Errorf("invalid MSTRDR packet size: %d (expected more than %d)", len(data), MSTRDRMinPacketSize)
+	}
+
+	if string(data[0:6]) != PacketTypeMSTRDR {
+		return fmt.Errorf("invalid MSTRDR signature: %s", string(data[0:6]))
+	}
+
+	p.RepeaterID = binary.BigEndian.Uint32(data[6:10])
+	p.Address = string(data[10:])
+	return nil
+}
+
+// Encode encodes the MSTRDR packet to raw bytes
+func (p *MSTRDRPacket) Encode() ([]byte, error) {
+	if p.Address == "" {
+		return nil, fmt.Errorf("MSTRDR address is empty")
+	}
+	data := make([]byte, MSTRDRMinPacketSize+len(p.Address))
+	copy(data[0:6], []byte(PacketTypeMSTRDR))
+	binary.BigEndian.PutUint32(data[6:10], p.RepeaterID)
+	copy(data[10:], p.Address)
+	return data, nil
+}
+
 // Helper functions for parsing packets
 
 // ParseRPTL parses an RPTL packet from raw bytes
@@ -334,3 +477,10 @@ func ParseMSTCL(data []byte) (*MSTCLPacket, error) {
 	err := p.Parse(data)
 	return p, err
 }
+
+// ParseMSTRDR parses an MSTRDR packet from raw bytes
+func ParseMSTRDR(data []byte) (*MSTRDRPacket, error) {
+	p := &MSTRDRPacket{}
+	err := p.Parse(data)
+	return p, err
+}
@@ -3,6 +3,7 @@ package protocol
 import (
 	"bytes"
 	"encoding/binary"
+	"strings"
 	"testing"
 )
 
@@ -167,6 +168,125 @@ func TestRPTCPacket_Parse(t *testing.T) {
 	}
 }
 
+func TestRPTCPacket_ParseClientVariants(t *testing.T) {
+	base := func() []byte {
+		data := make([]byte, RPTCPacketSize)
+		for i := 8; i < len(data); i++ {
+			data[i] = ' '
+		}
+		copy(data[0:4], []byte("RPTC"))
+		binary.BigEndian.PutUint32(data[4:8], 312000)
+		return data
+	}
+
+	tests := []struct {
+		name     string
+		build    func() []byte
+		callsign string
+		location string
+	}{
+		{
+			name: "MMDVMHost space padding",
+			build: func() []byte {
+				data := base()
+				copy(data[8:16], []byte("W1ABC   "))
+				copy(data[16:25], []byte("449000000"))
+				copy(data[58:78], []byte("Boston, MA          "))
+				return data
+			},
+			callsign: "W1ABC",
+			location: "Boston, MA",
+		},
+		{
+			name: "Null padded fields",
+			build: func() []byte {
+				data := base()
+				copy(data[8:16], []byte("W1ABC\x00\x00\x00"))
+				copy(data[16:25], []byte("449000000"))
+				copy(data[58:78], []byte("Boston, MA\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"))
+				return data
+			},
+			callsign: "W1ABC",
+			location: "Boston, MA",
+		},
+		{
+			name: "Callsign shifted by a stray byte",
+			build: func() []byte {
+				data := base()
+				data[8] = 0x01
+				copy(data[9:16], []byte("W1ABC  "))
+				copy(data[16:25], []byte("449000000"))
+				return data
+			},
+			callsign: "W1ABC",
+			location: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packet, err := ParseRPTC(tt.build())
+			if err != nil {
+				t.Fatalf("Failed to parse RPTC packet: %v", err)
+			}
+			if packet.Callsign != tt.callsign {
+				t.Errorf("Expected callsign %q, got %q", tt.callsign, packet.Callsign)
+			}
+			if packet.Location != tt.location {
+				t.Errorf("Expected location %q, got %q", tt.location, packet.Location)
+			}
+			if packet.RXFreq != "449000000" {
+				t.Errorf("Expected RX freq 449000000, got %q", packet.RXFreq)
+			}
+		})
+	}
+}
+
+func TestRPTCPacket_ParseControlCharsInDescription(t *testing.T) {
+	data := make([]byte, RPTCPacketSize)
+	for i := 8; i < len(data); i++ {
+		data[i] = ' '
+	}
+	copy(data[0:4], []byte("RPTC"))
+	binary.BigEndian.PutUint32(data[4:8], 312000)
+	copy(data[8:16], []byte("W1ABC   "))
+	copy(data[78:97], []byte("\x1bHi\r\nOPTIONS:TS2=9"))
+
+	packet, err := ParseRPTC(data)
+	if err != nil {
+		t.Fatalf("Failed to parse RPTC packet: %v", err)
+	}
+	if packet.Description != "HiOPTIONS:TS2=9" {
+		t.Errorf("Expected control characters stripped, got %q", packet.Description)
+	}
+}
+
+func TestSanitizeDescription(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		maxLen int
+		want   string
+	}{
+		{"plain", "Boston repeater", 0, "Boston repeater"},
+		{"control characters", "Bos\x00ton\x07\trpt\x7f", 0, "Bostonrpt"},
+		{"invalid UTF-8", "Caf\xff\xfe", 0, "Caf"},
+		{"oversized without options", strings.Repeat("A", 40), 10, strings.Repeat("A", 10)},
+		{"oversized keeps options tail", strings.Repeat("x", 30) + " OPTIONS:TS1=3100;TS2=91", 32, "xxxxxxxxxOPTIONS:TS1=3100;TS2=91"},
+		{"options tail alone exceeds limit", "Long text OPTIONS:TS1=3100,3101,3102", 10, "OPTIONS:TS1=3100,3101,3102"},
+		{"clamp does not split runes", "Zürich", 2, "Z"},
+		{"options found past runes upper-casing shortens", "ſſſſſſ OPTIONS:TS1=91", 20, "ſſſOPTIONS:TS1=91"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeDescription(tt.in, tt.maxLen); got != tt.want {
+				t.Errorf("SanitizeDescription(%q, %d) = %q, want %q", tt.in, tt.maxLen, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestRPTCPacket_Encode(t *testing.T) {
 	packet := &RPTCPacket{
 		RepeaterID:  312000,
@@ -472,3 +592,30 @@ func TestAuthPackets_InvalidSize(t *testing.T) {
 		})
 	}
 }
+
+func TestMSTRDRPacket_RoundTrip(t *testing.T) {
+	packet := &MSTRDRPacket{RepeaterID: 312000, Address: "backup.example.net:62031"}
+
+	data, err := packet.Encode()
+	if err != nil {
+		t.Fatalf("Failed to encode MSTRDR packet: %v", err)
+	}
+	if !bytes.Equal(data[0:6], []byte("MSTRDR")) {
+		t.Error("Invalid signature in encoded packet")
+	}
+
+	parsed, err := ParseMSTRDR(data)
+	if err != nil {
+		t.Fatalf("Failed to parse MSTRDR packet: %v", err)
+	}
+	if parsed.RepeaterID != 312000 || parsed.Address != "backup.example.net:62031" {
+		t.Errorf("Round trip mismatch: %+v", parsed)
+	}
+
+	if _, err := ParseMSTRDR(data[:MSTRDRMinPacketSize]); err == nil {
+		t.Error("Expected an error for an MSTRDR without an address")
+	}
+	if _, err := (&MSTRDRPacket{RepeaterID: 1}).Encode(); err == nil {
+		t.Error("Expected an error encoding an MSTRDR without an address")
+	}
+}
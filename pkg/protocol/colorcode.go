@@ -0,0 +1,158 @@
+package protocol
+
+import "math/bits"
+
+// The color code is carried twice in a burst: data sync bursts (LC headers,
+// terminators, data) hold it in the Golay(20,8) coded slot type either side
+// of the sync, and voice bursts B-F hold it in the QR(16,7) coded EMB either
+// side of the embedded signalling. Voice burst A carries only sync.
+
+// golay2087Rows are the 12 parity bits for each of the 8 data bits (LSB
+// first), laid out as they follow the data in the burst: 8 bits then 4
+var golay2087Rows = [8]uint16{0x8eb, 0x93e, 0xa97, 0xdc6, 0x367, 0x6cd, 0xd99, 0xf68}
+
+// qr1676Rows are the 9 parity bits for each of the 7 data bits (LSB first)
+var qr1676Rows = [7]uint16{0x073, 0x0e5, 0x1c9, 0x1e2, 0x1b7, 0x11e, 0x04f}
+
+// golay2087Encode returns the 20-bit slot type codeword for an 8-bit value
+func golay2087Encode(value uint8) uint32 {
+	var parity uint16
+	for i, row := range golay2087Rows {
+		if value&(1<<i) != 0 {
+			parity ^= row
+		}
+	}
+	return uint32(value)<<12 | uint32(parity)
+}
+
+// qr1676Encode returns the 16-bit EMB codeword for a 7-bit value
+func qr1676Encode(value uint8) uint16 {
+	var parity uint16
+	for i, row := range qr1676Rows {
+		if value&(1<<i) != 0 {
+			parity ^= row
+		}
+	}
+	return uint16(value&0x7f)<<9 | parity
+}
+
+// slotTypeBits are the burst bit positions of the 20 slot type bits
+var slotTypeBits = func() [20]int {
+	var pos [20]int
+	for i := range pos {
+		pos[i] = 98 + i
+		if i >= 10 {
+			pos[i] = 156 + i - 10
+		}
+	}
+	return pos
+}()
+
+// embBits are the burst bit positions of the 16 EMB bits
+var embBits = func() [16]int {
+	var pos [16]int
+	for i := range pos {
+		pos[i] = 108 + i
+		if i >= 8 {
+			pos[i] = 148 + i - 8
+		}
+	}
+	return pos
+}()
+
+// readBits reads the bits at pos, first position as the most significant
+func readBits(payload []byte, pos []int) uint32 {
+	var v uint32
+	for _, bit := range pos {
+		v <<= 1
+		if payload[bit/8]&(0x80>>(bit%8)) != 0 {
+			v |= 1
+		}
+	}
+	return v
+}
+
+// writeBits writes the low len(pos) bits of v to the bits at pos
+func writeBits(payload []byte, pos []int, v uint32) {
+	for i, bit := range pos {
+		mask := byte(0x80 >> (bit % 8))
+		if v&(1<<(len(pos)-1-i)) != 0 {
+			payload[bit/8] |= mask
+		} else {
+			payload[bit/8] &^= mask
+		}
+	}
+}
+
+// carriesSlotType reports whether the burst is a data sync burst
+func (p *DMRDPacket) carriesSlotType() bool {
+	return p.FrameType == FrameTypeVoiceTerminator || p.FrameType == FrameTypeDataSync
+}
+
+// carriesEMB reports whether the burst is voice burst B-F
+func (p *DMRDPacket) carriesEMB() bool {
+	return p.FrameType == FrameTypeVoice && p.DataType >= 1 && p.DataType <= 5
+}
+
+// decodeSlotType returns the 8-bit slot type value nearest the coded bits
+func decodeSlotType(payload []byte) uint8 {
+	got := readBits(payload, slotTypeBits[:])
+	var value uint8
+	best := 21
+	for v := 0; v < 256; v++ {
+		if d := bits.OnesCount32(got ^ golay2087Encode(uint8(v))); d < best {
+			best, value = d, uint8(v)
+		}
+	}
+	return value
+}
+
+// decodeEMB returns the 7-bit EMB value nearest the coded bits
+func decodeEMB(payload []byte) uint8 {
+	got := uint16(readBits(payload, embBits[:]))
+	var value uint8
+	best := 17
+	for v := 0; v < 128; v++ {
+		if d := bits.OnesCount16(got ^ qr1676Encode(uint8(v))); d < best {
+			best, value = d, uint8(v)
+		}
+	}
+	return value
+}
+
+// ColorCode decodes the color code from the slot type or EMB, taking the
+// nearest codeword. ok is false for bursts that carry neither.
+func (p *DMRDPacket) ColorCode() (cc uint8, ok bool) {
+	if len(p.Payload) < lcPayloadSize {
+		return 0, false
+	}
+	switch {
+	case p.carriesSlotType():
+		return decodeSlotType(p.Payload) >> 4, true
+	case p.carriesEMB():
+		return decodeEMB(p.Payload) >> 3, true
+	}
+	return 0, false
+}
+
+// SetColorCode rewrites the color code in the payload's slot type or EMB
+// with fresh parity, keeping the slot type's data type and the EMB's PI and
+// LCSS bits. It returns false for bursts without a color code, such as
+// voice sync bursts.
+func (p *DMRDPacket) SetColorCode(cc uint8) bool {
+	if len(p.Payload) < lcPayloadSize {
+		return false
+	}
+	cc &= 0x0f
+	switch {
+	case p.carriesSlotType():
+		value := cc<<4 | decodeSlotType(p.Payload)&0x0f
+		writeBits(p.Payload, slotTypeBits[:], golay2087Encode(value))
+		return true
+	case p.carriesEMB():
+		value := cc<<3 | decodeEMB(p.Payload)&0x07
+		writeBits(p.Payload, embBits[:], uint32(qr1676Encode(value)))
+		return true
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package protocol
+
+import (
+	"bytes"
+	"math/bits"
+	"testing"
+)
+
+func TestColorCodeCodes_MinimumDistance(t *testing.T) {
+	// Golay(20,8) corrects 3 bit errors and QR(16,7) 2
+	for a := 0; a < 256; a++ {
+		for b := a + 1; b < 256; b++ {
+			if d := bits.OnesCount32(golay2087Encode(uint8(a)) ^ golay2087Encode(uint8(b))); d < 7 {
+				t.Fatalf("Golay codewords %#x and %#x differ in %d bits", a, b, d)
+			}
+			if a < 128 && b < 128 {
+				if d := bits.OnesCount16(qr1676Encode(uint8(a)) ^ qr1676Encode(uint8(b))); d < 6 {
+					t.Fatalf("QR codewords %#x and %#x differ in %d bits", a, b, d)
+				}
+			}
+		}
+	}
+}
+
+func TestDMRDPacket_SetColorCode_SlotType(t *testing.T) {
+	p := &DMRDPacket{
+		FrameType: FrameTypeVoiceTerminator,
+		DataType:  DataTypeVoiceLCHeader,
+		Payload:   bytes.Repeat([]byte{0x5a}, 33),
+	}
+	// CC 1, voice LC header
+	writeBits(p.Payload, slotTypeBits[:], golay2087Encode(0x11))
+	orig := append([]byte(nil), p.Payload...)
+
+	if !p.SetColorCode(7) {
+		t.Fatal("SetColorCode returned false for a data sync burst")
+	}
+	if cc, ok := p.ColorCode(); !ok || cc != 7 {
+		t.Errorf("ColorCode = %d, %v; want 7, true", cc, ok)
+	}
+	if got := decodeSlotType(p.Payload); got != 0x71 {
+		t.Errorf("Slot type = %#x, want 0x71", got)
+	}
+	// Only the slot type bits change: LC info and sync are untouched
+	for i := range p.Payload {
+		diff := p.Payload[i] ^ orig[i]
+		for b := 0; b < 8; b++ {
+			bit := i*8 + b
+			inSlotType := (bit >= 98 && bit < 108) || (bit >= 156 && bit < 166)
+			if diff&(0x80>>b) != 0 && !inSlotType {
+				t.Fatalf("Bit %d outside the slot type changed", bit)
+			}
+		}
+	}
+
+	// A couple of bit errors still decode
+	p.Payload[12] ^= 0x01
+	p.Payload[20] ^= 0x40
+	if cc, _ := p.ColorCode(); cc != 7 {
+		t.Errorf("ColorCode after bit errors = %d, want 7", cc)
+	}
+}
+
+func TestDMRDPacket_SetColorCode_EMB(t *testing.T) {
+	p := &DMRDPacket{
+		FrameType: FrameTypeVoice,
+		DataType:  1, // Burst B
+		Payload:   bytes.Repeat([]byte{0xa5}, 33),
+	}
+	// CC 1, PI 0, LCSS 1 (first fragment)
+	writeBits(p.Payload, embBits[:], uint32(qr1676Encode(1<<3|1)))
+
+	if !p.SetColorCode(12) {
+		t.Fatal("SetColorCode returned false for a voice burst")
+	}
+	if got := decodeEMB(p.Payload); got != 12<<3|1 {
+		t.Errorf("EMB = %#x, want %#x", got, 12<<3|1)
+	}
+	// The embedded signalling between the EMB halves is untouched
+	if !bytes.Equal(p.Payload[15:18], []byte{0xa5, 0xa5, 0xa5}) {
+		t.Errorf("Embedded signalling modified: % x", p.Payload[15:18])
+	}
+}
+
+func TestDMRDPacket_SetColorCode_VoiceSync(t *testing.T) {
+	p := &DMRDPacket{
+		FrameType: FrameTypeVoiceHeader,
+		Payload:   bytes.Repeat([]byte{0x5a}, 33),
+	}
+	if p.SetColorCode(3) {
+		t.Error("SetColorCode returned true for a voice sync burst")
+	}
+	if !bytes.Equal(p.Payload, bytes.Repeat([]byte{0x5a}, 33)) {
+		t.Error("Voice sync burst modified")
+	}
+	if _, ok := p.ColorCode(); ok {
+		t.Error("ColorCode ok for a voice sync burst")
+	}
+}
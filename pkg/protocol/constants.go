@@ -13,6 +13,7 @@ const (
 	PacketTypeMSTPONG = "MSTPONG"
 	PacketTypeMSTNAK  = "MSTNAK"
 	PacketTypeMSTCL   = "MSTCL"
+	PacketTypeMSTRDR  = "MSTRDR" // dmr-nexus extension: reconnect to another master
 )
 
 // Packet size constants (in bytes)
@@ -30,6 +31,7 @@ const (
 	MSTPONGPacketSize        = 11  // Pong from master (MSTPONG + 4 byte repeater ID)
 	MSTNAKPacketSize         = 10  // Negative acknowledgement (MSTNAK + 4 byte repeater ID)
 	MSTCLPacketSize          = 9   // Close connection (MSTCL + 4 byte repeater ID)
+	MSTRDRMinPacketSize      = 10  // Redirect (MSTRDR + 4 byte repeater ID), followed by the "host:port" to use
 )
 
 // Slot byte (byte 15) bit masks - DMR slot information encoding
@@ -48,6 +50,35 @@ const (
 	FrameTypeDataSync        = 0x03 // Data synchronization
 )
 
+// Data types seen on terminator frames. Only the terminator with LC ends the
+// stream; any other data type on a terminator frame (a PI header, a voice LC
+// header or a CSBK) does not.
+const (
+	DataTypePIHeader      = 0x00 // Privacy indicator header, sent ahead of encrypted voice
+	DataTypeVoiceLCHeader = 0x01 // Voice LC header
+	DataTypeTerminatorLC  = 0x02 // Terminator with LC
+)
+
+// Data types of packet data bursts. Like terminators they are data sync
+// bursts, which HomeBrew sends as frame type 2 (FrameTypeVoiceTerminator).
+const (
+	DataTypeDataHeader = 0x06 // Data header opening a packet data call
+	DataTypeRate12Data = 0x07 // Rate 1/2 (BPTC) data block
+	DataTypeRate34Data = 0x08 // Rate 3/4 (trellis) data block
+)
+
+// Service option bits in the third byte of a full link control (LC)
+const (
+	ServiceOptionEmergency = 0x80 // Emergency call
+	ServiceOptionPrivacy   = 0x40 // Privacy: the voice payload is encrypted
+)
+
+// Full link control opcodes (FLCO, low 6 bits of the first LC byte)
+const (
+	FLCOGroupVoice = 0x00 // Group voice channel user
+	FLCOUnitToUnit = 0x03 // Unit to unit voice channel user
+)
+
 // DMRD packet field offsets
 const (
 	DMRDOffsetSignature = 0  // 4 bytes: "DMRD"
@@ -0,0 +1,160 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Packet data: a data header burst says how many blocks follow, and the
+// blocks carry the user data, then pad octets, then a CRC-32 over the
+// message. Data headers and rate 1/2 blocks use the same BPTC(196,96) coding
+// as full LC. Rate 3/4 (trellis) blocks are not decoded.
+
+// Data packet formats (DPF, low 4 bits of the first header byte)
+const (
+	DataFormatUnconfirmed = 0x02
+	DataFormatConfirmed   = 0x03
+)
+
+// SAPIPPacketData is the service access point of IPv4 packet data
+const SAPIPPacketData = 0x04
+
+const (
+	dataHeaderSize       = 12
+	confirmedBlockHeader = 2 // Serial number and CRC-9 ahead of each confirmed block's data
+	dataMessageCRCSize   = 4
+	dataHeaderCRCMask    = 0xCCCC
+)
+
+// DataHeader opens a packet data call
+type DataHeader struct {
+	Group             bool
+	ResponseRequested bool
+	Format            byte
+	SAP               byte
+	PadOctets         int
+	DestinationID     uint32
+	SourceID          uint32
+	BlocksToFollow    int
+}
+
+// ParseDataHeader decodes the data header carried by a data header burst
+func ParseDataHeader(payload []byte) (*DataHeader, error) {
+	b, err := ExtractDataBlock(payload)
+	if err != nil {
+		return nil, err
+	}
+	crc := ^crcCCITT(b[:10]) ^ dataHeaderCRCMask
+	if binary.BigEndian.Uint16(b[10:12]) != crc {
+		return nil, fmt.Errorf("data header CRC mismatch")
+	}
+
+	h := &DataHeader{
+		Group:             b[0]&0x80 != 0,
+		ResponseRequested: b[0]&0x40 != 0,
+		Format:            b[0] & 0x0F,
+		SAP:               b[1] >> 4,
+		DestinationID:     uint32(b[2])<<16 | uint32(b[3])<<8 | uint32(b[4]),
+		SourceID:          uint32(b[5])<<16 | uint32(b[6])<<8 | uint32(b[7]),
+	}
+	if h.Format != DataFormatUnconfirmed && h.Format != DataFormatConfirmed {
+		return nil, fmt.Errorf("unsupported data packet format 0x%X", h.Format)
+	}
+	h.PadOctets = int(b[0]&0x10) | int(b[1]&0x0F)
+	h.BlocksToFollow = int(b[8] & 0x7F)
+	return h, nil
+}
+
+// Encode returns the 12 header bytes, CRC included, ready for
+// EmbedDataBlock
+func (h *DataHeader) Encode() []byte {
+	b := make([]byte, dataHeaderSize)
+	if h.Group {
+		b[0] |= 0x80
+	}
+	if h.ResponseRequested {
+		b[0] |= 0x40
+	}
+	b[0] |= byte(h.PadOctets&0x10) | h.Format&0x0F
+	b[1] = h.SAP<<4 | byte(h.PadOctets&0x0F)
+	b[2], b[3], b[4] = byte(h.DestinationID>>16), byte(h.DestinationID>>8), byte(h.DestinationID)
+	b[5], b[6], b[7] = byte(h.SourceID>>16), byte(h.SourceID>>8), byte(h.SourceID)
+	b[8] = 0x80 | byte(h.BlocksToFollow&0x7F) // Full message
+	binary.BigEndian.PutUint16(b[10:12], ^crcCCITT(b[:10])^dataHeaderCRCMask)
+	return b
+}
+
+// ExtractDataBlock decodes the 12 bytes carried by a data header or rate
+// 1/2 data burst
+func ExtractDataBlock(payload []byte) ([]byte, error) {
+	return ExtractFullLC(payload)
+}
+
+// EmbedDataBlock encodes 12 bytes into a data header or rate 1/2 data burst
+func EmbedDataBlock(block []byte, payload []byte) error {
+	return EmbedFullLC(block, payload)
+}
+
+// AssembleData joins the decoded rate 1/2 blocks of a packet data call and
+// returns the user data, without the pad octets and message CRC. The
+// message CRC is not checked.
+func AssembleData(h *DataHeader, blocks [][]byte) ([]byte, error) {
+	if len(blocks) != h.BlocksToFollow {
+		return nil, fmt.Errorf("expected %d data blocks, got %d", h.BlocksToFollow, len(blocks))
+	}
+
+	var data []byte
+	for _, block := range blocks {
+		if len(block) != dataHeaderSize {
+			return nil, fmt.Errorf("data block must be %d bytes, got %d", dataHeaderSize, len(block))
+		}
+		if h.Format == DataFormatConfirmed {
+			block = block[confirmedBlockHeader:]
+		}
+		data = append(data, block...)
+	}
+
+	trailer := h.PadOctets + dataMessageCRCSize
+	if len(data) < trailer {
+		return nil, fmt.Errorf("data too short for %d pad octets and CRC", h.PadOctets)
+	}
+	return data[:len(data)-trailer], nil
+}
+
+// UDPPayload returns the destination port and payload of a UDP datagram in
+// an IPv4 packet
+func UDPPayload(packet []byte) (uint16, []byte, error) {
+	if len(packet) < 20 || packet[0]>>4 != 4 {
+		return 0, nil, fmt.Errorf("not an IPv4 packet")
+	}
+	if packet[9] != 17 {
+		return 0, nil, fmt.Errorf("IP protocol %d is not UDP", packet[9])
+	}
+	ihl := int(packet[0]&0x0F) * 4
+	if ihl < 20 || len(packet) < ihl+8 {
+		return 0, nil, fmt.Errorf("truncated UDP datagram")
+	}
+
+	udp := packet[ihl:]
+	length := int(binary.BigEndian.Uint16(udp[4:6]))
+	if length < 8 || length > len(udp) {
+		return 0, nil, fmt.Errorf("invalid UDP length %d", length)
+	}
+	return binary.BigEndian.Uint16(udp[2:4]), udp[8:length], nil
+}
+
+// crcCCITT computes CRC-16-CCITT (polynomial 0x1021, initial value 0)
+func crcCCITT(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,91 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestCRCCCITT_CheckValue(t *testing.T) {
+	if got := crcCCITT([]byte("123456789")); got != 0x31C3 {
+		t.Errorf("crcCCITT = 0x%04X, want 0x31C3", got)
+	}
+}
+
+func TestDataHeader_RoundTrip(t *testing.T) {
+	want := &DataHeader{
+		Format:         DataFormatUnconfirmed,
+		SAP:            SAPIPPacketData,
+		PadOctets:      17,
+		DestinationID:  3100,
+		SourceID:       3120001,
+		BlocksToFollow: 3,
+	}
+	payload := make([]byte, 33)
+	if err := EmbedDataBlock(want.Encode(), payload); err != nil {
+		t.Fatalf("EmbedDataBlock error: %v", err)
+	}
+
+	got, err := ParseDataHeader(payload)
+	if err != nil {
+		t.Fatalf("ParseDataHeader error: %v", err)
+	}
+	if *got != *want {
+		t.Errorf("ParseDataHeader = %+v, want %+v", got, want)
+	}
+
+	// A corrupted CRC is rejected
+	header := want.Encode()
+	header[11] ^= 0x01
+	if err := EmbedDataBlock(header, payload); err != nil {
+		t.Fatalf("EmbedDataBlock error: %v", err)
+	}
+	if _, err := ParseDataHeader(payload); err == nil {
+		t.Error("Expected CRC mismatch")
+	}
+}
+
+func TestAssembleData(t *testing.T) {
+	message := []byte("position report")
+	pad := 2*dataHeaderSize - len(message) - dataMessageCRCSize
+
+	raw := append(append([]byte{}, message...), make([]byte, pad+dataMessageCRCSize)...)
+	blocks := [][]byte{raw[:12], raw[12:]}
+	h := &DataHeader{Format: DataFormatUnconfirmed, PadOctets: pad, BlocksToFollow: 2}
+
+	data, err := AssembleData(h, blocks)
+	if err != nil {
+		t.Fatalf("AssembleData error: %v", err)
+	}
+	if !bytes.Equal(data, message) {
+		t.Errorf("AssembleData = %q, want %q", data, message)
+	}
+
+	if _, err := AssembleData(h, blocks[:1]); err == nil {
+		t.Error("Expected error for a missing block")
+	}
+}
+
+func TestUDPPayload(t *testing.T) {
+	datagram := []byte{0x0f, 0xa1, 0x0f, 0xa1, 0x00, 0x0b, 0x00, 0x00, 'a', 'b', 'c'}
+	packet := append([]byte{0x45, 0, 0, byte(20 + len(datagram)), 0, 0, 0, 0, 64, 17, 0, 0,
+		12, 0, 0, 1, 13, 0, 0, 1}, datagram...)
+
+	port, payload, err := UDPPayload(packet)
+	if err != nil {
+		t.Fatalf("UDPPayload error: %v", err)
+	}
+	if port != LRRPPort || string(payload) != "abc" {
+		t.Errorf("UDPPayload = %d %q, want %d \"abc\"", port, payload, LRRPPort)
+	}
+
+	tcp := append([]byte{}, packet...)
+	tcp[9] = 6
+	if _, _, err := UDPPayload(tcp); err == nil {
+		t.Error("Expected error for a TCP packet")
+	}
+	binary.BigEndian.PutUint16(packet[24:26], 200)
+	if _, _, err := UDPPayload(packet); err == nil {
+		t.Error("Expected error for an overlong UDP length")
+	}
+}
@@ -160,6 +160,13 @@ func (p *DMRDPacket) Encode() ([]byte, error) {
 	return data, nil
 }
 
+// IsTerminator reports whether the packet ends its stream: a terminator
+// with LC. Data type zero on the same frame type is a PI header, which
+// opens an encrypted call rather than ending one.
+func (p *DMRDPacket) IsTerminator() bool {
+	return p.FrameType == FrameTypeVoiceTerminator && p.DataType == DataTypeTerminatorLC
+}
+
 // ParseDMRD parses a DMRD packet from raw bytes
 func ParseDMRD(data []byte) (*DMRDPacket, error) {
 	p := &DMRDPacket{}
@@ -252,3 +252,35 @@ func TestDMRDPacket_CallType(t *testing.T) {
 		})
 	}
 }
+
+func TestDMRDPacket_IsTerminator(t *testing.T) {
+	tests := []struct {
+		name      string
+		frameType byte
+		dataType  byte
+		want      bool
+	}{
+		{"Terminator with LC", FrameTypeVoiceTerminator, DataTypeTerminatorLC, true},
+		{"PI header", FrameTypeVoiceTerminator, DataTypePIHeader, false},
+		{"Voice LC header in data sync", FrameTypeVoiceTerminator, 0x01, false},
+		{"CSBK in data sync", FrameTypeVoiceTerminator, 0x03, false},
+		{"Voice burst C", FrameTypeVoice, 0x02, false},
+		{"Voice header", FrameTypeVoiceHeader, 0x00, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := make([]byte, DMRDPacketSize)
+			copy(data[0:4], []byte("DMRD"))
+			data[15] = tt.frameType<<4 | tt.dataType
+
+			packet, err := ParseDMRD(data)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if got := packet.IsTerminator(); got != tt.want {
+				t.Errorf("IsTerminator() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
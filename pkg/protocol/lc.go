@@ -0,0 +1,252 @@
+package protocol
+
+import "fmt"
+
+// BPTC(196,96) protects the full link control carried in voice LC headers
+// and terminators. The 196 coded bits are the two 98-bit info halves of the
+// 33-byte burst, either side of the slot type and sync. Deinterleaved they
+// form a 13x15 matrix whose rows are Hamming(15,11,3) and columns
+// Hamming(13,9,3) codewords; bit 0 is reserved.
+
+const (
+	bptcBits      = 196
+	bptcDataBytes = 12 // 9 bytes of LC plus 3 bytes of Reed-Solomon parity
+	lcPayloadSize = 33
+)
+
+// bptcDataRanges lists the deinterleaved positions holding data bits
+var bptcDataRanges = [][2]int{
+	{4, 11}, {16, 26}, {31, 41}, {46, 56}, {61, 71},
+	{76, 86}, {91, 101}, {106, 116}, {121, 131},
+}
+
+// ExtractFullLC decodes the 12 bytes of full LC (with its Reed-Solomon
+// parity) from a voice LC header or terminator payload, correcting single
+// bit errors per row and column. The Reed-Solomon parity is not checked.
+func ExtractFullLC(payload []byte) ([]byte, error) {
+	if len(payload) < lcPayloadSize {
+		return nil, fmt.Errorf("LC payload too short: %d bytes", len(payload))
+	}
+
+	raw := bptcRawBits(payload)
+	var m [bptcBits]bool
+	for a := 0; a < bptcBits; a++ {
+		m[a] = raw[(a*181)%bptcBits]
+	}
+	bptcCorrect(&m)
+
+	lc := make([]byte, bptcDataBytes)
+	pos := 0
+	for _, r := range bptcDataRanges {
+		for a := r[0]; a <= r[1]; a++ {
+			if m[a] {
+				lc[pos/8] |= 0x80 >> (pos % 8)
+			}
+			pos++
+		}
+	}
+	return lc, nil
+}
+
+// EmbedFullLC encodes 12 bytes of full LC (with its Reed-Solomon parity)
+// into the info bits of a 33-byte voice LC header or terminator payload,
+// leaving the slot type and sync bits in the middle untouched
+func EmbedFullLC(lc []byte, payload []byte) error {
+	if len(lc) != bptcDataBytes {
+		return fmt.Errorf("LC must be %d bytes, got %d", bptcDataBytes, len(lc))
+	}
+	if len(payload) < lcPayloadSize {
+		return fmt.Errorf("LC payload too short: %d bytes", len(payload))
+	}
+
+	var m [bptcBits]bool
+	pos := 0
+	for _, r := range bptcDataRanges {
+		for a := r[0]; a <= r[1]; a++ {
+			m[a] = lc[pos/8]&(0x80>>(pos%8)) != 0
+			pos++
+		}
+	}
+	for r := 0; r < 9; r++ {
+		row := m[r*15+1 : r*15+16]
+		setParity(row, hamming15113)
+	}
+	for c := 0; c < 15; c++ {
+		var col [13]bool
+		for a := 0; a < 13; a++ {
+			col[a] = m[c+1+a*15]
+		}
+		setParity(col[:], hamming1393)
+		for a := 0; a < 13; a++ {
+			m[c+1+a*15] = col[a]
+		}
+	}
+
+	var raw [bptcBits]bool
+	for a := 0; a < bptcBits; a++ {
+		raw[(a*181)%bptcBits] = m[a]
+	}
+	for i := 0; i < bptcBits; i++ {
+		bit := i
+		if i >= 98 {
+			bit = i + 68 // skip slot type and sync
+		}
+		mask := byte(0x80 >> (bit % 8))
+		if raw[i] {
+			payload[bit/8] |= mask
+		} else {
+			payload[bit/8] &^= mask
+		}
+	}
+	return nil
+}
+
+// CarriesFullLC reports whether the packet's payload is a BPTC-coded full
+// LC: a voice LC header or a terminator with LC
+func (p *DMRDPacket) CarriesFullLC() bool {
+	return p.FrameType == FrameTypeVoiceTerminator &&
+		(p.DataType == DataTypeVoiceLCHeader || p.DataType == DataTypeTerminatorLC)
+}
+
+// IsEncrypted reports whether the packet's full LC has the privacy service
+// option set. Only voice LC headers and terminators carry it.
+func (p *DMRDPacket) IsEncrypted() bool {
+	if !p.CarriesFullLC() {
+		return false
+	}
+	lc, err := ExtractFullLC(p.Payload)
+	if err != nil {
+		return false
+	}
+	return lc[2]&ServiceOptionPrivacy != 0
+}
+
+// bptcRawBits pulls the 196 coded bits out of a burst
+func bptcRawBits(payload []byte) [bptcBits]bool {
+	var raw [bptcBits]bool
+	for i := 0; i < bptcBits; i++ {
+		bit := i
+		if i >= 98 {
+			bit = i + 68
+		}
+		raw[i] = payload[bit/8]&(0x80>>(bit%8)) != 0
+	}
+	return raw
+}
+
+// Parity equations: each lists the data bit positions XORed into one
+// parity bit, which follows the data bits in the codeword
+var (
+	hamming15113 = [][]int{
+		{0, 1, 2, 3, 5, 7, 8},
+		{1, 2, 3, 4, 6, 8, 9},
+		{2, 3, 4, 5, 7, 9, 10},
+		{0, 1, 2, 4, 6, 7, 10},
+	}
+	hamming1393 = [][]int{
+		{0, 1, 3, 5, 6},
+		{0, 1, 2, 4, 6, 7},
+		{0, 1, 2, 3, 5, 7, 8},
+		{0, 2, 4, 5, 8},
+	}
+)
+
+// syndrome returns the failed parity checks of a codeword as a bit mask
+func syndrome(cw []bool, eqs [][]int) int {
+	dataBits := len(cw) - len(eqs)
+	s := 0
+	for i, eq := range eqs {
+		p := false
+		for _, d := range eq {
+			p = p != cw[d]
+		}
+		if p != cw[dataBits+i] {
+			s |= 1 << i
+		}
+	}
+	return s
+}
+
+// setParity fills in a codeword's parity bits
+func setParity(cw []bool, eqs [][]int) {
+	dataBits := len(cw) - len(eqs)
+	for i, eq := range eqs {
+		p := false
+		for _, d := range eq {
+			p = p != cw[d]
+		}
+		cw[dataBits+i] = p
+	}
+}
+
+// correctSingle flips the one bit whose error explains the codeword's
+// syndrome, if there is one
+func correctSingle(cw []bool, eqs [][]int) {
+	for i := range cw {
+		cw[i] = !cw[i]
+		if syndrome(cw, eqs) == 0 {
+			return
+		}
+		cw[i] = !cw[i]
+	}
+}
+
+// bptcCorrect repairs single bit errors in the deinterleaved matrix,
+// alternating columns and rows a few times as the reference decoders do
+func bptcCorrect(m *[bptcBits]bool) {
+	for pass := 0; pass < 5; pass++ {
+		changed := false
+		for c := 0; c < 15; c++ {
+			var col [13]bool
+			for a := 0; a < 13; a++ {
+				col[a] = m[c+1+a*15]
+			}
+			if syndrome(col[:], hamming1393) == 0 {
+				continue
+			}
+			changed = true
+			correctSingle(col[:], hamming1393)
+			for a := 0; a < 13; a++ {
+				m[c+1+a*15] = col[a]
+			}
+		}
+		for r := 0; r < 9; r++ {
+			row := m[r*15+1 : r*15+16]
+			if syndrome(row, hamming15113) != 0 {
+				changed = true
+				correctSingle(row, hamming15113)
+			}
+		}
+		if !changed {
+			return
+		}
+	}
+}
+
+// ConvertToGroupCall turns a private call frame into a group call to tgid.
+// Voice LC headers and terminators get their full LC rewritten to match,
+// with fresh Reed-Solomon parity. The embedded LC in voice bursts B-E is
+// left alone; receivers that heard the header follow it.
+func (p *DMRDPacket) ConvertToGroupCall(tgid uint32) error {
+	p.CallType = CallTypeGroup
+	p.DestinationID = tgid
+	if !p.CarriesFullLC() {
+		return nil
+	}
+
+	lc, err := ExtractFullLC(p.Payload)
+	if err != nil {
+		return err
+	}
+	lc[0] = lc[0]&0xc0 | FLCOGroupVoice
+	lc[3] = byte(tgid >> 16)
+	lc[4] = byte(tgid >> 8)
+	lc[5] = byte(tgid)
+
+	mask := byte(lcCRCMaskTerminator)
+	if p.DataType == DataTypeVoiceLCHeader {
+		mask = lcCRCMaskVoiceHeader
+	}
+	setFullLCParity(lc, mask)
+	return EmbedFullLC(lc, p.Payload)
+}
@@ -0,0 +1,118 @@
+package protocol
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFullLC_RoundTrip(t *testing.T) {
+	// Group voice, FID 0, privacy set, TG 3100 from 3120001, zeroed parity
+	lc := []byte{0x00, 0x00, ServiceOptionPrivacy, 0x00, 0x0c, 0x1c, 0x2f, 0xa1, 0x81, 0x00, 0x00, 0x00}
+
+	payload := bytes.Repeat([]byte{0x5a}, 33)
+	if err := EmbedFullLC(lc, payload); err != nil {
+		t.Fatalf("EmbedFullLC error: %v", err)
+	}
+	// Slot type and sync bits between the info halves are left alone
+	if !bytes.Equal(payload[13:20], bytes.Repeat([]byte{0x5a}, 7)) {
+		t.Errorf("Sync bytes modified: % x", payload[13:20])
+	}
+
+	got, err := ExtractFullLC(payload)
+	if err != nil {
+		t.Fatalf("ExtractFullLC error: %v", err)
+	}
+	if !bytes.Equal(got, lc) {
+		t.Errorf("ExtractFullLC = % x, want % x", got, lc)
+	}
+
+	// A single flipped bit in the coded info is corrected
+	payload[3] ^= 0x10
+	got, err = ExtractFullLC(payload)
+	if err != nil {
+		t.Fatalf("ExtractFullLC error: %v", err)
+	}
+	if !bytes.Equal(got, lc) {
+		t.Errorf("ExtractFullLC after bit error = % x, want % x", got, lc)
+	}
+}
+
+func TestDMRDPacket_IsEncrypted(t *testing.T) {
+	header := func(serviceOptions byte) *DMRDPacket {
+		payload := make([]byte, 33)
+		lc := make([]byte, 12)
+		lc[2] = serviceOptions
+		if err := EmbedFullLC(lc, payload); err != nil {
+			t.Fatalf("EmbedFullLC error: %v", err)
+		}
+		return &DMRDPacket{FrameType: FrameTypeVoiceTerminator, DataType: DataTypeVoiceLCHeader, Payload: payload}
+	}
+
+	if !header(ServiceOptionPrivacy).IsEncrypted() {
+		t.Error("Header with privacy option should be encrypted")
+	}
+	if header(ServiceOptionEmergency).IsEncrypted() {
+		t.Error("Header without privacy option should not be encrypted")
+	}
+
+	voice := header(ServiceOptionPrivacy)
+	voice.FrameType = FrameTypeVoice
+	if voice.IsEncrypted() {
+		t.Error("Voice bursts carry no full LC")
+	}
+}
+
+func TestSetFullLCParity_IsReedSolomonCodeword(t *testing.T) {
+	lc := []byte{0x00, 0x00, 0x00, 0x00, 0x0c, 0x1c, 0x2f, 0xa1, 0x81, 0, 0, 0}
+	setFullLCParity(lc, lcCRCMaskVoiceHeader)
+
+	// With the mask removed the 12 bytes evaluate to zero at the generator's roots
+	cw := append([]byte{}, lc...)
+	for i := 9; i < 12; i++ {
+		cw[i] ^= lcCRCMaskVoiceHeader
+	}
+	for _, root := range []byte{2, 4, 8} {
+		var s byte
+		for _, b := range cw {
+			s = gfMul(s, root) ^ b
+		}
+		if s != 0 {
+			t.Errorf("Syndrome at %d = %#x, want 0 (lc % x)", root, s, lc)
+		}
+	}
+}
+
+func TestDMRDPacket_ConvertToGroupCall(t *testing.T) {
+	// Unit to unit call from 3120001 to 3120002
+	lc := []byte{FLCOUnitToUnit, 0x00, 0x00, 0x2f, 0xa1, 0x82, 0x2f, 0xa1, 0x81, 0, 0, 0}
+	setFullLCParity(lc, lcCRCMaskVoiceHeader)
+	payload := make([]byte, 33)
+	if err := EmbedFullLC(lc, payload); err != nil {
+		t.Fatalf("EmbedFullLC error: %v", err)
+	}
+	p := &DMRDPacket{
+		SourceID:      3120001,
+		DestinationID: 3120002,
+		CallType:      CallTypePrivate,
+		FrameType:     FrameTypeVoiceTerminator,
+		DataType:      DataTypeVoiceLCHeader,
+		Payload:       payload,
+	}
+
+	if err := p.ConvertToGroupCall(9); err != nil {
+		t.Fatalf("ConvertToGroupCall error: %v", err)
+	}
+	if p.CallType != CallTypeGroup || p.DestinationID != 9 {
+		t.Errorf("Expected group call to 9, got call type %d dst %d", p.CallType, p.DestinationID)
+	}
+
+	got, err := ExtractFullLC(p.Payload)
+	if err != nil {
+		t.Fatalf("ExtractFullLC error: %v", err)
+	}
+	want := []byte{FLCOGroupVoice, 0x00, 0x00, 0x00, 0x00, 0x09, 0x2f, 0xa1, 0x81, 0, 0, 0}
+	setFullLCParity(want, lcCRCMaskVoiceHeader)
+	if !bytes.Equal(got, want) {
+		t.Errorf("LC = % x, want % x", got, want)
+	}
+}
@@ -0,0 +1,117 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// LRRP (Location Request/Response Protocol) carries radio positions as UDP
+// datagrams to port 4001. A message is a type byte, a length byte and a run
+// of tokens, each a token byte followed by its fields.
+
+// LRRPPort is the UDP port LRRP reports are sent to
+const LRRPPort = 4001
+
+// LRRP message types that carry a position
+const (
+	lrrpImmediateLocationResponse = 0x07
+	lrrpTriggeredLocationData     = 0x0D
+)
+
+// LRRP tokens
+const (
+	lrrpTokenRequestID = 0x22 // Length byte, then the ID
+	lrrpTokenTimestamp = 0x34 // 40 bits: year, month, day, hour, minute, second
+	lrrpTokenResult    = 0x37 // 1-byte result code
+	lrrpTokenResult16  = 0x38 // 2-byte result code
+	lrrpTokenCircle2D  = 0x51 // Latitude, longitude, radius
+	lrrpTokenCircle3D  = 0x54 // Latitude, longitude, radius, altitude
+	lrrpTokenPoint2D   = 0x66 // Latitude, longitude
+	lrrpTokenPoint3D   = 0x69 // Latitude, longitude, altitude
+)
+
+// LRRPReport is a position decoded from an LRRP location response
+type LRRPReport struct {
+	RequestID []byte
+	Latitude  float64
+	Longitude float64
+	Time      time.Time // Zero if the report carries no timestamp
+}
+
+// ParseLRRP decodes an immediate location response or triggered location
+// data message. Tokens after the position are not decoded.
+func ParseLRRP(data []byte) (*LRRPReport, error) {
+	if len(data) < 2 {
+		return nil, fmt.Errorf("LRRP message too short: %d bytes", len(data))
+	}
+	if data[0] != lrrpImmediateLocationResponse && data[0] != lrrpTriggeredLocationData {
+		return nil, fmt.Errorf("LRRP message type 0x%02X carries no position", data[0])
+	}
+	length := int(data[1])
+	if len(data) < 2+length {
+		return nil, fmt.Errorf("LRRP message truncated: want %d bytes, have %d", length, len(data)-2)
+	}
+	body := data[2 : 2+length]
+
+	report := &LRRPReport{}
+	for i := 0; i < len(body); {
+		token := body[i]
+		i++
+		switch token {
+		case lrrpTokenRequestID:
+			if i >= len(body) || i+1+int(body[i]) > len(body) {
+				return nil, fmt.Errorf("LRRP request ID truncated")
+			}
+			n := int(body[i])
+			report.RequestID = append([]byte(nil), body[i+1:i+1+n]...)
+			i += 1 + n
+		case lrrpTokenTimestamp:
+			if i+5 > len(body) {
+				return nil, fmt.Errorf("LRRP timestamp truncated")
+			}
+			report.Time = lrrpTime(body[i : i+5])
+			i += 5
+		case lrrpTokenResult:
+			if i >= len(body) {
+				return nil, fmt.Errorf("LRRP result truncated")
+			}
+			if body[i] != 0 {
+				return nil, fmt.Errorf("LRRP result code 0x%02X", body[i])
+			}
+			i++
+		case lrrpTokenResult16:
+			if i+2 > len(body) {
+				return nil, fmt.Errorf("LRRP result truncated")
+			}
+			if code := binary.BigEndian.Uint16(body[i : i+2]); code != 0 {
+				return nil, fmt.Errorf("LRRP result code 0x%04X", code)
+			}
+			i += 2
+		case lrrpTokenCircle2D, lrrpTokenCircle3D, lrrpTokenPoint2D, lrrpTokenPoint3D:
+			if i+8 > len(body) {
+				return nil, fmt.Errorf("LRRP position truncated")
+			}
+			report.Latitude = float64(int32(binary.BigEndian.Uint32(body[i:i+4]))) * 90 / (1 << 31)
+			report.Longitude = float64(int32(binary.BigEndian.Uint32(body[i+4:i+8]))) * 180 / (1 << 31)
+			return report, nil
+		default:
+			return nil, fmt.Errorf("LRRP token 0x%02X before any position", token)
+		}
+	}
+	return nil, fmt.Errorf("LRRP message carries no position")
+}
+
+// lrrpTime decodes a 40-bit LRRP timestamp: 14 bits of year, then month,
+// day, hour, minute and second
+func lrrpTime(b []byte) time.Time {
+	v := uint64(b[0])<<32 | uint64(b[1])<<24 | uint64(b[2])<<16 | uint64(b[3])<<8 | uint64(b[4])
+	return time.Date(
+		int(v>>26),
+		time.Month(v>>22&0x0F),
+		int(v>>17&0x1F),
+		int(v>>12&0x1F),
+		int(v>>6&0x3F),
+		int(v&0x3F),
+		0, time.UTC)
+}
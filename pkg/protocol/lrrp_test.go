@@ -0,0 +1,55 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+// lrrpSample is a triggered location data report: request ID 00 00 01,
+// timestamp 2026-10-17 12:34:56 UTC, point-2d at 42.33, -83.05
+func lrrpSample() []byte {
+	ts := uint64(2026)<<26 | 10<<22 | 17<<17 | 12<<12 | 34<<6 | 56
+	body := []byte{lrrpTokenRequestID, 0x03, 0x00, 0x00, 0x01, lrrpTokenTimestamp,
+		byte(ts >> 32), byte(ts >> 24), byte(ts >> 16), byte(ts >> 8), byte(ts)}
+	lat, lon := 42.33*(1<<31)/90, -83.05*(1<<31)/180
+	body = append(body, lrrpTokenPoint2D)
+	body = binary.BigEndian.AppendUint32(body, uint32(int32(math.Round(lat))))
+	body = binary.BigEndian.AppendUint32(body, uint32(int32(math.Round(lon))))
+	return append([]byte{lrrpTriggeredLocationData, byte(len(body))}, body...)
+}
+
+func TestParseLRRP(t *testing.T) {
+	report, err := ParseLRRP(lrrpSample())
+	if err != nil {
+		t.Fatalf("ParseLRRP error: %v", err)
+	}
+	if math.Abs(report.Latitude-42.33) > 1e-6 || math.Abs(report.Longitude+83.05) > 1e-6 {
+		t.Errorf("Position = %f, %f; want 42.33, -83.05", report.Latitude, report.Longitude)
+	}
+	if want := time.Date(2026, 10, 17, 12, 34, 56, 0, time.UTC); !report.Time.Equal(want) {
+		t.Errorf("Time = %v, want %v", report.Time, want)
+	}
+	if !bytes.Equal(report.RequestID, []byte{0x00, 0x00, 0x01}) {
+		t.Errorf("RequestID = % x", report.RequestID)
+	}
+}
+
+func TestParseLRRP_Errors(t *testing.T) {
+	sample := lrrpSample()
+	failed := append([]byte{lrrpImmediateLocationResponse, 0x02}, lrrpTokenResult, 0x10)
+
+	for name, data := range map[string][]byte{
+		"too short":       {lrrpTriggeredLocationData},
+		"request message": append([]byte{0x05}, sample[1:]...),
+		"truncated":       sample[:len(sample)-3],
+		"result code":     failed,
+		"no position":     {lrrpTriggeredLocationData, 0x00},
+	} {
+		if _, err := ParseLRRP(data); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package protocol
+
+// Reed-Solomon(12,9) over GF(2^8) protects the 9 bytes of a full LC with 3
+// parity bytes. The generator polynomial has roots alpha^1..alpha^3:
+// x^3 + 14x^2 + 56x + 64. The parity is XORed with a mask that says what
+// the LC is carried in.
+
+const (
+	lcCRCMaskVoiceHeader = 0x96
+	lcCRCMaskTerminator  = 0x99
+)
+
+// rs129Generator holds the generator coefficients, constant term first
+var rs129Generator = [3]byte{64, 56, 14}
+
+// gfMul multiplies in GF(2^8) with the primitive polynomial x^8+x^4+x^3+x^2+1
+func gfMul(a, b byte) byte {
+	var p byte
+	for b != 0 {
+		if b&1 != 0 {
+			p ^= a
+		}
+		carry := a & 0x80
+		a <<= 1
+		if carry != 0 {
+			a ^= 0x1d
+		}
+		b >>= 1
+	}
+	return p
+}
+
+// setFullLCParity fills in lc[9:12] from lc[0:9] with the mask for the
+// burst type (voice LC header or terminator)
+func setFullLCParity(lc []byte, mask byte) {
+	var reg [3]byte // reg[i] is the coefficient of x^i of the remainder
+	for _, b := range lc[:9] {
+		feedback := b ^ reg[2]
+		reg[2] = reg[1] ^ gfMul(rs129Generator[2], feedback)
+		reg[1] = reg[0] ^ gfMul(rs129Generator[1], feedback)
+		reg[0] = gfMul(rs129Generator[0], feedback)
+	}
+	lc[9] = reg[2] ^ mask
+	lc[10] = reg[1] ^ mask
+	lc[11] = reg[0] ^ mask
+}
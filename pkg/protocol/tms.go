@@ -0,0 +1,71 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// TMS (Text Messaging Service) carries radio text messages as UDP datagrams
+// to port 4007. A message is a 2-byte length, a header byte (bit 7: header
+// extensions follow, low 5 bits: PDU type), an address length byte and
+// address, the header extensions (bit 7 of each: another follows) and the
+// text in UTF-16LE.
+
+// TMSPort is the UDP port text messages are sent to
+const TMSPort = 4007
+
+const (
+	tmsPDUTypeMask  = 0x1F
+	tmsPDUText      = 0x00
+	tmsExtendedFlag = 0x80
+)
+
+// ParseTMS decodes the text of a TMS text message. Acknowledgements and
+// other PDU types are rejected.
+func ParseTMS(data []byte) (string, error) {
+	if len(data) < 4 {
+		return "", fmt.Errorf("TMS message too short: %d bytes", len(data))
+	}
+	length := int(binary.BigEndian.Uint16(data[0:2]))
+	if len(data) < 2+length {
+		return "", fmt.Errorf("TMS message truncated: want %d bytes, have %d", length, len(data)-2)
+	}
+	body := data[2 : 2+length]
+	if len(body) < 2 {
+		return "", fmt.Errorf("TMS message too short: %d bytes", len(body))
+	}
+
+	header := body[0]
+	if header&tmsPDUTypeMask != tmsPDUText {
+		return "", fmt.Errorf("TMS PDU type 0x%02X is not a text message", header&tmsPDUTypeMask)
+	}
+	i := 2 + int(body[1]) // Skip the address
+	for extended := header&tmsExtendedFlag != 0; extended; i++ {
+		if i >= len(body) {
+			return "", fmt.Errorf("TMS header truncated")
+		}
+		extended = body[i]&tmsExtendedFlag != 0
+	}
+	if i > len(body) {
+		return "", fmt.Errorf("TMS address truncated")
+	}
+
+	text := body[i:]
+	units := make([]uint16, len(text)/2)
+	for j := range units {
+		units[j] = binary.LittleEndian.Uint16(text[2*j:])
+	}
+	return strings.TrimLeft(string(utf16.Decode(units)), "\r\n"), nil
+}
+
+// EncodeTMS builds a TMS text message with no address and the usual
+// sequence number and encoding header extensions
+func EncodeTMS(text string, seq byte) []byte {
+	body := []byte{tmsExtendedFlag | tmsPDUText, 0x00, tmsExtendedFlag | seq&0x1F, 0x04}
+	for _, u := range utf16.Encode([]rune("\r\n" + text)) {
+		body = binary.LittleEndian.AppendUint16(body, u)
+	}
+	return append(binary.BigEndian.AppendUint16(nil, uint16(len(body))), body...)
+}
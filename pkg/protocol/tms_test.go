@@ -0,0 +1,27 @@
+package protocol
+
+import "testing"
+
+func TestParseTMS(t *testing.T) {
+	// Captured from a radio: ack requested, sequence 0, "\r\nHi"
+	captured := []byte{0x00, 0x0C, 0xC0, 0x00, 0x80, 0x04, 0x0D, 0x00, 0x0A, 0x00, 0x48, 0x00, 0x69, 0x00}
+	if text, err := ParseTMS(captured); err != nil || text != "Hi" {
+		t.Errorf("ParseTMS(captured) = %q, %v; want \"Hi\"", text, err)
+	}
+
+	if text, err := ParseTMS(EncodeTMS("net check-in ✓", 3)); err != nil || text != "net check-in ✓" {
+		t.Errorf("ParseTMS(EncodeTMS) = %q, %v", text, err)
+	}
+
+	for name, data := range map[string][]byte{
+		"short":     {0x00, 0x01},
+		"truncated": {0x00, 0x10, 0xC0, 0x00},
+		"ack":       {0x00, 0x03, 0x9F, 0x00, 0x01},
+		"header":    {0x00, 0x03, 0x80, 0x00, 0x80},
+		"address":   {0x00, 0x03, 0x00, 0x05, 0x01},
+	} {
+		if _, err := ParseTMS(data); err == nil {
+			t.Errorf("ParseTMS(%s) expected error", name)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/dbehnke/dmr-nexus/pkg/database"
@@ -28,6 +29,9 @@ type Syncer struct {
 	repo   *database.DMRUserRepository
 	logger *logger.Logger
 	client *http.Client
+
+	onSyncMu sync.Mutex
+	onSync   []func()
 }
 
 // NewSyncer creates a new RadioID syncer
@@ -41,6 +45,14 @@ func NewSyncer(repo *database.DMRUserRepository, log *logger.Logger) *Syncer {
 	}
 }
 
+// OnSync registers fn to run after each successful sync, e.g. to drop caches
+// of the old user data. It may be called while the syncer is running.
+func (s *Syncer) OnSync(fn func()) {
+	s.onSyncMu.Lock()
+	defer s.onSyncMu.Unlock()
+	s.onSync = append(s.onSync, fn)
+}
+
 // Start begins the periodic sync process
 func (s *Syncer) Start(ctx context.Context) {
 	// Sync immediately on startup
@@ -105,6 +117,13 @@ func (s *Syncer) Sync(ctx context.Context) error {
 		return fmt.Errorf("failed to save users: %w", err)
 	}
 
+	s.onSyncMu.Lock()
+	hooks := append([]func(){}, s.onSync...)
+	s.onSyncMu.Unlock()
+	for _, fn := range hooks {
+		fn()
+	}
+
 	// Get final count
 	count, _ := s.repo.Count()
 
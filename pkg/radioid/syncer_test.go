@@ -2,7 +2,10 @@ package radioid
 
 import (
 	"context"
+	"io"
+	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -174,3 +177,36 @@ func TestSyncer_Start_Cancellation(t *testing.T) {
 	syncer.Start(ctx)
 	// If we get here without hanging, the test passes
 }
+
+// csvTransport serves a fixed CSV body for any request
+type csvTransport string
+
+func (c csvTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(string(c))),
+		Request:    req,
+	}, nil
+}
+
+func TestSyncer_Sync_RunsOnSyncHooks(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	db, err := database.NewDB(database.Config{Path: filepath.Join(t.TempDir(), "users.db")}, log)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	syncer := NewSyncer(database.NewDMRUserRepository(db.GetDB()), log)
+	syncer.client = &http.Client{Transport: csvTransport("RADIO_ID,CALLSIGN,FIRST_NAME,LAST_NAME,CITY,STATE,COUNTRY\n3120001,W1ABC,John,Doe,Boston,MA,United States\n")}
+
+	calls := 0
+	syncer.OnSync(func() { calls++ })
+
+	if err := syncer.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected the hook to run once, ran %d times", calls)
+	}
+}
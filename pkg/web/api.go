@@ -2,15 +2,21 @@ package web
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/config"
 	"github.com/dbehnke/dmr-nexus/pkg/database"
 	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/metrics"
+	"github.com/dbehnke/dmr-nexus/pkg/network"
 	"github.com/dbehnke/dmr-nexus/pkg/peer"
+	"github.com/dbehnke/dmr-nexus/pkg/privacy"
+	"gorm.io/gorm"
 )
 
 // API handles REST API endpoints
@@ -20,6 +26,43 @@ type API struct {
 	router   *bridge.Router
 	txRepo   *database.TransmissionRepository
 	userRepo *database.DMRUserRepository
+	metrics  *metrics.Collector
+
+	// Read-through cache of user lookups; nil disables it
+	users *userCache
+
+	// Radio position reports (LRRP); nil disables /api/positions
+	positionRepo *database.PositionRepository
+
+	// Radio text messages (TMS); nil disables /api/messages
+	messageRepo *database.MessageRepository
+
+	// Tags transmissions with the running net; nil disables /api/net/start and /stop
+	netLogger *bridge.TransmissionLogger
+
+	// Talkgroup directory served at /api/talkgroups
+	talkgroups []TalkgroupDTO
+
+	// excludeMonitors leaves repeat-all (TG 777) peers out of subscriber lists
+	excludeMonitors bool
+
+	// Log tailing over SSE; nil disables /api/logs/stream
+	logRing     *logger.Ring
+	logMinLevel logger.Level
+
+	// Admin endpoints (diagnostics) require these basic auth credentials
+	adminUser string
+	adminPass string
+
+	// Sanitized running configuration and start time, for diagnostics
+	config  *config.Config
+	started time.Time
+
+	// Anonymizes subscriber radio IDs and callsigns in responses; nil serves them as-is
+	anon *privacy.Anonymizer
+
+	// Running MASTER systems; nil disables /api/peer/{id}/disconnect
+	systems *network.Registry
 }
 
 // streamActivity tracks active transmission metadata
@@ -31,7 +74,8 @@ type API struct {
 // NewAPI creates a new API instance
 func NewAPI(log *logger.Logger) *API {
 	return &API{
-		logger: log,
+		logger:  log,
+		started: time.Now(),
 	}
 }
 
@@ -91,11 +135,85 @@ func (a *API) SetTransmissionRepo(repo *database.TransmissionRepository) {
 	a.txRepo = repo
 }
 
+// SetExcludeMonitors controls whether repeat-all (monitor) peers are left
+// out of dynamic bridge subscriber lists
+func (a *API) SetExcludeMonitors(exclude bool) {
+	a.excludeMonitors = exclude
+}
+
+// countsAsSubscriber reports whether a peer should appear in subscriber lists
+func (a *API) countsAsSubscriber(p *peer.Peer) bool {
+	return !(a.excludeMonitors && p.GetRepeatMode())
+}
+
 // SetUserRepo sets the user repository
 func (a *API) SetUserRepo(repo *database.DMRUserRepository) {
 	a.userRepo = repo
 }
 
+// SetUserCache caches up to size user lookups for ttl; a size of zero or
+// less disables the cache
+func (a *API) SetUserCache(size int, ttl time.Duration) {
+	if size <= 0 || ttl <= 0 {
+		a.users = nil
+		return
+	}
+	a.users = newUserCache(size, ttl)
+}
+
+// InvalidateUserCache forgets cached user lookups, e.g. after the user
+// database has been re-synced
+func (a *API) InvalidateUserCache() {
+	if a.users != nil {
+		a.users.clear()
+	}
+}
+
+// SetAnonymizer anonymizes subscriber radio IDs and callsigns in transmission,
+// stream and bridge responses. The user directory lookup is unaffected.
+func (a *API) SetAnonymizer(anon *privacy.Anonymizer) {
+	a.anon = anon
+}
+
+// SetMetrics sets the metrics collector used to count failed user lookups
+func (a *API) SetMetrics(c *metrics.Collector) {
+	a.metrics = c
+}
+
+// lookupUser returns the user for a radio ID, or nil if there is no user
+// repo, the ID is unknown, or the database is unavailable. Database failures
+// are logged and metered but never fail the caller, which falls back to the
+// numeric ID.
+func (a *API) lookupUser(radioID uint32) (*database.DMRUser, error) {
+	if a.userRepo == nil {
+		return nil, nil
+	}
+	now := time.Now()
+	if a.users != nil {
+		if user, ok := a.users.get(radioID, now); ok {
+			return user, nil
+		}
+	}
+	user, err := a.userRepo.GetByRadioID(radioID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		user, err = nil, nil
+	}
+	if err == nil {
+		if a.users != nil {
+			a.users.put(radioID, user, now)
+		}
+		return user, nil
+	}
+
+	if a.metrics != nil {
+		a.metrics.UserLookupFailed()
+	}
+	a.logger.Debug("User lookup failed",
+		logger.Int("radio_id", int(radioID)),
+		logger.Error(err))
+	return nil, err
+}
+
 // PeerDTO is a lightweight response for peer info
 type PeerDTO struct {
 	ID          uint32   `json:"id"`
@@ -109,6 +227,8 @@ type PeerDTO struct {
 	BytesRx     uint64   `json:"bytes_rx"`
 	PacketsTx   uint64   `json:"packets_tx"`
 	BytesTx     uint64   `json:"bytes_tx"`
+	Talking     bool     `json:"talking"`     // Transmitting now
+	CallFrames  int      `json:"call_frames"` // Frames in the call in progress
 	TS1         []uint32 `json:"ts1,omitempty"`
 	TS2         []uint32 `json:"ts2,omitempty"`
 }
@@ -183,6 +303,24 @@ func (a *API) HandleStatus(w http.ResponseWriter, r *http.Request) {
 		"build_time": buildTime,
 	}
 
+	// Live counts for dashboards; zero until the dependencies are wired in
+	connectedPeers := 0
+	if a.peers != nil {
+		for _, p := range a.peers.GetAllPeers() {
+			if p.GetState() == peer.StateConnected {
+				connectedPeers++
+			}
+		}
+	}
+	activeStreams, dynamicBridges := 0, 0
+	if a.router != nil {
+		activeStreams = a.router.ActiveStreamCount()
+		dynamicBridges = a.router.DynamicBridgeCount()
+	}
+	response["connected_peers"] = connectedPeers
+	response["active_streams"] = activeStreams
+	response["dynamic_bridges"] = dynamicBridges
+
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		a.logger.Error("Failed to encode status response", logger.Error(err))
 	}
@@ -221,6 +359,8 @@ func (a *API) HandlePeers(w http.ResponseWriter, r *http.Request) {
 			BytesRx:     snap.BytesRx,
 			PacketsTx:   snap.PacketsTx,
 			BytesTx:     snap.BytesTx,
+			Talking:     snap.Talking,
+			CallFrames:  snap.CallFrames,
 			TS1:         snap.Subscriptions.TS1,
 			TS2:         snap.Subscriptions.TS2,
 		})
@@ -230,6 +370,77 @@ func (a *API) HandlePeers(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// RepeaterDTO is the full RPTC configuration a peer reported, as sent
+type RepeaterDTO struct {
+	ID          uint32 `json:"id"`
+	State       string `json:"state"`
+	Callsign    string `json:"callsign"`
+	RXFreq      string `json:"rx_freq"`
+	TXFreq      string `json:"tx_freq"`
+	TXPower     string `json:"tx_power"`
+	ColorCode   string `json:"color_code"`
+	Latitude    string `json:"latitude"`
+	Longitude   string `json:"longitude"`
+	Height      string `json:"height"`
+	Location    string `json:"location"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+	SoftwareID  string `json:"software_id"`
+	PackageID   string `json:"package_id"`
+	ConnectedAt int64  `json:"connected_at"`
+}
+
+// HandleRepeater handles the /api/repeater/:id endpoint
+func (a *API) HandleRepeater(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/repeater/")
+	id64, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid repeater ID", http.StatusBadRequest)
+		return
+	}
+
+	if a.peers == nil {
+		http.Error(w, "Repeater not found", http.StatusNotFound)
+		return
+	}
+	p := a.peers.GetPeer(uint32(id64))
+	if p == nil {
+		http.Error(w, "Repeater not found", http.StatusNotFound)
+		return
+	}
+
+	cfg := p.GetRepeaterConfig()
+	dto := RepeaterDTO{
+		ID:          p.ID,
+		State:       p.GetState().String(),
+		Callsign:    cfg.Callsign,
+		RXFreq:      cfg.RXFreq,
+		TXFreq:      cfg.TXFreq,
+		TXPower:     cfg.TXPower,
+		ColorCode:   cfg.ColorCode,
+		Latitude:    cfg.Latitude,
+		Longitude:   cfg.Longitude,
+		Height:      cfg.Height,
+		Location:    cfg.Location,
+		Description: cfg.Description,
+		URL:         cfg.URL,
+		SoftwareID:  cfg.SoftwareID,
+		PackageID:   cfg.PackageID,
+		ConnectedAt: p.GetConnectedAt().Unix(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
+		a.logger.Error("Failed to encode repeater response", logger.Error(err))
+	}
+}
+
 // HandleBridges handles the /api/bridges endpoint
 func (a *API) HandleBridges(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -280,7 +491,7 @@ func (a *API) HandleBridges(w http.ResponseWriter, r *http.Request) {
 				if p.GetState().String() != "connected" {
 					continue
 				}
-				if p.Subscriptions == nil {
+				if p.Subscriptions == nil || !a.countsAsSubscriber(p) {
 					continue
 				}
 
@@ -315,16 +526,18 @@ func (a *API) HandleBridges(w http.ResponseWriter, r *http.Request) {
 			LastActivity:  db.LastActivity.Unix(),
 			Subscribers:   subscribers,
 			Active:        active,
-			ActiveRadioID: db.ActiveRadioID,
+			ActiveRadioID: a.anon.RadioID(db.ActiveRadioID),
 		}
 
-		// If active and we have a user repo, look up user info for active radio
-		if active && db.ActiveRadioID != 0 && a.userRepo != nil {
-			if user, err := a.userRepo.GetByRadioID(db.ActiveRadioID); err == nil {
-				dto.ActiveCallsign = user.Callsign
-				dto.ActiveFirstName = user.FirstName
-				dto.ActiveLastName = user.LastName
-				dto.ActiveLocation = user.Location()
+		// If active, look up user info for active radio
+		if active && db.ActiveRadioID != 0 {
+			if user, _ := a.lookupUser(db.ActiveRadioID); user != nil {
+				dto.ActiveCallsign = a.anon.Callsign(user.Callsign)
+				if !a.anon.Enabled() {
+					dto.ActiveFirstName = user.FirstName
+					dto.ActiveLastName = user.LastName
+					dto.ActiveLocation = user.Location()
+				}
 			}
 		}
 
@@ -337,6 +550,47 @@ func (a *API) HandleBridges(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// HandleRoutes handles the /api/routes endpoint, returning the effective
+// routing table derived from static bridge rules and dynamic bridges
+func (a *API) HandleRoutes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	routes := []bridge.RouteEntry{}
+	if a.router != nil {
+		routes = a.router.RouteTable(a.talkgroupSubscribers)
+	}
+
+	if err := json.NewEncoder(w).Encode(routes); err != nil {
+		a.logger.Error("Failed to encode routes response", logger.Error(err))
+	}
+}
+
+// talkgroupSubscribers returns the connected peers that dynamic bridge
+// traffic for a talkgroup is forwarded to: subscribers on either timeslot
+// and peers in repeat-all mode
+func (a *API) talkgroupSubscribers(tgid uint32) []uint32 {
+	if a.peers == nil {
+		return nil
+	}
+
+	result := make([]uint32, 0)
+	for _, p := range a.peers.GetAllPeers() {
+		if p.GetState() != peer.StateConnected {
+			continue
+		}
+		if p.GetRepeatMode() || (p.Subscriptions != nil && p.Subscriptions.IsSubscribedToTalkgroup(tgid)) {
+			result = append(result, p.ID)
+		}
+	}
+	return result
+}
+
 // HandleActivity handles the /api/activity endpoint
 func (a *API) HandleActivity(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -406,7 +660,7 @@ func (a *API) HandleTransmissions(w http.ResponseWriter, r *http.Request) {
 	for _, tx := range transmissions {
 		dto := TransmissionDTO{
 			ID:          tx.ID,
-			RadioID:     tx.RadioID,
+			RadioID:     a.anon.RadioID(tx.RadioID),
 			TalkgroupID: tx.TalkgroupID,
 			Timeslot:    tx.Timeslot,
 			Duration:    tx.Duration,
@@ -417,10 +671,8 @@ func (a *API) HandleTransmissions(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Look up callsign if user repo is available
-		if a.userRepo != nil {
-			if user, err := a.userRepo.GetByRadioID(tx.RadioID); err == nil {
-				dto.Callsign = user.Callsign
-			}
+		if user, _ := a.lookupUser(tx.RadioID); user != nil {
+			dto.Callsign = a.anon.Callsign(user.Callsign)
 		}
 
 		dtos = append(dtos, dto)
@@ -449,6 +701,7 @@ type UserDTO struct {
 	State     string `json:"state"`
 	Country   string `json:"country"`
 	Location  string `json:"location"`
+	Degraded  bool   `json:"degraded,omitempty"` // User database unavailable; only RadioID is set
 }
 
 // HandleUserLookup handles the /api/user/:radio_id endpoint
@@ -481,9 +734,17 @@ func (a *API) HandleUserLookup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Look up user
-	user, err := a.userRepo.GetByRadioID(radioID)
+	// Look up user; if the database is unavailable, answer with the bare
+	// radio ID rather than failing the request
+	user, err := a.lookupUser(radioID)
 	if err != nil {
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(UserDTO{RadioID: radioID, Degraded: true}); err != nil {
+			a.logger.Error("Failed to encode user response", logger.Error(err))
+		}
+		return
+	}
+	if user == nil {
 		http.Error(w, "User not found", http.StatusNotFound)
 		return
 	}
@@ -535,6 +796,8 @@ func (a *API) GetPeersData() []PeerDTO {
 			BytesRx:     snap.BytesRx,
 			PacketsTx:   snap.PacketsTx,
 			BytesTx:     snap.BytesTx,
+			Talking:     snap.Talking,
+			CallFrames:  snap.CallFrames,
 			TS1:         snap.Subscriptions.TS1,
 			TS2:         snap.Subscriptions.TS2,
 		})
@@ -580,7 +843,7 @@ func (a *API) GetBridgesData() map[string]interface{} {
 				if p.GetState().String() != "connected" {
 					continue
 				}
-				if p.Subscriptions == nil {
+				if p.Subscriptions == nil || !a.countsAsSubscriber(p) {
 					continue
 				}
 				
@@ -615,16 +878,18 @@ func (a *API) GetBridgesData() map[string]interface{} {
 			LastActivity:  db.LastActivity.Unix(),
 			Subscribers:   subscribers,
 			Active:        active,
-			ActiveRadioID: db.ActiveRadioID,
+			ActiveRadioID: a.anon.RadioID(db.ActiveRadioID),
 		}
 		
-		// If active and we have a user repo, look up user info
-		if active && db.ActiveRadioID != 0 && a.userRepo != nil {
-			if user, err := a.userRepo.GetByRadioID(db.ActiveRadioID); err == nil {
-				dto.ActiveCallsign = user.Callsign
-				dto.ActiveFirstName = user.FirstName
-				dto.ActiveLastName = user.LastName
-				dto.ActiveLocation = user.Location()
+		// If active, look up user info
+		if active && db.ActiveRadioID != 0 {
+			if user, _ := a.lookupUser(db.ActiveRadioID); user != nil {
+				dto.ActiveCallsign = a.anon.Callsign(user.Callsign)
+				if !a.anon.Enabled() {
+					dto.ActiveFirstName = user.FirstName
+					dto.ActiveLastName = user.LastName
+					dto.ActiveLocation = user.Location()
+				}
 			}
 		}
 		
@@ -656,7 +921,7 @@ func (a *API) GetTransmissionsData(page, perPage int) map[string]interface{} {
 	for _, tx := range transmissions {
 		dto := TransmissionDTO{
 			ID:          tx.ID,
-			RadioID:     tx.RadioID,
+			RadioID:     a.anon.RadioID(tx.RadioID),
 			TalkgroupID: tx.TalkgroupID,
 			Timeslot:    tx.Timeslot,
 			Duration:    tx.Duration,
@@ -667,10 +932,8 @@ func (a *API) GetTransmissionsData(page, perPage int) map[string]interface{} {
 		}
 		
 		// Look up callsign if user repo is available
-		if a.userRepo != nil {
-			if user, err := a.userRepo.GetByRadioID(tx.RadioID); err == nil {
-				dto.Callsign = user.Callsign
-			}
+		if user, _ := a.lookupUser(tx.RadioID); user != nil {
+			dto.Callsign = a.anon.Callsign(user.Callsign)
 		}
 		
 		dtos = append(dtos, dto)
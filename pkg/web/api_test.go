@@ -1,17 +1,25 @@
 package web
 
 import (
+	"context"
 	"encoding/json"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/config"
 	"github.com/dbehnke/dmr-nexus/pkg/database"
 	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/metrics"
+	"github.com/dbehnke/dmr-nexus/pkg/network"
 	"github.com/dbehnke/dmr-nexus/pkg/peer"
+	"github.com/dbehnke/dmr-nexus/pkg/privacy"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
 )
 
 func TestMaskIPAddress(t *testing.T) {
@@ -135,6 +143,58 @@ func TestDashboardBridgeCount_AAA(t *testing.T) {
 	}
 }
 
+func TestHandleStatus_LiveCounts(t *testing.T) {
+	api := NewAPI(logger.New(logger.Config{Level: "error"}))
+
+	status := func() map[string]interface{} {
+		w := httptest.NewRecorder()
+		api.HandleStatus(w, httptest.NewRequest("GET", "/api/status", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		var response map[string]interface{}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return response
+	}
+
+	// Without dependencies the counts are zero and the version fields remain
+	response := status()
+	if response["service"] != "dmr-nexus" || response["version"] == nil {
+		t.Errorf("Expected version info kept, got %v", response)
+	}
+	for _, key := range []string{"connected_peers", "active_streams", "dynamic_bridges"} {
+		if n, ok := response[key].(float64); !ok || n != 0 {
+			t.Errorf("Expected %s 0 without dependencies, got %v", key, response[key])
+		}
+	}
+
+	pm := peer.NewPeerManager()
+	pm.AddPeer(312000, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 62031}).SetConnected()
+	pm.AddPeer(312001, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 62032}) // still logging in
+	router := bridge.NewRouter()
+	router.GetOrCreateDynamicBridge(3100)
+	router.GetOrCreateDynamicBridge(91)
+	router.RoutePacket(&protocol.DMRDPacket{
+		SourceID:      3120001,
+		DestinationID: 3100,
+		Timeslot:      1,
+		CallType:      protocol.CallTypeGroup,
+		FrameType:     protocol.FrameTypeVoiceHeader,
+		StreamID:      4242,
+	}, "SYSTEM1")
+	api.SetDeps(pm, router)
+
+	response = status()
+	want := map[string]float64{"connected_peers": 1, "active_streams": 1, "dynamic_bridges": 2}
+	for key, n := range want {
+		if got, ok := response[key].(float64); !ok || got != n {
+			t.Errorf("Expected %s %v, got %v", key, n, response[key])
+		}
+	}
+}
+
 func TestHandleTransmissions_NoRepo(t *testing.T) {
 	log := logger.New(logger.Config{Level: "error"})
 	api := NewAPI(log)
@@ -238,6 +298,59 @@ func TestHandleTransmissions_WithData(t *testing.T) {
 	}
 }
 
+func TestHandleTransmissions_Anonymized(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	db, err := database.NewDB(database.Config{Path: filepath.Join(t.TempDir(), "tx.db")}, log)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	txRepo := database.NewTransmissionRepository(db.GetDB())
+	userRepo := database.NewDMRUserRepository(db.GetDB())
+	if err := userRepo.Upsert(&database.DMRUser{RadioID: 3120001, Callsign: "N0CALL"}); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	// Saved before privacy mode was turned on, so it carries the real ID
+	now := time.Now()
+	if err := txRepo.Create(&database.Transmission{
+		RadioID:     3120001,
+		TalkgroupID: 91,
+		Timeslot:    1,
+		StreamID:    1,
+		StartTime:   now,
+		EndTime:     now.Add(time.Second),
+	}); err != nil {
+		t.Fatalf("Failed to create transmission: %v", err)
+	}
+
+	anon := privacy.New(privacy.ModeHash, "secret")
+	api := NewAPI(log)
+	api.SetTransmissionRepo(txRepo)
+	api.SetUserRepo(userRepo)
+	api.SetAnonymizer(anon)
+
+	w := httptest.NewRecorder()
+	api.HandleTransmissions(w, httptest.NewRequest("GET", "/api/transmissions", nil))
+
+	var response struct {
+		Transmissions []TransmissionDTO `json:"transmissions"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Transmissions) != 1 {
+		t.Fatalf("Expected 1 transmission, got %d", len(response.Transmissions))
+	}
+	tx := response.Transmissions[0]
+	if tx.RadioID != anon.RadioID(3120001) {
+		t.Errorf("Expected pseudonymous radio ID, got %d", tx.RadioID)
+	}
+	if tx.Callsign == "N0CALL" || tx.Callsign != anon.Callsign("N0CALL") {
+		t.Errorf("Expected pseudonymous callsign, got %q", tx.Callsign)
+	}
+}
+
 func TestHandleTransmissions_MethodNotAllowed(t *testing.T) {
 	log := logger.New(logger.Config{Level: "error"})
 	api := NewAPI(log)
@@ -308,3 +421,268 @@ func TestHandlePeers_MaskedIPAddress(t *testing.T) {
 		}
 	}
 }
+
+func TestHandleRoutes_AAA(t *testing.T) {
+	// Arrange
+	log := logger.New(logger.Config{Level: "error"})
+	api := NewAPI(log)
+
+	pm := peer.NewPeerManager()
+	p1 := pm.AddPeer(1001, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 10001})
+	p1.SetConnected()
+	p1.GetSubscriptions().AddDynamic(7000, 2)
+	p2 := pm.AddPeer(1002, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 10002})
+	p2.GetSubscriptions().AddDynamic(7000, 1) // Not connected, not routed to
+
+	router := bridge.NewRouter()
+	rules := bridge.NewBridgeRuleSet("NATIONWIDE")
+	rules.AddRule(&bridge.BridgeRule{System: "SYSTEM1", TGID: 3100, Timeslot: 1, Active: true})
+	rules.AddRule(&bridge.BridgeRule{System: "SYSTEM2", TGID: 3100, Timeslot: 1, Active: true})
+	router.AddBridge(rules)
+	router.GetOrCreateDynamicBridge(7000)
+
+	api.SetDeps(pm, router)
+
+	req := httptest.NewRequest("GET", "/api/routes", nil)
+	w := httptest.NewRecorder()
+
+	// Act
+	api.HandleRoutes(w, req)
+
+	// Assert
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var routes []bridge.RouteEntry
+	if err := json.NewDecoder(w.Body).Decode(&routes); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(routes) != 3 {
+		t.Fatalf("Expected 3 routes (TG 3100 TS1, TG 7000 TS1/TS2), got %d: %+v", len(routes), routes)
+	}
+	if routes[0].TGID != 3100 || len(routes[0].Systems) != 2 {
+		t.Errorf("Unexpected static route: %+v", routes[0])
+	}
+	for _, route := range routes[1:] {
+		if route.TGID != 7000 || len(route.Peers) != 1 || route.Peers[0] != 1001 {
+			t.Errorf("Unexpected dynamic route: %+v", route)
+		}
+	}
+}
+
+func TestHandleBridges_ExcludeMonitors_AAA(t *testing.T) {
+	// Arrange
+	log := logger.New(logger.Config{Level: "error"})
+
+	pm := peer.NewPeerManager()
+	listener := pm.AddPeer(1001, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 10001})
+	listener.SetConnected()
+	listener.GetSubscriptions().AddDynamic(7000, 2)
+	monitor := pm.AddPeer(1002, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 10002})
+	monitor.SetConnected()
+	monitor.SetRepeatMode(true)
+	monitor.GetSubscriptions().AddDynamic(7000, 1)
+
+	router := bridge.NewRouter()
+	router.GetOrCreateDynamicBridge(7000)
+
+	subscriberCount := func(exclude bool) int {
+		api := NewAPI(log)
+		api.SetDeps(pm, router)
+		api.SetExcludeMonitors(exclude)
+
+		req := httptest.NewRequest("GET", "/api/bridges", nil)
+		w := httptest.NewRecorder()
+		api.HandleBridges(w, req)
+
+		var resp struct {
+			Dynamic []DynamicBridgeDTO `json:"dynamic"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(resp.Dynamic) != 1 {
+			t.Fatalf("Expected 1 dynamic bridge, got %d", len(resp.Dynamic))
+		}
+
+		dyn := api.GetBridgesData()["dynamic"].([]DynamicBridgeDTO)
+		if len(dyn[0].Subscribers) != len(resp.Dynamic[0].Subscribers) {
+			t.Errorf("WebSocket data has %d subscribers, HTTP has %d",
+				len(dyn[0].Subscribers), len(resp.Dynamic[0].Subscribers))
+		}
+		return len(resp.Dynamic[0].Subscribers)
+	}
+
+	// Act / Assert
+	if got := subscriberCount(false); got != 2 {
+		t.Errorf("Expected 2 subscribers without exclusion, got %d", got)
+	}
+	if got := subscriberCount(true); got != 1 {
+		t.Errorf("Expected repeat-all peer to be excluded, got %d subscribers", got)
+	}
+}
+
+func TestUserLookup_DegradesWhenDatabaseUnavailable(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	dir := t.TempDir()
+
+	txDB, err := database.NewDB(database.Config{Path: filepath.Join(dir, "tx.db")}, log)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() { _ = txDB.Close() }()
+	txRepo := database.NewTransmissionRepository(txDB.GetDB())
+	now := time.Now()
+	if err := txRepo.Create(&database.Transmission{
+		RadioID:     3120001,
+		TalkgroupID: 91,
+		Timeslot:    1,
+		StreamID:    1,
+		StartTime:   now,
+		EndTime:     now.Add(time.Second),
+	}); err != nil {
+		t.Fatalf("Failed to create transmission: %v", err)
+	}
+
+	// User repo on a closed database fails every query
+	userDB, err := database.NewDB(database.Config{Path: filepath.Join(dir, "users.db")}, log)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	userRepo := database.NewDMRUserRepository(userDB.GetDB())
+	if err := userDB.Close(); err != nil {
+		t.Fatalf("Failed to close database: %v", err)
+	}
+
+	collector := metrics.NewCollector()
+	api := NewAPI(log)
+	api.SetTransmissionRepo(txRepo)
+	api.SetUserRepo(userRepo)
+	api.SetMetrics(collector)
+
+	// User lookup answers with the numeric ID instead of an error
+	w := httptest.NewRecorder()
+	api.HandleUserLookup(w, httptest.NewRequest("GET", "/api/user/3120001", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var user UserDTO
+	if err := json.NewDecoder(w.Body).Decode(&user); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if user.RadioID != 3120001 || !user.Degraded || user.Callsign != "" {
+		t.Errorf("Expected degraded numeric fallback, got %+v", user)
+	}
+
+	// Transmissions are still listed, just without callsigns
+	w = httptest.NewRecorder()
+	api.HandleTransmissions(w, httptest.NewRequest("GET", "/api/transmissions", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var response struct {
+		Transmissions []TransmissionDTO `json:"transmissions"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Transmissions) != 1 || response.Transmissions[0].RadioID != 3120001 || response.Transmissions[0].Callsign != "" {
+		t.Errorf("Expected transmission with numeric ID only, got %+v", response.Transmissions)
+	}
+
+	if got := collector.GetUserLookupFailures(); got != 2 {
+		t.Errorf("Expected 2 metered lookup failures, got %d", got)
+	}
+}
+
+func TestHandleRepeater_ServesRPTCConfig(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	pm := peer.NewPeerManager()
+
+	srv := network.NewServer(config.SystemConfig{Mode: "MASTER", Passphrase: "secret"}, "test-system", log).
+		WithPeerManager(pm)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = srv.Start(ctx) }()
+	if err := srv.WaitStarted(ctx); err != nil {
+		t.Fatalf("server did not start: %v", err)
+	}
+	addr, err := srv.Addr()
+	if err != nil {
+		t.Fatalf("Addr error: %v", err)
+	}
+
+	// A real PEER client performs the RPTL/RPTK/RPTC handshake
+	client := network.NewClient(config.SystemConfig{
+		Mode:        "PEER",
+		MasterIP:    "127.0.0.1",
+		MasterPort:  addr.Port,
+		Passphrase:  "secret",
+		RadioID:     312100,
+		Callsign:    "W1REP",
+		RXFreq:      449000000,
+		TXFreq:      444000000,
+		TXPower:     25,
+		ColorCode:   3,
+		Latitude:    42.3601,
+		Longitude:   -71.0589,
+		Height:      75,
+		Location:    "Boston, MA",
+		Description: "Test repeater",
+		URL:         "https://w1rep.example",
+		SoftwareID:  "TestSW",
+		PackageID:   "TestPkg",
+	}, log)
+	go func() { _ = client.Start(ctx) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if p := pm.GetPeer(312100); p != nil && p.GetState() == peer.StateConnected {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("peer did not complete handshake")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	api := NewAPI(log)
+	api.SetDeps(pm, nil)
+
+	w := httptest.NewRecorder()
+	api.HandleRepeater(w, httptest.NewRequest("GET", "/api/repeater/312100", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var got RepeaterDTO
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	want := RepeaterDTO{
+		ID:          312100,
+		State:       "connected",
+		Callsign:    "W1REP",
+		RXFreq:      "449000000",
+		TXFreq:      "444000000",
+		TXPower:     "25",
+		ColorCode:   "3",
+		Latitude:    "42.3601",
+		Longitude:   "-71.0589",
+		Height:      "75",
+		Location:    "Boston, MA",
+		Description: "Test repeater",
+		URL:         "https://w1rep.example",
+		SoftwareID:  "TestSW",
+		PackageID:   "TestPkg",
+		ConnectedAt: got.ConnectedAt,
+	}
+	if got != want {
+		t.Errorf("Repeater config mismatch:\n got  %+v\n want %+v", got, want)
+	}
+
+	w = httptest.NewRecorder()
+	api.HandleRepeater(w, httptest.NewRequest("GET", "/api/repeater/999999", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown repeater, got %d", w.Code)
+	}
+}
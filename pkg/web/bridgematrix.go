@@ -0,0 +1,29 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+)
+
+// HandleBridgeMatrix handles /api/bridge-matrix: which systems are linked,
+// and by which talkgroups, for drawing the network
+func (a *API) HandleBridgeMatrix(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	matrix := bridge.BridgeMatrix{Systems: []string{}, Links: [][][]uint32{}}
+	if a.router != nil {
+		matrix = a.router.BridgeMatrix()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(matrix); err != nil {
+		a.logger.Error("Failed to encode bridge matrix response", logger.Error(err))
+	}
+}
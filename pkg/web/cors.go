@@ -0,0 +1,68 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+)
+
+var (
+	defaultCORSMethods = []string{"GET", "POST", "DELETE"}
+	defaultCORSHeaders = []string{"Content-Type", "Authorization"}
+)
+
+// corsMiddleware adds CORS headers for requests from the configured origins
+// and answers their preflight requests. With no origins configured the
+// handler is returned unchanged, leaving the browser's same-origin policy in
+// place.
+func corsMiddleware(cfg config.CORSConfig, next http.Handler) http.Handler {
+	if len(cfg.AllowedOrigins) == 0 {
+		return next
+	}
+
+	allowAny := false
+	origins := make(map[string]bool, len(cfg.AllowedOrigins))
+	for _, o := range cfg.AllowedOrigins {
+		if o == "*" {
+			allowAny = true
+		}
+		origins[strings.TrimSuffix(o, "/")] = true
+	}
+	methods := cfg.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	headers := cfg.AllowedHeaders
+	if len(headers) == 0 {
+		headers = defaultCORSHeaders
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(headers, ", ")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Origin")
+
+		origin := r.Header.Get("Origin")
+		if origin == "" || (!allowAny && !origins[origin]) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+
+		// Preflight: answer it here rather than in the API handlers
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", allowMethods)
+			w.Header().Set("Access-Control-Allow-Headers", allowHeaders)
+			if cfg.MaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(cfg.MaxAge))
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,108 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	h := corsMiddleware(config.CORSConfig{
+		AllowedOrigins: []string{"https://dash.example.org"},
+		MaxAge:         600,
+	}, ok)
+
+	t.Run("preflight from allowed origin", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/api/streams/5", nil)
+		req.Header.Set("Origin", "https://dash.example.org")
+		req.Header.Set("Access-Control-Request-Method", "DELETE")
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusNoContent {
+			t.Fatalf("Expected 204, got %d", rr.Code)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "https://dash.example.org" {
+			t.Errorf("Allow-Origin = %q", got)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST, DELETE" {
+			t.Errorf("Allow-Methods = %q", got)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Headers"); got != "Content-Type, Authorization" {
+			t.Errorf("Allow-Headers = %q", got)
+		}
+		if got := rr.Header().Get("Access-Control-Max-Age"); got != "600" {
+			t.Errorf("Max-Age = %q", got)
+		}
+	})
+
+	t.Run("simple request from allowed origin", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/status", nil)
+		req.Header.Set("Origin", "https://dash.example.org")
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", rr.Code)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "https://dash.example.org" {
+			t.Errorf("Allow-Origin = %q", got)
+		}
+	})
+
+	t.Run("other origin gets no CORS headers", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/api/status", nil)
+		req.Header.Set("Origin", "https://evil.example.com")
+		req.Header.Set("Access-Control-Request-Method", "GET")
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("Expected no Allow-Origin, got %q", got)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Methods"); got != "" {
+			t.Errorf("Expected no Allow-Methods, got %q", got)
+		}
+	})
+}
+
+func TestCORSMiddleware_DefaultSameOrigin(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	h := corsMiddleware(config.CORSConfig{}, ok)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/status", nil)
+	req.Header.Set("Origin", "https://dash.example.org")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Expected no CORS headers by default, got Allow-Origin %q", got)
+	}
+}
+
+func TestCORSMiddleware_Wildcard(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	h := corsMiddleware(config.CORSConfig{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}}, ok)
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/peers", nil)
+	req.Header.Set("Origin", "http://localhost:5173")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "http://localhost:5173" {
+		t.Errorf("Allow-Origin = %q", got)
+	}
+	if got := rr.Header().Get("Access-Control-Allow-Methods"); got != "GET" {
+		t.Errorf("Allow-Methods = %q", got)
+	}
+}
@@ -0,0 +1,120 @@
+package web
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+)
+
+// SetAdminCredentials sets the basic auth credentials admin endpoints
+// require. With no credentials set, admin endpoints are refused.
+func (a *API) SetAdminCredentials(username, password string) {
+	a.adminUser = username
+	a.adminPass = password
+}
+
+// SetConfig provides the running configuration for diagnostics; it is
+// sanitized before being stored
+func (a *API) SetConfig(cfg *config.Config) {
+	if cfg == nil {
+		a.config = nil
+		return
+	}
+	sanitized := cfg.Sanitized()
+	a.config = &sanitized
+}
+
+// requireAdmin checks basic auth against the admin credentials, writing the
+// error response and returning false if the request may not proceed
+func (a *API) requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if a.adminUser == "" || a.adminPass == "" {
+		http.Error(w, "Admin endpoints require web.username and web.password", http.StatusForbidden)
+		return false
+	}
+	user, pass, ok := r.BasicAuth()
+	if !ok ||
+		subtle.ConstantTimeCompare([]byte(user), []byte(a.adminUser)) != 1 ||
+		subtle.ConstantTimeCompare([]byte(pass), []byte(a.adminPass)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Basic realm="dmr-nexus"`)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// diagnosticsLogLines bounds how many recent log lines a bundle carries
+const diagnosticsLogLines = 200
+
+// HandleDiagnostics handles GET /api/diagnostics: a one-shot support bundle
+// with sanitized config, peers, streams, bridges, recent events, version
+// and runtime stats
+func (a *API) HandleDiagnostics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !a.requireAdmin(w, r) {
+		return
+	}
+
+	versionStr, commit, buildTime := GetVersionInfo()
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	logs := make([]map[string]interface{}, 0)
+	if a.logRing != nil {
+		recent := a.logRing.Recent()
+		if len(recent) > diagnosticsLogLines {
+			recent = recent[len(recent)-diagnosticsLogLines:]
+		}
+		for _, e := range recent {
+			logs = append(logs, map[string]interface{}{
+				"time": e.Time,
+				"line": e.Line,
+			})
+		}
+	}
+
+	bundle := map[string]interface{}{
+		"generated_at": time.Now().UTC(),
+		"version": map[string]interface{}{
+			"version":    versionStr,
+			"commit":     commit,
+			"build_time": buildTime,
+			"go_version": runtime.Version(),
+		},
+		"runtime": map[string]interface{}{
+			"uptime_seconds": int64(time.Since(a.started).Seconds()),
+			"goroutines":     runtime.NumGoroutine(),
+			"gomaxprocs":     runtime.GOMAXPROCS(0),
+			"num_cpu":        runtime.NumCPU(),
+			"memory": map[string]interface{}{
+				"alloc_bytes":      mem.Alloc,
+				"heap_inuse_bytes": mem.HeapInuse,
+				"sys_bytes":        mem.Sys,
+				"num_gc":           mem.NumGC,
+			},
+		},
+		"config":  a.config,
+		"peers":   a.GetPeersData(),
+		"streams": a.activeStreams(),
+		"bridges": a.GetBridgesData(),
+		"recent_events": map[string]interface{}{
+			"transmissions": a.GetTransmissionsData(1, 50)["transmissions"],
+			"logs":          logs,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="dmr-nexus-diagnostics.json"`)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(bundle); err != nil {
+		a.logger.Error("Failed to encode diagnostics response", logger.Error(err))
+	}
+}
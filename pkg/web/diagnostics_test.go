@@ -0,0 +1,65 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/peer"
+)
+
+func TestHandleDiagnostics(t *testing.T) {
+	api := NewAPI(logger.New(logger.Config{Level: "error"}))
+	api.SetDeps(peer.NewPeerManager(), bridge.NewRouter())
+	api.SetConfig(&config.Config{
+		Systems: map[string]config.SystemConfig{
+			"MASTER-1": {Mode: "MASTER", Passphrase: "s3cret"},
+		},
+	})
+
+	get := func(user, pass string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/diagnostics", nil)
+		if user != "" {
+			req.SetBasicAuth(user, pass)
+		}
+		w := httptest.NewRecorder()
+		api.HandleDiagnostics(w, req)
+		return w
+	}
+
+	if w := get("admin", "pw"); w.Code != http.StatusForbidden {
+		t.Fatalf("Expected 403 with no admin credentials configured, got %d", w.Code)
+	}
+
+	api.SetAdminCredentials("admin", "pw")
+	if w := get("", ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected 401 without auth, got %d", w.Code)
+	}
+	if w := get("admin", "wrong"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected 401 with a wrong password, got %d", w.Code)
+	}
+
+	w := get("admin", "pw")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	if strings.Contains(body, "s3cret") {
+		t.Error("Diagnostics leaked the system passphrase")
+	}
+
+	var bundle map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(body), &bundle); err != nil {
+		t.Fatalf("Decode error: %v", err)
+	}
+	for _, key := range []string{"generated_at", "version", "runtime", "config", "peers", "streams", "bridges", "recent_events"} {
+		if _, ok := bundle[key]; !ok {
+			t.Errorf("Diagnostics bundle missing %q", key)
+		}
+	}
+}
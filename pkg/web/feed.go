@@ -0,0 +1,132 @@
+package web
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/database"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+)
+
+// rssFeed is an RSS 2.0 document
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Description string  `xml:"description"`
+	PubDate     string  `xml:"pubDate"`
+	GUID        rssGUID `xml:"guid"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// HandleFeed handles /api/feed.xml: recent transmissions as an RSS feed for
+// feed readers and home dashboards. ?limit sets the number of items
+// (default 50, at most 100).
+func (a *API) HandleFeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit := 50
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+
+	var transmissions []database.Transmission
+	if a.txRepo != nil {
+		var err error
+		transmissions, err = a.txRepo.GetRecent(limit)
+		if err != nil {
+			a.logger.Error("Failed to get transmissions for feed", logger.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	title := "DMR-Nexus"
+	if a.config != nil && a.config.Server.Name != "" {
+		title = a.config.Server.Name
+	}
+	// The Host header is the client's to choose, so the channel links to the
+	// configured public URL, or relatively
+	link := "/"
+	if a.config != nil && a.config.Web.PublicURL != "" {
+		link = strings.TrimRight(a.config.Web.PublicURL, "/") + "/"
+	}
+
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         title + " transmissions",
+			Link:          link,
+			Description:   "Recent transmissions heard on " + title,
+			LastBuildDate: time.Now().UTC().Format(time.RFC1123Z),
+			Items:         make([]rssItem, 0, len(transmissions)),
+		},
+	}
+	for _, tx := range transmissions {
+		feed.Channel.Items = append(feed.Channel.Items, a.feedItem(tx))
+	}
+
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		return
+	}
+	if err := xml.NewEncoder(w).Encode(feed); err != nil {
+		a.logger.Error("Failed to encode feed response", logger.Error(err))
+	}
+}
+
+// feedItem describes one transmission as a feed item, with the caller
+// anonymized like /api/transmissions
+func (a *API) feedItem(tx database.Transmission) rssItem {
+	caller := "Unknown"
+	if radioID := a.anon.RadioID(tx.RadioID); radioID != 0 {
+		caller = strconv.FormatUint(uint64(radioID), 10)
+	}
+	if user, _ := a.lookupUser(tx.RadioID); user != nil {
+		if callsign := a.anon.Callsign(user.Callsign); callsign != "" {
+			caller = callsign
+		}
+	}
+
+	talkgroup := "TG " + strconv.FormatUint(uint64(tx.TalkgroupID), 10)
+	for _, tg := range a.talkgroups {
+		if tg.ID == tx.TalkgroupID {
+			talkgroup += " (" + tg.Name + ")"
+			break
+		}
+	}
+
+	return rssItem{
+		Title: caller + " on " + talkgroup,
+		Description: fmt.Sprintf("%s on %s, timeslot %d, for %.1fs",
+			caller, talkgroup, tx.Timeslot, tx.Duration),
+		PubDate: tx.StartTime.UTC().Format(time.RFC1123Z),
+		GUID:    rssGUID{Value: "dmr-nexus-tx-" + strconv.FormatUint(uint64(tx.ID), 10)},
+	}
+}
@@ -0,0 +1,111 @@
+package web
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/database"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+)
+
+func TestHandleFeed(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	db, err := database.NewDB(database.Config{Path: filepath.Join(t.TempDir(), "feed.db")}, log)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	txRepo := database.NewTransmissionRepository(db.GetDB())
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, tx := range []database.Transmission{
+		{RadioID: 3120001, TalkgroupID: 3100, Timeslot: 1, Duration: 4.2, StartTime: start, EndTime: start.Add(4200 * time.Millisecond)},
+		{RadioID: 3120002, TalkgroupID: 91, Timeslot: 2, Duration: 12, StartTime: start.Add(time.Minute), EndTime: start.Add(72 * time.Second)},
+	} {
+		if err := txRepo.Create(&tx); err != nil {
+			t.Fatalf("Create %d error: %v", i, err)
+		}
+	}
+	userRepo := database.NewDMRUserRepository(db.GetDB())
+	if err := userRepo.Upsert(&database.DMRUser{RadioID: 3120001, Callsign: "W1ABC"}); err != nil {
+		t.Fatalf("Upsert error: %v", err)
+	}
+
+	api := NewAPI(log)
+	api.SetTransmissionRepo(txRepo)
+	api.SetUserRepo(userRepo)
+	api.SetTalkgroups([]config.Talkgroup{{ID: 3100, Name: "USA"}})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/feed.xml", nil)
+	req.Host = "attacker.example"
+	api.HandleFeed(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/rss+xml") {
+		t.Errorf("Expected an RSS content type, got %q", ct)
+	}
+
+	var feed struct {
+		XMLName xml.Name `xml:"rss"`
+		Version string   `xml:"version,attr"`
+		Channel struct {
+			Title string `xml:"title"`
+			Link  string `xml:"link"`
+			Items []struct {
+				Title       string `xml:"title"`
+				Description string `xml:"description"`
+				PubDate     string `xml:"pubDate"`
+				GUID        string `xml:"guid"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("Feed is not valid XML: %v", err)
+	}
+	if feed.Version != "2.0" || feed.Channel.Title == "" {
+		t.Errorf("Expected an RSS 2.0 channel with a title, got version %q title %q", feed.Version, feed.Channel.Title)
+	}
+	// The client's Host header never makes it into the feed
+	if feed.Channel.Link != "/" {
+		t.Errorf("Expected a relative channel link without web.public_url, got %q", feed.Channel.Link)
+	}
+	items := feed.Channel.Items
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(items))
+	}
+
+	// Newest first
+	if items[0].Title != "3120002 on TG 91" {
+		t.Errorf("Unexpected first item title %q", items[0].Title)
+	}
+	if items[1].Title != "W1ABC on TG 3100 (USA)" || !strings.Contains(items[1].Description, "4.2s") {
+		t.Errorf("Unexpected second item %q / %q", items[1].Title, items[1].Description)
+	}
+	if pub, err := time.Parse(time.RFC1123Z, items[1].PubDate); err != nil || !pub.Equal(start) {
+		t.Errorf("Expected pubDate %v, got %q (%v)", start, items[1].PubDate, err)
+	}
+	if items[0].GUID == "" || items[0].GUID == items[1].GUID {
+		t.Errorf("Expected distinct GUIDs, got %q and %q", items[0].GUID, items[1].GUID)
+	}
+
+	w = httptest.NewRecorder()
+	api.HandleFeed(w, httptest.NewRequest(http.MethodGet, "/api/feed.xml?limit=1", nil))
+	if n := strings.Count(w.Body.String(), "<item>"); n != 1 {
+		t.Errorf("Expected 1 item with limit=1, got %d", n)
+	}
+
+	api.SetConfig(&config.Config{Web: config.WebConfig{PublicURL: "https://dmr.example.org/"}})
+	w = httptest.NewRecorder()
+	api.HandleFeed(w, req)
+	if !strings.Contains(w.Body.String(), "<link>https://dmr.example.org/</link>") {
+		t.Errorf("Expected the channel to link to web.public_url, got %s", w.Body.String())
+	}
+}
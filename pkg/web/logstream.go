@@ -0,0 +1,107 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+)
+
+const (
+	// logStreamBuffer is how many lines a slow client may fall behind
+	// before it starts missing lines
+	logStreamBuffer = 256
+	// logStreamKeepalive is how often an idle stream sends a comment so
+	// proxies don't time it out
+	logStreamKeepalive = 15 * time.Second
+)
+
+// SetLogRing enables GET /api/logs/stream, serving lines from ring at
+// minLevel or above
+func (a *API) SetLogRing(ring *logger.Ring, minLevel string) {
+	a.logRing = ring
+	a.logMinLevel = logger.ParseLevel(minLevel)
+}
+
+// HandleLogStream streams recent and new log lines as Server-Sent Events.
+// ?level= may raise, but not lower, the configured minimum level.
+func (a *API) HandleLogStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if a.logRing == nil {
+		http.Error(w, "Log streaming not enabled", http.StatusNotFound)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	minLevel := a.logMinLevel
+	if q := r.URL.Query().Get("level"); q != "" {
+		if lvl := logger.ParseLevel(q); lvl > minLevel {
+			minLevel = lvl
+		}
+	}
+
+	// The stream outlives the server's write timeout
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	recent, lines, cancel := a.logRing.Subscribe(logStreamBuffer)
+	defer cancel()
+
+	for _, e := range recent {
+		if e.Level >= minLevel {
+			writeLogEvent(w, e)
+		}
+	}
+	flusher.Flush()
+
+	keepalive := time.NewTicker(logStreamKeepalive)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e := <-lines:
+			if e.Level < minLevel {
+				continue
+			}
+			if err := writeLogEvent(w, e); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeLogEvent writes one log line as an SSE event, timestamped like the
+// console output
+func writeLogEvent(w http.ResponseWriter, e logger.Entry) error {
+	line := e.Time.Format("2006/01/02 15:04:05") + " " + e.Line
+	var b strings.Builder
+	for _, l := range strings.Split(line, "\n") {
+		b.WriteString("data: ")
+		b.WriteString(l)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	_, err := fmt.Fprint(w, b.String())
+	return err
+}
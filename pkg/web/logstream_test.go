@@ -0,0 +1,97 @@
+package web
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+)
+
+func TestHandleLogStream_SSE(t *testing.T) {
+	ring := logger.NewRing(16)
+	log := logger.New(logger.Config{Level: "debug", Output: io.Discard, Ring: ring})
+	api := NewAPI(log)
+	api.SetLogRing(ring, "info")
+
+	srv := httptest.NewServer(http.HandlerFunc(api.HandleLogStream))
+	defer srv.Close()
+
+	log.Info("before connect", logger.Int("peer_id", 311001))
+	log.Debug("below minimum level")
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET error: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Expected text/event-stream, got %q", ct)
+	}
+
+	events := make(chan string, 8)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+				events <- data
+			}
+		}
+		close(events)
+	}()
+
+	next := func() string {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				t.Fatal("Stream closed early")
+			}
+			return e
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for a log event")
+		}
+		return ""
+	}
+
+	// Buffered lines are replayed on connect, filtered by level
+	if e := next(); !strings.Contains(e, "[INFO] before connect peer_id=311001") {
+		t.Fatalf("Expected buffered line, got %q", e)
+	}
+
+	// Lines logged while connected are streamed live
+	log.WithComponent("network.MASTER-A").Warn("while connected")
+	if e := next(); !strings.Contains(e, "[network.MASTER-A] [WARN] while connected") {
+		t.Fatalf("Expected live line, got %q", e)
+	}
+}
+
+func TestHandleLogStream_DisabledAndLevelFloor(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error", Output: io.Discard})
+	api := NewAPI(log)
+
+	w := httptest.NewRecorder()
+	api.HandleLogStream(w, httptest.NewRequest("GET", "/api/logs/stream", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("Expected 404 without a log ring, got %d", w.Code)
+	}
+
+	// ?level= cannot go below the configured minimum
+	ring := logger.NewRing(4)
+	logger.New(logger.Config{Level: "debug", Output: io.Discard, Ring: ring}).Info("too chatty")
+	logger.New(logger.Config{Level: "debug", Output: io.Discard, Ring: ring}).Warn("kept")
+	api.SetLogRing(ring, "warn")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // replay the buffer, then return
+	w = httptest.NewRecorder()
+	api.HandleLogStream(w, httptest.NewRequest("GET", "/api/logs/stream?level=debug", nil).WithContext(ctx))
+	body := w.Body.String()
+	if strings.Contains(body, "too chatty") || !strings.Contains(body, "[WARN] kept") {
+		t.Fatalf("Expected only warn and above, got %q", body)
+	}
+}
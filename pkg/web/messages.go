@@ -0,0 +1,89 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/dbehnke/dmr-nexus/pkg/database"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+)
+
+// MessageDTO is a text message sent by a radio
+type MessageDTO struct {
+	SourceID      uint32 `json:"source_id"`
+	Callsign      string `json:"callsign,omitempty"`
+	DestinationID uint32 `json:"destination_id"`
+	Group         bool   `json:"group"` // DestinationID is a talkgroup
+	Text          string `json:"text"`
+	Timestamp     int64  `json:"timestamp"`
+}
+
+// SetMessageRepo sets the repository backing /api/messages
+func (a *API) SetMessageRepo(repo *database.MessageRepository) {
+	a.messageRepo = repo
+}
+
+// HandleMessages handles /api/messages, the most recent text messages,
+// newest first. It takes an optional talkgroup to show one talkgroup's
+// messages and an optional limit, default 100 and at most 1000.
+func (a *API) HandleMessages(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if a.messageRepo == nil {
+		http.Error(w, "Messages not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	limit := 100
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 1000 {
+			limit = l
+		}
+	}
+
+	var messages []database.Message
+	var err error
+	if tgStr := r.URL.Query().Get("talkgroup"); tgStr != "" {
+		tgid, perr := strconv.ParseUint(tgStr, 10, 32)
+		if perr != nil {
+			http.Error(w, "Invalid talkgroup", http.StatusBadRequest)
+			return
+		}
+		messages, err = a.messageRepo.GetByTalkgroup(uint32(tgid), limit)
+	} else {
+		messages, err = a.messageRepo.GetRecent(limit)
+	}
+	if err != nil {
+		a.logger.Error("Failed to get messages", logger.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	dtos := make([]MessageDTO, 0, len(messages))
+	for _, msg := range messages {
+		dto := MessageDTO{
+			SourceID:      a.anon.RadioID(msg.SourceID),
+			DestinationID: msg.DestinationID,
+			Group:         msg.Group,
+			Text:          msg.Text,
+			Timestamp:     msg.Timestamp.Unix(),
+		}
+		// A private message's destination is a subscriber too
+		if !msg.Group {
+			dto.DestinationID = a.anon.RadioID(msg.DestinationID)
+		}
+		if user, _ := a.lookupUser(msg.SourceID); user != nil {
+			dto.Callsign = a.anon.Callsign(user.Callsign)
+		}
+		dtos = append(dtos, dto)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(dtos); err != nil {
+		a.logger.Error("Failed to encode messages response", logger.Error(err))
+	}
+}
@@ -0,0 +1,81 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/database"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/privacy"
+)
+
+func TestHandleMessages(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	db, err := database.NewDB(database.Config{Path: filepath.Join(t.TempDir(), "messages.db")}, log)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	api := NewAPI(log)
+	w := httptest.NewRecorder()
+	api.HandleMessages(w, httptest.NewRequest(http.MethodGet, "/api/messages", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a repository, got %d", w.Code)
+	}
+
+	repo := database.NewMessageRepository(db.GetDB())
+	now := time.Now()
+	for i, msg := range []database.Message{
+		{SourceID: 3120001, DestinationID: 3100, Group: true, Text: "good morning", Timestamp: now.Add(-time.Hour)},
+		{SourceID: 3120002, DestinationID: 3120001, Text: "call me", Timestamp: now},
+	} {
+		if err := repo.Create(&msg); err != nil {
+			t.Fatalf("Create %d error: %v", i, err)
+		}
+	}
+	api.SetMessageRepo(repo)
+
+	get := func(path string) (int, []MessageDTO) {
+		w := httptest.NewRecorder()
+		api.HandleMessages(w, httptest.NewRequest(http.MethodGet, path, nil))
+		var dtos []MessageDTO
+		if w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&dtos); err != nil {
+				t.Fatalf("Failed to decode %s: %v", path, err)
+			}
+		}
+		return w.Code, dtos
+	}
+
+	if code, recent := get("/api/messages"); code != http.StatusOK || len(recent) != 2 || recent[0].Text != "call me" {
+		t.Errorf("GET /api/messages = %d %+v, want both messages newest first", code, recent)
+	}
+	code, tg := get("/api/messages?talkgroup=3100")
+	want := MessageDTO{SourceID: 3120001, DestinationID: 3100, Group: true, Text: "good morning", Timestamp: now.Add(-time.Hour).Unix()}
+	if code != http.StatusOK || len(tg) != 1 || tg[0] != want {
+		t.Errorf("GET /api/messages?talkgroup=3100 = %d %+v, want %+v", code, tg, want)
+	}
+	if code, _ := get("/api/messages?talkgroup=abc"); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid talkgroup, got %d", code)
+	}
+
+	// Saved before privacy mode was turned on, so served pseudonymous; a
+	// talkgroup is not a subscriber and stays as it is
+	anon := privacy.New(privacy.ModeHash, "secret")
+	api.SetAnonymizer(anon)
+	_, recent := get("/api/messages")
+	if len(recent) != 2 {
+		t.Fatalf("Expected both messages, got %+v", recent)
+	}
+	if recent[0].SourceID != anon.RadioID(3120002) || recent[0].DestinationID != anon.RadioID(3120001) {
+		t.Errorf("Expected pseudonymous private message IDs, got %+v", recent[0])
+	}
+	if recent[1].SourceID != anon.RadioID(3120001) || recent[1].DestinationID != 3100 {
+		t.Errorf("Expected pseudonymous source to the real talkgroup, got %+v", recent[1])
+	}
+}
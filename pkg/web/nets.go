@@ -0,0 +1,136 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+)
+
+// NetRequest is the body of POST /api/net/start and /api/net/stop
+type NetRequest struct {
+	Name      string `json:"name"`
+	Talkgroup uint32 `json:"talkgroup,omitempty"` // Required to start
+}
+
+// NetCheckinDTO is one station heard during a net
+type NetCheckinDTO struct {
+	RadioID       uint32 `json:"radio_id"`
+	Callsign      string `json:"callsign,omitempty"`
+	FirstHeard    int64  `json:"first_heard"`
+	LastHeard     int64  `json:"last_heard"`
+	Transmissions int    `json:"transmissions"`
+}
+
+// SetNetLogger sets the transmission logger that tags transmissions with
+// the running net; nil disables starting and stopping nets
+func (a *API) SetNetLogger(tl *bridge.TransmissionLogger) {
+	a.netLogger = tl
+}
+
+// HandleNet handles POST /api/net/start and /api/net/stop (admin only), and
+// GET /api/net/{name}/checkins: the unique stations heard on a net, in
+// order of first check-in
+func (a *API) HandleNet(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/net/")
+	switch {
+	case path == "start" || path == "stop":
+		a.handleNetControl(w, r, path)
+	case strings.HasSuffix(path, "/checkins") && path != "/checkins":
+		a.handleNetCheckins(w, r, strings.TrimSuffix(path, "/checkins"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (a *API) handleNetControl(w http.ResponseWriter, r *http.Request, action string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !a.requireAdmin(w, r) {
+		return
+	}
+	if a.netLogger == nil {
+		http.Error(w, "Net logging not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req NetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || strings.Contains(req.Name, "/") {
+		http.Error(w, "A net name without '/' is required", http.StatusBadRequest)
+		return
+	}
+
+	if action == "start" {
+		if req.Talkgroup == 0 {
+			http.Error(w, "A talkgroup is required", http.StatusBadRequest)
+			return
+		}
+		if err := a.netLogger.StartNet(req.Name, req.Talkgroup); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+	} else if !a.netLogger.StopNet(req.Name) {
+		http.Error(w, "Net not running", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(req); err != nil {
+		a.logger.Error("Failed to encode net response", logger.Error(err))
+	}
+}
+
+func (a *API) handleNetCheckins(w http.ResponseWriter, r *http.Request, name string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if a.txRepo == nil {
+		http.Error(w, "Transmissions not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	transmissions, err := a.txRepo.GetByNet(name)
+	if err != nil {
+		a.logger.Error("Failed to get net transmissions", logger.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	checkins := make([]NetCheckinDTO, 0)
+	index := make(map[uint32]int)
+	for _, tx := range transmissions {
+		if i, ok := index[tx.RadioID]; ok {
+			checkins[i].LastHeard = tx.EndTime.Unix()
+			checkins[i].Transmissions++
+			continue
+		}
+		dto := NetCheckinDTO{
+			RadioID:       a.anon.RadioID(tx.RadioID),
+			FirstHeard:    tx.StartTime.Unix(),
+			LastHeard:     tx.EndTime.Unix(),
+			Transmissions: 1,
+		}
+		if user, _ := a.lookupUser(tx.RadioID); user != nil {
+			dto.Callsign = a.anon.Callsign(user.Callsign)
+		}
+		index[tx.RadioID] = len(checkins)
+		checkins = append(checkins, dto)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(checkins); err != nil {
+		a.logger.Error("Failed to encode net check-ins response", logger.Error(err))
+	}
+}
@@ -0,0 +1,110 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/database"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+)
+
+func TestHandleNet(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	db, err := database.NewDB(database.Config{Path: filepath.Join(t.TempDir(), "net.db")}, log)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	txRepo := database.NewTransmissionRepository(db.GetDB())
+	userRepo := database.NewDMRUserRepository(db.GetDB())
+	if err := userRepo.Upsert(&database.DMRUser{RadioID: 3120001, Callsign: "N0CALL"}); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	txLogger := bridge.NewTransmissionLogger(txRepo, log)
+
+	api := NewAPI(log)
+	api.SetTransmissionRepo(txRepo)
+	api.SetUserRepo(userRepo)
+	api.SetNetLogger(txLogger)
+	api.SetAdminCredentials("admin", "pw")
+
+	post := func(action, body string, auth bool) int {
+		req := httptest.NewRequest("POST", "/api/net/"+action, strings.NewReader(body))
+		if auth {
+			req.SetBasicAuth("admin", "pw")
+		}
+		w := httptest.NewRecorder()
+		api.HandleNet(w, req)
+		return w.Code
+	}
+
+	if code := post("start", `{"name":"sunday","talkgroup":3100}`, false); code != http.StatusUnauthorized {
+		t.Fatalf("Expected 401 starting a net without auth, got %d", code)
+	}
+	if code := post("start", `{"name":"sunday"}`, true); code != http.StatusBadRequest {
+		t.Fatalf("Expected 400 starting a net without a talkgroup, got %d", code)
+	}
+	if code := post("start", `{"name":"sunday","talkgroup":3100}`, true); code != http.StatusOK {
+		t.Fatalf("Expected 200 starting a net, got %d", code)
+	}
+	if code := post("start", `{"name":"monday","talkgroup":3100}`, true); code != http.StatusConflict {
+		t.Fatalf("Expected 409 starting a second net on the talkgroup, got %d", code)
+	}
+	if nets := txLogger.ActiveNets(); nets[3100] != "sunday" {
+		t.Fatalf("Expected net sunday running on TG 3100, got %v", nets)
+	}
+	if code := post("stop", `{"name":"sunday"}`, true); code != http.StatusOK {
+		t.Fatalf("Expected 200 stopping the net, got %d", code)
+	}
+	if code := post("stop", `{"name":"sunday"}`, true); code != http.StatusNotFound {
+		t.Fatalf("Expected 404 stopping a net that isn't running, got %d", code)
+	}
+
+	// Two overs from one station, one from another, and traffic outside the net
+	start := time.Date(2026, 3, 8, 19, 0, 0, 0, time.UTC)
+	for i, tx := range []database.Transmission{
+		{RadioID: 3120001, NetName: "sunday"},
+		{RadioID: 3120002, NetName: "sunday"},
+		{RadioID: 3120001, NetName: "sunday"},
+		{RadioID: 3120003},
+	} {
+		tx.TalkgroupID = 3100
+		tx.Timeslot = 1
+		tx.StreamID = uint32(i + 1)
+		tx.StartTime = start.Add(time.Duration(i) * time.Minute)
+		tx.EndTime = tx.StartTime.Add(10 * time.Second)
+		if err := txRepo.Create(&tx); err != nil {
+			t.Fatalf("Failed to create transmission: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/api/net/sunday/checkins", nil)
+	w := httptest.NewRecorder()
+	api.HandleNet(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var checkins []NetCheckinDTO
+	if err := json.NewDecoder(w.Body).Decode(&checkins); err != nil {
+		t.Fatalf("Decode error: %v", err)
+	}
+	want := []NetCheckinDTO{
+		{RadioID: 3120001, Callsign: "N0CALL", FirstHeard: start.Unix(), LastHeard: start.Add(2*time.Minute + 10*time.Second).Unix(), Transmissions: 2},
+		{RadioID: 3120002, FirstHeard: start.Add(time.Minute).Unix(), LastHeard: start.Add(time.Minute + 10*time.Second).Unix(), Transmissions: 1},
+	}
+	if len(checkins) != len(want) {
+		t.Fatalf("Expected %d check-ins, got %+v", len(want), checkins)
+	}
+	for i := range want {
+		if checkins[i] != want[i] {
+			t.Errorf("checkin[%d] = %+v, want %+v", i, checkins[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/network"
+)
+
+// SetSystems provides the running MASTER systems for peer control
+func (a *API) SetSystems(reg *network.Registry) {
+	a.systems = reg
+}
+
+// HandlePeerAdmin handles POST /api/peer/{id}/disconnect (admin only): the
+// peer is sent MSTCL and dropped from its system
+func (a *API) HandlePeerAdmin(w http.ResponseWriter, r *http.Request) {
+	idStr, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/api/peer/"), "/disconnect")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !a.requireAdmin(w, r) {
+		return
+	}
+	id64, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid peer ID", http.StatusBadRequest)
+		return
+	}
+	if a.systems == nil {
+		http.Error(w, "Peer control not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	system, ok := a.systems.DisconnectPeer(uint32(id64))
+	if !ok {
+		http.Error(w, "Peer not connected", http.StatusNotFound)
+		return
+	}
+	a.logger.Info("Peer disconnected via API",
+		logger.Uint64("peer_id", id64),
+		logger.String("system", system))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	response := map[string]interface{}{
+		"status":  "disconnected",
+		"peer_id": uint32(id64),
+		"system":  system,
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		a.logger.Error("Failed to encode disconnect response", logger.Error(err))
+	}
+}
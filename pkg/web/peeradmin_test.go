@@ -0,0 +1,83 @@
+package web
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/network"
+	"github.com/dbehnke/dmr-nexus/pkg/peer"
+)
+
+func TestHandlePeerAdmin_Disconnect(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	pm := peer.NewPeerManager()
+	p := pm.AddPeer(312000, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 62031})
+	p.SetSystem("MASTER-1")
+	p.SetConnected()
+
+	reg := network.NewRegistry()
+	reg.Register(network.NewServer(config.SystemConfig{Mode: "MASTER"}, "MASTER-1", log).WithPeerManager(pm))
+
+	api := NewAPI(log)
+	api.SetSystems(reg)
+
+	post := func(path string, auth bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, nil)
+		if auth {
+			req.SetBasicAuth("admin", "secret")
+		}
+		w := httptest.NewRecorder()
+		api.HandlePeerAdmin(w, req)
+		return w
+	}
+
+	// Refused until admin credentials are configured
+	if w := post("/api/peer/312000/disconnect", true); w.Code != http.StatusForbidden {
+		t.Fatalf("Expected 403 without admin credentials, got %d", w.Code)
+	}
+	api.SetAdminCredentials("admin", "secret")
+	if w := post("/api/peer/312000/disconnect", false); w.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected 401 without auth, got %d", w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	api.HandlePeerAdmin(w, httptest.NewRequest("GET", "/api/peer/312000/disconnect", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET, got %d", w.Code)
+	}
+	if w := post("/api/peer/abc/disconnect", true); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a bad peer ID, got %d", w.Code)
+	}
+	if w := post("/api/peer/999/disconnect", true); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown peer, got %d", w.Code)
+	}
+
+	w = post("/api/peer/312000/disconnect", true)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response struct {
+		Status string `json:"status"`
+		PeerID uint32 `json:"peer_id"`
+		System string `json:"system"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Decode error: %v", err)
+	}
+	if response.Status != "disconnected" || response.PeerID != 312000 || response.System != "MASTER-1" {
+		t.Errorf("Unexpected response %+v", response)
+	}
+	if pm.GetPeer(312000) != nil {
+		t.Error("Expected the peer removed")
+	}
+
+	// Already gone
+	if w := post("/api/peer/312000/disconnect", true); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 once disconnected, got %d", w.Code)
+	}
+}
@@ -0,0 +1,84 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dbehnke/dmr-nexus/pkg/database"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+)
+
+// PositionDTO is a location report sent by a radio
+type PositionDTO struct {
+	RadioID   uint32  `json:"radio_id"`
+	Callsign  string  `json:"callsign,omitempty"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Timestamp int64   `json:"timestamp"`
+}
+
+// SetPositionRepo sets the repository backing /api/positions
+func (a *API) SetPositionRepo(repo *database.PositionRepository) {
+	a.positionRepo = repo
+}
+
+// HandlePositions handles /api/positions (the latest position of each
+// radio) and /api/positions/{radio_id} (one radio's history). Both take an
+// optional limit, default 100 and at most 1000.
+func (a *API) HandlePositions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if a.positionRepo == nil {
+		http.Error(w, "Positions not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	limit := 100
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 1000 {
+			limit = l
+		}
+	}
+
+	var positions []database.Position
+	var err error
+	if idStr := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/api/positions"), "/"); idStr != "" {
+		radioID, perr := strconv.ParseUint(idStr, 10, 32)
+		if perr != nil {
+			http.Error(w, "Invalid radio ID", http.StatusBadRequest)
+			return
+		}
+		positions, err = a.positionRepo.GetByRadioID(uint32(radioID), limit)
+	} else {
+		positions, err = a.positionRepo.GetLatest(limit)
+	}
+	if err != nil {
+		a.logger.Error("Failed to get positions", logger.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	dtos := make([]PositionDTO, 0, len(positions))
+	for _, pos := range positions {
+		dto := PositionDTO{
+			RadioID:   a.anon.RadioID(pos.RadioID),
+			Latitude:  pos.Latitude,
+			Longitude: pos.Longitude,
+			Timestamp: pos.Timestamp.Unix(),
+		}
+		if user, _ := a.lookupUser(pos.RadioID); user != nil {
+			dto.Callsign = a.anon.Callsign(user.Callsign)
+		}
+		dtos = append(dtos, dto)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(dtos); err != nil {
+		a.logger.Error("Failed to encode positions response", logger.Error(err))
+	}
+}
@@ -0,0 +1,67 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/database"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/privacy"
+)
+
+func TestHandlePositions(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	db, err := database.NewDB(database.Config{Path: filepath.Join(t.TempDir(), "positions.db")}, log)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	repo := database.NewPositionRepository(db.GetDB())
+	now := time.Now()
+	for i, pos := range []database.Position{
+		{RadioID: 3120001, Latitude: 42.30, Longitude: -83.00, Timestamp: now.Add(-time.Hour)},
+		{RadioID: 3120001, Latitude: 42.33, Longitude: -83.05, Timestamp: now},
+		{RadioID: 3120002, Latitude: 41.00, Longitude: -82.00, Timestamp: now.Add(-time.Minute)},
+	} {
+		if err := repo.Create(&pos); err != nil {
+			t.Fatalf("Create %d error: %v", i, err)
+		}
+	}
+
+	api := NewAPI(log)
+	api.SetPositionRepo(repo)
+
+	get := func(path string) (int, []PositionDTO) {
+		w := httptest.NewRecorder()
+		api.HandlePositions(w, httptest.NewRequest(http.MethodGet, path, nil))
+		var dtos []PositionDTO
+		if w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&dtos); err != nil {
+				t.Fatalf("Failed to decode %s: %v", path, err)
+			}
+		}
+		return w.Code, dtos
+	}
+
+	if code, latest := get("/api/positions"); code != http.StatusOK || len(latest) != 2 || latest[0].Latitude != 42.33 {
+		t.Errorf("GET /api/positions = %d %+v, want the latest fix of each radio", code, latest)
+	}
+	if code, history := get("/api/positions/3120001?limit=1"); code != http.StatusOK || len(history) != 1 || history[0].Latitude != 42.33 {
+		t.Errorf("GET /api/positions/3120001 = %d %+v, want the newest fix", code, history)
+	}
+	if code, _ := get("/api/positions/abc"); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid radio ID, got %d", code)
+	}
+
+	// Saved before privacy mode was turned on, so served pseudonymous
+	anon := privacy.New(privacy.ModeHash, "secret")
+	api.SetAnonymizer(anon)
+	if _, latest := get("/api/positions"); len(latest) != 2 || latest[0].RadioID != anon.RadioID(3120001) {
+		t.Errorf("Expected pseudonymous radio IDs, got %+v", latest)
+	}
+}
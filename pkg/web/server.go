@@ -4,13 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net"
 	"net/http"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
 
+	"github.com/dbehnke/dmr-nexus/internal/netlisten"
 	"github.com/dbehnke/dmr-nexus/pkg/bridge"
 	"github.com/dbehnke/dmr-nexus/pkg/config"
 	"github.com/dbehnke/dmr-nexus/pkg/logger"
@@ -63,11 +63,22 @@ func spaHandler(fsys http.FileSystem) http.Handler {
 
 // NewServer creates a new web server instance
 func NewServer(cfg config.WebConfig, log *logger.Logger) *Server {
+	hub := NewWebSocketHub(log)
+	hub.SetHeartbeat(
+		time.Duration(cfg.WSPingInterval)*time.Second,
+		time.Duration(cfg.WSPongTimeout)*time.Second,
+	)
+
+	api := NewAPI(log)
+	api.SetExcludeMonitors(cfg.ExcludeMonitorPeers)
+	api.SetAdminCredentials(cfg.Username, cfg.Password)
+	api.SetUserCache(cfg.UserCacheSize, time.Duration(cfg.UserCacheTTL)*time.Second)
+
 	return &Server{
 		config: cfg,
 		logger: log,
-		hub:    NewWebSocketHub(log),
-		api:    NewAPI(log),
+		hub:    hub,
+		api:    api,
 	}
 }
 
@@ -202,10 +213,25 @@ func (s *Server) Start(ctx context.Context) error {
 	// API endpoints
 	mux.HandleFunc("/api/status", s.api.HandleStatus)
 	mux.HandleFunc("/api/peers", s.api.HandlePeers)
+	mux.HandleFunc("/api/peer/", s.api.HandlePeerAdmin)
+	mux.HandleFunc("/api/repeater/", s.api.HandleRepeater)
 	mux.HandleFunc("/api/bridges", s.api.HandleBridges)
+	mux.HandleFunc("/api/bridge-matrix", s.api.HandleBridgeMatrix)
+	mux.HandleFunc("/api/messages", s.api.HandleMessages)
+	mux.HandleFunc("/api/stats", s.api.HandleStats)
+	mux.HandleFunc("/api/routes", s.api.HandleRoutes)
 	mux.HandleFunc("/api/activity", s.api.HandleActivity)
 	mux.HandleFunc("/api/transmissions", s.api.HandleTransmissions)
+	mux.HandleFunc("/api/feed.xml", s.api.HandleFeed)
 	mux.HandleFunc("/api/user/", s.api.HandleUserLookup)
+	mux.HandleFunc("/api/positions", s.api.HandlePositions)
+	mux.HandleFunc("/api/positions/", s.api.HandlePositions)
+	mux.HandleFunc("/api/talkgroups", s.api.HandleTalkgroups)
+	mux.HandleFunc("/api/net/", s.api.HandleNet)
+	mux.HandleFunc("/api/logs/stream", s.api.HandleLogStream)
+	mux.HandleFunc("/api/streams", s.api.HandleStreams)
+	mux.HandleFunc("/api/streams/", s.api.HandleStreams)
+	mux.HandleFunc("/api/diagnostics", s.api.HandleDiagnostics)
 
 	// WebSocket endpoint
 	mux.Handle("/ws", s.hub.Handler())
@@ -250,14 +276,14 @@ func (s *Server) Start(ctx context.Context) error {
 	// Create HTTP server
 	s.server = &http.Server{
 		Addr:         addr,
-		Handler:      mux,
+		Handler:      corsMiddleware(s.config.CORS, mux),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
 
 	// Start listener to get actual address (especially for port 0)
-	listener, err := net.Listen("tcp", addr)
+	listener, err := netlisten.Listen(addr, s.config.UnixSocket)
 	if err != nil {
 		return fmt.Errorf("failed to create listener: %w", err)
 	}
@@ -324,6 +350,14 @@ func (s *Server) PeerDisconnectedHandler() func(id uint32) {
 	}
 }
 
+// BridgeChangeHandler returns a function suitable for the router's bridge
+// change hook
+func (s *Server) BridgeChangeHandler() func(change bridge.BridgeChange) {
+	return func(change bridge.BridgeChange) {
+		s.hub.BroadcastBridgeChange(change)
+	}
+}
+
 // handleHealth handles the health check endpoint
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
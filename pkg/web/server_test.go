@@ -3,9 +3,11 @@ package web
 import (
 	"context"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -110,6 +112,52 @@ func TestServer_HealthEndpoint(t *testing.T) {
 	}
 }
 
+func TestServer_UnixSocket(t *testing.T) {
+	// Socket paths are length-limited, so keep clear of long TempDir names
+	dir, err := os.MkdirTemp("", "nexus")
+	if err != nil {
+		t.Fatalf("MkdirTemp error: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	socketPath := filepath.Join(dir, "web.sock")
+
+	cfg := config.WebConfig{Enabled: true, UnixSocket: socketPath}
+	log := logger.New(logger.Config{Level: "error"})
+	srv := NewServer(cfg, log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- srv.Start(ctx)
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socketPath)
+		},
+	}}
+	resp, err := client.Get("http://nexus/health")
+	if err != nil {
+		t.Fatalf("Failed to request health endpoint over socket: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	cancel()
+	if err := <-errChan; err != nil && err != context.Canceled && err != http.ErrServerClosed {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if _, err := os.Stat(socketPath); !os.IsNotExist(err) {
+		t.Errorf("Expected socket to be removed on shutdown, stat err = %v", err)
+	}
+}
+
 func TestSpaHandler(t *testing.T) {
 	// Create a temporary directory with test files
 	tmpDir := t.TempDir()
@@ -0,0 +1,35 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/metrics"
+)
+
+// StatsResponse is the body served at /api/stats
+type StatsResponse struct {
+	// Handshakes counts, per system, peer logins reaching each stage
+	Handshakes map[string]metrics.HandshakeFunnel `json:"handshakes"`
+}
+
+// HandleStats handles /api/stats: counters that show where peers stall
+// while logging in
+func (a *API) HandleStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	stats := StatsResponse{Handshakes: map[string]metrics.HandshakeFunnel{}}
+	if a.metrics != nil {
+		stats.Handshakes = a.metrics.GetHandshakeFunnels()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		a.logger.Error("Failed to encode stats response", logger.Error(err))
+	}
+}
@@ -0,0 +1,49 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/metrics"
+)
+
+func TestHandleStats(t *testing.T) {
+	api := NewAPI(logger.New(logger.Config{Level: "error"}))
+
+	get := func() StatsResponse {
+		t.Helper()
+		w := httptest.NewRecorder()
+		api.HandleStats(w, httptest.NewRequest("GET", "/api/stats", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		var stats StatsResponse
+		if err := json.NewDecoder(w.Body).Decode(&stats); err != nil {
+			t.Fatalf("Decode error: %v", err)
+		}
+		return stats
+	}
+
+	if stats := get(); stats.Handshakes == nil || len(stats.Handshakes) != 0 {
+		t.Fatalf("Expected an empty handshake map without metrics, got %+v", stats.Handshakes)
+	}
+
+	collector := metrics.NewCollector()
+	collector.HandshakeStageReached("MASTER-1", metrics.HandshakeLogin)
+	collector.HandshakeStageReached("MASTER-1", metrics.HandshakeKey)
+	api.SetMetrics(collector)
+
+	want := metrics.HandshakeFunnel{Login: 1, Key: 1}
+	if got := get().Handshakes["MASTER-1"]; got != want {
+		t.Errorf("Expected funnel %+v, got %+v", want, got)
+	}
+
+	w := httptest.NewRecorder()
+	api.HandleStats(w, httptest.NewRequest("POST", "/api/stats", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for POST, got %d", w.Code)
+	}
+}
@@ -0,0 +1,90 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+)
+
+// StreamDTO is a transmission currently holding a talkgroup
+type StreamDTO struct {
+	StreamID     uint32 `json:"stream_id"`
+	TGID         uint32 `json:"tgid"`
+	RadioID      uint32 `json:"radio_id"`
+	Callsign     string `json:"callsign,omitempty"`
+	LastActivity int64  `json:"last_activity"`
+}
+
+// HandleStreams handles /api/streams (GET lists active streams) and
+// /api/streams/{stream_id} (DELETE force-ends one; admin only)
+func (a *API) HandleStreams(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/api/streams"), "/")
+	if idStr == "" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		a.listStreams(w)
+		return
+	}
+
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !a.requireAdmin(w, r) {
+		return
+	}
+	id64, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid stream ID", http.StatusBadRequest)
+		return
+	}
+	if a.router == nil || !a.router.RevokeStream(uint32(id64)) {
+		http.Error(w, "Stream not found", http.StatusNotFound)
+		return
+	}
+
+	a.logger.Info("Stream revoked via API", logger.Uint64("stream", id64))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listStreams writes the dynamic talkgroups' active streams, skipping any
+// that went idle without a terminator
+func (a *API) listStreams(w http.ResponseWriter) {
+	streams := a.activeStreams()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(streams); err != nil {
+		a.logger.Error("Failed to encode streams response", logger.Error(err))
+	}
+}
+
+// activeStreams returns the streams currently holding dynamic talkgroups
+func (a *API) activeStreams() []StreamDTO {
+	streams := make([]StreamDTO, 0)
+	if a.router != nil {
+		for _, b := range a.router.GetAllDynamicBridges() {
+			if b.ActiveStreamID == 0 || time.Since(b.LastActivity) > bridge.StreamIdleTimeout {
+				continue
+			}
+			dto := StreamDTO{
+				StreamID:     b.ActiveStreamID,
+				TGID:         b.TGID,
+				RadioID:      a.anon.RadioID(b.ActiveRadioID),
+				LastActivity: b.LastActivity.Unix(),
+			}
+			if user, _ := a.lookupUser(b.ActiveRadioID); user != nil {
+				dto.Callsign = a.anon.Callsign(user.Callsign)
+			}
+			streams = append(streams, dto)
+		}
+	}
+	return streams
+}
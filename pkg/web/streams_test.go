@@ -0,0 +1,96 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/dbehnke/dmr-nexus/pkg/protocol"
+)
+
+func TestHandleStreams_ListAndRevoke(t *testing.T) {
+	router := bridge.NewRouter()
+	router.GetOrCreateDynamicBridge(3100)
+	router.GetOrCreateDynamicBridge(91)
+	router.RoutePacket(&protocol.DMRDPacket{
+		SourceID:      3120001,
+		DestinationID: 3100,
+		Timeslot:      1,
+		CallType:      protocol.CallTypeGroup,
+		FrameType:     protocol.FrameTypeVoiceHeader,
+		StreamID:      4242,
+	}, "SYSTEM1")
+
+	api := NewAPI(logger.New(logger.Config{Level: "error"}))
+	api.SetDeps(nil, router)
+
+	list := func() []StreamDTO {
+		w := httptest.NewRecorder()
+		api.HandleStreams(w, httptest.NewRequest("GET", "/api/streams", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		var streams []StreamDTO
+		if err := json.NewDecoder(w.Body).Decode(&streams); err != nil {
+			t.Fatalf("Decode error: %v", err)
+		}
+		return streams
+	}
+
+	streams := list()
+	if len(streams) != 1 || streams[0].StreamID != 4242 || streams[0].TGID != 3100 || streams[0].RadioID != 3120001 {
+		t.Fatalf("Expected the one active stream on TG 3100, got %+v", streams)
+	}
+
+	do := func(method, path string, auth bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if auth {
+			req.SetBasicAuth("admin", "secret")
+		}
+		w := httptest.NewRecorder()
+		api.HandleStreams(w, req)
+		return w
+	}
+
+	// Ending someone else's transmission is an admin action
+	if w := do("DELETE", "/api/streams/4242", true); w.Code != http.StatusForbidden {
+		t.Fatalf("Expected 403 without admin credentials, got %d", w.Code)
+	}
+	api.SetAdminCredentials("admin", "secret")
+	if w := do("DELETE", "/api/streams/4242", false); w.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected 401 without auth, got %d", w.Code)
+	}
+	if streams := list(); len(streams) != 1 {
+		t.Fatalf("Expected the stream untouched by rejected requests, got %+v", streams)
+	}
+
+	if w := do("DELETE", "/api/streams/4242", true); w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d", w.Code)
+	}
+	if streams := list(); len(streams) != 0 {
+		t.Fatalf("Expected no active streams after revoking, got %+v", streams)
+	}
+
+	// The source's next frame is cut off with one terminator downstream
+	next := &protocol.DMRDPacket{DestinationID: 3100, StreamID: 4242, FrameType: protocol.FrameTypeVoice}
+	if preempted, notify := router.PreemptedStream(next); !preempted || !notify {
+		t.Errorf("Expected the revoked stream cut off with notification, got preempted=%v notify=%v", preempted, notify)
+	}
+
+	for _, tc := range []struct {
+		method, path string
+		want         int
+	}{
+		{"DELETE", "/api/streams/4242", http.StatusNotFound},
+		{"DELETE", "/api/streams/abc", http.StatusBadRequest},
+		{"POST", "/api/streams", http.StatusMethodNotAllowed},
+		{"GET", "/api/streams/4242", http.StatusMethodNotAllowed},
+	} {
+		if w := do(tc.method, tc.path, true); w.Code != tc.want {
+			t.Errorf("%s %s: expected status %d, got %d", tc.method, tc.path, tc.want, w.Code)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+)
+
+// TalkgroupDTO is one talkgroup directory entry
+type TalkgroupDTO struct {
+	ID          uint32 `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// SetTalkgroups sets the talkgroup directory served at /api/talkgroups
+func (a *API) SetTalkgroups(tgs []config.Talkgroup) {
+	dtos := make([]TalkgroupDTO, 0, len(tgs))
+	for _, tg := range tgs {
+		dtos = append(dtos, TalkgroupDTO{ID: uint32(tg.ID), Name: tg.Name, Description: tg.Description})
+	}
+	a.talkgroups = dtos
+}
+
+// HandleTalkgroups handles /api/talkgroups, the talkgroup directory sorted
+// by ID
+func (a *API) HandleTalkgroups(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	talkgroups := a.talkgroups
+	if talkgroups == nil {
+		talkgroups = []TalkgroupDTO{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(talkgroups); err != nil {
+		a.logger.Error("Failed to encode talkgroups response", logger.Error(err))
+	}
+}
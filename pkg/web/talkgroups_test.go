@@ -0,0 +1,49 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dbehnke/dmr-nexus/pkg/config"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+)
+
+func TestHandleTalkgroups(t *testing.T) {
+	api := NewAPI(logger.New(logger.Config{Level: "error"}))
+
+	get := func() (int, []TalkgroupDTO) {
+		w := httptest.NewRecorder()
+		api.HandleTalkgroups(w, httptest.NewRequest(http.MethodGet, "/api/talkgroups", nil))
+		var dtos []TalkgroupDTO
+		if w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&dtos); err != nil {
+				t.Fatalf("Failed to decode /api/talkgroups: %v", err)
+			}
+		}
+		return w.Code, dtos
+	}
+
+	if code, tgs := get(); code != http.StatusOK || tgs == nil || len(tgs) != 0 {
+		t.Errorf("GET /api/talkgroups = %d %+v, want an empty list without a directory", code, tgs)
+	}
+
+	api.SetTalkgroups([]config.Talkgroup{
+		{ID: 9, Name: "Local"},
+		{ID: 3100, Name: "USA", Description: "Nationwide"},
+	})
+	code, tgs := get()
+	if code != http.StatusOK || len(tgs) != 2 {
+		t.Fatalf("GET /api/talkgroups = %d %+v, want both entries", code, tgs)
+	}
+	if tgs[0] != (TalkgroupDTO{ID: 9, Name: "Local"}) || tgs[1] != (TalkgroupDTO{ID: 3100, Name: "USA", Description: "Nationwide"}) {
+		t.Errorf("Unexpected directory: %+v", tgs)
+	}
+
+	w := httptest.NewRecorder()
+	api.HandleTalkgroups(w, httptest.NewRequest(http.MethodPost, "/api/talkgroups", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for POST, got %d", w.Code)
+	}
+}
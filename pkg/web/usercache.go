@@ -0,0 +1,84 @@
+package web
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/database"
+)
+
+// userCache is a size-bounded LRU of radio ID lookups with a TTL. Unknown
+// IDs are cached too (as nil) so dashboards polling unregistered talkers
+// don't hit the database each time.
+type userCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List // Front is most recently used
+	entries map[uint32]*list.Element
+}
+
+type userCacheEntry struct {
+	radioID uint32
+	user    *database.DMRUser
+	expires time.Time
+}
+
+func newUserCache(size int, ttl time.Duration) *userCache {
+	return &userCache{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[uint32]*list.Element),
+	}
+}
+
+// get returns the cached user for a radio ID; ok is false on a miss
+func (c *userCache) get(radioID uint32, now time.Time) (user *database.DMRUser, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, found := c.entries[radioID]
+	if !found {
+		return nil, false
+	}
+	entry := el.Value.(*userCacheEntry)
+	if now.After(entry.expires) {
+		c.order.Remove(el)
+		delete(c.entries, radioID)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return entry.user, true
+}
+
+// put caches a lookup result, evicting the least recently used entry when full
+func (c *userCache) put(radioID uint32, user *database.DMRUser, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, found := c.entries[radioID]; found {
+		entry := el.Value.(*userCacheEntry)
+		entry.user = user
+		entry.expires = now.Add(c.ttl)
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.entries[radioID] = c.order.PushFront(&userCacheEntry{radioID: radioID, user: user, expires: now.Add(c.ttl)})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*userCacheEntry).radioID)
+	}
+}
+
+// clear drops every entry
+func (c *userCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.entries = make(map[uint32]*list.Element)
+}
@@ -0,0 +1,79 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/dmr-nexus/pkg/database"
+	"github.com/dbehnke/dmr-nexus/pkg/logger"
+)
+
+func TestUserLookup_CacheAvoidsDatabase(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	db, err := database.NewDB(database.Config{Path: filepath.Join(t.TempDir(), "users.db")}, log)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	repo := database.NewDMRUserRepository(db.GetDB())
+	if err := repo.Upsert(&database.DMRUser{RadioID: 3120001, Callsign: "W1ABC"}); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	api := NewAPI(log)
+	api.SetUserRepo(repo)
+	api.SetUserCache(10, time.Minute)
+
+	lookup := func() UserDTO {
+		w := httptest.NewRecorder()
+		api.HandleUserLookup(w, httptest.NewRequest("GET", "/api/user/3120001", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		var user UserDTO
+		if err := json.NewDecoder(w.Body).Decode(&user); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return user
+	}
+
+	if user := lookup(); user.Callsign != "W1ABC" {
+		t.Fatalf("Expected W1ABC, got %+v", user)
+	}
+
+	// With the database gone, the cached lookup still answers
+	if err := db.Close(); err != nil {
+		t.Fatalf("Failed to close database: %v", err)
+	}
+	if user := lookup(); user.Callsign != "W1ABC" || user.Degraded {
+		t.Errorf("Expected a cache hit, got %+v", user)
+	}
+
+	// After a sync the cache is dropped and the next lookup goes to the database
+	api.InvalidateUserCache()
+	if user := lookup(); !user.Degraded {
+		t.Errorf("Expected a database lookup after invalidation, got %+v", user)
+	}
+}
+
+func TestUserCache_EvictsLeastRecentlyUsedAndExpires(t *testing.T) {
+	now := time.Now()
+	c := newUserCache(2, time.Minute)
+	c.put(1, &database.DMRUser{RadioID: 1}, now)
+	c.put(2, &database.DMRUser{RadioID: 2}, now)
+	c.get(1, now)
+	c.put(3, nil, now) // Unknown IDs are cached as nil
+
+	if _, ok := c.get(2, now); ok {
+		t.Error("Expected the least recently used entry to be evicted")
+	}
+	if user, ok := c.get(3, now); !ok || user != nil {
+		t.Errorf("Expected a cached miss for 3, got %v, %v", user, ok)
+	}
+	if _, ok := c.get(1, now.Add(2*time.Minute)); ok {
+		t.Error("Expected an expired entry to miss")
+	}
+}
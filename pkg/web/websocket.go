@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/dbehnke/dmr-nexus/pkg/bridge"
 	"github.com/dbehnke/dmr-nexus/pkg/logger"
 	"github.com/gorilla/websocket"
 )
@@ -30,24 +31,49 @@ type Client struct {
 	messages chan []byte
 }
 
+const (
+	// DefaultPingInterval is how often the server pings each WebSocket client
+	DefaultPingInterval = 30 * time.Second
+	// DefaultPongTimeout is how long a client may go without answering a ping
+	DefaultPongTimeout = 60 * time.Second
+	// writeWait is the time allowed to write a single message to a client
+	writeWait = 10 * time.Second
+)
+
 // WebSocketHub manages WebSocket client connections and broadcasts
 type WebSocketHub struct {
-	clients    map[*Client]bool
-	broadcast  chan Event
-	register   chan *Client
-	unregister chan *Client
-	logger     *logger.Logger
-	mu         sync.RWMutex
+	clients      map[*Client]bool
+	broadcast    chan Event
+	register     chan *Client
+	unregister   chan *Client
+	logger       *logger.Logger
+	pingInterval time.Duration
+	pongTimeout  time.Duration
+	mu           sync.RWMutex
 }
 
 // NewWebSocketHub creates a new WebSocket hub
 func NewWebSocketHub(log *logger.Logger) *WebSocketHub {
 	return &WebSocketHub{
-		clients:    make(map[*Client]bool),
-		broadcast:  make(chan Event, 256),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
-		logger:     log,
+		clients:      make(map[*Client]bool),
+		broadcast:    make(chan Event, 256),
+		register:     make(chan *Client),
+		unregister:   make(chan *Client),
+		logger:       log,
+		pingInterval: DefaultPingInterval,
+		pongTimeout:  DefaultPongTimeout,
+	}
+}
+
+// SetHeartbeat configures the ping interval and the pong timeout after which
+// an unresponsive client is disconnected. Non-positive values keep the defaults.
+// Must be called before Handler.
+func (h *WebSocketHub) SetHeartbeat(pingInterval, pongTimeout time.Duration) {
+	if pingInterval > 0 {
+		h.pingInterval = pingInterval
+	}
+	if pongTimeout > 0 {
+		h.pongTimeout = pongTimeout
 	}
 }
 
@@ -137,24 +163,48 @@ func (h *WebSocketHub) Handler() http.Handler {
 		client := &Client{ID: r.RemoteAddr, conn: conn, messages: make(chan []byte, 256)}
 		h.register <- client
 
-		// Reader goroutine: drain read to detect close
+		// Reader goroutine: drain read to detect close. The read deadline is
+		// pushed out on every pong, so a client that stops answering pings
+		// times out here and is removed.
 		go func() {
 			defer func() {
 				h.unregister <- client
 				_ = client.conn.Close()
 			}()
 			client.conn.SetReadLimit(1024)
+			_ = client.conn.SetReadDeadline(time.Now().Add(h.pongTimeout))
+			client.conn.SetPongHandler(func(string) error {
+				return client.conn.SetReadDeadline(time.Now().Add(h.pongTimeout))
+			})
 			for {
 				if _, _, err := client.conn.ReadMessage(); err != nil {
+					h.logger.Debug("WebSocket client read ended",
+						logger.String("client_id", client.ID),
+						logger.Error(err))
 					return
 				}
 			}
 		}()
 
-		// Writer loop
+		// Writer loop: forwards broadcasts and sends periodic pings
 		go func() {
-			for msg := range client.messages {
-				_ = client.conn.WriteMessage(websocket.TextMessage, msg)
+			ticker := time.NewTicker(h.pingInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case msg, ok := <-client.messages:
+					if !ok {
+						return
+					}
+					_ = client.conn.SetWriteDeadline(time.Now().Add(writeWait))
+					_ = client.conn.WriteMessage(websocket.TextMessage, msg)
+				case <-ticker.C:
+					if err := client.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+						// Closing the connection unblocks the reader, which unregisters the client
+						_ = client.conn.Close()
+						return
+					}
+				}
 			}
 		}()
 	})
@@ -224,6 +274,22 @@ func (h *WebSocketHub) BroadcastBridgesUpdate(bridges interface{}) {
 	})
 }
 
+// BroadcastBridgeChange broadcasts a static bridge rule switching on or off
+func (h *WebSocketHub) BroadcastBridgeChange(change bridge.BridgeChange) {
+	h.Broadcast(Event{
+		Type:      "bridge_change",
+		Timestamp: time.Now(),
+		Data: map[string]interface{}{
+			"bridge":   change.Bridge,
+			"system":   change.System,
+			"tgid":     change.TGID,
+			"timeslot": change.Timeslot,
+			"active":   change.Active,
+			"trigger":  change.Trigger,
+		},
+	})
+}
+
 // BroadcastTransmissionsUpdate broadcasts transmission update to all clients
 func (h *WebSocketHub) BroadcastTransmissionsUpdate(transmissions interface{}) {
 	h.Broadcast(Event{
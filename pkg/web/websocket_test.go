@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/dbehnke/dmr-nexus/pkg/logger"
+	"github.com/gorilla/websocket"
 )
 
 func TestWebSocketHub_New(t *testing.T) {
@@ -114,3 +115,74 @@ func TestEvent_Marshal(t *testing.T) {
 		t.Error("Marshaled data doesn't contain event type")
 	}
 }
+
+func TestWebSocketHub_RemovesUnresponsiveClient(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	hub := NewWebSocketHub(log)
+	hub.SetHeartbeat(50*time.Millisecond, 150*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hub.Run(ctx)
+
+	server := httptest.NewServer(hub.Handler())
+	defer server.Close()
+
+	// A client that never reads never answers pings, like a closed browser tab
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to dial WebSocket: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	deadline := time.Now().Add(time.Second)
+	for hub.GetClientCount() != 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if hub.GetClientCount() != 1 {
+		t.Fatalf("Expected 1 registered client, got %d", hub.GetClientCount())
+	}
+
+	deadline = time.Now().Add(2 * time.Second)
+	for hub.GetClientCount() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if hub.GetClientCount() != 0 {
+		t.Errorf("Expected unresponsive client to be removed, still have %d", hub.GetClientCount())
+	}
+}
+
+func TestWebSocketHub_KeepsResponsiveClient(t *testing.T) {
+	log := logger.New(logger.Config{Level: "error"})
+	hub := NewWebSocketHub(log)
+	hub.SetHeartbeat(50*time.Millisecond, 150*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hub.Run(ctx)
+
+	server := httptest.NewServer(hub.Handler())
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to dial WebSocket: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	// Reading lets the default ping handler answer with pongs
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	time.Sleep(400 * time.Millisecond)
+	if hub.GetClientCount() != 1 {
+		t.Errorf("Expected responsive client to stay connected, got %d clients", hub.GetClientCount())
+	}
+}